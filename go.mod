@@ -1,5 +1,7 @@
 module github.com/jiyeyuran/mediasoup-go
 
+go 1.18
+
 require (
 	github.com/imdario/mergo v0.3.8-0.20190313170249-367dccd03f18
 	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
@@ -7,3 +9,12 @@ require (
 	github.com/sirupsen/logrus v1.4.1
 	github.com/stretchr/testify v1.3.1-0.20190311161405-34c6fa2dc709
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20180905080454-ebe1bf3edb33 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
@@ -0,0 +1,66 @@
+package mediasoup
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ActiveSpeakerObserverDominantSpeaker is the parameter of the
+// "dominantspeaker" event.
+type ActiveSpeakerObserverDominantSpeaker struct {
+	Producer *Producer
+}
+
+type ActiveSpeakerObserver struct {
+	*baseRtpObserver
+	logger   logrus.FieldLogger
+	settings CreateActiveSpeakerObserverParams
+}
+
+/**
+ * New ActiveSpeakerObserver.
+ *
+ * @emits {ActiveSpeakerObserverDominantSpeaker} dominantspeaker
+ */
+func NewActiveSpeakerObserver(
+	internal internalData,
+	channel *Channel,
+	getProducerById fetchProducerFunc,
+) *ActiveSpeakerObserver {
+	o := &ActiveSpeakerObserver{
+		baseRtpObserver: newRtpObserver(internal, channel),
+		logger:          TypeLogger("ActiveSpeakerObserver"),
+	}
+
+	o.handleWorkerNotifications(internal.RtpObserverId, getProducerById)
+
+	return o
+}
+
+func (o *ActiveSpeakerObserver) handleWorkerNotifications(
+	rtpObserverId string,
+	getProducerById fetchProducerFunc,
+) {
+	o.baseRtpObserver.channel.On(rtpObserverId,
+		func(event string, data json.RawMessage) {
+			switch event {
+			case RtpObserverNotificationDominantSpeaker:
+				var notification DominantSpeakerNotification
+
+				json.Unmarshal([]byte(data), &notification)
+
+				// The Producer may have been closed in the meanwhile.
+				producer := getProducerById(notification.ProducerId)
+
+				if producer != nil {
+					o.SafeEmit("dominantspeaker", ActiveSpeakerObserverDominantSpeaker{
+						Producer: producer,
+					})
+				}
+			default:
+				o.logger.Errorf(`ignoring unknown event "%s"`, event)
+			}
+		},
+	)
+}
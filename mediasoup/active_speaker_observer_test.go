@@ -0,0 +1,65 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateActiveSpeakerObserver_Succeeds(t *testing.T) {
+	worker := CreateTestWorker()
+	router, _ := worker.CreateRouter(audioLevelMediaCodecs)
+	activeSpeakerObserver, err := router.CreateActiveSpeakerObserver(nil)
+
+	assert.NoError(t, err)
+	assert.False(t, activeSpeakerObserver.Closed())
+	assert.False(t, activeSpeakerObserver.Paused())
+
+	dump := router.Dump()
+
+	var result struct {
+		RtpObserverIds []string
+	}
+	assert.NoError(t, dump.Unmarshal(&result))
+	assert.Equal(t, []string{activeSpeakerObserver.Id()}, result.RtpObserverIds)
+
+	activeSpeakerObserver.Close()
+
+	assert.True(t, activeSpeakerObserver.Closed())
+}
+
+func TestActiveSpeakerObserver_DominantSpeaker(t *testing.T) {
+	channel := newTestChannel()
+	producer := &Producer{internal: internalData{ProducerId: "p1"}}
+
+	observer := NewActiveSpeakerObserver(
+		internalData{RtpObserverId: "o1"},
+		channel,
+		func(producerId string) *Producer {
+			if producerId == producer.Id() {
+				return producer
+			}
+			return nil
+		},
+	)
+
+	speakers := make(chan ActiveSpeakerObserverDominantSpeaker, 2)
+
+	observer.On("dominantspeaker", func(speaker ActiveSpeakerObserverDominantSpeaker) {
+		speakers <- speaker
+	})
+
+	// Unknown Producers are ignored.
+	channel.SafeEmit("o1", RtpObserverNotificationDominantSpeaker, json.RawMessage(`{"producerId":"p2"}`))
+	channel.SafeEmit("o1", RtpObserverNotificationDominantSpeaker, json.RawMessage(`{"producerId":"p1"}`))
+
+	select {
+	case speaker := <-speakers:
+		assert.Equal(t, producer, speaker.Producer)
+	case <-time.After(time.Second):
+		t.Fatal("dominantspeaker not emitted")
+	}
+	assert.Empty(t, speakers)
+}
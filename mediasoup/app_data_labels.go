@@ -0,0 +1,128 @@
+package mediasoup
+
+import "sync"
+
+// OverflowLabelValue replaces the values of a label beyond its cardinality
+// limit.
+const OverflowLabelValue = "_other"
+
+type AppDataLabelOptions struct {
+	// Keys of the app data turned into labels, e.g. "roomId" or "tenant".
+	Keys []string
+	// MaxValues is the number of distinct values of each label, further
+	// values being reported as OverflowLabelValue, default 100.
+	MaxValues int
+	// MaxValueLength truncates longer values, default 64.
+	MaxValueLength int
+}
+
+/**
+ * AppDataLabeler turns the declared app data keys of the entities into labels,
+ * used consistently as trace ids of the channel requests and as labels of the
+ * stats snapshots. The number of distinct values of each label is limited, so
+ * a misused key (e.g. a peer id) cannot blow up the cardinality of the
+ * metrics.
+ */
+type AppDataLabeler struct {
+	locker  sync.Mutex
+	options AppDataLabelOptions
+	values  map[string]map[string]bool
+}
+
+func NewAppDataLabeler(options AppDataLabelOptions) *AppDataLabeler {
+	if options.MaxValues == 0 {
+		options.MaxValues = 100
+	}
+	if options.MaxValueLength == 0 {
+		options.MaxValueLength = 64
+	}
+
+	return &AppDataLabeler{
+		options: options,
+		values:  make(map[string]map[string]bool),
+	}
+}
+
+var (
+	appDataLabelerLocker sync.Mutex
+	appDataLabeler       *AppDataLabeler
+)
+
+// SetAppDataLabeler sets the labeler applied to the entities created from
+// now on, a nil labeler disabling app data labels (the default).
+func SetAppDataLabeler(labeler *AppDataLabeler) {
+	appDataLabelerLocker.Lock()
+	defer appDataLabelerLocker.Unlock()
+
+	appDataLabeler = labeler
+}
+
+// appDataLabels returns the labels of the app data given by the package
+// labeler, nil without labeler.
+func appDataLabels(appData interface{}) TraceIds {
+	appDataLabelerLocker.Lock()
+	labeler := appDataLabeler
+	appDataLabelerLocker.Unlock()
+
+	if labeler == nil {
+		return nil
+	}
+
+	return labeler.Labels(appData)
+}
+
+// Labels returns the declared keys of the app data having a scalar value.
+func (labeler *AppDataLabeler) Labels(appData interface{}) TraceIds {
+	values := AppDataLabels(appData)
+
+	labeler.locker.Lock()
+	defer labeler.locker.Unlock()
+
+	var labels TraceIds
+
+	for _, key := range labeler.options.Keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if len(value) > labeler.options.MaxValueLength {
+			value = value[:labeler.options.MaxValueLength]
+		}
+
+		seen := labeler.values[key]
+		if seen == nil {
+			seen = make(map[string]bool)
+			labeler.values[key] = seen
+		}
+
+		if !seen[value] {
+			if len(seen) >= labeler.options.MaxValues {
+				value = OverflowLabelValue
+			} else {
+				seen[value] = true
+			}
+		}
+
+		if labels == nil {
+			labels = make(TraceIds)
+		}
+		labels[key] = value
+	}
+
+	return labels
+}
+
+// Cardinality returns the number of distinct values of each label so far,
+// OverflowLabelValue excluded.
+func (labeler *AppDataLabeler) Cardinality() map[string]int {
+	labeler.locker.Lock()
+	defer labeler.locker.Unlock()
+
+	cardinality := make(map[string]int, len(labeler.values))
+
+	for key, values := range labeler.values {
+		cardinality[key] = len(values)
+	}
+
+	return cardinality
+}
@@ -0,0 +1,52 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppDataLabeler(t *testing.T) {
+	labeler := NewAppDataLabeler(AppDataLabelOptions{
+		Keys:           []string{"roomId", "tenant"},
+		MaxValues:      2,
+		MaxValueLength: 8,
+	})
+
+	assert.Equal(t, TraceIds{"roomId": "r1", "tenant": "acme"},
+		labeler.Labels(H{"roomId": "r1", "tenant": "acme", "peerId": "p1"}))
+	assert.Equal(t, TraceIds{"roomId": "12345678"}, labeler.Labels(H{"roomId": 123456789}))
+	assert.Equal(t, TraceIds{"roomId": OverflowLabelValue}, labeler.Labels(H{"roomId": "r3"}))
+
+	// Known values are still reported.
+	assert.Equal(t, TraceIds{"roomId": "r1"}, labeler.Labels(H{"roomId": "r1", "tenant": H{"nested": 1}}))
+	assert.Nil(t, labeler.Labels(nil))
+
+	assert.Equal(t, map[string]int{"roomId": 2, "tenant": 1}, labeler.Cardinality())
+}
+
+func TestAppDataLabels_Applied(t *testing.T) {
+	SetAppDataLabeler(NewAppDataLabeler(AppDataLabelOptions{Keys: []string{"roomId"}}))
+	defer SetAppDataLabeler(nil)
+
+	assert.Equal(t, TraceIds{"roomId": "r1"}, appDataLabels(H{"roomId": "r1"}))
+
+	fakeClock := testutil.NewFakeClock(time.Unix(1000, 0))
+
+	poller := NewStatsPoller(StatsPollerOptions{Interval: time.Second, Clock: fakeClock})
+	defer poller.Close()
+
+	snapshots := make(chan StatsSnapshot, 1)
+	poller.On("stats", func(snapshot StatsSnapshot) { snapshots <- snapshot })
+
+	poller.add("t1", NewEventEmitter(TypeLogger("test")), H{"roomId": "r1"}, func() (StatsSnapshot, error) {
+		return StatsSnapshot{Entity: IdEntityTransport}, nil
+	})
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	assert.Equal(t, map[string]string{"roomId": "r1"}, (<-snapshots).Labels)
+}
@@ -8,7 +8,8 @@ import (
 
 type AudioLevelObserver struct {
 	*baseRtpObserver
-	logger logrus.FieldLogger
+	logger   logrus.FieldLogger
+	settings CreateAudioLevelObserverParams
 }
 
 func NewAudioLevelObserver(
@@ -33,14 +34,11 @@ func (o *AudioLevelObserver) handleWorkerNotifications(
 	o.baseRtpObserver.channel.On(rtpObserverId,
 		func(event string, data json.RawMessage) {
 			switch event {
-			case "volumes":
+			case RtpObserverNotificationVolumes:
 				// Get the corresponding Producer instance and remove entries with
 				// no Producer (it may have been closed in the meanwhile).
 				var volumes []VolumeInfo
-				var notifications []struct {
-					ProducerId string
-					Volume     uint8
-				}
+				var notifications []VolumeNotification
 
 				json.Unmarshal([]byte(data), &notifications)
 
@@ -55,10 +53,10 @@ func (o *AudioLevelObserver) handleWorkerNotifications(
 					}
 				}
 
-				if len(volumes) > 0 {
+				if len(volumes) > 0 && allowGovernedEvent(GovernedEventVolumes) {
 					o.SafeEmit("volumes", volumes)
 				}
-			case "silence":
+			case RtpObserverNotificationSilence:
 				o.SafeEmit("silence")
 			default:
 				o.logger.Errorf(`ignoring unknown event "%s"`, event)
@@ -0,0 +1,243 @@
+package mediasoup
+
+import "sync"
+
+// PathBandwidth aggregates the bandwidth estimation of all the transports
+// whose selected tuple goes to the same remote IP with the same protocol, that
+// is, the transports sharing the same network path to a peer.
+type PathBandwidth struct {
+	RemoteIp     string   `json:"remoteIp"`
+	Protocol     string   `json:"protocol"`
+	TransportIds []string `json:"transportIds"`
+	// Available bitrates are the highest estimation of the transports, each
+	// of them estimating the whole path.
+	AvailableIncomingBitrate uint32 `json:"availableIncomingBitrate"`
+	AvailableOutgoingBitrate uint32 `json:"availableOutgoingBitrate"`
+	// MaxIncomingBitrate is the sum of the limits set on the transports.
+	MaxIncomingBitrate uint32 `json:"maxIncomingBitrate"`
+}
+
+// GetPathBandwidths gets the stats of the given transports and aggregates
+// them per network path. Transports without a selected tuple are ignored.
+func GetPathBandwidths(transports ...Transport) (paths []PathBandwidth, err error) {
+	var stats []TransportStat
+
+	for _, transport := range transports {
+		var transportStats []TransportStat
+
+		if transportStats, err = transport.GetStats(); err != nil {
+			return
+		}
+
+		stats = append(stats, transportStats...)
+	}
+
+	return aggregatePathBandwidths(stats), nil
+}
+
+func aggregatePathBandwidths(stats []TransportStat) (paths []PathBandwidth) {
+	type pathKey struct {
+		remoteIp string
+		protocol string
+	}
+
+	indexes := map[pathKey]int{}
+
+	for _, stat := range stats {
+		tuple := stat.IceSelectedTuple
+
+		if tuple == nil {
+			tuple = stat.Tuple
+		}
+		if tuple == nil || len(tuple.RemoteIp) == 0 {
+			continue
+		}
+
+		key := pathKey{remoteIp: tuple.RemoteIp, protocol: tuple.Protocol}
+		idx, ok := indexes[key]
+
+		if !ok {
+			idx = len(paths)
+			indexes[key] = idx
+			paths = append(paths, PathBandwidth{
+				RemoteIp: tuple.RemoteIp,
+				Protocol: tuple.Protocol,
+			})
+		}
+
+		path := &paths[idx]
+		path.TransportIds = append(path.TransportIds, stat.TransportId)
+		path.MaxIncomingBitrate += stat.MaxIncomingBitrate
+
+		if stat.AvailableIncomingBitrate > path.AvailableIncomingBitrate {
+			path.AvailableIncomingBitrate = stat.AvailableIncomingBitrate
+		}
+		if stat.AvailableOutgoingBitrate > path.AvailableOutgoingBitrate {
+			path.AvailableOutgoingBitrate = stat.AvailableOutgoingBitrate
+		}
+	}
+
+	return
+}
+
+// BitrateBudget constrains the aggregate bitrate of all the transports of a
+// peer instead of each one separately. Closed transports are removed from the
+// budget automatically.
+type BitrateBudget struct {
+	locker     sync.Mutex
+	maxBitrate uint32
+	transports map[string]Transport
+	// Removal of the "close" listener of each transport.
+	offs map[string]func()
+}
+
+func NewBitrateBudget(maxBitrate uint32, transports ...Transport) *BitrateBudget {
+	budget := &BitrateBudget{
+		maxBitrate: maxBitrate,
+		transports: make(map[string]Transport),
+		offs:       make(map[string]func()),
+	}
+
+	for _, transport := range transports {
+		budget.AddTransport(transport)
+	}
+
+	return budget
+}
+
+// MaxBitrate returns the aggregate budget in bps.
+func (budget *BitrateBudget) MaxBitrate() uint32 {
+	budget.locker.Lock()
+	defer budget.locker.Unlock()
+
+	return budget.maxBitrate
+}
+
+// SetMaxBitrate updates the aggregate budget in bps. Call Allocate() or
+// ApplyIncoming() afterwards to redistribute it.
+func (budget *BitrateBudget) SetMaxBitrate(maxBitrate uint32) {
+	budget.locker.Lock()
+	defer budget.locker.Unlock()
+
+	budget.maxBitrate = maxBitrate
+}
+
+func (budget *BitrateBudget) AddTransport(transport Transport) {
+	budget.locker.Lock()
+	defer budget.locker.Unlock()
+
+	if _, ok := budget.transports[transport.Id()]; ok {
+		return
+	}
+
+	budget.transports[transport.Id()] = transport
+	budget.offs[transport.Id()] = OnSignal(transport.Observer(), "close", func() {
+		budget.RemoveTransport(transport.Id())
+	})
+}
+
+func (budget *BitrateBudget) RemoveTransport(transportId string) {
+	budget.locker.Lock()
+	off := budget.offs[transportId]
+	delete(budget.transports, transportId)
+	delete(budget.offs, transportId)
+	budget.locker.Unlock()
+
+	if off != nil {
+		off()
+	}
+}
+
+// Allocate splits the budget among the transports proportionally to their
+// available outgoing bitrate (evenly if nothing is known yet), so the caller
+// can fit the consumers of each transport (e.g. by choosing layers) into its
+// share. It returns the share in bps keyed by transport id.
+func (budget *BitrateBudget) Allocate() (shares map[string]uint32, err error) {
+	budget.locker.Lock()
+	maxBitrate := budget.maxBitrate
+	transports := budget.transportList()
+	budget.locker.Unlock()
+
+	weights := make(map[string]uint32)
+
+	for _, transport := range transports {
+		var stats []TransportStat
+
+		if stats, err = transport.GetStats(); err != nil {
+			return
+		}
+
+		weights[transport.Id()] = 0
+
+		for _, stat := range stats {
+			weights[transport.Id()] += stat.AvailableOutgoingBitrate
+		}
+	}
+
+	return splitBitrate(maxBitrate, weights), nil
+}
+
+// ApplyIncoming splits the budget evenly among the WebRtcTransports and sets
+// it as their maximum incoming bitrate.
+func (budget *BitrateBudget) ApplyIncoming() error {
+	budget.locker.Lock()
+	maxBitrate := budget.maxBitrate
+	transports := budget.transportList()
+	budget.locker.Unlock()
+
+	weights := make(map[string]uint32)
+
+	for _, transport := range transports {
+		if _, ok := transport.(*WebRtcTransport); ok {
+			weights[transport.Id()] = 0
+		}
+	}
+
+	shares := splitBitrate(maxBitrate, weights)
+
+	for _, transport := range transports {
+		webRtcTransport, ok := transport.(*WebRtcTransport)
+		if !ok {
+			continue
+		}
+		if err := webRtcTransport.SetMaxIncomingBitrate(int(shares[transport.Id()])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (budget *BitrateBudget) transportList() (transports []Transport) {
+	for _, transport := range budget.transports {
+		transports = append(transports, transport)
+	}
+
+	return
+}
+
+// splitBitrate splits the bitrate proportionally to the given weights, or
+// evenly if all of them are zero.
+func splitBitrate(bitrate uint32, weights map[string]uint32) map[string]uint32 {
+	shares := make(map[string]uint32, len(weights))
+
+	if len(weights) == 0 {
+		return shares
+	}
+
+	var total uint64
+
+	for _, weight := range weights {
+		total += uint64(weight)
+	}
+
+	for id, weight := range weights {
+		if total == 0 {
+			shares[id] = bitrate / uint32(len(weights))
+		} else {
+			shares[id] = uint32(uint64(bitrate) * uint64(weight) / total)
+		}
+	}
+
+	return shares
+}
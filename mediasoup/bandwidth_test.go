@@ -0,0 +1,83 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregatePathBandwidths(t *testing.T) {
+	stats := []TransportStat{
+		{
+			TransportId:              "t1",
+			AvailableOutgoingBitrate: 1000,
+			IceSelectedTuple:         &TransportTuple{RemoteIp: "1.1.1.1", RemotePort: 1000, Protocol: "udp"},
+		},
+		{
+			TransportId:              "t2",
+			AvailableOutgoingBitrate: 2000,
+			IceSelectedTuple:         &TransportTuple{RemoteIp: "1.1.1.1", RemotePort: 2000, Protocol: "udp"},
+		},
+		{
+			TransportId:              "t3",
+			AvailableOutgoingBitrate: 3000,
+			Tuple:                    &TransportTuple{RemoteIp: "2.2.2.2", RemotePort: 3000, Protocol: "udp"},
+		},
+		{
+			TransportId: "t4",
+		},
+	}
+
+	assert.Equal(t, []PathBandwidth{
+		{
+			RemoteIp:                 "1.1.1.1",
+			Protocol:                 "udp",
+			TransportIds:             []string{"t1", "t2"},
+			AvailableOutgoingBitrate: 2000,
+		},
+		{
+			RemoteIp:                 "2.2.2.2",
+			Protocol:                 "udp",
+			TransportIds:             []string{"t3"},
+			AvailableOutgoingBitrate: 3000,
+		},
+	}, aggregatePathBandwidths(stats))
+}
+
+func TestBitrateBudget_RemoveTransport(t *testing.T) {
+	transport1 := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  newTestChannel(),
+	})
+	transport2 := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t2"},
+		Channel:  newTestChannel(),
+	})
+
+	budget := NewBitrateBudget(1000, transport1, transport2)
+	otherBudget := NewBitrateBudget(1000, transport1)
+
+	assert.Equal(t, 2, transport1.Observer().ListenerCount("close"))
+
+	budget.RemoveTransport("t1")
+
+	// Only the listener of this budget is removed.
+	assert.Equal(t, 1, transport1.Observer().ListenerCount("close"))
+	assert.Len(t, budget.transports, 1)
+
+	// Closed transports are removed.
+	assert.NoError(t, transport2.Close())
+	assert.Empty(t, budget.transports)
+	assert.Equal(t, 0, transport2.Observer().ListenerCount("close"))
+
+	otherBudget.RemoveTransport("t1")
+	assert.Equal(t, 0, transport1.Observer().ListenerCount("close"))
+}
+
+func TestSplitBitrate(t *testing.T) {
+	assert.Equal(t, map[string]uint32{"t1": 250000, "t2": 750000},
+		splitBitrate(1000000, map[string]uint32{"t1": 100, "t2": 300}))
+	assert.Equal(t, map[string]uint32{"t1": 500000, "t2": 500000},
+		splitBitrate(1000000, map[string]uint32{"t1": 0, "t2": 0}))
+	assert.Empty(t, splitBitrate(1000000, nil))
+}
@@ -0,0 +1,107 @@
+package mediasoup
+
+import "fmt"
+
+// Bits per pixel used to estimate the bitrate of a VP8/H264 layer, other
+// codecs are scaled by bitrateLadderCodecFactors.
+const bitrateLadderBitsPerPixel = 0.1
+
+// Minimum bitrate of a layer.
+const bitrateLadderMinBitrate = 100000
+
+var bitrateLadderCodecFactors = map[string]float64{
+	"video/vp8":  1,
+	"video/h264": 1,
+	"video/vp9":  0.7,
+	"video/av1":  0.6,
+}
+
+type BitrateLadderParams struct {
+	// MimeType of the codec, e.g. "video/VP8".
+	MimeType string
+	// Width and Height of the captured video.
+	Width  int
+	Height int
+	// Framerate of the captured video, default 30.
+	Framerate float64
+	// MaxBitrate caps the highest layer, 0 means no cap.
+	MaxBitrate uint32
+}
+
+/**
+ * Recommend the encodings a client should produce with, following the
+ * mediasoup recommendations: up to 3 simulcast layers scaled down by 4, 2
+ * and 1 with temporal layers for VP8 (H264 without them since browsers don't
+ * support them), and a single SVC encoding for VP9 and AV1. Layers below 180p
+ * are skipped.
+ *
+ * @returns {[]RtpEncoding} lowest layer first.
+ * @throws {TypeError} if wrong arguments.
+ * @throws {UnsupportedError} if the codec is not a video codec.
+ */
+func RecommendBitrateLadder(params BitrateLadderParams) (encodings []RtpEncoding, err error) {
+	mimeType := ParseMimeType(params.MimeType).String()
+	factor, ok := bitrateLadderCodecFactors[mimeType]
+
+	if !ok {
+		err = NewUnsupportedError("unsupported codec %s", params.MimeType)
+		return
+	}
+	if params.Width <= 0 || params.Height <= 0 {
+		err = NewTypeError("invalid resolution [width:%d, height:%d]", params.Width, params.Height)
+		return
+	}
+	if params.Framerate <= 0 {
+		params.Framerate = 30
+	}
+
+	scales := []float64{4, 2, 1}
+
+	for len(scales) > 1 && float64(params.Height)/scales[0] < 180 {
+		scales = scales[1:]
+	}
+
+	bitrates := make([]uint32, len(scales))
+
+	for i, scale := range scales {
+		pixels := float64(params.Width) / scale * float64(params.Height) / scale
+		bitrate := uint32(pixels * params.Framerate * bitrateLadderBitsPerPixel * factor)
+
+		if bitrate < bitrateLadderMinBitrate {
+			bitrate = bitrateLadderMinBitrate
+		}
+		if params.MaxBitrate > 0 && bitrate > params.MaxBitrate {
+			bitrate = params.MaxBitrate
+		}
+
+		bitrates[i] = bitrate
+	}
+
+	switch mimeType {
+	case "video/vp9", "video/av1":
+		encodings = append(encodings, RtpEncoding{
+			MaxBitrate:      bitrates[len(bitrates)-1],
+			MaxFramerate:    params.Framerate,
+			ScalabilityMode: fmt.Sprintf("L%dT3_KEY", len(scales)),
+		})
+
+	default:
+		scalabilityMode := "L1T3"
+
+		if mimeType == "video/h264" {
+			scalabilityMode = "L1T1"
+		}
+
+		for i, scale := range scales {
+			encodings = append(encodings, RtpEncoding{
+				Rid:                   fmt.Sprintf("r%d", i),
+				MaxBitrate:            bitrates[i],
+				MaxFramerate:          params.Framerate,
+				ScaleResolutionDownBy: scale,
+				ScalabilityMode:       scalabilityMode,
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendBitrateLadder(t *testing.T) {
+	encodings, err := RecommendBitrateLadder(BitrateLadderParams{
+		MimeType: "video/VP8",
+		Width:    1280,
+		Height:   720,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, encodings, 3)
+
+	for i, scale := range []float64{4, 2, 1} {
+		assert.Equal(t, scale, encodings[i].ScaleResolutionDownBy)
+		assert.Equal(t, "L1T3", encodings[i].ScalabilityMode)
+		assert.Equal(t, float64(30), encodings[i].MaxFramerate)
+	}
+	assert.EqualValues(t, 172800, encodings[0].MaxBitrate)
+	assert.EqualValues(t, 691200, encodings[1].MaxBitrate)
+	assert.EqualValues(t, 2764800, encodings[2].MaxBitrate)
+
+	encodings, err = RecommendBitrateLadder(BitrateLadderParams{
+		MimeType:   "video/H264",
+		Width:      640,
+		Height:     360,
+		Framerate:  15,
+		MaxBitrate: 200000,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, encodings, 2)
+	assert.Equal(t, "r0", encodings[0].Rid)
+	assert.Equal(t, float64(2), encodings[0].ScaleResolutionDownBy)
+	assert.Equal(t, "L1T1", encodings[0].ScalabilityMode)
+	assert.EqualValues(t, 100000, encodings[0].MaxBitrate)
+	assert.EqualValues(t, 200000, encodings[1].MaxBitrate)
+
+	encodings, err = RecommendBitrateLadder(BitrateLadderParams{
+		MimeType: " Video/VP9 ",
+		Width:    1920,
+		Height:   1080,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, encodings, 1)
+	assert.Equal(t, "L3T3_KEY", encodings[0].ScalabilityMode)
+	assert.Zero(t, encodings[0].ScaleResolutionDownBy)
+
+	_, err = RecommendBitrateLadder(BitrateLadderParams{MimeType: "audio/opus", Width: 1, Height: 1})
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	_, err = RecommendBitrateLadder(BitrateLadderParams{MimeType: "video/VP8"})
+	assert.IsType(t, NewTypeError(""), err)
+}
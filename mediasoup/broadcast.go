@@ -0,0 +1,292 @@
+package mediasoup
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BroadcastSessionParams struct {
+	// ProducerId fanned out to the viewers.
+	ProducerId string
+	// TransportParams of the pre-created viewer WebRtcTransports.
+	TransportParams CreateWebRtcTransportParams
+	// PoolSize is the number of idle viewer transports kept ready by Warm(),
+	// default 0.
+	PoolSize int
+}
+
+// BroadcastConsumeRequest is a viewer request given to ConsumeBatch().
+type BroadcastConsumeRequest struct {
+	Transport       Transport
+	RtpCapabilities RtpCapabilities
+	Paused          bool
+	AppData         interface{}
+	// Token required if the Router has a ConsumeTokenValidator.
+	Token string
+}
+
+/**
+ * BroadcastSession optimizes the consume path of a single Producer fanned out
+ * to many viewers: consumer RTP parameters are computed and validated once per
+ * distinct RTP capabilities and reused with new SSRCs, viewer transports are
+ * created ahead of time, and viewers can be consumed in batches.
+ *
+ * @emits close
+ */
+type BroadcastSession struct {
+	EventEmitter
+	logger     logrus.FieldLogger
+	router     *Router
+	params     BroadcastSessionParams
+	producer   *Producer
+	locker     sync.Mutex
+	templates  map[string]RtpParameters
+	transports []*WebRtcTransport
+	consumers  map[string]*Consumer
+	closed     bool
+}
+
+// CreateBroadcastSession creates a BroadcastSession for the given Producer.
+func (router *Router) CreateBroadcastSession(params BroadcastSessionParams) (session *BroadcastSession, err error) {
+	router.logger.Debug("createBroadcastSession()")
+
+	producer := router.GetProducerById(params.ProducerId)
+
+	if producer == nil {
+		err = NewTypeError(`Producer with id "%s" not found`, params.ProducerId)
+		return
+	}
+
+	logger := TypeLogger("BroadcastSession")
+
+	session = &BroadcastSession{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		router:       router,
+		params:       params,
+		producer:     producer,
+		templates:    make(map[string]RtpParameters),
+		consumers:    make(map[string]*Consumer),
+	}
+
+	producer.Observer().On("close", session.Close)
+	router.Observer().On("close", session.Close)
+
+	return
+}
+
+// Producer fanned out.
+func (session *BroadcastSession) Producer() *Producer {
+	return session.producer
+}
+
+// Whether the BroadcastSession is closed.
+func (session *BroadcastSession) Closed() bool {
+	session.locker.Lock()
+	defer session.locker.Unlock()
+
+	return session.closed
+}
+
+// Viewers returns the number of open Consumers created by the session.
+func (session *BroadcastSession) Viewers() int {
+	session.locker.Lock()
+	defer session.locker.Unlock()
+
+	return len(session.consumers)
+}
+
+// Warm creates viewer transports until PoolSize of them are idle.
+func (session *BroadcastSession) Warm() (err error) {
+	session.logger.Debug("warm()")
+
+	for {
+		session.locker.Lock()
+		closed, idle := session.closed, len(session.transports)
+		session.locker.Unlock()
+
+		if closed {
+			return NewInvalidStateError("BroadcastSession closed")
+		}
+		if idle >= session.params.PoolSize {
+			return
+		}
+
+		transport, err := session.router.CreateWebRtcTransport(session.params.TransportParams)
+		if err != nil {
+			return err
+		}
+
+		session.locker.Lock()
+		session.transports = append(session.transports, transport)
+		session.locker.Unlock()
+	}
+}
+
+// AcquireTransport returns an idle viewer transport, created on demand if the
+// pool is empty. The transport belongs to the caller from now on.
+func (session *BroadcastSession) AcquireTransport() (transport *WebRtcTransport, err error) {
+	session.locker.Lock()
+
+	if session.closed {
+		session.locker.Unlock()
+		err = NewInvalidStateError("BroadcastSession closed")
+		return
+	}
+
+	for len(session.transports) > 0 && transport == nil {
+		transport = session.transports[0]
+		session.transports = session.transports[1:]
+
+		if transport.Closed() {
+			transport = nil
+		}
+	}
+
+	session.locker.Unlock()
+
+	if transport == nil {
+		transport, err = session.router.CreateWebRtcTransport(session.params.TransportParams)
+	}
+
+	return
+}
+
+// Consume creates a Consumer of the Producer in the viewer transport, reusing
+// the RTP parameters computed for the same RTP capabilities.
+func (session *BroadcastSession) Consume(request BroadcastConsumeRequest) (consumer *Consumer, err error) {
+	if session.Closed() {
+		err = NewInvalidStateError("BroadcastSession closed")
+		return
+	}
+	if request.Transport == nil {
+		err = NewTypeError("missing transport")
+		return
+	}
+
+	rtpParameters, err := session.consumerRtpParameters(request.RtpCapabilities)
+	if err != nil {
+		return
+	}
+
+	consumer, err = request.Transport.Consume(transportConsumeParams{
+		ProducerId:    session.producer.Id(),
+		Paused:        request.Paused,
+		AppData:       request.AppData,
+		Token:         request.Token,
+		rtpParameters: &rtpParameters,
+	})
+	if err != nil {
+		return
+	}
+
+	session.locker.Lock()
+	session.consumers[consumer.Id()] = consumer
+	session.locker.Unlock()
+
+	consumer.Observer().On("close", func() {
+		session.locker.Lock()
+		delete(session.consumers, consumer.Id())
+		session.locker.Unlock()
+	})
+
+	return
+}
+
+// ConsumeBatch consumes the Producer for every request, in order. The
+// returned slices have the same length as requests.
+func (session *BroadcastSession) ConsumeBatch(requests []BroadcastConsumeRequest) ([]*Consumer, []error) {
+	session.logger.Debugf("consumeBatch() [count:%d]", len(requests))
+
+	consumers := make([]*Consumer, len(requests))
+	errs := make([]error, len(requests))
+
+	for i, request := range requests {
+		consumers[i], errs[i] = session.Consume(request)
+	}
+
+	return consumers, errs
+}
+
+// Close closes the idle transports. Consumers and acquired transports are
+// left to their owners.
+func (session *BroadcastSession) Close() {
+	session.locker.Lock()
+
+	if session.closed {
+		session.locker.Unlock()
+		return
+	}
+
+	session.logger.Debug("close()")
+
+	session.closed = true
+	transports := session.transports
+	session.transports = nil
+	session.templates = make(map[string]RtpParameters)
+	session.locker.Unlock()
+
+	for _, transport := range transports {
+		transport.Close()
+	}
+
+	session.SafeEmit("close")
+}
+
+// consumerRtpParameters returns the cached RTP parameters for the
+// capabilities with new SSRCs.
+func (session *BroadcastSession) consumerRtpParameters(caps RtpCapabilities) (params RtpParameters, err error) {
+	key, err := rtpCapabilitiesKey(caps)
+	if err != nil {
+		return
+	}
+
+	session.locker.Lock()
+	template, ok := session.templates[key]
+	session.locker.Unlock()
+
+	if !ok {
+		if template, err = GetConsumerRtpParameters(
+			session.producer.ConsumableRtpParameters(), caps); err != nil {
+			return
+		}
+
+		session.locker.Lock()
+		session.templates[key] = template
+		session.locker.Unlock()
+	}
+
+	return newRtpParametersFromTemplate(template), nil
+}
+
+// newRtpParametersFromTemplate copies the template with new SSRCs.
+func newRtpParametersFromTemplate(template RtpParameters) RtpParameters {
+	params := template
+	params.Encodings = make([]RtpEncoding, 0, len(template.Encodings))
+
+	for _, encoding := range template.Encodings {
+		encoding.Ssrc = generateRandomNumber()
+
+		if encoding.Rtx != nil {
+			encoding.Rtx = &RtpEncoding{Ssrc: generateRandomNumber()}
+		}
+
+		params.Encodings = append(params.Encodings, encoding)
+	}
+
+	return params
+}
+
+func rtpCapabilitiesKey(caps RtpCapabilities) (string, error) {
+	data, err := caps.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,77 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRtpParametersFromTemplate(t *testing.T) {
+	template := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+		},
+		Encodings: []RtpEncoding{
+			{Ssrc: 1111, Rtx: &RtpEncoding{Ssrc: 2222}},
+		},
+	}
+
+	params := newRtpParametersFromTemplate(template)
+
+	assert.Equal(t, template.Codecs, params.Codecs)
+	assert.Len(t, params.Encodings, 1)
+	assert.NotEqual(t, uint32(1111), params.Encodings[0].Ssrc)
+	assert.NotEqual(t, uint32(2222), params.Encodings[0].Rtx.Ssrc)
+	assert.EqualValues(t, 1111, template.Encodings[0].Ssrc)
+	assert.EqualValues(t, 2222, template.Encodings[0].Rtx.Ssrc)
+}
+
+func TestBroadcastSession(t *testing.T) {
+	ns := setupPipeTest(t)
+	defer ns.router1.Close()
+	defer ns.router2.Close()
+
+	session, err := ns.router1.CreateBroadcastSession(BroadcastSessionParams{
+		ProducerId: ns.audioProducer.Id(),
+		TransportParams: CreateWebRtcTransportParams{
+			ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+		},
+		PoolSize: 2,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Warm())
+
+	var requests []BroadcastConsumeRequest
+
+	for i := 0; i < 3; i++ {
+		transport, err := session.AcquireTransport()
+		assert.NoError(t, err)
+
+		requests = append(requests, BroadcastConsumeRequest{
+			Transport:       transport,
+			RtpCapabilities: ns.router1.RtpCapabilities(),
+		})
+	}
+
+	consumers, errs := session.ConsumeBatch(requests)
+	assert.Len(t, consumers, 3)
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.Len(t, session.templates, 1)
+	assert.Equal(t, 3, session.Viewers())
+	assert.NotEqual(t,
+		consumers[0].RtpParameters().Encodings[0].Ssrc,
+		consumers[1].RtpParameters().Encodings[0].Ssrc)
+
+	consumers[0].Close()
+	assert.Equal(t, 2, session.Viewers())
+
+	_, err = ns.router1.CreateBroadcastSession(BroadcastSessionParams{ProducerId: "unknown"})
+	assert.IsType(t, NewTypeError(""), err)
+
+	ns.audioProducer.Close()
+	assert.True(t, session.Closed())
+
+	_, err = session.AcquireTransport()
+	assert.IsType(t, NewInvalidStateError(""), err)
+}
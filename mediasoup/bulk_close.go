@@ -0,0 +1,115 @@
+package mediasoup
+
+import (
+	"sort"
+	"time"
+)
+
+// BulkCloseResult is the summary of CloseAllTransports() and
+// CloseAllConsumers().
+type BulkCloseResult struct {
+	// Closed ids, in closing order.
+	Closed []string
+	// Errors by id of the entities the worker failed to close.
+	Errors map[string]error
+	// Elapsed time of the whole operation.
+	Elapsed time.Duration
+}
+
+/**
+ * Close all the Transports of the Router with a single batch of worker
+ * requests, e.g. when tearing down a large room. Transports are closed in
+ * ascending id order: the worker processes the requests in that order and
+ * the close events of the Transports are emitted in that order too, before
+ * the summary event.
+ *
+ * @emits {BulkCloseResult} transportsclose
+ */
+func (router *Router) CloseAllTransports() (result BulkCloseResult) {
+	router.logger.Debug("closeAllTransports()")
+
+	transports := router.Transports()
+
+	ids := make([]string, 0, len(transports))
+	requests := make([]channelRequest, 0, len(transports))
+	closers := make([]func(), 0, len(transports))
+
+	for _, transport := range transports {
+		if request := transport.prepareClose(); request != nil {
+			ids = append(ids, transport.Id())
+			requests = append(requests, *request)
+			closers = append(closers, transport.finishClose)
+		}
+	}
+
+	result = bulkClose(router.channel, ids, requests, closers)
+
+	router.SafeEmit("transportsclose", result)
+
+	// Emit observer event.
+	router.observer.SafeEmit("transportsclose", result)
+
+	return
+}
+
+/**
+ * Close all the Consumers of the Transport with a single batch of worker
+ * requests. Consumers are closed in ascending id order, as described in
+ * Router.CloseAllTransports().
+ *
+ * @emits {BulkCloseResult} consumersclose
+ */
+func (transport *baseTransport) CloseAllConsumers() (result BulkCloseResult) {
+	transport.logger.Debug("closeAllConsumers()")
+
+	consumers := transport.consumerList()
+
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].Id() < consumers[j].Id()
+	})
+
+	ids := make([]string, 0, len(consumers))
+	requests := make([]channelRequest, 0, len(consumers))
+	closers := make([]func(), 0, len(consumers))
+
+	for _, consumer := range consumers {
+		if request := consumer.prepareClose(); request != nil {
+			ids = append(ids, consumer.Id())
+			requests = append(requests, *request)
+			closers = append(closers, consumer.finishClose)
+		}
+	}
+
+	result = bulkClose(transport.channel, ids, requests, closers)
+
+	transport.SafeEmit("consumersclose", result)
+
+	// Emit observer event.
+	transport.observer.SafeEmit("consumersclose", result)
+
+	return
+}
+
+// bulkClose sends the close requests in a batch and calls the closer of
+// every entity closed by the worker, in order.
+func bulkClose(channel *Channel, ids []string, requests []channelRequest, closers []func()) (result BulkCloseResult) {
+	start := time.Now()
+
+	result.Closed = []string{}
+	result.Errors = map[string]error{}
+
+	for i, response := range channel.RequestBatch(requests) {
+		if err := response.Err(); err != nil {
+			result.Errors[ids[i]] = err
+			continue
+		}
+
+		closers[i]()
+
+		result.Closed = append(result.Closed, ids[i])
+	}
+
+	result.Elapsed = time.Since(start)
+
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/jiyeyuran/mediasoup-go/mediasoup/netstring"
@@ -19,11 +20,14 @@ const (
 type sentInfo struct {
 	id         int64
 	method     string
+	trace      string
 	responseCh chan Response
 }
 
 type Channel struct {
 	EventEmitter
+	// Guards closed, nextId and sents.
+	locker       sync.Mutex
 	socket       net.Conn
 	logger       logrus.FieldLogger
 	workerLogger logrus.FieldLogger
@@ -31,6 +35,7 @@ type Channel struct {
 	nextId       int64
 	sents        map[int64]sentInfo
 	closeCh      chan struct{}
+	timeouts     RequestTimeouts
 }
 
 func NewChannel(socket net.Conn, pid int) *Channel {
@@ -44,6 +49,7 @@ func NewChannel(socket net.Conn, pid int) *Channel {
 		workerLogger: workerLogger,
 		sents:        make(map[int64]sentInfo),
 		closeCh:      make(chan struct{}),
+		timeouts:     DefaultRequestTimeouts(),
 	}
 
 	go channel.runReadLoop()
@@ -54,15 +60,25 @@ func NewChannel(socket net.Conn, pid int) *Channel {
 }
 
 func (c *Channel) Close() {
+	c.locker.Lock()
 	if c.closed {
+		c.locker.Unlock()
 		return
 	}
+	c.closed = true
+	c.locker.Unlock()
 
 	c.logger.Debugln("close()")
 
 	c.socket.Close()
+}
 
-	c.closed = true
+// Closed tells whether the Channel is closed.
+func (c *Channel) Closed() bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.closed
 }
 
 func (c *Channel) Request(
@@ -70,48 +86,47 @@ func (c *Channel) Request(
 	internal interface{},
 	data ...interface{},
 ) (rsp Response) {
-	if c.nextId < 4294967295 {
-		c.nextId++
-	} else {
-		c.nextId = 1
-	}
+	c.locker.Lock()
+	id := c.newRequestId()
+	c.locker.Unlock()
 
-	id := c.nextId
+	trace := traceSuffix(internal)
 
-	c.logger.Debugf("request() [method:%s, id:%d]", method, id)
+	c.logger.Debugf("request() [method:%s, id:%d%s]", method, id, trace)
 
-	if c.closed {
+	if c.Closed() {
 		rsp.err = NewInvalidStateError("Channel closed")
 		return
 	}
 
 	sent := sentInfo{
-		id:         id,
-		method:     method,
-		responseCh: make(chan Response),
+		id:     id,
+		method: method,
+		trace:  trace,
+		// Buffered so a response arriving with the timeout does not block the
+		// read loop.
+		responseCh: make(chan Response, 1),
 	}
+
+	c.locker.Lock()
 	c.sents[id] = sent
+	pending := len(c.sents)
+	c.locker.Unlock()
 
-	defer delete(c.sents, id)
+	defer func() {
+		c.locker.Lock()
+		delete(c.sents, id)
+		c.locker.Unlock()
+	}()
 
-	req := struct {
-		Id       int64       `json:"id"`
-		Method   string      `json:"method,omitempty"`
-		Internal interface{} `json:"internal,omitempty"`
-		Data     interface{} `json:"data,omitempty"`
-	}{
-		Id:       id,
-		Method:   method,
-		Internal: internal,
-	}
+	var reqData interface{}
 	if len(data) > 0 {
-		req.Data = data[0]
+		reqData = data[0]
 	}
-	rawData, _ := json.Marshal(req)
 
-	ns := netstring.Encode(rawData)
-	if len(ns) > NS_MESSAGE_MAX_LEN {
-		rsp.err = errors.New("Channel request too big")
+	ns, err := encodeRequest(id, method, internal, reqData)
+	if err != nil {
+		rsp.err = err
 		return
 	}
 
@@ -120,15 +135,14 @@ func (c *Channel) Request(
 		return
 	}
 
-	timeout := 1000 * (15 + (0.1 * float64(len(c.sents))))
-	timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+	timer := time.NewTimer(c.timeouts.Timeout(method, pending))
 	defer timer.Stop()
 
 	select {
 	case rsp = <-sent.responseCh:
 		return
 	case <-timer.C:
-		rsp.err = errors.New("Channel request timeout")
+		rsp.err = fmt.Errorf("Channel request timeout [method:%s, id:%d%s]", method, id, trace)
 	case <-c.closeCh:
 		rsp.err = errors.New("Channel closed")
 	}
@@ -136,6 +150,199 @@ func (c *Channel) Request(
 	return
 }
 
+// channelRequest is a request given to Channel.RequestBatch().
+type channelRequest struct {
+	Method   string
+	Internal interface{}
+	Data     interface{}
+}
+
+/**
+ * Send the requests with a single write, so the worker processes them in
+ * order without waiting for each response, then wait for all the responses.
+ *
+ * @returns {[]Response} - In the order of the requests.
+ */
+func (c *Channel) RequestBatch(requests []channelRequest) []Response {
+	responses := make([]Response, len(requests))
+
+	if len(requests) == 0 {
+		return responses
+	}
+
+	c.logger.Debugf("requestBatch() [count:%d]", len(requests))
+
+	if c.Closed() {
+		for i := range responses {
+			responses[i].err = NewInvalidStateError("Channel closed")
+		}
+		return responses
+	}
+
+	sents := make([]sentInfo, len(requests))
+	buf := []byte{}
+
+	c.locker.Lock()
+
+	for i, request := range requests {
+		sents[i] = sentInfo{
+			id:     c.newRequestId(),
+			method: request.Method,
+			trace:  traceSuffix(request.Internal),
+			// Buffered so responses are not blocked until their turn.
+			responseCh: make(chan Response, 1),
+		}
+
+		c.logger.Debugf("request() [method:%s, id:%d%s]", sents[i].method, sents[i].id, sents[i].trace)
+
+		ns, err := encodeRequest(sents[i].id, request.Method, request.Internal, request.Data)
+		if err != nil {
+			responses[i].err = err
+			continue
+		}
+
+		c.sents[sents[i].id] = sents[i]
+		buf = append(buf, ns...)
+	}
+
+	pending := len(c.sents)
+	c.locker.Unlock()
+
+	defer func() {
+		c.locker.Lock()
+		for _, sent := range sents {
+			delete(c.sents, sent.id)
+		}
+		c.locker.Unlock()
+	}()
+
+	if _, err := c.socket.Write(buf); err != nil {
+		for i := range responses {
+			if responses[i].err == nil {
+				responses[i].err = err
+			}
+		}
+		return responses
+	}
+
+	// The whole batch is given the longest timeout of its requests.
+	var timeout time.Duration
+
+	for _, request := range requests {
+		if t := c.timeouts.Timeout(request.Method, pending); t > timeout {
+			timeout = t
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for i, sent := range sents {
+		if responses[i].err != nil {
+			continue
+		}
+
+		select {
+		case responses[i] = <-sent.responseCh:
+		case <-timer.C:
+			for ; i < len(responses); i++ {
+				if responses[i].err == nil {
+					responses[i].err = fmt.Errorf("Channel request timeout [method:%s, id:%d%s]",
+						sents[i].method, sents[i].id, sents[i].trace)
+				}
+			}
+			return responses
+		case <-c.closeCh:
+			for ; i < len(responses); i++ {
+				if responses[i].err == nil {
+					responses[i].err = errors.New("Channel closed")
+				}
+			}
+			return responses
+		}
+	}
+
+	return responses
+}
+
+// Notify sends a notification to the worker, which doesn't answer it.
+func (c *Channel) Notify(event string, internal interface{}, data ...interface{}) error {
+	c.logger.Debugf("notify() [event:%s%s]", event, traceSuffix(internal))
+
+	if c.Closed() {
+		return NewInvalidStateError("Channel closed")
+	}
+
+	var notificationData interface{}
+	if len(data) > 0 {
+		notificationData = data[0]
+	}
+
+	ns, err := encodeNotification(event, internal, notificationData)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.socket.Write(ns)
+
+	return err
+}
+
+func (c *Channel) newRequestId() int64 {
+	if c.nextId < 4294967295 {
+		c.nextId++
+	} else {
+		c.nextId = 1
+	}
+
+	return c.nextId
+}
+
+func encodeRequest(id int64, method string, internal, data interface{}) ([]byte, error) {
+	req := struct {
+		Id       int64       `json:"id"`
+		Method   string      `json:"method,omitempty"`
+		Internal interface{} `json:"internal,omitempty"`
+		Data     interface{} `json:"data,omitempty"`
+	}{
+		Id:       id,
+		Method:   method,
+		Internal: internal,
+		Data:     data,
+	}
+	rawData, _ := json.Marshal(req)
+
+	ns := netstring.Encode(rawData)
+	if len(ns) > NS_MESSAGE_MAX_LEN {
+		return nil, errors.New("Channel request too big")
+	}
+
+	return ns, nil
+}
+
+func encodeNotification(event string, internal, data interface{}) ([]byte, error) {
+	notification := struct {
+		Event    string      `json:"event"`
+		Internal interface{} `json:"internal,omitempty"`
+		Data     interface{} `json:"data,omitempty"`
+	}{
+		Event:    event,
+		Internal: internal,
+		Data:     data,
+	}
+	rawData, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := netstring.Encode(rawData)
+	if len(ns) > NS_MESSAGE_MAX_LEN {
+		return nil, errors.New("Channel notification too big")
+	}
+
+	return ns, nil
+}
+
 func (c *Channel) runReadLoop() {
 	decoder := netstring.NewDecoder()
 
@@ -168,7 +375,10 @@ func (c *Channel) runReadLoop() {
 		}
 	}
 
+	c.locker.Lock()
 	c.closed = true
+	c.locker.Unlock()
+
 	close(c.closeCh)
 }
 
@@ -195,7 +405,10 @@ func (c *Channel) processMessage(nsPayload []byte) {
 	json.Unmarshal(nsPayload, &msg)
 
 	if msg.Id > 0 {
+		c.locker.Lock()
 		sent, ok := c.sents[msg.Id]
+		c.locker.Unlock()
+
 		if !ok {
 			c.logger.Errorf("received response does not match any sent request [id:%d]", msg.Id)
 			return
@@ -210,14 +423,19 @@ func (c *Channel) processMessage(nsPayload []byte) {
 		json.Unmarshal(nsPayload, &msg)
 
 		if msg.Accepted {
-			c.logger.Debugf("request succeeded [method:%s, id:%d]", sent.method, sent.id)
+			c.logger.Debugf("request succeeded [method:%s, id:%d%s]", sent.method, sent.id, sent.trace)
 
 			sent.responseCh <- Response{data: msg.Data}
 		} else if len(msg.Error) > 0 {
-			c.logger.Warnf("request failed [method:%s, id:%d]: %s",
-				sent.method, sent.id, msg.Reason)
-
-			sent.responseCh <- Response{err: NewTypeError(msg.Reason)}
+			c.logger.Warnf("request failed [method:%s, id:%d%s]: %s",
+				sent.method, sent.id, sent.trace, msg.Reason)
+
+			if len(sent.trace) > 0 {
+				sent.responseCh <- Response{err: NewTypeError("%s [method:%s%s]",
+					msg.Reason, sent.method, sent.trace)}
+			} else {
+				sent.responseCh <- Response{err: NewTypeError(msg.Reason)}
+			}
 		}
 	} else if len(msg.TargetId) > 0 {
 		var notification struct {
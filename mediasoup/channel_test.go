@@ -0,0 +1,196 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/netstring"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestChannel returns a Channel to a fake worker answering every request
+// with its method in order, failing it if the method is "consumer.fail" and
+// never answering "*.hang" methods.
+func newTestChannel() *Channel {
+	return newTestChannelWith(func(string) {})
+}
+
+// newRecordingTestChannel returns a test Channel and the methods of the
+// requests it received so far, in order.
+func newRecordingTestChannel() (channel *Channel, methods func() []string) {
+	var locker sync.Mutex
+	var received []string
+
+	channel = newTestChannelWith(func(method string) {
+		locker.Lock()
+		defer locker.Unlock()
+
+		received = append(received, method)
+	})
+
+	return channel, func() []string {
+		locker.Lock()
+		defer locker.Unlock()
+
+		return append([]string{}, received...)
+	}
+}
+
+// newTestChannelWith is newTestChannel calling onRequest with the method of
+// every request before answering it.
+func newTestChannelWith(onRequest func(method string)) *Channel {
+	return newFailingTestChannel(func(method string) bool {
+		onRequest(method)
+		return false
+	})
+}
+
+// newFailingTestChannel is newTestChannel also failing the requests whose
+// method fail returns true for. fail is called for every request before
+// answering it.
+func newFailingTestChannel(fail func(method string) bool) *Channel {
+	socket, workerSocket := net.Pipe()
+
+	go func() {
+		defer workerSocket.Close()
+
+		decoder := netstring.NewDecoder()
+		buf := make([]byte, NS_PAYLOAD_MAX_LEN)
+
+		go func() {
+			for payload := range decoder.Result() {
+				var req struct {
+					Id     int64
+					Method string
+				}
+				json.Unmarshal(payload, &req)
+				failed := fail(req.Method)
+
+				var rsp H
+				if strings.HasSuffix(req.Method, ".hang") {
+					continue
+				} else if req.Method == "consumer.fail" || failed {
+					rsp = H{"id": req.Id, "error": "Error", "reason": "failed"}
+				} else {
+					rsp = H{"id": req.Id, "accepted": true, "data": H{"method": req.Method}}
+				}
+				data, _ := json.Marshal(rsp)
+				workerSocket.Write(netstring.Encode(data))
+			}
+		}()
+
+		for {
+			n, err := workerSocket.Read(buf)
+			if err != nil {
+				return
+			}
+			decoder.Feed(buf[:n])
+		}
+	}()
+
+	return NewChannel(socket, 0)
+}
+
+func TestChannelRequestBatch(t *testing.T) {
+	channel := newTestChannel()
+	defer channel.Close()
+
+	responses := channel.RequestBatch([]channelRequest{
+		{Method: "consumer.close", Internal: internalData{ConsumerId: "c1"}},
+		{Method: "consumer.fail", Internal: internalData{ConsumerId: "c2"}},
+		{Method: "transport.close", Internal: internalData{TransportId: "t1"}},
+	})
+	assert.Len(t, responses, 3)
+
+	var result struct{ Method string }
+
+	assert.NoError(t, responses[0].Unmarshal(&result))
+	assert.Equal(t, "consumer.close", result.Method)
+	assert.EqualError(t, responses[1].Err(), "failed")
+	assert.NoError(t, responses[2].Unmarshal(&result))
+	assert.Equal(t, "transport.close", result.Method)
+	channel.locker.Lock()
+	assert.Empty(t, channel.sents)
+	channel.locker.Unlock()
+
+	assert.Empty(t, channel.RequestBatch(nil))
+}
+
+func TestChannelRequest_TraceIds(t *testing.T) {
+	channel := newTestChannel()
+	defer channel.Close()
+
+	internal := internalData{
+		ConsumerId: "c1",
+		Trace:      TraceIds{"room": "r1", "peer": "p1"},
+	}
+
+	err := channel.Request("consumer.fail", internal).Err()
+	assert.IsType(t, NewTypeError(""), err)
+	assert.EqualError(t, err, "failed [method:consumer.fail, peer:p1, room:r1]")
+
+	err = channel.Request("consumer.fail", internalData{ConsumerId: "c1"}).Err()
+	assert.EqualError(t, err, "failed")
+
+	responses := channel.RequestBatch([]channelRequest{{Method: "consumer.fail", Internal: internal}})
+	assert.EqualError(t, responses[0].Err(), "failed [method:consumer.fail, peer:p1, room:r1]")
+}
+
+func TestChannelRequest_Timeout(t *testing.T) {
+	channel := newTestChannel()
+	defer channel.Close()
+
+	channel.timeouts = RequestTimeouts{
+		Cheap:             10 * time.Millisecond,
+		Default:           10 * time.Millisecond,
+		PerPendingRequest: time.Millisecond,
+	}
+
+	start := time.Now()
+	err := channel.Request("consumer.hang", internalData{}).Err()
+	assert.EqualError(t, err, "Channel request timeout [method:consumer.hang, id:1]")
+	assert.True(t, time.Since(start) < time.Second)
+
+	responses := channel.RequestBatch([]channelRequest{
+		{Method: "consumer.close"},
+		{Method: "consumer.hang"},
+	})
+	assert.NoError(t, responses[0].Err())
+	assert.EqualError(t, responses[1].Err(), "Channel request timeout [method:consumer.hang, id:3]")
+}
+
+func TestChannelNotify(t *testing.T) {
+	socket, workerSocket := net.Pipe()
+	channel := NewChannel(socket, 0)
+	defer channel.Close()
+
+	received := make(chan []byte, 1)
+
+	go func() {
+		decoder := netstring.NewDecoder()
+		buf := make([]byte, NS_PAYLOAD_MAX_LEN)
+
+		n, _ := workerSocket.Read(buf)
+		decoder.Feed(buf[:n])
+		received <- <-decoder.Result()
+	}()
+
+	err := channel.Notify("consumer.requestKeyFrame", internalData{ConsumerId: "c1"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"event":"consumer.requestKeyFrame","internal":{"consumerId":"c1"}}`, string(<-received))
+
+	workerSocket.Close()
+
+	select {
+	case <-channel.closeCh:
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed")
+	}
+
+	assert.True(t, channel.Closed())
+	assert.IsType(t, NewInvalidStateError(""), channel.Notify("worker.close", nil))
+}
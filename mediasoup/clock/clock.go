@@ -0,0 +1,76 @@
+// Package clock abstracts the time functions used by the periodic subsystems
+// (stats recorder, leak watcher, webhook...), so tests can drive them with a
+// fake clock instead of sleeping.
+package clock
+
+import "time"
+
+// Clock tells the time and creates tickers and timers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	Sleep(d time.Duration)
+}
+
+// Ticker is the interface of *time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is the interface of *time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// System is the Clock of the time package.
+var System Clock = systemClock{}
+
+// OrSystem returns c, or System if c is nil.
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+// NowMs returns the time of the clock in milliseconds.
+func NowMs(c Clock) int64 {
+	return c.Now().UnixNano() / int64(time.Millisecond)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,74 @@
+/**
+ * Command ortcconformance reports what a browser will negotiate with a Router.
+ *
+ *	ortcconformance -device device.json -router router.json [-json]
+ *
+ * device.json holds {"audio": ..., "video": ...}, the results of
+ * RTCRtpReceiver.getCapabilities("audio") and ("video") in the browser, and
+ * router.json the RTP capabilities of the Router, as given to
+ * mediasoup-client Device.load().
+ */
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/conformance"
+)
+
+func main() {
+	devicePath := flag.String("device", "", "JSON of the browser RTCRtpReceiver.getCapabilities() by kind")
+	routerPath := flag.String("router", "", "JSON of the Router RTP capabilities")
+	asJson := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if len(*devicePath) == 0 || len(*routerPath) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var device conformance.DeviceCapabilities
+	var routerCaps mediasoup.RtpCapabilities
+
+	if err := readJson(*devicePath, &device); err != nil {
+		fail(err)
+	}
+	if err := readJson(*routerPath, &routerCaps); err != nil {
+		fail(err)
+	}
+
+	report, err := conformance.Check(device, routerCaps)
+	if err != nil {
+		fail(err)
+	}
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+		return
+	}
+
+	fmt.Print(report)
+}
+
+func readJson(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,118 @@
+package mediasoup
+
+import (
+	h264 "github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+)
+
+// Codec presets for GenerateRouterRtpCapabilities() and CreateRouter(). Every
+// call returns new slices, which may be modified by the caller.
+
+// DefaultAudioOnlyCodecs returns Opus with in-band FEC, for audio rooms and
+// voice bridges.
+func DefaultAudioOnlyCodecs() []RtpCodecCapability {
+	return []RtpCodecCapability{
+		presetOpusCodec(),
+	}
+}
+
+/**
+ * WebinarCodecs returns Opus, VP8 and H264 constrained baseline, which every
+ * browser can both send and decode in hardware or software, so a presenter
+ * can be consumed by a large audience without any codec mismatch.
+ */
+func WebinarCodecs() []RtpCodecCapability {
+	return []RtpCodecCapability{
+		presetOpusCodec(),
+		{
+			Kind:      "video",
+			MimeType:  "video/VP8",
+			ClockRate: 90000,
+			Parameters: &RtpCodecParameter{
+				XGoogleStartBitrate: 1000,
+			},
+		},
+		presetH264Codec("42e01f", 1),
+	}
+}
+
+/**
+ * CompatibilityMaxCodecs returns the widest vetted set, for rooms mixing
+ * browsers, native SDKs, SIP gateways and hardware encoders: Opus, PCMU,
+ * PCMA, VP8, VP9 profiles 0 and 2, H264 baseline (both packetization modes)
+ * and main, H265 and AV1.
+ */
+func CompatibilityMaxCodecs() []RtpCodecCapability {
+	vp9Profile2 := uint8(2)
+
+	return []RtpCodecCapability{
+		presetOpusCodec(),
+		{
+			Kind:      "audio",
+			MimeType:  "audio/PCMU",
+			ClockRate: 8000,
+		},
+		{
+			Kind:      "audio",
+			MimeType:  "audio/PCMA",
+			ClockRate: 8000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/VP8",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/VP9",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/VP9",
+			ClockRate: 90000,
+			Parameters: &RtpCodecParameter{
+				ProfileId: &vp9Profile2,
+			},
+		},
+		presetH264Codec("42e01f", 1),
+		presetH264Codec("42e01f", 0),
+		presetH264Codec("4d0032", 1),
+		{
+			Kind:      "video",
+			MimeType:  "video/H265",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/AV1",
+			ClockRate: 90000,
+		},
+	}
+}
+
+func presetOpusCodec() RtpCodecCapability {
+	return RtpCodecCapability{
+		Kind:      "audio",
+		MimeType:  "audio/opus",
+		ClockRate: 48000,
+		Channels:  2,
+		Parameters: &RtpCodecParameter{
+			Useinbandfec: 1,
+		},
+	}
+}
+
+func presetH264Codec(profileLevelId string, packetizationMode int) RtpCodecCapability {
+	return RtpCodecCapability{
+		Kind:      "video",
+		MimeType:  "video/H264",
+		ClockRate: 90000,
+		Parameters: &RtpCodecParameter{
+			RtpH264Parameter: h264.RtpH264Parameter{
+				PacketizationMode:     packetizationMode,
+				ProfileLevelId:        profileLevelId,
+				LevelAsymmetryAllowed: 1,
+			},
+		},
+	}
+}
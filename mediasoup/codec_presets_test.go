@@ -0,0 +1,33 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecPresets(t *testing.T) {
+	presets := map[string]func() []RtpCodecCapability{
+		"DefaultAudioOnlyCodecs": DefaultAudioOnlyCodecs,
+		"WebinarCodecs":          WebinarCodecs,
+		"CompatibilityMaxCodecs": CompatibilityMaxCodecs,
+	}
+
+	for name, preset := range presets {
+		caps, err := GenerateRouterRtpCapabilities(preset())
+		assert.NoError(t, err, name)
+		assert.NotEmpty(t, caps.Codecs, name)
+	}
+
+	caps, _ := GenerateRouterRtpCapabilities(DefaultAudioOnlyCodecs())
+
+	for _, codec := range caps.Codecs {
+		assert.EqualValues(t, "audio", codec.Kind)
+	}
+
+	// Presets are not shared between calls.
+	codecs := WebinarCodecs()
+	codecs[0].Parameters.Useinbandfec = 0
+
+	assert.EqualValues(t, 1, WebinarCodecs()[0].Parameters.Useinbandfec)
+}
@@ -0,0 +1,254 @@
+/**
+ * Package conformance reports what a browser will negotiate with a Router, as
+ * computed by mediasoup-client, from the RTCRtpReceiver.getCapabilities()
+ * results of the browser. It lets support engineers troubleshoot client
+ * compatibility without reproducing calls, see cmd/ortcconformance.
+ */
+package conformance
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/sdp"
+)
+
+// BrowserCodec is a codec of RTCRtpReceiver.getCapabilities().
+type BrowserCodec struct {
+	MimeType    string `json:"mimeType"`
+	ClockRate   int    `json:"clockRate"`
+	Channels    int    `json:"channels,omitempty"`
+	SdpFmtpLine string `json:"sdpFmtpLine,omitempty"`
+}
+
+// BrowserHeaderExtension is a header extension of
+// RTCRtpReceiver.getCapabilities().
+type BrowserHeaderExtension struct {
+	Uri string `json:"uri"`
+}
+
+// BrowserCapabilities is the result of RTCRtpReceiver.getCapabilities(kind).
+type BrowserCapabilities struct {
+	Codecs           []BrowserCodec           `json:"codecs"`
+	HeaderExtensions []BrowserHeaderExtension `json:"headerExtensions"`
+}
+
+// DeviceCapabilities are the browser capabilities by kind, i.e. the JSON of
+// {audio: RTCRtpReceiver.getCapabilities("audio"), video: ...}.
+type DeviceCapabilities map[mediasoup.MediaKind]BrowserCapabilities
+
+// First dynamic payload type given to the browser codecs, which have none.
+const firstDevicePayloadType = 96
+
+/**
+ * RtpCapabilities converts the browser capabilities to the ones
+ * mediasoup-client would get from the browser SDP. Payload types and header
+ * extension ids are made up. RTCRtpReceiver.getCapabilities() lists RTX once,
+ * so it is associated to every video codec.
+ */
+func (device DeviceCapabilities) RtpCapabilities() (caps mediasoup.RtpCapabilities, err error) {
+	payloadType, extId := firstDevicePayloadType, 1
+
+	for _, kind := range []mediasoup.MediaKind{mediasoup.MediaKindAudio, mediasoup.MediaKindVideo} {
+		browserCaps := device[kind]
+		hasRtx := false
+		var mediaCodecs []mediasoup.RtpCodecCapability
+
+		for _, browserCodec := range browserCaps.Codecs {
+			mimeType := mediasoup.ParseMimeType(browserCodec.MimeType)
+
+			if mimeType.IsRtx() {
+				hasRtx = true
+				continue
+			}
+
+			codec := mediasoup.RtpCodecCapability{
+				Kind:                 kind,
+				MimeType:             browserCodec.MimeType,
+				ClockRate:            browserCodec.ClockRate,
+				Channels:             browserCodec.Channels,
+				PreferredPayloadType: payloadType,
+			}
+			payloadType++
+
+			if len(browserCodec.SdpFmtpLine) > 0 {
+				if codec.Parameters, err = sdp.ParseFmtp(browserCodec.SdpFmtpLine); err != nil {
+					return caps, fmt.Errorf("%s: %s", browserCodec.MimeType, err)
+				}
+			}
+
+			mediaCodecs = append(mediaCodecs, codec)
+		}
+
+		for _, codec := range mediaCodecs {
+			caps.Codecs = append(caps.Codecs, codec)
+
+			if hasRtx && kind == mediasoup.MediaKindVideo {
+				caps.Codecs = append(caps.Codecs, mediasoup.RtpCodecCapability{
+					Kind:                 kind,
+					MimeType:             "video/rtx",
+					ClockRate:            codec.ClockRate,
+					PreferredPayloadType: payloadType,
+					Parameters:           &mediasoup.RtpCodecParameter{Apt: codec.PreferredPayloadType},
+				})
+				payloadType++
+			}
+		}
+
+		for _, ext := range browserCaps.HeaderExtensions {
+			caps.HeaderExtensions = append(caps.HeaderExtensions, mediasoup.RtpHeaderExtension{
+				Kind:        kind,
+				Uri:         ext.Uri,
+				PreferredId: extId,
+			})
+			extId++
+		}
+	}
+
+	return
+}
+
+// CodecReport tells whether a Router codec will be negotiated.
+type CodecReport struct {
+	Kind      mediasoup.MediaKind `json:"kind"`
+	MimeType  string              `json:"mimeType"`
+	ClockRate int                 `json:"clockRate"`
+	Channels  int                 `json:"channels,omitempty"`
+	// PayloadType of the Router codec.
+	PayloadType int  `json:"payloadType"`
+	Negotiated  bool `json:"negotiated"`
+	// Rtx is set if RTX will be used along the codec.
+	Rtx bool `json:"rtx,omitempty"`
+	// Parameters of the answer, e.g. the H264 profile-level-id.
+	Parameters *mediasoup.RtpCodecParameter `json:"parameters,omitempty"`
+	// Reason why the codec will not be negotiated.
+	Reason string `json:"reason,omitempty"`
+}
+
+// HeaderExtensionReport tells whether a Router header extension will be
+// negotiated.
+type HeaderExtensionReport struct {
+	Kind       mediasoup.MediaKind `json:"kind"`
+	Uri        string              `json:"uri"`
+	Id         int                 `json:"id"`
+	Negotiated bool                `json:"negotiated"`
+	// Direction for the device, e.g. "recvonly".
+	Direction string `json:"direction,omitempty"`
+}
+
+type Report struct {
+	Codecs           []CodecReport           `json:"codecs"`
+	HeaderExtensions []HeaderExtensionReport `json:"headerExtensions"`
+}
+
+/**
+ * Check reports, for every codec and header extension of the Router, whether
+ * the device will negotiate it, as mediasoup-client does with
+ * GetExtendedRtpCapabilities(). RTCP feedback is not reported since
+ * RTCRtpReceiver.getCapabilities() does not list it.
+ */
+func Check(device DeviceCapabilities, routerCaps mediasoup.RtpCapabilities) (report Report, err error) {
+	deviceCaps, err := device.RtpCapabilities()
+	if err != nil {
+		return
+	}
+
+	extendedCaps := mediasoup.GetExtendedRtpCapabilities(deviceCaps, routerCaps)
+
+	report.Codecs = []CodecReport{}
+	report.HeaderExtensions = []HeaderExtensionReport{}
+
+	for _, codec := range routerCaps.Codecs {
+		if mediasoup.ParseMimeType(codec.MimeType).IsRtx() {
+			continue
+		}
+
+		codecReport := CodecReport{
+			Kind:        codec.Kind,
+			MimeType:    codec.MimeType,
+			ClockRate:   codec.ClockRate,
+			Channels:    codec.Channels,
+			PayloadType: codec.PreferredPayloadType,
+			Reason:      codecMismatchReason(codec, deviceCaps),
+		}
+
+		for _, extendedCodec := range extendedCaps.Codecs {
+			if extendedCodec.RemotePayloadType == codec.PreferredPayloadType {
+				codecReport.Negotiated = true
+				codecReport.Rtx = extendedCodec.RemoteRtxPayloadType != 0
+				codecReport.Parameters = extendedCodec.RemoteParameters
+				codecReport.Reason = ""
+				break
+			}
+		}
+
+		report.Codecs = append(report.Codecs, codecReport)
+	}
+
+	for _, ext := range routerCaps.HeaderExtensions {
+		extReport := HeaderExtensionReport{Kind: ext.Kind, Uri: ext.Uri, Id: ext.PreferredId}
+
+		for _, extendedExt := range extendedCaps.HeaderExtensions {
+			if extendedExt.Kind == ext.Kind && extendedExt.Uri == ext.Uri {
+				extReport.Negotiated = true
+				extReport.Direction = extendedExt.Direction
+				break
+			}
+		}
+
+		report.HeaderExtensions = append(report.HeaderExtensions, extReport)
+	}
+
+	return
+}
+
+func codecMismatchReason(codec mediasoup.RtpCodecCapability, deviceCaps mediasoup.RtpCapabilities) string {
+	mimeType := mediasoup.ParseMimeType(codec.MimeType)
+
+	for _, deviceCodec := range deviceCaps.Codecs {
+		if mediasoup.ParseMimeType(deviceCodec.MimeType) == mimeType {
+			return "codec parameters not compatible (e.g. clock rate, channels or profile)"
+		}
+	}
+
+	return "codec not supported by the device"
+}
+
+// String returns the report as text tables.
+func (report Report) String() string {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "KIND\tCODEC\tPT\tNEGOTIATED\tRTX\tANSWER\tREASON")
+
+	for _, codec := range report.Codecs {
+		answer := ""
+		if codec.Parameters != nil {
+			answer, _ = sdp.WriteFmtp(codec.Parameters)
+		}
+		fmt.Fprintf(w, "%s\t%s/%d\t%d\t%s\t%s\t%s\t%s\n", codec.Kind, codec.MimeType, codec.ClockRate,
+			codec.PayloadType, yesNo(codec.Negotiated), yesNo(codec.Rtx), answer, codec.Reason)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "KIND\tHEADER EXTENSION\tID\tNEGOTIATED\tDIRECTION")
+
+	for _, ext := range report.HeaderExtensions {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", ext.Kind, ext.Uri, ext.Id, yesNo(ext.Negotiated), ext.Direction)
+	}
+
+	w.Flush()
+
+	return b.String()
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+
+	return "no"
+}
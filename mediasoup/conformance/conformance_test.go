@@ -0,0 +1,114 @@
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+// Trimmed Chrome RTCRtpReceiver.getCapabilities() results.
+const chromeDeviceJSON = `{
+	"audio": {
+		"codecs": [
+			{"mimeType": "audio/opus", "clockRate": 48000, "channels": 2, "sdpFmtpLine": "minptime=10;useinbandfec=1"},
+			{"mimeType": "audio/PCMU", "clockRate": 8000}
+		],
+		"headerExtensions": [
+			{"uri": "urn:ietf:params:rtp-hdrext:ssrc-audio-level"}
+		]
+	},
+	"video": {
+		"codecs": [
+			{"mimeType": "video/VP8", "clockRate": 90000},
+			{"mimeType": "video/rtx", "clockRate": 90000},
+			{"mimeType": "video/H264", "clockRate": 90000, "sdpFmtpLine": "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e034"}
+		],
+		"headerExtensions": [
+			{"uri": "urn:3gpp:video-orientation"}
+		]
+	}
+}`
+
+func TestCheck(t *testing.T) {
+	var device DeviceCapabilities
+	assert.NoError(t, json.Unmarshal([]byte(chromeDeviceJSON), &device))
+
+	routerCaps, err := mediasoup.GenerateRouterRtpCapabilities([]mediasoup.RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+		{
+			Kind:      "video",
+			MimeType:  "video/H264",
+			ClockRate: 90000,
+			Parameters: &mediasoup.RtpCodecParameter{
+				RtpH264Parameter: h264profile.RtpH264Parameter{
+					LevelAsymmetryAllowed: 1,
+					PacketizationMode:     1,
+					ProfileLevelId:        "42e01f",
+				},
+			},
+		},
+		{Kind: "video", MimeType: "video/AV1", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	report, err := Check(device, routerCaps)
+	assert.NoError(t, err)
+	assert.Len(t, report.Codecs, 4)
+
+	opus, vp8, h264, av1 := report.Codecs[0], report.Codecs[1], report.Codecs[2], report.Codecs[3]
+
+	assert.Equal(t, "audio/opus", opus.MimeType)
+	assert.True(t, opus.Negotiated)
+	assert.False(t, opus.Rtx)
+
+	assert.Equal(t, "video/VP8", vp8.MimeType)
+	assert.True(t, vp8.Negotiated)
+	assert.True(t, vp8.Rtx)
+
+	// Constrained baseline is answered as the level of the Router.
+	assert.Equal(t, "video/H264", h264.MimeType)
+	assert.True(t, h264.Negotiated)
+	assert.Equal(t, "42e01f", h264.Parameters.ProfileLevelId)
+
+	assert.Equal(t, "video/AV1", av1.MimeType)
+	assert.False(t, av1.Negotiated)
+	assert.Equal(t, "codec not supported by the device", av1.Reason)
+
+	negotiated := map[string]bool{}
+	for _, ext := range report.HeaderExtensions {
+		negotiated[string(ext.Kind)+" "+ext.Uri] = ext.Negotiated
+	}
+	assert.True(t, negotiated["audio urn:ietf:params:rtp-hdrext:ssrc-audio-level"])
+	assert.True(t, negotiated["video urn:3gpp:video-orientation"])
+	assert.False(t, negotiated["video http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time"])
+
+	assert.Contains(t, report.String(), "video/AV1/90000")
+}
+
+func TestDeviceCapabilities_InvalidFmtp(t *testing.T) {
+	device := DeviceCapabilities{
+		mediasoup.MediaKindVideo: {Codecs: []BrowserCodec{
+			{MimeType: "video/VP9", ClockRate: 90000, SdpFmtpLine: "profile-id=foo"},
+		}},
+	}
+
+	_, err := device.RtpCapabilities()
+	assert.Error(t, err)
+}
+
+func TestCodecMismatchReason(t *testing.T) {
+	deviceCaps := mediasoup.RtpCapabilities{
+		Codecs: []mediasoup.RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		},
+	}
+
+	assert.Equal(t, "codec parameters not compatible (e.g. clock rate, channels or profile)",
+		codecMismatchReason(mediasoup.RtpCodecCapability{MimeType: " Audio/OPUS", ClockRate: 16000}, deviceCaps))
+	assert.Equal(t, "codec not supported by the device",
+		codecMismatchReason(mediasoup.RtpCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000}, deviceCaps))
+}
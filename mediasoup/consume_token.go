@@ -0,0 +1,72 @@
+package mediasoup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsumeTokenValidator authorizes Transport.Consume() calls given the
+// Producer id and the token of transportConsumeParams. A non nil error
+// rejects the call.
+type ConsumeTokenValidator func(producerId, token string) error
+
+// GenerateConsumeToken returns a token authorizing to consume the Producer
+// until expiresAt, in the form "<expiry unix seconds>.<base64url HMAC-SHA256
+// of producerId and expiry>".
+func GenerateConsumeToken(secret, producerId string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return expiry + "." + consumeTokenSignature(secret, producerId, expiry)
+}
+
+// VerifyConsumeToken checks a token generated by GenerateConsumeToken().
+func VerifyConsumeToken(secret, producerId, token string) error {
+	return verifyConsumeToken(secret, producerId, token, time.Now())
+}
+
+// NewConsumeTokenValidator returns a validator accepting the tokens generated
+// by GenerateConsumeToken() with the given secret.
+func NewConsumeTokenValidator(secret string) ConsumeTokenValidator {
+	return func(producerId, token string) error {
+		return VerifyConsumeToken(secret, producerId, token)
+	}
+}
+
+func verifyConsumeToken(secret, producerId, token string, now time.Time) error {
+	if len(token) == 0 {
+		return NewUnauthorizedError("missing consume token")
+	}
+
+	parts := strings.SplitN(token, ".", 2)
+
+	if len(parts) != 2 {
+		return NewUnauthorizedError("malformed consume token")
+	}
+
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return NewUnauthorizedError("malformed consume token")
+	}
+
+	signature := consumeTokenSignature(secret, producerId, parts[0])
+
+	if !hmac.Equal([]byte(signature), []byte(parts[1])) {
+		return NewUnauthorizedError("invalid consume token [producerId:%s]", producerId)
+	}
+	if now.Unix() > expiry {
+		return NewUnauthorizedError("expired consume token [producerId:%s]", producerId)
+	}
+
+	return nil
+}
+
+func consumeTokenSignature(secret, producerId, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(producerId + "." + expiry))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
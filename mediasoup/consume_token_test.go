@@ -0,0 +1,27 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyConsumeToken(t *testing.T) {
+	now := time.Now()
+	token := GenerateConsumeToken("secret", "producer-1", now.Add(time.Minute))
+
+	assert.NoError(t, verifyConsumeToken("secret", "producer-1", token, now))
+	assert.NoError(t, NewConsumeTokenValidator("secret")("producer-1", token))
+
+	for _, err := range []error{
+		verifyConsumeToken("secret", "producer-1", "", now),
+		verifyConsumeToken("secret", "producer-1", "foo", now),
+		verifyConsumeToken("secret", "producer-1", "foo.bar", now),
+		verifyConsumeToken("other", "producer-1", token, now),
+		verifyConsumeToken("secret", "producer-2", token, now),
+		verifyConsumeToken("secret", "producer-1", token, now.Add(2*time.Minute)),
+	} {
+		assert.IsType(t, NewUnauthorizedError(""), err)
+	}
+}
@@ -2,6 +2,7 @@ package mediasoup
 
 import (
 	"encoding/json"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,7 +20,29 @@ type Consumer struct {
 	score          *ConsumerScore
 	// Current video layers (just for video with simulcast or SVC).
 	currentLayers *VideoLayer
-	observer      EventEmitter
+	// Preferred video layers, as applied by the worker.
+	preferredLayers *VideoLayer
+	priority        uint8
+	// Request a key frame when resumed or switching spatial layer (video
+	// only).
+	autoKeyFrame bool
+	// Created without RTX, kept when re-created by RevalidateConsumers().
+	rtxDisabled bool
+	// Enabled trace event types, nil if never set.
+	traceEventTypes []string
+	observer        EventEmitter
+	// Set by the Transport, used by SwitchProducer(). authorizeSwitch checks
+	// the Consumer may consume the Producer, returning the function to call
+	// once switched.
+	getProducerById fetchProducerFunc
+	authorizeSwitch func(producer *Producer, token string) (switched func(), err error)
+	// Held by SwitchProducer() while it re-creates the worker consumer, and
+	// for reading by the methods requesting the worker consumer, so they
+	// never run during a switch.
+	switchLocker sync.RWMutex
+	// Guards internal.ProducerId, which ProducerId() reads without
+	// switchLocker so event listeners can call it during a switch.
+	producerIdLocker sync.Mutex
 }
 
 /**
@@ -30,7 +53,10 @@ type Consumer struct {
  * @emits consumerpause
  * @emits consumerresume
  * @emits {consumer: Number, consumer: Number} score
- * @emits {spatialLayer: Number|Null} layerschange
+ * @emits {VideoLayer} layerschange - No argument if there are no current
+ *   layers anymore.
+ * @emits {producerId string} producerswitch
+ * @emits {TraceNotification} trace
  * @emits @close
  * @emits @consumerclose
  */
@@ -61,6 +87,8 @@ func NewConsumer(
 		paused:         paused,
 		producerPaused: producerPaused,
 		score:          score,
+		priority:       1,
+		autoKeyFrame:   true,
 		observer:       NewEventEmitter(AppLogger()),
 	}
 
@@ -81,6 +109,9 @@ func (consumer *Consumer) ConsumerId() string {
 
 // Associated Producer id.
 func (consumer *Consumer) ProducerId() string {
+	consumer.producerIdLocker.Lock()
+	defer consumer.producerIdLocker.Unlock()
+
 	return consumer.internal.ProducerId
 }
 
@@ -90,7 +121,7 @@ func (consumer *Consumer) Closed() bool {
 }
 
 // Media kind.
-func (consumer *Consumer) Kind() string {
+func (consumer *Consumer) Kind() MediaKind {
 	return consumer.data.Kind
 }
 
@@ -137,7 +168,9 @@ func (consumer *Consumer) AppData() interface{} {
  * @emits pause
  * @emits resume
  * @emits {consumer: Number, consumer: Number} score
- * @emits {spatialLayer: Number|Null} layerschange
+ * @emits {VideoLayer} layerschange
+ * @emits {producerId string} producerswitch
+ * @emits {TraceNotification} trace
  */
 func (consumer *Consumer) Observer() EventEmitter {
 	return consumer.observer
@@ -145,28 +178,48 @@ func (consumer *Consumer) Observer() EventEmitter {
 
 // Close the Consumer.
 func (consumer *Consumer) Close() (err error) {
-	if consumer.closed {
+	request := consumer.prepareClose()
+
+	if request == nil {
+		return
+	}
+
+	response := consumer.channel.Request(request.Method, request.Internal, request.Data)
+
+	if err = response.Err(); err != nil {
 		return
 	}
 
+	consumer.finishClose()
+
+	return
+}
+
+// prepareClose marks the Consumer closed and returns the worker request
+// closing it, nil if it is already closed.
+func (consumer *Consumer) prepareClose() *channelRequest {
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	if consumer.closed {
+		return nil
+	}
+
 	consumer.closed = true
 
 	consumer.logger.Debug("close()")
 
 	consumer.channel.RemoveAllListeners(consumer.internal.ConsumerId)
 
-	response := consumer.channel.Request("consumer.close", consumer.internal, nil)
-
-	if err = response.Err(); err != nil {
-		return
-	}
+	return &channelRequest{Method: "consumer.close", Internal: consumer.internal}
+}
 
+// finishClose emits the close events once the worker closed the Consumer.
+func (consumer *Consumer) finishClose() {
 	consumer.Emit("@close")
 
 	// Emit observer event.
 	consumer.observer.SafeEmit("close")
-
-	return
 }
 
 // Transport was closed.
@@ -189,6 +242,9 @@ func (consumer *Consumer) TransportClosed() {
 func (consumer *Consumer) Dump() Response {
 	consumer.logger.Debug("dump()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
 	return consumer.channel.Request("consumer.dump", consumer.internal, nil)
 }
 
@@ -196,6 +252,9 @@ func (consumer *Consumer) Dump() Response {
 func (consumer *Consumer) GetStats() Response {
 	consumer.logger.Debug("getStats()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
 	return consumer.channel.Request("consumer.getStats", consumer.internal, nil)
 }
 
@@ -203,6 +262,9 @@ func (consumer *Consumer) GetStats() Response {
 func (consumer *Consumer) Pause() (err error) {
 	consumer.logger.Debug("pause()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
 	wasPaused := consumer.paused || consumer.producerPaused
 
 	response := consumer.channel.Request("consumer.pause", consumer.internal, nil)
@@ -225,6 +287,9 @@ func (consumer *Consumer) Pause() (err error) {
 func (consumer *Consumer) Resume() (err error) {
 	consumer.logger.Debug("resume()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
 	wasPaused := consumer.paused || consumer.producerPaused
 
 	response := consumer.channel.Request("consumer.resume", consumer.internal, nil)
@@ -235,43 +300,417 @@ func (consumer *Consumer) Resume() (err error) {
 
 	consumer.paused = false
 
-	// Emit observer event.
 	if wasPaused && !consumer.producerPaused {
+		consumer.autoRequestKeyFrame("resume")
+
+		// Emit observer event.
 		consumer.observer.SafeEmit("resume")
 	}
 
 	return
 }
 
-// Set preferred video layers.
-func (consumer *Consumer) SetPreferredLayers(spatialLayer, temporalLayer uint8) (err error) {
+// Consumer preferred video layers, nil until set.
+func (consumer *Consumer) PreferredLayers() *VideoLayer {
+	return consumer.preferredLayers
+}
+
+// Consumer priority, used to distribute the available outgoing bitrate.
+func (consumer *Consumer) Priority() uint8 {
+	return consumer.priority
+}
+
+/**
+ * Set preferred video layers of a simulcast or SVC Consumer. The worker
+ * may lower them to the ones the Producer actually sends.
+ *
+ * @throws {InvalidStateError} if the Consumer is closed.
+ * @throws {TypeError} if the layers exceed the scalabilityMode of the RTP
+ *   parameters.
+ */
+func (consumer *Consumer) SetPreferredLayers(spatialLayer, temporalLayer uint8) error {
 	consumer.logger.Debug("setPreferredLayers()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	return consumer.setPreferredLayers(spatialLayer, temporalLayer)
+}
+
+func (consumer *Consumer) setPreferredLayers(spatialLayer, temporalLayer uint8) (err error) {
+	if consumer.closed {
+		return NewInvalidStateError("Consumer closed")
+	}
+
+	// Layers are checked when known, the worker clamps them otherwise.
+	if rtpParameters := consumer.RtpParameters(); len(rtpParameters.Encodings) > 0 &&
+		len(rtpParameters.Encodings[0].ScalabilityMode) > 0 {
+		maxSpatialLayer, maxTemporalLayer := MaxPreferredLayers(rtpParameters)
+
+		if spatialLayer > maxSpatialLayer || temporalLayer > maxTemporalLayer {
+			return NewTypeError("layers out of range [spatialLayer:%d, temporalLayer:%d, max:%d/%d]",
+				spatialLayer, temporalLayer, maxSpatialLayer, maxTemporalLayer)
+		}
+	}
+
 	response := consumer.channel.Request(
 		"consumer.setPreferredLayers",
 		consumer.internal,
+		VideoLayer{
+			SpatialLayer:  spatialLayer,
+			TemporalLayer: temporalLayer,
+		},
+	)
+
+	if err = response.Err(); err != nil {
+		return
+	}
+
+	preferredLayers := VideoLayer{SpatialLayer: spatialLayer, TemporalLayer: temporalLayer}
+
+	// The worker answers the layers it applied, if any.
+	if data := response.Data(); len(data) > 0 && string(data) != "null" {
+		response.Unmarshal(&preferredLayers)
+	}
+
+	consumer.preferredLayers = &preferredLayers
+
+	return
+}
+
+/**
+ * Set the priority of the Consumer, from 1 (the default) to 255. Consumers
+ * with higher priority get a larger share of the available outgoing bitrate.
+ *
+ * @throws {InvalidStateError} if the Consumer is closed.
+ * @throws {TypeError} if priority is 0.
+ */
+func (consumer *Consumer) SetPriority(priority uint8) error {
+	consumer.logger.Debug("setPriority()")
+
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	return consumer.setPriority(priority)
+}
+
+func (consumer *Consumer) setPriority(priority uint8) (err error) {
+	if consumer.closed {
+		return NewInvalidStateError("Consumer closed")
+	}
+	if priority < 1 {
+		return NewTypeError("wrong priority [priority:%d]", priority)
+	}
+
+	response := consumer.channel.Request(
+		"consumer.setPriority",
+		consumer.internal,
 		map[string]uint8{
-			"spatialLayer":  spatialLayer,
-			"temporalLayer": temporalLayer,
+			"priority": priority,
 		},
 	)
 
-	return response.Err()
+	if err = response.Err(); err != nil {
+		return
+	}
+
+	consumer.priority = priority
+
+	return
+}
+
+// Unset the priority of the Consumer, i.e. set it back to 1.
+func (consumer *Consumer) UnsetPriority() error {
+	consumer.logger.Debug("unsetPriority()")
+
+	return consumer.SetPriority(1)
+}
+
+// Whether a key frame is requested when the video Consumer is resumed or
+// switches spatial layer.
+func (consumer *Consumer) AutoKeyFrame() bool {
+	return consumer.autoKeyFrame
+}
+
+// Enable or disable the key frame requests issued when the video Consumer is
+// resumed or switches spatial layer. Enabled by default unless the Consumer
+// was created with DisableAutoKeyFrame.
+func (consumer *Consumer) SetAutoKeyFrame(enabled bool) {
+	consumer.autoKeyFrame = enabled
+}
+
+// autoRequestKeyFrame requests a key frame if enabled, so the endpoint can
+// decode right away instead of waiting for the next one. It returns whether
+// the request was issued. The caller holds switchLocker for reading.
+func (consumer *Consumer) autoRequestKeyFrame(reason string) bool {
+	if !consumer.autoKeyFrame || consumer.closed || consumer.Kind() != MediaKindVideo {
+		return false
+	}
+
+	if err := consumer.requestKeyFrame(); err != nil {
+		consumer.logger.Warnf("automatic key frame request failed [reason:%s]: %s", reason, err)
+	}
+
+	return true
+}
+
+// lockedAutoRequestKeyFrame is autoRequestKeyFrame for the callers not
+// holding switchLocker, e.g. the notification handlers.
+func (consumer *Consumer) lockedAutoRequestKeyFrame(reason string) {
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	consumer.autoRequestKeyFrame(reason)
 }
 
 // Request a key frame to the Producer.
 func (consumer *Consumer) RequestKeyFrame() error {
 	consumer.logger.Debug("requestKeyFrame()")
 
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	return consumer.requestKeyFrame()
+}
+
+func (consumer *Consumer) requestKeyFrame() error {
 	response := consumer.channel.Request("consumer.requestKeyFrame", consumer.internal, nil)
 
 	return response.Err()
 }
 
+/**
+ * Switch the Consumer to another Producer of the same kind, type and codecs
+ * without renegotiation: the Consumer keeps its id and RTP parameters (so the
+ * SSRCs seen by the client don't change) while receiving the media of the new
+ * Producer, e.g. for server side camera switching or broadcast failover.
+ *
+ * EXPERIMENTAL: the switch is not atomic. The worker has no request to
+ * re-target a consumer, so the worker consumer is closed ("consumer.close"),
+ * then created again for the new Producer with the same id and RTP parameters
+ * ("transport.consume"), then its priority, preferred layers and trace event
+ * types are applied again. Meanwhile the endpoint receives no media, no RTCP
+ * is forwarded and the worker notifications of the Consumer (e.g. "score")
+ * are lost. The other methods of the Consumer wait for the switch to end.
+ *
+ * The new Producer is authorized as in Transport.Consume(): token is checked
+ * by the ConsumeTokenValidator of the Router, if any, and a protected Producer
+ * by its ProtectedConsumePolicy.
+ *
+ * If creating the worker consumer for the new Producer fails, it is created
+ * again for the current Producer and the error is returned, the Consumer
+ * going on as before. Only if that fails too, e.g. because the current
+ * Producer was closed meanwhile, the Consumer is closed and emits
+ * "producerclose".
+ *
+ * @throws {InvalidStateError} if the Consumer or its Producer is closed.
+ * @throws {TypeError} if the Producer is not found.
+ * @throws {UnsupportedError} if the Producer is not compatible or this is a
+ * pipe Consumer.
+ * @throws {UnauthorizedError} if the Producer is protected and not allowed.
+ */
+func (consumer *Consumer) SwitchProducer(producerId, token string) (err error) {
+	consumer.logger.Debugf("switchProducer() [producerId:%s]", producerId)
+
+	consumer.switchLocker.Lock()
+
+	wasPaused := consumer.paused || consumer.producerPaused
+	switched, lost, err := consumer.switchProducer(producerId, token)
+	isPaused := consumer.paused || consumer.producerPaused
+
+	consumer.switchLocker.Unlock()
+
+	// Events are emitted once unlocked, so listeners can use the Consumer.
+	if lost {
+		consumer.closeAfterSwitchFailure()
+	}
+	if switched == nil {
+		return
+	}
+
+	switched()
+
+	consumer.SafeEmit("producerswitch", producerId)
+
+	// Emit observer events.
+	consumer.observer.SafeEmit("producerswitch", producerId)
+
+	if isPaused != wasPaused {
+		if isPaused {
+			consumer.observer.SafeEmit("pause")
+		} else {
+			consumer.observer.SafeEmit("resume")
+		}
+	}
+
+	return
+}
+
+// switchProducer does the switch of SwitchProducer() with switchLocker held.
+// It returns the function to call once switched, nil if it did not switch,
+// and whether the worker consumer was lost, the Consumer being closed.
+func (consumer *Consumer) switchProducer(producerId, token string) (switched func(), lost bool, err error) {
+	if consumer.closed {
+		err = NewInvalidStateError("Consumer closed")
+		return
+	}
+	if consumer.getProducerById == nil {
+		// Pipe Consumers.
+		err = NewUnsupportedError("Consumer cannot switch Producer")
+		return
+	}
+	if producerId == consumer.internal.ProducerId {
+		return
+	}
+
+	producer := consumer.getProducerById(producerId)
+	oldProducer := consumer.getProducerById(consumer.internal.ProducerId)
+
+	if producer == nil {
+		err = NewTypeError(`Producer with id "%s" not found`, producerId)
+		return
+	}
+	// The worker closes the Consumer anyway, don't lose it meanwhile.
+	if oldProducer == nil || oldProducer.Closed() {
+		err = NewInvalidStateError("Producer of the Consumer closed")
+		return
+	}
+	if err = checkSwitchProducer(consumer, producer); err != nil {
+		return
+	}
+
+	authorized, err := consumer.authorizeSwitch(producer, token)
+	if err != nil {
+		return
+	}
+
+	response := consumer.channel.Request("consumer.close", consumer.internal, nil)
+
+	if err = response.Err(); err != nil {
+		return
+	}
+
+	if err = consumer.consumeProducer(producer); err != nil {
+		consumer.logger.Errorf("switchProducer() failed [producerId:%s]: %s", producerId, err)
+
+		if rerr := consumer.consumeProducer(oldProducer); rerr != nil {
+			consumer.logger.Errorf("restoring Producer failed [producerId:%s]: %s",
+				oldProducer.Id(), rerr)
+
+			consumer.closed = true
+			lost = true
+		}
+
+		return
+	}
+
+	return authorized, false, nil
+}
+
+// consumeProducer creates the worker consumer for the Producer with the
+// current id, RTP parameters and settings.
+func (consumer *Consumer) consumeProducer(producer *Producer) (err error) {
+	internal := consumer.internal
+	internal.ProducerId = producer.Id()
+
+	reqData := H{
+		"kind":                   consumer.Kind(),
+		"rtpParameters":          consumer.RtpParameters(),
+		"type":                   consumer.Type(),
+		"paused":                 consumer.paused,
+		"consumableRtpEncodings": producer.ConsumableRtpParameters().Encodings,
+	}
+
+	response := consumer.channel.Request("transport.consume", internal, reqData)
+
+	var status struct {
+		ProducerPaused bool
+	}
+	if err = response.Unmarshal(&status); err != nil {
+		return
+	}
+
+	consumer.producerIdLocker.Lock()
+	consumer.internal.ProducerId = internal.ProducerId
+	consumer.producerIdLocker.Unlock()
+
+	consumer.producerPaused = status.ProducerPaused
+
+	consumer.applySettings()
+
+	return
+}
+
+// applySettings sets again the settings of the Consumer to a new worker
+// consumer, which starts with the default ones.
+func (consumer *Consumer) applySettings() {
+	if consumer.priority > 1 {
+		if err := consumer.setPriority(consumer.priority); err != nil {
+			consumer.logger.Warnf("restoring priority failed: %s", err)
+		}
+	}
+	if layers := consumer.preferredLayers; layers != nil {
+		if err := consumer.setPreferredLayers(layers.SpatialLayer, layers.TemporalLayer); err != nil {
+			consumer.logger.Warnf("restoring preferred layers failed: %s", err)
+		}
+	}
+	if len(consumer.traceEventTypes) > 0 {
+		if err := consumer.enableTraceEvent(consumer.traceEventTypes...); err != nil {
+			consumer.logger.Warnf("restoring trace event types failed: %s", err)
+		}
+	}
+}
+
+// closeAfterSwitchFailure emits the close of the Consumer as if its Producer
+// was closed since there is no worker consumer anymore.
+func (consumer *Consumer) closeAfterSwitchFailure() {
+	consumer.channel.RemoveAllListeners(consumer.internal.ConsumerId)
+
+	consumer.Emit("@producerclose")
+	consumer.SafeEmit("producerclose")
+
+	// Emit observer event.
+	consumer.observer.SafeEmit("close")
+}
+
+// checkSwitchProducer checks the Producer can feed the Consumer without
+// changing its RTP parameters.
+func checkSwitchProducer(consumer *Consumer, producer *Producer) error {
+	if producer.Kind() != consumer.Kind() {
+		return NewUnsupportedError("Producer kind %s does not match Consumer kind %s",
+			producer.Kind(), consumer.Kind())
+	}
+	if producer.Type() != consumer.Type() {
+		return NewUnsupportedError("Producer type %s does not match Consumer type %s",
+			producer.Type(), consumer.Type())
+	}
+
+	consumableCodecs := producer.ConsumableRtpParameters().Codecs
+
+	for _, codec := range consumer.RtpParameters().Codecs {
+		var matched bool
+
+		for _, consumableCodec := range consumableCodecs {
+			if consumableCodec.PayloadType == codec.PayloadType &&
+				matchedCodecs(&codec, consumableCodec, codecMatchStrict) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return NewUnsupportedError("Producer does not have codec %s [payloadType:%d]",
+				codec.MimeType, codec.PayloadType)
+		}
+	}
+
+	return nil
+}
+
 func (consumer *Consumer) handleWorkerNotifications() {
 	consumer.channel.On(consumer.internal.ConsumerId, func(event string, data json.RawMessage) {
 		switch event {
-		case "producerclose":
+		case ConsumerNotificationProducerClose:
 			if consumer.closed {
 				break
 			}
@@ -286,7 +725,7 @@ func (consumer *Consumer) handleWorkerNotifications() {
 			// Emit observer event.
 			consumer.observer.SafeEmit("close")
 
-		case "producerpause":
+		case ConsumerNotificationProducerPause:
 			if consumer.producerPaused {
 				break
 			}
@@ -302,7 +741,7 @@ func (consumer *Consumer) handleWorkerNotifications() {
 				consumer.observer.SafeEmit("pause")
 			}
 
-		case "producerresume":
+		case ConsumerNotificationProducerResume:
 			if !consumer.producerPaused {
 				break
 			}
@@ -313,28 +752,53 @@ func (consumer *Consumer) handleWorkerNotifications() {
 
 			consumer.SafeEmit("producerresume")
 
-			// Emit observer event.
 			if wasPaused && !consumer.paused {
+				// Notifications are handled by the channel reader, so don't
+				// block it waiting for the response.
+				go consumer.lockedAutoRequestKeyFrame("producerresume")
+
+				// Emit observer event.
 				consumer.observer.SafeEmit("resume")
 			}
 
-		case "score":
+		case ConsumerNotificationScore:
 			var score ConsumerScore
 
 			json.Unmarshal([]byte(data), &score)
 
 			consumer.score = &score
 
+			if !allowGovernedEvent(GovernedEventConsumerScore) {
+				break
+			}
+
 			consumer.SafeEmit("score", score)
 
 			// Emit observer event.
 			consumer.observer.SafeEmit("score", score)
 
-		case "layerschange":
+		case ConsumerNotificationLayersChange:
+			// The worker sends null when there are no current layers.
+			if len(data) == 0 || string(data) == "null" {
+				consumer.currentLayers = nil
+
+				consumer.SafeEmit("layerschange")
+
+				// Emit observer event.
+				consumer.observer.SafeEmit("layerschange")
+
+				break
+			}
+
 			var layer VideoLayer
 
 			json.Unmarshal([]byte(data), &layer)
 
+			if previous := consumer.currentLayers; previous != nil &&
+				previous.SpatialLayer != layer.SpatialLayer {
+				go consumer.lockedAutoRequestKeyFrame("layerschange")
+			}
+
 			consumer.currentLayers = &layer
 
 			consumer.SafeEmit("layerschange", layer)
@@ -342,6 +806,16 @@ func (consumer *Consumer) handleWorkerNotifications() {
 			// Emit observer event.
 			consumer.observer.SafeEmit("layerschange", layer)
 
+		case ConsumerNotificationTrace:
+			trace := TraceNotification{}
+
+			json.Unmarshal([]byte(data), &trace)
+
+			consumer.SafeEmit("trace", trace)
+
+			// Emit observer event.
+			consumer.observer.SafeEmit("trace", trace)
+
 		default:
 			consumer.logger.Errorf(`ignoring unknown event "%s"`, event)
 		}
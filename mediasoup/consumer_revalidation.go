@@ -0,0 +1,178 @@
+package mediasoup
+
+import "sort"
+
+// Actions taken on a Consumer by RevalidateConsumers().
+const (
+	ConsumerRevalidationKept      = "kept"
+	ConsumerRevalidationRecreated = "recreated"
+	ConsumerRevalidationClosed    = "closed"
+)
+
+type RevalidateConsumersParams struct {
+	// RtpCapabilities newly reported by the client.
+	RtpCapabilities RtpCapabilities
+	// Recreate the incompatible Consumers whose Producer can still be
+	// consumed with the new capabilities, instead of closing them.
+	Recreate bool
+	// Device hint of the client, see Consume().
+	Device string
+}
+
+// ConsumerRevalidation is the outcome of RevalidateConsumers() for a Consumer.
+type ConsumerRevalidation struct {
+	// Action is one of ConsumerRevalidationKept, ConsumerRevalidationRecreated
+	// or ConsumerRevalidationClosed.
+	Action     string
+	ConsumerId string
+	ProducerId string
+	// Consumer replacing the closed one if recreated. The client must be
+	// signaled the new Consumer.
+	Consumer *Consumer
+	// Reason the Consumer was not kept.
+	Reason error
+}
+
+/**
+ * Re-validate the Consumers of the transport against the RTP capabilities
+ * newly reported by the client, e.g. after a codec was disabled by policy.
+ * Incompatible Consumers are closed, or re-created with the new capabilities
+ * if params.Recreate is set and the Producer can still be consumed. Pipe
+ * Consumers are kept.
+ *
+ * @emits {ConsumerRevalidation} consumerrevalidation - For every Consumer
+ * not kept.
+ * @returns {[]ConsumerRevalidation} - One per Consumer, sorted by Consumer id.
+ * @throws {InvalidStateError} if the transport is closed.
+ * @throws {TypeError} if the RTP capabilities are invalid.
+ */
+func (transport *baseTransport) RevalidateConsumers(
+	params RevalidateConsumersParams,
+) (results []ConsumerRevalidation, err error) {
+	transport.logger.Debug("revalidateConsumers()")
+
+	if transport.closed {
+		err = NewInvalidStateError("Transport closed")
+		return
+	}
+
+	caps := params.RtpCapabilities
+	caps.Codecs = make([]RtpCodecCapability, 0, len(params.RtpCapabilities.Codecs))
+
+	for _, capCodec := range params.RtpCapabilities.Codecs {
+		if err = validateRtpCodecCapability(&capCodec); err != nil {
+			return
+		}
+		caps.Codecs = append(caps.Codecs, capCodec)
+	}
+
+	consumers := transport.consumerList()
+
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].Id() < consumers[j].Id()
+	})
+
+	for _, consumer := range consumers {
+		if consumer.Closed() {
+			continue
+		}
+
+		result := transport.revalidateConsumer(consumer, caps, params)
+
+		results = append(results, result)
+
+		if result.Action == ConsumerRevalidationKept {
+			continue
+		}
+
+		transport.SafeEmit("consumerrevalidation", result)
+
+		// Emit observer event.
+		transport.observer.SafeEmit("consumerrevalidation", result)
+	}
+
+	return
+}
+
+func (transport *baseTransport) revalidateConsumer(
+	consumer *Consumer,
+	caps RtpCapabilities,
+	params RevalidateConsumersParams,
+) ConsumerRevalidation {
+	result := ConsumerRevalidation{
+		Action:     ConsumerRevalidationKept,
+		ConsumerId: consumer.Id(),
+		ProducerId: consumer.ProducerId(),
+	}
+
+	if consumer.Type() == "pipe" {
+		return result
+	}
+
+	result.Reason = checkConsumerRtpParameters(consumer.Kind(), consumer.RtpParameters(), caps)
+
+	if result.Reason == nil {
+		return result
+	}
+
+	transport.logger.Debugf("consumer incompatible [consumerId:%s]: %s", consumer.Id(), result.Reason)
+
+	paused, appData := consumer.Paused(), consumer.AppData()
+
+	consumer.Close()
+
+	result.Action = ConsumerRevalidationClosed
+
+	if !params.Recreate {
+		return result
+	}
+
+	newConsumer, err := transport.Consume(transportConsumeParams{
+		ProducerId:      result.ProducerId,
+		RtpCapabilities: caps,
+		Paused:          paused,
+		AppData:         appData,
+		Device:          params.Device,
+		DisableRtx:      consumer.rtxDisabled,
+		authorized:      true,
+	})
+	if err != nil {
+		transport.logger.Debugf("consumer not re-created [producerId:%s]: %s", result.ProducerId, err)
+
+		return result
+	}
+
+	result.Action = ConsumerRevalidationRecreated
+	result.Consumer = newConsumer
+
+	return result
+}
+
+// checkConsumerRtpParameters checks that the codecs and header extensions of
+// the Consumer RTP parameters are still supported by caps.
+func checkConsumerRtpParameters(kind MediaKind, params RtpParameters, caps RtpCapabilities) error {
+	for _, codec := range params.Codecs {
+		if _, matched := selectMatchedCodecs(&codec, caps.Codecs, codecMatchStrict); !matched {
+			return NewUnsupportedError("codec not supported anymore [mimeType:%s]", codec.MimeType)
+		}
+	}
+
+	for _, ext := range params.HeaderExtensions {
+		ext.Kind = kind
+
+		var matched bool
+
+		for _, capExt := range caps.HeaderExtensions {
+			if matchHeaderExtensions(ext, capExt) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return NewUnsupportedError("header extension not supported anymore [uri:%s]", ext.Uri)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConsumerRtpParameters(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+	}
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "video", MimeType: "video/VP8", PreferredPayloadType: 96, ClockRate: 90000},
+			{Kind: "video", MimeType: "video/rtx", PreferredPayloadType: 97, ClockRate: 90000},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 4},
+		},
+	}
+
+	assert.NoError(t, checkConsumerRtpParameters(MediaKindVideo, params, caps))
+
+	// Audio only header extension.
+	noExtCaps := caps
+	noExtCaps.HeaderExtensions = []RtpHeaderExtension{
+		{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 4},
+	}
+	assert.IsType(t, NewUnsupportedError(""),
+		checkConsumerRtpParameters(MediaKindVideo, params, noExtCaps))
+
+	// RTX disabled.
+	noRtxCaps := caps
+	noRtxCaps.Codecs = caps.Codecs[:1]
+	assert.IsType(t, NewUnsupportedError(""),
+		checkConsumerRtpParameters(MediaKindVideo, params, noRtxCaps))
+}
@@ -0,0 +1,80 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportConsume_DisableRtx(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	producers := map[string]*Producer{}
+	transport := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  newTestChannel(),
+		GetRouterRtpCapabilities: func() RtpCapabilities {
+			return caps
+		},
+		GetProducerById: func(producerId string) *Producer {
+			return producers[producerId]
+		},
+	})
+	transport.On("@newproducer", func(producer *Producer) {
+		producers[producer.Id()] = producer
+	})
+
+	producer, err := transport.Produce(transportProduceParams{
+		Kind: "video",
+		RtpParameters: RtpParameters{
+			Codecs: []RtpCodecCapability{
+				{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+				{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 97, Parameters: &RtpCodecParameter{Apt: 96}},
+			},
+			Encodings: []RtpEncoding{{Ssrc: 1111, Rtx: &RtpEncoding{Ssrc: 1112}}},
+		},
+	})
+	assert.NoError(t, err)
+
+	hasRtx := func(consumer *Consumer) bool {
+		params := consumer.RtpParameters()
+
+		for _, codec := range params.Codecs {
+			if ParseMimeType(codec.MimeType).IsRtx() {
+				return true
+			}
+		}
+
+		return params.Encodings[0].Rtx != nil
+	}
+
+	consumer, err := transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: caps,
+	})
+	assert.NoError(t, err)
+	assert.True(t, hasRtx(consumer))
+
+	consumer, err = transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: caps,
+		DisableRtx:      true,
+	})
+	assert.NoError(t, err)
+	assert.False(t, hasRtx(consumer))
+	assert.Len(t, consumer.RtpParameters().Codecs, 1)
+
+	assert.True(t, transport.RtxEnabled())
+	transport.SetRtxEnabled(false)
+	assert.False(t, transport.RtxEnabled())
+
+	consumer, err = transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: caps,
+	})
+	assert.NoError(t, err)
+	assert.False(t, hasRtx(consumer))
+}
@@ -2,10 +2,13 @@ package mediasoup
 
 import (
 	"encoding/json"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -315,7 +318,7 @@ func (suite *ConsumerTestSuite) TestTransportConsume_Succeeds() {
 	suite.NotEmpty(audioConsumer.Id())
 	suite.Equal(suite.audioProducer.Id(), audioConsumer.ProducerId())
 	suite.False(audioConsumer.Closed())
-	suite.Equal("audio", audioConsumer.Kind())
+	suite.Equal(MediaKindAudio, audioConsumer.Kind())
 	suite.NotEmpty(audioConsumer.RtpParameters())
 	suite.Empty(audioConsumer.RtpParameters().Mid)
 	suite.Len(audioConsumer.RtpParameters().Codecs, 1)
@@ -381,7 +384,7 @@ func (suite *ConsumerTestSuite) TestTransportConsume_Succeeds() {
 	suite.NotEmpty(videoConsumer.Id())
 	suite.Equal(suite.videoProducer.Id(), videoConsumer.ProducerId())
 	suite.False(videoConsumer.Closed())
-	suite.Equal("video", videoConsumer.Kind())
+	suite.Equal(MediaKindVideo, videoConsumer.Kind())
 	suite.NotEmpty(videoConsumer.RtpParameters())
 	suite.Empty(videoConsumer.RtpParameters().Mid)
 	suite.Len(videoConsumer.RtpParameters().Codecs, 2)
@@ -482,7 +485,7 @@ func (suite *ConsumerTestSuite) TestConsumerDump() {
 	type Dump struct {
 		RtpParameters              *RtpParameters
 		Id                         string
-		Kind                       string
+		Kind                       MediaKind
 		Type                       string
 		ConsumableRtpEncodings     []RtpMappingEncoding
 		SupportedCodecPayloadTypes []uint32
@@ -802,3 +805,402 @@ func (suite *ConsumerTestSuite) videoConsumer(paused bool) *Consumer {
 func TestConsumerTestSuite(t *testing.T) {
 	suite.Run(t, new(ConsumerTestSuite))
 }
+
+func (suite *ConsumerTestSuite) TestConsumerSwitchProducer() {
+	audioConsumer := suite.audioConsumer()
+	ssrc := audioConsumer.RtpParameters().Encodings[0].Ssrc
+
+	// Same codecs as audioProducer.
+	audioProducer2, err := suite.transport1.Produce(transportProduceParams{
+		Kind: "audio",
+		RtpParameters: RtpParameters{
+			Mid: "AUDIO2",
+			Codecs: []RtpCodecCapability{
+				{
+					MimeType:    "audio/opus",
+					PayloadType: 111,
+					ClockRate:   48000,
+					Channels:    2,
+				},
+			},
+			Encodings: []RtpEncoding{{Ssrc: 33333333}},
+		},
+	})
+	suite.NoError(err)
+
+	onObserverSwitch := NewMockFunc(suite.T())
+	audioConsumer.Observer().On("producerswitch", onObserverSwitch.Fn())
+
+	suite.NoError(audioConsumer.SwitchProducer(audioProducer2.Id(), ""))
+	onObserverSwitch.ExpectCalledWith(audioProducer2.Id())
+	suite.Equal(audioProducer2.Id(), audioConsumer.ProducerId())
+	suite.Equal(ssrc, audioConsumer.RtpParameters().Encodings[0].Ssrc)
+	suite.False(audioConsumer.Closed())
+
+	var dump struct {
+		ProducerId string
+	}
+	suite.NoError(audioConsumer.Dump().Unmarshal(&dump))
+	suite.Equal(audioProducer2.Id(), dump.ProducerId)
+
+	// Closing the old Producer does not close the Consumer anymore.
+	suite.audioProducer.Close()
+	suite.False(audioConsumer.Closed())
+
+	err = audioConsumer.SwitchProducer(suite.videoProducer.Id(), "")
+	suite.IsType(NewUnsupportedError(""), err)
+
+	err = audioConsumer.SwitchProducer("unknown", "")
+	suite.IsType(NewTypeError(""), err)
+
+	audioConsumer.Close()
+	err = audioConsumer.SwitchProducer(audioProducer2.Id(), "")
+	suite.IsType(NewInvalidStateError(""), err)
+}
+
+func (suite *ConsumerTestSuite) TestConsumerSwitchProducer_RestoresProducer() {
+	audioConsumer := suite.audioConsumer()
+
+	audioProducer2, err := suite.transport1.Produce(transportProduceParams{
+		Kind: "audio",
+		RtpParameters: RtpParameters{
+			Mid: "AUDIO2",
+			Codecs: []RtpCodecCapability{
+				{
+					MimeType:    "audio/opus",
+					PayloadType: 111,
+					ClockRate:   48000,
+					Channels:    2,
+				},
+			},
+			Encodings: []RtpEncoding{{Ssrc: 33333333}},
+		},
+	})
+	suite.NoError(err)
+
+	// Close the Producer in the worker only, so consuming it fails there.
+	suite.NoError(audioProducer2.channel.Request("producer.close", audioProducer2.internal).Err())
+
+	onObserverSwitch := NewMockFunc(suite.T())
+	audioConsumer.Observer().On("producerswitch", onObserverSwitch.Fn())
+
+	err = audioConsumer.SwitchProducer(audioProducer2.Id(), "")
+	suite.IsType(NewTypeError(""), err)
+	onObserverSwitch.ExpectCalledTimes(0)
+
+	// The worker consumer is restored for the current Producer.
+	suite.False(audioConsumer.Closed())
+	suite.Equal(suite.audioProducer.Id(), audioConsumer.ProducerId())
+
+	var dump struct {
+		Id         string
+		ProducerId string
+	}
+	suite.NoError(audioConsumer.Dump().Unmarshal(&dump))
+	suite.Equal(audioConsumer.Id(), dump.Id)
+	suite.Equal(suite.audioProducer.Id(), dump.ProducerId)
+
+	// The worker notifies the restored consumer when its Producer closes.
+	wf := NewWaitFunc(suite.T())
+	audioConsumer.On("producerclose", wf.Fn())
+
+	suite.audioProducer.Close()
+
+	wf.Wait()
+	suite.True(audioConsumer.Closed())
+}
+
+func (suite *ConsumerTestSuite) TestTransportRevalidateConsumers() {
+	audioConsumer := suite.audioConsumer()
+	videoConsumer := suite.videoConsumer(false)
+
+	// The client stops supporting audio levels.
+	caps := suite.consumerDeviceCapabilities
+	caps.HeaderExtensions = nil
+
+	for _, ext := range suite.consumerDeviceCapabilities.HeaderExtensions {
+		if ext.Uri != "urn:ietf:params:rtp-hdrext:ssrc-audio-level" {
+			caps.HeaderExtensions = append(caps.HeaderExtensions, ext)
+		}
+	}
+
+	onRevalidation := NewMockFunc(suite.T())
+	suite.transport2.Observer().On("consumerrevalidation", onRevalidation.Fn())
+
+	results, err := suite.transport2.RevalidateConsumers(RevalidateConsumersParams{
+		RtpCapabilities: caps,
+		Recreate:        true,
+	})
+	suite.NoError(err)
+	suite.Len(results, 2)
+	onRevalidation.ExpectCalledTimes(1)
+
+	for _, result := range results {
+		switch result.ConsumerId {
+		case audioConsumer.Id():
+			suite.Equal(ConsumerRevalidationRecreated, result.Action)
+			suite.IsType(NewUnsupportedError(""), result.Reason)
+			suite.True(audioConsumer.Closed())
+			suite.Equal(suite.audioProducer.Id(), result.Consumer.ProducerId())
+			suite.Len(result.Consumer.RtpParameters().HeaderExtensions,
+				len(audioConsumer.RtpParameters().HeaderExtensions)-1)
+
+		case videoConsumer.Id():
+			suite.Equal(ConsumerRevalidationKept, result.Action)
+			suite.False(videoConsumer.Closed())
+
+		default:
+			suite.Fail("unexpected consumer", result.ConsumerId)
+		}
+	}
+
+	// The client stops supporting H264.
+	caps.Codecs = []RtpCodecCapability{suite.consumerDeviceCapabilities.Codecs[0]}
+
+	results, err = suite.transport2.RevalidateConsumers(RevalidateConsumersParams{
+		RtpCapabilities: caps,
+		Recreate:        true,
+	})
+	suite.NoError(err)
+	suite.Len(results, 2)
+
+	for _, result := range results {
+		if result.ConsumerId == videoConsumer.Id() {
+			suite.Equal(ConsumerRevalidationClosed, result.Action)
+			suite.Nil(result.Consumer)
+			suite.True(videoConsumer.Closed())
+		} else {
+			suite.Equal(ConsumerRevalidationKept, result.Action)
+		}
+	}
+}
+
+func (suite *ConsumerTestSuite) TestTransportConsume_ProtectedProducer() {
+	rtpParameters := suite.audioProducer.RtpParameters()
+	rtpParameters.Mid = "PROTECTED"
+	rtpParameters.Encodings = []RtpEncoding{{Ssrc: 33333333}}
+
+	producer, err := suite.transport1.Produce(transportProduceParams{
+		Kind:          MediaKindAudio,
+		RtpParameters: rtpParameters,
+		Protected:     true,
+	})
+	suite.NoError(err)
+	suite.True(producer.Protected())
+	suite.False(suite.audioProducer.Protected())
+
+	var audit ProtectedConsumeAudit
+
+	suite.transport2.Observer().On("protectedconsume", func(a ProtectedConsumeAudit) {
+		audit = a
+	})
+
+	// The Router has no ProtectedConsumePolicy.
+	_, err = suite.transport2.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: suite.consumerDeviceCapabilities,
+	})
+	suite.IsType(NewUnauthorizedError(""), err)
+	suite.False(audit.Allowed)
+	suite.Equal(producer.Id(), audit.ProducerId)
+	suite.Equal(suite.transport2.Id(), audit.TransportId)
+	suite.Empty(audit.ConsumerId)
+}
+
+func (suite *ConsumerTestSuite) TestTransportCloseAllConsumers() {
+	audioConsumer := suite.audioConsumer()
+	videoConsumer := suite.videoConsumer(false)
+
+	onConsumersClose := NewMockFunc(suite.T())
+	suite.transport2.Observer().On("consumersclose", onConsumersClose.Fn())
+
+	result := suite.transport2.CloseAllConsumers()
+	suite.Empty(result.Errors)
+	suite.ElementsMatch([]string{audioConsumer.Id(), videoConsumer.Id()}, result.Closed)
+	suite.True(audioConsumer.Closed())
+	suite.True(videoConsumer.Closed())
+	suite.Empty(suite.transport2.consumerList())
+	onConsumersClose.ExpectCalledTimes(1)
+
+	// Producers are untouched.
+	suite.False(suite.audioProducer.Closed())
+}
+
+func TestConsumerSetPreferredLayers(t *testing.T) {
+	consumer := NewConsumer(
+		internalData{ConsumerId: "c1"},
+		consumerData{
+			Kind: MediaKindVideo,
+			Type: "simulcast",
+			RtpParameters: RtpParameters{
+				Encodings: []RtpEncoding{{Ssrc: 1, ScalabilityMode: "L3T3"}},
+			},
+		},
+		newTestChannel(), nil, false, false, nil,
+	)
+
+	assert.Nil(t, consumer.PreferredLayers())
+	assert.NoError(t, consumer.SetPreferredLayers(1, 2))
+	assert.Equal(t, &VideoLayer{SpatialLayer: 1, TemporalLayer: 2}, consumer.PreferredLayers())
+	assert.IsType(t, NewTypeError(""), consumer.SetPreferredLayers(3, 0))
+	assert.IsType(t, NewTypeError(""), consumer.SetPreferredLayers(0, 3))
+
+	consumer.closed = true
+
+	assert.IsType(t, NewInvalidStateError(""), consumer.SetPreferredLayers(0, 0))
+}
+
+func TestConsumerSetPriority(t *testing.T) {
+	consumer := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false, false, nil)
+
+	assert.EqualValues(t, 1, consumer.Priority())
+	assert.NoError(t, consumer.SetPriority(3))
+	assert.EqualValues(t, 3, consumer.Priority())
+	assert.IsType(t, NewTypeError(""), consumer.SetPriority(0))
+	assert.EqualValues(t, 3, consumer.Priority())
+	assert.NoError(t, consumer.UnsetPriority())
+	assert.EqualValues(t, 1, consumer.Priority())
+}
+
+func TestConsumerLayersChange(t *testing.T) {
+	channel := newTestChannel()
+	consumer := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		channel, nil, false, false, nil)
+
+	layers := make(chan *VideoLayer, 2)
+
+	consumer.On("layerschange", func(layer VideoLayer) {
+		layers <- consumer.CurrentLayers()
+	})
+
+	channel.SafeEmit("c1", ConsumerNotificationLayersChange, json.RawMessage(`{"spatialLayer":2,"temporalLayer":1}`))
+	assert.Equal(t, &VideoLayer{SpatialLayer: 2, TemporalLayer: 1}, <-layers)
+
+	channel.SafeEmit("c1", ConsumerNotificationLayersChange, json.RawMessage(`null`))
+	assert.Nil(t, <-layers)
+}
+
+func TestConsumerAutoKeyFrame(t *testing.T) {
+	video := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, true, false, nil)
+	audio := NewConsumer(internalData{ConsumerId: "c2"}, consumerData{Kind: MediaKindAudio},
+		newTestChannel(), nil, true, false, nil)
+
+	assert.True(t, video.AutoKeyFrame())
+	assert.True(t, video.autoRequestKeyFrame("test"))
+	assert.False(t, audio.autoRequestKeyFrame("test"))
+	assert.NoError(t, video.Resume())
+
+	video.SetAutoKeyFrame(false)
+
+	assert.False(t, video.AutoKeyFrame())
+	assert.False(t, video.autoRequestKeyFrame("test"))
+}
+
+func TestConsumerSwitchProducer_SerializesRequests(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	assert.NoError(t, err)
+
+	var locker sync.Mutex
+	var methods []string
+	consuming := make(chan struct{})
+	release := make(chan struct{})
+	blockConsume := false
+
+	channel := newTestChannelWith(func(method string) {
+		locker.Lock()
+		methods = append(methods, method)
+		block := blockConsume && method == "transport.consume"
+		locker.Unlock()
+
+		if block {
+			close(consuming)
+			<-release
+		}
+	})
+	producers := map[string]*Producer{}
+
+	transport := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  channel,
+		GetRouterRtpCapabilities: func() RtpCapabilities {
+			return caps
+		},
+		GetProducerById: func(producerId string) *Producer {
+			return producers[producerId]
+		},
+	})
+	transport.On("@newproducer", func(producer *Producer) {
+		producers[producer.Id()] = producer
+	})
+
+	produce := func(ssrc uint32) *Producer {
+		producer, err := transport.Produce(transportProduceParams{
+			Kind: "audio",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PayloadType: 111},
+				},
+				Encodings: []RtpEncoding{{Ssrc: ssrc}},
+			},
+		})
+		assert.NoError(t, err)
+		return producer
+	}
+
+	producer1 := produce(1111)
+	producer2 := produce(2222)
+
+	consumer, err := transport.Consume(transportConsumeParams{
+		ProducerId:      producer1.Id(),
+		RtpCapabilities: caps,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, consumer.SetPriority(3))
+
+	// Listeners may use the Consumer.
+	consumer.On("producerswitch", func(producerId string) {
+		assert.Equal(t, producerId, consumer.ProducerId())
+		assert.NoError(t, consumer.SetPriority(4))
+	})
+
+	locker.Lock()
+	blockConsume = true
+	before := len(methods)
+	locker.Unlock()
+
+	switched := make(chan error)
+	go func() { switched <- consumer.SwitchProducer(producer2.Id(), "") }()
+
+	<-consuming
+
+	paused := make(chan error)
+	go func() { paused <- consumer.Pause() }()
+
+	// Pause() waits for the switch, including the settings applied again.
+	select {
+	case <-paused:
+		t.Fatal("Pause() did not wait for SwitchProducer()")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-switched)
+	assert.NoError(t, <-paused)
+
+	locker.Lock()
+	defer locker.Unlock()
+
+	assert.Equal(t, []string{
+		"consumer.close",
+		"transport.consume",
+		"consumer.setPriority",
+		"consumer.setPriority",
+		"consumer.pause",
+	}, methods[before:])
+	assert.True(t, consumer.Paused())
+	assert.EqualValues(t, 4, consumer.Priority())
+}
@@ -0,0 +1,71 @@
+package mediasoup
+
+import "sync"
+
+/**
+ * API versioning.
+ *
+ * The Go API follows semantic versioning, its major version being the one of
+ * the mediasoup worker it drives. Renamed APIs keep working through the
+ * aliases and wrappers below, marked "Deprecated:" for linters and reported
+ * once at runtime through the DeprecationHandler. They are only removed with
+ * the next major version, which will be published under the matching module
+ * path suffix (e.g. /v4).
+ */
+const (
+	APIVersionMajor = 3
+	APIVersionMinor = 1
+	APIVersionPatch = 0
+	APIVersion      = "3.1.0"
+)
+
+// RtcpConfiguation is the former, misspelled name of RtcpParameters.
+//
+// Deprecated: use RtcpParameters.
+type RtcpConfiguation = RtcpParameters
+
+// DeprecationHandler is called the first time a deprecated API is used, with
+// its name and the name of its replacement.
+type DeprecationHandler func(name, replacement string)
+
+var (
+	deprecationLocker  sync.Mutex
+	deprecationHandler DeprecationHandler = logDeprecation
+	deprecationsSeen                      = map[string]bool{}
+)
+
+// SetDeprecationHandler replaces the handler of deprecated API uses, which
+// logs a warning by default. A nil handler disables the reports.
+func SetDeprecationHandler(handler DeprecationHandler) {
+	deprecationLocker.Lock()
+	defer deprecationLocker.Unlock()
+
+	deprecationHandler = handler
+}
+
+func logDeprecation(name, replacement string) {
+	AppLogger().Warnf("%s is deprecated and will be removed in the next major version, use %s instead",
+		name, replacement)
+}
+
+// deprecated reports the use of a deprecated API, once per API.
+func deprecated(name, replacement string) {
+	deprecationLocker.Lock()
+
+	handler := deprecationHandler
+	seen := deprecationsSeen[name]
+	deprecationsSeen[name] = true
+
+	deprecationLocker.Unlock()
+
+	if !seen && handler != nil {
+		handler(name, replacement)
+	}
+}
+
+// PlainTransport is the name of PlainRtpTransport since mediasoup 3.5.
+type PlainTransport = PlainRtpTransport
+
+// CreatePlainTransportParams are the parameters of
+// Router.CreatePlainTransport().
+type CreatePlainTransportParams = CreatePlainRtpTransportParams
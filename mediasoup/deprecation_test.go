@@ -0,0 +1,35 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated(t *testing.T) {
+	defer SetDeprecationHandler(logDeprecation)
+
+	var reports [][2]string
+
+	SetDeprecationHandler(func(name, replacement string) {
+		reports = append(reports, [2]string{name, replacement})
+	})
+
+	deprecated("TestDeprecated.Old", "TestDeprecated.New")
+	deprecated("TestDeprecated.Old", "TestDeprecated.New")
+
+	assert.Equal(t, [][2]string{{"TestDeprecated.Old", "TestDeprecated.New"}}, reports)
+
+	SetDeprecationHandler(nil)
+
+	deprecated("TestDeprecated.Other", "TestDeprecated.New")
+
+	assert.Len(t, reports, 1)
+}
+
+func TestDeprecatedAliases(t *testing.T) {
+	var rtcp RtcpConfiguation = RtcpParameters{Cname: "cname"}
+
+	assert.NoError(t, rtcp.Validate())
+	assert.Equal(t, RtcpParameters{Cname: "cname"}, RtpParameters{Rtcp: rtcp}.Rtcp)
+}
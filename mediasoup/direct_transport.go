@@ -0,0 +1,150 @@
+package mediasoup
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Transport = (*DirectTransport)(nil)
+
+/**
+ * DirectTransport connects the Go application to the Router without any
+ * network stack. RTP packets crafted in Go are sent with Producer.Send(), and
+ * the packets of its Consumers are emitted by them as "rtp" events, e.g. to
+ * mix audio server side or to run bots.
+ *
+ * @emits {rtcpPacket []byte} rtcp
+ */
+type DirectTransport struct {
+	*baseTransport
+	logger         logrus.FieldLogger
+	data           DirectTransportData
+	payloadChannel *PayloadChannel
+}
+
+func NewDirectTransport(data DirectTransportData, params createTransportParams) *DirectTransport {
+	logger := TypeLogger("DirectTransport")
+
+	logger.Debug("constructor()")
+
+	transport := &DirectTransport{
+		baseTransport:  newTransport(params),
+		logger:         logger,
+		data:           data,
+		payloadChannel: params.PayloadChannel,
+	}
+
+	transport.handleWorkerNotifications()
+
+	return transport
+}
+
+func (transport *DirectTransport) MaxMessageSize() uint32 {
+	return transport.data.MaxMessageSize
+}
+
+// Connect does nothing, a DirectTransport is always connected.
+func (transport *DirectTransport) Connect(transportConnectParams) error {
+	transport.logger.Debug("connect()")
+
+	return nil
+}
+
+/**
+ * Create a Producer whose RTP packets are sent with Producer.Send().
+ *
+ * @override
+ */
+func (transport *DirectTransport) Produce(params transportProduceParams) (producer *Producer, err error) {
+	if producer, err = transport.baseTransport.Produce(params); err != nil {
+		return
+	}
+
+	producer.payloadChannel = transport.payloadChannel
+
+	return
+}
+
+/**
+ * Create a Consumer emitting the RTP packets it receives as "rtp" events.
+ * RtpCapabilities default to the ones of the Router, so the packets are
+ * received as produced.
+ *
+ * @override
+ */
+func (transport *DirectTransport) Consume(params transportConsumeParams) (consumer *Consumer, err error) {
+	if len(params.RtpCapabilities.Codecs) == 0 {
+		params.RtpCapabilities = transport.getRouterRtpCapabilities()
+	}
+
+	if consumer, err = transport.baseTransport.Consume(params); err != nil {
+		return
+	}
+
+	consumerId := consumer.Id()
+
+	transport.payloadChannel.On(consumerId, func(event string, data json.RawMessage, payload []byte) {
+		switch event {
+		case ConsumerNotificationRtp:
+			if consumer.Closed() {
+				break
+			}
+
+			consumer.SafeEmit("rtp", payload)
+
+		default:
+			transport.logger.Errorf(`ignoring unknown event "%s" [consumerId:%s]`, event, consumerId)
+		}
+	})
+
+	consumer.Observer().Once("close", func() {
+		transport.payloadChannel.RemoveAllListeners(consumerId)
+	})
+
+	return
+}
+
+// SendRtcp sends a RTCP packet to the Router, e.g. a receiver report of the
+// Consumers.
+func (transport *DirectTransport) SendRtcp(rtcpPacket []byte) error {
+	if transport.closed {
+		return NewInvalidStateError("Transport closed")
+	}
+
+	return transport.payloadChannel.Notify("transport.sendRtcp", transport.internal, nil, rtcpPacket)
+}
+
+func (transport *DirectTransport) handleWorkerNotifications() {
+	transportId := transport.Id()
+
+	transport.payloadChannel.On(transportId, func(event string, data json.RawMessage, payload []byte) {
+		switch event {
+		case TransportNotificationRtcp:
+			if transport.closed {
+				break
+			}
+
+			transport.SafeEmit("rtcp", payload)
+
+		default:
+			transport.logger.Errorf(`ignoring unknown event "%s"`, event)
+		}
+	})
+
+	transport.observer.Once("close", func() {
+		transport.payloadChannel.RemoveAllListeners(transportId)
+	})
+}
+
+// Send a RTP packet to the Router, only for Producers of a DirectTransport.
+func (producer *Producer) Send(rtpPacket []byte) error {
+	if producer.payloadChannel == nil {
+		return NewUnsupportedError("Producer is not in a DirectTransport")
+	}
+	if producer.closed {
+		return NewInvalidStateError("Producer closed")
+	}
+
+	return producer.payloadChannel.Notify("producer.send", producer.internal, nil, rtpPacket)
+}
@@ -0,0 +1,96 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/netstring"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPayloadChannel() (channel *PayloadChannel, workerSocket net.Conn, messages chan []byte) {
+	socket, workerSocket := net.Pipe()
+	messages = make(chan []byte, 8)
+
+	go func() {
+		decoder := netstring.NewDecoder()
+		buf := make([]byte, NS_PAYLOAD_MAX_LEN)
+
+		go func() {
+			for payload := range decoder.Result() {
+				messages <- append([]byte(nil), payload...)
+			}
+		}()
+
+		for {
+			n, err := workerSocket.Read(buf)
+			if err != nil {
+				return
+			}
+			decoder.Feed(buf[:n])
+		}
+	}()
+
+	return NewPayloadChannel(socket, 0), workerSocket, messages
+}
+
+func TestCreateDirectTransport(t *testing.T) {
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	_, err := router.CreateDirectTransport(CreateDirectTransportParams{})
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	payloadChannel, workerSocket, messages := newTestPayloadChannel()
+	defer payloadChannel.Close()
+
+	router.payloadChannel = payloadChannel
+
+	transport, err := router.CreateDirectTransport(CreateDirectTransportParams{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 262144, transport.MaxMessageSize())
+	assert.NoError(t, transport.Connect(transportConnectParams{}))
+	assert.Equal(t, []Transport{transport}, router.Transports())
+
+	// RTCP from Go.
+	assert.NoError(t, transport.SendRtcp([]byte{0x80, 0xc9}))
+
+	var notification struct {
+		Event    string
+		Internal internalData
+	}
+	assert.NoError(t, json.Unmarshal(<-messages, &notification))
+	assert.Equal(t, "transport.sendRtcp", notification.Event)
+	assert.Equal(t, transport.Id(), notification.Internal.TransportId)
+	assert.Equal(t, []byte{0x80, 0xc9}, <-messages)
+
+	// RTCP from the worker.
+	received := make(chan []byte, 1)
+
+	transport.On("rtcp", func(packet []byte) {
+		received <- packet
+	})
+
+	workerSocket.Write(append(
+		netstring.Encode([]byte(`{"targetId":"`+transport.Id()+`","event":"rtcp"}`)),
+		netstring.Encode([]byte{0x80, 0xc8})...))
+
+	assert.Equal(t, []byte{0x80, 0xc8}, <-received)
+}
+
+func TestProducerSend(t *testing.T) {
+	internal := internalData{TransportId: "t1", ProducerId: "p1"}
+	producer := NewProducer(internal, producerData{Kind: MediaKindAudio}, newTestChannel(), nil, false)
+
+	assert.IsType(t, NewUnsupportedError(""), producer.Send([]byte{0x80}))
+
+	payloadChannel, _, messages := newTestPayloadChannel()
+	defer payloadChannel.Close()
+
+	producer.payloadChannel = payloadChannel
+
+	assert.NoError(t, producer.Send([]byte{0x80, 0x6f}))
+	assert.JSONEq(t, `{"event":"producer.send","internal":{"transportId":"t1","producerId":"p1"}}`,
+		string(<-messages))
+	assert.Equal(t, []byte{0x80, 0x6f}, <-messages)
+}
@@ -0,0 +1,106 @@
+package mediasoup
+
+import "fmt"
+
+/**
+ * checkDuplicateSsrcs returns a DuplicateSsrcError if the SSRCs (including
+ * RTX ones), rids or MID of the RTP parameters are already used by one of the
+ * given Producers of the Transport, or repeated within the parameters. Like
+ * in the worker, rids are only compared between Producers without MID.
+ */
+func checkDuplicateSsrcs(rtpParameters RtpParameters, producers map[string]*Producer) error {
+	ssrcs := map[uint32]string{}
+	rids := map[string]string{}
+	mids := map[string]string{}
+
+	for id, producer := range producers {
+		params := producer.RtpParameters()
+
+		for _, ssrc := range encodingSsrcs(params.Encodings) {
+			ssrcs[ssrc] = id
+		}
+		if len(params.Mid) > 0 {
+			mids[params.Mid] = id
+			continue
+		}
+		for _, encoding := range params.Encodings {
+			if len(encoding.Rid) > 0 {
+				rids[encoding.Rid] = id
+			}
+		}
+	}
+
+	if len(rtpParameters.Mid) > 0 {
+		if id, ok := mids[rtpParameters.Mid]; ok {
+			return newDuplicateSsrcError(DuplicateSsrcError{Mid: rtpParameters.Mid, ProducerId: id})
+		}
+	}
+
+	own := map[uint32]bool{}
+
+	for _, ssrc := range encodingSsrcs(rtpParameters.Encodings) {
+		if id, ok := ssrcs[ssrc]; ok {
+			return newDuplicateSsrcError(DuplicateSsrcError{Ssrc: ssrc, ProducerId: id})
+		}
+		if own[ssrc] {
+			return newDuplicateSsrcError(DuplicateSsrcError{Ssrc: ssrc})
+		}
+		own[ssrc] = true
+	}
+
+	ownRids := map[string]bool{}
+
+	for _, encoding := range rtpParameters.Encodings {
+		rid := encoding.Rid
+
+		if len(rid) == 0 {
+			continue
+		}
+		if id, ok := rids[rid]; ok && len(rtpParameters.Mid) == 0 {
+			return newDuplicateSsrcError(DuplicateSsrcError{Rid: rid, ProducerId: id})
+		}
+		if ownRids[rid] {
+			return newDuplicateSsrcError(DuplicateSsrcError{Rid: rid})
+		}
+		ownRids[rid] = true
+	}
+
+	return nil
+}
+
+// encodingSsrcs returns the media and RTX SSRCs of the encodings.
+func encodingSsrcs(encodings []RtpEncoding) (ssrcs []uint32) {
+	for _, encoding := range encodings {
+		if encoding.Ssrc != 0 {
+			ssrcs = append(ssrcs, encoding.Ssrc)
+		}
+		if encoding.Rtx != nil && encoding.Rtx.Ssrc != 0 {
+			ssrcs = append(ssrcs, encoding.Rtx.Ssrc)
+		}
+	}
+
+	return
+}
+
+func newDuplicateSsrcError(e DuplicateSsrcError) error {
+	var what string
+
+	switch {
+	case e.Ssrc != 0:
+		what = fmt.Sprintf("ssrc:%d", e.Ssrc)
+	case len(e.Rid) > 0:
+		what = fmt.Sprintf("rid:%s", e.Rid)
+	default:
+		what = fmt.Sprintf("mid:%s", e.Mid)
+	}
+
+	e.name = "DuplicateSsrcError"
+
+	if len(e.ProducerId) > 0 {
+		e.message = fmt.Sprintf("already used by another Producer [%s, producerId:%s]", what, e.ProducerId)
+	} else {
+		e.message = fmt.Sprintf("used by several encodings [%s]", what)
+	}
+
+	return e
+}
@@ -0,0 +1,66 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDuplicateSsrcs(t *testing.T) {
+	producers := map[string]*Producer{
+		"p1": {data: producerData{RtpParameters: RtpParameters{
+			Encodings: []RtpEncoding{{Ssrc: 1, Rtx: &RtpEncoding{Ssrc: 2}}},
+		}}},
+		"p2": {data: producerData{RtpParameters: RtpParameters{
+			Encodings: []RtpEncoding{{Rid: "h"}, {Rid: "l"}},
+		}}},
+		"p3": {data: producerData{RtpParameters: RtpParameters{
+			Mid:       "3",
+			Encodings: []RtpEncoding{{Rid: "m"}},
+		}}},
+	}
+
+	assert.NoError(t, checkDuplicateSsrcs(RtpParameters{
+		Encodings: []RtpEncoding{{Ssrc: 3, Rtx: &RtpEncoding{Ssrc: 4}}, {Rid: "m"}},
+	}, producers))
+
+	// Rids are not compared between Producers with MID.
+	assert.NoError(t, checkDuplicateSsrcs(RtpParameters{
+		Mid:       "4",
+		Encodings: []RtpEncoding{{Rid: "h"}},
+	}, producers))
+
+	err := checkDuplicateSsrcs(RtpParameters{
+		Encodings: []RtpEncoding{{Ssrc: 3, Rtx: &RtpEncoding{Ssrc: 2}}},
+	}, producers)
+	if assert.IsType(t, DuplicateSsrcError{}, err) {
+		assert.EqualValues(t, 2, err.(DuplicateSsrcError).Ssrc)
+		assert.Equal(t, "p1", err.(DuplicateSsrcError).ProducerId)
+	}
+
+	err = checkDuplicateSsrcs(RtpParameters{
+		Encodings: []RtpEncoding{{Rid: "l"}},
+	}, producers)
+	if assert.IsType(t, DuplicateSsrcError{}, err) {
+		assert.Equal(t, "l", err.(DuplicateSsrcError).Rid)
+		assert.Equal(t, "p2", err.(DuplicateSsrcError).ProducerId)
+	}
+
+	err = checkDuplicateSsrcs(RtpParameters{Mid: "3"}, producers)
+	if assert.IsType(t, DuplicateSsrcError{}, err) {
+		assert.Equal(t, "3", err.(DuplicateSsrcError).Mid)
+	}
+
+	err = checkDuplicateSsrcs(RtpParameters{
+		Encodings: []RtpEncoding{{Ssrc: 5}, {Ssrc: 6, Rtx: &RtpEncoding{Ssrc: 5}}},
+	}, nil)
+	if assert.IsType(t, DuplicateSsrcError{}, err) {
+		assert.Empty(t, err.(DuplicateSsrcError).ProducerId)
+		assert.Contains(t, err.Error(), "ssrc:5")
+	}
+
+	err = checkDuplicateSsrcs(RtpParameters{
+		Encodings: []RtpEncoding{{Rid: "a"}, {Rid: "a"}},
+	}, nil)
+	assert.IsType(t, DuplicateSsrcError{}, err)
+}
@@ -0,0 +1,679 @@
+package mediasoup
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder serializes state snapshots (dumps) and event payloads. Values are
+// encoded following their json struct tags whatever the format.
+type Encoder interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType, e.g. "application/json".
+	ContentType() string
+}
+
+// JSONEncoder encodes with encoding/json.
+type JSONEncoder struct{}
+
+func (JSONEncoder) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONEncoder) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JSONEncoder) ContentType() string {
+	return "application/json"
+}
+
+/**
+ * MsgpackEncoder encodes to MessagePack, which is smaller and cheaper to
+ * produce than JSON for the dumps of large Routers. Struct fields are named
+ * and omitted as their json tags say, json.RawMessage values are embedded as
+ * MessagePack and map keys are sorted.
+ *
+ * Unmarshal() decodes maps into map[string]interface{}, integers into int64
+ * (uint64 if they overflow it), floats into float64 and binaries into []byte.
+ * Other targets are filled from these generic values through encoding/json.
+ */
+type MsgpackEncoder struct{}
+
+func (MsgpackEncoder) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := msgpackEncode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (MsgpackEncoder) Unmarshal(data []byte, v interface{}) error {
+	decoder := msgpackDecoder{data: data}
+
+	value, err := decoder.decode()
+	if err != nil {
+		return err
+	}
+	if decoder.pos != len(data) {
+		return NewTypeError("msgpack: %d trailing bytes", len(data)-decoder.pos)
+	}
+
+	if ptr, ok := v.(*interface{}); ok {
+		*ptr = value
+		return nil
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, v)
+}
+
+func (MsgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+// Encode returns the response data, e.g. a dump, encoded with encoder.
+func (r Response) Encode(encoder Encoder) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if _, ok := encoder.(JSONEncoder); ok {
+		return r.data, nil
+	}
+
+	return encoder.Marshal(r.data)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+func msgpackEncode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+
+	switch v.Type() {
+	case rawMessageType:
+		return msgpackEncodeJSON(buf, v.Bytes())
+	case jsonNumberType:
+		return msgpackEncodeNumber(buf, v.String())
+	}
+
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		if v.Type().Implements(jsonMarshalerType) {
+			data, err := v.Interface().(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return err
+			}
+			return msgpackEncodeJSON(buf, data)
+		}
+		if v.Type().Implements(textMarshalerType) {
+			text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+			msgpackEncodeString(buf, string(text))
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		return msgpackEncode(buf, v.Elem())
+
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		msgpackEncodeInt(buf, v.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		msgpackEncodeUint(buf, v.Uint())
+
+	case reflect.Float32:
+		buf.WriteByte(0xca)
+		binary.Write(buf, binary.BigEndian, math.Float32bits(float32(v.Float())))
+
+	case reflect.Float64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v.Float()))
+
+	case reflect.String:
+		msgpackEncodeString(buf, v.String())
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(data), v)
+			msgpackEncodeBinary(buf, data)
+			return nil
+		}
+
+		msgpackEncodeLength(buf, v.Len(), 0x90, 0xdc)
+
+		for i := 0; i < v.Len(); i++ {
+			if err := msgpackEncode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := msgpackMapKey(iter.Key())
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			values[key] = iter.Value()
+		}
+
+		sort.Strings(keys)
+
+		msgpackEncodeLength(buf, len(keys), 0x80, 0xde)
+
+		for _, key := range keys {
+			msgpackEncodeString(buf, key)
+
+			if err := msgpackEncode(buf, values[key]); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		fields := msgpackStructFields(v.Type())
+		values := make([]reflect.Value, 0, len(fields))
+		names := make([]string, 0, len(fields))
+
+		for _, field := range fields {
+			fv, ok := fieldByIndex(v, field.index)
+			if !ok || (field.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			values = append(values, fv)
+			names = append(names, field.name)
+		}
+
+		msgpackEncodeLength(buf, len(values), 0x80, 0xde)
+
+		for i, fv := range values {
+			msgpackEncodeString(buf, names[i])
+
+			if err := msgpackEncode(buf, fv); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return NewUnsupportedError("msgpack: unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// msgpackEncodeJSON transcodes JSON data.
+func msgpackEncodeJSON(buf *bytes.Buffer, data []byte) error {
+	if len(data) == 0 {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return msgpackEncode(buf, reflect.ValueOf(value))
+}
+
+func msgpackEncodeNumber(buf *bytes.Buffer, number string) error {
+	if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+		msgpackEncodeInt(buf, i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(number, 10, 64); err == nil {
+		msgpackEncodeUint(buf, u)
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return err
+	}
+
+	return msgpackEncode(buf, reflect.ValueOf(f))
+}
+
+func msgpackEncodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		msgpackEncodeUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func msgpackEncodeUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, u)
+	}
+}
+
+func msgpackEncodeString(buf *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n <= 31:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+
+	buf.WriteString(s)
+}
+
+func msgpackEncodeBinary(buf *bytes.Buffer, data []byte) {
+	switch n := len(data); {
+	case n <= math.MaxUint8:
+		buf.Write([]byte{0xc4, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xc5)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xc6)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+
+	buf.Write(data)
+}
+
+// msgpackEncodeLength writes the header of an array (fix 0x90, 16 bits 0xdc)
+// or a map (fix 0x80, 16 bits 0xde), the 32 bits code follows the 16 bits one.
+func msgpackEncodeLength(buf *bytes.Buffer, n int, fix, code16 byte) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(code16 + 1)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func msgpackMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if key.Type().Implements(textMarshalerType) {
+		text, err := key.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+
+	return "", NewUnsupportedError("msgpack: unsupported map key type %s", key.Type())
+}
+
+type msgpackField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var msgpackFieldsCache sync.Map // reflect.Type -> []msgpackField
+
+// msgpackStructFields returns the fields encoding/json would encode, embedded
+// structs without tag being flattened.
+func msgpackStructFields(t reflect.Type) []msgpackField {
+	if fields, ok := msgpackFieldsCache.Load(t); ok {
+		return fields.([]msgpackField)
+	}
+
+	var (
+		fields []msgpackField
+		seen   = map[string]bool{}
+	)
+
+	var walk func(t reflect.Type, index []int)
+
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+
+			if tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			fieldIndex := append(append([]int{}, index...), i)
+
+			if sf.Anonymous && len(name) == 0 {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft, fieldIndex)
+					continue
+				}
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if len(name) == 0 {
+				name = sf.Name
+			}
+			// Shallower fields win, as in encoding/json.
+			if seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			fields = append(fields, msgpackField{
+				name:      name,
+				index:     fieldIndex,
+				omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			})
+		}
+	}
+
+	walk(t, nil)
+
+	msgpackFieldsCache.Store(t, fields)
+
+	return fields
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex returning false on a nil
+// embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, NewTypeError("msgpack: unexpected end of data")
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(n int) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *msgpackDecoder) decode() (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code := b[0]; {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.decodeMap(int(code & 0x0f))
+	case code&0xf0 == 0x90:
+		return d.decodeArray(int(code & 0x0f))
+	case code&0xe0 == 0xa0:
+		return d.decodeString(int(code & 0x1f))
+	}
+
+	switch code := b[0]; code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, data...), nil
+
+	case 0xca:
+		u, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (code - 0xcc))
+		if err != nil || u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), nil
+
+	case 0xd0:
+		u, err := d.readUint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := d.readUint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := d.readUint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := d.readUint(8)
+		return int64(u), err
+
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+
+	return nil, NewUnsupportedError("msgpack: unsupported code 0x%02x", b[0])
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, NewTypeError("msgpack: unexpected end of data")
+	}
+
+	array := make([]interface{}, n)
+
+	for i := range array {
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		array[i] = value
+	}
+
+	return array, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, NewTypeError("msgpack: unexpected end of data")
+	}
+
+	m := make(map[string]interface{}, n)
+
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		switch k := key.(type) {
+		case string:
+			m[k] = value
+		case int64:
+			m[strconv.FormatInt(k, 10)] = value
+		default:
+			return nil, NewUnsupportedError("msgpack: unsupported map key %v", key)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,99 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgpackEncoder(t *testing.T) {
+	encoder := MsgpackEncoder{}
+
+	type embedded struct {
+		Score int `json:"score"`
+	}
+	type value struct {
+		embedded
+		Id       string          `json:"id"`
+		Paused   bool            `json:"paused,omitempty"`
+		Ratio    float64         `json:"ratio"`
+		Negative int             `json:"negative"`
+		Big      uint64          `json:"big"`
+		Bytes    []byte          `json:"bytes"`
+		List     []string        `json:"list"`
+		Raw      json.RawMessage `json:"raw"`
+		Skipped  string          `json:"-"`
+	}
+
+	data, err := encoder.Marshal(value{
+		embedded: embedded{Score: 10},
+		Id:       "c1",
+		Ratio:    0.5,
+		Negative: -1000,
+		Big:      1 << 40,
+		Bytes:    []byte{1, 2},
+		List:     []string{"a", "b"},
+		Raw:      json.RawMessage(`{"ssrc":1111,"rid":"r0"}`),
+		Skipped:  "x",
+	})
+	assert.NoError(t, err)
+
+	var decoded interface{}
+
+	assert.NoError(t, encoder.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]interface{}{
+		"score":    int64(10),
+		"id":       "c1",
+		"ratio":    0.5,
+		"negative": int64(-1000),
+		"big":      int64(1 << 40),
+		"bytes":    []byte{1, 2},
+		"list":     []interface{}{"a", "b"},
+		"raw":      map[string]interface{}{"ssrc": int64(1111), "rid": "r0"},
+	}, decoded)
+
+	// Fixed map header of 8 entries, keys in declaration order.
+	assert.Equal(t, byte(0x88), data[0])
+	assert.Equal(t, "score", string(data[2:7]))
+
+	var params RtpParameters
+
+	data, err = encoder.Marshal(RtpParameters{
+		Mid:       "0",
+		Codecs:    []RtpCodecCapability{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, encoder.Unmarshal(data, &params))
+	assert.Equal(t, "0", params.Mid)
+	assert.Equal(t, 48000, params.Codecs[0].ClockRate)
+	assert.EqualValues(t, 1111, params.Encodings[0].Ssrc)
+
+	assert.Error(t, encoder.Unmarshal(data[:len(data)-1], &params))
+	assert.Error(t, encoder.Unmarshal(append(data, 0xc0), &params))
+}
+
+func TestResponseEncode(t *testing.T) {
+	rsp := Response{data: json.RawMessage(`{"id":"r1","transportIds":["t1","t2"]}`)}
+
+	data, err := rsp.Encode(JSONEncoder{})
+	assert.NoError(t, err)
+	assert.Equal(t, rsp.Data(), data)
+
+	data, err = rsp.Encode(MsgpackEncoder{})
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(rsp.Data()))
+
+	var dump struct {
+		Id           string   `json:"id"`
+		TransportIds []string `json:"transportIds"`
+	}
+
+	assert.NoError(t, MsgpackEncoder{}.Unmarshal(data, &dump))
+	assert.Equal(t, "r1", dump.Id)
+	assert.Equal(t, []string{"t1", "t2"}, dump.TransportIds)
+
+	_, err = Response{err: NewInvalidStateError("closed")}.Encode(MsgpackEncoder{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,257 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+// RouterMemoryUsage is the Go side accounting of the entities of a Router.
+type RouterMemoryUsage struct {
+	RouterId     string `json:"routerId"`
+	Transports   int    `json:"transports"`
+	Producers    int    `json:"producers"`
+	Consumers    int    `json:"consumers"`
+	RtpObservers int    `json:"rtpObservers"`
+	// Listeners of the worker notifications of the entities.
+	Listeners int `json:"listeners"`
+	// ApproxBytes held by the RTP capabilities and parameters of the entities,
+	// estimated from their JSON size.
+	ApproxBytes int `json:"approxBytes"`
+}
+
+// MemoryUsage returns the entity counts and the approximate memory of the
+// Router.
+func (router *Router) MemoryUsage() (usage RouterMemoryUsage) {
+	transports := router.Transports()
+	producers := router.Producers()
+	rtpObserverIds := router.rtpObserverIds()
+
+	usage.RouterId = router.Id()
+	usage.Transports = len(transports)
+	usage.Producers = len(producers)
+	usage.RtpObservers = len(rtpObserverIds)
+
+	usage.ApproxBytes += approxJSONSize(router.data.RtpCapabilities)
+	usage.Listeners += router.channel.ListenerCount(router.Id())
+
+	for _, transport := range transports {
+		usage.Listeners += router.channel.ListenerCount(transport.Id())
+
+		for _, consumer := range transport.consumerList() {
+			usage.Consumers++
+			usage.Listeners += router.channel.ListenerCount(consumer.Id())
+			usage.ApproxBytes += approxJSONSize(consumer.RtpParameters())
+		}
+	}
+
+	for _, producer := range producers {
+		usage.Listeners += router.channel.ListenerCount(producer.Id())
+		usage.ApproxBytes += approxJSONSize(producer.RtpParameters()) +
+			approxJSONSize(producer.ConsumableRtpParameters())
+	}
+
+	for _, id := range rtpObserverIds {
+		usage.Listeners += router.channel.ListenerCount(id)
+	}
+
+	return
+}
+
+// MemoryUsage returns the usage of every Router of the Worker, sorted by
+// Router id.
+func (w *Worker) MemoryUsage() []RouterMemoryUsage {
+	routers := w.routerList()
+	usages := make([]RouterMemoryUsage, 0, len(routers))
+
+	for _, router := range routers {
+		usages = append(usages, router.MemoryUsage())
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].RouterId < usages[j].RouterId
+	})
+
+	return usages
+}
+
+// PublishExpvar exports the memory usage of the Worker as the expvar name,
+// e.g. "mediasoup.worker.<pid>". Like expvar.Publish, it panics if the name
+// is already used.
+func (w *Worker) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return w.MemoryUsage()
+	}))
+}
+
+func approxJSONSize(v interface{}) int {
+	data, _ := json.Marshal(v)
+
+	return len(data)
+}
+
+// EntityLeak is an entity closed in the worker but still referenced in Go.
+type EntityLeak struct {
+	// Entity is IdEntityTransport, IdEntityProducer, IdEntityConsumer or
+	// IdEntityRtpObserver.
+	Entity string `json:"entity"`
+	Id     string `json:"id"`
+}
+
+/**
+ * Detect the entities of the Router that the worker does not know anymore
+ * but are still referenced in Go, e.g. because a close notification was
+ * lost. Entities closed in Go during the worker dump are not reported.
+ *
+ * @returns {[]EntityLeak} - Sorted by entity and id.
+ */
+func (router *Router) DetectLeaks() (leaks []EntityLeak, err error) {
+	router.logger.Debug("detectLeaks()")
+
+	// Snapshot the Go side before the dump, so entities created meanwhile
+	// are not reported.
+	candidates := router.entityIds()
+
+	var dump struct {
+		TransportIds             []string
+		RtpObserverIds           []string
+		MapProducerIdConsumerIds map[string][]string
+		MapConsumerIdProducerId  map[string]string
+	}
+
+	if err = router.Dump().Unmarshal(&dump); err != nil {
+		return
+	}
+
+	known := map[EntityLeak]bool{}
+
+	for _, id := range dump.TransportIds {
+		known[EntityLeak{IdEntityTransport, id}] = true
+	}
+	for _, id := range dump.RtpObserverIds {
+		known[EntityLeak{IdEntityRtpObserver, id}] = true
+	}
+	for id := range dump.MapProducerIdConsumerIds {
+		known[EntityLeak{IdEntityProducer, id}] = true
+	}
+	for id := range dump.MapConsumerIdProducerId {
+		known[EntityLeak{IdEntityConsumer, id}] = true
+	}
+
+	// Entities closed in Go during the dump are not referenced anymore.
+	current := map[EntityLeak]bool{}
+
+	for _, entity := range router.entityIds() {
+		current[entity] = true
+	}
+
+	for _, candidate := range candidates {
+		if !known[candidate] && current[candidate] {
+			leaks = append(leaks, candidate)
+		}
+	}
+
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Entity != leaks[j].Entity {
+			return leaks[i].Entity < leaks[j].Entity
+		}
+		return leaks[i].Id < leaks[j].Id
+	})
+
+	return
+}
+
+// entityIds returns the entities referenced by the Router in Go.
+func (router *Router) entityIds() (entities []EntityLeak) {
+	for _, transport := range router.Transports() {
+		entities = append(entities, EntityLeak{Entity: IdEntityTransport, Id: transport.Id()})
+
+		for _, consumer := range transport.consumerList() {
+			entities = append(entities, EntityLeak{Entity: IdEntityConsumer, Id: consumer.Id()})
+		}
+	}
+	for _, producer := range router.Producers() {
+		entities = append(entities, EntityLeak{Entity: IdEntityProducer, Id: producer.Id()})
+	}
+	for _, id := range router.rtpObserverIds() {
+		entities = append(entities, EntityLeak{Entity: IdEntityRtpObserver, Id: id})
+	}
+
+	return
+}
+
+type LeakDetectorOptions struct {
+	// Interval between detections, default 1 minute.
+	Interval time.Duration
+	// OnLeaks is called for every Router with leaks.
+	OnLeaks func(router *Router, leaks []EntityLeak)
+	// Clock used for the interval, default clock.System.
+	Clock clock.Clock
+}
+
+/**
+ * Run DetectLeaks() on every Router of the Worker periodically, until the
+ * returned function is called or the Worker is closed.
+ */
+func (w *Worker) WatchLeaks(options LeakDetectorOptions) (stop func()) {
+	if options.Interval == 0 {
+		options.Interval = time.Minute
+	}
+
+	stopCh := make(chan struct{})
+	ticker := clock.OrSystem(options.Clock).NewTicker(options.Interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+			}
+
+			if w.Closed() {
+				return
+			}
+
+			for _, router := range w.routerList() {
+				leaks, err := router.DetectLeaks()
+				if err != nil {
+					w.logger.Warnf("leak detection failed [routerId:%s]: %s", router.Id(), err)
+					continue
+				}
+				if len(leaks) > 0 {
+					w.logger.Warnf("leaked entities [routerId:%s, count:%d]", router.Id(), len(leaks))
+
+					if options.OnLeaks != nil {
+						options.OnLeaks(router, leaks)
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { close(stopCh) })
+	}
+}
+
+func (w *Worker) routerList() []*Router {
+	w.routersLocker.Lock()
+	defer w.routersLocker.Unlock()
+
+	routers := make([]*Router, 0, len(w.routers))
+
+	for _, router := range w.routers {
+		routers = append(routers, router)
+	}
+
+	return routers
+}
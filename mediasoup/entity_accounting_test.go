@@ -0,0 +1,84 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterMemoryUsageAndDetectLeaks(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(audioProducerParameters)
+	assert.NoError(t, err)
+
+	_, err = transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: router.RtpCapabilities(),
+	})
+	assert.NoError(t, err)
+
+	usage := router.MemoryUsage()
+	assert.Equal(t, router.Id(), usage.RouterId)
+	assert.Equal(t, 1, usage.Transports)
+	assert.Equal(t, 1, usage.Producers)
+	assert.Equal(t, 1, usage.Consumers)
+	assert.True(t, usage.Listeners >= 3)
+	assert.True(t, usage.ApproxBytes > 0)
+
+	leaks, err := router.DetectLeaks()
+	assert.NoError(t, err)
+	assert.Empty(t, leaks)
+
+	// Close the Producer in the worker only.
+	assert.NoError(t, router.channel.Request("producer.close", producer.internal).Err())
+
+	leaks, err = router.DetectLeaks()
+	assert.NoError(t, err)
+	assert.Contains(t, leaks, EntityLeak{Entity: IdEntityProducer, Id: producer.Id()})
+}
+
+func TestRouterMemoryUsage_Concurrent(t *testing.T) {
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	payloadChannel, _, _ := newTestPayloadChannel()
+	defer payloadChannel.Close()
+
+	router.payloadChannel = payloadChannel
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// As WatchLeaks() and PublishExpvar() do.
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				router.MemoryUsage()
+				router.entityIds()
+			}
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		transport, err := router.CreateDirectTransport(CreateDirectTransportParams{})
+		assert.NoError(t, err)
+
+		rtpObserver, err := router.CreateAudioLevelObserver(nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, transport.Close())
+		rtpObserver.Close()
+	}
+
+	assert.Zero(t, router.MemoryUsage().Transports)
+}
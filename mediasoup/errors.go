@@ -43,3 +43,55 @@ func NewInvalidStateError(format string, args ...interface{}) error {
 func (e InvalidStateError) Error() string {
 	return fmt.Sprintf("%s:%s", e.name, e.message)
 }
+
+// TimeoutError produced when an operation does not complete in time.
+type TimeoutError struct {
+	name    string
+	message string
+}
+
+func NewTimeoutError(format string, args ...interface{}) error {
+	return TimeoutError{
+		name:    "TimeoutError",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("%s:%s", e.name, e.message)
+}
+
+// UnauthorizedError produced when a request is not authorized.
+type UnauthorizedError struct {
+	name    string
+	message string
+}
+
+func NewUnauthorizedError(format string, args ...interface{}) error {
+	return UnauthorizedError{
+		name:    "UnauthorizedError",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e UnauthorizedError) Error() string {
+	return fmt.Sprintf("%s:%s", e.name, e.message)
+}
+
+// DuplicateSsrcError produced when a Producer uses a SSRC, rid or MID
+// already used by another Producer of the same Transport.
+type DuplicateSsrcError struct {
+	name    string
+	message string
+	// Ssrc, Rid or Mid in conflict.
+	Ssrc uint32
+	Rid  string
+	Mid  string
+	// ProducerId of the Producer already using it, empty if the conflict is
+	// within the RTP parameters themselves.
+	ProducerId string
+}
+
+func (e DuplicateSsrcError) Error() string {
+	return fmt.Sprintf("%s:%s", e.name, e.message)
+}
@@ -1,9 +1,11 @@
 package mediasoup
 
 import (
+	"fmt"
 	"reflect"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -35,6 +37,38 @@ type (
 	}
 )
 
+// HandlerError is a panic recovered from an event listener.
+type HandlerError struct {
+	Event string
+	// Value given to panic().
+	Value interface{}
+	Stack []byte
+}
+
+func (e HandlerError) Error() string {
+	return fmt.Sprintf(`panic in "%s" listener: %v`, e.Event, e.Value)
+}
+
+var (
+	handlerErrorLocker   sync.RWMutex
+	handlerErrorCallback func(err HandlerError)
+	handlerPanics        uint64
+)
+
+// OnHandlerError sets the callback called with every panic recovered from an
+// event listener, nil removes it. Panics are always logged.
+func OnHandlerError(callback func(err HandlerError)) {
+	handlerErrorLocker.Lock()
+	defer handlerErrorLocker.Unlock()
+
+	handlerErrorCallback = callback
+}
+
+// HandlerPanics returns the number of panics recovered from event listeners.
+func HandlerPanics() uint64 {
+	return atomic.LoadUint64(&handlerPanics)
+}
+
 func NewEventEmitter(logger logrus.FieldLogger) EventEmitter {
 	return &eventEmitter{
 		logger: logger,
@@ -42,29 +76,35 @@ func NewEventEmitter(logger logrus.FieldLogger) EventEmitter {
 }
 
 func (e *eventEmitter) AddListener(evt string, listeners ...interface{}) {
-	if len(listeners) == 0 {
-		return
+	for _, listener := range listeners {
+		e.addListener(evt, listener, false)
 	}
+}
 
-	var listenerValues []*intervalListener
+func (e *eventEmitter) Once(evt string, listener interface{}) {
+	e.addListener(evt, listener, true)
+}
 
-	for _, listener := range listeners {
-		listenerValue := reflect.ValueOf(listener)
-		listenerType := listenerValue.Type()
+// addListener adds the listener if it's a function, returning its entry.
+func (e *eventEmitter) addListener(evt string, listener interface{}, once bool) *intervalListener {
+	listenerValue := reflect.ValueOf(listener)
 
-		if listenerType.Kind() != reflect.Func {
-			continue
-		}
-		var argTypes []reflect.Type
+	if listenerValue.Kind() != reflect.Func {
+		return nil
+	}
 
-		for i := 0; i < listenerType.NumIn(); i++ {
-			argTypes = append(argTypes, listenerType.In(i))
-		}
+	listenerType := listenerValue.Type()
+
+	var argTypes []reflect.Type
 
-		listenerValues = append(listenerValues, &intervalListener{
-			FuncValue: listenerValue,
-			ArgTypes:  argTypes,
-		})
+	for i := 0; i < listenerType.NumIn(); i++ {
+		argTypes = append(argTypes, listenerType.In(i))
+	}
+
+	item := &intervalListener{
+		FuncValue: listenerValue,
+		ArgTypes:  argTypes,
+		Once:      once,
 	}
 
 	e.mu.Lock()
@@ -74,29 +114,14 @@ func (e *eventEmitter) AddListener(evt string, listeners ...interface{}) {
 		e.evtListeners = make(map[string][]*intervalListener)
 	}
 
-	e.evtListeners[evt] = append(e.evtListeners[evt], listenerValues...)
-}
-
-func (e *eventEmitter) Once(evt string, listener interface{}) {
-	e.AddListener(evt, listener)
-
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	listenerPointer := reflect.ValueOf(listener).Pointer()
-	listeners := e.evtListeners[evt]
+	e.evtListeners[evt] = append(e.evtListeners[evt], item)
 
-	for i := len(listeners) - 1; i >= 0; i-- {
-		item := listeners[i]
-
-		if item.FuncValue.Pointer() == listenerPointer {
-			item.Once = true
-			break
-		}
-	}
+	return item
 }
 
-// Emit fires a particular event
+// Emit fires a particular event. A panicking listener does not prevent the
+// other listeners from being called, the first panic is returned as a
+// HandlerError.
 func (e *eventEmitter) Emit(evt string, argv ...interface{}) (err error) {
 	e.mu.Lock()
 
@@ -105,7 +130,15 @@ func (e *eventEmitter) Emit(evt string, argv ...interface{}) (err error) {
 		return // has no listeners to emit yet
 	}
 
-	listeners := e.evtListeners[evt][:]
+	// Copy the listeners, they may be removed meanwhile. Once listeners are
+	// removed right away so concurrent emits don't call them twice.
+	listeners := append([]*intervalListener(nil), e.evtListeners[evt]...)
+
+	for _, listener := range listeners {
+		if listener.Once {
+			e.removeListenerLocked(evt, listener)
+		}
+	}
 
 	e.mu.Unlock()
 
@@ -135,16 +168,42 @@ func (e *eventEmitter) Emit(evt string, argv ...interface{}) (err error) {
 			}
 		}
 
-		listener.FuncValue.Call(actualCallArgs)
-
-		if listener.Once {
-			e.RemoveListener(evt, listener)
+		if callErr := e.callListener(evt, listener, actualCallArgs); callErr != nil && err == nil {
+			err = callErr
 		}
 	}
 
 	return
 }
 
+func (e *eventEmitter) callListener(
+	evt string, listener *intervalListener, args []reflect.Value,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			handlerErr := HandlerError{Event: evt, Value: r, Stack: debug.Stack()}
+
+			atomic.AddUint64(&handlerPanics, 1)
+
+			e.logger.WithField("event", evt).Errorf("listener panic: %v", r)
+
+			handlerErrorLocker.RLock()
+			callback := handlerErrorCallback
+			handlerErrorLocker.RUnlock()
+
+			if callback != nil {
+				callback(handlerErr)
+			}
+
+			err = handlerErr
+		}
+	}()
+
+	listener.FuncValue.Call(args)
+
+	return
+}
+
 // SafaEmit fires a particular event and ignore panic.
 func (e *eventEmitter) SafeEmit(evt string, argv ...interface{}) {
 	defer func() {
@@ -161,10 +220,6 @@ func (e *eventEmitter) SafeEmit(evt string, argv ...interface{}) {
 }
 
 func (e *eventEmitter) RemoveListener(evt string, listener interface{}) (ok bool) {
-	if e.evtListeners == nil {
-		return
-	}
-
 	if listener == nil {
 		return
 	}
@@ -172,31 +227,43 @@ func (e *eventEmitter) RemoveListener(evt string, listener interface{}) (ok bool
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	idx := -1
 	listenerPointer := reflect.ValueOf(listener).Pointer()
-	listeners := e.evtListeners[evt]
 
-	for index, item := range listeners {
+	for _, item := range e.evtListeners[evt] {
 		if listener == item ||
 			item.FuncValue.Pointer() == listenerPointer {
-			idx = index
-			break
+			return e.removeListenerLocked(evt, item)
 		}
 	}
 
-	if idx < 0 {
-		return
-	}
+	return
+}
 
-	var modifiedListeners []*intervalListener
+// removeListenerLocked removes the entry, must be called with the lock held.
+// The slice is copied since Emit() may be iterating over the previous one.
+func (e *eventEmitter) removeListenerLocked(evt string, listener *intervalListener) bool {
+	listeners := e.evtListeners[evt]
 
-	if len(listeners) > 1 {
-		modifiedListeners = append(listeners[:idx], listeners[idx+1:]...)
-	}
+	for index, item := range listeners {
+		if item != listener {
+			continue
+		}
+
+		var modifiedListeners []*intervalListener
+
+		modifiedListeners = append(modifiedListeners, listeners[:index]...)
+		modifiedListeners = append(modifiedListeners, listeners[index+1:]...)
 
-	e.evtListeners[evt] = modifiedListeners
+		if len(modifiedListeners) > 0 {
+			e.evtListeners[evt] = modifiedListeners
+		} else {
+			delete(e.evtListeners, evt)
+		}
+
+		return true
+	}
 
-	return true
+	return false
 }
 
 func (e *eventEmitter) RemoveAllListeners(evt string) {
@@ -84,3 +84,32 @@ func TestEventEmitter_RemoveAllListeners(t *testing.T) {
 	assert.Equal(t, 0, onObserver.CalledTimes())
 	assert.Equal(t, 0, emitter.ListenerCount(evName))
 }
+
+func TestEventEmitter_EmitRecoversListenerPanic(t *testing.T) {
+	evName := "test"
+	logger := TypeLogger("eventEmitter")
+	emitter := NewEventEmitter(logger)
+
+	var handlerErrors []HandlerError
+
+	OnHandlerError(func(err HandlerError) {
+		handlerErrors = append(handlerErrors, err)
+	})
+	defer OnHandlerError(nil)
+
+	panics := HandlerPanics()
+	observer := NewMockFunc(t)
+
+	emitter.On(evName, func() { panic("boom") })
+	emitter.On(evName, observer.Fn())
+
+	err := emitter.Emit(evName)
+	assert.IsType(t, HandlerError{}, err)
+	assert.EqualError(t, err, `panic in "test" listener: boom`)
+	observer.ExpectCalledTimes(1)
+
+	assert.Len(t, handlerErrors, 1)
+	assert.Equal(t, "boom", handlerErrors[0].Value)
+	assert.NotEmpty(t, handlerErrors[0].Stack)
+	assert.Equal(t, panics+1, HandlerPanics())
+}
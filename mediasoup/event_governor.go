@@ -0,0 +1,144 @@
+package mediasoup
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+// Events subject to the EventGovernor.
+const (
+	GovernedEventProducerScore = "producer.score"
+	GovernedEventConsumerScore = "consumer.score"
+	GovernedEventVolumes       = "audiolevelobserver.volumes"
+)
+
+type EventGovernorOptions struct {
+	// MaxRate is the maximum number of emitted events per second, per
+	// governed event across all the Workers.
+	MaxRate float64
+	// Window the rate is measured on, default 1s.
+	Window time.Duration
+	// OnShed, if set, is called at the end of every window events were shed
+	// in, and once when shedding stops.
+	OnShed func(notice ShedNotice)
+	// Clock of the windows, default clock.System.
+	Clock clock.Clock
+}
+
+// ShedNotice reports the shedding of a governed event in the last window.
+type ShedNotice struct {
+	Event string
+	// Rate of the events received, per second.
+	Rate float64
+	// Dropped events in the window.
+	Dropped uint64
+	// SampleEvery is the sampling of the next window, 1 meaning no sampling.
+	SampleEvery uint64
+}
+
+type governedEventState struct {
+	windowStart time.Time
+	received    uint64
+	emitted     uint64
+	sampleEvery uint64
+}
+
+/**
+ * EventGovernor protects the control plane of huge rooms (e.g. 10k
+ * participant broadcasts) from the volume of score and audio level events.
+ * Above MaxRate, only one event out of SampleEvery is emitted, SampleEvery
+ * being computed from the rate of the previous window, and the events above
+ * the budget of the window are dropped. The state of the entities (e.g.
+ * Producer.Score()) is still updated by every notification.
+ */
+type EventGovernor struct {
+	locker  sync.Mutex
+	options EventGovernorOptions
+	events  map[string]*governedEventState
+}
+
+func NewEventGovernor(options EventGovernorOptions) *EventGovernor {
+	if options.Window == 0 {
+		options.Window = time.Second
+	}
+	options.Clock = clock.OrSystem(options.Clock)
+
+	return &EventGovernor{
+		options: options,
+		events:  make(map[string]*governedEventState),
+	}
+}
+
+var (
+	eventGovernorLocker sync.Mutex
+	eventGovernor       *EventGovernor
+)
+
+// SetEventGovernor governs the score and volumes events of all the Workers,
+// a nil governor disabling it (the default).
+func SetEventGovernor(governor *EventGovernor) {
+	eventGovernorLocker.Lock()
+	defer eventGovernorLocker.Unlock()
+
+	eventGovernor = governor
+}
+
+// allowGovernedEvent tells whether the governed event can be emitted.
+func allowGovernedEvent(event string) bool {
+	eventGovernorLocker.Lock()
+	governor := eventGovernor
+	eventGovernorLocker.Unlock()
+
+	return governor == nil || governor.Allow(event)
+}
+
+// Allow accounts an event and tells whether it can be emitted.
+func (governor *EventGovernor) Allow(event string) bool {
+	var notice *ShedNotice
+
+	defer func() {
+		if notice != nil && governor.options.OnShed != nil {
+			governor.options.OnShed(*notice)
+		}
+	}()
+
+	governor.locker.Lock()
+	defer governor.locker.Unlock()
+
+	now := governor.options.Clock.Now()
+	budget := uint64(math.Max(1, governor.options.MaxRate*governor.options.Window.Seconds()))
+
+	state, ok := governor.events[event]
+	if !ok {
+		state = &governedEventState{windowStart: now, sampleEvery: 1}
+		governor.events[event] = state
+	}
+
+	if elapsed := now.Sub(state.windowStart); elapsed >= governor.options.Window {
+		dropped := state.received - state.emitted
+		sampleEvery := uint64(math.Max(1, math.Ceil(float64(state.received)/float64(budget))))
+
+		if dropped > 0 || sampleEvery != state.sampleEvery {
+			notice = &ShedNotice{
+				Event:       event,
+				Rate:        float64(state.received) / elapsed.Seconds(),
+				Dropped:     dropped,
+				SampleEvery: sampleEvery,
+			}
+		}
+
+		*state = governedEventState{windowStart: now, sampleEvery: sampleEvery}
+	}
+
+	state.received++
+
+	if state.emitted >= budget || (state.received-1)%state.sampleEvery != 0 {
+		return false
+	}
+	state.emitted++
+
+	return true
+}
@@ -0,0 +1,78 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventGovernor(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(1000, 0))
+
+	var notices []ShedNotice
+
+	governor := NewEventGovernor(EventGovernorOptions{
+		MaxRate: 5,
+		Clock:   fakeClock,
+		OnShed:  func(notice ShedNotice) { notices = append(notices, notice) },
+	})
+
+	emit := func(n int) (emitted int) {
+		for i := 0; i < n; i++ {
+			if governor.Allow(GovernedEventConsumerScore) {
+				emitted++
+			}
+		}
+		return
+	}
+
+	// Burst, the events above the budget are dropped.
+	assert.Equal(t, 5, emit(20))
+	assert.Empty(t, notices)
+
+	// Sampled one out of 4 events.
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, 5, emit(20))
+	assert.Equal(t, []ShedNotice{
+		{Event: GovernedEventConsumerScore, Rate: 20, Dropped: 15, SampleEvery: 4},
+	}, notices)
+
+	// Other events have their own budget.
+	assert.True(t, governor.Allow(GovernedEventVolumes))
+
+	// Back to normal.
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, 1, emit(2))
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, 2, emit(2))
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, 2, emit(2))
+
+	assert.Len(t, notices, 3)
+	assert.Equal(t, ShedNotice{Event: GovernedEventConsumerScore, Rate: 2, Dropped: 1, SampleEvery: 1}, notices[2])
+}
+
+func TestEventGovernor_ProducerScore(t *testing.T) {
+	SetEventGovernor(NewEventGovernor(EventGovernorOptions{
+		MaxRate: 1,
+		Clock:   testutil.NewFakeClock(time.Unix(1000, 0)),
+	}))
+	defer SetEventGovernor(nil)
+
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{Kind: MediaKindAudio},
+		newTestChannel(), nil, false)
+
+	emitted := 0
+	producer.On("score", func(score []ProducerScore) { emitted++ })
+
+	for i := 1; i <= 3; i++ {
+		data, _ := json.Marshal([]ProducerScore{{Ssrc: 1111, Score: uint8(i)}})
+		producer.channel.SafeEmit("p1", ProducerNotificationScore, json.RawMessage(data))
+	}
+
+	assert.Equal(t, 1, emitted)
+	assert.EqualValues(t, 3, producer.Score()[0].Score)
+}
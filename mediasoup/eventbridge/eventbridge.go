@@ -0,0 +1,269 @@
+// Package eventbridge publishes mediasoup entity lifecycle and quality events
+// to a message broker such as NATS or Kafka.
+//
+// The broker client is not a dependency of this package, it is plugged in
+// through the Publisher interface. For instance a *nats.Conn can be used as is:
+//
+//	bridge := eventbridge.New(natsConn)
+//	bridge.WatchWorker(worker)
+//
+// and a Kafka writer with a small adapter:
+//
+//	eventbridge.PublisherFunc(func(topic string, data []byte) error {
+//		return writer.WriteMessages(ctx, kafka.Message{Topic: topic, Value: data})
+//	})
+package eventbridge
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	"github.com/sirupsen/logrus"
+)
+
+// Publisher sends a serialized event to a topic (NATS subject, Kafka topic...).
+type Publisher interface {
+	Publish(topic string, data []byte) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(topic string, data []byte) error
+
+func (fn PublisherFunc) Publish(topic string, data []byte) error {
+	return fn(topic, data)
+}
+
+// Serializer encodes an event, e.g. to JSON or to a protobuf message.
+type Serializer func(event Event) ([]byte, error)
+
+// JSONSerializer is the default Serializer.
+func JSONSerializer(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// MsgpackSerializer encodes events to MessagePack, see mediasoup.MsgpackEncoder.
+func MsgpackSerializer(event Event) ([]byte, error) {
+	return mediasoup.MsgpackEncoder{}.Marshal(event)
+}
+
+// EncoderSerializer adapts a mediasoup.Encoder to Serializer.
+func EncoderSerializer(encoder mediasoup.Encoder) Serializer {
+	return func(event Event) ([]byte, error) {
+		return encoder.Marshal(event)
+	}
+}
+
+// Event published to the broker.
+type Event struct {
+	// Entity is one of "worker", "router", "transport", "producer",
+	// "consumer" or "talktime".
+	Entity string `json:"entity"`
+	// Type is the observer event, e.g. "new", "close", "score".
+	Type      string      `json:"type"`
+	Id        string      `json:"id"`
+	ParentId  string      `json:"parentId,omitempty"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+	AppData   interface{} `json:"appData,omitempty"`
+}
+
+type Option func(bridge *Bridge)
+
+// WithTopicPrefix sets the prefix of the topics, default "mediasoup". Events
+// are published to "<prefix>.<entity>.<type>".
+func WithTopicPrefix(prefix string) Option {
+	return func(bridge *Bridge) {
+		bridge.topicFunc = func(event Event) string {
+			return prefix + "." + event.Entity + "." + event.Type
+		}
+	}
+}
+
+// WithTopicFunc chooses the topic of every event.
+func WithTopicFunc(topicFunc func(event Event) string) Option {
+	return func(bridge *Bridge) {
+		bridge.topicFunc = topicFunc
+	}
+}
+
+// WithSerializer replaces JSONSerializer.
+func WithSerializer(serializer Serializer) Option {
+	return func(bridge *Bridge) {
+		bridge.serializer = serializer
+	}
+}
+
+// WithErrorHandler is called when an event cannot be serialized or published.
+// By default errors are logged.
+func WithErrorHandler(handler func(err error, event Event)) Option {
+	return func(bridge *Bridge) {
+		bridge.errorHandler = handler
+	}
+}
+
+// Bridge subscribes to the observers of mediasoup entities and publishes their
+// events.
+type Bridge struct {
+	logger       logrus.FieldLogger
+	publisher    Publisher
+	serializer   Serializer
+	topicFunc    func(event Event) string
+	errorHandler func(err error, event Event)
+}
+
+func New(publisher Publisher, options ...Option) *Bridge {
+	logger := mediasoup.TypeLogger("EventBridge")
+
+	bridge := &Bridge{
+		logger:     logger,
+		publisher:  publisher,
+		serializer: JSONSerializer,
+		errorHandler: func(err error, event Event) {
+			logger.Errorf("publishing event failed [entity:%s, type:%s]: %s",
+				event.Entity, event.Type, err)
+		},
+	}
+
+	WithTopicPrefix("mediasoup")(bridge)
+
+	for _, option := range options {
+		option(bridge)
+	}
+
+	return bridge
+}
+
+// WatchWorker publishes the events of the Worker and of every Router created
+// from now on.
+func (bridge *Bridge) WatchWorker(worker *mediasoup.Worker) {
+	id := workerId(worker)
+
+	bridge.publish(Event{Entity: "worker", Type: "new", Id: id, AppData: worker.AppData()})
+
+	worker.Observer().On("newrouter", func(router *mediasoup.Router) {
+		bridge.watchRouter(router, id)
+	})
+	worker.Observer().On("close", func() {
+		bridge.publish(Event{Entity: "worker", Type: "close", Id: id})
+	})
+}
+
+// WatchRouter publishes the events of the Router and of every Transport,
+// Producer and Consumer created in it from now on.
+func (bridge *Bridge) WatchRouter(router *mediasoup.Router) {
+	bridge.watchRouter(router, "")
+}
+
+func (bridge *Bridge) watchRouter(router *mediasoup.Router, parentId string) {
+	id := router.Id()
+
+	bridge.publish(Event{
+		Entity: "router", Type: "new", Id: id, ParentId: parentId, AppData: router.AppData(),
+	})
+
+	router.Observer().On("newtransport", func(transport mediasoup.Transport) {
+		bridge.watchTransport(transport, id)
+	})
+	router.Observer().On("close", func() {
+		bridge.publish(Event{Entity: "router", Type: "close", Id: id, ParentId: parentId})
+	})
+}
+
+func (bridge *Bridge) watchTransport(transport mediasoup.Transport, parentId string) {
+	id := transport.Id()
+
+	bridge.publish(Event{
+		Entity: "transport", Type: "new", Id: id, ParentId: parentId, AppData: transport.AppData(),
+	})
+
+	transport.Observer().On("newproducer", func(producer *mediasoup.Producer) {
+		bridge.watchProducer(producer, id)
+	})
+	transport.Observer().On("newconsumer", func(consumer *mediasoup.Consumer) {
+		bridge.watchConsumer(consumer, id)
+	})
+	transport.Observer().On("close", func() {
+		bridge.publish(Event{Entity: "transport", Type: "close", Id: id, ParentId: parentId})
+	})
+}
+
+func (bridge *Bridge) watchProducer(producer *mediasoup.Producer, parentId string) {
+	id := producer.Id()
+	newEvent := func(typ string, data interface{}) Event {
+		return Event{Entity: "producer", Type: typ, Id: id, ParentId: parentId, Data: data}
+	}
+
+	event := newEvent("new", map[string]interface{}{
+		"kind": producer.Kind(), "type": producer.Type(), "paused": producer.Paused(),
+	})
+	event.AppData = producer.AppData()
+	bridge.publish(event)
+
+	observer := producer.Observer()
+
+	observer.On("close", func() { bridge.publish(newEvent("close", nil)) })
+	observer.On("pause", func() { bridge.publish(newEvent("pause", nil)) })
+	observer.On("resume", func() { bridge.publish(newEvent("resume", nil)) })
+	observer.On("score", func(score []mediasoup.ProducerScore) {
+		bridge.publish(newEvent("score", score))
+	})
+}
+
+func (bridge *Bridge) watchConsumer(consumer *mediasoup.Consumer, parentId string) {
+	id := consumer.Id()
+	newEvent := func(typ string, data interface{}) Event {
+		return Event{Entity: "consumer", Type: typ, Id: id, ParentId: parentId, Data: data}
+	}
+
+	event := newEvent("new", map[string]interface{}{
+		"kind":       consumer.Kind(),
+		"type":       consumer.Type(),
+		"producerId": consumer.ProducerId(),
+		"paused":     consumer.Paused(),
+	})
+	event.AppData = consumer.AppData()
+	bridge.publish(event)
+
+	observer := consumer.Observer()
+
+	observer.On("close", func() { bridge.publish(newEvent("close", nil)) })
+	observer.On("pause", func() { bridge.publish(newEvent("pause", nil)) })
+	observer.On("resume", func() { bridge.publish(newEvent("resume", nil)) })
+	observer.On("score", func(score mediasoup.ConsumerScore) {
+		bridge.publish(newEvent("score", score))
+	})
+	observer.On("layerschange", func(layer mediasoup.VideoLayer) {
+		bridge.publish(newEvent("layerschange", layer))
+	})
+}
+
+// WatchTalkTime publishes the "speakerturn" and "interruption" events of the
+// TalkTimeTracker of a room, with the room id as event id.
+func (bridge *Bridge) WatchTalkTime(tracker *mediasoup.TalkTimeTracker, roomId string) {
+	tracker.On("speakerturn", func(turn mediasoup.SpeakerTurn) {
+		bridge.publish(Event{Entity: "talktime", Type: "speakerturn", Id: roomId, Data: turn})
+	})
+	tracker.On("interruption", func(interruption mediasoup.TalkInterruption) {
+		bridge.publish(Event{Entity: "talktime", Type: "interruption", Id: roomId, Data: interruption})
+	})
+}
+
+func (bridge *Bridge) publish(event Event) {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+
+	data, err := bridge.serializer(event)
+	if err == nil {
+		err = bridge.publisher.Publish(bridge.topicFunc(event), data)
+	}
+	if err != nil {
+		bridge.errorHandler(err, event)
+	}
+}
+
+func workerId(worker *mediasoup.Worker) string {
+	return "worker-" + strconv.Itoa(worker.Pid())
+}
@@ -0,0 +1,93 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgePublish(t *testing.T) {
+	var topics []string
+	var payloads [][]byte
+
+	bridge := New(PublisherFunc(func(topic string, data []byte) error {
+		topics = append(topics, topic)
+		payloads = append(payloads, data)
+		return nil
+	}))
+
+	bridge.publish(Event{Entity: "consumer", Type: "score", Id: "c1", Data: map[string]int{"score": 10}})
+
+	assert.Equal(t, []string{"mediasoup.consumer.score"}, topics)
+
+	var event Event
+	assert.NoError(t, json.Unmarshal(payloads[0], &event))
+	assert.Equal(t, "c1", event.Id)
+	assert.NotZero(t, event.Timestamp)
+}
+
+func TestBridgePublish_Options(t *testing.T) {
+	var topic string
+	var payload []byte
+	var publishErr error
+
+	bridge := New(
+		PublisherFunc(func(t string, data []byte) error {
+			topic, payload = t, data
+			return errors.New("broker down")
+		}),
+		WithTopicPrefix("sfu.eu"),
+		WithSerializer(func(event Event) ([]byte, error) {
+			return []byte(event.Id), nil
+		}),
+		WithErrorHandler(func(err error, event Event) {
+			publishErr = err
+		}),
+	)
+
+	bridge.publish(Event{Entity: "router", Type: "close", Id: "r1"})
+
+	assert.Equal(t, "sfu.eu.router.close", topic)
+	assert.Equal(t, "r1", string(payload))
+	assert.EqualError(t, publishErr, "broker down")
+}
+
+func TestBridgePublish_Msgpack(t *testing.T) {
+	var payload []byte
+
+	bridge := New(
+		PublisherFunc(func(topic string, data []byte) error {
+			payload = data
+			return nil
+		}),
+		WithSerializer(MsgpackSerializer),
+	)
+
+	bridge.publish(Event{Entity: "consumer", Type: "score", Id: "c1", Data: map[string]int{"score": 10}})
+
+	var event Event
+	assert.NoError(t, mediasoup.MsgpackEncoder{}.Unmarshal(payload, &event))
+	assert.Equal(t, "c1", event.Id)
+	assert.Equal(t, map[string]interface{}{"score": float64(10)}, event.Data)
+	assert.NotZero(t, event.Timestamp)
+}
+
+func TestBridgeWatchTalkTime(t *testing.T) {
+	var topics []string
+
+	bridge := New(PublisherFunc(func(topic string, data []byte) error {
+		topics = append(topics, topic)
+		return nil
+	}))
+
+	tracker := mediasoup.NewTalkTimeTracker(mediasoup.TalkTimeTrackerOptions{})
+	bridge.WatchTalkTime(tracker, "room1")
+
+	tracker.SafeEmit("interruption", mediasoup.TalkInterruption{PeerId: "bob"})
+	tracker.SafeEmit("speakerturn", mediasoup.SpeakerTurn{PeerId: "alice"})
+
+	assert.Equal(t, []string{"mediasoup.talktime.interruption", "mediasoup.talktime.speakerturn"}, topics)
+}
@@ -0,0 +1,110 @@
+package mediasoup
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// FeatureFlags gate experimental behaviors so deployments can opt in
+// gradually. The package defaults are read from the comma separated
+// MEDIASOUP_FEATURE_FLAGS environment variable (e.g. "strictValidation") and
+// can be overridden per Worker with WithFeatureFlags().
+type FeatureFlags struct {
+	// FlatBuffersChannel is reserved for the FlatBuffers channel format of
+	// mediasoup 3.13+ workers. Channel only speaks the JSON format of the
+	// workers this package runs, so the flag has no effect.
+	FlatBuffersChannel bool
+	// SvcShaping is reserved for shaping the layers an SVC Consumer forwards
+	// beyond its preferred layers. The worker has no such request, so the
+	// flag has no effect.
+	SvcShaping bool
+	// StrictValidation rejects RTP parameters given to Produce() with unknown
+	// header extensions. Duplicated encodings are always rejected, see
+	// DuplicateSsrcError.
+	StrictValidation bool
+}
+
+var featureFlagNames = map[string]func(f *FeatureFlags) *bool{
+	"flatBuffersChannel": func(f *FeatureFlags) *bool { return &f.FlatBuffersChannel },
+	"svcShaping":         func(f *FeatureFlags) *bool { return &f.SvcShaping },
+	"strictValidation":   func(f *FeatureFlags) *bool { return &f.StrictValidation },
+}
+
+var (
+	featureFlagsLocker  sync.RWMutex
+	defaultFeatureFlags FeatureFlags
+)
+
+func init() {
+	flags, err := ParseFeatureFlags(os.Getenv("MEDIASOUP_FEATURE_FLAGS"))
+	if err != nil {
+		AppLogger().Warnf("MEDIASOUP_FEATURE_FLAGS: %s", err)
+	}
+
+	defaultFeatureFlags = flags
+}
+
+// ParseFeatureFlags parses a comma separated list of flag names. Unknown names
+// are reported as a TypeError, the known ones are still enabled.
+func ParseFeatureFlags(names string) (flags FeatureFlags, err error) {
+	var unknown []string
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+
+		if len(name) == 0 {
+			continue
+		}
+
+		if flag, ok := featureFlagNames[name]; ok {
+			*flag(&flags) = true
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		err = NewTypeError("unknown feature flags: %s", strings.Join(unknown, ","))
+	}
+
+	return
+}
+
+// Enabled returns whether the flag with the given name is enabled.
+func (flags FeatureFlags) Enabled(name string) bool {
+	if flag, ok := featureFlagNames[name]; ok {
+		return *flag(&flags)
+	}
+
+	return false
+}
+
+// DefaultFeatureFlags returns the flags used by Workers created without
+// WithFeatureFlags().
+func DefaultFeatureFlags() FeatureFlags {
+	featureFlagsLocker.RLock()
+	defer featureFlagsLocker.RUnlock()
+
+	return defaultFeatureFlags
+}
+
+// SetDefaultFeatureFlags replaces the package defaults. Existing Workers keep
+// their flags.
+func SetDefaultFeatureFlags(flags FeatureFlags) {
+	featureFlagsLocker.Lock()
+	defer featureFlagsLocker.Unlock()
+
+	defaultFeatureFlags = flags
+}
+
+// validateRtpParametersStrict implements the StrictValidation feature flag.
+func validateRtpParametersStrict(params RtpParameters) error {
+	for _, ext := range params.HeaderExtensions {
+		if len(HeaderExtensionName(ext.Uri)) == 0 {
+			return NewTypeError("unknown header extension [uri:%s]", ext.Uri)
+		}
+	}
+
+	return nil
+}
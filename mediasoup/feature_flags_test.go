@@ -0,0 +1,46 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeatureFlags(t *testing.T) {
+	flags, err := ParseFeatureFlags(" strictValidation,,")
+	assert.NoError(t, err)
+	assert.Equal(t, FeatureFlags{StrictValidation: true}, flags)
+	assert.True(t, flags.Enabled("strictValidation"))
+	assert.False(t, flags.Enabled("unknown"))
+
+	// Reserved flags are accepted.
+	flags, err = ParseFeatureFlags("flatBuffersChannel,svcShaping")
+	assert.NoError(t, err)
+	assert.Equal(t, FeatureFlags{FlatBuffersChannel: true, SvcShaping: true}, flags)
+
+	flags, err = ParseFeatureFlags("strictValidation,foo")
+	assert.IsType(t, NewTypeError(""), err)
+	assert.True(t, flags.StrictValidation)
+}
+
+func TestSetDefaultFeatureFlags(t *testing.T) {
+	defaults := DefaultFeatureFlags()
+	defer SetDefaultFeatureFlags(defaults)
+
+	SetDefaultFeatureFlags(FeatureFlags{StrictValidation: true})
+
+	assert.True(t, DefaultFeatureFlags().StrictValidation)
+}
+
+func TestValidateRtpParametersStrict(t *testing.T) {
+	params := RtpParameters{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncoding{{Rid: "r0"}, {Rid: "r1"}},
+	}
+	assert.NoError(t, validateRtpParametersStrict(params))
+
+	params.HeaderExtensions = append(params.HeaderExtensions, RtpHeaderExtension{Uri: "urn:foo", Id: 2})
+	assert.IsType(t, NewTypeError(""), validateRtpParametersStrict(params))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -243,6 +244,66 @@ type RtpH264Parameter struct {
 	LevelAsymmetryAllowed int    `json:"level-asymmetry-allowed,omitempty"`
 }
 
+/**
+ * Parse H264 fmtp parameters given as a map, as produced by SDP parsers or
+ * read from MP4 boxes. Keys are matched regardless of their casing, numeric
+ * values may be given as strings and the profile-level-id is normalized.
+ */
+func ParseRtpH264Parameter(fmtp map[string]interface{}) (params RtpH264Parameter) {
+	for key, value := range fmtp {
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "packetization-mode":
+			params.PacketizationMode = parseFmtpInt(value)
+		case "level-asymmetry-allowed":
+			params.LevelAsymmetryAllowed = parseFmtpInt(value)
+		case "profile-level-id":
+			params.ProfileLevelId = fmt.Sprint(value)
+		}
+	}
+
+	return params.Normalize()
+}
+
+/**
+ * Parse a H264 fmtp line such as "Profile-Level-Id=42E01F;packetization-mode=1".
+ */
+func ParseRtpH264Fmtp(fmtp string) RtpH264Parameter {
+	values := map[string]interface{}{}
+
+	for _, pair := range strings.Split(fmtp, ";") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			values[kv[0]] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return ParseRtpH264Parameter(values)
+}
+
+/**
+ * Returns a copy of the parameters with the profile-level-id trimmed and
+ * lower-cased, so equal profiles compare equal as strings.
+ */
+func (params RtpH264Parameter) Normalize() RtpH264Parameter {
+	params.ProfileLevelId = strings.ToLower(strings.TrimSpace(params.ProfileLevelId))
+
+	return params
+}
+
+func parseFmtpInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(strings.TrimSpace(v))
+		return i
+	default:
+		i, _ := strconv.Atoi(fmt.Sprint(v))
+		return i
+	}
+}
+
 /**
  * Generate codec parameters that will be used as answer in an SDP negotiation
  * based on local supported parameters and remote offered parameters. Both
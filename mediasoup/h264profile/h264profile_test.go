@@ -146,6 +146,39 @@ func TestGenerateProfileLevelIdForAnswerConstrainedBaselineLevelAsymmetry(t *tes
 	assert.Equal(t, answer, "42e01f")
 }
 
+func TestParseRtpH264Parameter(t *testing.T) {
+	params := ParseRtpH264Parameter(map[string]interface{}{
+		"Packetization-Mode":      "1",
+		"PROFILE-LEVEL-ID":        " 42E01F",
+		"level-asymmetry-allowed": float64(1),
+	})
+
+	assert.Equal(t, RtpH264Parameter{
+		PacketizationMode:     1,
+		ProfileLevelId:        "42e01f",
+		LevelAsymmetryAllowed: 1,
+	}, params)
+}
+
+func TestParseRtpH264Fmtp(t *testing.T) {
+	params := ParseRtpH264Fmtp("Profile-Level-Id=4D0032; packetization-mode=1")
+
+	assert.Equal(t, RtpH264Parameter{
+		PacketizationMode: 1,
+		ProfileLevelId:    "4d0032",
+	}, params)
+}
+
+func TestGenerateProfileLevelIdForAnswerMissingProfileLevelId(t *testing.T) {
+	answer, err := GenerateProfileLevelIdForAnswer(
+		RtpH264Parameter{},
+		RtpH264Parameter{ProfileLevelId: "42E01F"}.Normalize(),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "42e01f", answer)
+}
+
 func Test_byteMaskString(t *testing.T) {
 	type args struct {
 		c   byte
@@ -0,0 +1,236 @@
+package h265profile
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProfileMain                              = 1
+	ProfileMain10                            = 2
+	ProfileMainStill                         = 3
+	ProfileRangeExtensions                   = 4
+	ProfileHighThroughput                    = 5
+	ProfileMultiviewMain                     = 6
+	ProfileScalableMain                      = 7
+	Profile3dMain                            = 8
+	ProfileScreenContentCoding               = 9
+	ProfileScalableRangeExtensions           = 10
+	ProfileHighThroughputScreenContentCoding = 11
+
+	TierMain = 0
+	TierHigh = 1
+
+	// All values are equal to 30 times the level number.
+	Level1   = 30
+	Level2   = 60
+	Level2_1 = 63
+	Level3   = 90
+	Level3_1 = 93
+	Level4   = 120
+	Level4_1 = 123
+	Level5   = 150
+	Level5_1 = 153
+	Level5_2 = 156
+	Level6   = 180
+	Level6_1 = 183
+	Level6_2 = 186
+
+	// Transmission modes (RFC 7798 section 7.1).
+	TxModeSrst = "SRST"
+	TxModeMrst = "MRST"
+	TxModeMrmt = "MRMT"
+)
+
+type ProfileTierLevel struct {
+	Profile int
+	Tier    int
+	Level   int
+}
+
+func (ptl ProfileTierLevel) String() string {
+	return fmt.Sprintf("profile-id=%d;tier-flag=%d;level-id=%d", ptl.Profile, ptl.Tier, ptl.Level)
+}
+
+// Default values when the SDP parameters are absent (RFC 7798 section 7.1).
+var DefaultProfileTierLevel = ProfileTierLevel{
+	Profile: ProfileMain,
+	Tier:    TierMain,
+	Level:   Level3_1,
+}
+
+type RtpH265Parameter struct {
+	ProfileSpace int    `json:"profile-space,omitempty"`
+	ProfileId    int    `json:"profile-id,omitempty"`
+	TierFlag     int    `json:"tier-flag,omitempty"`
+	LevelId      int    `json:"level-id,omitempty"`
+	TxMode       string `json:"tx-mode,omitempty"`
+}
+
+/**
+ * Parse H265 fmtp parameters given as a map, as produced by SDP parsers.
+ * Keys are matched regardless of their casing and numeric values may be given
+ * as strings.
+ */
+func ParseRtpH265Parameter(fmtp map[string]interface{}) (params RtpH265Parameter) {
+	for key, value := range fmtp {
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "profile-space":
+			params.ProfileSpace = parseFmtpInt(value)
+		case "profile-id":
+			params.ProfileId = parseFmtpInt(value)
+		case "tier-flag":
+			params.TierFlag = parseFmtpInt(value)
+		case "level-id":
+			params.LevelId = parseFmtpInt(value)
+		case "tx-mode":
+			params.TxMode = fmt.Sprint(value)
+		}
+	}
+
+	return params.Normalize()
+}
+
+/**
+ * Parse a H265 fmtp line such as "profile-id=1;tier-flag=0;level-id=93".
+ */
+func ParseRtpH265Fmtp(fmtp string) RtpH265Parameter {
+	values := map[string]interface{}{}
+
+	for _, pair := range strings.Split(fmtp, ";") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			values[kv[0]] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return ParseRtpH265Parameter(values)
+}
+
+/**
+ * Returns a copy of the parameters with the tx-mode trimmed and upper-cased.
+ */
+func (params RtpH265Parameter) Normalize() RtpH265Parameter {
+	params.TxMode = strings.ToUpper(strings.TrimSpace(params.TxMode))
+
+	return params
+}
+
+// GetTxMode returns the tx-mode, "SRST" if absent.
+func (params RtpH265Parameter) GetTxMode() string {
+	if txMode := params.Normalize().TxMode; len(txMode) > 0 {
+		return txMode
+	}
+
+	return TxModeSrst
+}
+
+/**
+ * Parse the profile, tier and level of the SDP parameters, absent values
+ * take their default. Nothing is returned if a value is not recognized.
+ */
+func ParseSdpProfileTierLevel(params RtpH265Parameter) *ProfileTierLevel {
+	ptl := DefaultProfileTierLevel
+
+	if params.ProfileSpace != 0 {
+		return nil
+	}
+
+	if params.ProfileId != 0 {
+		if params.ProfileId < ProfileMain || params.ProfileId > ProfileHighThroughputScreenContentCoding {
+			return nil
+		}
+		ptl.Profile = params.ProfileId
+	}
+
+	switch params.TierFlag {
+	case TierMain, TierHigh:
+		ptl.Tier = params.TierFlag
+	default:
+		return nil
+	}
+
+	if params.LevelId != 0 {
+		switch params.LevelId {
+		case Level1, Level2, Level2_1, Level3, Level3_1, Level4, Level4_1,
+			Level5, Level5_1, Level5_2, Level6, Level6_1, Level6_2:
+			ptl.Level = params.LevelId
+		default:
+			return nil
+		}
+	}
+
+	// The high tier is only defined from level 4 on.
+	if ptl.Tier == TierHigh && ptl.Level < Level4 {
+		return nil
+	}
+
+	return &ptl
+}
+
+/**
+ * Returns true if the parameters have the same H265 profile and tier.
+ */
+func IsSameProfileAndTier(params1, params2 RtpH265Parameter) bool {
+	ptl1 := ParseSdpProfileTierLevel(params1)
+	ptl2 := ParseSdpProfileTierLevel(params2)
+
+	return ptl1 != nil && ptl2 != nil &&
+		ptl1.Profile == ptl2.Profile && ptl1.Tier == ptl2.Tier
+}
+
+/**
+ * Generate the level-id of the answer of a SDP negotiation based on the local
+ * supported parameters and the remote offered ones. Profiles and tiers must
+ * be equal, the level of the answer is the lowest one since it must not be
+ * higher than the offered one (RFC 7798 section 7.2.2).
+ *
+ * @returns The level-id of the answer, or 0 if no one of the params has it.
+ */
+func GenerateLevelIdForAnswer(localSupportedParams, remoteOfferedParams RtpH265Parameter) (levelId int, err error) {
+	localPtl := ParseSdpProfileTierLevel(localSupportedParams)
+	remotePtl := ParseSdpProfileTierLevel(remoteOfferedParams)
+
+	if localPtl == nil {
+		err = errors.New("invalid local profile-tier-level")
+		return
+	}
+	if remotePtl == nil {
+		err = errors.New("invalid remote profile-tier-level")
+		return
+	}
+	if localPtl.Profile != remotePtl.Profile {
+		err = errors.New("H265 Profile mismatch")
+		return
+	}
+	if localPtl.Tier != remotePtl.Tier {
+		err = errors.New("H265 Tier mismatch")
+		return
+	}
+
+	if localSupportedParams.LevelId == 0 && remoteOfferedParams.LevelId == 0 {
+		return 0, nil
+	}
+
+	if localPtl.Level < remotePtl.Level {
+		return localPtl.Level, nil
+	}
+
+	return remotePtl.Level, nil
+}
+
+func parseFmtpInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(strings.TrimSpace(v))
+		return i
+	default:
+		i, _ := strconv.Atoi(fmt.Sprint(v))
+		return i
+	}
+}
@@ -0,0 +1,81 @@
+package h265profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSdpProfileTierLevelDefault(t *testing.T) {
+	ptl := ParseSdpProfileTierLevel(RtpH265Parameter{})
+	assert.NotNil(t, ptl)
+	assert.Equal(t, DefaultProfileTierLevel, *ptl)
+}
+
+func TestParseSdpProfileTierLevel(t *testing.T) {
+	ptl := ParseSdpProfileTierLevel(RtpH265Parameter{ProfileId: 2, TierFlag: 1, LevelId: 120})
+	assert.NotNil(t, ptl)
+	assert.Equal(t, ProfileTierLevel{Profile: ProfileMain10, Tier: TierHigh, Level: Level4}, *ptl)
+	assert.Equal(t, "profile-id=2;tier-flag=1;level-id=120", ptl.String())
+}
+
+func TestParseSdpProfileTierLevelInvalid(t *testing.T) {
+	// Invalid profile.
+	assert.Nil(t, ParseSdpProfileTierLevel(RtpH265Parameter{ProfileId: 12}))
+	// Invalid tier.
+	assert.Nil(t, ParseSdpProfileTierLevel(RtpH265Parameter{TierFlag: 2}))
+	// Invalid level.
+	assert.Nil(t, ParseSdpProfileTierLevel(RtpH265Parameter{LevelId: 91}))
+	// High tier below level 4.
+	assert.Nil(t, ParseSdpProfileTierLevel(RtpH265Parameter{TierFlag: 1, LevelId: 93}))
+	// Reserved profile space.
+	assert.Nil(t, ParseSdpProfileTierLevel(RtpH265Parameter{ProfileSpace: 1}))
+}
+
+func TestIsSameProfileAndTier(t *testing.T) {
+	assert.True(t, IsSameProfileAndTier(RtpH265Parameter{}, RtpH265Parameter{ProfileId: 1, LevelId: 150}))
+	assert.False(t, IsSameProfileAndTier(RtpH265Parameter{}, RtpH265Parameter{ProfileId: 2}))
+	assert.False(t, IsSameProfileAndTier(
+		RtpH265Parameter{LevelId: 120}, RtpH265Parameter{TierFlag: 1, LevelId: 120}))
+}
+
+func TestParseRtpH265Fmtp(t *testing.T) {
+	assert.Equal(t,
+		RtpH265Parameter{ProfileId: 1, TierFlag: 0, LevelId: 93, TxMode: "SRST"},
+		ParseRtpH265Fmtp("level-id=93;profile-id=1;tier-flag=0;tx-mode=srst"))
+	assert.Equal(t,
+		RtpH265Parameter{ProfileId: 2},
+		ParseRtpH265Parameter(map[string]interface{}{"Profile-Id": float64(2)}))
+}
+
+func TestGetTxMode(t *testing.T) {
+	assert.Equal(t, TxModeSrst, RtpH265Parameter{}.GetTxMode())
+	assert.Equal(t, TxModeMrst, RtpH265Parameter{TxMode: " mrst"}.GetTxMode())
+}
+
+func TestGenerateLevelIdForAnswer(t *testing.T) {
+	levelId, err := GenerateLevelIdForAnswer(RtpH265Parameter{}, RtpH265Parameter{})
+	assert.NoError(t, err)
+	assert.Zero(t, levelId)
+
+	// The answer level cannot be higher than the offered one.
+	levelId, err = GenerateLevelIdForAnswer(
+		RtpH265Parameter{LevelId: Level5_1}, RtpH265Parameter{LevelId: Level4})
+	assert.NoError(t, err)
+	assert.Equal(t, Level4, levelId)
+
+	levelId, err = GenerateLevelIdForAnswer(
+		RtpH265Parameter{LevelId: Level3}, RtpH265Parameter{})
+	assert.NoError(t, err)
+	assert.Equal(t, Level3, levelId)
+
+	_, err = GenerateLevelIdForAnswer(RtpH265Parameter{}, RtpH265Parameter{ProfileId: 2})
+	assert.Error(t, err)
+
+	_, err = GenerateLevelIdForAnswer(
+		RtpH265Parameter{LevelId: Level4}, RtpH265Parameter{TierFlag: 1, LevelId: Level4})
+	assert.Error(t, err)
+
+	_, err = GenerateLevelIdForAnswer(RtpH265Parameter{LevelId: 1}, RtpH265Parameter{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package mediasoup
+
+import (
+	"crypto/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Entities given to an IdGenerator.
+const (
+	IdEntityRouter       = "router"
+	IdEntityTransport    = "transport"
+	IdEntityProducer     = "producer"
+	IdEntityConsumer     = "consumer"
+	IdEntityDataConsumer = "dataConsumer"
+	IdEntityRtpObserver  = "rtpObserver"
+)
+
+// IdGenerator returns a new unique id for an entity (IdEntityRouter,
+// IdEntityTransport...), so deployments can embed shard or time information
+// in ids for log correlation.
+type IdGenerator func(entity string) string
+
+func newId(idGenerator IdGenerator, entity string) string {
+	if idGenerator == nil {
+		idGenerator = UuidIdGenerator
+	}
+
+	return idGenerator(entity)
+}
+
+func (w *Worker) newId(entity string) string {
+	return newId(w.idGenerator, entity)
+}
+
+func (router *Router) newId(entity string) string {
+	return newId(router.data.IdGenerator, entity)
+}
+
+func (transport *baseTransport) newId(entity string) string {
+	return newId(transport.idGenerator, entity)
+}
+
+// UuidIdGenerator generates UUIDv4 ids, the default.
+func UuidIdGenerator(entity string) string {
+	return uuid.NewV4().String()
+}
+
+// Crockford's base32 alphabet used by ULIDs.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// UlidIdGenerator generates ULIDs: 26 characters sortable by creation time
+// (48 bits of milliseconds followed by 80 random bits).
+func UlidIdGenerator(entity string) string {
+	var data [16]byte
+
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+
+	for i := 0; i < 6; i++ {
+		data[i] = byte(ms >> (40 - 8*i))
+	}
+
+	if _, err := rand.Read(data[6:]); err != nil {
+		panic(err)
+	}
+
+	return encodeUlid(data)
+}
+
+func encodeUlid(data [16]byte) string {
+	// 128 bits in 26 characters of 5 bits, the first one just has 3 bits.
+	var (
+		id    [26]byte
+		carry uint32
+		bits  uint
+		pos   = len(id) - 1
+	)
+
+	for i := len(data) - 1; i >= 0; i-- {
+		carry |= uint32(data[i]) << bits
+		bits += 8
+
+		for bits >= 5 && pos >= 0 {
+			id[pos] = ulidAlphabet[carry&0x1f]
+			carry >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+
+	if pos >= 0 {
+		id[pos] = ulidAlphabet[carry&0x1f]
+	}
+
+	return string(id[:])
+}
+
+// Epoch of the snowflake ids, 2020-01-01T00:00:00Z in milliseconds.
+const snowflakeEpoch = 1577836800000
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNodeId    = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// NewSnowflakeIdGenerator returns a generator of decimal snowflake ids: 41
+// bits of milliseconds since 2020, 10 bits of node id and 12 bits of sequence.
+func NewSnowflakeIdGenerator(nodeId int64) (IdGenerator, error) {
+	if nodeId < 0 || nodeId > snowflakeMaxNodeId {
+		return nil, NewTypeError("invalid snowflake node id [nodeId:%d]", nodeId)
+	}
+
+	var (
+		locker   sync.Mutex
+		lastMs   int64
+		sequence int64
+	)
+
+	return func(entity string) string {
+		locker.Lock()
+		defer locker.Unlock()
+
+		ms := time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+
+		if ms < lastMs {
+			// Clock went backwards, keep the last timestamp.
+			ms = lastMs
+		}
+
+		if ms == lastMs {
+			sequence = (sequence + 1) & snowflakeMaxSequence
+
+			if sequence == 0 {
+				// Sequence exhausted, wait for the next millisecond.
+				for ms <= lastMs {
+					time.Sleep(100 * time.Microsecond)
+					ms = time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+				}
+			}
+		} else {
+			sequence = 0
+		}
+
+		lastMs = ms
+
+		id := ms<<(snowflakeNodeBits+snowflakeSequenceBits) |
+			nodeId<<snowflakeSequenceBits | sequence
+
+		return strconv.FormatInt(id, 10)
+	}, nil
+}
@@ -0,0 +1,55 @@
+package mediasoup
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUlidIdGenerator(t *testing.T) {
+	id := UlidIdGenerator(IdEntityProducer)
+	assert.Len(t, id, 26)
+	assert.True(t, regexp.MustCompile("^[0-7][0-9A-HJKMNP-TV-Z]{25}$").MatchString(id))
+	assert.NotEqual(t, id, UlidIdGenerator(IdEntityProducer))
+
+	var data [16]byte
+	assert.Equal(t, "00000000000000000000000000", encodeUlid(data))
+
+	for i := range data {
+		data[i] = 0xff
+	}
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeUlid(data))
+}
+
+func TestNewSnowflakeIdGenerator(t *testing.T) {
+	_, err := NewSnowflakeIdGenerator(1024)
+	assert.IsType(t, NewTypeError(""), err)
+
+	generator, err := NewSnowflakeIdGenerator(5)
+	assert.NoError(t, err)
+
+	ids := make([]int64, 0, 5000)
+	seen := map[int64]bool{}
+
+	for i := 0; i < 5000; i++ {
+		id, err := strconv.ParseInt(generator(IdEntityConsumer), 10, 64)
+		assert.NoError(t, err)
+		assert.False(t, seen[id])
+		assert.EqualValues(t, 5, id>>snowflakeSequenceBits&snowflakeMaxNodeId)
+
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	assert.True(t, sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }))
+}
+
+func TestNewId(t *testing.T) {
+	assert.Len(t, newId(nil, IdEntityRouter), 36)
+	assert.Equal(t, "router-1", newId(func(entity string) string {
+		return entity + "-1"
+	}, IdEntityRouter))
+}
@@ -0,0 +1,21 @@
+package mediasoup
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Numbers sent by the worker such as SSRCs (uint32) and timestamps (uint64)
+// are decoded into typed struct fields, which keep them exact. Untyped values
+// (H, interface{}) keep the encoding/json defaults, so numbers in them are
+// float64 as callers expect.
+
+// unmarshalUseNumber is json.Unmarshal keeping numbers as json.Number, for
+// values decoded and then encoded again internally, e.g. the internal of raw
+// requests, whose numbers must not go through float64.
+func unmarshalUseNumber(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
@@ -0,0 +1,49 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONNumberBoundaries(t *testing.T) {
+	params := RtpParameters{
+		Encodings: []RtpEncoding{
+			{Ssrc: math.MaxUint32, Rtx: &RtpEncoding{Ssrc: 1<<31 + 1}},
+		},
+	}
+	data, err := json.Marshal(params)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"ssrc":4294967295`)
+
+	var decoded RtpParameters
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, uint32(math.MaxUint32), decoded.Encodings[0].Ssrc)
+	assert.Equal(t, uint32(1<<31+1), decoded.Encodings[0].Rtx.Ssrc)
+
+	var stat RtpStreamStat
+	assert.NoError(t, json.Unmarshal([]byte(`{"timestamp":18446744073709551615,"ssrc":2147483648}`), &stat))
+	assert.Equal(t, uint64(math.MaxUint64), stat.Timestamp)
+	assert.Equal(t, uint32(1<<31), stat.Ssrc)
+
+	data, err = json.Marshal(stat)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"timestamp":18446744073709551615`)
+	assert.Contains(t, string(data), `"ssrc":2147483648`)
+
+	var trace TraceNotification
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"rtp","timestamp":9007199254740993}`), &trace))
+	assert.Equal(t, uint64(9007199254740993), trace.Timestamp)
+
+	// Untyped values keep the default decoding.
+	var h H
+	rsp := Response{data: []byte(`{"ssrc":4294967295,"score":0.5}`)}
+	assert.NoError(t, rsp.Unmarshal(&h))
+	assert.Equal(t, H{"ssrc": float64(4294967295), "score": 0.5}, h)
+
+	labels := AppDataLabels(H{"ssrc": uint32(math.MaxUint32), "ts": uint64(9007199254740993)})
+	assert.Equal(t, "4294967295", labels["ssrc"])
+	assert.Equal(t, "9007199254740993", labels["ts"])
+}
@@ -0,0 +1,193 @@
+package mediasoup
+
+// V2RtpCodec is a codec of mediasoup v2 RTP parameters or capabilities.
+type V2RtpCodec struct {
+	Kind                 string             `json:"kind,omitempty"`
+	Name                 string             `json:"name,omitempty"`
+	MimeType             string             `json:"mimeType,omitempty"`
+	ClockRate            int                `json:"clockRate,omitempty"`
+	NumChannels          int                `json:"numChannels,omitempty"`
+	PayloadType          int                `json:"payloadType,omitempty"`
+	PreferredPayloadType int                `json:"preferredPayloadType,omitempty"`
+	Parameters           *RtpCodecParameter `json:"parameters,omitempty"`
+	RtcpFeedback         []RtcpFeedback     `json:"rtcpFeedback,omitempty"`
+}
+
+// V2RtpHeaderExtension is a header extension of mediasoup v2 RTP parameters
+// or capabilities.
+type V2RtpHeaderExtension struct {
+	Kind             string `json:"kind,omitempty"`
+	Uri              string `json:"uri,omitempty"`
+	Id               int    `json:"id,omitempty"`
+	PreferredId      int    `json:"preferredId,omitempty"`
+	Encrypt          bool   `json:"encrypt,omitempty"`
+	PreferredEncrypt bool   `json:"preferredEncrypt,omitempty"`
+	Parameters       *H     `json:"parameters,omitempty"`
+}
+
+// V2RtpEncoding is an encoding of mediasoup v2 RTP parameters. Simulcast
+// streams were identified by profile ("low", "medium", "high").
+type V2RtpEncoding struct {
+	Ssrc       uint32 `json:"ssrc,omitempty"`
+	Rid        string `json:"rid,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	MaxBitrate uint32 `json:"maxBitrate,omitempty"`
+	Rtx        *struct {
+		Ssrc uint32 `json:"ssrc,omitempty"`
+	} `json:"rtx,omitempty"`
+}
+
+type V2RtpParameters struct {
+	MuxId            string                 `json:"muxId,omitempty"`
+	Codecs           []V2RtpCodec           `json:"codecs,omitempty"`
+	HeaderExtensions []V2RtpHeaderExtension `json:"headerExtensions,omitempty"`
+	Encodings        []V2RtpEncoding        `json:"encodings,omitempty"`
+	Rtcp             RtcpParameters         `json:"rtcp,omitempty"`
+}
+
+type V2RtpCapabilities struct {
+	Codecs           []V2RtpCodec           `json:"codecs,omitempty"`
+	HeaderExtensions []V2RtpHeaderExtension `json:"headerExtensions,omitempty"`
+	FecMechanisms    []string               `json:"fecMechanisms,omitempty"`
+}
+
+// V2HeaderExtensionUris maps header extension uris used by mediasoup v2 era
+// clients to the ones of this version. Other uris are kept.
+var V2HeaderExtensionUris = map[string]string{
+	"http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions": TransportWideCcUri,
+	"urn:ietf:params:rtp-hdrext:sdes:repair-rtp-stream-id":                   "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id",
+}
+
+/**
+ * Convert mediasoup v2 RTP parameters of the given kind into RTP parameters
+ * of this version: muxId becomes mid, numChannels becomes channels, codec
+ * names become mime types, legacy header extension uris are translated and
+ * simulcast profiles without SSRC become rids.
+ *
+ * @throws {TypeError} if wrong arguments.
+ */
+func ConvertV2RtpParameters(kind MediaKind, v2 V2RtpParameters) (params RtpParameters, err error) {
+	params.Mid = v2.MuxId
+	params.Rtcp = v2.Rtcp
+
+	for _, v2Codec := range v2.Codecs {
+		if len(v2Codec.Kind) == 0 {
+			v2Codec.Kind = string(kind)
+		}
+
+		var codec RtpCodecCapability
+
+		if codec, err = convertV2RtpCodec(v2Codec); err != nil {
+			return
+		}
+
+		codec.Kind = ""
+		params.Codecs = append(params.Codecs, codec)
+	}
+
+	for _, v2Ext := range v2.HeaderExtensions {
+		ext := RtpHeaderExtension{
+			Uri:        convertV2HeaderExtensionUri(v2Ext.Uri),
+			Id:         v2Ext.Id,
+			Parameters: v2Ext.Parameters,
+		}
+
+		if v2Ext.Encrypt {
+			ext.Encrypt = newBool(true)
+		}
+
+		params.HeaderExtensions = append(params.HeaderExtensions, ext)
+	}
+
+	for _, v2Encoding := range v2.Encodings {
+		encoding := RtpEncoding{
+			Ssrc:       v2Encoding.Ssrc,
+			Rid:        v2Encoding.Rid,
+			MaxBitrate: v2Encoding.MaxBitrate,
+		}
+
+		if encoding.Ssrc == 0 && len(encoding.Rid) == 0 {
+			encoding.Rid = v2Encoding.Profile
+		}
+		if v2Encoding.Rtx != nil && v2Encoding.Rtx.Ssrc > 0 {
+			encoding.Rtx = &RtpEncoding{Ssrc: v2Encoding.Rtx.Ssrc}
+		}
+
+		params.Encodings = append(params.Encodings, encoding)
+	}
+
+	return
+}
+
+/**
+ * Convert mediasoup v2 RTP capabilities into RTP capabilities of this
+ * version.
+ *
+ * @throws {TypeError} if wrong arguments.
+ */
+func ConvertV2RtpCapabilities(v2 V2RtpCapabilities) (caps RtpCapabilities, err error) {
+	for _, v2Codec := range v2.Codecs {
+		var codec RtpCodecCapability
+
+		if codec, err = convertV2RtpCodec(v2Codec); err != nil {
+			return
+		}
+
+		caps.Codecs = append(caps.Codecs, codec)
+	}
+
+	for _, v2Ext := range v2.HeaderExtensions {
+		caps.HeaderExtensions = append(caps.HeaderExtensions, RtpHeaderExtension{
+			Kind:             MediaKind(v2Ext.Kind),
+			Uri:              convertV2HeaderExtensionUri(v2Ext.Uri),
+			PreferredId:      v2Ext.PreferredId,
+			PreferredEncrypt: v2Ext.PreferredEncrypt,
+		})
+	}
+
+	caps.FecMechanisms = v2.FecMechanisms
+
+	return
+}
+
+func convertV2RtpCodec(v2Codec V2RtpCodec) (codec RtpCodecCapability, err error) {
+	mimeType := v2Codec.MimeType
+
+	if len(mimeType) == 0 {
+		if len(v2Codec.Kind) == 0 || len(v2Codec.Name) == 0 {
+			err = NewTypeError("missing codec mimeType or kind and name")
+			return
+		}
+
+		mimeType = v2Codec.Kind + "/" + v2Codec.Name
+	}
+
+	kind := MediaKind(v2Codec.Kind)
+
+	if len(kind) == 0 {
+		kind = MediaKind(ParseMimeType(mimeType).Kind())
+	}
+
+	codec = RtpCodecCapability{
+		Kind:                 kind,
+		MimeType:             mimeType,
+		ClockRate:            v2Codec.ClockRate,
+		Channels:             v2Codec.NumChannels,
+		PayloadType:          v2Codec.PayloadType,
+		PreferredPayloadType: v2Codec.PreferredPayloadType,
+		Parameters:           v2Codec.Parameters,
+		RtcpFeedback:         v2Codec.RtcpFeedback,
+	}
+
+	err = validateRtpCodecParameters(codec)
+
+	return
+}
+
+func convertV2HeaderExtensionUri(uri string) string {
+	if v3Uri, ok := V2HeaderExtensionUris[uri]; ok {
+		return v3Uri
+	}
+
+	return uri
+}
@@ -0,0 +1,85 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertV2RtpParameters(t *testing.T) {
+	const v2ParametersJSON = `
+{
+	"muxId": "1",
+	"codecs": [
+		{
+			"name": "opus",
+			"payloadType": 111,
+			"clockRate": 48000,
+			"numChannels": 2,
+			"parameters": { "useinbandfec": 1 }
+		}
+	],
+	"headerExtensions": [
+		{ "uri": "urn:ietf:params:rtp-hdrext:ssrc-audio-level", "id": 1 },
+		{ "uri": "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions", "id": 5, "encrypt": true }
+	],
+	"encodings": [ { "ssrc": 1111, "rtx": { "ssrc": 2222 } }, { "profile": "high" } ],
+	"rtcp": { "cname": "FOOBAR", "reducedSize": true }
+}
+`
+	var v2 V2RtpParameters
+
+	assert.NoError(t, json.Unmarshal([]byte(v2ParametersJSON), &v2))
+
+	params, err := ConvertV2RtpParameters("audio", v2)
+	assert.NoError(t, err)
+	assert.Equal(t, RtpParameters{
+		Mid: "1",
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "audio/opus",
+				PayloadType: 111,
+				ClockRate:   48000,
+				Channels:    2,
+				Parameters:  &RtpCodecParameter{Useinbandfec: 1},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", Id: 1},
+			{Uri: TransportWideCcUri, Id: 5, Encrypt: newBool(true)},
+		},
+		Encodings: []RtpEncoding{
+			{Ssrc: 1111, Rtx: &RtpEncoding{Ssrc: 2222}},
+			{Rid: "high"},
+		},
+		Rtcp: RtcpParameters{Cname: "FOOBAR", ReducedSize: true},
+	}, params)
+
+	_, err = ConvertV2RtpParameters("", V2RtpParameters{
+		Codecs: []V2RtpCodec{{Name: "opus", ClockRate: 48000}},
+	})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestConvertV2RtpCapabilities(t *testing.T) {
+	caps, err := ConvertV2RtpCapabilities(V2RtpCapabilities{
+		Codecs: []V2RtpCodec{
+			{Kind: "audio", Name: "opus", ClockRate: 48000, NumChannels: 2, PreferredPayloadType: 100},
+			{MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 101},
+		},
+		HeaderExtensions: []V2RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:3gpp:video-orientation", PreferredId: 4},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PreferredPayloadType: 100},
+			{Kind: "video", MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 101},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:3gpp:video-orientation", PreferredId: 4},
+		},
+	}, caps)
+}
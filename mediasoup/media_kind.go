@@ -0,0 +1,63 @@
+package mediasoup
+
+import "encoding/json"
+
+// MediaKind is the kind of a Producer, Consumer, codec or header extension.
+type MediaKind string
+
+const (
+	MediaKindAudio MediaKind = "audio"
+	MediaKindVideo MediaKind = "video"
+	MediaKindData  MediaKind = "data"
+)
+
+// ParseMediaKind returns the MediaKind named s.
+func ParseMediaKind(s string) (MediaKind, error) {
+	kind := MediaKind(s)
+
+	if err := kind.Validate(); err != nil {
+		return "", err
+	}
+
+	return kind, nil
+}
+
+// Validate returns a TypeError if the kind is not one of the MediaKind
+// constants.
+func (kind MediaKind) Validate() error {
+	switch kind {
+	case MediaKindAudio, MediaKindVideo, MediaKindData:
+		return nil
+	default:
+		return NewTypeError(`invalid kind "%s"`, string(kind))
+	}
+}
+
+// IsRtp returns whether media of this kind is carried over RTP, "audio" or
+// "video".
+func (kind MediaKind) IsRtp() bool {
+	return kind == MediaKindAudio || kind == MediaKindVideo
+}
+
+func (kind MediaKind) String() string {
+	return string(kind)
+}
+
+// UnmarshalJSON rejects unknown kinds. An empty kind is accepted since it is
+// optional in capabilities.
+func (kind *MediaKind) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if len(s) > 0 {
+		if err := MediaKind(s).Validate(); err != nil {
+			return err
+		}
+	}
+
+	*kind = MediaKind(s)
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMediaKind(t *testing.T) {
+	kind, err := ParseMediaKind("video")
+	assert.NoError(t, err)
+	assert.Equal(t, MediaKindVideo, kind)
+	assert.True(t, kind.IsRtp())
+	assert.False(t, MediaKindData.IsRtp())
+
+	_, err = ParseMediaKind("vidoe")
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestMediaKindJSON(t *testing.T) {
+	var codec RtpCodecCapability
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"kind":"audio","mimeType":"audio/opus"}`), &codec))
+	assert.Equal(t, MediaKindAudio, codec.Kind)
+
+	data, err := json.Marshal(codec)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"audio","mimeType":"audio/opus"}`, string(data))
+
+	var ext RtpHeaderExtension
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"kind":"","uri":"urn:ietf:params:rtp-hdrext:sdes:mid"}`), &ext))
+	assert.Empty(t, ext.Kind)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"kind":"audoi"}`), &codec))
+}
@@ -4,6 +4,8 @@ import (
 	"sync"
 )
 
+// CreateWorker spawns a mediasoup-worker process and waits for it to run.
+// Spawning is not supported on Windows, where it returns an UnsupportedError.
 func CreateWorker(workerBin string, options ...Option) (worker *Worker, err error) {
 	worker, err = newWorker(workerBin, options...)
 	if err != nil {
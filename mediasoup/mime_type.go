@@ -0,0 +1,60 @@
+package mediasoup
+
+import "strings"
+
+// MimeType is a normalized codec mime type such as "video/vp8". Leading and
+// trailing spaces are ignored and both kind and subtype are lower-cased, so
+// two MimeType values can be compared with ==.
+type MimeType struct {
+	kind    string
+	subtype string
+}
+
+// ParseMimeType parses a mime type string like "video/VP8".
+func ParseMimeType(mimeType string) MimeType {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+
+	parts := strings.SplitN(mimeType, "/", 2)
+
+	m := MimeType{kind: strings.TrimSpace(parts[0])}
+
+	if len(parts) == 2 {
+		m.subtype = strings.TrimSpace(parts[1])
+	}
+
+	return m
+}
+
+// Kind returns "audio", "video" or whatever precedes the slash.
+func (m MimeType) Kind() string {
+	return m.kind
+}
+
+// Subtype returns the codec name, e.g. "vp8".
+func (m MimeType) Subtype() string {
+	return m.subtype
+}
+
+// IsRtx returns whether it is a RTX (retransmission) codec.
+func (m MimeType) IsRtx() bool {
+	return m.subtype == "rtx"
+}
+
+// IsFec returns whether it is a FEC (forward error correction) codec.
+func (m MimeType) IsFec() bool {
+	switch m.subtype {
+	case "ulpfec", "flexfec", "flexfec-03":
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the canonical "kind/subtype" form.
+func (m MimeType) String() string {
+	if len(m.subtype) == 0 {
+		return m.kind
+	}
+
+	return m.kind + "/" + m.subtype
+}
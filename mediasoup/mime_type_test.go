@@ -0,0 +1,55 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMimeType(t *testing.T) {
+	mimeType := ParseMimeType(" Video/VP8 ")
+
+	assert.Equal(t, "video", mimeType.Kind())
+	assert.Equal(t, "vp8", mimeType.Subtype())
+	assert.Equal(t, "video/vp8", mimeType.String())
+	assert.Equal(t, ParseMimeType("video/vp8"), mimeType)
+	assert.False(t, mimeType.IsRtx())
+	assert.False(t, mimeType.IsFec())
+
+	assert.True(t, ParseMimeType("video/RTX").IsRtx())
+	assert.True(t, ParseMimeType("video/ulpfec").IsFec())
+	assert.Empty(t, ParseMimeType("audio").Subtype())
+}
+
+func TestMatchedCodecs_MimeTypeCase(t *testing.T) {
+	aCodec := RtpCodecCapability{MimeType: "audio/OPUS ", ClockRate: 48000, Channels: 2}
+	bCodec := RtpCodecCapability{MimeType: "audio/opus", ClockRate: 48000, Channels: 2}
+
+	assert.True(t, matchedCodecs(&aCodec, bCodec, codecMatchStrict))
+}
+
+func TestMatchedCodecs_H264ProfileLevelIdCase(t *testing.T) {
+	aCodec := RtpCodecCapability{
+		MimeType:  "video/H264",
+		ClockRate: 90000,
+		Parameters: &RtpCodecParameter{
+			RtpH264Parameter: h264profile.RtpH264Parameter{
+				PacketizationMode: 1,
+				ProfileLevelId:    "42E01F ",
+			},
+		},
+	}
+	bCodec := RtpCodecCapability{
+		MimeType:  "video/H264",
+		ClockRate: 90000,
+		Parameters: &RtpCodecParameter{
+			RtpH264Parameter: h264profile.RtpH264Parameter{
+				PacketizationMode: 1,
+			},
+		},
+	}
+
+	assert.True(t, matchedCodecs(&aCodec, bCodec, codecMatchStrictAndModify))
+	assert.Equal(t, "42e01f", aCodec.Parameters.ProfileLevelId)
+}
@@ -0,0 +1,218 @@
+package mediasoup
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+/**
+ * Mirror duplicates Producers of a Router into another Router (usually in a
+ * Worker running a new version) through PipeTransports, so the other Router
+ * receives real traffic while the Consumers stay on the source Router.
+ *
+ * @emits {producer *Producer} newproducer - pipe Producer created in the target Router
+ * @emits {producerId string, err error} mirrorerror
+ * @emits close
+ */
+type Mirror struct {
+	EventEmitter
+	logger    logrus.FieldLogger
+	locker    sync.Mutex
+	source    *Router
+	target    *Router
+	listenIp  ListenIp
+	producers map[string]*Producer
+	closed    bool
+}
+
+type MirrorParams struct {
+	// Target Router which receives the mirrored Producers.
+	Target *Router
+	// ListenIp of the PipeTransports, default "127.0.0.1".
+	ListenIp ListenIp
+}
+
+// CreateMirror creates a Mirror of this Router into params.Target. Producers
+// are mirrored with AddProducer() or MirrorAll().
+func (router *Router) CreateMirror(params MirrorParams) (mirror *Mirror, err error) {
+	router.logger.Debug("createMirror()")
+
+	if params.Target == nil {
+		err = NewTypeError("missing target Router")
+		return
+	}
+	if params.Target == router {
+		err = NewTypeError("cannot use this Router as target")
+		return
+	}
+
+	logger := TypeLogger("Mirror")
+
+	mirror = &Mirror{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		source:       router,
+		target:       params.Target,
+		listenIp:     params.ListenIp,
+		producers:    make(map[string]*Producer),
+	}
+
+	router.Observer().On("close", mirror.Close)
+	params.Target.Observer().On("close", mirror.Close)
+
+	return
+}
+
+// Source Router.
+func (mirror *Mirror) Source() *Router {
+	return mirror.source
+}
+
+// Target Router.
+func (mirror *Mirror) Target() *Router {
+	return mirror.target
+}
+
+// Whether the Mirror is closed.
+func (mirror *Mirror) Closed() bool {
+	mirror.locker.Lock()
+	defer mirror.locker.Unlock()
+
+	return mirror.closed
+}
+
+// Producers returns the pipe Producers in the target Router keyed by id, which
+// is the same as the id of the mirrored Producer.
+func (mirror *Mirror) Producers() map[string]*Producer {
+	mirror.locker.Lock()
+	defer mirror.locker.Unlock()
+
+	producers := make(map[string]*Producer, len(mirror.producers))
+
+	for id, producer := range mirror.producers {
+		producers[id] = producer
+	}
+
+	return producers
+}
+
+// AddProducer mirrors the given Producer of the source Router and returns the
+// pipe Producer created in the target Router.
+func (mirror *Mirror) AddProducer(producerId string) (pipeProducer *Producer, err error) {
+	mirror.logger.Debugf("addProducer() [producerId:%s]", producerId)
+
+	mirror.locker.Lock()
+
+	if mirror.closed {
+		mirror.locker.Unlock()
+		err = NewInvalidStateError("Mirror closed")
+		return
+	}
+	if pipeProducer = mirror.producers[producerId]; pipeProducer != nil {
+		mirror.locker.Unlock()
+		return
+	}
+
+	mirror.locker.Unlock()
+
+	_, pipeProducer, err = mirror.source.PipeToRouter(PipeToRouterParams{
+		ProducerId: producerId,
+		Router:     mirror.target,
+		ListenIp:   mirror.listenIp,
+	})
+	if err != nil {
+		return
+	}
+
+	mirror.locker.Lock()
+
+	if mirror.closed {
+		mirror.locker.Unlock()
+		pipeProducer.Close()
+		err = NewInvalidStateError("Mirror closed")
+		return
+	}
+
+	mirror.producers[producerId] = pipeProducer
+	mirror.locker.Unlock()
+
+	pipeProducer.Observer().On("close", func() {
+		mirror.locker.Lock()
+		defer mirror.locker.Unlock()
+
+		if mirror.producers[producerId] == pipeProducer {
+			delete(mirror.producers, producerId)
+		}
+	})
+
+	mirror.SafeEmit("newproducer", pipeProducer)
+
+	return
+}
+
+// RemoveProducer stops mirroring the given Producer.
+func (mirror *Mirror) RemoveProducer(producerId string) {
+	mirror.logger.Debugf("removeProducer() [producerId:%s]", producerId)
+
+	mirror.locker.Lock()
+	pipeProducer := mirror.producers[producerId]
+	delete(mirror.producers, producerId)
+	mirror.locker.Unlock()
+
+	if pipeProducer != nil {
+		// Closes the pipe Consumer in the source Router too.
+		pipeProducer.Close()
+	}
+}
+
+// MirrorAll mirrors every current and future Producer of the source Router.
+// Failures of future Producers are reported with the "mirrorerror" event.
+func (mirror *Mirror) MirrorAll() (err error) {
+	mirror.logger.Debug("mirrorAll()")
+
+	for producerId := range mirror.source.producers {
+		if _, err = mirror.AddProducer(producerId); err != nil {
+			return
+		}
+	}
+
+	mirror.source.Observer().On("newtransport", func(transport Transport) {
+		transport.Observer().On("newproducer", func(producer *Producer) {
+			if mirror.Closed() {
+				return
+			}
+			if _, err := mirror.AddProducer(producer.Id()); err != nil {
+				mirror.logger.Errorf("mirroring Producer failed [producerId:%s]: %s",
+					producer.Id(), err)
+
+				mirror.SafeEmit("mirrorerror", producer.Id(), err)
+			}
+		})
+	})
+
+	return
+}
+
+// Close stops mirroring every Producer.
+func (mirror *Mirror) Close() {
+	mirror.locker.Lock()
+
+	if mirror.closed {
+		mirror.locker.Unlock()
+		return
+	}
+
+	mirror.logger.Debug("close()")
+
+	mirror.closed = true
+	producers := mirror.producers
+	mirror.producers = make(map[string]*Producer)
+	mirror.locker.Unlock()
+
+	for _, pipeProducer := range producers {
+		pipeProducer.Close()
+	}
+
+	mirror.SafeEmit("close")
+}
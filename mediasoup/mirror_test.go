@@ -0,0 +1,71 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterCreateMirror_TypeError(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	_, err = router.CreateMirror(MirrorParams{})
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = router.CreateMirror(MirrorParams{Target: router})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestMirror_AddProducer(t *testing.T) {
+	ns := setupPipeTest(t)
+	defer ns.router1.Close()
+	defer ns.router2.Close()
+
+	mirror, err := ns.router1.CreateMirror(MirrorParams{Target: ns.router2})
+	assert.NoError(t, err)
+
+	pipeProducer, err := mirror.AddProducer(ns.audioProducer.Id())
+	assert.NoError(t, err)
+	assert.Equal(t, ns.audioProducer.Id(), pipeProducer.Id())
+	assert.Equal(t, MediaKindAudio, pipeProducer.Kind())
+
+	again, err := mirror.AddProducer(ns.audioProducer.Id())
+	assert.NoError(t, err)
+	assert.Equal(t, pipeProducer, again)
+	assert.Len(t, mirror.Producers(), 1)
+
+	mirror.RemoveProducer(ns.audioProducer.Id())
+	assert.True(t, pipeProducer.Closed())
+	assert.Empty(t, mirror.Producers())
+
+	// The source Producer is untouched.
+	assert.False(t, ns.audioProducer.Closed())
+}
+
+func TestMirror_MirrorAll(t *testing.T) {
+	ns := setupPipeTest(t)
+	defer ns.router1.Close()
+	defer ns.router2.Close()
+
+	mirror, err := ns.router1.CreateMirror(MirrorParams{Target: ns.router2})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mirror.MirrorAll())
+	assert.Len(t, mirror.Producers(), 2)
+
+	transport, err := ns.router1.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(audioProducerParameters)
+	assert.NoError(t, err)
+	assert.NotNil(t, mirror.Producers()[producer.Id()])
+
+	mirror.Close()
+	assert.True(t, mirror.Closed())
+	assert.Empty(t, mirror.Producers())
+	assert.False(t, producer.Closed())
+}
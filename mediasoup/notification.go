@@ -0,0 +1,170 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Notification events sent by the worker process, by target entity.
+const (
+	// Worker.
+	WorkerNotificationRunning = "running"
+
+	// Producer.
+	ProducerNotificationScore                  = "score"
+	ProducerNotificationVideoOrientationChange = "videoorientationchange"
+	ProducerNotificationTrace                  = "trace"
+
+	// Consumer.
+	ConsumerNotificationProducerClose  = "producerclose"
+	ConsumerNotificationProducerPause  = "producerpause"
+	ConsumerNotificationProducerResume = "producerresume"
+	ConsumerNotificationScore          = "score"
+	ConsumerNotificationLayersChange   = "layerschange"
+	ConsumerNotificationTrace          = "trace"
+	// Consumer of a DirectTransport, on the PayloadChannel.
+	ConsumerNotificationRtp = "rtp"
+
+	// Transport.
+	TransportNotificationIceStateChange         = "icestatechange"
+	TransportNotificationIceSelectedTupleChange = "iceselectedtuplechange"
+	TransportNotificationDtlsStateChange        = "dtlsstatechange"
+	TransportNotificationTuple                  = "tuple"
+	TransportNotificationRtcpTuple              = "rtcptuple"
+	TransportNotificationSctpStateChange        = "sctpstatechange"
+	TransportNotificationTrace                  = "trace"
+	// DirectTransport, on the PayloadChannel.
+	TransportNotificationRtcp = "rtcp"
+
+	// DataConsumer.
+	DataConsumerNotificationSctpSendBufferFull = "sctpsendbufferfull"
+	DataConsumerNotificationBufferedAmountLow  = "bufferedamountlow"
+
+	// RtpObserver.
+	RtpObserverNotificationVolumes         = "volumes"
+	RtpObserverNotificationSilence         = "silence"
+	RtpObserverNotificationDominantSpeaker = "dominantspeaker"
+)
+
+// IceStateChangeNotification is the payload of "icestatechange".
+type IceStateChangeNotification struct {
+	IceState string `json:"iceState"`
+}
+
+// IceSelectedTupleChangeNotification is the payload of
+// "iceselectedtuplechange".
+type IceSelectedTupleChangeNotification struct {
+	IceSelectedTuple TransportTuple `json:"iceSelectedTuple"`
+}
+
+// DtlsStateChangeNotification is the payload of "dtlsstatechange".
+type DtlsStateChangeNotification struct {
+	DtlsState string `json:"dtlsState"`
+	// DtlsRemoteCert is set if the state is "connected".
+	DtlsRemoteCert string `json:"dtlsRemoteCert,omitempty"`
+}
+
+// TupleNotification is the payload of "tuple".
+type TupleNotification struct {
+	Tuple TransportTuple `json:"tuple"`
+}
+
+// RtcpTupleNotification is the payload of "rtcptuple".
+type RtcpTupleNotification struct {
+	RtcpTuple TransportTuple `json:"rtcpTuple"`
+}
+
+// SctpStateChangeNotification is the payload of "sctpstatechange".
+type SctpStateChangeNotification struct {
+	SctpState string `json:"sctpState"`
+}
+
+// BufferedAmountLowNotification is the payload of "bufferedamountlow".
+type BufferedAmountLowNotification struct {
+	BufferedAmount uint32 `json:"bufferedAmount"`
+}
+
+// VolumeNotification is an entry of the payload of "volumes".
+type VolumeNotification struct {
+	ProducerId string `json:"producerId"`
+	// Volume in dBvo, from -127 to 0.
+	Volume int8 `json:"volume"`
+}
+
+// DominantSpeakerNotification is the payload of "dominantspeaker".
+type DominantSpeakerNotification struct {
+	ProducerId string `json:"producerId"`
+}
+
+// TraceNotification is the payload of "trace", sent for the trace event
+// types enabled on a Producer, Consumer or Transport.
+type TraceNotification struct {
+	// Type such as "rtp", "keyframe", "nack", "pli", "fir", "probation" or
+	// "bwe".
+	Type      string `json:"type"`
+	Timestamp uint64 `json:"timestamp"`
+	// Direction is "in" or "out".
+	Direction string `json:"direction"`
+	// Info depends on Type.
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+/**
+ * Decode the payload of a worker notification of an entity (IdEntityProducer,
+ * IdEntityConsumer, IdEntityDataConsumer, IdEntityTransport or
+ * IdEntityRtpObserver), e.g. received
+ * by a custom channel listener.
+ *
+ * @returns The typed payload ([]ProducerScore, ConsumerScore, VideoLayer,
+ * TupleNotification...), nil for events without payload.
+ * @throws {TypeError} if the event is unknown.
+ */
+func DecodeNotification(entity, event string, data []byte) (payload interface{}, err error) {
+	switch entity + "." + event {
+	case IdEntityProducer + "." + ProducerNotificationScore:
+		payload = &[]ProducerScore{}
+	case IdEntityProducer + "." + ProducerNotificationVideoOrientationChange:
+		payload = &VideoOrientation{}
+	case IdEntityConsumer + "." + ConsumerNotificationScore:
+		payload = &ConsumerScore{}
+	case IdEntityConsumer + "." + ConsumerNotificationLayersChange:
+		payload = &VideoLayer{}
+	case IdEntityConsumer + "." + ConsumerNotificationProducerClose,
+		IdEntityConsumer + "." + ConsumerNotificationProducerPause,
+		IdEntityConsumer + "." + ConsumerNotificationProducerResume,
+		IdEntityDataConsumer + "." + DataConsumerNotificationSctpSendBufferFull,
+		IdEntityRtpObserver + "." + RtpObserverNotificationSilence:
+		return nil, nil
+	case IdEntityTransport + "." + TransportNotificationIceStateChange:
+		payload = &IceStateChangeNotification{}
+	case IdEntityTransport + "." + TransportNotificationIceSelectedTupleChange:
+		payload = &IceSelectedTupleChangeNotification{}
+	case IdEntityTransport + "." + TransportNotificationDtlsStateChange:
+		payload = &DtlsStateChangeNotification{}
+	case IdEntityTransport + "." + TransportNotificationTuple:
+		payload = &TupleNotification{}
+	case IdEntityTransport + "." + TransportNotificationRtcpTuple:
+		payload = &RtcpTupleNotification{}
+	case IdEntityTransport + "." + TransportNotificationSctpStateChange:
+		payload = &SctpStateChangeNotification{}
+	case IdEntityDataConsumer + "." + DataConsumerNotificationBufferedAmountLow:
+		payload = &BufferedAmountLowNotification{}
+	case IdEntityRtpObserver + "." + RtpObserverNotificationVolumes:
+		payload = &[]VolumeNotification{}
+	case IdEntityRtpObserver + "." + RtpObserverNotificationDominantSpeaker:
+		payload = &DominantSpeakerNotification{}
+	case IdEntityProducer + "." + ProducerNotificationTrace,
+		IdEntityConsumer + "." + ConsumerNotificationTrace,
+		IdEntityTransport + "." + TransportNotificationTrace:
+		payload = &TraceNotification{}
+	default:
+		return nil, NewTypeError(`unknown notification [entity:%s, event:%s]`, entity, event)
+	}
+
+	if err = json.Unmarshal(data, payload); err != nil {
+		return nil, NewTypeError("invalid %s notification: %s", event, err)
+	}
+
+	// Return the value instead of the pointer.
+	return reflect.ValueOf(payload).Elem().Interface(), nil
+}
@@ -0,0 +1,45 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeNotification(t *testing.T) {
+	payload, err := DecodeNotification(IdEntityProducer, ProducerNotificationScore,
+		[]byte(`[{"score":10,"ssrc":1111}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ProducerScore{{Score: 10, Ssrc: 1111}}, payload)
+
+	payload, err = DecodeNotification(IdEntityConsumer, ConsumerNotificationScore,
+		[]byte(`{"producer":10,"consumer":9}`))
+	assert.NoError(t, err)
+	assert.Equal(t, ConsumerScore{Producer: 10, Consumer: 9}, payload)
+
+	payload, err = DecodeNotification(IdEntityTransport, TransportNotificationDtlsStateChange,
+		[]byte(`{"dtlsState":"connected","dtlsRemoteCert":"CERT"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, DtlsStateChangeNotification{DtlsState: "connected", DtlsRemoteCert: "CERT"}, payload)
+
+	payload, err = DecodeNotification(IdEntityRtpObserver, RtpObserverNotificationVolumes,
+		[]byte(`[{"producerId":"p1","volume":-50}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []VolumeNotification{{ProducerId: "p1", Volume: -50}}, payload)
+
+	payload, err = DecodeNotification(IdEntityTransport, TransportNotificationTrace,
+		[]byte(`{"type":"bwe","timestamp":1,"direction":"out","info":{"availableBitrate":1000}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "bwe", payload.(TraceNotification).Type)
+	assert.JSONEq(t, `{"availableBitrate":1000}`, string(payload.(TraceNotification).Info))
+
+	payload, err = DecodeNotification(IdEntityConsumer, ConsumerNotificationProducerPause, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+
+	_, err = DecodeNotification(IdEntityProducer, "foo", nil)
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = DecodeNotification(IdEntityConsumer, ConsumerNotificationLayersChange, []byte(`[]`))
+	assert.IsType(t, NewTypeError(""), err)
+}
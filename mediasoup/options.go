@@ -3,6 +3,8 @@ package mediasoup
 import (
 	"fmt"
 	"os"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
 )
 
 // Options to start worker
@@ -14,6 +16,21 @@ type Options struct {
 	RTCMaxPort          uint16   `json:"rtcMaxPort,omitempty"`
 	DTLSCertificateFile string   `json:"dtlsCertificateFile,omitempty"`
 	DTLSPrivateKeyFile  string   `json:"dtlsPrivateKeyFile,omitempty"`
+	// AppData is custom app data of the Worker, it is not sent to the worker
+	// process but included in Dump().
+	AppData interface{} `json:"-"`
+	// FeatureFlags of the Worker and its Routers, default
+	// DefaultFeatureFlags().
+	FeatureFlags *FeatureFlags `json:"-"`
+	// IdGenerator of the ids of the Routers, Transports, Producers, Consumers
+	// and RtpObservers of the Worker, default UuidIdGenerator.
+	IdGenerator IdGenerator `json:"-"`
+	// RequestTimeouts of the channel requests to the worker process, default
+	// DefaultRequestTimeouts().
+	RequestTimeouts *RequestTimeouts `json:"-"`
+	// WorkerBinCandidates are used if no worker binary is given, the first
+	// one compatible with the host is spawned, see SelectWorkerBinary().
+	WorkerBinCandidates []string `json:"-"`
 }
 
 func NewOptions() *Options {
@@ -93,3 +110,215 @@ func WithDTLSCert(dtlsCertificateFile, dtlsPrivateKeyFile string) Option {
 		o.DTLSPrivateKeyFile = dtlsPrivateKeyFile
 	}
 }
+
+// WithAppData attaches custom app data (e.g. a region or a host name) to the
+// Worker.
+func WithAppData(appData interface{}) Option {
+	return func(o *Options) {
+		o.AppData = appData
+	}
+}
+
+// WithFeatureFlags overrides the package feature flags for the Worker.
+func WithFeatureFlags(flags FeatureFlags) Option {
+	return func(o *Options) {
+		o.FeatureFlags = &flags
+	}
+}
+
+// WithIdGenerator sets the generator of the ids of the entities of the
+// Worker, e.g. UlidIdGenerator or NewSnowflakeIdGenerator().
+func WithIdGenerator(idGenerator IdGenerator) Option {
+	return func(o *Options) {
+		o.IdGenerator = idGenerator
+	}
+}
+
+// WithRequestTimeouts sets the timeouts of the channel requests by class, so
+// expensive requests of huge Routers do not time out while cheap ones still
+// fail fast.
+func WithRequestTimeouts(timeouts RequestTimeouts) Option {
+	return func(o *Options) {
+		o.RequestTimeouts = &timeouts
+	}
+}
+
+// RouterOptions to create router
+type RouterOptions struct {
+	// MappedSsrcRange restricts the SSRCs assigned to consumable streams of
+	// Producers in the Router. If not set, random SSRCs are used.
+	MappedSsrcRange *MappedSsrcRange
+	// SsrcAllocator assigns the SSRCs of consumable streams of Producers in
+	// the Router, it cannot be combined with MappedSsrcRange.
+	SsrcAllocator SsrcAllocator
+	// ExcludeHeaderExtensions lists header extension uris received from
+	// Producers but not sent to Consumers, in addition to mid, rid and rrid.
+	ExcludeHeaderExtensions []string
+	// IncludeHeaderExtensions lists header extension uris sent to Consumers
+	// although excluded by default, e.g. mid to keep it end-to-end for
+	// diagnostic tooling. Exclusions take precedence.
+	IncludeHeaderExtensions []string
+	// CodecOrder sorts the media codecs of the Router RTP capabilities. If not
+	// set, the order of the given media codecs is kept.
+	CodecOrder CodecOrderFunc
+	// AppData is custom app data of the Router, included in Dump().
+	AppData interface{}
+	// HeaderExtensionMode of Producer RTP parameters, default
+	// HeaderExtensionStrict.
+	HeaderExtensionMode HeaderExtensionMode
+	// ConsumeTokenValidator, if set, authorizes every Transport.Consume()
+	// call of the Router with the given token.
+	ConsumeTokenValidator ConsumeTokenValidator
+	// ProtectedConsumePolicy authorizes consuming protected Producers of the
+	// Router. If not set, protected Producers cannot be consumed.
+	ProtectedConsumePolicy ProtectedConsumePolicy
+	// TraceIds of the Router, inherited by its Transports.
+	TraceIds TraceIds
+	// SupportedRtpCapabilities replaces GetSupportedRtpCapabilities() as the
+	// codecs and header extensions the Router media codecs are picked from.
+	SupportedRtpCapabilities *RtpCapabilities
+	// AdditionalCodecs are supported in addition to the package ones, e.g.
+	// audio/L16 for a worker built with it.
+	AdditionalCodecs []RtpCodecCapability
+	// CustomHeaderExtensions are offered in addition to the package ones.
+	CustomHeaderExtensions []RtpHeaderExtension
+	// ExtmapAllowMixed accepts two-byte header extensions (ids above 14) in
+	// the Router, from Producers and in custom header extensions.
+	ExtmapAllowMixed bool
+	// Clock of the CloseGracefully() delay, default clock.System.
+	Clock clock.Clock
+}
+
+type RouterOption func(o *RouterOptions)
+
+// WithMappedSsrcRange assigns mapped SSRCs sequentially from [min, max], so
+// routers given disjoint ranges (e.g. router index * 1000000) never collide.
+func WithMappedSsrcRange(min, max uint32) RouterOption {
+	return func(o *RouterOptions) {
+		o.MappedSsrcRange = &MappedSsrcRange{Min: min, Max: max}
+	}
+}
+
+// WithSsrcAllocator assigns mapped SSRCs with the given allocator, e.g.
+// NewSequentialSsrcAllocator(1000) for reproducible SSRCs in tests.
+func WithSsrcAllocator(allocator SsrcAllocator) RouterOption {
+	return func(o *RouterOptions) {
+		o.SsrcAllocator = allocator
+	}
+}
+
+// WithExcludedHeaderExtensions stops sending the given header extensions to
+// Consumers of the Router.
+func WithExcludedHeaderExtensions(uris ...string) RouterOption {
+	return func(o *RouterOptions) {
+		o.ExcludeHeaderExtensions = append(o.ExcludeHeaderExtensions, uris...)
+	}
+}
+
+// WithIncludedHeaderExtensions sends the given header extensions, such as
+// "urn:ietf:params:rtp-hdrext:sdes:mid", to Consumers of the Router although
+// they are excluded by default.
+func WithIncludedHeaderExtensions(uris ...string) RouterOption {
+	return func(o *RouterOptions) {
+		o.IncludeHeaderExtensions = append(o.IncludeHeaderExtensions, uris...)
+	}
+}
+
+// WithCodecOrder sorts the Router RTP capabilities codecs, since clients
+// usually pick the first codec they support.
+func WithCodecOrder(codecOrder CodecOrderFunc) RouterOption {
+	return func(o *RouterOptions) {
+		o.CodecOrder = codecOrder
+	}
+}
+
+// WithRouterAppData attaches custom app data (e.g. a room id or a tenant id) to
+// the Router.
+func WithRouterAppData(appData interface{}) RouterOption {
+	return func(o *RouterOptions) {
+		o.AppData = appData
+	}
+}
+
+// WithHeaderExtensionMode sets how unsupported Producer RTP header extensions
+// are handled in the Router.
+func WithHeaderExtensionMode(mode HeaderExtensionMode) RouterOption {
+	return func(o *RouterOptions) {
+		o.HeaderExtensionMode = mode
+	}
+}
+
+// WithConsumeTokenValidator requires a valid token to consume Producers in the
+// Router, e.g. NewConsumeTokenValidator(secret), so viewing is authorized even
+// if the signaling layer is compromised. Pipe Consumers are not checked.
+func WithConsumeTokenValidator(validator ConsumeTokenValidator) RouterOption {
+	return func(o *RouterOptions) {
+		o.ConsumeTokenValidator = validator
+	}
+}
+
+// WithProtectedConsumePolicy authorizes the Consumers of protected Producers,
+// including pipe Consumers, so sensitive streams are not consumed by
+// unauthorized parties such as server-side recorders.
+func WithProtectedConsumePolicy(policy ProtectedConsumePolicy) RouterOption {
+	return func(o *RouterOptions) {
+		o.ProtectedConsumePolicy = policy
+	}
+}
+
+// WithTraceIds attaches correlation ids (e.g. the room id) to the worker
+// requests of the Router, so worker failures can be tied to the application
+// session.
+func WithTraceIds(ids TraceIds) RouterOption {
+	return func(o *RouterOptions) {
+		o.TraceIds = ids
+	}
+}
+
+// WithRouterClock sets the clock timing the CloseGracefully() delay of the
+// Router, e.g. a fake clock in tests.
+func WithRouterClock(c clock.Clock) RouterOption {
+	return func(o *RouterOptions) {
+		o.Clock = c
+	}
+}
+
+// WithWorkerBinCandidates sets the worker binaries to select from when no
+// worker binary is given, e.g. bundled glibc and musl builds per architecture.
+func WithWorkerBinCandidates(paths ...string) Option {
+	return func(o *Options) {
+		o.WorkerBinCandidates = paths
+	}
+}
+
+// WithSupportedRtpCapabilities replaces the RTP capabilities supported by the
+// package for the Router.
+func WithSupportedRtpCapabilities(caps RtpCapabilities) RouterOption {
+	return func(o *RouterOptions) {
+		o.SupportedRtpCapabilities = &caps
+	}
+}
+
+// WithAdditionalCodecs supports codecs unknown to the package in the Router,
+// they still have to be listed in its media codecs to be used.
+func WithAdditionalCodecs(codecs ...RtpCodecCapability) RouterOption {
+	return func(o *RouterOptions) {
+		o.AdditionalCodecs = append(o.AdditionalCodecs, codecs...)
+	}
+}
+
+// WithCustomHeaderExtensions offers header extensions unknown to the package
+// in the Router RTP capabilities.
+func WithCustomHeaderExtensions(exts ...RtpHeaderExtension) RouterOption {
+	return func(o *RouterOptions) {
+		o.CustomHeaderExtensions = append(o.CustomHeaderExtensions, exts...)
+	}
+}
+
+// WithExtmapAllowMixed accepts two-byte header extensions (ids above 14) in
+// the Router, see RtpCapabilities.ExtmapAllowMixed.
+func WithExtmapAllowMixed() RouterOption {
+	return func(o *RouterOptions) {
+		o.ExtmapAllowMixed = true
+	}
+}
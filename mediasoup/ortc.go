@@ -1,13 +1,14 @@
 package mediasoup
 
 import (
-	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/imdario/mergo"
 	"github.com/jinzhu/copier"
 	h264 "github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	h265 "github.com/jiyeyuran/mediasoup-go/mediasoup/h265profile"
 )
 
 var DYNAMIC_PAYLOAD_TYPES = [...]int{
@@ -25,29 +26,118 @@ const (
 	codecMatchStrict          = 0x01
 	codecMatchModify          = 0x10
 	codecMatchStrictAndModify = codecMatchStrict | codecMatchModify
+	// Intersect the Opus parameters of a with the ones of b, see
+	// intersectOpusParameters().
+	codecMatchIntersect = 0x20
 )
 
+// HeaderExtensionMode decides what to do with Producer RTP header extensions
+// not supported by the Router.
+type HeaderExtensionMode int
+
+const (
+	// HeaderExtensionStrict fails with UnsupportedError (default).
+	HeaderExtensionStrict HeaderExtensionMode = iota
+	// HeaderExtensionLenient silently drops them, since many clients send
+	// proprietary extensions.
+	HeaderExtensionLenient
+)
+
+// CodecOrderFunc reports whether codec a must be placed before codec b.
+type CodecOrderFunc func(a, b RtpCodecCapability) bool
+
+// PreferCodecs returns a CodecOrderFunc placing codecs in the order of the
+// given mime types (e.g. "video/VP9", "video/H264"). Codecs not listed keep
+// their relative order after the listed ones.
+func PreferCodecs(mimeTypes ...string) CodecOrderFunc {
+	ranks := map[MimeType]int{}
+
+	for i, mimeType := range mimeTypes {
+		ranks[ParseMimeType(mimeType)] = i
+	}
+
+	rank := func(codec RtpCodecCapability) int {
+		if r, ok := ranks[ParseMimeType(codec.MimeType)]; ok {
+			return r
+		}
+		return len(mimeTypes)
+	}
+
+	return func(a, b RtpCodecCapability) bool {
+		return rank(a) < rank(b)
+	}
+}
+
 /**
  * Generate RTP capabilities for the Router based on the given media codecs and
- * mediasoup supported RTP capabilities.
+ * mediasoup supported RTP capabilities, which the options can extend or
+ * replace (WithAdditionalCodecs, WithCustomHeaderExtensions,
+ * WithSupportedRtpCapabilities).
  *
  */
-func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCapabilities, err error) {
+func GenerateRouterRtpCapabilities(
+	mediaCodecs []RtpCodecCapability,
+	options ...RouterOption,
+) (caps RtpCapabilities, err error) {
 	if len(mediaCodecs) == 0 {
 		err = NewTypeError("mediaCodecs cannot be empty")
 		return
 	}
 
-	supportedRtpCapabilities := GetSupportedRtpCapabilities()
+	opts := &RouterOptions{}
+
+	for _, option := range options {
+		option(opts)
+	}
+
+	mediaCodecs = cloneRtpCodecCapabilities(mediaCodecs)
+
+	if opts.CodecOrder != nil {
+		sort.SliceStable(mediaCodecs, func(i, j int) bool {
+			return opts.CodecOrder(mediaCodecs[i], mediaCodecs[j])
+		})
+	}
+
+	supportedRtpCapabilities, err := routerSupportedRtpCapabilities(opts)
+	if err != nil {
+		return
+	}
 	supportedCodecs := supportedRtpCapabilities.Codecs
 
-	caps.HeaderExtensions = supportedRtpCapabilities.HeaderExtensions
+	// Deep copy, the directions below must not leak into the supported
+	// capabilities.
+	caps.HeaderExtensions = cloneRtpHeaderExtensions(supportedRtpCapabilities.HeaderExtensions)
+
+	for i, ext := range caps.HeaderExtensions {
+		uri := CanonicalHeaderExtensionUri(ext.Uri)
+
+		if containsHeaderExtensionUri(opts.IncludeHeaderExtensions, uri) {
+			caps.HeaderExtensions[i].Direction = HeaderExtensionDirectionSendRecv
+		}
+		if containsHeaderExtensionUri(opts.ExcludeHeaderExtensions, uri) {
+			caps.HeaderExtensions[i].Direction = HeaderExtensionDirectionRecvOnly
+		}
+	}
 	caps.FecMechanisms = supportedRtpCapabilities.FecMechanisms
+	caps.ExtmapAllowMixed = opts.ExtmapAllowMixed
 
-	dynamicPayloadTypeIdx := 0
+	for _, ext := range caps.HeaderExtensions {
+		if IsTwoByteHeaderExtensionId(ext.PreferredId) && !caps.ExtmapAllowMixed {
+			err = NewTypeError(
+				"header extension needs two-byte header extensions, see WithExtmapAllowMixed() [uri:%s, preferredId:%d]",
+				ext.Uri, ext.PreferredId)
+			return
+		}
+	}
 
+	var codecs []RtpCodecCapability
+
+	registry := newPayloadTypeRegistry(DYNAMIC_PAYLOAD_TYPES[:])
+
+	// Reserve the given and static payload types first, so dynamic ones never
+	// collide with them.
 	for _, mediaCodec := range mediaCodecs {
-		if err = checkCodecCapability(&mediaCodec); err != nil {
+		if err = validateRtpCodecCapability(&mediaCodec); err != nil {
 			return
 		}
 
@@ -56,10 +146,20 @@ func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCa
 
 		if !matched {
 			err = NewUnsupportedError(
-				fmt.Sprintf(`media codec not supported [mimeType:%s]`, mediaCodec.MimeType))
+				`media codec not supported [mimeType:%s]`, mediaCodec.MimeType)
 			return
 		}
 
+		// Keep the given payload type.
+		if mediaCodec.PreferredPayloadType > 0 {
+			codec.PreferredPayloadType = mediaCodec.PreferredPayloadType
+		}
+		if codec.PreferredPayloadType > 0 {
+			if err = registry.reserve(codec.PreferredPayloadType, codec.MimeType); err != nil {
+				return
+			}
+		}
+
 		// Normalize channels.
 		if codec.Kind != "audio" {
 			codec.Channels = 0
@@ -80,16 +180,15 @@ func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCa
 			codec.RtcpFeedback = []RtcpFeedback{}
 		}
 
+		codecs = append(codecs, codec)
+	}
+
+	for _, codec := range codecs {
 		// Assign a payload type.
 		if codec.PreferredPayloadType == 0 {
-			if dynamicPayloadTypeIdx >= len(DYNAMIC_PAYLOAD_TYPES) {
-				err = errors.New("cannot allocate more dynamic codec payload types")
+			if codec.PreferredPayloadType, err = registry.allocate(codec.MimeType); err != nil {
 				return
 			}
-
-			codec.PreferredPayloadType = DYNAMIC_PAYLOAD_TYPES[dynamicPayloadTypeIdx]
-
-			dynamicPayloadTypeIdx++
 		}
 
 		// Append to the codec list.
@@ -97,15 +196,17 @@ func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCa
 
 		// Add a RTX video codec if video.
 		if codec.Kind == "video" {
-			if dynamicPayloadTypeIdx >= len(DYNAMIC_PAYLOAD_TYPES) {
-				err = errors.New("cannot allocate more dynamic codec payload types")
+			var pt int
+
+			rtxMimeType := fmt.Sprintf("%s/rtx", codec.Kind)
+
+			if pt, err = registry.allocate(rtxMimeType); err != nil {
 				return
 			}
-			pt := DYNAMIC_PAYLOAD_TYPES[dynamicPayloadTypeIdx]
 
 			rtxCodec := RtpCodecCapability{
 				Kind:                 codec.Kind,
-				MimeType:             fmt.Sprintf("%s/rtx", codec.Kind),
+				MimeType:             rtxMimeType,
 				PreferredPayloadType: pt,
 				ClockRate:            codec.ClockRate,
 				RtcpFeedback:         []RtcpFeedback{},
@@ -114,8 +215,6 @@ func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCa
 				},
 			}
 
-			dynamicPayloadTypeIdx++
-
 			// Append to the codec list.
 			caps.Codecs = append(caps.Codecs, rtxCodec)
 		}
@@ -128,20 +227,45 @@ func GenerateRouterRtpCapabilities(mediaCodecs []RtpCodecCapability) (caps RtpCa
  * Get a mapping of the codec payload, RTP header extensions and encodings from
  * the given Producer RTP parameters to the values expected by the Router.
  *
+ * Unsupported header extensions are rejected unless HeaderExtensionLenient is
+ * given, in which case they are left out of the mapping.
  */
 func GetProducerRtpParametersMapping(
 	params RtpParameters,
 	caps RtpCapabilities,
+	headerExtensionMode ...HeaderExtensionMode,
+) (rtpMapping RtpMappingParameters, err error) {
+	mode := HeaderExtensionStrict
+
+	if len(headerExtensionMode) > 0 {
+		mode = headerExtensionMode[0]
+	}
+
+	return getProducerRtpParametersMapping(params, caps,
+		generateSsrcFunc(NewSequentialSsrcAllocator(generateRandomNumber())), mode, nil)
+}
+
+// getProducerRtpParametersMapping logs its decisions in explain, if not nil.
+func getProducerRtpParametersMapping(
+	params RtpParameters,
+	caps RtpCapabilities,
+	generateMappedSsrc generateSsrcFunc,
+	headerExtensionMode HeaderExtensionMode,
+	explain *OrtcExplanation,
 ) (rtpMapping RtpMappingParameters, err error) {
-	// Match parameters media codecs to capabilities media codecs.
-	codecToCapCodec := map[*RtpCodecCapability]RtpCodecCapability{}
+	// Codecs are modified while matching (codecMatchModify), work on copies.
+	params, caps = params.Clone(), caps.Clone()
+
+	// Match parameters media codecs to capabilities media codecs, in the
+	// order of the parameters.
+	capCodecs := make([]*RtpCodecCapability, len(params.Codecs))
 
 	for i, codec := range params.Codecs {
-		if err = checkCodecParameters(codec); err != nil {
+		if err = validateRtpCodecParameters(codec); err != nil {
 			return
 		}
 
-		if strings.HasSuffix(strings.ToLower(codec.MimeType), "/rtx") {
+		if ParseMimeType(codec.MimeType).IsRtx() {
 			continue
 		}
 
@@ -149,70 +273,80 @@ func GetProducerRtpParametersMapping(
 			&codec, caps.Codecs, codecMatchStrictAndModify)
 
 		if !matched {
+			explain.reject(OrtcStepCodec, codecSubject(codec), "%s",
+				codecMismatchReason(codec, caps.Codecs))
+
 			err = NewUnsupportedError(
-				"unsupported codec [mimeType:%s, payloadType:%d]",
-				codec.MimeType, codec.PreferredPayloadType,
+				"unsupported codec [mimeType:%s, payloadType:%d], supported codecs: %s",
+				codec.MimeType, codec.PayloadType, supportedCodecsString(caps),
 			)
+			return
 		}
 
-		codecToCapCodec[&params.Codecs[i]] = matchedCapCodec
+		capCodecs[i] = &matchedCapCodec
+
+		explain.accept(OrtcStepCodec, codecSubject(codec), "mapped to payloadType %d",
+			matchedCapCodec.PreferredPayloadType)
 	}
 
 	for i, codec := range params.Codecs {
-		if !strings.HasSuffix(strings.ToLower(codec.MimeType), "/rtx") {
+		if !ParseMimeType(codec.MimeType).IsRtx() {
 			continue
 		}
 
 		if codec.Parameters == nil {
-			err = NewTypeError("missing parameters in RTX codec")
+			err = NewTypeError("missing parameters in RTX codec [payloadType:%d]", codec.PayloadType)
 			return
 		}
 
-		var associatedMediaCodec *RtpCodecCapability
+		var capMediaCodec *RtpCodecCapability
 
-		for i, mediaCodec := range params.Codecs {
-			if mediaCodec.PayloadType == codec.Parameters.Apt {
-				associatedMediaCodec = &params.Codecs[i]
+		for j, mediaCodec := range params.Codecs {
+			if mediaCodec.PayloadType == codec.Parameters.Apt && capCodecs[j] != nil &&
+				!ParseMimeType(mediaCodec.MimeType).IsRtx() {
+				capMediaCodec = capCodecs[j]
 				break
 			}
 		}
 
-		if associatedMediaCodec == nil {
-			err = NewTypeError(`missing media codec found for RTX PT %d`, codec.PayloadType)
+		if capMediaCodec == nil {
+			explain.reject(OrtcStepRtx, codecSubject(codec), "no media codec with payloadType %d",
+				codec.Parameters.Apt)
+
+			err = NewTypeError("missing media codec for RTX codec [payloadType:%d, apt:%d]",
+				codec.PayloadType, codec.Parameters.Apt)
 			return
 		}
 
-		capMediaCodec := codecToCapCodec[associatedMediaCodec]
-
-		var associatedCapRtxCodec *RtpCodecCapability
-
 		// Ensure that the capabilities media codec has a RTX codec.
-		for _, capCodec := range caps.Codecs {
-			if !strings.HasSuffix(strings.ToLower(capCodec.MimeType), "/rtx") {
-				continue
-			}
-			if capCodec.Parameters.Apt == capMediaCodec.PreferredPayloadType {
-				associatedCapRtxCodec = &capCodec
+		for j, capCodec := range caps.Codecs {
+			if ParseMimeType(capCodec.MimeType).IsRtx() && capCodec.Parameters != nil &&
+				capCodec.Parameters.Apt == capMediaCodec.PreferredPayloadType {
+				capCodecs[i] = &caps.Codecs[j]
 				break
 			}
 		}
 
-		if associatedCapRtxCodec == nil {
+		if capCodecs[i] == nil {
+			explain.reject(OrtcStepRtx, codecSubject(codec), "no RTX codec in the Router capabilities for %s",
+				capMediaCodec.MimeType)
+
 			err = NewUnsupportedError(
-				"no RTX codec for capability codec PT %d",
-				capMediaCodec.PreferredPayloadType,
+				"no RTX codec for capability codec [mimeType:%s, payloadType:%d]",
+				capMediaCodec.MimeType, capMediaCodec.PreferredPayloadType,
 			)
 			return
 		}
 
-		codecToCapCodec[&params.Codecs[i]] = *associatedCapRtxCodec
+		explain.accept(OrtcStepRtx, codecSubject(codec), "mapped to payloadType %d",
+			capCodecs[i].PreferredPayloadType)
 	}
 
 	// Generate codecs mapping.
-	for codec, capCodec := range codecToCapCodec {
+	for i, codec := range params.Codecs {
 		rtpMapping.Codecs = append(rtpMapping.Codecs, RtpMappingCodec{
 			PayloadType:       codec.PayloadType,
-			MappedPayloadType: capCodec.PreferredPayloadType,
+			MappedPayloadType: capCodecs[i].PreferredPayloadType,
 		})
 	}
 
@@ -220,17 +354,39 @@ func GetProducerRtpParametersMapping(
 	for _, ext := range params.HeaderExtensions {
 		var matchedCapExt *RtpHeaderExtension
 
+		if IsTwoByteHeaderExtensionId(ext.Id) && !caps.ExtmapAllowMixed {
+			explain.reject(OrtcStepHeaderExtension, headerExtensionSubject(ext),
+				"two-byte header extension id not allowed by the Router")
+
+			err = NewUnsupportedError(
+				`two-byte header extension id not allowed by the Router [uri:"%s", id:%d], see WithExtmapAllowMixed()`,
+				ext.Uri, ext.Id,
+			)
+
+			return
+		}
+
 		for _, capExt := range caps.HeaderExtensions {
-			if matchHeaderExtensions(ext, capExt) {
+			// Extensions the Router doesn't receive are unsupported.
+			if matchHeaderExtensions(ext, capExt) && canRecvHeaderExtension(capExt) {
 				matchedCapExt = &capExt
 				break
 			}
 		}
 
+		if matchedCapExt == nil && headerExtensionMode == HeaderExtensionLenient {
+			explain.reject(OrtcStepHeaderExtension, headerExtensionSubject(ext),
+				"not received by the Router, dropped in lenient mode")
+			continue
+		}
+
 		if matchedCapExt == nil {
+			explain.reject(OrtcStepHeaderExtension, headerExtensionSubject(ext),
+				"not received by the Router")
+
 			err = NewUnsupportedError(
-				`unsupported header extensions [uri:"%s", id:%d]`,
-				ext.Uri, ext.Id,
+				`unsupported header extension [uri:"%s", id:%d], supported header extensions: %s`,
+				ext.Uri, ext.Id, supportedHeaderExtensionsString(caps),
 			)
 
 			return
@@ -243,17 +399,39 @@ func GetProducerRtpParametersMapping(
 				MappedId: matchedCapExt.PreferredId,
 			},
 		)
+
+		explain.accept(OrtcStepHeaderExtension, headerExtensionSubject(ext), "mapped to id %d",
+			matchedCapExt.PreferredId)
 	}
 
 	// Generate encodings mapping.
 	for _, encoding := range params.Encodings {
+		mode := ParseScalabilityMode(encoding.ScalabilityMode)
+
 		mappedEncoding := RtpMappingEncoding{
-			Rid:        encoding.Rid,
-			Ssrc:       encoding.Ssrc,
-			MappedSsrc: generateRandomNumber(),
+			Rid:             encoding.Rid,
+			Ssrc:            encoding.Ssrc,
+			MappedSsrc:      generateMappedSsrc(),
+			ScalabilityMode: encoding.ScalabilityMode,
+			SpatialLayers:   mode.SpatialLayers,
+			TemporalLayers:  mode.TemporalLayers,
 		}
 
+		if encoding.Rtx != nil && encoding.Rtx.Ssrc != 0 {
+			mappedEncoding.RtxSsrc = encoding.Rtx.Ssrc
+			mappedEncoding.MappedRtxSsrc = generateMappedSsrc()
+		}
+
+		// Kept even when failing, so the caller can release the SSRCs.
 		rtpMapping.Encodings = append(rtpMapping.Encodings, mappedEncoding)
+
+		if mappedEncoding.MappedSsrc == 0 || mappedEncoding.RtxSsrc != 0 && mappedEncoding.MappedRtxSsrc == 0 {
+			err = NewInvalidStateError("no mapped SSRC available, all the SSRCs of the Router are in use")
+			return
+		}
+
+		explain.accept(OrtcStepEncoding, fmt.Sprintf("[rid:%s, ssrc:%d]", encoding.Rid, encoding.Ssrc),
+			"mapped to ssrc %d", mappedEncoding.MappedSsrc)
 	}
 
 	return
@@ -265,17 +443,19 @@ func GetProducerRtpParametersMapping(
  *
  */
 func GetConsumableRtpParameters(
-	kind string,
+	kind MediaKind,
 	params RtpParameters,
 	caps RtpCapabilities,
 	rtpMapping RtpMappingParameters,
 ) (consumableParams RtpParameters, err error) {
+	params, caps = params.Clone(), caps.Clone()
+
 	for _, codec := range params.Codecs {
-		if err = checkCodecParameters(codec); err != nil {
+		if err = validateRtpCodecParameters(codec); err != nil {
 			return
 		}
 
-		if strings.HasSuffix(strings.ToLower(codec.MimeType), "/rtx") {
+		if ParseMimeType(codec.MimeType).IsRtx() {
 			continue
 		}
 
@@ -311,7 +491,7 @@ func GetConsumableRtpParameters(
 		var consumableCapRtxCodec *RtpCodecCapability
 
 		for _, capRtxCodec := range caps.Codecs {
-			if strings.HasSuffix(strings.ToLower(capRtxCodec.MimeType), "/rtx") &&
+			if ParseMimeType(capRtxCodec.MimeType).IsRtx() &&
 				capRtxCodec.Parameters.Apt == consumableCodec.PayloadType {
 				consumableCapRtxCodec = &capRtxCodec
 				break
@@ -333,10 +513,14 @@ func GetConsumableRtpParameters(
 	}
 
 	for _, capExt := range caps.HeaderExtensions {
-		if capExt.Kind != kind ||
-			capExt.Uri == "urn:ietf:params:rtp-hdrext:sdes:mid" ||
-			capExt.Uri == "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id" ||
-			capExt.Uri == "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id" {
+		// Just take the extensions which can be sent to Consumers.
+		if capExt.Kind != kind || !canSendHeaderExtension(capExt) {
+			continue
+		}
+
+		// The Dependency Descriptor can't be generated by the SFU, so offer it
+		// just if the Producer sends it.
+		if capExt.Uri == DependencyDescriptorUri && !hasHeaderExtension(params, capExt) {
 			continue
 		}
 
@@ -349,6 +533,8 @@ func GetConsumableRtpParameters(
 			consumableParams.HeaderExtensions, consumableExt)
 	}
 
+	consumableParams.ExtmapAllowMixed = hasTwoByteHeaderExtension(consumableParams.HeaderExtensions)
+
 	for i, encoding := range params.Encodings {
 		encoding.Rid = ""
 		encoding.Rtx = nil
@@ -358,12 +544,17 @@ func GetConsumableRtpParameters(
 		consumableParams.Encodings = append(consumableParams.Encodings, encoding)
 	}
 
-	consumableParams.Rtcp = RtcpConfiguation{
+	consumableParams.Rtcp = RtcpParameters{
 		Cname:       params.Rtcp.Cname,
 		ReducedSize: true,
 		Mux:         newBool(true),
 	}
 
+	// Producers without CNAME (e.g. piped ones) still need one.
+	if len(consumableParams.Rtcp.Cname) == 0 {
+		consumableParams.Rtcp.Cname = GenerateCname()
+	}
+
 	return
 }
 
@@ -375,7 +566,7 @@ func CanConsume(consumableParams RtpParameters, caps RtpCapabilities) bool {
 	capCodecs := []RtpCodecCapability{}
 
 	for _, capCodec := range caps.Codecs {
-		if checkCodecCapability(&capCodec) != nil {
+		if validateRtpCodecCapability(&capCodec) != nil {
 			return false
 		}
 		capCodecs = append(capCodecs, capCodec)
@@ -395,7 +586,7 @@ func CanConsume(consumableParams RtpParameters, caps RtpCapabilities) bool {
 
 	// Ensure there is at least one media codec.
 	if len(matchingCodecs) == 0 ||
-		strings.HasSuffix(matchingCodecs[0].MimeType, "/rtx") {
+		ParseMimeType(matchingCodecs[0].MimeType).IsRtx() {
 		return false
 	}
 
@@ -413,10 +604,18 @@ func CanConsume(consumableParams RtpParameters, caps RtpCapabilities) bool {
 func GetConsumerRtpParameters(
 	consumableParams RtpParameters, caps RtpCapabilities,
 ) (consumerParams RtpParameters, err error) {
+	return getConsumerRtpParameters(consumableParams, caps, nil)
+}
+
+// getConsumerRtpParameters logs its decisions in explain, if not nil.
+func getConsumerRtpParameters(
+	consumableParams RtpParameters, caps RtpCapabilities, explain *OrtcExplanation,
+) (consumerParams RtpParameters, err error) {
+	consumableParams, caps = consumableParams.Clone(), caps.Clone()
 	consumerParams.HeaderExtensions = []RtpHeaderExtension{}
 
 	for _, capCodec := range caps.Codecs {
-		if err = checkCodecCapability(&capCodec); err != nil {
+		if err = validateRtpCodecCapability(&capCodec); err != nil {
 			return
 		}
 	}
@@ -426,50 +625,71 @@ func GetConsumerRtpParameters(
 	copier.Copy(&consumableCodecs, &consumableParams.Codecs)
 
 	for _, codec := range consumableCodecs {
-		matchedCapCodec, matched := selectMatchedCodecs(&codec, caps.Codecs, codecMatchStrict)
+		matchedCapCodec, matched := selectMatchedCodecs(&codec, caps.Codecs, codecMatchStrict|codecMatchIntersect)
 
 		if !matched {
+			explain.reject(OrtcStepCodec, codecSubject(codec), "%s",
+				codecMismatchReason(codec, caps.Codecs))
 			continue
 		}
 
+		explain.accept(OrtcStepCodec, codecSubject(codec), "supported by the endpoint")
+
 		codec.RtcpFeedback = matchedCapCodec.RtcpFeedback
 
 		consumerParams.Codecs = append(consumerParams.Codecs, codec)
 
-		if !rtxSupported && strings.HasSuffix(codec.MimeType, "/rtx") {
+		if !rtxSupported && ParseMimeType(codec.MimeType).IsRtx() {
 			rtxSupported = true
 		}
 	}
 
 	// Ensure there is at least one media codec.
 	if len(consumerParams.Codecs) == 0 ||
-		strings.HasSuffix(consumerParams.Codecs[0].MimeType, "/rtx") {
+		ParseMimeType(consumerParams.Codecs[0].MimeType).IsRtx() {
 		err = NewUnsupportedError("no compatible media codecs")
 		return
 	}
 
 	for _, ext := range consumableParams.HeaderExtensions {
+		supported := false
+
+		if IsTwoByteHeaderExtensionId(ext.Id) && !caps.ExtmapAllowMixed {
+			explain.reject(OrtcStepHeaderExtension, headerExtensionSubject(ext),
+				"two-byte header extension id not allowed by the endpoint")
+			continue
+		}
+
 		for _, capExt := range caps.HeaderExtensions {
 			if capExt.PreferredId == ext.Id {
 				consumerParams.HeaderExtensions =
 					append(consumerParams.HeaderExtensions, ext)
+				supported = true
 				break
 			}
 		}
+
+		if supported {
+			explain.accept(OrtcStepHeaderExtension, headerExtensionSubject(ext), "supported by the endpoint")
+		} else {
+			explain.reject(OrtcStepHeaderExtension, headerExtensionSubject(ext), "not in the endpoint capabilities")
+		}
 	}
 
 	consumerEncoding := RtpEncoding{
-		Ssrc: generateRandomNumber(),
+		Ssrc:            generateRandomNumber(),
+		ScalabilityMode: consumerScalabilityMode(consumableParams.Encodings),
 	}
 
 	if rtxSupported {
 		consumerEncoding.Rtx = &RtpEncoding{
-			Ssrc: generateRandomNumber(),
+			Ssrc: consumerEncoding.Ssrc + 1,
 		}
 	}
 
 	consumerParams.Encodings = append(consumerParams.Encodings, consumerEncoding)
 	consumerParams.Rtcp = consumableParams.Rtcp
+	consumerParams.ExtmapAllowMixed = hasTwoByteHeaderExtension(consumerParams.HeaderExtensions)
 
 	return
 }
@@ -486,13 +706,14 @@ func GetConsumerRtpParameters(
  * @throws {TypeError} if wrong arguments.
  */
 func GetPipeConsumerRtpParameters(consumableParams RtpParameters) (consumerParams RtpParameters) {
+	consumableParams = consumableParams.Clone()
 	consumerParams.Rtcp = consumableParams.Rtcp
 
 	consumableCodecs := []RtpCodecCapability{}
 	copier.Copy(&consumableCodecs, &consumableParams.Codecs)
 
 	for _, codec := range consumableCodecs {
-		if strings.HasSuffix(strings.ToLower(codec.MimeType), "/rtx") {
+		if ParseMimeType(codec.MimeType).IsRtx() {
 			continue
 		}
 
@@ -515,6 +736,7 @@ func GetPipeConsumerRtpParameters(consumableParams RtpParameters) (consumerParam
 			consumerParams.HeaderExtensions = append(consumerParams.HeaderExtensions, ext)
 		}
 	}
+	consumerParams.ExtmapAllowMixed = hasTwoByteHeaderExtension(consumerParams.HeaderExtensions)
 
 	consumableEncodings := []RtpEncoding{}
 	copier.Copy(&consumableEncodings, &consumableParams.Encodings)
@@ -528,23 +750,25 @@ func GetPipeConsumerRtpParameters(consumableParams RtpParameters) (consumerParam
 	return
 }
 
-func checkCodecCapability(codec *RtpCodecCapability) (err error) {
-	if len(codec.MimeType) == 0 || codec.ClockRate == 0 {
-		return NewTypeError("invalid RTCRtpCodecCapability")
+// checkAv1Parameters validates the ranges of the AV1 fmtp parameters given
+// by the AV1 RTP payload specification.
+func checkAv1Parameters(codec RtpCodecCapability) error {
+	if ParseMimeType(codec.MimeType).String() != "video/av1" || codec.Parameters == nil {
+		return nil
 	}
 
-	// Add kind if not present.
-	if len(codec.Kind) == 0 {
-		codec.Kind = strings.ToLower(strings.Split(codec.MimeType, "/")[0])
-	}
+	params := codec.Parameters
 
-	return
-}
-
-func checkCodecParameters(codec RtpCodecCapability) error {
-	if len(codec.MimeType) == 0 || codec.ClockRate == 0 {
-		return NewTypeError("invalid RTCRtpCodecParameter{s")
+	if params.Profile != nil && *params.Profile > 2 {
+		return NewTypeError("invalid AV1 profile [profile:%d]", *params.Profile)
+	}
+	if params.LevelIdx != nil && *params.LevelIdx > 31 {
+		return NewTypeError("invalid AV1 level-idx [level-idx:%d]", *params.LevelIdx)
 	}
+	if params.Tier != nil && *params.Tier > 1 {
+		return NewTypeError("invalid AV1 tier [tier:%d]", *params.Tier)
+	}
+
 	return nil
 }
 
@@ -564,8 +788,8 @@ func matchedCodecs(
 	aCodec *RtpCodecCapability,
 	bCodec RtpCodecCapability,
 	mode codecMatchMode) (matched bool) {
-	aMimeType := strings.ToLower(aCodec.MimeType)
-	bMimeType := strings.ToLower(bCodec.MimeType)
+	aMimeType := ParseMimeType(aCodec.MimeType)
+	bMimeType := ParseMimeType(bCodec.MimeType)
 
 	if aMimeType != bMimeType {
 		return
@@ -575,14 +799,19 @@ func matchedCodecs(
 		return
 	}
 
-	if strings.HasPrefix(aMimeType, "audio/") &&
+	if aMimeType.Kind() == "audio" &&
 		aCodec.Channels > 0 &&
 		bCodec.Channels > 0 &&
 		aCodec.Channels != bCodec.Channels {
 		return
 	}
 
-	switch aMimeType {
+	switch aMimeType.String() {
+	case "audio/opus":
+		if mode&codecMatchIntersect > 0 {
+			aCodec.Parameters = intersectOpusParameters(aCodec.Parameters, bCodec.Parameters)
+		}
+
 	case "video/h264":
 		aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
 		if aParameters == nil {
@@ -597,8 +826,10 @@ func matchedCodecs(
 		}
 
 		if mode&codecMatchStrict > 0 {
+			// A missing profile-level-id means 42e01f, see
+			// h264.ParseSdpProfileLevelId().
 			selectedProfileLevelId, err := h264.GenerateProfileLevelIdForAnswer(
-				aParameters.RtpH264Parameter, bParameters.RtpH264Parameter)
+				aParameters.RtpH264Parameter.Normalize(), bParameters.RtpH264Parameter.Normalize())
 			if err != nil {
 				return
 			}
@@ -608,11 +839,202 @@ func matchedCodecs(
 				aCodec.Parameters = aParameters
 			}
 		}
+
+	case "video/h265":
+		aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
+		if aParameters == nil {
+			aParameters = &RtpCodecParameter{}
+		}
+		if bParameters == nil {
+			bParameters = &RtpCodecParameter{}
+		}
+
+		aH265Parameters, bH265Parameters := aParameters.RtpH265Parameter(), bParameters.RtpH265Parameter()
+
+		if aH265Parameters.GetTxMode() != bH265Parameters.GetTxMode() {
+			return
+		}
+
+		if mode&codecMatchStrict > 0 {
+			// Missing profile-id, tier-flag and level-id take their default,
+			// see h265.ParseSdpProfileTierLevel().
+			selectedLevelId, err := h265.GenerateLevelIdForAnswer(aH265Parameters, bH265Parameters)
+			if err != nil {
+				return
+			}
+
+			if mode&codecMatchModify > 0 && selectedLevelId > 0 {
+				aParameters.LevelId = selectedLevelId
+				aCodec.Parameters = aParameters
+			}
+		}
+
+	case "video/vp9":
+		if mode&codecMatchStrict > 0 {
+			aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
+			if aParameters == nil {
+				aParameters = &RtpCodecParameter{}
+			}
+			if bParameters == nil {
+				bParameters = &RtpCodecParameter{}
+			}
+
+			if uint8Value(aParameters.ProfileId, 0) != uint8Value(bParameters.ProfileId, 0) {
+				return
+			}
+		}
+
+	case "video/av1":
+		if mode&codecMatchStrict > 0 {
+			aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
+			if aParameters == nil {
+				aParameters = &RtpCodecParameter{}
+			}
+			if bParameters == nil {
+				bParameters = &RtpCodecParameter{}
+			}
+
+			// Profiles are not compatible with each other.
+			if uint8Value(aParameters.Profile, 0) != uint8Value(bParameters.Profile, 0) {
+				return
+			}
+
+			// A stream of higher tier or level than the one of b cannot be
+			// decoded by b, only checked if both are given.
+			if aParameters.Tier != nil && bParameters.Tier != nil &&
+				*aParameters.Tier > *bParameters.Tier {
+				return
+			}
+			if aParameters.LevelIdx != nil && bParameters.LevelIdx != nil &&
+				*aParameters.LevelIdx > *bParameters.LevelIdx {
+				return
+			}
+		}
 	}
 
 	return true
 }
 
+/**
+ * Intersect the Opus fmtp parameters of a producer codec with the ones the
+ * consuming endpoint states, instead of copying the producer ones:
+ *
+ * - sprop-stereo is cleared if the receiver states stereo=0.
+ * - maxplaybackrate and maxaveragebitrate take the lowest given value.
+ *
+ * Parameters the capabilities don't state are kept as they are. usedtx and
+ * useinbandfec are always kept, capabilities cannot state them disabled. The
+ * producer parameters are never modified, a copy is returned.
+ */
+func intersectOpusParameters(params, capParams *RtpCodecParameter) *RtpCodecParameter {
+	if params == nil || capParams == nil ||
+		capParams.Stereo == nil && capParams.Maxplaybackrate == 0 && capParams.Maxaveragebitrate == 0 {
+		return params
+	}
+
+	result := *params
+	if capParams.Stereo != nil && *capParams.Stereo == 0 {
+		result.SpropStereo = 0
+	}
+	result.Maxplaybackrate = minNonZeroUint32(params.Maxplaybackrate, capParams.Maxplaybackrate)
+	result.Maxaveragebitrate = minNonZeroUint32(params.Maxaveragebitrate, capParams.Maxaveragebitrate)
+
+	return &result
+}
+
+// minNonZeroUint32 returns the lowest of a and b, 0 meaning unset.
+func minNonZeroUint32(a, b uint32) uint32 {
+	if a == 0 || b != 0 && b < a {
+		return b
+	}
+
+	return a
+}
+
+func uint8Value(value *uint8, defaultValue uint8) uint8 {
+	if value == nil {
+		return defaultValue
+	}
+
+	return *value
+}
+
+// stripHeaderExtension returns the RTP parameters without the given header
+// extension.
+func stripHeaderExtension(params RtpParameters, uri string) RtpParameters {
+	headerExtensions := []RtpHeaderExtension{}
+
+	for _, ext := range params.HeaderExtensions {
+		if CanonicalHeaderExtensionUri(ext.Uri) != CanonicalHeaderExtensionUri(uri) {
+			headerExtensions = append(headerExtensions, ext)
+		}
+	}
+
+	params.HeaderExtensions = headerExtensions
+
+	return params
+}
+
+func hasHeaderExtension(params RtpParameters, capExt RtpHeaderExtension) bool {
+	for _, ext := range params.HeaderExtensions {
+		if matchHeaderExtensions(ext, capExt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// supportedCodecsString lists the media codecs of the capabilities for error
+// messages, e.g. "audio/opus, video/VP8".
+func supportedCodecsString(caps RtpCapabilities) string {
+	var mimeTypes []string
+
+	for _, capCodec := range caps.Codecs {
+		if !ParseMimeType(capCodec.MimeType).IsRtx() {
+			mimeTypes = appendUnique(mimeTypes, capCodec.MimeType)
+		}
+	}
+
+	return strings.Join(mimeTypes, ", ")
+}
+
+// supportedHeaderExtensionsString lists the header extensions the Router
+// receives for error messages.
+func supportedHeaderExtensionsString(caps RtpCapabilities) string {
+	var uris []string
+
+	for _, capExt := range caps.HeaderExtensions {
+		if canRecvHeaderExtension(capExt) {
+			uris = appendUnique(uris, capExt.Uri)
+		}
+	}
+
+	return strings.Join(uris, ", ")
+}
+
+// containsHeaderExtensionUri returns whether uris contains the canonical uri,
+// directly or by an alias.
+func containsHeaderExtensionUri(uris []string, uri string) bool {
+	for _, u := range uris {
+		if CanonicalHeaderExtensionUri(u) == uri {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
+
 func matchHeaderExtensions(aExt, bExt RtpHeaderExtension) bool {
 	if len(aExt.Kind) > 0 &&
 		len(bExt.Kind) > 0 &&
@@ -620,5 +1042,5 @@ func matchHeaderExtensions(aExt, bExt RtpHeaderExtension) bool {
 		return false
 	}
 
-	return aExt.Uri == bExt.Uri
+	return CanonicalHeaderExtensionUri(aExt.Uri) == CanonicalHeaderExtensionUri(bExt.Uri)
 }
@@ -0,0 +1,315 @@
+package mediasoup
+
+import "fmt"
+
+// ExtendedRtpCodec is a codec supported by both a device and the remote
+// Router, with the payload types and parameters of each side.
+type ExtendedRtpCodec struct {
+	Kind                 MediaKind          `json:"kind"`
+	MimeType             string             `json:"mimeType"`
+	ClockRate            int                `json:"clockRate"`
+	Channels             int                `json:"channels,omitempty"`
+	LocalPayloadType     int                `json:"localPayloadType"`
+	LocalRtxPayloadType  int                `json:"localRtxPayloadType,omitempty"`
+	RemotePayloadType    int                `json:"remotePayloadType"`
+	RemoteRtxPayloadType int                `json:"remoteRtxPayloadType,omitempty"`
+	LocalParameters      *RtpCodecParameter `json:"localParameters,omitempty"`
+	RemoteParameters     *RtpCodecParameter `json:"remoteParameters,omitempty"`
+	RtcpFeedback         []RtcpFeedback     `json:"rtcpFeedback"`
+}
+
+// ExtendedRtpHeaderExtension is a header extension supported by both a
+// device and the remote Router.
+type ExtendedRtpHeaderExtension struct {
+	Kind      MediaKind `json:"kind"`
+	Uri       string    `json:"uri"`
+	SendId    int       `json:"sendId"`
+	RecvId    int       `json:"recvId"`
+	Encrypt   bool      `json:"encrypt,omitempty"`
+	Direction string    `json:"direction"`
+}
+
+// ExtendedRtpCapabilities are the RTP capabilities shared by a device and
+// the remote Router, as computed by mediasoup-client.
+type ExtendedRtpCapabilities struct {
+	Codecs           []ExtendedRtpCodec           `json:"codecs"`
+	HeaderExtensions []ExtendedRtpHeaderExtension `json:"headerExtensions"`
+}
+
+/**
+ * Generate extended RTP capabilities for sending and receiving, from the
+ * local capabilities of a device and the RTP capabilities of the remote
+ * Router. This is the device side counterpart of the Router ORTC functions,
+ * to build Go based mediasoup clients and test harnesses.
+ */
+func GetExtendedRtpCapabilities(localCaps, remoteCaps RtpCapabilities) (extendedCaps ExtendedRtpCapabilities) {
+	extendedCaps.Codecs = []ExtendedRtpCodec{}
+	extendedCaps.HeaderExtensions = []ExtendedRtpHeaderExtension{}
+
+	// Match media codecs and keep the order preferred by remoteCaps.
+	for _, remoteCodec := range remoteCaps.Codecs {
+		if ParseMimeType(remoteCodec.MimeType).IsRtx() {
+			continue
+		}
+
+		remoteCodec = remoteCodec.Clone()
+
+		var localCodec RtpCodecCapability
+		var matched bool
+
+		for _, codec := range localCaps.Codecs {
+			if matchedCodecs(&remoteCodec, codec, codecMatchStrictAndModify) {
+				localCodec, matched = codec, true
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		extendedCaps.Codecs = append(extendedCaps.Codecs, ExtendedRtpCodec{
+			Kind:              localCodec.Kind,
+			MimeType:          localCodec.MimeType,
+			ClockRate:         localCodec.ClockRate,
+			Channels:          localCodec.Channels,
+			LocalPayloadType:  localCodec.PreferredPayloadType,
+			RemotePayloadType: remoteCodec.PreferredPayloadType,
+			LocalParameters:   localCodec.Parameters,
+			RemoteParameters:  remoteCodec.Parameters,
+			RtcpFeedback:      reduceRtcpFeedback(localCodec, remoteCodec),
+		})
+	}
+
+	// Match RTX codecs.
+	for i, extendedCodec := range extendedCaps.Codecs {
+		localRtxCodec, localOk := findRtxCodec(localCaps.Codecs, extendedCodec.LocalPayloadType)
+		remoteRtxCodec, remoteOk := findRtxCodec(remoteCaps.Codecs, extendedCodec.RemotePayloadType)
+
+		if localOk && remoteOk {
+			extendedCaps.Codecs[i].LocalRtxPayloadType = localRtxCodec.PreferredPayloadType
+			extendedCaps.Codecs[i].RemoteRtxPayloadType = remoteRtxCodec.PreferredPayloadType
+		}
+	}
+
+	// Match header extensions.
+	for _, remoteExt := range remoteCaps.HeaderExtensions {
+		for _, localExt := range localCaps.HeaderExtensions {
+			if localExt.Kind == remoteExt.Kind &&
+				CanonicalHeaderExtensionUri(localExt.Uri) == CanonicalHeaderExtensionUri(remoteExt.Uri) {
+				extendedCaps.HeaderExtensions = append(extendedCaps.HeaderExtensions, ExtendedRtpHeaderExtension{
+					Kind:      remoteExt.Kind,
+					Uri:       remoteExt.Uri,
+					SendId:    localExt.PreferredId,
+					RecvId:    remoteExt.PreferredId,
+					Encrypt:   localExt.PreferredEncrypt,
+					Direction: extendedHeaderExtensionDirection(localExt, remoteExt),
+				})
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// extendedHeaderExtensionDirection returns the direction of a header
+// extension for the device: the reverse of the Router one, restricted to the
+// device one if given.
+func extendedHeaderExtensionDirection(localExt, remoteExt RtpHeaderExtension) string {
+	direction := reverseHeaderExtensionDirection(HeaderExtensionDirection(remoteExt))
+
+	if len(localExt.Direction) == 0 || localExt.Direction == HeaderExtensionDirectionSendRecv ||
+		localExt.Direction == direction {
+		return direction
+	}
+	if direction == HeaderExtensionDirectionSendRecv {
+		return localExt.Direction
+	}
+
+	return HeaderExtensionDirectionInactive
+}
+
+/**
+ * Generate the RTP capabilities for receiving media, i.e. the ones given to
+ * the Router by Consume() requests.
+ */
+func GetRecvRtpCapabilities(extendedCaps ExtendedRtpCapabilities) (caps RtpCapabilities) {
+	caps.Codecs = []RtpCodecCapability{}
+	caps.HeaderExtensions = []RtpHeaderExtension{}
+
+	for _, extendedCodec := range extendedCaps.Codecs {
+		caps.Codecs = append(caps.Codecs, RtpCodecCapability{
+			Kind:                 extendedCodec.Kind,
+			MimeType:             extendedCodec.MimeType,
+			PreferredPayloadType: extendedCodec.RemotePayloadType,
+			ClockRate:            extendedCodec.ClockRate,
+			Channels:             extendedCodec.Channels,
+			Parameters:           extendedCodec.LocalParameters,
+			RtcpFeedback:         extendedCodec.RtcpFeedback,
+		})
+
+		// Add RTX codec.
+		if extendedCodec.RemoteRtxPayloadType == 0 {
+			continue
+		}
+
+		caps.Codecs = append(caps.Codecs, RtpCodecCapability{
+			Kind:                 extendedCodec.Kind,
+			MimeType:             fmt.Sprintf("%s/rtx", extendedCodec.Kind),
+			PreferredPayloadType: extendedCodec.RemoteRtxPayloadType,
+			ClockRate:            extendedCodec.ClockRate,
+			Parameters: &RtpCodecParameter{
+				Apt: extendedCodec.RemotePayloadType,
+			},
+			RtcpFeedback: []RtcpFeedback{},
+		})
+	}
+
+	for _, extendedExt := range extendedCaps.HeaderExtensions {
+		// Ignore RTP extensions not valid for receiving.
+		if extendedExt.Direction != "sendrecv" && extendedExt.Direction != "recvonly" {
+			continue
+		}
+
+		caps.HeaderExtensions = append(caps.HeaderExtensions, RtpHeaderExtension{
+			Kind:             extendedExt.Kind,
+			Uri:              extendedExt.Uri,
+			PreferredId:      extendedExt.RecvId,
+			PreferredEncrypt: extendedExt.Encrypt,
+		})
+	}
+
+	return
+}
+
+/**
+ * Generate the RTP parameters of the given kind for sending media, i.e. the
+ * ones given to the Router by Produce() requests once encodings are set.
+ */
+func GetSendingRtpParameters(kind MediaKind, extendedCaps ExtendedRtpCapabilities) (params RtpParameters) {
+	params.Codecs = []RtpCodecCapability{}
+	params.HeaderExtensions = []RtpHeaderExtension{}
+	params.Encodings = []RtpEncoding{}
+
+	for _, extendedCodec := range extendedCaps.Codecs {
+		if extendedCodec.Kind != kind {
+			continue
+		}
+
+		params.Codecs = append(params.Codecs, RtpCodecCapability{
+			MimeType:     extendedCodec.MimeType,
+			PayloadType:  extendedCodec.LocalPayloadType,
+			ClockRate:    extendedCodec.ClockRate,
+			Channels:     extendedCodec.Channels,
+			Parameters:   extendedCodec.LocalParameters,
+			RtcpFeedback: extendedCodec.RtcpFeedback,
+		})
+
+		// Add RTX codec.
+		if extendedCodec.LocalRtxPayloadType == 0 {
+			continue
+		}
+
+		params.Codecs = append(params.Codecs, RtpCodecCapability{
+			MimeType:    fmt.Sprintf("%s/rtx", extendedCodec.Kind),
+			PayloadType: extendedCodec.LocalRtxPayloadType,
+			ClockRate:   extendedCodec.ClockRate,
+			Parameters: &RtpCodecParameter{
+				Apt: extendedCodec.LocalPayloadType,
+			},
+			RtcpFeedback: []RtcpFeedback{},
+		})
+	}
+
+	for _, extendedExt := range extendedCaps.HeaderExtensions {
+		// Ignore RTP extensions of a different kind and those not valid for
+		// sending.
+		if extendedExt.Kind != kind ||
+			(extendedExt.Direction != "sendrecv" && extendedExt.Direction != "sendonly") {
+			continue
+		}
+
+		encrypt := extendedExt.Encrypt
+
+		params.HeaderExtensions = append(params.HeaderExtensions, RtpHeaderExtension{
+			Uri:     extendedExt.Uri,
+			Id:      extendedExt.SendId,
+			Encrypt: &encrypt,
+		})
+	}
+
+	return
+}
+
+/**
+ * Reduce the given codecs to the first one, or to the one matching capCodec
+ * if given, followed by its RTX codec if any, as done by mediasoup-client
+ * when producing with a specific codec.
+ */
+func ReduceCodecs(codecs []RtpCodecCapability, capCodec *RtpCodecCapability) (filteredCodecs []RtpCodecCapability, err error) {
+	// If no capability codec is given, take the first one (and RTX).
+	if capCodec == nil {
+		if len(codecs) == 0 {
+			return nil, NewTypeError("no codecs")
+		}
+
+		filteredCodecs = append(filteredCodecs, codecs[0])
+
+		if len(codecs) > 1 && isRtxCodecOf(codecs[1], codecs[0]) {
+			filteredCodecs = append(filteredCodecs, codecs[1])
+		}
+
+		return
+	}
+
+	for i, codec := range codecs {
+		capCodecCopy := capCodec.Clone()
+
+		if !matchedCodecs(&capCodecCopy, codec, codecMatchStrict) {
+			continue
+		}
+
+		filteredCodecs = append(filteredCodecs, codec)
+
+		if i+1 < len(codecs) && isRtxCodecOf(codecs[i+1], codec) {
+			filteredCodecs = append(filteredCodecs, codecs[i+1])
+		}
+
+		return
+	}
+
+	return nil, NewTypeError("no matching codec found [mimeType:%s]", capCodec.MimeType)
+}
+
+// reduceRtcpFeedback returns the RTCP feedback of codecB supported by codecA.
+func reduceRtcpFeedback(codecA, codecB RtpCodecCapability) []RtcpFeedback {
+	reducedRtcpFeedback := []RtcpFeedback{}
+
+	for _, bFb := range codecB.RtcpFeedback {
+		for _, aFb := range codecA.RtcpFeedback {
+			if aFb.Type == bFb.Type && aFb.Parameter == bFb.Parameter {
+				reducedRtcpFeedback = append(reducedRtcpFeedback, bFb)
+				break
+			}
+		}
+	}
+
+	return reducedRtcpFeedback
+}
+
+func findRtxCodec(codecs []RtpCodecCapability, payloadType int) (RtpCodecCapability, bool) {
+	for _, codec := range codecs {
+		if ParseMimeType(codec.MimeType).IsRtx() &&
+			codec.Parameters != nil && codec.Parameters.Apt == payloadType {
+			return codec, true
+		}
+	}
+
+	return RtpCodecCapability{}, false
+}
+
+func isRtxCodecOf(rtxCodec, codec RtpCodecCapability) bool {
+	return ParseMimeType(rtxCodec.MimeType).IsRtx() &&
+		rtxCodec.Parameters != nil && rtxCodec.Parameters.Apt == codec.PayloadType
+}
@@ -0,0 +1,204 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDeviceRtpCapabilities() RtpCapabilities {
+	return RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{
+				Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PreferredPayloadType: 111,
+				RtcpFeedback: []RtcpFeedback{{Type: "x-unknown"}},
+			},
+			{
+				Kind: "video", MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 96,
+				RtcpFeedback: []RtcpFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}, {Type: "x-unknown"}},
+			},
+			{
+				Kind: "video", MimeType: "video/rtx", ClockRate: 90000, PreferredPayloadType: 97,
+				Parameters: &RtpCodecParameter{Apt: 96},
+			},
+			{
+				Kind: "video", MimeType: "video/H264", ClockRate: 90000, PreferredPayloadType: 125,
+				Parameters: &RtpCodecParameter{
+					RtpH264Parameter: h264profile.RtpH264Parameter{
+						PacketizationMode:     1,
+						ProfileLevelId:        "42e01f",
+						LevelAsymmetryAllowed: 1,
+					},
+				},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", PreferredId: 1},
+			{Kind: "video", Uri: "urn:3gpp:video-orientation", PreferredId: 13},
+			{Kind: "video", Uri: "urn:unknown", PreferredId: 14},
+		},
+	}
+}
+
+func TestGetExtendedRtpCapabilities(t *testing.T) {
+	routerCaps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+		{Kind: "video", MimeType: "video/VP9", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	deviceCaps := testDeviceRtpCapabilities()
+	extendedCaps := GetExtendedRtpCapabilities(deviceCaps, routerCaps)
+
+	assert.Len(t, extendedCaps.Codecs, 2)
+
+	opus, vp8 := extendedCaps.Codecs[0], extendedCaps.Codecs[1]
+
+	assert.Equal(t, "audio/opus", opus.MimeType)
+	assert.Equal(t, 111, opus.LocalPayloadType)
+	assert.Equal(t, routerCaps.Codecs[0].PreferredPayloadType, opus.RemotePayloadType)
+	assert.Zero(t, opus.LocalRtxPayloadType)
+	assert.Empty(t, opus.RtcpFeedback)
+
+	assert.Equal(t, "video/VP8", vp8.MimeType)
+	assert.Equal(t, 96, vp8.LocalPayloadType)
+	assert.Equal(t, 97, vp8.LocalRtxPayloadType)
+	assert.NotZero(t, vp8.RemoteRtxPayloadType)
+	assert.Contains(t, vp8.RtcpFeedback, RtcpFeedback{Type: "nack", Parameter: "pli"})
+	assert.NotContains(t, vp8.RtcpFeedback, RtcpFeedback{Type: "x-unknown"})
+
+	for _, ext := range extendedCaps.HeaderExtensions {
+		assert.NotEqual(t, "urn:unknown", ext.Uri)
+	}
+
+	recvCaps := GetRecvRtpCapabilities(extendedCaps)
+
+	assert.Len(t, recvCaps.Codecs, 3)
+	assert.Equal(t, "video/rtx", recvCaps.Codecs[2].MimeType)
+	assert.Equal(t, vp8.RemotePayloadType, recvCaps.Codecs[2].Parameters.Apt)
+
+	sendParams := GetSendingRtpParameters(MediaKindVideo, extendedCaps)
+
+	assert.Len(t, sendParams.Codecs, 2)
+	assert.Equal(t, 96, sendParams.Codecs[0].PayloadType)
+	assert.Equal(t, 96, sendParams.Codecs[1].Parameters.Apt)
+
+	for _, ext := range sendParams.HeaderExtensions {
+		assert.NotEqual(t, "urn:ietf:params:rtp-hdrext:ssrc-audio-level", ext.Uri)
+	}
+
+	// The receiving capabilities can consume what the Router offers.
+	consumerParams, err := GetConsumerRtpParameters(RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", ClockRate: 90000, PayloadType: vp8.RemotePayloadType},
+		},
+	}, recvCaps)
+	assert.NoError(t, err)
+	assert.Equal(t, "video/VP8", consumerParams.Codecs[0].MimeType)
+}
+
+func TestGetExtendedRtpCapabilities_H264ProfileLevelId(t *testing.T) {
+	routerCaps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{
+			Kind: "video", MimeType: "video/H264", ClockRate: 90000,
+			Parameters: &RtpCodecParameter{
+				RtpH264Parameter: h264profile.RtpH264Parameter{
+					PacketizationMode:     1,
+					ProfileLevelId:        "42e034",
+					LevelAsymmetryAllowed: 1,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	extendedCaps := GetExtendedRtpCapabilities(testDeviceRtpCapabilities(), routerCaps)
+
+	assert.Len(t, extendedCaps.Codecs, 1)
+	// The router capabilities are not modified by the matching.
+	assert.Equal(t, "42e034", routerCaps.Codecs[0].Parameters.ProfileLevelId)
+}
+
+func TestReduceCodecs(t *testing.T) {
+	codecs := []RtpCodecCapability{
+		{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+		{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 97, Parameters: &RtpCodecParameter{Apt: 96}},
+		{MimeType: "video/VP9", ClockRate: 90000, PayloadType: 98},
+		{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 99, Parameters: &RtpCodecParameter{Apt: 98}},
+	}
+
+	reduced, err := ReduceCodecs(codecs, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, codecs[:2], reduced)
+
+	reduced, err = ReduceCodecs(codecs, &RtpCodecCapability{Kind: "video", MimeType: "video/VP9", ClockRate: 90000})
+	assert.NoError(t, err)
+	assert.Equal(t, codecs[2:], reduced)
+
+	_, err = ReduceCodecs(codecs, &RtpCodecCapability{Kind: "video", MimeType: "video/H264", ClockRate: 90000})
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = ReduceCodecs(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestGetExtendedRtpCapabilities_HeaderExtensionDirection(t *testing.T) {
+	localCaps := RtpCapabilities{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+			{Kind: "video", Uri: VideoOrientationUri, PreferredId: 2},
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:toffset", PreferredId: 3, Direction: HeaderExtensionDirectionRecvOnly},
+		},
+	}
+	remoteCaps := RtpCapabilities{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 5, Direction: HeaderExtensionDirectionRecvOnly},
+			{Kind: "video", Uri: VideoOrientationUri, PreferredId: 4},
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:toffset", PreferredId: 2, Direction: HeaderExtensionDirectionRecvOnly},
+		},
+	}
+
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+
+	var directions []string
+
+	for _, ext := range extendedCaps.HeaderExtensions {
+		directions = append(directions, ext.Direction)
+	}
+
+	assert.Equal(t, []string{
+		HeaderExtensionDirectionSendOnly,
+		HeaderExtensionDirectionSendRecv,
+		HeaderExtensionDirectionInactive,
+	}, directions)
+
+	recvCaps := GetRecvRtpCapabilities(extendedCaps)
+
+	assert.Len(t, recvCaps.HeaderExtensions, 1)
+	assert.Equal(t, VideoOrientationUri, recvCaps.HeaderExtensions[0].Uri)
+
+	sendingParams := GetSendingRtpParameters("video", extendedCaps)
+
+	assert.Len(t, sendingParams.HeaderExtensions, 2)
+}
+
+func TestGetExtendedRtpCapabilities_HeaderExtensionAliases(t *testing.T) {
+	localCaps := RtpCapabilities{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions", PreferredId: 3},
+		},
+	}
+	remoteCaps := RtpCapabilities{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: TransportWideCcUri, PreferredId: 5},
+		},
+	}
+
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+
+	assert.Equal(t, []ExtendedRtpHeaderExtension{
+		{Kind: "video", Uri: TransportWideCcUri, SendId: 3, RecvId: 5, Direction: HeaderExtensionDirectionSendRecv},
+	}, extendedCaps.HeaderExtensions)
+}
@@ -0,0 +1,141 @@
+package mediasoup
+
+import "fmt"
+
+// Steps of an OrtcDecision.
+const (
+	OrtcStepCodec           = "codec"
+	OrtcStepRtx             = "rtx"
+	OrtcStepHeaderExtension = "headerExtension"
+	OrtcStepEncoding        = "encoding"
+)
+
+// OrtcDecision tells whether a codec, header extension or encoding was kept
+// and why.
+type OrtcDecision struct {
+	Step     string `json:"step"`
+	Subject  string `json:"subject"`
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason"`
+}
+
+// OrtcExplanation is the decision log of an ORTC computation, e.g. to be
+// returned by a debug endpoint.
+type OrtcExplanation struct {
+	Decisions []OrtcDecision `json:"decisions"`
+	// Error of the computation, if any.
+	Error string `json:"error,omitempty"`
+}
+
+/**
+ * Same as GetProducerRtpParametersMapping, explaining which codecs, header
+ * extensions and encodings were mapped and why the others were rejected.
+ * The explanation is returned even if the mapping fails.
+ */
+func ExplainProducerRtpParametersMapping(
+	params RtpParameters,
+	caps RtpCapabilities,
+	headerExtensionMode ...HeaderExtensionMode,
+) (rtpMapping RtpMappingParameters, explanation OrtcExplanation, err error) {
+	mode := HeaderExtensionStrict
+
+	if len(headerExtensionMode) > 0 {
+		mode = headerExtensionMode[0]
+	}
+
+	rtpMapping, err = getProducerRtpParametersMapping(params, caps,
+		generateSsrcFunc(NewSequentialSsrcAllocator(generateRandomNumber())), mode, &explanation)
+
+	explanation.fail(err)
+
+	return
+}
+
+/**
+ * Same as GetConsumerRtpParameters, explaining which codecs and header
+ * extensions were kept and why the others were rejected. The explanation is
+ * returned even if no codec is compatible.
+ */
+func ExplainConsumerRtpParameters(
+	consumableParams RtpParameters, caps RtpCapabilities,
+) (consumerParams RtpParameters, explanation OrtcExplanation, err error) {
+	consumerParams, err = getConsumerRtpParameters(consumableParams, caps, &explanation)
+
+	explanation.fail(err)
+
+	return
+}
+
+// accept logs a kept item, nothing if the explanation is nil.
+func (e *OrtcExplanation) accept(step, subject, format string, args ...interface{}) {
+	e.add(step, subject, true, format, args...)
+}
+
+// reject logs a rejected item, nothing if the explanation is nil.
+func (e *OrtcExplanation) reject(step, subject, format string, args ...interface{}) {
+	e.add(step, subject, false, format, args...)
+}
+
+func (e *OrtcExplanation) add(step, subject string, accepted bool, format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+
+	e.Decisions = append(e.Decisions, OrtcDecision{
+		Step:     step,
+		Subject:  subject,
+		Accepted: accepted,
+		Reason:   fmt.Sprintf(format, args...),
+	})
+}
+
+func (e *OrtcExplanation) fail(err error) {
+	if e != nil && err != nil {
+		e.Error = err.Error()
+	}
+}
+
+func codecSubject(codec RtpCodecCapability) string {
+	return fmt.Sprintf("%s [payloadType:%d]", codec.MimeType, codec.PayloadType)
+}
+
+func headerExtensionSubject(ext RtpHeaderExtension) string {
+	return fmt.Sprintf("%s [id:%d]", ext.Uri, ext.Id)
+}
+
+// codecMismatchReason tells why no codec of the list matches the given one.
+func codecMismatchReason(codec RtpCodecCapability, codecs []RtpCodecCapability) string {
+	var sameMimeType []RtpCodecCapability
+
+	mimeType := ParseMimeType(codec.MimeType)
+
+	for _, c := range codecs {
+		if ParseMimeType(c.MimeType) == mimeType {
+			sameMimeType = append(sameMimeType, c)
+		}
+	}
+
+	if len(sameMimeType) == 0 {
+		return "mime type not supported"
+	}
+
+	var sameClockRate []RtpCodecCapability
+
+	for _, c := range sameMimeType {
+		if c.ClockRate == codec.ClockRate {
+			sameClockRate = append(sameClockRate, c)
+		}
+	}
+
+	if len(sameClockRate) == 0 {
+		return fmt.Sprintf("clock rate %d not supported", codec.ClockRate)
+	}
+
+	for _, c := range sameClockRate {
+		if codec.Channels == 0 || c.Channels == 0 || c.Channels == codec.Channels {
+			return "codec parameters not compatible (e.g. profile or packetization mode)"
+		}
+	}
+
+	return fmt.Sprintf("%d channels not supported", codec.Channels)
+}
@@ -0,0 +1,89 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainProducerRtpParametersMapping(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	assert.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PayloadType: 111},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+			{Uri: "urn:foo", Id: 2},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	}
+
+	_, explanation, err := ExplainProducerRtpParametersMapping(params, caps, HeaderExtensionLenient)
+	assert.NoError(t, err)
+	assert.Empty(t, explanation.Error)
+
+	if assert.Len(t, explanation.Decisions, 4) {
+		assert.Equal(t, OrtcStepCodec, explanation.Decisions[0].Step)
+		assert.True(t, explanation.Decisions[0].Accepted)
+		assert.True(t, explanation.Decisions[1].Accepted)
+		assert.Equal(t, OrtcDecision{
+			Step:     OrtcStepHeaderExtension,
+			Subject:  "urn:foo [id:2]",
+			Reason:   "not received by the Router, dropped in lenient mode",
+			Accepted: false,
+		}, explanation.Decisions[2])
+		assert.Equal(t, OrtcStepEncoding, explanation.Decisions[3].Step)
+	}
+
+	params.Codecs[0].ClockRate = 16000
+
+	_, explanation, err = ExplainProducerRtpParametersMapping(params, caps)
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), explanation.Error)
+	assert.Equal(t, []OrtcDecision{{
+		Step:    OrtcStepCodec,
+		Subject: "audio/opus [payloadType:111]",
+		Reason:  "clock rate 16000 not supported",
+	}}, explanation.Decisions)
+
+	assert.Equal(t, "clock rate 16000 not supported", codecMismatchReason(
+		RtpCodecCapability{MimeType: " AUDIO/Opus", ClockRate: 16000}, caps.Codecs))
+}
+
+func TestExplainConsumerRtpParameters(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 101},
+			{MimeType: "video/H264", ClockRate: 90000, PayloadType: 102},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "video", MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 101},
+		},
+	}
+
+	params, explanation, err := ExplainConsumerRtpParameters(consumableParams, caps)
+	assert.NoError(t, err)
+	assert.Len(t, params.Codecs, 1)
+	assert.Equal(t, []OrtcDecision{
+		{Step: OrtcStepCodec, Subject: "video/VP8 [payloadType:101]", Accepted: true, Reason: "supported by the endpoint"},
+		{Step: OrtcStepCodec, Subject: "video/H264 [payloadType:102]", Reason: "mime type not supported"},
+		{Step: OrtcStepHeaderExtension, Subject: "urn:ietf:params:rtp-hdrext:sdes:mid [id:1]", Reason: "not in the endpoint capabilities"},
+	}, explanation.Decisions)
+
+	caps.Codecs = nil
+
+	_, explanation, err = ExplainConsumerRtpParameters(consumableParams, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+	assert.Equal(t, err.Error(), explanation.Error)
+}
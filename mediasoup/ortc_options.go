@@ -0,0 +1,170 @@
+package mediasoup
+
+// ProducerRtpParametersMappingOptions are the arguments of
+// ComputeProducerRtpParametersMapping().
+type ProducerRtpParametersMappingOptions struct {
+	// Params are the RTP parameters given by the Producer.
+	Params RtpParameters
+	// Caps are the RTP capabilities of the Router.
+	Caps RtpCapabilities
+	// HeaderExtensionMode, default HeaderExtensionStrict.
+	HeaderExtensionMode HeaderExtensionMode
+}
+
+func (o ProducerRtpParametersMappingOptions) Validate() error {
+	if len(o.Params.Codecs) == 0 {
+		return NewTypeError("missing params.codecs")
+	}
+	if len(o.Caps.Codecs) == 0 {
+		return NewTypeError("missing caps.codecs")
+	}
+	if o.HeaderExtensionMode != HeaderExtensionStrict &&
+		o.HeaderExtensionMode != HeaderExtensionLenient {
+		return NewTypeError("invalid headerExtensionMode [mode:%v]", o.HeaderExtensionMode)
+	}
+
+	return nil
+}
+
+// ComputeProducerRtpParametersMapping is GetProducerRtpParametersMapping()
+// with an option struct, so new options don't break callers.
+func ComputeProducerRtpParametersMapping(o ProducerRtpParametersMappingOptions) (RtpMappingParameters, error) {
+	if err := o.Validate(); err != nil {
+		return RtpMappingParameters{}, err
+	}
+
+	return GetProducerRtpParametersMapping(o.Params, o.Caps, o.HeaderExtensionMode)
+}
+
+// ConsumableRtpParametersOptions are the arguments of
+// ComputeConsumableRtpParameters().
+type ConsumableRtpParametersOptions struct {
+	// Kind of the Producer, "audio" or "video".
+	Kind MediaKind
+	// Params are the RTP parameters given by the Producer.
+	Params RtpParameters
+	// Caps are the RTP capabilities of the Router.
+	Caps RtpCapabilities
+	// Mapping returned by ComputeProducerRtpParametersMapping().
+	Mapping RtpMappingParameters
+}
+
+func (o ConsumableRtpParametersOptions) Validate() error {
+	if !o.Kind.IsRtp() {
+		return NewTypeError(`invalid kind "%s"`, o.Kind)
+	}
+	if len(o.Mapping.Codecs) == 0 {
+		return NewTypeError("missing mapping.codecs")
+	}
+
+	return nil
+}
+
+// ComputeConsumableRtpParameters is GetConsumableRtpParameters() with an
+// option struct, so new options don't break callers.
+func ComputeConsumableRtpParameters(o ConsumableRtpParametersOptions) (RtpParameters, error) {
+	if err := o.Validate(); err != nil {
+		return RtpParameters{}, err
+	}
+
+	return GetConsumableRtpParameters(o.Kind, o.Params, o.Caps, o.Mapping)
+}
+
+// ConsumerRtpParametersOptions are the arguments of
+// ComputeConsumerRtpParameters().
+type ConsumerRtpParametersOptions struct {
+	// ConsumableParams of the Producer.
+	ConsumableParams RtpParameters
+	// Caps are the RTP capabilities of the consuming endpoint, unused for
+	// pipe Consumers.
+	Caps RtpCapabilities
+	// Pipe generates the parameters of a pipe Consumer.
+	Pipe bool
+	// DisableRtx removes RTX codecs and streams.
+	DisableRtx bool
+	// PreferredCodec is the mime type of the media codec to put first if
+	// several are available, e.g. "video/H264".
+	PreferredCodec string
+}
+
+func (o ConsumerRtpParametersOptions) Validate() error {
+	if len(o.ConsumableParams.Codecs) == 0 {
+		return NewTypeError("missing consumableParams.codecs")
+	}
+	if !o.Pipe && len(o.Caps.Codecs) == 0 {
+		return NewTypeError("missing caps.codecs")
+	}
+	if len(o.PreferredCodec) > 0 && len(ParseMimeType(o.PreferredCodec).Subtype()) == 0 {
+		return NewTypeError("invalid preferredCodec [mimeType:%s]", o.PreferredCodec)
+	}
+
+	return nil
+}
+
+// ComputeConsumerRtpParameters is GetConsumerRtpParameters() and
+// GetPipeConsumerRtpParameters() with an option struct, so new options don't
+// break callers.
+func ComputeConsumerRtpParameters(o ConsumerRtpParametersOptions) (params RtpParameters, err error) {
+	if err = o.Validate(); err != nil {
+		return
+	}
+
+	if o.Pipe {
+		params = GetPipeConsumerRtpParameters(o.ConsumableParams)
+	} else if params, err = GetConsumerRtpParameters(o.ConsumableParams, o.Caps); err != nil {
+		return
+	}
+
+	if o.DisableRtx {
+		params = removeRtx(params)
+	}
+
+	if len(o.PreferredCodec) > 0 {
+		params = preferCodec(params, ParseMimeType(o.PreferredCodec))
+	}
+
+	return
+}
+
+func removeRtx(params RtpParameters) RtpParameters {
+	codecs := make([]RtpCodecCapability, 0, len(params.Codecs))
+
+	for _, codec := range params.Codecs {
+		if !ParseMimeType(codec.MimeType).IsRtx() {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	encodings := make([]RtpEncoding, 0, len(params.Encodings))
+
+	for _, encoding := range params.Encodings {
+		encoding.Rtx = nil
+		encodings = append(encodings, encoding)
+	}
+
+	params.Codecs = codecs
+	params.Encodings = encodings
+
+	return params
+}
+
+// preferCodec moves the first media codec with the given mime type to the
+// front, keeping the order of the others.
+func preferCodec(params RtpParameters, mimeType MimeType) RtpParameters {
+	for i, codec := range params.Codecs {
+		if ParseMimeType(codec.MimeType) != mimeType {
+			continue
+		}
+
+		codecs := make([]RtpCodecCapability, 0, len(params.Codecs))
+		codecs = append(codecs, codec)
+		codecs = append(codecs, params.Codecs[:i]...)
+		codecs = append(codecs, params.Codecs[i+1:]...)
+
+		params.Codecs = codecs
+
+		break
+	}
+
+	return params
+}
@@ -0,0 +1,61 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerRtpParametersOptionsValidate(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []RtpCodecCapability{{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000}},
+	}
+
+	assert.IsType(t, NewTypeError(""), ConsumerRtpParametersOptions{}.Validate())
+	assert.IsType(t, NewTypeError(""), ConsumerRtpParametersOptions{
+		ConsumableParams: consumableParams,
+	}.Validate())
+	assert.NoError(t, ConsumerRtpParametersOptions{
+		ConsumableParams: consumableParams,
+		Pipe:             true,
+	}.Validate())
+	assert.IsType(t, NewTypeError(""), ConsumerRtpParametersOptions{
+		ConsumableParams: consumableParams,
+		Pipe:             true,
+		PreferredCodec:   "vp8",
+	}.Validate())
+}
+
+func TestComputeConsumerRtpParameters(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+			{MimeType: "video/H264", PayloadType: 103, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 104, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 103}},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+		Rtcp:      RtcpParameters{Cname: "FOOBAR"},
+	}
+
+	params, err := ComputeConsumerRtpParameters(ConsumerRtpParametersOptions{
+		ConsumableParams: consumableParams,
+		Pipe:             true,
+		DisableRtx:       true,
+		PreferredCodec:   "video/h264",
+	})
+	assert.NoError(t, err)
+
+	mimeTypes := []string{}
+	for _, codec := range params.Codecs {
+		mimeTypes = append(mimeTypes, codec.MimeType)
+	}
+	assert.Equal(t, []string{"video/H264", "video/VP8"}, mimeTypes)
+
+	for _, encoding := range params.Encodings {
+		assert.Nil(t, encoding.Rtx)
+	}
+
+	_, err = ComputeConsumerRtpParameters(ConsumerRtpParametersOptions{})
+	assert.Error(t, err)
+}
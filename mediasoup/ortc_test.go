@@ -170,6 +170,45 @@ func TestGenerateRouterRtpCapabilities_TooManyCodecs(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGenerateRouterRtpCapabilities_CodecOrder(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:      "audio",
+			MimeType:  "audio/opus",
+			ClockRate: 48000,
+			Channels:  2,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/H264",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/VP9",
+			ClockRate: 90000,
+		},
+	}
+
+	rtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs,
+		WithCodecOrder(PreferCodecs("video/vp9", "video/h264")))
+	assert.NoError(t, err)
+
+	var mimeTypes []string
+	for _, codec := range rtpCapabilities.Codecs {
+		mimeTypes = append(mimeTypes, codec.MimeType)
+	}
+
+	assert.Equal(t, []string{
+		"video/VP9", "video/rtx", "video/H264", "video/rtx", "audio/opus",
+	}, mimeTypes)
+	assert.Equal(t, 100, rtpCapabilities.Codecs[0].PreferredPayloadType)
+	assert.Equal(t, 100, rtpCapabilities.Codecs[1].Parameters.Apt)
+
+	// Input codecs are left untouched.
+	assert.Equal(t, "audio/opus", mediaCodecs[0].MimeType)
+}
+
 func TestProducerComsumerPipeRtpParameters_Succeed(t *testing.T) {
 	mediaCodecs := []RtpCodecCapability{
 		{
@@ -537,6 +576,307 @@ func TestGetProducerRtpParametersMapping_UnsupportedError(t *testing.T) {
 	assert.IsType(t, err, NewUnsupportedError(""))
 }
 
+func TestGetProducerRtpParametersMapping_HeaderExtensionMode(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:      "audio",
+			MimeType:  "audio/opus",
+			ClockRate: 48000,
+			Channels:  2,
+		},
+	}
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "audio/opus",
+				ClockRate:   48000,
+				Channels:    2,
+				PayloadType: 111,
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+			{Uri: "urn:example:proprietary", Id: 2},
+		},
+		Encodings: []RtpEncoding{
+			{
+				Ssrc: 11111111,
+			},
+		},
+	}
+
+	_, err = GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	rtpMapping, err := GetProducerRtpParametersMapping(
+		rtpParameters, routerRtpCapabilities, HeaderExtensionLenient)
+	assert.NoError(t, err)
+	assert.Len(t, rtpMapping.HeaderExtensions, 1)
+	assert.Equal(t, 1, rtpMapping.HeaderExtensions[0].Id)
+}
+
+func TestGetConsumerRtpParameters_DependencyDescriptor(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:      "video",
+			MimeType:  "video/AV1",
+			ClockRate: 90000,
+		},
+	}
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/AV1",
+				ClockRate:   90000,
+				PayloadType: 96,
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: DependencyDescriptorUri, Id: 11},
+		},
+		Encodings: []RtpEncoding{
+			{
+				Ssrc: 11111111,
+			},
+		},
+	}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtpMappingHeaderExt{{Id: 11, MappedId: 8}}, rtpMapping.HeaderExtensions)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters(
+		"video", rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+	assert.Contains(t, consumableRtpParameters.HeaderExtensions,
+		RtpHeaderExtension{Uri: DependencyDescriptorUri, Id: 8})
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(
+		consumableRtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+	assert.Contains(t, consumerRtpParameters.HeaderExtensions,
+		RtpHeaderExtension{Uri: DependencyDescriptorUri, Id: 8})
+
+	// Not offered if the Producer does not send it.
+	rtpParameters.HeaderExtensions = nil
+
+	consumableRtpParameters, err = GetConsumableRtpParameters(
+		"video", rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+	assert.NotContains(t, consumableRtpParameters.HeaderExtensions,
+		RtpHeaderExtension{Uri: DependencyDescriptorUri, Id: 8})
+}
+
+func TestGetConsumerRtpParameters_AV1(t *testing.T) {
+	uint8Ptr := func(v uint8) *uint8 { return &v }
+
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:       "video",
+			MimeType:   "video/AV1",
+			ClockRate:  90000,
+			Parameters: &RtpCodecParameter{Profile: uint8Ptr(0), LevelIdx: uint8Ptr(12)},
+		},
+	}
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(12), *routerRtpCapabilities.Codecs[0].Parameters.LevelIdx)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/AV1",
+				ClockRate:   90000,
+				PayloadType: 96,
+				Parameters:  &RtpCodecParameter{Profile: uint8Ptr(1)},
+			},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+	}
+
+	// Profile 1 is not supported by the Router.
+	_, err = GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	rtpParameters.Codecs[0].Parameters = &RtpCodecParameter{LevelIdx: uint8Ptr(8)}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters(
+		"video", rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{
+				Kind:                 "video",
+				MimeType:             "video/AV1",
+				ClockRate:            90000,
+				PreferredPayloadType: 96,
+				Parameters:           &RtpCodecParameter{LevelIdx: uint8Ptr(5)},
+			},
+		},
+	}
+
+	// The endpoint cannot decode level-idx 8.
+	_, err = GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	caps.Codecs[0].Parameters.LevelIdx = uint8Ptr(8)
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, "video/AV1", consumerRtpParameters.Codecs[0].MimeType)
+
+	caps.Codecs[0].Parameters.Tier = uint8Ptr(2)
+	assert.False(t, CanConsume(consumableRtpParameters, caps))
+}
+
+func TestGetProducerRtpParametersMapping_VP9ProfileId(t *testing.T) {
+	uint8Ptr := func(v uint8) *uint8 { return &v }
+
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:      "video",
+			MimeType:  "video/VP9",
+			ClockRate: 90000,
+		},
+		{
+			Kind:       "video",
+			MimeType:   "video/VP9",
+			ClockRate:  90000,
+			Parameters: &RtpCodecParameter{ProfileId: uint8Ptr(2)},
+		},
+	}
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/VP9",
+				ClockRate:   90000,
+				PayloadType: 98,
+				Parameters:  &RtpCodecParameter{ProfileId: uint8Ptr(2)},
+			},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+	}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+
+	// Mapped to the profile 2 capability, not to the first VP9 one.
+	var profile2PayloadType int
+
+	for _, codec := range routerRtpCapabilities.Codecs {
+		if codec.MimeType == "video/VP9" && uint8Value(codec.Parameters.ProfileId, 0) == 2 {
+			profile2PayloadType = codec.PreferredPayloadType
+		}
+	}
+
+	assert.NotZero(t, profile2PayloadType)
+	assert.Equal(t, profile2PayloadType, rtpMapping.Codecs[0].MappedPayloadType)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters(
+		"video", rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{
+				Kind:                 "video",
+				MimeType:             "video/VP9",
+				ClockRate:            90000,
+				PreferredPayloadType: 98,
+			},
+		},
+	}
+
+	// A profile 0 only endpoint cannot consume it.
+	assert.False(t, CanConsume(consumableRtpParameters, caps))
+
+	caps.Codecs[0].Parameters = &RtpCodecParameter{ProfileId: uint8Ptr(2)}
+	assert.True(t, CanConsume(consumableRtpParameters, caps))
+}
+
+func TestGetConsumerRtpParameters_H265(t *testing.T) {
+	uint8Ptr := func(v uint8) *uint8 { return &v }
+
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:       "video",
+			MimeType:   "video/H265",
+			ClockRate:  90000,
+			Parameters: &RtpCodecParameter{ProfileId: uint8Ptr(1), LevelId: 153},
+		},
+	}
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/H265",
+				ClockRate:   90000,
+				PayloadType: 96,
+				Parameters:  &RtpCodecParameter{ProfileId: uint8Ptr(2)},
+			},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+	}
+
+	// Main 10 is not supported by the Router.
+	_, err = GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	rtpParameters.Codecs[0].Parameters = &RtpCodecParameter{LevelId: 120}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters(
+		"video", rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{
+				Kind:                 "video",
+				MimeType:             "video/H265",
+				ClockRate:            90000,
+				PreferredPayloadType: 96,
+				Parameters:           &RtpCodecParameter{TxMode: "MRST"},
+			},
+		},
+	}
+
+	// Different transmission mode.
+	assert.False(t, CanConsume(consumableRtpParameters, caps))
+
+	caps.Codecs[0].Parameters = nil
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, "video/H265", consumerRtpParameters.Codecs[0].MimeType)
+
+	// High tier endpoints cannot consume main tier streams.
+	caps.Codecs[0].Parameters = &RtpCodecParameter{TierFlag: 1, LevelId: 150}
+	assert.False(t, CanConsume(consumableRtpParameters, caps))
+}
+
 func assertJSONEq(t *testing.T, expected, actual interface{}) {
 	expectedData, err := json.Marshal(expected)
 	assert.NoError(t, err)
@@ -546,3 +886,320 @@ func assertJSONEq(t *testing.T, expected, actual interface{}) {
 
 	assert.JSONEq(t, string(expectedData), string(actualData))
 }
+
+func TestGetProducerRtpParametersMapping_Rtx(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+			{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 97, Parameters: &RtpCodecParameter{Apt: 96}},
+		},
+		Encodings: []RtpEncoding{
+			{Ssrc: 1, Rtx: &RtpEncoding{Ssrc: 2}},
+			{Ssrc: 3, Rtx: &RtpEncoding{Ssrc: 4}},
+			{Ssrc: 5},
+		},
+	}
+
+	mapping, err := GetProducerRtpParametersMapping(params, caps)
+	assert.NoError(t, err)
+
+	mappedSsrcs := map[uint32]bool{}
+
+	for i, encoding := range mapping.Encodings {
+		if rtx := params.Encodings[i].Rtx; rtx != nil {
+			assert.Equal(t, rtx.Ssrc, encoding.RtxSsrc)
+			assert.NotZero(t, encoding.MappedRtxSsrc)
+		} else {
+			assert.Zero(t, encoding.RtxSsrc)
+			assert.Zero(t, encoding.MappedRtxSsrc)
+		}
+
+		for _, ssrc := range []uint32{encoding.MappedSsrc, encoding.MappedRtxSsrc} {
+			if ssrc != 0 {
+				assert.False(t, mappedSsrcs[ssrc])
+				mappedSsrcs[ssrc] = true
+			}
+		}
+	}
+
+	assert.Len(t, mappedSsrcs, 5)
+}
+
+func TestGetProducerRtpParametersMapping_UnsupportedCodec(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/H264", ClockRate: 90000, PayloadType: 96},
+			{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 97, Parameters: &RtpCodecParameter{Apt: 96}},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	}
+
+	_, err = GetProducerRtpParametersMapping(params, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+	assert.Contains(t, err.Error(), "payloadType:96")
+	assert.Contains(t, err.Error(), "supported codecs: audio/opus, video/VP8")
+
+	params.Codecs[0].MimeType = "video/VP8"
+	params.HeaderExtensions = []RtpHeaderExtension{{Uri: "urn:unknown", Id: 1}}
+
+	_, err = GetProducerRtpParametersMapping(params, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+	assert.Contains(t, err.Error(), "urn:ietf:params:rtp-hdrext:sdes:mid")
+
+	params.HeaderExtensions = nil
+
+	rtpMapping, err := GetProducerRtpParametersMapping(params, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtpMappingCodec{
+		{PayloadType: 96, MappedPayloadType: caps.Codecs[1].PreferredPayloadType},
+		{PayloadType: 97, MappedPayloadType: caps.Codecs[2].PreferredPayloadType},
+	}, rtpMapping.Codecs)
+}
+
+func TestGetConsumableRtpParameters_HeaderExtensionExclusions(t *testing.T) {
+	const midUri = "urn:ietf:params:rtp-hdrext:sdes:mid"
+
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	}, WithIncludedHeaderExtensions(midUri), WithExcludedHeaderExtensions(VideoOrientationUri))
+	assert.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs:    []RtpCodecCapability{{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96}},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(params, caps)
+	assert.NoError(t, err)
+
+	consumableParams, err := GetConsumableRtpParameters("video", params, caps, rtpMapping)
+	assert.NoError(t, err)
+
+	var uris []string
+
+	for _, ext := range consumableParams.HeaderExtensions {
+		uris = append(uris, ext.Uri)
+	}
+
+	assert.Contains(t, uris, midUri)
+	assert.NotContains(t, uris, VideoOrientationUri)
+	assert.NotContains(t, uris, "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id")
+
+	// The supported capabilities are left untouched.
+	for _, ext := range GetSupportedRtpCapabilities().HeaderExtensions {
+		if ext.Uri == midUri {
+			assert.Equal(t, HeaderExtensionDirectionRecvOnly, ext.Direction)
+		}
+	}
+}
+
+func TestGetConsumerRtpParameters_Opus(t *testing.T) {
+	producerParameters := &RtpCodecParameter{
+		SpropStereo:       1,
+		Useinbandfec:      1,
+		Usedtx:            1,
+		Maxplaybackrate:   48000,
+		Maxaveragebitrate: 128000,
+	}
+	consumableRtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "audio/opus",
+				ClockRate:   48000,
+				Channels:    2,
+				PayloadType: 100,
+				Parameters:  producerParameters,
+			},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{
+				Kind:                 "audio",
+				MimeType:             "audio/opus",
+				ClockRate:            48000,
+				Channels:             2,
+				PreferredPayloadType: 100,
+				Parameters: &RtpCodecParameter{
+					Useinbandfec:    1,
+					Maxplaybackrate: 16000,
+				},
+			},
+		},
+	}
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, &RtpCodecParameter{
+		SpropStereo:       1,
+		Useinbandfec:      1,
+		Usedtx:            1,
+		Maxplaybackrate:   16000,
+		Maxaveragebitrate: 128000,
+	}, consumerRtpParameters.Codecs[0].Parameters)
+
+	// The consumable parameters are left untouched.
+	assert.EqualValues(t, 48000, producerParameters.Maxplaybackrate)
+
+	// A mono receiver.
+	mono := uint8(0)
+	caps.Codecs[0].Parameters = &RtpCodecParameter{Stereo: &mono, Maxaveragebitrate: 64000}
+
+	consumerRtpParameters, err = GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, &RtpCodecParameter{
+		Useinbandfec:      1,
+		Usedtx:            1,
+		Maxplaybackrate:   48000,
+		Maxaveragebitrate: 64000,
+	}, consumerRtpParameters.Codecs[0].Parameters)
+	assert.EqualValues(t, 1, producerParameters.SpropStereo)
+
+	// No Opus preferences, the producer parameters are kept.
+	caps.Codecs[0].Parameters = nil
+
+	consumerRtpParameters, err = GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, producerParameters, consumerRtpParameters.Codecs[0].Parameters)
+}
+
+func TestGenerateRouterRtpCapabilities_SupportedOverrides(t *testing.T) {
+	l16 := RtpCodecCapability{
+		Kind:      "audio",
+		MimeType:  "audio/L16",
+		ClockRate: 44100,
+		Channels:  2,
+	}
+	frameMarking := RtpHeaderExtension{
+		Kind:        "video",
+		Uri:         "http://tools.ietf.org/html/draft-ietf-avtext-framemarking-07",
+		PreferredId: 13,
+	}
+
+	_, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{l16})
+	assert.IsType(t, NewUnsupportedError(""), err)
+
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{l16},
+		WithAdditionalCodecs(l16), WithCustomHeaderExtensions(frameMarking))
+	assert.NoError(t, err)
+	assert.Equal(t, "audio/L16", caps.Codecs[0].MimeType)
+	assert.Equal(t, 100, caps.Codecs[0].PreferredPayloadType)
+
+	frameMarking.Direction = HeaderExtensionDirectionSendRecv
+	assert.Contains(t, caps.HeaderExtensions, frameMarking)
+
+	// The package capabilities are left untouched.
+	assert.NotContains(t, GetSupportedRtpCapabilities().HeaderExtensions, frameMarking)
+
+	_, err = GenerateRouterRtpCapabilities([]RtpCodecCapability{l16},
+		WithAdditionalCodecs(RtpCodecCapability{MimeType: "audio/opus", ClockRate: 48000, Channels: 2}))
+	assert.IsType(t, NewTypeError(""), err)
+
+	frameMarking.PreferredId = 5
+	_, err = GenerateRouterRtpCapabilities([]RtpCodecCapability{l16},
+		WithAdditionalCodecs(l16), WithCustomHeaderExtensions(frameMarking))
+	assert.IsType(t, NewTypeError(""), err)
+
+	// Replaced capabilities.
+	caps, err = GenerateRouterRtpCapabilities([]RtpCodecCapability{l16},
+		WithSupportedRtpCapabilities(RtpCapabilities{Codecs: []RtpCodecCapability{l16}}))
+	assert.NoError(t, err)
+	assert.Len(t, caps.Codecs, 1)
+	assert.Empty(t, caps.HeaderExtensions)
+}
+
+func TestGenerateRouterRtpCapabilities_PreferredPayloadType(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PreferredPayloadType: 101},
+		{Kind: "audio", MimeType: "audio/PCMA", ClockRate: 8000},
+	}
+
+	caps, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	pts := []int{}
+	for _, codec := range caps.Codecs {
+		pts = append(pts, codec.PreferredPayloadType)
+	}
+	// VP8 and its RTX skip 101, taken by opus, PCMA keeps its static one.
+	assert.Equal(t, []int{100, 102, 101, 8}, pts)
+	assert.Equal(t, 100, caps.Codecs[1].Parameters.Apt)
+
+	mediaCodecs[0].PreferredPayloadType = 101
+
+	_, err = GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.IsType(t, NewTypeError(""), err)
+	assert.Contains(t, err.Error(), "duplicated preferredPayloadType 101")
+
+	mediaCodecs[0].PreferredPayloadType = 8
+
+	_, err = GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.Contains(t, err.Error(), "duplicated preferredPayloadType 8 [video/VP8, audio/PCMA]")
+}
+
+func TestTwoByteHeaderExtensions(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{{Kind: "video", MimeType: "video/VP8", ClockRate: 90000}}
+	twoByteExt := RtpHeaderExtension{Kind: "video", Uri: "urn:example:two-byte", PreferredId: 16}
+
+	_, err := GenerateRouterRtpCapabilities(mediaCodecs, WithCustomHeaderExtensions(twoByteExt))
+	assert.IsType(t, NewTypeError(""), err)
+
+	caps, err := GenerateRouterRtpCapabilities(mediaCodecs,
+		WithExtmapAllowMixed(), WithCustomHeaderExtensions(twoByteExt))
+	assert.NoError(t, err)
+	assert.True(t, caps.ExtmapAllowMixed)
+
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96}},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:example:two-byte", Id: 20},
+			{Uri: "urn:3gpp:video-orientation", Id: 15},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+	}
+
+	// Two-byte ids of the Producer are remapped to the Router ones.
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtpMappingHeaderExt{{Id: 20, MappedId: 16}, {Id: 15, MappedId: 4}},
+		rtpMapping.HeaderExtensions)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters("video", rtpParameters, caps, rtpMapping)
+	assert.NoError(t, err)
+	assert.True(t, consumableRtpParameters.ExtmapAllowMixed)
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(consumableRtpParameters, caps)
+	assert.NoError(t, err)
+	assert.True(t, consumerRtpParameters.ExtmapAllowMixed)
+	assert.Contains(t, consumerRtpParameters.HeaderExtensions, RtpHeaderExtension{Uri: "urn:example:two-byte", Id: 16})
+
+	// An endpoint not allowing mixed extensions only gets one-byte ones.
+	endpointCaps := caps
+	endpointCaps.ExtmapAllowMixed = false
+
+	consumerRtpParameters, err = GetConsumerRtpParameters(consumableRtpParameters, endpointCaps)
+	assert.NoError(t, err)
+	assert.False(t, consumerRtpParameters.ExtmapAllowMixed)
+	assert.NotContains(t, consumerRtpParameters.HeaderExtensions, RtpHeaderExtension{Uri: "urn:example:two-byte", Id: 16})
+	assert.NotEmpty(t, consumerRtpParameters.HeaderExtensions)
+
+	// A Router not allowing mixed extensions rejects two-byte ids.
+	caps, err = GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+
+	_, err = GetProducerRtpParametersMapping(rtpParameters, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+}
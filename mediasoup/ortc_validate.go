@@ -0,0 +1,246 @@
+package mediasoup
+
+// Ranges of RTP payload types and header extension ids (two-byte header).
+const (
+	maxPayloadType       = 127
+	maxHeaderExtensionId = 255
+	// Ids above it need two-byte header extensions (RFC 8285).
+	maxOneByteHeaderExtensionId = 14
+)
+
+/**
+ * Validate RTP capabilities given by an endpoint or a Router. The kind of
+ * codecs which don't have it is taken from their mimeType.
+ *
+ * @throws {TypeError} naming the first invalid field.
+ */
+func ValidateRtpCapabilities(caps *RtpCapabilities) error {
+	for i := range caps.Codecs {
+		if err := validateRtpCodecCapability(&caps.Codecs[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, ext := range caps.HeaderExtensions {
+		if err := validateRtpHeaderExtension(ext); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Validate RTP parameters given by an endpoint, as done by Produce(). The
+ * encodings are checked regardless of the media kind, see
+ * RtpEncoding.Validate() for the kind specific checks.
+ *
+ * @throws {TypeError} naming the first invalid field.
+ */
+func ValidateRtpParameters(params RtpParameters) error {
+	for _, codec := range params.Codecs {
+		if err := validateRtpCodecParameters(codec); err != nil {
+			return err
+		}
+	}
+
+	for _, ext := range params.HeaderExtensions {
+		if err := validateRtpHeaderExtensionParameters(ext); err != nil {
+			return err
+		}
+	}
+
+	for _, encoding := range params.Encodings {
+		if err := validateRtpEncodingParameters(encoding); err != nil {
+			return err
+		}
+	}
+
+	return params.Rtcp.Validate()
+}
+
+// ValidateSctpCapabilities validates the SCTP capabilities of an endpoint.
+func ValidateSctpCapabilities(caps SctpCapabilities) error {
+	return ValidateNumSctpStreams(caps.NumStreams)
+}
+
+// ValidateNumSctpStreams checks that both OS and MIS are given.
+func ValidateNumSctpStreams(numStreams NumSctpStreams) error {
+	if numStreams.OS == 0 {
+		return NewTypeError("missing numStreams.OS")
+	}
+	if numStreams.MIS == 0 {
+		return NewTypeError("missing numStreams.MIS")
+	}
+
+	return nil
+}
+
+/**
+ * Validate the SCTP parameters of a data stream. Partial reliability implies
+ * unordered delivery, so ordered is set to false if not given along with
+ * maxPacketLifeTime or maxRetransmits.
+ *
+ * @throws {TypeError} if both maxPacketLifeTime and maxRetransmits are given,
+ *   or any of them with ordered delivery.
+ */
+func ValidateSctpStreamParameters(params *SctpStreamParameters) error {
+	if params.MaxPacketLifeTime > 0 && params.MaxRetransmits > 0 {
+		return NewTypeError("cannot provide both maxPacketLifeTime and maxRetransmits")
+	}
+
+	if params.MaxPacketLifeTime > 0 || params.MaxRetransmits > 0 {
+		if params.Ordered != nil && *params.Ordered {
+			return NewTypeError("cannot be ordered with maxPacketLifeTime or maxRetransmits")
+		}
+		params.Ordered = newBool(false)
+	}
+
+	return nil
+}
+
+// validateRtpCodecCapability validates a codec of RTP capabilities and sets
+// its kind if missing.
+func validateRtpCodecCapability(codec *RtpCodecCapability) (err error) {
+	if err = validateCodecMimeType(codec.MimeType); err != nil {
+		return
+	}
+
+	mimeKind := MediaKind(ParseMimeType(codec.MimeType).Kind())
+
+	if len(codec.Kind) == 0 {
+		codec.Kind = mimeKind
+	} else if codec.Kind != mimeKind {
+		return NewTypeError("codec.kind does not match codec.mimeType [kind:%s, mimeType:%s]",
+			codec.Kind, codec.MimeType)
+	}
+
+	if codec.PreferredPayloadType < 0 || codec.PreferredPayloadType > maxPayloadType {
+		return NewTypeError("invalid codec.preferredPayloadType [mimeType:%s, preferredPayloadType:%d]",
+			codec.MimeType, codec.PreferredPayloadType)
+	}
+
+	return validateCodecCommon(*codec)
+}
+
+// validateRtpCodecParameters validates a codec of RTP parameters.
+func validateRtpCodecParameters(codec RtpCodecCapability) (err error) {
+	if err = validateCodecMimeType(codec.MimeType); err != nil {
+		return
+	}
+
+	if codec.PayloadType < 0 || codec.PayloadType > maxPayloadType {
+		return NewTypeError("invalid codec.payloadType [mimeType:%s, payloadType:%d]",
+			codec.MimeType, codec.PayloadType)
+	}
+
+	return validateCodecCommon(codec)
+}
+
+func validateCodecMimeType(mimeType string) error {
+	parsed := ParseMimeType(mimeType)
+
+	if len(parsed.Subtype()) == 0 || !MediaKind(parsed.Kind()).IsRtp() {
+		return NewTypeError("invalid codec.mimeType [mimeType:%s]", mimeType)
+	}
+
+	return nil
+}
+
+// validateCodecCommon checks the fields shared by codec capabilities and
+// parameters.
+func validateCodecCommon(codec RtpCodecCapability) error {
+	if codec.ClockRate <= 0 {
+		return NewTypeError("missing codec.clockRate [mimeType:%s]", codec.MimeType)
+	}
+	if codec.Channels < 0 {
+		return NewTypeError("invalid codec.channels [mimeType:%s, channels:%d]",
+			codec.MimeType, codec.Channels)
+	}
+
+	for _, fb := range codec.RtcpFeedback {
+		if err := validateRtcpFeedback(fb); err != nil {
+			return err
+		}
+	}
+
+	return checkAv1Parameters(codec)
+}
+
+func validateRtcpFeedback(fb RtcpFeedback) error {
+	if len(fb.Type) == 0 {
+		return NewTypeError("missing rtcpFeedback.type")
+	}
+
+	return nil
+}
+
+// validateRtpHeaderExtension validates a header extension of RTP
+// capabilities.
+func validateRtpHeaderExtension(ext RtpHeaderExtension) error {
+	if !ext.Kind.IsRtp() {
+		return NewTypeError("invalid ext.kind [uri:%s, kind:%s]", ext.Uri, ext.Kind)
+	}
+	if len(ext.Uri) == 0 {
+		return NewTypeError("missing ext.uri")
+	}
+	if ext.PreferredId < 1 || ext.PreferredId > maxHeaderExtensionId {
+		return NewTypeError("invalid ext.preferredId [uri:%s, preferredId:%d]", ext.Uri, ext.PreferredId)
+	}
+
+	switch ext.Direction {
+	case "", HeaderExtensionDirectionSendRecv, HeaderExtensionDirectionSendOnly,
+		HeaderExtensionDirectionRecvOnly, HeaderExtensionDirectionInactive:
+	default:
+		return NewTypeError("invalid ext.direction [uri:%s, direction:%s]", ext.Uri, ext.Direction)
+	}
+
+	return nil
+}
+
+// validateRtpHeaderExtensionParameters validates a header extension of RTP
+// parameters.
+func validateRtpHeaderExtensionParameters(ext RtpHeaderExtension) error {
+	if len(ext.Uri) == 0 {
+		return NewTypeError("missing ext.uri")
+	}
+	if ext.Id < 1 || ext.Id > maxHeaderExtensionId {
+		return NewTypeError("invalid ext.id [uri:%s, id:%d]", ext.Uri, ext.Id)
+	}
+
+	return nil
+}
+
+func validateRtpEncodingParameters(encoding RtpEncoding) error {
+	if encoding.Rtx != nil && encoding.Rtx.Ssrc == 0 {
+		return NewTypeError("missing encoding.rtx.ssrc [ssrc:%d, rid:%s]", encoding.Ssrc, encoding.Rid)
+	}
+	if encoding.CodecPayloadType > maxPayloadType {
+		return NewTypeError("invalid encoding.codecPayloadType [codecPayloadType:%d]",
+			encoding.CodecPayloadType)
+	}
+	if len(encoding.ScalabilityMode) > 0 && !scalabilityModeRegex.MatchString(encoding.ScalabilityMode) {
+		return NewTypeError("invalid encoding.scalabilityMode [scalabilityMode:%s]",
+			encoding.ScalabilityMode)
+	}
+
+	return nil
+}
+
+// IsTwoByteHeaderExtensionId tells whether the header extension id needs the
+// two-byte header extension format (RFC 8285), i.e. is above 14.
+func IsTwoByteHeaderExtensionId(id int) bool {
+	return id > maxOneByteHeaderExtensionId
+}
+
+// hasTwoByteHeaderExtension tells whether some header extension of the RTP
+// parameters needs the two-byte format.
+func hasTwoByteHeaderExtension(exts []RtpHeaderExtension) bool {
+	for _, ext := range exts {
+		if IsTwoByteHeaderExtensionId(ext.Id) {
+			return true
+		}
+	}
+
+	return false
+}
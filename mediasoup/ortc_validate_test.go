@@ -0,0 +1,94 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRtpCapabilities(t *testing.T) {
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PreferredPayloadType: 100},
+			{MimeType: "video/VP8", ClockRate: 90000, RtcpFeedback: []RtcpFeedback{{Type: "nack"}}},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+		},
+	}
+
+	assert.NoError(t, ValidateRtpCapabilities(&caps))
+	assert.Equal(t, MediaKindAudio, caps.Codecs[0].Kind)
+	assert.Equal(t, MediaKindVideo, caps.Codecs[1].Kind)
+
+	invalid := []RtpCapabilities{
+		{Codecs: []RtpCodecCapability{{MimeType: "opus", ClockRate: 48000}}},
+		{Codecs: []RtpCodecCapability{{MimeType: "application/opus", ClockRate: 48000}}},
+		{Codecs: []RtpCodecCapability{{Kind: "video", MimeType: "audio/opus", ClockRate: 48000}}},
+		{Codecs: []RtpCodecCapability{{MimeType: "audio/opus"}}},
+		{Codecs: []RtpCodecCapability{{MimeType: "audio/opus", ClockRate: 48000, PreferredPayloadType: 128}}},
+		{Codecs: []RtpCodecCapability{{MimeType: "video/VP8", ClockRate: 90000, RtcpFeedback: []RtcpFeedback{{Parameter: "pli"}}}}},
+		{HeaderExtensions: []RtpHeaderExtension{{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1}}},
+		{HeaderExtensions: []RtpHeaderExtension{{Kind: "audio", PreferredId: 1}}},
+		{HeaderExtensions: []RtpHeaderExtension{{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid"}}},
+	}
+
+	for i := range invalid {
+		assert.IsType(t, NewTypeError(""), ValidateRtpCapabilities(&invalid[i]), "case %d", i)
+	}
+}
+
+func TestValidateRtpParameters(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "audio/PCMU", ClockRate: 8000, PayloadType: 0},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111, Rtx: &RtpEncoding{Ssrc: 2222}, ScalabilityMode: "L1T3"}},
+		Rtcp:      RtcpParameters{Cname: "FOOBAR"},
+	}
+
+	assert.NoError(t, ValidateRtpParameters(params))
+
+	invalid := []func(params *RtpParameters){
+		func(params *RtpParameters) { params.Codecs[0].PayloadType = 128 },
+		func(params *RtpParameters) { params.Codecs[0].ClockRate = 0 },
+		func(params *RtpParameters) { params.HeaderExtensions[0].Id = 0 },
+		func(params *RtpParameters) { params.HeaderExtensions[0].Uri = "" },
+		func(params *RtpParameters) { params.Encodings[0].Rtx = &RtpEncoding{} },
+		func(params *RtpParameters) { params.Encodings[0].ScalabilityMode = "foo" },
+		func(params *RtpParameters) { params.Rtcp.Cname = "\n" },
+	}
+
+	for i, modify := range invalid {
+		params := RtpParameters{
+			Codecs:           []RtpCodecCapability{{MimeType: "audio/PCMU", ClockRate: 8000}},
+			HeaderExtensions: []RtpHeaderExtension{{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1}},
+			Encodings:        []RtpEncoding{{Ssrc: 1111}},
+		}
+		modify(&params)
+
+		assert.IsType(t, NewTypeError(""), ValidateRtpParameters(params), "case %d", i)
+	}
+}
+
+func TestValidateSctpParameters(t *testing.T) {
+	assert.NoError(t, ValidateSctpCapabilities(SctpCapabilities{NumStreams: NumSctpStreams{OS: 1024, MIS: 1024}}))
+	assert.IsType(t, NewTypeError(""), ValidateSctpCapabilities(SctpCapabilities{NumStreams: NumSctpStreams{MIS: 1024}}))
+	assert.IsType(t, NewTypeError(""), ValidateNumSctpStreams(NumSctpStreams{OS: 1024}))
+
+	params := SctpStreamParameters{StreamId: 1, MaxRetransmits: 3}
+
+	assert.NoError(t, ValidateSctpStreamParameters(&params))
+	assert.Equal(t, newBool(false), params.Ordered)
+
+	params = SctpStreamParameters{StreamId: 1, Ordered: newBool(true), MaxPacketLifeTime: 100}
+
+	assert.IsType(t, NewTypeError(""), ValidateSctpStreamParameters(&params))
+
+	params = SctpStreamParameters{StreamId: 1, MaxPacketLifeTime: 100, MaxRetransmits: 3}
+
+	assert.IsType(t, NewTypeError(""), ValidateSctpStreamParameters(&params))
+}
@@ -0,0 +1,289 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/netstring"
+	"github.com/sirupsen/logrus"
+)
+
+/**
+ * PayloadChannel carries messages with a binary payload between Go and the
+ * worker (fds 5 and 6), e.g. RTP packets of DirectTransport Consumers or
+ * DataConsumer messages. Each request and notification is a JSON netstring
+ * followed by a netstring with the payload. Responses are JSON netstrings as
+ * in the Channel.
+ *
+ * Notifications from the worker are emitted with the target id as the event
+ * name and (event string, data json.RawMessage, payload []byte) arguments, in
+ * order, from the goroutine reading the worker messages: the order of RTP
+ * packets and DataChannel messages is kept, and listeners must not wait for a
+ * response of the PayloadChannel.
+ */
+type PayloadChannel struct {
+	EventEmitter
+	// Guards closed, nextId and sents.
+	locker   sync.Mutex
+	socket   net.Conn
+	logger   logrus.FieldLogger
+	closed   bool
+	nextId   int64
+	sents    map[int64]sentInfo
+	closeCh  chan struct{}
+	timeouts RequestTimeouts
+	// Notification waiting for its payload.
+	ongoingNotification *payloadChannelNotification
+}
+
+type payloadChannelNotification struct {
+	TargetId string
+	Event    string
+	Data     json.RawMessage
+}
+
+func NewPayloadChannel(socket net.Conn, pid int) *PayloadChannel {
+	logger := TypeLogger(fmt.Sprintf("PayloadChannel[pid:%d]", pid))
+
+	channel := &PayloadChannel{
+		EventEmitter: NewEventEmitter(logger),
+		socket:       socket,
+		logger:       logger,
+		sents:        make(map[int64]sentInfo),
+		closeCh:      make(chan struct{}),
+		timeouts:     DefaultRequestTimeouts(),
+	}
+
+	go channel.runReadLoop()
+
+	logger.Debugln("constructor()")
+
+	return channel
+}
+
+func (c *PayloadChannel) Close() {
+	c.locker.Lock()
+	if c.closed {
+		c.locker.Unlock()
+		return
+	}
+	c.closed = true
+	c.locker.Unlock()
+
+	c.logger.Debugln("close()")
+
+	c.socket.Close()
+}
+
+// Closed tells whether the PayloadChannel is closed.
+func (c *PayloadChannel) Closed() bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.closed
+}
+
+// Notify sends a notification with its payload to the worker.
+func (c *PayloadChannel) Notify(event string, internal, data interface{}, payload []byte) error {
+	c.logger.Debugf("notify() [event:%s%s]", event, traceSuffix(internal))
+
+	if c.Closed() {
+		return NewInvalidStateError("PayloadChannel closed")
+	}
+
+	ns, err := encodeNotification(event, internal, data)
+	if err != nil {
+		return err
+	}
+
+	return c.write(ns, payload)
+}
+
+// Request sends a request with its payload to the worker and waits for the
+// response.
+func (c *PayloadChannel) Request(method string, internal, data interface{}, payload []byte) (rsp Response) {
+	c.locker.Lock()
+	id := c.newRequestId()
+	c.locker.Unlock()
+
+	trace := traceSuffix(internal)
+
+	c.logger.Debugf("request() [method:%s, id:%d%s]", method, id, trace)
+
+	if c.Closed() {
+		rsp.err = NewInvalidStateError("PayloadChannel closed")
+		return
+	}
+
+	ns, err := encodeRequest(id, method, internal, data)
+	if err != nil {
+		rsp.err = err
+		return
+	}
+
+	sent := sentInfo{
+		id:         id,
+		method:     method,
+		trace:      trace,
+		responseCh: make(chan Response, 1),
+	}
+
+	c.locker.Lock()
+	c.sents[id] = sent
+	pending := len(c.sents)
+	c.locker.Unlock()
+
+	defer func() {
+		c.locker.Lock()
+		delete(c.sents, id)
+		c.locker.Unlock()
+	}()
+
+	if rsp.err = c.write(ns, payload); rsp.err != nil {
+		return
+	}
+
+	timer := time.NewTimer(c.timeouts.Timeout(method, pending))
+	defer timer.Stop()
+
+	select {
+	case rsp = <-sent.responseCh:
+	case <-timer.C:
+		rsp.err = fmt.Errorf("PayloadChannel request timeout [method:%s, id:%d%s]", method, id, trace)
+	case <-c.closeCh:
+		rsp.err = errors.New("PayloadChannel closed")
+	}
+
+	return
+}
+
+// write sends the message and its payload at once, so they can't be
+// interleaved with another message.
+func (c *PayloadChannel) write(ns, payload []byte) error {
+	payloadNs := netstring.Encode(payload)
+
+	if len(payloadNs) > NS_MESSAGE_MAX_LEN {
+		return errors.New("PayloadChannel payload too big")
+	}
+
+	_, err := c.socket.Write(append(ns, payloadNs...))
+
+	return err
+}
+
+func (c *PayloadChannel) newRequestId() int64 {
+	if c.nextId < 4294967295 {
+		c.nextId++
+	} else {
+		c.nextId = 1
+	}
+
+	return c.nextId
+}
+
+func (c *PayloadChannel) runReadLoop() {
+	decoder := netstring.NewDecoder()
+
+	go func() {
+		for {
+			select {
+			case nsPayload := <-decoder.Result():
+				c.processNSPayload(nsPayload)
+			case <-c.closeCh:
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, NS_PAYLOAD_MAX_LEN)
+
+	for {
+		n, err := c.socket.Read(buf)
+		if err != nil {
+			c.logger.Errorf("PayloadChannel error: %s", err)
+			break
+		}
+
+		decoder.Feed(buf[:n])
+
+		if decoder.Length() > NS_PAYLOAD_MAX_LEN {
+			c.logger.Errorln("receiving buffer is full, discarding all data into it")
+			decoder.Reset()
+		}
+	}
+
+	c.locker.Lock()
+	c.closed = true
+	c.locker.Unlock()
+
+	close(c.closeCh)
+}
+
+func (c *PayloadChannel) processNSPayload(nsPayload []byte) {
+	// The payload of the previous notification, whatever its first byte.
+	if notification := c.ongoingNotification; notification != nil {
+		c.ongoingNotification = nil
+
+		payload := append([]byte(nil), nsPayload...)
+
+		c.SafeEmit(notification.TargetId, notification.Event, notification.Data, payload)
+
+		return
+	}
+
+	if len(nsPayload) == 0 || nsPayload[0] != '{' {
+		c.logger.Errorf("unexpected data: %s", nsPayload)
+		return
+	}
+
+	var msg struct {
+		Id       int64
+		Accepted bool
+		Error    string
+		Reason   string
+		TargetId string
+		Event    string
+		Data     json.RawMessage
+	}
+	if err := json.Unmarshal(nsPayload, &msg); err != nil {
+		c.logger.Errorf("received invalid message: %s", err)
+		return
+	}
+
+	switch {
+	case msg.Id > 0:
+		c.locker.Lock()
+		sent, ok := c.sents[msg.Id]
+		c.locker.Unlock()
+
+		if !ok {
+			c.logger.Errorf("received response does not match any sent request [id:%d]", msg.Id)
+			return
+		}
+
+		if msg.Accepted {
+			c.logger.Debugf("request succeeded [method:%s, id:%d%s]", sent.method, sent.id, sent.trace)
+
+			sent.responseCh <- Response{data: msg.Data}
+		} else {
+			c.logger.Warnf("request failed [method:%s, id:%d%s]: %s",
+				sent.method, sent.id, sent.trace, msg.Reason)
+
+			sent.responseCh <- Response{err: NewTypeError(msg.Reason)}
+		}
+
+	case len(msg.TargetId) > 0:
+		c.ongoingNotification = &payloadChannelNotification{
+			TargetId: msg.TargetId,
+			Event:    msg.Event,
+			Data:     msg.Data,
+		}
+
+	default:
+		c.logger.Errorln("received message is not a response nor a notification")
+	}
+}
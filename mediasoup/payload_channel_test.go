@@ -0,0 +1,91 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/netstring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadChannel(t *testing.T) {
+	socket, workerSocket := net.Pipe()
+	channel := NewPayloadChannel(socket, 0)
+	defer channel.Close()
+
+	messages := make(chan []byte, 4)
+
+	go func() {
+		decoder := netstring.NewDecoder()
+		buf := make([]byte, NS_PAYLOAD_MAX_LEN)
+
+		go func() {
+			for payload := range decoder.Result() {
+				messages <- append([]byte(nil), payload...)
+			}
+		}()
+
+		for {
+			n, err := workerSocket.Read(buf)
+			if err != nil {
+				return
+			}
+			decoder.Feed(buf[:n])
+		}
+	}()
+
+	// Notification with its payload.
+	err := channel.Notify("dataConsumer.send", internalData{ConsumerId: "c1"}, H{"ppid": 53}, []byte("hello"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"event":"dataConsumer.send","internal":{"consumerId":"c1"},"data":{"ppid":53}}`,
+		string(<-messages))
+	assert.Equal(t, "hello", string(<-messages))
+
+	// Request answered by the worker.
+	go func() {
+		<-messages
+		<-messages
+		workerSocket.Write(netstring.Encode([]byte(`{"id":1,"accepted":true,"data":{"sent":true}}`)))
+	}()
+
+	var result struct{ Sent bool }
+
+	assert.NoError(t, channel.Request("transport.sendRtcp", nil, nil, []byte{0x80}).Unmarshal(&result))
+	assert.True(t, result.Sent)
+
+	// Notification from the worker followed by its payload.
+	received := make(chan []byte, 1)
+
+	channel.On("c1", func(event string, data json.RawMessage, payload []byte) {
+		assert.Equal(t, "rtp", event)
+		received <- payload
+	})
+
+	workerSocket.Write(append(
+		netstring.Encode([]byte(`{"targetId":"c1","event":"rtp"}`)),
+		netstring.Encode([]byte{0x80, 0x60})...))
+
+	assert.Equal(t, []byte{0x80, 0x60}, <-received)
+
+	// Notifications are emitted in order.
+	const count = 50
+	ordered := make(chan byte, count)
+
+	channel.On("c2", func(event string, data json.RawMessage, payload []byte) {
+		ordered <- payload[0]
+	})
+
+	for i := 0; i < count; i++ {
+		workerSocket.Write(append(
+			netstring.Encode([]byte(`{"targetId":"c2","event":"rtp"}`)),
+			netstring.Encode([]byte{byte(i)})...))
+	}
+	for i := 0; i < count; i++ {
+		assert.Equal(t, byte(i), <-ordered)
+	}
+
+	channel.Close()
+	assert.True(t, channel.Closed())
+	assert.IsType(t, NewInvalidStateError(""), channel.Notify("dataConsumer.send", nil, nil, nil))
+}
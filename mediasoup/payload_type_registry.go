@@ -0,0 +1,43 @@
+package mediasoup
+
+import "errors"
+
+// payloadTypeRegistry tracks the payload types of the codecs of RTP
+// capabilities, so a preferred payload type given by the user or a static one
+// (e.g. PCMA 8) is never given to another codec or RTX codec.
+type payloadTypeRegistry struct {
+	owners  map[int]string
+	dynamic []int
+}
+
+func newPayloadTypeRegistry(dynamic []int) *payloadTypeRegistry {
+	return &payloadTypeRegistry{
+		owners:  map[int]string{},
+		dynamic: dynamic,
+	}
+}
+
+// reserve assigns pt to owner, failing if already used by another codec.
+func (r *payloadTypeRegistry) reserve(pt int, owner string) error {
+	if other, ok := r.owners[pt]; ok {
+		return NewTypeError("duplicated preferredPayloadType %d [%s, %s]", pt, other, owner)
+	}
+	r.owners[pt] = owner
+
+	return nil
+}
+
+// allocate assigns the next unused dynamic payload type to owner.
+func (r *payloadTypeRegistry) allocate(owner string) (int, error) {
+	for len(r.dynamic) > 0 {
+		pt := r.dynamic[0]
+		r.dynamic = r.dynamic[1:]
+
+		if _, ok := r.owners[pt]; !ok {
+			r.owners[pt] = owner
+			return pt, nil
+		}
+	}
+
+	return 0, errors.New("cannot allocate more dynamic codec payload types")
+}
@@ -3,7 +3,6 @@ package mediasoup
 import (
 	"fmt"
 
-	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,17 +30,36 @@ func (t PipeTransport) Tuple() TransportTuple {
 	return t.data.Tuple
 }
 
+// Local SRTP parameters, nil if SRTP is not enabled.
+func (t PipeTransport) SrtpParameters() *SrtpParameters {
+	return t.data.SrtpParameters
+}
+
 /**
  * Provide the PipeTransport remote parameters.
  *
  * @param {String} ip - Remote IP.
  * @param {Number} port - Remote port.
+ * @param {SrtpParameters} [srtpParameters] - Remote SRTP parameters, required
+ *   if SRTP is enabled. The worker accepts a single connect(), so they are
+ *   fixed for the lifetime of the PipeTransport.
  *
  * @override
  */
 func (t *PipeTransport) Connect(params transportConnectParams) (err error) {
 	t.logger.Debug("connect()")
 
+	if t.SrtpParameters() != nil {
+		if params.SrtpParameters == nil {
+			return NewTypeError("missing srtpParameters (SRTP enabled)")
+		}
+		if err = params.SrtpParameters.Validate(); err != nil {
+			return
+		}
+	} else if params.SrtpParameters != nil {
+		return NewTypeError("srtpParameters given but SRTP is not enabled")
+	}
+
 	resp := t.channel.Request("transport.connect", t.internal, params)
 
 	return resp.Unmarshal(&t.data)
@@ -62,6 +80,47 @@ func (transport *PipeTransport) Produce(params transportProduceParams) (producer
 	return transport.baseTransport.Produce(params)
 }
 
+/**
+ * Create a Producer for a stream sent by a remote which is not a mediasoup
+ * Router (e.g. another SFU speaking plain RTP or SRTP), from RTP parameters
+ * known beforehand instead of those of a pipe Consumer. There is no MID or RID
+ * signaling on a PipeTransport, so every encoding must have its SSRC. The
+ * mapping to the Router capabilities is generated as for any Producer.
+ *
+ * @param [id] - Producer id, e.g. the id of the stream in the remote.
+ * @param kind - "audio"/"video".
+ * @param rtpParameters - RTP parameters of the remote stream.
+ * @param [paused=false] - Whether the Producer must start paused.
+ * @param [appData={}] - Custom app data.
+ */
+func (transport *PipeTransport) ImportProducer(params ImportPipeProducerParams) (producer *Producer, err error) {
+	transport.logger.Debug("importProducer()")
+
+	rtpParameters := params.RtpParameters
+
+	if len(rtpParameters.Encodings) == 0 {
+		err = NewTypeError("missing encodings")
+		return
+	}
+
+	for i, encoding := range rtpParameters.Encodings {
+		if encoding.Ssrc == 0 {
+			err = NewTypeError("missing ssrc in encoding %d", i)
+			return
+		}
+	}
+
+	rtpParameters.Mid = ""
+
+	return transport.Produce(transportProduceParams{
+		Id:            params.Id,
+		Kind:          params.Kind,
+		RtpParameters: rtpParameters,
+		Paused:        params.Paused,
+		AppData:       params.AppData,
+	})
+}
+
 /**
  * Create a pipe Consumer.
  *
@@ -73,6 +132,10 @@ func (transport *PipeTransport) Produce(params transportProduceParams) (producer
 func (t *PipeTransport) Consume(params transportConsumeParams) (consumer *Consumer, err error) {
 	t.logger.Debug("consume()")
 
+	if err = t.checkRouterClosing(); err != nil {
+		return
+	}
+
 	producerId, appData := params.ProducerId, params.AppData
 
 	if appData == nil {
@@ -87,12 +150,18 @@ func (t *PipeTransport) Consume(params transportConsumeParams) (consumer *Consum
 
 	if producer == nil {
 		err = fmt.Errorf(`Producer with id "%s" not found`, producerId)
+		return
+	}
+
+	protectedAudit, err := t.authorizeProtectedConsume(producer, true, params)
+	if err != nil {
+		return
 	}
 
 	rtpParameters := GetPipeConsumerRtpParameters(producer.ConsumableRtpParameters())
 
 	internal := t.internal
-	internal.ConsumerId = uuid.NewV4().String()
+	internal.ConsumerId = t.newId(IdEntityConsumer)
 	internal.ProducerId = producerId
 
 	reqData := H{
@@ -128,16 +197,15 @@ func (t *PipeTransport) Consume(params transportConsumeParams) (consumer *Consum
 		nil,
 	)
 
-	t.consumers[consumer.Id()] = consumer
-	consumer.On("@close", func() {
-		delete(t.consumers, consumer.Id())
-	})
-	consumer.On("@producerclose", func() {
-		delete(t.consumers, consumer.Id())
-	})
+	t.addConsumer(consumer)
 
 	// Emit observer event.
 	t.observer.SafeEmit("newconsumer", consumer)
 
+	if protectedAudit != nil {
+		protectedAudit.ConsumerId = consumer.Id()
+		t.emitProtectedConsume(producer, *protectedAudit)
+	}
+
 	return
 }
@@ -264,7 +264,7 @@ func TestRouterPipeToRouter_SucceedsWithAudio(t *testing.T) {
 
 	assert.Equal(t, pipeProducer.Id(), ns.audioProducer.Id())
 	assert.False(t, pipeProducer.Closed())
-	assert.Equal(t, pipeProducer.Kind(), "audio")
+	assert.Equal(t, pipeProducer.Kind(), MediaKindAudio)
 	assert.NotNil(t, pipeProducer.RtpParameters())
 	assert.Zero(t, pipeProducer.RtpParameters().Mid)
 	assertJSONEq(t, pipeProducer.RtpParameters().Codecs, []RtpCodecCapability{
@@ -319,7 +319,7 @@ func TestRouterPipeToRouter_SucceedsWithVideo(t *testing.T) {
 
 	assert.NotEmpty(t, pipeConsumer.Id())
 	assert.False(t, pipeConsumer.Closed())
-	assert.Equal(t, pipeConsumer.Kind(), "video")
+	assert.Equal(t, pipeConsumer.Kind(), MediaKindVideo)
 	assert.NotNil(t, pipeConsumer.RtpParameters())
 	assert.Zero(t, pipeConsumer.RtpParameters().Mid)
 	assertJSONEq(t, pipeConsumer.RtpParameters().Codecs, []RtpCodecCapability{
@@ -356,7 +356,7 @@ func TestRouterPipeToRouter_SucceedsWithVideo(t *testing.T) {
 
 	assert.Equal(t, pipeProducer.Id(), ns.videoProducer.Id())
 	assert.False(t, pipeProducer.Closed())
-	assert.Equal(t, pipeProducer.Kind(), "video")
+	assert.Equal(t, pipeProducer.Kind(), MediaKindVideo)
 	assert.NotNil(t, pipeProducer.RtpParameters())
 	assert.Zero(t, pipeProducer.RtpParameters().Mid)
 	assertJSONEq(t, pipeProducer.RtpParameters().Codecs, []RtpCodecCapability{
@@ -404,7 +404,7 @@ func TestTransportConsume_ForAPipeProducerSucceeds(t *testing.T) {
 
 	assert.NotEmpty(t, videoConsumer.Id())
 	assert.False(t, videoConsumer.Closed())
-	assert.Equal(t, videoConsumer.Kind(), "video")
+	assert.Equal(t, videoConsumer.Kind(), MediaKindVideo)
 	assert.NotNil(t, videoConsumer.RtpParameters())
 	assert.Zero(t, videoConsumer.RtpParameters().Mid)
 	assertJSONEq(t, videoConsumer.RtpParameters().Codecs, []RtpCodecCapability{
@@ -521,3 +521,55 @@ func TestProducerCloseIsTransmittedToPipeConsumer(t *testing.T) {
 
 	assert.True(t, videoConsumer.Closed())
 }
+
+func TestPipeTransportConnect_SrtpTypeError(t *testing.T) {
+	srtpParameters, err := NewSrtpParameters(SrtpCryptoSuiteAesCm128HmacSha1_80)
+	assert.NoError(t, err)
+
+	transport := NewPipeTransport(PipeTransportData{}, createTransportParams{
+		Internal: internalData{TransportId: "t1"},
+		Channel:  newTestChannel(),
+	})
+
+	// SRTP not enabled.
+	err = transport.Connect(transportConnectParams{SrtpParameters: &srtpParameters})
+	assert.IsType(t, NewTypeError(""), err)
+
+	transport.data.SrtpParameters = &srtpParameters
+
+	err = transport.Connect(transportConnectParams{Ip: "127.0.0.1", Port: 9999})
+	assert.IsType(t, NewTypeError(""), err)
+
+	err = transport.Connect(transportConnectParams{Ip: "127.0.0.1", Port: 9999, SrtpParameters: &srtpParameters})
+	assert.NoError(t, err)
+}
+
+func TestRouterCreatePipeTransport_InvalidSrtpCryptoSuite(t *testing.T) {
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	_, err := router.CreatePipeTransport(CreatePipeTransportParams{
+		ListenIp:        ListenIp{Ip: "127.0.0.1"},
+		EnableSrtp:      true,
+		SrtpCryptoSuite: "FOO",
+	})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestPipeTransportImportProducer_MissingSsrc(t *testing.T) {
+	transport := NewPipeTransport(PipeTransportData{}, createTransportParams{
+		Internal: internalData{TransportId: "t1"},
+		Channel:  newTestChannel(),
+	})
+
+	_, err := transport.ImportProducer(ImportPipeProducerParams{
+		Kind: MediaKindAudio,
+		RtpParameters: RtpParameters{
+			Codecs:    []RtpCodecCapability{{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PayloadType: 100}},
+			Encodings: []RtpEncoding{{Rid: "r0"}},
+		},
+	})
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = transport.ImportProducer(ImportPipeProducerParams{Kind: MediaKindAudio})
+	assert.IsType(t, NewTypeError(""), err)
+}
@@ -1,51 +1,125 @@
 package mediasoup
 
 import (
+	"encoding/json"
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
 	"github.com/sirupsen/logrus"
 )
 
 var _ Transport = (*PlainRtpTransport)(nil)
 
+// States of a comedia PlainRtpTransport learning its remote tuple.
+const (
+	ComediaStateNew       = "new"
+	ComediaStateWaiting   = "waiting"
+	ComediaStateConnected = "connected"
+	ComediaStateFailed    = "failed"
+)
+
+type ComediaWaitOptions struct {
+	// Timeout of every attempt, default 5s.
+	Timeout time.Duration
+	// MaxRetries after the first attempt timed out.
+	MaxRetries int
+	// OnRetry is called before every retry, e.g. to restart the external
+	// sender. Returning an error stops waiting.
+	OnRetry func(attempt int) error
+	// Clock timing the attempts, default clock.System.
+	Clock clock.Clock
+}
+
 type PlainRtpTransport struct {
 	*baseTransport
-	logger logrus.FieldLogger
-	data   PlainTransportData
+	logger       logrus.FieldLogger
+	data         PlainTransportData
+	locker       sync.Mutex
+	comediaState string
+	// Closed once the remote tuple is known, under locker.
+	tupleCh chan struct{}
 }
 
+/**
+ * New PlainRtpTransport.
+ *
+ * @emits {TransportTuple} tuple
+ * @emits {TransportTuple} rtcptuple
+ * @emits {string} comediastatechange
+ */
 func NewPlainRtpTransport(data PlainTransportData, params createTransportParams) *PlainRtpTransport {
 	logger := TypeLogger("PlainRtpTransport")
 
 	logger.Debug("constructor()")
 
-	return &PlainRtpTransport{
+	t := &PlainRtpTransport{
 		baseTransport: newTransport(params),
 		logger:        logger,
 		data:          data,
+		comediaState:  ComediaStateNew,
+		tupleCh:       make(chan struct{}),
+	}
+
+	if len(data.Tuple.RemoteIp) > 0 {
+		t.comediaState = ComediaStateConnected
+		close(t.tupleCh)
 	}
+
+	t.handleWorkerNotifications()
+
+	return t
 }
 
-func (t PlainRtpTransport) Tuple() TransportTuple {
+func (t *PlainRtpTransport) Tuple() TransportTuple {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
 	return t.data.Tuple
 }
 
-func (t PlainRtpTransport) RtcpTuple() *TransportTuple {
+func (t *PlainRtpTransport) RtcpTuple() *TransportTuple {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
 	return t.data.RtcpTuple
 }
 
+// Local SRTP parameters, nil if SRTP is not enabled.
+func (t *PlainRtpTransport) SrtpParameters() *SrtpParameters {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	return t.data.SrtpParameters
+}
+
 /**
  * Provide the PlainRtpTransport remote parameters.
  *
  * @param {String} ip - Remote IP.
  * @param {Number} port - Remote port.
  * @param {Number} [rtcpPort] - Remote RTCP port (ignored if rtcpMux was true).
+ * @param {SrtpParameters} [srtpParameters] - Remote SRTP parameters, required
+ *   if SRTP is enabled. They cannot be changed by connecting again, see
+ *   SrtpParameters.
  *
  * @override
  */
 func (t *PlainRtpTransport) Connect(params transportConnectParams) (err error) {
 	t.logger.Debug("connect()")
 
+	if t.SrtpParameters() != nil {
+		if params.SrtpParameters == nil {
+			return NewTypeError("missing srtpParameters (SRTP enabled)")
+		}
+		if err = params.SrtpParameters.Validate(); err != nil {
+			return
+		}
+	} else if params.SrtpParameters != nil {
+		return NewTypeError("srtpParameters given but SRTP is not enabled")
+	}
+
 	resp := t.channel.Request("transport.connect", t.internal, params)
 
 	// Update data.
@@ -65,3 +139,138 @@ func (t *PlainRtpTransport) Consume(params transportConsumeParams) (*Consumer, e
 
 	return t.baseTransport.Consume(params)
 }
+
+// State of the remote tuple detection of a comedia transport.
+func (t *PlainRtpTransport) ComediaState() string {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	return t.comediaState
+}
+
+/**
+ * Wait until a comedia PlainRtpTransport learns the remote tuple from the
+ * first RTP packet received, so ingest orchestration knows the external sender
+ * is connected. Every attempt times out after options.Timeout and
+ * options.OnRetry is called before the next one.
+ */
+func (t *PlainRtpTransport) WaitForTuple(options ComediaWaitOptions) (tuple TransportTuple, err error) {
+	t.logger.Debug("waitForTuple()")
+
+	if !t.data.Comedia {
+		err = NewInvalidStateError("comedia is not enabled")
+		return
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 5 * time.Second
+	}
+
+	clk := clock.OrSystem(options.Clock)
+
+	for attempt := 0; ; attempt++ {
+		if t.Closed() {
+			err = NewInvalidStateError("PlainRtpTransport closed")
+			return
+		}
+
+		t.setComediaState(ComediaStateWaiting)
+
+		timer := clk.NewTimer(options.Timeout)
+
+		select {
+		case <-t.tupleCh:
+			timer.Stop()
+
+			return t.Tuple(), nil
+
+		case <-timer.C():
+		}
+
+		if attempt >= options.MaxRetries {
+			break
+		}
+
+		t.logger.Warnf("remote tuple not learned yet, retrying [attempt:%d]", attempt+1)
+
+		if options.OnRetry != nil {
+			if err = options.OnRetry(attempt + 1); err != nil {
+				break
+			}
+		}
+	}
+
+	t.setComediaState(ComediaStateFailed)
+
+	if err == nil {
+		err = NewTimeoutError("remote tuple not learned")
+	}
+
+	return
+}
+
+func (t *PlainRtpTransport) setComediaState(state string) {
+	t.locker.Lock()
+
+	if t.comediaState == state || t.comediaState == ComediaStateConnected {
+		t.locker.Unlock()
+		return
+	}
+
+	t.comediaState = state
+	t.locker.Unlock()
+
+	t.SafeEmit("comediastatechange", state)
+
+	// Emit observer event.
+	t.observer.SafeEmit("comediastatechange", state)
+}
+
+/**
+ * @private
+ */
+func (t *PlainRtpTransport) handleWorkerNotifications() {
+	t.channel.On(t.internal.TransportId, func(event string, rawData json.RawMessage) {
+		var data PlainTransportData
+		json.Unmarshal([]byte(rawData), &data)
+
+		switch event {
+		case TransportNotificationTuple:
+			tuple := data.Tuple
+
+			t.locker.Lock()
+			t.data.Tuple = tuple
+			t.locker.Unlock()
+
+			t.SafeEmit("tuple", tuple)
+
+			// Emit observer event.
+			t.observer.SafeEmit("tuple", tuple)
+
+			t.setComediaState(ComediaStateConnected)
+
+			// Tuple notifications may be emitted concurrently.
+			t.locker.Lock()
+			select {
+			case <-t.tupleCh:
+			default:
+				close(t.tupleCh)
+			}
+			t.locker.Unlock()
+
+		case TransportNotificationRtcpTuple:
+			rtcpTuple := *data.RtcpTuple
+
+			t.locker.Lock()
+			t.data.RtcpTuple = &rtcpTuple
+			t.locker.Unlock()
+
+			t.SafeEmit("rtcptuple", rtcpTuple)
+
+			// Emit observer event.
+			t.observer.SafeEmit("rtcptuple", rtcpTuple)
+
+		default:
+			t.logger.Errorf(`ignoring unknown event "%s"`, event)
+		}
+	})
+}
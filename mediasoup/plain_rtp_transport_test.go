@@ -1,9 +1,14 @@
 package mediasoup
 
 import (
+	"encoding/json"
+	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -280,3 +285,170 @@ func TestPlaintRtpTransport_Emits_Routerclose_If_WorkerClosed(t *testing.T) {
 	assert.Equal(t, called, 1)
 	assert.True(t, transport.Closed())
 }
+
+func newTestComediaTransport() (*PlainRtpTransport, func()) {
+	socket, peer := net.Pipe()
+	channel := NewChannel(socket, 0)
+
+	transport := NewPlainRtpTransport(PlainTransportData{Comedia: true}, createTransportParams{
+		Internal: internalData{TransportId: "transport1"},
+		Channel:  channel,
+	})
+
+	return transport, func() {
+		channel.Close()
+		peer.Close()
+	}
+}
+
+func TestPlainRtpTransport_WaitForTuple_Succeeds(t *testing.T) {
+	transport, closeFn := newTestComediaTransport()
+	defer closeFn()
+
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+
+	var states []string
+	transport.On("comediastatechange", func(state string) {
+		states = append(states, state)
+	})
+
+	go func() {
+		// Let the first attempt time out, then learn the tuple in the second.
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(time.Second)
+		fakeClock.BlockUntil(1)
+		transport.channel.Emit("transport1", "tuple",
+			json.RawMessage(`{"tuple":{"localIp":"127.0.0.1","localPort":10000,"remoteIp":"1.2.3.4","remotePort":5004,"protocol":"udp"}}`))
+	}()
+
+	retries := 0
+	tuple, err := transport.WaitForTuple(ComediaWaitOptions{
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		OnRetry: func(attempt int) error {
+			retries = attempt
+			return nil
+		},
+		Clock: fakeClock,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", tuple.RemoteIp)
+	assert.Equal(t, ComediaStateConnected, transport.ComediaState())
+	assert.Equal(t, 1, retries)
+	assert.Equal(t, []string{ComediaStateWaiting, ComediaStateConnected}, states)
+
+	// Already connected.
+	tuple, err = transport.WaitForTuple(ComediaWaitOptions{Clock: fakeClock})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(5004), tuple.RemotePort)
+}
+
+func TestPlainRtpTransport_WaitForTuple_Timeout(t *testing.T) {
+	transport, closeFn := newTestComediaTransport()
+	defer closeFn()
+
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+
+	// Time the given number of attempts out.
+	timeOut := func(attempts int) {
+		for i := 0; i < attempts; i++ {
+			fakeClock.BlockUntil(1)
+			fakeClock.Advance(time.Second)
+		}
+	}
+
+	go timeOut(3)
+
+	retries := 0
+	_, err := transport.WaitForTuple(ComediaWaitOptions{
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		OnRetry: func(attempt int) error {
+			retries++
+			return nil
+		},
+		Clock: fakeClock,
+	})
+	assert.IsType(t, NewTimeoutError(""), err)
+	assert.Equal(t, 2, retries)
+	assert.Equal(t, ComediaStateFailed, transport.ComediaState())
+
+	go timeOut(1)
+
+	_, err = transport.WaitForTuple(ComediaWaitOptions{
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		OnRetry: func(attempt int) error {
+			return errors.New("sender failed")
+		},
+		Clock: fakeClock,
+	})
+	assert.EqualError(t, err, "sender failed")
+}
+
+func TestPlainRtpTransportConnect_SrtpTypeError(t *testing.T) {
+	transport, closeFn := newTestComediaTransport()
+	defer closeFn()
+
+	srtpParameters, err := NewSrtpParameters(SrtpCryptoSuiteAesCm128HmacSha1_80)
+	assert.NoError(t, err)
+
+	// SRTP not enabled.
+	err = transport.Connect(transportConnectParams{SrtpParameters: &srtpParameters})
+	assert.IsType(t, NewTypeError(""), err)
+
+	transport.data.SrtpParameters = &srtpParameters
+
+	err = transport.Connect(transportConnectParams{Ip: "127.0.0.1", Port: 9999})
+	assert.IsType(t, NewTypeError(""), err)
+
+	err = transport.Connect(transportConnectParams{
+		Ip:             "127.0.0.1",
+		Port:           9999,
+		SrtpParameters: &SrtpParameters{CryptoSuite: srtpParameters.CryptoSuite},
+	})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestRouterCreatePlainRtpTransport_EnableSrtp(t *testing.T) {
+	router, err := worker.CreateRouter(testPlainMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	_, err = router.CreatePlainRtpTransport(CreatePlainRtpTransportParams{
+		ListenIp:        ListenIp{Ip: "127.0.0.1"},
+		EnableSrtp:      true,
+		SrtpCryptoSuite: "foo",
+	})
+	assert.IsType(t, NewTypeError(""), err)
+
+	transport, err := router.CreatePlainRtpTransport(CreatePlainRtpTransportParams{
+		ListenIp:   ListenIp{Ip: "127.0.0.1"},
+		RtcpMux:    true,
+		EnableSrtp: true,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, transport.SrtpParameters())
+	assert.Equal(t, SrtpCryptoSuiteAesCm128HmacSha1_80, transport.SrtpParameters().CryptoSuite)
+
+	srtpParameters, _ := NewSrtpParameters(SrtpCryptoSuiteAesCm128HmacSha1_80)
+
+	err = transport.Connect(transportConnectParams{
+		Ip:             "127.0.0.1",
+		Port:           9999,
+		SrtpParameters: &srtpParameters,
+	})
+	assert.NoError(t, err)
+
+	// Keys cannot be rotated by connecting again.
+	localSrtpParameters := *transport.SrtpParameters()
+	rotatedSrtpParameters, _ := NewSrtpParameters(SrtpCryptoSuiteAesCm128HmacSha1_80)
+
+	err = transport.Connect(transportConnectParams{
+		Ip:             "127.0.0.1",
+		Port:           9999,
+		SrtpParameters: &rotatedSrtpParameters,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, localSrtpParameters, *transport.SrtpParameters())
+}
@@ -0,0 +1,163 @@
+package mediasoup
+
+import "sync"
+
+// SocketFlags of the listening socket of a Transport.
+type SocketFlags struct {
+	// Ipv6Only disables dual-stack support for an IPv6 listen ip.
+	Ipv6Only bool `json:"ipv6Only,omitempty"`
+	// UdpReusePort lets several Transports listen on the same UDP port.
+	UdpReusePort bool `json:"udpReusePort,omitempty"`
+}
+
+/**
+ * PortPool is a reserved range of ports (e.g. the ones opened in the
+ * firewall for ingest endpoints) shared by the Plain and Pipe transports
+ * created with it, across Routers and Workers. A port is held until the
+ * Transport using it is closed.
+ */
+type PortPool struct {
+	locker sync.Mutex
+	min    uint16
+	max    uint16
+	next   uint16
+	ports  map[uint16]portHolders
+}
+
+type portHolders struct {
+	count int
+	reuse bool
+}
+
+// NewPortPool returns a pool of the ports from min to max, included.
+func NewPortPool(min, max uint16) (*PortPool, error) {
+	if min == 0 || min > max {
+		return nil, NewTypeError("invalid port range [min:%d, max:%d]", min, max)
+	}
+
+	return &PortPool{
+		min:   min,
+		max:   max,
+		next:  min,
+		ports: map[uint16]portHolders{},
+	}, nil
+}
+
+// Contains returns true if the port is in the range of the pool.
+func (pool *PortPool) Contains(port uint16) bool {
+	return port >= pool.min && port <= pool.max
+}
+
+// Available returns the number of ports not held.
+func (pool *PortPool) Available() int {
+	pool.locker.Lock()
+	defer pool.locker.Unlock()
+
+	return int(pool.max-pool.min) + 1 - len(pool.ports)
+}
+
+/**
+ * Acquire holds a free port of the pool, chosen round-robin so a port just
+ * released is not given again immediately.
+ */
+func (pool *PortPool) Acquire() (port uint16, err error) {
+	pool.locker.Lock()
+	defer pool.locker.Unlock()
+
+	size := int(pool.max-pool.min) + 1
+
+	for i := 0; i < size; i++ {
+		port = pool.next
+
+		if pool.next == pool.max {
+			pool.next = pool.min
+		} else {
+			pool.next++
+		}
+
+		if _, ok := pool.ports[port]; !ok {
+			pool.ports[port] = portHolders{count: 1}
+			return
+		}
+	}
+
+	return 0, NewInvalidStateError("no available port [min:%d, max:%d]", pool.min, pool.max)
+}
+
+/**
+ * Reserve holds the given port of the pool. A port already held can only be
+ * shared if every holder sets reuse (i.e. SocketFlags.UdpReusePort).
+ */
+func (pool *PortPool) Reserve(port uint16, reuse bool) error {
+	if !pool.Contains(port) {
+		return NewTypeError("port out of pool range [port:%d, min:%d, max:%d]", port, pool.min, pool.max)
+	}
+
+	pool.locker.Lock()
+	defer pool.locker.Unlock()
+
+	holders, ok := pool.ports[port]
+
+	if ok && !(reuse && holders.reuse) {
+		return NewInvalidStateError("port already in use [port:%d]", port)
+	}
+
+	pool.ports[port] = portHolders{count: holders.count + 1, reuse: reuse}
+
+	return nil
+}
+
+// Release a port held with Acquire() or Reserve().
+func (pool *PortPool) Release(port uint16) {
+	pool.locker.Lock()
+	defer pool.locker.Unlock()
+
+	holders, ok := pool.ports[port]
+	if !ok {
+		return
+	}
+
+	if holders.count <= 1 {
+		delete(pool.ports, port)
+	} else {
+		holders.count--
+		pool.ports[port] = holders
+	}
+}
+
+// holdListenPort applies the pool to the listen ip of a Transport being
+// created, returning the function releasing the port.
+func holdListenPort(pool *PortPool, listenIp *ListenIp) (release func(), err error) {
+	release = func() {}
+
+	if pool == nil {
+		return
+	}
+
+	if listenIp.Port == 0 {
+		if listenIp.Port, err = pool.Acquire(); err != nil {
+			return
+		}
+	} else {
+		reuse := listenIp.Flags != nil && listenIp.Flags.UdpReusePort
+
+		if err = pool.Reserve(listenIp.Port, reuse); err != nil {
+			return
+		}
+	}
+
+	port := listenIp.Port
+	release = func() { pool.Release(port) }
+
+	return
+}
+
+// listenPortError makes the error of the worker explicit when a given port
+// could not be listened on.
+func listenPortError(listenIp ListenIp, err error) error {
+	if listenIp.Port == 0 {
+		return err
+	}
+
+	return NewTypeError("cannot listen on port [ip:%s, port:%d]: %s", listenIp.Ip, listenIp.Port, err)
+}
@@ -0,0 +1,94 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPortPool_TypeError(t *testing.T) {
+	_, err := NewPortPool(0, 10)
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = NewPortPool(20, 10)
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestPortPool(t *testing.T) {
+	pool, err := NewPortPool(40000, 40002)
+	assert.NoError(t, err)
+
+	port, err := pool.Acquire()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 40000, port)
+
+	assert.IsType(t, NewInvalidStateError(""), pool.Reserve(40000, false))
+	assert.IsType(t, NewTypeError(""), pool.Reserve(50000, false))
+
+	// Reused ports are shared by reusing holders only.
+	assert.NoError(t, pool.Reserve(40001, true))
+	assert.NoError(t, pool.Reserve(40001, true))
+	assert.Error(t, pool.Reserve(40001, false))
+
+	port, err = pool.Acquire()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 40002, port)
+
+	_, err = pool.Acquire()
+	assert.Error(t, err)
+	assert.Equal(t, 0, pool.Available())
+
+	pool.Release(40000)
+	pool.Release(40001)
+
+	assert.Equal(t, 1, pool.Available())
+	assert.Error(t, pool.Reserve(40001, false))
+
+	pool.Release(40001)
+
+	assert.Equal(t, 2, pool.Available())
+}
+
+func TestHoldListenPort(t *testing.T) {
+	release, err := holdListenPort(nil, &ListenIp{})
+	assert.NoError(t, err)
+	release()
+
+	pool, _ := NewPortPool(40000, 40001)
+
+	listenIp := ListenIp{Ip: "127.0.0.1"}
+	release, err = holdListenPort(pool, &listenIp)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 40000, listenIp.Port)
+
+	_, err = holdListenPort(pool, &ListenIp{Port: 40000})
+	assert.Error(t, err)
+
+	release()
+
+	_, err = holdListenPort(pool, &ListenIp{Port: 40000})
+	assert.NoError(t, err)
+}
+
+func TestCreatePlainRtpTransport_Port(t *testing.T) {
+	worker := CreateTestWorker()
+	router, _ := worker.CreateRouter(nil)
+	pool, _ := NewPortPool(40000, 40010)
+
+	transport, err := router.CreatePlainRtpTransport(CreatePlainRtpTransportParams{
+		ListenIp: ListenIp{Ip: "127.0.0.1", Port: 40005},
+		PortPool: pool,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 40005, transport.Tuple().LocalPort)
+
+	_, err = router.CreatePlainRtpTransport(CreatePlainRtpTransportParams{
+		ListenIp: ListenIp{Ip: "127.0.0.1", Port: 40005},
+		PortPool: pool,
+	})
+	assert.IsType(t, NewInvalidStateError(""), err)
+
+	transport.Close()
+
+	assert.Equal(t, 11, pool.Available())
+}
@@ -18,7 +18,11 @@ type Producer struct {
 	paused   bool
 	closed   bool
 	score    []ProducerScore
-	observer EventEmitter
+	// Last video orientation (just for video with urn:3gpp:video-orientation).
+	videoOrientation *VideoOrientation
+	// Set for Producers of a DirectTransport, see Send().
+	payloadChannel *PayloadChannel
+	observer       EventEmitter
 }
 
 /**
@@ -26,7 +30,8 @@ type Producer struct {
  *
  * @emits transportclose
  * @emits {Array<Object>} score
- * @emits {Object} videoorientationchange
+ * @emits {VideoOrientation} videoorientationchange
+ * @emits {TraceNotification} trace
  * @emits @close
  */
 func NewProducer(
@@ -70,7 +75,7 @@ func (producer *Producer) Closed() bool {
 }
 
 // Media kind.
-func (producer *Producer) Kind() string {
+func (producer *Producer) Kind() MediaKind {
 	return producer.data.Kind
 }
 
@@ -90,6 +95,12 @@ func (producer *Producer) ConsumableRtpParameters() RtpParameters {
 	return producer.data.ConsumableRtpParameters
 }
 
+// Whether the Producer is protected, i.e. only consumable if allowed by the
+// ProtectedConsumePolicy of the Router.
+func (producer *Producer) Protected() bool {
+	return producer.data.Protected
+}
+
 // Whether the Producer is paused.
 func (producer *Producer) Paused() bool {
 	return producer.paused
@@ -100,7 +111,12 @@ func (producer *Producer) Score() []ProducerScore {
 	return producer.score
 }
 
-//App custom data.
+// Last video orientation signaled by the Producer, nil if none.
+func (producer *Producer) VideoOrientation() *VideoOrientation {
+	return producer.videoOrientation
+}
+
+// App custom data.
 func (producer *Producer) AppData() interface{} {
 	return producer.appData
 }
@@ -112,7 +128,9 @@ func (producer *Producer) AppData() interface{} {
  * @emits pause
  * @emits resume
  * @emits {[]ProducerScore} score
- * @emits {Object} videoorientationchange
+ * @emits {VideoOrientation} videoorientationchange
+ * @emits {TraceNotification} trace
+ * @emits {ProtectedConsumeAudit} protectedconsume
  */
 func (producer *Producer) Observer() EventEmitter {
 	return producer.observer
@@ -224,26 +242,42 @@ func (producer *Producer) Resume() (err error) {
 func (producer *Producer) handleWorkerNotifications() {
 	producer.channel.On(producer.internal.ProducerId, func(event string, data json.RawMessage) {
 		switch event {
-		case "score":
+		case ProducerNotificationScore:
 			producer.score = []ProducerScore{}
 
 			json.Unmarshal([]byte(data), &producer.score)
 
+			if !allowGovernedEvent(GovernedEventProducerScore) {
+				break
+			}
+
 			producer.SafeEmit("score", producer.score)
 
 			// Emit observer event.
 			producer.observer.SafeEmit("score", producer.score)
 
-		case "videoorientationchange":
+		case ProducerNotificationVideoOrientationChange:
 			orientation := VideoOrientation{}
 
 			json.Unmarshal([]byte(data), &orientation)
 
+			producer.videoOrientation = &orientation
+
 			producer.SafeEmit("videoorientationchange", orientation)
 
 			// Emit observer event.
 			producer.observer.SafeEmit("videoorientationchange", orientation)
 
+		case ProducerNotificationTrace:
+			trace := TraceNotification{}
+
+			json.Unmarshal([]byte(data), &trace)
+
+			producer.SafeEmit("trace", trace)
+
+			// Emit observer event.
+			producer.observer.SafeEmit("trace", trace)
+
 		default:
 			producer.logger.Errorf(`ignoring unknown event "%s"`, event)
 		}
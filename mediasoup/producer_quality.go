@@ -0,0 +1,183 @@
+package mediasoup
+
+import (
+	"sort"
+	"sync"
+)
+
+type ProducerQualityOptions struct {
+	// JitterBoundsMs are the upper bounds of the jitter histogram buckets in
+	// milliseconds, in increasing order, default 5, 10, 20, 30, 50 and 100. A
+	// last bucket counts the samples above the last bound.
+	JitterBoundsMs []float64
+}
+
+// EncodingQuality is the quality of a Producer encoding over the observed
+// stats samples.
+type EncodingQuality struct {
+	Ssrc     uint32 `json:"ssrc"`
+	Rid      string `json:"rid,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Samples  uint64 `json:"samples"`
+	// JitterHistogram counts the samples per bucket of
+	// ProducerQualityOptions.JitterBoundsMs.
+	JitterHistogram []uint64 `json:"jitterHistogram"`
+	// InactiveSamples counts the samples without any packet received since
+	// the previous one, InactivityGaps the runs of such samples.
+	InactiveSamples uint64 `json:"inactiveSamples"`
+	InactivityGaps  uint64 `json:"inactivityGaps"`
+	// LongestGapMs is the longest inactivity gap, including the current one.
+	LongestGapMs int64 `json:"longestGapMs"`
+	// Inactive tells whether the encoding is in an inactivity gap.
+	Inactive bool `json:"inactive"`
+}
+
+// ProducerQualityReport is the quality of the encodings of a Producer,
+// sorted by SSRC.
+type ProducerQualityReport struct {
+	ProducerId   string            `json:"producerId"`
+	JitterBounds []float64         `json:"jitterBoundsMs"`
+	Encodings    []EncodingQuality `json:"encodings"`
+}
+
+type encodingQualityState struct {
+	EncodingQuality
+	lastTimestamp   int64
+	lastPacketCount uint32
+	gapStart        int64
+}
+
+/**
+ * ProducerQualityTracker computes jitter histograms and inactivity gaps of
+ * the encodings of a Producer from its periodic stats, e.g. for SLA
+ * reporting on publisher quality. Feed it with Observe() or Attach() it to a
+ * StatsPoller polling the Producer.
+ */
+type ProducerQualityTracker struct {
+	locker     sync.Mutex
+	producerId string
+	kind       MediaKind
+	clockRates map[MimeType]int
+	bounds     []float64
+	encodings  map[uint32]*encodingQualityState
+}
+
+func NewProducerQualityTracker(producer *Producer, options ProducerQualityOptions) *ProducerQualityTracker {
+	if len(options.JitterBoundsMs) == 0 {
+		options.JitterBoundsMs = []float64{5, 10, 20, 30, 50, 100}
+	}
+
+	clockRates := map[MimeType]int{}
+
+	for _, codec := range producer.RtpParameters().Codecs {
+		clockRates[ParseMimeType(codec.MimeType)] = codec.ClockRate
+	}
+
+	return &ProducerQualityTracker{
+		producerId: producer.Id(),
+		kind:       producer.Kind(),
+		clockRates: clockRates,
+		bounds:     options.JitterBoundsMs,
+		encodings:  make(map[uint32]*encodingQualityState),
+	}
+}
+
+// Attach observes the "stats" of the poller, until off() is called.
+func (tracker *ProducerQualityTracker) Attach(poller *StatsPoller) (off func()) {
+	return OnEvent(poller.EventEmitter, "stats", tracker.Observe)
+}
+
+// Observe accounts the inbound streams of a stats snapshot of the Producer,
+// snapshots of other entities are ignored.
+func (tracker *ProducerQualityTracker) Observe(snapshot StatsSnapshot) {
+	if snapshot.Id != tracker.producerId || snapshot.Entity != IdEntityProducer {
+		return
+	}
+
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	for _, stat := range snapshot.Streams {
+		if stat.Type != "inbound-rtp" {
+			continue
+		}
+
+		state, ok := tracker.encodings[stat.Ssrc]
+		if !ok {
+			state = &encodingQualityState{
+				EncodingQuality: EncodingQuality{
+					Ssrc:            stat.Ssrc,
+					Rid:             stat.Rid,
+					MimeType:        stat.MimeType,
+					JitterHistogram: make([]uint64, len(tracker.bounds)+1),
+				},
+			}
+			tracker.encodings[stat.Ssrc] = state
+		}
+
+		state.Samples++
+		state.JitterHistogram[tracker.jitterBucket(stat)]++
+
+		if ok && stat.PacketCount == state.lastPacketCount {
+			state.InactiveSamples++
+
+			if !state.Inactive {
+				state.Inactive = true
+				state.InactivityGaps++
+				state.gapStart = state.lastTimestamp
+			}
+			if gap := snapshot.Timestamp - state.gapStart; gap > state.LongestGapMs {
+				state.LongestGapMs = gap
+			}
+		} else {
+			state.Inactive = false
+		}
+
+		state.lastTimestamp = snapshot.Timestamp
+		state.lastPacketCount = stat.PacketCount
+	}
+}
+
+// Report returns the quality of the encodings observed so far.
+func (tracker *ProducerQualityTracker) Report() ProducerQualityReport {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	report := ProducerQualityReport{
+		ProducerId:   tracker.producerId,
+		JitterBounds: tracker.bounds,
+		Encodings:    make([]EncodingQuality, 0, len(tracker.encodings)),
+	}
+
+	for _, state := range tracker.encodings {
+		quality := state.EncodingQuality
+		quality.JitterHistogram = append([]uint64(nil), state.JitterHistogram...)
+		report.Encodings = append(report.Encodings, quality)
+	}
+
+	sort.Slice(report.Encodings, func(i, j int) bool {
+		return report.Encodings[i].Ssrc < report.Encodings[j].Ssrc
+	})
+
+	return report
+}
+
+// jitterBucket returns the histogram bucket of the stream jitter, which is
+// in RTP timestamp units of the codec clock rate.
+func (tracker *ProducerQualityTracker) jitterBucket(stat RtpStreamStat) int {
+	clockRate := tracker.clockRates[ParseMimeType(stat.MimeType)]
+
+	if clockRate == 0 {
+		if tracker.kind == MediaKindAudio {
+			clockRate = 48000
+		} else {
+			clockRate = 90000
+		}
+	}
+
+	jitterMs := float64(stat.Jitter) * 1000 / float64(clockRate)
+
+	return sort.Search(len(tracker.bounds), func(i int) bool {
+		return jitterMs < tracker.bounds[i]
+	})
+}
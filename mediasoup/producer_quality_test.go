@@ -0,0 +1,96 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProducerQualityTracker(t *testing.T) {
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{
+		Kind: MediaKindVideo,
+		RtpParameters: RtpParameters{
+			Codecs: []RtpCodecCapability{{MimeType: "video/VP8", ClockRate: 90000}},
+		},
+	}, newTestChannel(), nil, false)
+
+	tracker := NewProducerQualityTracker(producer, ProducerQualityOptions{JitterBoundsMs: []float64{10, 50}})
+
+	sample := func(timestamp int64, packetCount, jitter uint32) StatsSnapshot {
+		return StatsSnapshot{
+			Id:        "p1",
+			Entity:    IdEntityProducer,
+			Timestamp: timestamp,
+			Streams: []RtpStreamStat{
+				{Type: "inbound-rtp", Ssrc: 2222, Rid: "r1", MimeType: "video/VP8", PacketCount: packetCount, Jitter: jitter},
+				{Type: "inbound-rtp", Ssrc: 1111, Rid: "r0", MimeType: "video/VP8", PacketCount: 100, Jitter: 450},
+			},
+		}
+	}
+
+	// 90 = 1ms, 1800 = 20ms and 9000 = 100ms at 90kHz.
+	tracker.Observe(sample(1000, 100, 90))
+	tracker.Observe(sample(2000, 200, 1800))
+	tracker.Observe(sample(3000, 200, 9000))
+	tracker.Observe(sample(4000, 200, 9000))
+	tracker.Observe(sample(5000, 300, 90))
+	tracker.Observe(sample(6000, 300, 90))
+
+	// Ignored.
+	tracker.Observe(StatsSnapshot{Id: "p2", Entity: IdEntityProducer, Streams: sample(0, 0, 0).Streams})
+
+	report := tracker.Report()
+	assert.Equal(t, "p1", report.ProducerId)
+	assert.Len(t, report.Encodings, 2)
+
+	// Always inactive after the first sample, never active again.
+	assert.Equal(t, EncodingQuality{
+		Ssrc:            1111,
+		Rid:             "r0",
+		MimeType:        "video/VP8",
+		Samples:         6,
+		JitterHistogram: []uint64{6, 0, 0},
+		InactiveSamples: 5,
+		InactivityGaps:  1,
+		LongestGapMs:    5000,
+		Inactive:        true,
+	}, report.Encodings[0])
+
+	assert.Equal(t, EncodingQuality{
+		Ssrc:            2222,
+		Rid:             "r1",
+		MimeType:        "video/VP8",
+		Samples:         6,
+		JitterHistogram: []uint64{3, 1, 2},
+		InactiveSamples: 3,
+		InactivityGaps:  2,
+		LongestGapMs:    2000,
+		Inactive:        true,
+	}, report.Encodings[1])
+
+	// Fed by a StatsPoller.
+	poller := NewStatsPoller(StatsPollerOptions{})
+	defer poller.Close()
+
+	off := tracker.Attach(poller)
+	poller.Emit("stats", sample(7000, 400, 90))
+	off()
+	poller.Emit("stats", sample(8000, 500, 90))
+
+	assert.EqualValues(t, 7, tracker.Report().Encodings[1].Samples)
+	assert.False(t, tracker.Report().Encodings[1].Inactive)
+}
+
+func TestProducerQualityTracker_ClockRate(t *testing.T) {
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{
+		Kind: MediaKindAudio,
+		RtpParameters: RtpParameters{
+			Codecs: []RtpCodecCapability{{MimeType: "audio/PCMU", ClockRate: 8000}},
+		},
+	}, newTestChannel(), nil, false)
+
+	tracker := NewProducerQualityTracker(producer, ProducerQualityOptions{JitterBoundsMs: []float64{5, 50}})
+
+	// 80 = 10ms at 8kHz, but below 2ms at the default 48kHz.
+	assert.Equal(t, 1, tracker.jitterBucket(RtpStreamStat{MimeType: "AUDIO/pcmu", Jitter: 80}))
+}
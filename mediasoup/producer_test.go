@@ -83,7 +83,7 @@ func (suite *ProducerTestSuite) TestWebRtcTransportProduce_Succeeds() {
 	onObserverNewProducer.ExpectCalledWith(audioProducer)
 	suite.NotEmpty(audioProducer.Id())
 	suite.False(audioProducer.Closed())
-	suite.Equal("audio", audioProducer.Kind())
+	suite.Equal(MediaKindAudio, audioProducer.Kind())
 	suite.NotEmpty(audioProducer.RtpParameters())
 	suite.Equal("simple", audioProducer.Type())
 	// Private API.
@@ -126,7 +126,7 @@ func (suite *ProducerTestSuite) TestPlainRtpTransportProduce_Succeeds() {
 	onObserverNewProducer.ExpectCalledWith(videoProducer)
 	suite.NotEmpty(videoProducer.Id())
 	suite.False(videoProducer.Closed())
-	suite.Equal("video", videoProducer.Kind())
+	suite.Equal(MediaKindVideo, videoProducer.Kind())
 	suite.NotEmpty(videoProducer.RtpParameters())
 	suite.Equal("simulcast", videoProducer.Type())
 	// Private API.
@@ -340,7 +340,7 @@ func (suite *ProducerTestSuite) TestWebRtcTransportProduce_WithAlreadyUsedMIDOrS
 	var produceParams transportProduceParams
 	json.Unmarshal([]byte(produceParamsJSON), &produceParams)
 	_, err := webRtcTransport.Produce(produceParams)
-	suite.Error(err)
+	suite.IsType(DuplicateSsrcError{}, err)
 
 	produceParamsJSON = `
 	{
@@ -363,7 +363,7 @@ func (suite *ProducerTestSuite) TestWebRtcTransportProduce_WithAlreadyUsedMIDOrS
 `
 	json.Unmarshal([]byte(produceParamsJSON), &produceParams)
 	_, err = webRtcTransport.Produce(produceParams)
-	suite.Error(err)
+	suite.IsType(DuplicateSsrcError{}, err)
 }
 
 func (suite *ProducerTestSuite) TestProduerDump_Succeeds() {
@@ -371,7 +371,7 @@ func (suite *ProducerTestSuite) TestProduerDump_Succeeds() {
 
 	type Dump struct {
 		Id            string
-		Kind          string
+		Kind          MediaKind
 		Type          string
 		RtpParameters RtpParameters
 	}
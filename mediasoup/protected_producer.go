@@ -0,0 +1,131 @@
+package mediasoup
+
+import "time"
+
+// ProtectedConsumeRequest is given to the ProtectedConsumePolicy of the
+// Router when a protected Producer is about to be consumed.
+type ProtectedConsumeRequest struct {
+	Producer    *Producer
+	TransportId string
+	// Pipe is true if the Producer is being piped to another Router.
+	Pipe bool
+	// Device hint and app data of the Consumer, see Consume().
+	Device  string
+	AppData interface{}
+}
+
+// ProtectedConsumePolicy authorizes the Consumers of protected Producers
+// (e.g. DRM or consent restricted screenshares). A non nil error rejects the
+// Consume() call.
+type ProtectedConsumePolicy func(request ProtectedConsumeRequest) error
+
+// ProtectedConsumeAudit records an attempt to consume a protected Producer.
+type ProtectedConsumeAudit struct {
+	ProducerId  string `json:"producerId"`
+	TransportId string `json:"transportId"`
+	// ConsumerId is empty if the attempt was rejected.
+	ConsumerId string    `json:"consumerId,omitempty"`
+	Pipe       bool      `json:"pipe"`
+	Allowed    bool      `json:"allowed"`
+	Reason     string    `json:"reason,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// checkProtectedConsume applies the policy to the request, rejecting it if
+// there is no policy.
+func checkProtectedConsume(policy ProtectedConsumePolicy, request ProtectedConsumeRequest) (audit ProtectedConsumeAudit, err error) {
+	audit = ProtectedConsumeAudit{
+		ProducerId:  request.Producer.Id(),
+		TransportId: request.TransportId,
+		Pipe:        request.Pipe,
+		Timestamp:   time.Now(),
+	}
+
+	if policy == nil {
+		err = NewUnauthorizedError("Producer is protected [producerId:%s]", audit.ProducerId)
+	} else {
+		err = policy(request)
+	}
+
+	if err != nil {
+		audit.Reason = err.Error()
+	} else {
+		audit.Allowed = true
+	}
+
+	return
+}
+
+// authorizeProtectedConsume checks the Consumer of a protected Producer,
+// returning the audit to emit once the Consumer is created. Unprotected
+// Producers are always allowed.
+func (transport *baseTransport) authorizeProtectedConsume(
+	producer *Producer,
+	pipe bool,
+	params transportConsumeParams,
+) (audit *ProtectedConsumeAudit, err error) {
+	if !producer.Protected() {
+		return
+	}
+
+	result, err := checkProtectedConsume(transport.protectedConsumePolicy, ProtectedConsumeRequest{
+		Producer:    producer,
+		TransportId: transport.Id(),
+		Pipe:        pipe,
+		Device:      params.Device,
+		AppData:     params.AppData,
+	})
+	if err != nil {
+		transport.logger.Warnf("protected Producer consume rejected [producerId:%s]: %s",
+			producer.Id(), err)
+
+		transport.emitProtectedConsume(producer, result)
+
+		return
+	}
+
+	return &result, nil
+}
+
+// authorizeSwitchProducer authorizes the Consumer created with params to
+// switch to the Producer, as Consume() does. The returned function emits the
+// audit of a protected Producer once switched.
+func (transport *baseTransport) authorizeSwitchProducer(
+	consumer *Consumer,
+	producer *Producer,
+	token string,
+	params transportConsumeParams,
+) (switched func(), err error) {
+	if transport.consumeTokenValidator != nil {
+		if err = transport.consumeTokenValidator(producer.Id(), token); err != nil {
+			return
+		}
+	}
+
+	params.ProducerId = producer.Id()
+	params.Token = token
+
+	audit, err := transport.authorizeProtectedConsume(producer, false, params)
+	if err != nil {
+		return
+	}
+
+	switched = func() {
+		if audit != nil {
+			audit.ConsumerId = consumer.Id()
+			transport.emitProtectedConsume(producer, *audit)
+		}
+	}
+
+	return
+}
+
+// emitProtectedConsume emits the audit on the Transport and on the observers
+// of the Transport and of the Producer.
+func (transport *baseTransport) emitProtectedConsume(producer *Producer, audit ProtectedConsumeAudit) {
+	transport.SafeEmit("protectedconsume", audit)
+
+	// Emit observer events.
+	transport.observer.SafeEmit("protectedconsume", audit)
+	producer.Observer().SafeEmit("protectedconsume", audit)
+}
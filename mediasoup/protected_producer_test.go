@@ -0,0 +1,147 @@
+package mediasoup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProtectedConsume(t *testing.T) {
+	producer := &Producer{
+		internal: internalData{ProducerId: "producer-1"},
+		data:     producerData{Kind: MediaKindVideo, Protected: true},
+	}
+	request := ProtectedConsumeRequest{
+		Producer:    producer,
+		TransportId: "transport-1",
+		AppData:     H{"role": "recorder"},
+	}
+
+	audit, err := checkProtectedConsume(nil, request)
+	assert.IsType(t, NewUnauthorizedError(""), err)
+	assert.False(t, audit.Allowed)
+	assert.Equal(t, "producer-1", audit.ProducerId)
+	assert.Equal(t, "transport-1", audit.TransportId)
+	assert.Equal(t, err.Error(), audit.Reason)
+
+	policy := func(request ProtectedConsumeRequest) error {
+		if request.AppData.(H)["role"] == "recorder" && !request.Pipe {
+			return errors.New("recording not consented")
+		}
+		return nil
+	}
+
+	audit, err = checkProtectedConsume(policy, request)
+	assert.EqualError(t, err, "recording not consented")
+	assert.False(t, audit.Allowed)
+	assert.Equal(t, "recording not consented", audit.Reason)
+
+	request.Pipe = true
+
+	audit, err = checkProtectedConsume(policy, request)
+	assert.NoError(t, err)
+	assert.True(t, audit.Allowed)
+	assert.True(t, audit.Pipe)
+	assert.Empty(t, audit.Reason)
+	assert.False(t, audit.Timestamp.IsZero())
+}
+
+func TestConsumerSwitchProducer_Authorization(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	assert.NoError(t, err)
+
+	channel, methods := newRecordingTestChannel()
+	producers := map[string]*Producer{}
+	var allowProtected bool
+
+	transport := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  channel,
+		GetRouterRtpCapabilities: func() RtpCapabilities {
+			return caps
+		},
+		GetProducerById: func(producerId string) *Producer {
+			return producers[producerId]
+		},
+		ConsumeTokenValidator: func(producerId, token string) error {
+			if token != "token-"+producerId {
+				return NewUnauthorizedError("invalid token")
+			}
+			return nil
+		},
+		ProtectedConsumePolicy: func(request ProtectedConsumeRequest) error {
+			if !allowProtected {
+				return errors.New("not allowed")
+			}
+			return nil
+		},
+	})
+	transport.On("@newproducer", func(producer *Producer) {
+		producers[producer.Id()] = producer
+	})
+
+	produce := func(ssrc uint32, protected bool) *Producer {
+		producer, err := transport.Produce(transportProduceParams{
+			Kind: "audio",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PayloadType: 111},
+				},
+				Encodings: []RtpEncoding{{Ssrc: ssrc}},
+			},
+			Protected: protected,
+		})
+		assert.NoError(t, err)
+		return producer
+	}
+
+	producer1 := produce(1111, false)
+	producer2 := produce(2222, false)
+	protectedProducer := produce(3333, true)
+
+	consumer, err := transport.Consume(transportConsumeParams{
+		ProducerId:      producer1.Id(),
+		RtpCapabilities: caps,
+		Token:           "token-" + producer1.Id(),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, consumer.SetPriority(3))
+	assert.NoError(t, consumer.EnableTraceEvent(TraceEventTypeRtp))
+
+	err = consumer.SwitchProducer(producer2.Id(), "wrong")
+	assert.IsType(t, NewUnauthorizedError(""), err)
+	assert.Equal(t, producer1.Id(), consumer.ProducerId())
+
+	err = consumer.SwitchProducer(protectedProducer.Id(), "token-"+protectedProducer.Id())
+	assert.EqualError(t, err, "not allowed")
+	assert.Equal(t, producer1.Id(), consumer.ProducerId())
+
+	before := len(methods())
+
+	assert.NoError(t, consumer.SwitchProducer(producer2.Id(), "token-"+producer2.Id()))
+	assert.Equal(t, producer2.Id(), consumer.ProducerId())
+
+	// The settings of the Consumer are applied to the new worker consumer.
+	assert.Equal(t, []string{
+		"consumer.close",
+		"transport.consume",
+		"consumer.setPriority",
+		"consumer.enableTraceEvent",
+	}, methods()[before:])
+
+	allowProtected = true
+
+	var audits []ProtectedConsumeAudit
+	transport.On("protectedconsume", func(audit ProtectedConsumeAudit) {
+		audits = append(audits, audit)
+	})
+
+	assert.NoError(t, consumer.SwitchProducer(protectedProducer.Id(), "token-"+protectedProducer.Id()))
+	if assert.Len(t, audits, 1) {
+		assert.True(t, audits[0].Allowed)
+		assert.Equal(t, consumer.Id(), audits[0].ConsumerId)
+	}
+}
@@ -0,0 +1,135 @@
+package mediasoup
+
+import (
+	"strings"
+	"sync"
+)
+
+// TransportWideCcUri is the transport-wide congestion control RTP header
+// extension.
+const TransportWideCcUri = "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01"
+
+// ClientQuirks adjust the RTP parameters of a Consumer for a client known to
+// deviate from what it announces in its RTP capabilities.
+type ClientQuirks struct {
+	// H264Only removes every video codec but H264 (and its RTX).
+	H264Only bool
+	// NoTransportCc removes the transport-wide-cc header extension and the
+	// "transport-cc" RTCP feedback so the client falls back to REMB.
+	NoTransportCc bool
+	// LowercaseRid lower cases the rid of the encodings.
+	LowercaseRid bool
+}
+
+var (
+	clientQuirksLocker sync.RWMutex
+	// device name -> quirks
+	clientQuirks = map[string]ClientQuirks{
+		"safari":  {H264Only: true},
+		"firefox": {NoTransportCc: true},
+		"chrome":  {LowercaseRid: true},
+	}
+)
+
+// RegisterClientQuirks registers (or replaces) the quirks of a device name,
+// matched case insensitively against the "device" hint given to Consume().
+func RegisterClientQuirks(device string, quirks ClientQuirks) {
+	clientQuirksLocker.Lock()
+	defer clientQuirksLocker.Unlock()
+
+	clientQuirks[strings.ToLower(device)] = quirks
+}
+
+// GetClientQuirks returns the quirks of the device hint, e.g. "Safari" or
+// "firefox/115.0". Only the name before the first "/" or space is used. A full
+// User-Agent is accepted too, see ParseUserAgent().
+func GetClientQuirks(device string) (quirks ClientQuirks, ok bool) {
+	name := strings.ToLower(strings.TrimSpace(device))
+
+	if strings.HasPrefix(name, "mozilla/") {
+		name = ParseUserAgent(device).Name()
+	} else if i := strings.IndexAny(name, "/ "); i >= 0 {
+		name = name[:i]
+	}
+
+	clientQuirksLocker.RLock()
+	defer clientQuirksLocker.RUnlock()
+
+	quirks, ok = clientQuirks[name]
+
+	return
+}
+
+/**
+ * Apply the client quirks to the RTP parameters of a Consumer.
+ *
+ * @param {RTCRtpParameters} params - Consumer RTP parameters.
+ * @param {ClientQuirks} quirks
+ *
+ * @returns {RTCRtpParameters}
+ * @throws {UnsupportedError} if no media codec is left.
+ */
+func applyClientQuirks(params RtpParameters, quirks ClientQuirks) (RtpParameters, error) {
+	if quirks.H264Only {
+		codecs := []RtpCodecCapability{}
+		payloadTypes := map[int]bool{}
+
+		for _, codec := range params.Codecs {
+			mimeType := ParseMimeType(codec.MimeType)
+
+			if mimeType.Kind() != "video" ||
+				strings.EqualFold(mimeType.Subtype(), "h264") {
+				codecs = append(codecs, codec)
+				payloadTypes[codec.PayloadType] = true
+			}
+		}
+
+		for _, codec := range params.Codecs {
+			if ParseMimeType(codec.MimeType).IsRtx() &&
+				codec.Parameters != nil && payloadTypes[codec.Parameters.Apt] &&
+				!payloadTypes[codec.PayloadType] {
+				codecs = append(codecs, codec)
+			}
+		}
+
+		if len(codecs) == 0 || ParseMimeType(codecs[0].MimeType).IsRtx() {
+			return params, NewUnsupportedError("no compatible media codecs for client quirks")
+		}
+
+		params.Codecs = codecs
+	}
+
+	if quirks.NoTransportCc {
+		params = stripHeaderExtension(params, TransportWideCcUri)
+
+		codecs := make([]RtpCodecCapability, 0, len(params.Codecs))
+
+		for _, codec := range params.Codecs {
+			rtcpFeedback := []RtcpFeedback{}
+
+			for _, fb := range codec.RtcpFeedback {
+				if fb.Type != "transport-cc" {
+					rtcpFeedback = append(rtcpFeedback, fb)
+				}
+			}
+
+			codec.RtcpFeedback = rtcpFeedback
+			codecs = append(codecs, codec)
+		}
+
+		params.Codecs = codecs
+	}
+
+	if quirks.LowercaseRid {
+		encodings := make([]RtpEncoding, 0, len(params.Encodings))
+
+		for _, encoding := range params.Encodings {
+			encoding.Rid = strings.ToLower(encoding.Rid)
+			encodings = append(encodings, encoding)
+		}
+
+		params.Encodings = encodings
+	}
+
+	return params, nil
+}
@@ -0,0 +1,92 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClientQuirks(t *testing.T) {
+	quirks, ok := GetClientQuirks("Safari")
+	assert.True(t, ok)
+	assert.True(t, quirks.H264Only)
+
+	quirks, ok = GetClientQuirks("firefox/115.0")
+	assert.True(t, ok)
+	assert.True(t, quirks.NoTransportCc)
+
+	_, ok = GetClientQuirks("")
+	assert.False(t, ok)
+
+	_, ok = GetClientQuirks("netscape")
+	assert.False(t, ok)
+
+	RegisterClientQuirks("Netscape", ClientQuirks{LowercaseRid: true})
+
+	quirks, ok = GetClientQuirks("netscape 4")
+	assert.True(t, ok)
+	assert.True(t, quirks.LowercaseRid)
+}
+
+func TestApplyClientQuirks(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/VP8",
+				PayloadType: 101,
+				RtcpFeedback: []RtcpFeedback{
+					{Type: "nack"},
+					{Type: "transport-cc"},
+				},
+			},
+			{
+				MimeType:    "video/rtx",
+				PayloadType: 102,
+				Parameters:  &RtpCodecParameter{Apt: 101},
+			},
+			{
+				MimeType:    "video/H264",
+				PayloadType: 103,
+				RtcpFeedback: []RtcpFeedback{
+					{Type: "nack"},
+					{Type: "transport-cc"},
+				},
+			},
+			{
+				MimeType:    "video/rtx",
+				PayloadType: 104,
+				Parameters:  &RtpCodecParameter{Apt: 103},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: TransportWideCcUri, Id: 5},
+			{Uri: "urn:3gpp:video-orientation", Id: 11},
+		},
+		Encodings: []RtpEncoding{
+			{Rid: "R0", Ssrc: 1111},
+		},
+	}
+
+	result, err := applyClientQuirks(params, ClientQuirks{H264Only: true})
+	assert.NoError(t, err)
+	assert.Len(t, result.Codecs, 2)
+	assert.Equal(t, 103, result.Codecs[0].PayloadType)
+	assert.Equal(t, 104, result.Codecs[1].PayloadType)
+	assert.Len(t, params.Codecs, 4)
+
+	result, err = applyClientQuirks(params, ClientQuirks{NoTransportCc: true})
+	assert.NoError(t, err)
+	assert.Len(t, result.HeaderExtensions, 1)
+	assert.Equal(t, []RtcpFeedback{{Type: "nack"}}, result.Codecs[0].RtcpFeedback)
+	assert.Len(t, params.Codecs[0].RtcpFeedback, 2)
+
+	result, err = applyClientQuirks(params, ClientQuirks{LowercaseRid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "r0", result.Encodings[0].Rid)
+	assert.Equal(t, "R0", params.Encodings[0].Rid)
+
+	params.Codecs = params.Codecs[:2]
+
+	_, err = applyClientQuirks(params, ClientQuirks{H264Only: true})
+	assert.IsType(t, NewUnsupportedError(""), err)
+}
@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+)
+
+var logger = mediasoup.TypeLogger("Registry")
+
+// RedisClient runs a Redis command and returns its reply, nil if the reply is
+// nil. Adapters are one-liners, e.g. for go-redis:
+//
+//	func(args ...interface{}) (interface{}, error) {
+//		reply, err := client.Do(ctx, args...).Result()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return reply, err
+//	}
+type RedisClient interface {
+	Do(args ...interface{}) (interface{}, error)
+}
+
+// RedisClientFunc adapts a function to RedisClient.
+type RedisClientFunc func(args ...interface{}) (interface{}, error)
+
+func (fn RedisClientFunc) Do(args ...interface{}) (interface{}, error) {
+	return fn(args...)
+}
+
+// Replaces the value of KEYS[1] if its nodeId is ARGV[1] or it does not exist
+// and ARGV[4] is "1".
+const redisReplaceScript = `
+local value = redis.call("GET", KEYS[1])
+if (not value and ARGV[4] == "1") or (value and cjson.decode(value).nodeId == ARGV[1]) then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0`
+
+// Deletes KEYS[1] if its nodeId is ARGV[1].
+const redisReleaseScript = `
+local value = redis.call("GET", KEYS[1])
+if value and cjson.decode(value).nodeId == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+return 0`
+
+// RedisRegistry is a Registry storing every room in a Redis key with a TTL.
+type RedisRegistry struct {
+	client    RedisClient
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewRedisRegistry creates a registry whose keys are "<keyPrefix>room:<id>".
+// Owners must send heartbeats more often than ttl, see KeepAlive().
+func NewRedisRegistry(client RedisClient, keyPrefix string, ttl time.Duration) *RedisRegistry {
+	return &RedisRegistry{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}
+}
+
+func (r *RedisRegistry) Claim(entry RoomEntry) (current RoomEntry, claimed bool, err error) {
+	entry.UpdatedAt = nowMs()
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	reply, err := r.client.Do("SET", r.key(entry.RoomId), string(value), "NX", "PX", r.ttlMs())
+	if err != nil {
+		return
+	}
+	if reply != nil {
+		return entry, true, nil
+	}
+
+	existing, err := r.Lookup(entry.RoomId)
+	if err != nil {
+		return
+	}
+	if existing == nil {
+		// Expired in the meantime.
+		return r.Claim(entry)
+	}
+
+	return *existing, false, nil
+}
+
+func (r *RedisRegistry) Lookup(roomId string) (entry *RoomEntry, err error) {
+	reply, err := r.client.Do("GET", r.key(roomId))
+	if err != nil || reply == nil {
+		return
+	}
+
+	entry = &RoomEntry{}
+
+	if err = json.Unmarshal(replyBytes(reply), entry); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (r *RedisRegistry) Heartbeat(roomId, nodeId string) error {
+	entry, err := r.Lookup(roomId)
+	if err != nil {
+		return err
+	}
+	if entry == nil || entry.NodeId != nodeId {
+		return ErrNotOwner
+	}
+
+	return r.replace(*entry, nodeId, false)
+}
+
+func (r *RedisRegistry) Takeover(entry RoomEntry, previousNodeId string) error {
+	return r.replace(entry, previousNodeId, true)
+}
+
+func (r *RedisRegistry) Release(roomId, nodeId string) error {
+	reply, err := r.client.Do("EVAL", redisReleaseScript, 1, r.key(roomId), nodeId)
+	if err != nil {
+		return err
+	}
+	if !replyOk(reply) {
+		return ErrNotOwner
+	}
+
+	return nil
+}
+
+func (r *RedisRegistry) replace(entry RoomEntry, expectedNodeId string, allowMissing bool) error {
+	entry.UpdatedAt = nowMs()
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	missing := "0"
+	if allowMissing {
+		missing = "1"
+	}
+
+	reply, err := r.client.Do("EVAL", redisReplaceScript, 1, r.key(entry.RoomId),
+		expectedNodeId, string(value), r.ttlMs(), missing)
+	if err != nil {
+		return err
+	}
+	if !replyOk(reply) {
+		return ErrNotOwner
+	}
+
+	return nil
+}
+
+func (r *RedisRegistry) key(roomId string) string {
+	return r.keyPrefix + "room:" + roomId
+}
+
+func (r *RedisRegistry) ttlMs() int64 {
+	return int64(r.ttl / time.Millisecond)
+}
+
+func replyBytes(reply interface{}) []byte {
+	switch v := reply.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+func replyOk(reply interface{}) bool {
+	switch v := reply.(type) {
+	case int64:
+		return v == 1
+	case int:
+		return v == 1
+	default:
+		return false
+	}
+}
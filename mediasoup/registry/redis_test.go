@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis implements the commands used by RedisRegistry, without TTLs.
+type fakeRedis struct {
+	sync.Mutex
+	values map[string]string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: map[string]string{}}
+}
+
+func (r *fakeRedis) Do(args ...interface{}) (interface{}, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	f := r.values
+
+	switch args[0] {
+	case "GET":
+		if value, ok := f[args[1].(string)]; ok {
+			return value, nil
+		}
+		return nil, nil
+
+	case "SET":
+		key := args[1].(string)
+		if _, ok := f[key]; ok && len(args) > 3 && args[3] == "NX" {
+			return nil, nil
+		}
+		f[key] = args[2].(string)
+		return "OK", nil
+
+	case "EVAL":
+		key, nodeId := args[3].(string), args[4].(string)
+		value, ok := f[key]
+
+		var owner struct{ NodeId string }
+		if ok {
+			json.Unmarshal([]byte(value), &owner)
+		}
+
+		switch args[1] {
+		case redisReplaceScript:
+			if (!ok && args[7] == "1") || (ok && owner.NodeId == nodeId) {
+				f[key] = args[5].(string)
+				return int64(1), nil
+			}
+		case redisReleaseScript:
+			if ok && owner.NodeId == nodeId {
+				delete(f, key)
+				return int64(1), nil
+			}
+		}
+		return int64(0), nil
+	}
+
+	panic(args[0])
+}
+
+func TestRedisRegistry(t *testing.T) {
+	registry := NewRedisRegistry(newFakeRedis(), "sfu:", time.Minute)
+
+	entry := RoomEntry{RoomId: "room1", NodeId: "node1", Host: "10.0.0.1", WorkerPid: 42, RouterId: "r1"}
+
+	current, claimed, err := registry.Claim(entry)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "node1", current.NodeId)
+
+	current, claimed, err = registry.Claim(RoomEntry{RoomId: "room1", NodeId: "node2"})
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, "r1", current.RouterId)
+
+	found, err := registry.Lookup("room1")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", found.Host)
+
+	assert.NoError(t, registry.Heartbeat("room1", "node1"))
+	assert.Equal(t, ErrNotOwner, registry.Heartbeat("room1", "node2"))
+
+	assert.Equal(t, ErrNotOwner, registry.Takeover(RoomEntry{RoomId: "room1", NodeId: "node3"}, "node2"))
+	assert.NoError(t, registry.Takeover(RoomEntry{RoomId: "room1", NodeId: "node2"}, "node1"))
+	assert.Equal(t, ErrNotOwner, registry.Heartbeat("room1", "node1"))
+
+	assert.Equal(t, ErrNotOwner, registry.Release("room1", "node1"))
+	assert.NoError(t, registry.Release("room1", "node2"))
+
+	found, err = registry.Lookup("room1")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	// A room which is not hosted anymore can be taken over by anyone.
+	assert.NoError(t, registry.Takeover(RoomEntry{RoomId: "room1", NodeId: "node3"}, "node1"))
+}
+
+func TestKeepAlive(t *testing.T) {
+	registry := NewRedisRegistry(newFakeRedis(), "", time.Minute)
+
+	_, _, err := registry.Claim(RoomEntry{RoomId: "room1", NodeId: "node1"})
+	assert.NoError(t, err)
+
+	lost := make(chan struct{})
+	stop := KeepAlive(registry, "room1", "node1", time.Millisecond, func() { close(lost) })
+	defer stop()
+
+	assert.NoError(t, registry.Takeover(RoomEntry{RoomId: "room1", NodeId: "node2"}, "node1"))
+
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("onLost not called")
+	}
+}
+
+// countingRegistry counts the heartbeats sent to a Registry.
+type countingRegistry struct {
+	Registry
+	heartbeats chan string
+}
+
+func (r countingRegistry) Heartbeat(roomId, nodeId string) error {
+	err := r.Registry.Heartbeat(roomId, nodeId)
+	r.heartbeats <- nodeId
+
+	return err
+}
+
+func TestKeepAliveWithOptions_Clock(t *testing.T) {
+	registry := countingRegistry{
+		Registry:   NewRedisRegistry(newFakeRedis(), "", time.Minute),
+		heartbeats: make(chan string, 1),
+	}
+
+	_, _, err := registry.Claim(RoomEntry{RoomId: "room1", NodeId: "node1"})
+	assert.NoError(t, err)
+
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+	lost := make(chan struct{})
+
+	stop := KeepAliveWithOptions(registry, "room1", "node1", KeepAliveOptions{
+		Interval: 10 * time.Second,
+		OnLost:   func() { close(lost) },
+		Clock:    fakeClock,
+	})
+	defer stop()
+
+	fakeClock.Advance(9 * time.Second)
+
+	select {
+	case <-registry.heartbeats:
+		t.Fatal("heartbeat sent before the interval")
+	default:
+	}
+
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, "node1", <-registry.heartbeats)
+
+	assert.NoError(t, registry.Takeover(RoomEntry{RoomId: "room1", NodeId: "node2"}, "node1"))
+	fakeClock.Advance(10 * time.Second)
+	assert.Equal(t, "node1", <-registry.heartbeats)
+	<-lost
+}
@@ -0,0 +1,107 @@
+// Package registry maps rooms to the node, worker and router hosting them, so a
+// clustering layer can route joins to the correct node.
+package registry
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+// ErrNotOwner is returned when a node updates a room owned by another node.
+var ErrNotOwner = errors.New("room is owned by another node")
+
+// RoomEntry describes where a room is hosted.
+type RoomEntry struct {
+	RoomId    string `json:"roomId"`
+	NodeId    string `json:"nodeId"`
+	Host      string `json:"host"`
+	WorkerPid int    `json:"workerPid"`
+	RouterId  string `json:"routerId"`
+	// UpdatedAt is the time of the last heartbeat in milliseconds.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// Registry stores room entries with a TTL, an entry disappears if its owner
+// stops sending heartbeats.
+type Registry interface {
+	// Claim stores the entry unless the room is already hosted, in which case
+	// the existing entry is returned with claimed false.
+	Claim(entry RoomEntry) (current RoomEntry, claimed bool, err error)
+	// Lookup returns the entry of the room, nil if it is not hosted.
+	Lookup(roomId string) (*RoomEntry, error)
+	// Heartbeat extends the TTL of a room owned by the given node.
+	Heartbeat(roomId, nodeId string) error
+	// Takeover replaces the entry of a room if it is still owned by
+	// previousNodeId or not hosted anymore.
+	Takeover(entry RoomEntry, previousNodeId string) error
+	// Release removes a room owned by the given node.
+	Release(roomId, nodeId string) error
+}
+
+// KeepAliveOptions are the options of KeepAliveWithOptions().
+type KeepAliveOptions struct {
+	// Interval between heartbeats.
+	Interval time.Duration
+	// OnLost, if given, is called once the room is owned by another node,
+	// after which heartbeats are stopped.
+	OnLost func()
+	// Clock of the heartbeats, default clock.System.
+	Clock clock.Clock
+}
+
+// KeepAlive sends heartbeats for the room every interval until stop is called.
+// onLost, if given, is called once the room is owned by another node, after
+// which heartbeats are stopped.
+func KeepAlive(registry Registry, roomId, nodeId string, interval time.Duration, onLost func()) (stop func()) {
+	return KeepAliveWithOptions(registry, roomId, nodeId, KeepAliveOptions{
+		Interval: interval,
+		OnLost:   onLost,
+	})
+}
+
+// KeepAliveWithOptions is KeepAlive() with a configurable clock.
+func KeepAliveWithOptions(registry Registry, roomId, nodeId string, options KeepAliveOptions) (stop func()) {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	ticker := clock.OrSystem(options.Clock).NewTicker(options.Interval)
+
+	go func() {
+		defer close(doneCh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+			}
+
+			err := registry.Heartbeat(roomId, nodeId)
+
+			if err == ErrNotOwner {
+				if options.OnLost != nil {
+					options.OnLost()
+				}
+				return
+			}
+			if err != nil {
+				logger.Warnf("heartbeat failed [roomId:%s]: %s", roomId, err)
+			}
+		}
+	}()
+
+	return func() {
+		select {
+		case <-stopCh:
+		default:
+			close(stopCh)
+		}
+		<-doneCh
+	}
+}
+
+func nowMs() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
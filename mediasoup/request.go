@@ -0,0 +1,26 @@
+package mediasoup
+
+// request sends a request to the worker and decodes its response into T.
+func request[T any](channel *Channel, method string, internal interface{}, data ...interface{}) (result T, err error) {
+	err = channel.Request(method, internal, data...).Unmarshal(&result)
+
+	return
+}
+
+// WorkerRequest sends a request, possibly one not wrapped by this package yet,
+// to the worker process and decodes its response into T.
+//
+//	dump, err := WorkerRequest[struct{ Pid int }](worker, "worker.dump", nil)
+func WorkerRequest[T any](worker *Worker, method string, data interface{}) (T, error) {
+	worker.logger.Debugf("request() [method:%s]", method)
+
+	return request[T](worker.channel, method, nil, data)
+}
+
+// RouterRequest sends a request addressed to the Router to the worker process
+// and decodes its response into T.
+func RouterRequest[T any](router *Router, method string, data interface{}) (T, error) {
+	router.logger.Debugf("request() [method:%s]", method)
+
+	return request[T](router.channel, method, router.internal, data)
+}
@@ -0,0 +1,111 @@
+package mediasoup
+
+import (
+	"strings"
+	"time"
+)
+
+// Classes of the worker channel requests, see RequestTimeouts.
+const (
+	// RequestClassCheap are requests handled at once by the worker, such as
+	// pause, resume, close or requestKeyFrame.
+	RequestClassCheap = "cheap"
+	// RequestClassDefault are the requests of no other class.
+	RequestClassDefault = "default"
+	// RequestClassExpensive are requests whose cost grows with the size of
+	// the Router, such as dump, getStats or the creation of Transports.
+	RequestClassExpensive = "expensive"
+)
+
+// RequestTimeouts of the worker channel requests by class.
+type RequestTimeouts struct {
+	// Timeout of the cheap requests, default 15s.
+	Cheap time.Duration
+	// Timeout of the other requests, default 15s.
+	Default time.Duration
+	// Timeout of the expensive requests, default 15s.
+	Expensive time.Duration
+	// PerPendingRequest is added to the timeout for every pending request,
+	// default 100ms.
+	PerPendingRequest time.Duration
+	// Methods overrides the timeout of given methods, e.g.
+	// {"router.dump": time.Minute}.
+	Methods map[string]time.Duration
+}
+
+// DefaultRequestTimeouts returns the timeouts used if not configured, the
+// same for every class.
+func DefaultRequestTimeouts() RequestTimeouts {
+	return RequestTimeouts{
+		Cheap:             15 * time.Second,
+		Default:           15 * time.Second,
+		Expensive:         15 * time.Second,
+		PerPendingRequest: 100 * time.Millisecond,
+	}
+}
+
+// Validate checks that no timeout is negative.
+func (timeouts RequestTimeouts) Validate() error {
+	if timeouts.Cheap < 0 || timeouts.Default < 0 || timeouts.Expensive < 0 ||
+		timeouts.PerPendingRequest < 0 {
+		return NewTypeError("negative request timeout")
+	}
+
+	for method, timeout := range timeouts.Methods {
+		if timeout < 0 {
+			return NewTypeError("negative request timeout [method:%s]", method)
+		}
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout of a request given the number of pending
+// requests. Unset timeouts take their default value.
+func (timeouts RequestTimeouts) Timeout(method string, pending int) time.Duration {
+	defaults := DefaultRequestTimeouts()
+
+	timeout, ok := timeouts.Methods[method]
+
+	if !ok || timeout == 0 {
+		switch RequestClass(method) {
+		case RequestClassCheap:
+			timeout = durationOr(timeouts.Cheap, defaults.Cheap)
+		case RequestClassExpensive:
+			timeout = durationOr(timeouts.Expensive, defaults.Expensive)
+		default:
+			timeout = durationOr(timeouts.Default, defaults.Default)
+		}
+	}
+
+	perPending := durationOr(timeouts.PerPendingRequest, defaults.PerPendingRequest)
+
+	return timeout + time.Duration(pending)*perPending
+}
+
+// RequestClass returns the class of a worker channel request method.
+func RequestClass(method string) string {
+	_, action, _ := strings.Cut(method, ".")
+
+	switch {
+	case action == "dump", action == "getStats", strings.HasPrefix(action, "create"):
+		return RequestClassExpensive
+
+	case action == "pause", action == "resume", action == "close",
+		action == "requestKeyFrame", action == "setPreferredLayers",
+		action == "setPriority", action == "unsetPriority",
+		action == "setMaxIncomingBitrate":
+		return RequestClassCheap
+
+	default:
+		return RequestClassDefault
+	}
+}
+
+func durationOr(d, defaultValue time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,40 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestClass(t *testing.T) {
+	assert.Equal(t, RequestClassExpensive, RequestClass("router.dump"))
+	assert.Equal(t, RequestClassExpensive, RequestClass("transport.getStats"))
+	assert.Equal(t, RequestClassExpensive, RequestClass("router.createWebRtcTransport"))
+	assert.Equal(t, RequestClassCheap, RequestClass("consumer.pause"))
+	assert.Equal(t, RequestClassCheap, RequestClass("producer.resume"))
+	assert.Equal(t, RequestClassCheap, RequestClass("transport.close"))
+	assert.Equal(t, RequestClassDefault, RequestClass("transport.consume"))
+	assert.Equal(t, RequestClassDefault, RequestClass("foo"))
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	assert.Equal(t, 15*time.Second+200*time.Millisecond,
+		RequestTimeouts{}.Timeout("router.dump", 2))
+
+	timeouts := RequestTimeouts{
+		Cheap:     time.Second,
+		Expensive: time.Minute,
+		Methods:   map[string]time.Duration{"transport.consume": 5 * time.Second},
+	}
+	assert.NoError(t, timeouts.Validate())
+	assert.Equal(t, time.Second, timeouts.Timeout("consumer.pause", 0))
+	assert.Equal(t, time.Minute+100*time.Millisecond, timeouts.Timeout("router.dump", 1))
+	assert.Equal(t, 15*time.Second, timeouts.Timeout("transport.produce", 0))
+	assert.Equal(t, 5*time.Second, timeouts.Timeout("transport.consume", 0))
+
+	assert.IsType(t, NewTypeError(""), RequestTimeouts{Cheap: -1}.Validate())
+	assert.IsType(t, NewTypeError(""), RequestTimeouts{
+		Methods: map[string]time.Duration{"router.dump": -1},
+	}.Validate())
+}
@@ -1,22 +1,36 @@
 package mediasoup
 
 import (
-	uuid "github.com/satori/go.uuid"
+	"encoding/json"
+	"sort"
+	"sync"
+
 	"github.com/sirupsen/logrus"
 )
 
 type Router struct {
 	EventEmitter
-	logger                  logrus.FieldLogger
-	internal                internalData
-	data                    routerData
-	channel                 *Channel
+	logger         logrus.FieldLogger
+	internal       internalData
+	data           routerData
+	channel        *Channel
+	payloadChannel *PayloadChannel
+	// Guards transports, producers and rtpObservers, also read by other
+	// goroutines (e.g. WatchLeaks()).
+	entitiesLocker          sync.Mutex
 	transports              map[string]Transport
 	producers               map[string]*Producer
 	rtpObservers            map[string]RtpObserver
 	mapRouterPipeTransports map[*Router][]*PipeTransport
+	generateMappedSsrc      generateSsrcFunc
+	releaseMappedSsrc       func(ssrc uint32)
 	observer                EventEmitter
-	closed                  bool
+	// Guards closed and closing, also set by CloseGracefully() and read by
+	// other goroutines.
+	stateLocker sync.Mutex
+	closed      bool
+	// Set by CloseGracefully().
+	closing bool
 }
 
 func NewRouter(internal internalData, data routerData, channel *Channel) *Router {
@@ -24,6 +38,8 @@ func NewRouter(internal internalData, data routerData, channel *Channel) *Router
 
 	logger.Debug("constructor()")
 
+	generateMappedSsrc, releaseMappedSsrc := newRouterSsrcAllocator(data)
+
 	return &Router{
 		EventEmitter:            NewEventEmitter(AppLogger()),
 		logger:                  logger,
@@ -34,6 +50,8 @@ func NewRouter(internal internalData, data routerData, channel *Channel) *Router
 		producers:               make(map[string]*Producer),
 		rtpObservers:            make(map[string]RtpObserver),
 		mapRouterPipeTransports: make(map[*Router][]*PipeTransport),
+		generateMappedSsrc:      generateMappedSsrc,
+		releaseMappedSsrc:       releaseMappedSsrc,
 		observer:                NewEventEmitter(AppLogger()),
 	}
 }
@@ -45,48 +63,208 @@ func (router *Router) Id() string {
 
 // Whether the Router is closed.
 func (router *Router) Closed() bool {
+	router.stateLocker.Lock()
+	defer router.stateLocker.Unlock()
+
 	return router.closed
 }
 
+// markClosed sets the Router closed, returning false if it already was.
+func (router *Router) markClosed() bool {
+	router.stateLocker.Lock()
+	defer router.stateLocker.Unlock()
+
+	if router.closed {
+		return false
+	}
+	router.closed = true
+
+	return true
+}
+
 // RTC capabilities of the Router.
 func (router *Router) RtpCapabilities() RtpCapabilities {
 	return router.data.RtpCapabilities
 }
 
+// Custom app data.
+func (router *Router) AppData() interface{} {
+	return router.data.AppData
+}
+
+// Feature flags inherited from the Worker.
+func (router *Router) FeatureFlags() FeatureFlags {
+	return router.data.FeatureFlags
+}
+
+// Range of mapped SSRCs, nil if they are randomly generated.
+func (router *Router) MappedSsrcRange() *MappedSsrcRange {
+	return router.data.MappedSsrcRange
+}
+
 func (router *Router) Observer() EventEmitter {
 	return router.observer
 }
 
+// Open Transports of the Router, sorted by id.
+func (router *Router) Transports() []Transport {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	transports := make([]Transport, 0, len(router.transports))
+
+	for _, transport := range router.transports {
+		transports = append(transports, transport)
+	}
+
+	sort.Slice(transports, func(i, j int) bool {
+		return transports[i].Id() < transports[j].Id()
+	})
+
+	return transports
+}
+
+// Open Producers of the Router, sorted by id.
+func (router *Router) Producers() []*Producer {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	producers := make([]*Producer, 0, len(router.producers))
+
+	for _, producer := range router.producers {
+		producers = append(producers, producer)
+	}
+
+	sort.Slice(producers, func(i, j int) bool {
+		return producers[i].Id() < producers[j].Id()
+	})
+
+	return producers
+}
+
+// GetProducerById returns an open Producer of the Router, nil if not found.
+func (router *Router) GetProducerById(producerId string) *Producer {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	return router.producers[producerId]
+}
+
+func (router *Router) getTransportById(transportId string) Transport {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	return router.transports[transportId]
+}
+
+// addTransport tracks the Transport and its Producers until they close.
+func (router *Router) addTransport(transport Transport) {
+	router.entitiesLocker.Lock()
+	router.transports[transport.Id()] = transport
+	router.entitiesLocker.Unlock()
+
+	transport.On("@close", func() {
+		router.entitiesLocker.Lock()
+		defer router.entitiesLocker.Unlock()
+
+		delete(router.transports, transport.Id())
+	})
+	transport.On("@newproducer", func(producer *Producer) {
+		router.entitiesLocker.Lock()
+		defer router.entitiesLocker.Unlock()
+
+		router.producers[producer.Id()] = producer
+	})
+	transport.On("@producerclose", func(producer *Producer) {
+		router.entitiesLocker.Lock()
+		defer router.entitiesLocker.Unlock()
+
+		delete(router.producers, producer.Id())
+	})
+}
+
+func (router *Router) addRtpObserver(rtpObserver RtpObserver) {
+	router.entitiesLocker.Lock()
+	router.rtpObservers[rtpObserver.Id()] = rtpObserver
+	router.entitiesLocker.Unlock()
+
+	rtpObserver.On("@close", func() {
+		router.entitiesLocker.Lock()
+		defer router.entitiesLocker.Unlock()
+
+		delete(router.rtpObservers, rtpObserver.Id())
+	})
+}
+
+func (router *Router) getRtpObserverById(rtpObserverId string) RtpObserver {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	return router.rtpObservers[rtpObserverId]
+}
+
+// rtpObserverIds returns the ids of the RtpObservers of the Router.
+func (router *Router) rtpObserverIds() (ids []string) {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	for id := range router.rtpObservers {
+		ids = append(ids, id)
+	}
+
+	return
+}
+
+// takeRtpObservers empties the RtpObservers of the Router, returning them.
+func (router *Router) takeRtpObservers() (rtpObservers []RtpObserver) {
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	for _, rtpObserver := range router.rtpObservers {
+		rtpObservers = append(rtpObservers, rtpObserver)
+	}
+	router.rtpObservers = make(map[string]RtpObserver)
+
+	return
+}
+
+// takeTransports empties the Transports and Producers of the Router,
+// returning the Transports.
+func (router *Router) takeTransports() []Transport {
+	transports := router.Transports()
+
+	router.entitiesLocker.Lock()
+	defer router.entitiesLocker.Unlock()
+
+	router.transports = make(map[string]Transport)
+	router.producers = make(map[string]*Producer)
+
+	return transports
+}
+
 // Close the Router.
 func (router *Router) Close() (err error) {
-	if router.closed {
+	if !router.markClosed() {
 		return
 	}
 
 	router.logger.Debug("close()")
 
-	router.closed = true
-
 	resp := router.channel.Request("router.close", router.internal)
 
 	if err = resp.Err(); err != nil {
 		return
 	}
 
-	// Close every Transport.
-	for _, transport := range router.transports {
+	// Close every Transport and clear the Producers map.
+	for _, transport := range router.takeTransports() {
 		transport.routerClosed()
 	}
-	router.transports = make(map[string]Transport)
-
-	// Clear the Producers map.
-	router.producers = make(map[string]*Producer)
 
 	// Close every RtpObserver.
-	for _, rtpObserver := range router.rtpObservers {
+	for _, rtpObserver := range router.takeRtpObservers() {
 		rtpObserver.routerClosed()
 	}
-	router.rtpObservers = make(map[string]RtpObserver)
 
 	// Clear map of Router/PipeTransports.
 	router.mapRouterPipeTransports = make(map[*Router][]*PipeTransport)
@@ -101,28 +279,21 @@ func (router *Router) Close() (err error) {
 
 // Worker was closed.
 func (router *Router) workerClosed() {
-	if router.closed {
+	if !router.markClosed() {
 		return
 	}
 
 	router.logger.Debug("workerClosed()")
 
-	router.closed = true
-
-	// Close every Transport.
-	for _, transport := range router.transports {
+	// Close every Transport and clear the Producers map.
+	for _, transport := range router.takeTransports() {
 		transport.routerClosed()
 	}
-	router.transports = make(map[string]Transport)
-
-	// Clear the Producers map.
-	router.producers = make(map[string]*Producer)
 
 	// Close every RtpObserver.
-	for _, rtpObserver := range router.rtpObservers {
+	for _, rtpObserver := range router.takeRtpObservers() {
 		rtpObserver.routerClosed()
 	}
-	router.rtpObservers = make(map[string]RtpObserver)
 
 	// Clear map of Router/PipeTransports.
 	router.mapRouterPipeTransports = make(map[*Router][]*PipeTransport)
@@ -139,7 +310,8 @@ func (router *Router) workerClosed() {
 func (router *Router) Dump() Response {
 	router.logger.Debug("dump()")
 
-	return router.channel.Request("router.dump", router.internal)
+	return dumpWithAppData(
+		router.channel.Request("router.dump", router.internal), router.data.AppData)
 }
 
 /**
@@ -152,6 +324,8 @@ func (router *Router) Dump() Response {
  * @param {Boolean} [enableTcp=false] - Enable TCP.
  * @param {Boolean} [preferUdp=false] - Prefer UDP.
  * @param {Boolean} [preferTcp=false] - Prefer TCP.
+ * @param {Number} [initialAvailableOutgoingBitrate] - Initial available
+ *   outgoing bitrate (in bps).
  * @param {Object} [appData={}] - Custom app data.
  */
 func (router *Router) CreateWebRtcTransport(
@@ -159,6 +333,11 @@ func (router *Router) CreateWebRtcTransport(
 ) (transport *WebRtcTransport, err error) {
 	router.logger.Debug("createWebRtcTransport()")
 
+	if router.Closing() {
+		err = NewInvalidStateError("Router closing")
+		return
+	}
+
 	if params.AppData == nil {
 		params.AppData = H{}
 	}
@@ -168,7 +347,8 @@ func (router *Router) CreateWebRtcTransport(
 	}
 
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.newId(IdEntityTransport)
+	internal.Trace = router.internal.Trace.merge(appDataLabels(params.AppData)).merge(params.TraceIds)
 	reqData := params
 	reqData.AppData = nil
 
@@ -187,20 +367,19 @@ func (router *Router) CreateWebRtcTransport(
 			return router.data.RtpCapabilities
 		},
 		GetProducerById: func(producerId string) *Producer {
-			return router.producers[producerId]
+			return router.GetProducerById(producerId)
 		},
+		GenerateMappedSsrc:     router.generateMappedSsrc,
+		ReleaseMappedSsrc:      router.releaseMappedSsrc,
+		IsRouterClosing:        router.Closing,
+		FeatureFlags:           router.data.FeatureFlags,
+		HeaderExtensionMode:    router.data.HeaderExtensionMode,
+		ConsumeTokenValidator:  router.data.ConsumeTokenValidator,
+		ProtectedConsumePolicy: router.data.ProtectedConsumePolicy,
+		IdGenerator:            router.data.IdGenerator,
 	})
 
-	router.transports[transport.Id()] = transport
-	transport.On("@close", func() {
-		delete(router.transports, transport.Id())
-	})
-	transport.On("@newproducer", func(producer *Producer) {
-		router.producers[producer.Id()] = producer
-	})
-	transport.On("@producerclose", func(producer *Producer) {
-		delete(router.producers, producer.Id())
-	})
+	router.addTransport(transport)
 
 	// Emit observer event.
 	router.observer.SafeEmit("newtransport", transport)
@@ -211,6 +390,19 @@ func (router *Router) CreateWebRtcTransport(
 /**
  * Create a PlainRtpTransport.
  *
+ * Deprecated: use CreatePlainTransport.
+ */
+func (router *Router) CreatePlainRtpTransport(
+	params CreatePlainRtpTransportParams,
+) (transport *PlainRtpTransport, err error) {
+	deprecated("Router.CreatePlainRtpTransport", "Router.CreatePlainTransport")
+
+	return router.CreatePlainTransport(params)
+}
+
+/**
+ * Create a PlainTransport.
+ *
  * @param {String|Object} listenIp - Listen IP string or an object with ip and
  *   optional announcedIp string.
  * @param {Boolean} [rtcpMux=true] - Use RTCP-mux.
@@ -220,12 +412,20 @@ func (router *Router) CreateWebRtcTransport(
  * @param {Boolean} [multiSource=false] - Whether RTP/RTCP from different remote
  *   IPs:ports is allowed. If set, the transport will just be valid for receiving
  *   media (consume() cannot be called on it) and connect() must not be called.
+ * @param {Boolean} [enableSrtp=false] - Whether SRTP is used.
+ * @param {String} [srtpCryptoSuite='AES_CM_128_HMAC_SHA1_80'] - SRTP crypto
+ *   suite, if enableSrtp.
  * @param {Object} [appData={}] - Custom app data.
  */
-func (router *Router) CreatePlainRtpTransport(
-	params CreatePlainRtpTransportParams,
-) (transport *PlainRtpTransport, err error) {
-	router.logger.Debug("createPlainRtpTransport()")
+func (router *Router) CreatePlainTransport(
+	params CreatePlainTransportParams,
+) (transport *PlainTransport, err error) {
+	router.logger.Debug("createPlainTransport()")
+
+	if router.Closing() {
+		err = NewInvalidStateError("Router closing")
+		return
+	}
 
 	if params.AppData == nil {
 		params.AppData = H{}
@@ -234,9 +434,24 @@ func (router *Router) CreatePlainRtpTransport(
 		err = NewTypeError("if given, appData must be an object")
 		return
 	}
+	if params.EnableSrtp {
+		if len(params.SrtpCryptoSuite) == 0 {
+			params.SrtpCryptoSuite = defaultSrtpCryptoSuite
+		}
+		if _, ok := srtpKeyLengths[params.SrtpCryptoSuite]; !ok {
+			err = NewTypeError("invalid SRTP crypto suite [cryptoSuite:%s]", params.SrtpCryptoSuite)
+			return
+		}
+	}
+
+	releasePort, err := holdListenPort(params.PortPool, &params.ListenIp)
+	if err != nil {
+		return
+	}
 
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.newId(IdEntityTransport)
+	internal.Trace = router.internal.Trace.merge(appDataLabels(params.AppData)).merge(params.TraceIds)
 	reqData := params
 	reqData.AppData = nil
 
@@ -244,6 +459,8 @@ func (router *Router) CreatePlainRtpTransport(
 
 	var data PlainTransportData
 	if err = resp.Unmarshal(&data); err != nil {
+		releasePort()
+		err = listenPortError(params.ListenIp, err)
 		return
 	}
 
@@ -255,20 +472,20 @@ func (router *Router) CreatePlainRtpTransport(
 			return router.data.RtpCapabilities
 		},
 		GetProducerById: func(producerId string) *Producer {
-			return router.producers[producerId]
+			return router.GetProducerById(producerId)
 		},
+		GenerateMappedSsrc:     router.generateMappedSsrc,
+		ReleaseMappedSsrc:      router.releaseMappedSsrc,
+		IsRouterClosing:        router.Closing,
+		FeatureFlags:           router.data.FeatureFlags,
+		HeaderExtensionMode:    router.data.HeaderExtensionMode,
+		ConsumeTokenValidator:  router.data.ConsumeTokenValidator,
+		ProtectedConsumePolicy: router.data.ProtectedConsumePolicy,
+		IdGenerator:            router.data.IdGenerator,
 	})
 
-	router.transports[transport.Id()] = transport
-	transport.On("@close", func() {
-		delete(router.transports, transport.Id())
-	})
-	transport.On("@newproducer", func(producer *Producer) {
-		router.producers[producer.Id()] = producer
-	})
-	transport.On("@producerclose", func(producer *Producer) {
-		delete(router.producers, producer.Id())
-	})
+	router.addTransport(transport)
+	transport.Observer().Once("close", releasePort)
 
 	// Emit observer event.
 	router.observer.SafeEmit("newtransport", transport)
@@ -281,6 +498,9 @@ func (router *Router) CreatePlainRtpTransport(
  *
  * @param {String|Object} listenIp - Listen IP string or an object with ip and optional
  *   announcedIp string.
+ * @param {Boolean} [enableSrtp=false] - Whether SRTP is used.
+ * @param {String} [srtpCryptoSuite='AES_CM_128_HMAC_SHA1_80'] - SRTP crypto
+ *   suite, if enableSrtp.
  * @param {Object} [appData={}] - Custom app data.
  */
 func (router *Router) CreatePipeTransport(
@@ -288,8 +508,28 @@ func (router *Router) CreatePipeTransport(
 ) (transport *PipeTransport, err error) {
 	router.logger.Debug("createPipeTransport()")
 
+	if router.Closing() {
+		err = NewInvalidStateError("Router closing")
+		return
+	}
+	if params.EnableSrtp {
+		if len(params.SrtpCryptoSuite) == 0 {
+			params.SrtpCryptoSuite = defaultSrtpCryptoSuite
+		}
+		if _, ok := srtpKeyLengths[params.SrtpCryptoSuite]; !ok {
+			err = NewTypeError("invalid SRTP crypto suite [cryptoSuite:%s]", params.SrtpCryptoSuite)
+			return
+		}
+	}
+
+	releasePort, err := holdListenPort(params.PortPool, &params.ListenIp)
+	if err != nil {
+		return
+	}
+
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.newId(IdEntityTransport)
+	internal.Trace = router.internal.Trace.merge(appDataLabels(params.AppData)).merge(params.TraceIds)
 	reqData := params
 	reqData.AppData = nil
 
@@ -297,6 +537,8 @@ func (router *Router) CreatePipeTransport(
 
 	var data PipeTransportData
 	if err = resp.Unmarshal(&data); err != nil {
+		releasePort()
+		err = listenPortError(params.ListenIp, err)
 		return
 	}
 
@@ -308,21 +550,92 @@ func (router *Router) CreatePipeTransport(
 			return router.data.RtpCapabilities
 		},
 		GetProducerById: func(producerId string) *Producer {
-			return router.producers[producerId]
+			return router.GetProducerById(producerId)
 		},
+		GenerateMappedSsrc:     router.generateMappedSsrc,
+		ReleaseMappedSsrc:      router.releaseMappedSsrc,
+		IsRouterClosing:        router.Closing,
+		FeatureFlags:           router.data.FeatureFlags,
+		HeaderExtensionMode:    router.data.HeaderExtensionMode,
+		ConsumeTokenValidator:  router.data.ConsumeTokenValidator,
+		ProtectedConsumePolicy: router.data.ProtectedConsumePolicy,
+		IdGenerator:            router.data.IdGenerator,
 	})
 
-	router.transports[transport.Id()] = transport
-	transport.On("@close", func() {
-		delete(router.transports, transport.Id())
-	})
-	transport.On("@newproducer", func(producer *Producer) {
-		router.producers[producer.Id()] = producer
-	})
-	transport.On("@producerclose", func(producer *Producer) {
-		delete(router.producers, producer.Id())
+	router.addTransport(transport)
+	transport.Observer().Once("close", releasePort)
+
+	// Emit observer event.
+	router.observer.SafeEmit("newtransport", transport)
+
+	return
+}
+
+/**
+ * Create a DirectTransport, to produce and consume RTP from Go.
+ *
+ * @param {Number} [maxMessageSize=262144] - Max size of the sent messages.
+ * @param {Object} [appData={}] - Custom app data.
+ */
+func (router *Router) CreateDirectTransport(
+	params CreateDirectTransportParams,
+) (transport *DirectTransport, err error) {
+	router.logger.Debug("createDirectTransport()")
+
+	if router.Closing() {
+		err = NewInvalidStateError("Router closing")
+		return
+	}
+	if router.payloadChannel == nil {
+		err = NewUnsupportedError("Router has no PayloadChannel")
+		return
+	}
+
+	if params.MaxMessageSize == 0 {
+		params.MaxMessageSize = 262144
+	}
+
+	internal := router.internal
+	internal.TransportId = router.newId(IdEntityTransport)
+	internal.Trace = router.internal.Trace.merge(appDataLabels(params.AppData)).merge(params.TraceIds)
+	reqData := H{
+		"direct":         true,
+		"maxMessageSize": params.MaxMessageSize,
+	}
+
+	resp := router.channel.Request("router.createDirectTransport", internal, reqData)
+
+	var data DirectTransportData
+	if err = resp.Unmarshal(&data); err != nil {
+		return
+	}
+	if data.MaxMessageSize == 0 {
+		data.MaxMessageSize = params.MaxMessageSize
+	}
+
+	transport = NewDirectTransport(data, createTransportParams{
+		Internal:       internal,
+		Channel:        router.channel,
+		PayloadChannel: router.payloadChannel,
+		AppData:        params.AppData,
+		GetRouterRtpCapabilities: func() RtpCapabilities {
+			return router.data.RtpCapabilities
+		},
+		GetProducerById: func(producerId string) *Producer {
+			return router.GetProducerById(producerId)
+		},
+		GenerateMappedSsrc:     router.generateMappedSsrc,
+		ReleaseMappedSsrc:      router.releaseMappedSsrc,
+		IsRouterClosing:        router.Closing,
+		FeatureFlags:           router.data.FeatureFlags,
+		HeaderExtensionMode:    router.data.HeaderExtensionMode,
+		ConsumeTokenValidator:  router.data.ConsumeTokenValidator,
+		ProtectedConsumePolicy: router.data.ProtectedConsumePolicy,
+		IdGenerator:            router.data.IdGenerator,
 	})
 
+	router.addTransport(transport)
+
 	// Emit observer event.
 	router.observer.SafeEmit("newtransport", transport)
 
@@ -360,9 +673,9 @@ func (router *Router) PipeToRouter(
 		return
 	}
 
-	producer, ok := router.producers[params.ProducerId]
+	producer := router.GetProducerById(params.ProducerId)
 
-	if !ok {
+	if producer == nil {
 		err = NewTypeError("Producer not found")
 		return
 	}
@@ -388,6 +701,7 @@ func (router *Router) PipeToRouter(
 	} else {
 		createPipeTransportParams := CreatePipeTransportParams{
 			ListenIp: params.ListenIp,
+			PortPool: params.PortPool,
 		}
 
 		localPipeTransport, err = router.CreatePipeTransport(createPipeTransportParams)
@@ -453,6 +767,7 @@ func (router *Router) PipeToRouter(
 		RtpParameters: pipeConsumer.RtpParameters(),
 		AppData:       producer.AppData(),
 		Paused:        pipeConsumer.ProducerPaused(),
+		Protected:     producer.Protected(),
 	})
 	if err != nil {
 		return
@@ -476,7 +791,8 @@ func (router *Router) PipeToRouter(
  *                                  event.
  * @param {Number} [threshold=-80] - Minimum average volume (in dBvo from -127 to 0)
  *                                   for entries in the "volumes" event.
- * @param {Number} [interval=1000] - Interval in ms for checking audio volumes.
+ * @param {Number} [interval=1000] - Interval in ms for checking audio volumes
+ *                                   (from 250 to 5000).
  *
  */
 func (router *Router) CreateAudioLevelObserver(
@@ -492,8 +808,12 @@ func (router *Router) CreateAudioLevelObserver(
 		}
 	}
 
+	if err = params.Validate(); err != nil {
+		return
+	}
+
 	internal := router.internal
-	internal.RtpObserverId = uuid.NewV4().String()
+	internal.RtpObserverId = router.newId(IdEntityRtpObserver)
 
 	resp := router.channel.Request("router.createAudioLevelObserver", internal, params)
 
@@ -501,18 +821,65 @@ func (router *Router) CreateAudioLevelObserver(
 		return
 	}
 
-	rtpObserver = NewAudioLevelObserver(
+	audioLevelObserver := NewAudioLevelObserver(
 		internal,
 		router.channel,
 		func(producerId string) *Producer {
-			return router.producers[producerId]
+			return router.GetProducerById(producerId)
 		},
 	)
+	audioLevelObserver.settings = *params
 
-	router.rtpObservers[rtpObserver.Id()] = rtpObserver
-	rtpObserver.On("@close", func() {
-		delete(router.rtpObservers, rtpObserver.Id())
-	})
+	rtpObserver = audioLevelObserver
+
+	router.addRtpObserver(rtpObserver)
+
+	return
+}
+
+/**
+ * Create an ActiveSpeakerObserver, emitting the dominant speaker among the
+ * audio Producers added to it.
+ *
+ * @param {Number} [interval=300] - Interval in ms for checking the dominant
+ *                                  speaker (at least 100).
+ */
+func (router *Router) CreateActiveSpeakerObserver(
+	params *CreateActiveSpeakerObserverParams,
+) (rtpObserver RtpObserver, err error) {
+	router.logger.Debug("createActiveSpeakerObserver()")
+
+	if params == nil {
+		params = &CreateActiveSpeakerObserverParams{
+			Interval: 300,
+		}
+	}
+
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	internal := router.internal
+	internal.RtpObserverId = router.newId(IdEntityRtpObserver)
+
+	resp := router.channel.Request("router.createActiveSpeakerObserver", internal, params)
+
+	if err = resp.Err(); err != nil {
+		return
+	}
+
+	activeSpeakerObserver := NewActiveSpeakerObserver(
+		internal,
+		router.channel,
+		func(producerId string) *Producer {
+			return router.GetProducerById(producerId)
+		},
+	)
+	activeSpeakerObserver.settings = *params
+
+	rtpObserver = activeSpeakerObserver
+
+	router.addRtpObserver(rtpObserver)
 
 	return
 }
@@ -525,7 +892,7 @@ func (router *Router) CreateAudioLevelObserver(
  *
  */
 func (router *Router) CanConsume(producerId string, rtpCapabilities RtpCapabilities) bool {
-	producer := router.producers[producerId]
+	producer := router.GetProducerById(producerId)
 
 	if producer == nil {
 		router.logger.Errorf(`canConsume() | Producer with id "%s" not found`, producerId)
@@ -535,3 +902,54 @@ func (router *Router) CanConsume(producerId string, rtpCapabilities RtpCapabilit
 
 	return CanConsume(producer.ConsumableRtpParameters(), rtpCapabilities)
 }
+
+/**
+ * Send a raw request to the worker on behalf of the Router, so new worker
+ * methods can be used before this package wraps them. The routerId of
+ * internal is set to the Router id and the given transportId, producerId and
+ * rtpObserverId must belong to the Router.
+ */
+func (router *Router) RawRequest(method string, internal, data json.RawMessage) (json.RawMessage, error) {
+	router.logger.Debugf("rawRequest() [method:%s]", method)
+
+	internalMap := map[string]interface{}{}
+
+	if len(internal) > 0 {
+		if err := unmarshalUseNumber(internal, &internalMap); err != nil {
+			return nil, NewTypeError("internal must be an object")
+		}
+		// JSON null.
+		if internalMap == nil {
+			internalMap = map[string]interface{}{}
+		}
+	}
+
+	if routerId, ok := internalMap["routerId"]; ok && routerId != router.Id() {
+		return nil, NewTypeError(`internal routerId "%v" does not match`, routerId)
+	}
+	internalMap["routerId"] = router.Id()
+
+	if id, ok := internalMap["transportId"].(string); ok && router.getTransportById(id) == nil {
+		return nil, NewTypeError(`Transport with id "%s" not found`, id)
+	}
+	if id, ok := internalMap["producerId"].(string); ok && router.GetProducerById(id) == nil {
+		return nil, NewTypeError(`Producer with id "%s" not found`, id)
+	}
+	if id, ok := internalMap["rtpObserverId"].(string); ok && router.getRtpObserverById(id) == nil {
+		return nil, NewTypeError(`RtpObserver with id "%s" not found`, id)
+	}
+
+	var rsp Response
+
+	if len(data) > 0 {
+		rsp = router.channel.Request(method, internalMap, data)
+	} else {
+		rsp = router.channel.Request(method, internalMap)
+	}
+
+	if err := rsp.Err(); err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(rsp.Data()), nil
+}
@@ -0,0 +1,90 @@
+package mediasoup
+
+import (
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+// Whether the Router is being closed by CloseGracefully().
+func (router *Router) Closing() bool {
+	router.stateLocker.Lock()
+	defer router.stateLocker.Unlock()
+
+	return router.closing
+}
+
+// markClosing sets the Router closing, failing if it's closed or already
+// closing.
+func (router *Router) markClosing() error {
+	router.stateLocker.Lock()
+	defer router.stateLocker.Unlock()
+
+	if router.closed {
+		return NewInvalidStateError("Router closed")
+	}
+	if router.closing {
+		return NewInvalidStateError("Router already closing")
+	}
+	router.closing = true
+
+	return nil
+}
+
+/**
+ * Close the Router once the given delay has elapsed, e.g. for a maintenance
+ * window. The observer emits "closing" with the delay right away so the
+ * signaling can tell the clients to move, and from then on new Transports,
+ * Producers and Consumers are rejected with InvalidStateError. Existing media
+ * keeps flowing until the Router is closed.
+ *
+ * It blocks until the Router is closed, returning early if it's closed
+ * meanwhile (e.g. by Close() or because the Worker died). If closing it
+ * fails, its error is returned and the Router is no longer closing.
+ *
+ * @throws {InvalidStateError} if the Router is closed or already closing.
+ */
+func (router *Router) CloseGracefully(delay time.Duration) error {
+	router.logger.Debugf("closeGracefully() [delay:%s]", delay)
+
+	if err := router.markClosing(); err != nil {
+		return err
+	}
+
+	closedCh := make(chan struct{})
+
+	off := OnceSignal(router.observer, "close", func() {
+		close(closedCh)
+	})
+	defer off()
+
+	// Emit observer event.
+	router.observer.SafeEmit("closing", delay)
+
+	timer := clock.OrSystem(router.data.Clock).NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		err := router.Close()
+		if err != nil {
+			router.stateLocker.Lock()
+			router.closing = false
+			router.stateLocker.Unlock()
+		}
+
+		return err
+	case <-closedCh:
+		return nil
+	}
+}
+
+// checkRouterClosing rejects new Producers and Consumers while the Router is
+// closing gracefully.
+func (transport *baseTransport) checkRouterClosing() error {
+	if transport.isRouterClosing != nil && transport.isRouterClosing() {
+		return NewInvalidStateError("Router closing")
+	}
+
+	return nil
+}
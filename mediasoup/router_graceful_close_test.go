@@ -0,0 +1,97 @@
+package mediasoup
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterCloseGracefully(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{Clock: fakeClock}, newTestChannel())
+
+	closingDelay := time.Duration(0)
+
+	router.Observer().On("closing", func(delay time.Duration) {
+		closingDelay = delay
+		assert.True(t, router.Closing())
+
+		_, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{})
+		assert.IsType(t, NewInvalidStateError(""), err)
+	})
+
+	go func() {
+		fakeClock.BlockUntil(1)
+		assert.False(t, router.Closed())
+		fakeClock.Advance(time.Minute)
+	}()
+
+	assert.NoError(t, router.CloseGracefully(time.Minute))
+	assert.Equal(t, time.Minute, closingDelay)
+	assert.True(t, router.Closed())
+	assert.IsType(t, NewInvalidStateError(""), router.CloseGracefully(0))
+}
+
+func TestRouterCloseGracefully_ClosedMeanwhile(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{Clock: fakeClock}, newTestChannel())
+
+	go func() {
+		fakeClock.BlockUntil(1)
+		router.Close()
+	}()
+
+	assert.NoError(t, router.CloseGracefully(time.Minute))
+	assert.True(t, router.Closed())
+	// The delay timer is stopped.
+	assert.Zero(t, fakeClock.Waiters())
+}
+
+func TestTransportCheckRouterClosing(t *testing.T) {
+	closing := false
+	transport := newTransport(createTransportParams{
+		IsRouterClosing: func() bool { return closing },
+	})
+
+	assert.NoError(t, transport.checkRouterClosing())
+
+	closing = true
+
+	assert.IsType(t, NewInvalidStateError(""), transport.checkRouterClosing())
+
+	_, err := transport.Produce(transportProduceParams{Kind: MediaKindAudio})
+	assert.IsType(t, NewInvalidStateError(""), err)
+}
+
+func TestRouterCloseGracefully_Concurrent(t *testing.T) {
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		router.CloseGracefully(time.Millisecond)
+	}()
+
+	for !router.Closed() {
+		router.Closing()
+	}
+
+	<-done
+}
+
+func TestRouterCloseGracefully_CloseFails(t *testing.T) {
+	socket, workerSocket := net.Pipe()
+	channel := NewChannel(socket, 0)
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, channel)
+
+	workerSocket.Close()
+	<-channel.closeCh
+
+	assert.Error(t, router.CloseGracefully(time.Millisecond))
+	assert.False(t, router.Closing())
+}
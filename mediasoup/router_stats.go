@@ -0,0 +1,139 @@
+package mediasoup
+
+import "time"
+
+// RouterAggregateStats sums up the stats of every entity of a Router.
+type RouterAggregateStats struct {
+	RouterId  string `json:"routerId"`
+	Timestamp int64  `json:"timestamp"`
+	// Number of Transports.
+	Transports int `json:"transports"`
+	// Number of Producers by kind.
+	Producers map[MediaKind]int `json:"producers"`
+	// Number of Consumers.
+	Consumers       int    `json:"consumers"`
+	IncomingBitrate uint32 `json:"incomingBitrate"`
+	OutgoingBitrate uint32 `json:"outgoingBitrate"`
+	BytesReceived   uint64 `json:"bytesReceived"`
+	BytesSent       uint64 `json:"bytesSent"`
+	PacketsReceived uint64 `json:"packetsReceived"`
+	// Average score of the Consumers which reported one, 0 if none did.
+	AverageConsumerScore float64 `json:"averageConsumerScore"`
+	// Number of worker requests issued.
+	Requests int `json:"requests"`
+	// Partial is true if MaxRequests was reached and PacketsReceived does not
+	// cover every Producer.
+	Partial bool `json:"partial"`
+}
+
+type AggregateStatsOptions struct {
+	// MaxRequests bounds the number of worker requests, default 100. Every
+	// Transport costs one request, and packet counts cost one request per
+	// Producer.
+	MaxRequests int
+}
+
+// GetAggregateStats computes the totals of the Router in one call. Bitrates
+// and bytes come from a getStats request per Transport, Producer and Consumer
+// counts and scores from the local state, and packets from a getStats request
+// per Producer while the request budget allows it.
+func (router *Router) GetAggregateStats(options ...AggregateStatsOptions) (stats RouterAggregateStats, err error) {
+	router.logger.Debug("getAggregateStats()")
+
+	opts := AggregateStatsOptions{MaxRequests: 100}
+
+	if len(options) > 0 && options[0].MaxRequests > 0 {
+		opts.MaxRequests = options[0].MaxRequests
+	}
+
+	var (
+		transportStats []TransportStat
+		consumerScores []uint8
+		streamStats    []RtpStreamStat
+	)
+
+	stats.RouterId = router.Id()
+	stats.Producers = map[MediaKind]int{}
+
+	for _, transport := range router.Transports() {
+		stats.Transports++
+
+		for _, consumer := range transport.consumerList() {
+			stats.Consumers++
+
+			if score := consumer.Score(); score != nil {
+				consumerScores = append(consumerScores, score.Consumer)
+			}
+		}
+
+		if stats.Requests >= opts.MaxRequests {
+			stats.Partial = true
+			continue
+		}
+
+		var s []TransportStat
+
+		stats.Requests++
+
+		if s, err = transport.GetStats(); err != nil {
+			return
+		}
+
+		transportStats = append(transportStats, s...)
+	}
+
+	for _, producer := range router.Producers() {
+		stats.Producers[producer.Kind()]++
+
+		if stats.Requests >= opts.MaxRequests {
+			stats.Partial = true
+			continue
+		}
+
+		var s []RtpStreamStat
+
+		stats.Requests++
+
+		if err = producer.GetStats().Unmarshal(&s); err != nil {
+			return
+		}
+
+		streamStats = append(streamStats, s...)
+	}
+
+	aggregateRouterStats(&stats, transportStats, streamStats, consumerScores)
+
+	return
+}
+
+func aggregateRouterStats(
+	stats *RouterAggregateStats,
+	transportStats []TransportStat,
+	streamStats []RtpStreamStat,
+	consumerScores []uint8,
+) {
+	stats.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+
+	for _, s := range transportStats {
+		stats.IncomingBitrate += s.RecvBitrate
+		stats.OutgoingBitrate += s.SendBitrate
+		stats.BytesReceived += uint64(s.BytesReceived)
+		stats.BytesSent += uint64(s.BytesSent)
+	}
+
+	for _, s := range streamStats {
+		if s.Type == "inbound-rtp" {
+			stats.PacketsReceived += uint64(s.PacketCount)
+		}
+	}
+
+	if len(consumerScores) > 0 {
+		var total int
+
+		for _, score := range consumerScores {
+			total += int(score)
+		}
+
+		stats.AverageConsumerScore = float64(total) / float64(len(consumerScores))
+	}
+}
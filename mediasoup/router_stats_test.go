@@ -0,0 +1,32 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateRouterStats(t *testing.T) {
+	stats := RouterAggregateStats{}
+
+	aggregateRouterStats(&stats,
+		[]TransportStat{
+			{RecvBitrate: 1000, SendBitrate: 3000, BytesReceived: 10, BytesSent: 30},
+			{RecvBitrate: 500, SendBitrate: 0, BytesReceived: 5},
+		},
+		[]RtpStreamStat{
+			{Type: "inbound-rtp", PacketCount: 100},
+			{Type: "inbound-rtp", PacketCount: 50},
+			{Type: "outbound-rtp", PacketCount: 1000},
+		},
+		[]uint8{10, 7},
+	)
+
+	assert.EqualValues(t, 1500, stats.IncomingBitrate)
+	assert.EqualValues(t, 3000, stats.OutgoingBitrate)
+	assert.EqualValues(t, 15, stats.BytesReceived)
+	assert.EqualValues(t, 30, stats.BytesSent)
+	assert.EqualValues(t, 150, stats.PacketsReceived)
+	assert.Equal(t, 8.5, stats.AverageConsumerScore)
+	assert.NotZero(t, stats.Timestamp)
+}
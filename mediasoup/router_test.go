@@ -1,7 +1,11 @@
 package mediasoup
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
 	"github.com/stretchr/testify/assert"
@@ -109,6 +113,44 @@ func TestCreateRouter_InvalidStateError(t *testing.T) {
 	assert.Error(t, err, NewInvalidStateError(""))
 }
 
+func TestCreateRouter_InvalidCapabilitiesSendNoRequest(t *testing.T) {
+	// A request to the closed channel would fail with an InvalidStateError.
+	worker := newTestWorker()
+	worker.channel.Close()
+
+	_, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.IsType(t, NewInvalidStateError(""), err)
+
+	_, err = worker.CreateRouter(testRouterMediaCodecs, WithCustomHeaderExtensions(RtpHeaderExtension{
+		Kind:        "audio",
+		Uri:         "urn:ietf:params:rtp-hdrext:custom",
+		PreferredId: 1,
+	}))
+	assert.IsType(t, NewTypeError(""), err)
+	assert.Contains(t, err.Error(), "custom header extension conflicts")
+
+	_, err = worker.CreateRouter([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2, PreferredPayloadType: 100},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 100},
+	})
+	assert.IsType(t, NewTypeError(""), err)
+	assert.Contains(t, err.Error(), "duplicated preferredPayloadType 100")
+
+	twoByteExt := WithCustomHeaderExtensions(RtpHeaderExtension{
+		Kind:        "video",
+		Uri:         "urn:ietf:params:rtp-hdrext:custom",
+		PreferredId: 20,
+	})
+
+	_, err = worker.CreateRouter(testRouterMediaCodecs, twoByteExt)
+	assert.IsType(t, NewTypeError(""), err)
+	assert.Contains(t, err.Error(), "needs two-byte header extensions")
+
+	_, err = worker.CreateRouter(testRouterMediaCodecs, twoByteExt, WithExtmapAllowMixed())
+	assert.IsType(t, NewInvalidStateError(""), err)
+	assert.Empty(t, worker.routers)
+}
+
 func TestRouterClose_Succeeds(t *testing.T) {
 	worker := CreateTestWorker()
 	router, _ := worker.CreateRouter(testRouterMediaCodecs)
@@ -133,3 +175,283 @@ func TestRouterEmitsWorkCloseIfWorkerIsClosed(t *testing.T) {
 	assert.Equal(t, 1, called)
 	assert.True(t, router.Closed())
 }
+
+func TestCreateRouter_MappedSsrcRange(t *testing.T) {
+	worker := CreateTestWorker()
+	router, err := worker.CreateRouter(testRouterMediaCodecs, WithMappedSsrcRange(1000000, 1000001))
+	assert.NoError(t, err)
+	assert.Equal(t, &MappedSsrcRange{Min: 1000000, Max: 1000001}, router.MappedSsrcRange())
+
+	transport, _ := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+
+	var producers []*Producer
+
+	for i := 0; i < 2; i++ {
+		producer, err := transport.Produce(transportProduceParams{
+			Kind: "audio",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{MimeType: "audio/opus", PayloadType: 111, ClockRate: 48000, Channels: 2},
+				},
+				Encodings: []RtpEncoding{{Ssrc: uint32(11111111 + i)}},
+			},
+		})
+		assert.NoError(t, err)
+
+		producers = append(producers, producer)
+	}
+
+	ssrcs := map[uint32]bool{}
+	for _, producer := range producers {
+		ssrcs[producer.ConsumableRtpParameters().Encodings[0].Ssrc] = true
+	}
+	assert.Equal(t, map[uint32]bool{1000000: true, 1000001: true}, ssrcs)
+
+	// The range is exhausted while both Producers are open.
+	_, err = transport.Produce(transportProduceParams{
+		Kind: "audio",
+		RtpParameters: RtpParameters{
+			Codecs: []RtpCodecCapability{
+				{MimeType: "audio/opus", PayloadType: 111, ClockRate: 48000, Channels: 2},
+			},
+			Encodings: []RtpEncoding{{Ssrc: 11111113}},
+		},
+	})
+	assert.IsType(t, NewInvalidStateError(""), err)
+
+	worker.Close()
+}
+
+func TestCreateRouter_InvalidMappedSsrcRange(t *testing.T) {
+	worker := CreateTestWorker()
+	_, err := worker.CreateRouter(testRouterMediaCodecs, WithMappedSsrcRange(2000, 1000))
+
+	assert.IsType(t, err, NewTypeError(""))
+
+	worker.Close()
+}
+
+func TestCreateRouter_SsrcAllocatorWithRange(t *testing.T) {
+	worker := CreateTestWorker()
+	_, err := worker.CreateRouter(testRouterMediaCodecs, WithMappedSsrcRange(1000, 2000),
+		WithSsrcAllocator(NewSequentialSsrcAllocator(1000)))
+
+	assert.IsType(t, err, NewTypeError(""))
+
+	worker.Close()
+}
+
+func TestCreateRouter_AppData(t *testing.T) {
+	worker := CreateTestWorker(WithAppData(H{"region": "eu"}))
+	defer worker.Close()
+
+	assert.Equal(t, H{"region": "eu"}, worker.AppData())
+	assert.JSONEq(t,
+		fmt.Sprintf(`{ "pid": %d, "routerIds": [], "appData": { "region": "eu" } }`, worker.Pid()),
+		string(worker.Dump().Data()))
+
+	router, err := worker.CreateRouter(testRouterMediaCodecs, WithRouterAppData(H{"roomId": "room1"}))
+	assert.NoError(t, err)
+	assert.Equal(t, H{"roomId": "room1"}, router.AppData())
+
+	var dump struct {
+		Id      string
+		AppData H
+	}
+	assert.NoError(t, router.Dump().Unmarshal(&dump))
+	assert.Equal(t, router.Id(), dump.Id)
+	assert.Equal(t, H{"roomId": "room1"}, dump.AppData)
+
+	_, err = worker.CreateRouter(testRouterMediaCodecs, WithRouterAppData("room1"))
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestCreateRouter_FeatureFlags(t *testing.T) {
+	worker := CreateTestWorker(WithFeatureFlags(FeatureFlags{StrictValidation: true}))
+	defer worker.Close()
+
+	assert.True(t, worker.FeatureFlags().StrictValidation)
+
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
+	assert.Equal(t, worker.FeatureFlags(), router.FeatureFlags())
+}
+
+func TestRouterRawRequest(t *testing.T) {
+	worker := CreateTestWorker()
+	defer worker.Close()
+
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
+
+	data, err := router.RawRequest("router.dump", nil, nil)
+	assert.NoError(t, err)
+
+	var dump struct{ Id string }
+	assert.NoError(t, json.Unmarshal(data, &dump))
+	assert.Equal(t, router.Id(), dump.Id)
+
+	_, err = router.RawRequest("router.dump", json.RawMessage(`{"routerId":"foo"}`), nil)
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = router.RawRequest("transport.dump", json.RawMessage(`{"transportId":"foo"}`), nil)
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = router.RawRequest("router.dump", json.RawMessage(`[]`), nil)
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestRouterRawRequest_NullInternal(t *testing.T) {
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	data, err := router.RawRequest("router.dump", json.RawMessage(`null`), nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data)
+}
+
+func TestRouterGetAggregateStats(t *testing.T) {
+	ns := setupPipeTest(t)
+	defer ns.router1.Close()
+	defer ns.router2.Close()
+
+	stats, err := ns.router1.GetAggregateStats()
+	assert.NoError(t, err)
+	assert.Equal(t, ns.router1.Id(), stats.RouterId)
+	assert.Equal(t, 1, stats.Transports)
+	assert.Equal(t, map[MediaKind]int{MediaKindAudio: 1, MediaKindVideo: 1}, stats.Producers)
+	assert.Equal(t, 3, stats.Requests)
+	assert.False(t, stats.Partial)
+
+	stats, err = ns.router1.GetAggregateStats(AggregateStatsOptions{MaxRequests: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Requests)
+	assert.True(t, stats.Partial)
+}
+
+func TestCreateRouter_ConsumeTokenValidator(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs,
+		WithConsumeTokenValidator(NewConsumeTokenValidator("secret")))
+	assert.NoError(t, err)
+	defer router.Close()
+
+	transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(audioProducerParameters)
+	assert.NoError(t, err)
+
+	_, err = transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: router.RtpCapabilities(),
+	})
+	assert.IsType(t, NewUnauthorizedError(""), err)
+
+	consumer, err := transport.Consume(transportConsumeParams{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: router.RtpCapabilities(),
+		Token:           GenerateConsumeToken("secret", producer.Id(), time.Now().Add(time.Minute)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, producer.Id(), consumer.ProducerId())
+}
+
+func TestRouterCloseAllTransports(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	var transports []Transport
+
+	for i := 0; i < 3; i++ {
+		transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+			ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+		})
+		assert.NoError(t, err)
+		transports = append(transports, transport)
+	}
+
+	producer, err := transports[0].Produce(audioProducerParameters)
+	assert.NoError(t, err)
+
+	var closeOrder []string
+
+	for _, transport := range transports {
+		transport := transport
+		transport.Observer().On("close", func() {
+			closeOrder = append(closeOrder, transport.Id())
+		})
+	}
+
+	onTransportsClose := NewMockFunc(t)
+	router.Observer().On("transportsclose", onTransportsClose.Fn())
+
+	result := router.CloseAllTransports()
+	assert.Empty(t, result.Errors)
+	assert.Len(t, result.Closed, 3)
+	assert.Equal(t, result.Closed, closeOrder)
+	assert.True(t, sort.StringsAreSorted(result.Closed))
+	onTransportsClose.ExpectCalledTimes(1)
+
+	for _, transport := range transports {
+		assert.True(t, transport.Closed())
+	}
+	assert.True(t, producer.Closed())
+
+	stats, err := router.GetAggregateStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Transports)
+
+	result = router.CloseAllTransports()
+	assert.Empty(t, result.Closed)
+}
+
+func TestRouterTransportsAndProducers(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	assert.Empty(t, router.Transports())
+	assert.Empty(t, router.Producers())
+
+	transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(audioProducerParameters)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Transport{transport}, router.Transports())
+	assert.Equal(t, []*Producer{producer}, router.Producers())
+	assert.Equal(t, producer, router.GetProducerById(producer.Id()))
+
+	producer.Close()
+	assert.Empty(t, router.Producers())
+	assert.Nil(t, router.GetProducerById(producer.Id()))
+
+	transport.Close()
+	assert.Empty(t, router.Transports())
+}
+
+func TestCreateRouter_TraceIds(t *testing.T) {
+	router, err := worker.CreateRouter(testPipeMediaCodecs, WithTraceIds(TraceIds{"room": "r1"}))
+	assert.NoError(t, err)
+	defer router.Close()
+
+	transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+		TraceIds:  TraceIds{"peer": "p1"},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(audioProducerParameters)
+	assert.NoError(t, err)
+
+	assert.Equal(t, TraceIds{"room": "r1"}, router.internal.Trace)
+	assert.Equal(t, TraceIds{"room": "r1", "peer": "p1"}, transport.internal.Trace)
+	assert.Equal(t, TraceIds{"room": "r1", "peer": "p1"}, producer.internal.Trace)
+}
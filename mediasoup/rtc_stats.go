@@ -0,0 +1,191 @@
+package mediasoup
+
+import "fmt"
+
+// RtcStats is a stats object shaped like the ones returned by the W3C
+// RTCPeerConnection.getStats(), so server side stats can be ingested by
+// client side analytics pipelines. Type is one of "codec", "inbound-rtp",
+// "outbound-rtp", "remote-inbound-rtp" or "remote-outbound-rtp" and
+// determines the fields set.
+type RtcStats struct {
+	Id        string  `json:"id"`
+	Type      string  `json:"type"`
+	Timestamp float64 `json:"timestamp"`
+
+	// codec
+	PayloadType int    `json:"payloadType,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	ClockRate   int    `json:"clockRate,omitempty"`
+	Channels    int    `json:"channels,omitempty"`
+
+	// rtp streams
+	Ssrc         uint32  `json:"ssrc,omitempty"`
+	Kind         string  `json:"kind,omitempty"`
+	CodecId      string  `json:"codecId,omitempty"`
+	Rid          string  `json:"rid,omitempty"`
+	PacketsLost  uint32  `json:"packetsLost,omitempty"`
+	FractionLost float64 `json:"fractionLost,omitempty"`
+	NackCount    uint32  `json:"nackCount,omitempty"`
+	PliCount     uint32  `json:"pliCount,omitempty"`
+	FirCount     uint32  `json:"firCount,omitempty"`
+
+	// inbound-rtp
+	PacketsReceived  uint32  `json:"packetsReceived,omitempty"`
+	BytesReceived    uint64  `json:"bytesReceived,omitempty"`
+	PacketsDiscarded uint32  `json:"packetsDiscarded,omitempty"`
+	PacketsRepaired  uint32  `json:"packetsRepaired,omitempty"`
+	Jitter           float64 `json:"jitter,omitempty"`
+
+	// outbound-rtp
+	PacketsSent              uint32 `json:"packetsSent,omitempty"`
+	BytesSent                uint64 `json:"bytesSent,omitempty"`
+	RetransmittedPacketsSent uint32 `json:"retransmittedPacketsSent,omitempty"`
+	TargetBitrate            uint32 `json:"targetBitrate,omitempty"`
+	RemoteId                 string `json:"remoteId,omitempty"`
+
+	// remote-inbound-rtp and remote-outbound-rtp
+	LocalId       string  `json:"localId,omitempty"`
+	RoundTripTime float64 `json:"roundTripTime,omitempty"`
+}
+
+// GetRtcStats returns the Producer stats in the W3C getStats() shape.
+func (producer *Producer) GetRtcStats() (stats []RtcStats, err error) {
+	var streamStats []RtpStreamStat
+
+	if err = producer.GetStats().Unmarshal(&streamStats); err != nil {
+		return
+	}
+
+	return convertRtcStats(producer.Id(), producer.RtpParameters(), streamStats), nil
+}
+
+// GetRtcStats returns the Consumer stats in the W3C getStats() shape. The
+// stats of the consumed Producer stream are included as "inbound-rtp".
+func (consumer *Consumer) GetRtcStats() (stats []RtcStats, err error) {
+	var streamStats []RtpStreamStat
+
+	if err = consumer.GetStats().Unmarshal(&streamStats); err != nil {
+		return
+	}
+
+	return convertRtcStats(consumer.Id(), consumer.RtpParameters(), streamStats), nil
+}
+
+func convertRtcStats(entityId string, rtpParameters RtpParameters, streamStats []RtpStreamStat) (stats []RtcStats) {
+	codecIds := map[string]bool{}
+
+	for _, streamStat := range streamStats {
+		timestamp := float64(streamStat.Timestamp)
+		codec := findRtcStatsCodec(rtpParameters, streamStat.MimeType)
+
+		var codecId string
+
+		if codec != nil {
+			codecId = fmt.Sprintf("RTCCodec_%s_%d", entityId, codec.PayloadType)
+
+			if _, ok := codecIds[codecId]; !ok {
+				codecIds[codecId] = true
+				stats = append(stats, RtcStats{
+					Id:          codecId,
+					Type:        "codec",
+					Timestamp:   timestamp,
+					PayloadType: codec.PayloadType,
+					MimeType:    codec.MimeType,
+					ClockRate:   codec.ClockRate,
+					Channels:    codec.Channels,
+				})
+			}
+		}
+
+		stat := RtcStats{
+			Timestamp:    timestamp,
+			Ssrc:         streamStat.Ssrc,
+			Kind:         streamStat.Kind,
+			CodecId:      codecId,
+			Rid:          streamStat.Rid,
+			PacketsLost:  streamStat.PacketsLost,
+			FractionLost: float64(streamStat.FractionLost) / 256,
+			NackCount:    streamStat.NackCount,
+			PliCount:     streamStat.PliCount,
+			FirCount:     streamStat.FirCount,
+		}
+
+		switch streamStat.Type {
+		case "inbound-rtp":
+			stat.Id = fmt.Sprintf("RTCInboundRTPStream_%s_%d", entityId, streamStat.Ssrc)
+			stat.Type = "inbound-rtp"
+			stat.PacketsReceived = streamStat.PacketCount
+			stat.BytesReceived = streamStat.ByteCount
+			stat.PacketsDiscarded = streamStat.PacketsDiscarded
+			stat.PacketsRepaired = streamStat.PacketsRepaired
+			if codec != nil && codec.ClockRate > 0 {
+				stat.Jitter = float64(streamStat.Jitter) / float64(codec.ClockRate)
+			}
+
+			// The RTT of a received stream comes from RTCP XR DLRR reports.
+			if streamStat.RoundTripTime <= 0 {
+				stats = append(stats, stat)
+				break
+			}
+
+			remote := RtcStats{
+				Id:            fmt.Sprintf("RTCRemoteOutboundRtpStream_%s_%d", entityId, streamStat.Ssrc),
+				Type:          "remote-outbound-rtp",
+				Timestamp:     timestamp,
+				Ssrc:          streamStat.Ssrc,
+				Kind:          streamStat.Kind,
+				CodecId:       codecId,
+				LocalId:       stat.Id,
+				RoundTripTime: streamStat.RoundTripTime / 1000,
+			}
+			stat.RemoteId = remote.Id
+
+			stats = append(stats, stat, remote)
+
+		case "outbound-rtp":
+			stat.Id = fmt.Sprintf("RTCOutboundRTPStream_%s_%d", entityId, streamStat.Ssrc)
+			stat.Type = "outbound-rtp"
+			stat.PacketsSent = streamStat.PacketCount
+			stat.BytesSent = streamStat.ByteCount
+			stat.RetransmittedPacketsSent = streamStat.PacketsRetransmitted
+			stat.TargetBitrate = streamStat.Bitrate
+
+			// Loss and RTT are reported by the remote endpoint.
+			remote := RtcStats{
+				Id:            fmt.Sprintf("RTCRemoteInboundRtpStream_%s_%d", entityId, streamStat.Ssrc),
+				Type:          "remote-inbound-rtp",
+				Timestamp:     timestamp,
+				Ssrc:          streamStat.Ssrc,
+				Kind:          streamStat.Kind,
+				CodecId:       codecId,
+				PacketsLost:   stat.PacketsLost,
+				FractionLost:  stat.FractionLost,
+				LocalId:       stat.Id,
+				RoundTripTime: streamStat.RoundTripTime / 1000,
+			}
+			stat.RemoteId = remote.Id
+			stat.PacketsLost, stat.FractionLost = 0, 0
+
+			stats = append(stats, stat, remote)
+		}
+	}
+
+	return
+}
+
+// findRtcStatsCodec returns the media codec with the given mime type, or the
+// first media codec if mimeType is empty.
+func findRtcStatsCodec(rtpParameters RtpParameters, mimeType string) *RtpCodecCapability {
+	for i, codec := range rtpParameters.Codecs {
+		parsed := ParseMimeType(codec.MimeType)
+
+		if parsed.IsRtx() || parsed.IsFec() {
+			continue
+		}
+		if len(mimeType) == 0 || parsed == ParseMimeType(mimeType) {
+			return &rtpParameters.Codecs[i]
+		}
+	}
+
+	return nil
+}
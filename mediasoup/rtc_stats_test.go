@@ -0,0 +1,109 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertRtcStats(t *testing.T) {
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000},
+		},
+	}
+	streamStats := []RtpStreamStat{
+		{
+			Type:                 "outbound-rtp",
+			Timestamp:            1000,
+			Ssrc:                 1111,
+			Kind:                 "video",
+			MimeType:             "video/VP8",
+			PacketsLost:          3,
+			FractionLost:         64,
+			PacketsRetransmitted: 2,
+			PacketCount:          100,
+			ByteCount:            120000,
+			Bitrate:              500000,
+			RoundTripTime:        40,
+		},
+		{
+			Type:            "inbound-rtp",
+			Timestamp:       1000,
+			Ssrc:            2222,
+			Kind:            "video",
+			MimeType:        "video/VP8",
+			PacketCount:     200,
+			ByteCount:       240000,
+			PacketsRepaired: 1,
+			Jitter:          900,
+		},
+	}
+
+	stats := convertRtcStats("c1", rtpParameters, streamStats)
+
+	assert.Len(t, stats, 4)
+
+	codec, outbound, remote, inbound := stats[0], stats[1], stats[2], stats[3]
+
+	assert.Equal(t, RtcStats{
+		Id:          "RTCCodec_c1_101",
+		Type:        "codec",
+		Timestamp:   1000,
+		PayloadType: 101,
+		MimeType:    "video/VP8",
+		ClockRate:   90000,
+	}, codec)
+
+	assert.Equal(t, "outbound-rtp", outbound.Type)
+	assert.Equal(t, codec.Id, outbound.CodecId)
+	assert.EqualValues(t, 100, outbound.PacketsSent)
+	assert.EqualValues(t, 120000, outbound.BytesSent)
+	assert.EqualValues(t, 2, outbound.RetransmittedPacketsSent)
+	assert.Equal(t, remote.Id, outbound.RemoteId)
+	assert.Zero(t, outbound.PacketsLost)
+
+	assert.Equal(t, "remote-inbound-rtp", remote.Type)
+	assert.Equal(t, outbound.Id, remote.LocalId)
+	assert.EqualValues(t, 3, remote.PacketsLost)
+	assert.Equal(t, 0.25, remote.FractionLost)
+	assert.Equal(t, 0.04, remote.RoundTripTime)
+
+	assert.Equal(t, "inbound-rtp", inbound.Type)
+	assert.EqualValues(t, 200, inbound.PacketsReceived)
+	assert.EqualValues(t, 1, inbound.PacketsRepaired)
+	assert.Equal(t, 0.01, inbound.Jitter)
+}
+
+func TestConvertRtcStats_RemoteOutbound(t *testing.T) {
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+	}
+	streamStats := []RtpStreamStat{
+		{
+			Type:          "inbound-rtp",
+			Timestamp:     1000,
+			Ssrc:          2222,
+			Kind:          "audio",
+			MimeType:      "audio/opus",
+			RoundTripTime: 25,
+		},
+	}
+
+	stats := convertRtcStats("p1", rtpParameters, streamStats)
+
+	assert.Len(t, stats, 3)
+
+	inbound, remote := stats[1], stats[2]
+
+	assert.Equal(t, "inbound-rtp", inbound.Type)
+	assert.Equal(t, remote.Id, inbound.RemoteId)
+
+	assert.Equal(t, "remote-outbound-rtp", remote.Type)
+	assert.Equal(t, inbound.Id, remote.LocalId)
+	assert.EqualValues(t, 2222, remote.Ssrc)
+	assert.Equal(t, 0.025, remote.RoundTripTime)
+}
@@ -0,0 +1,29 @@
+package mediasoup
+
+import (
+	uuid "github.com/satori/go.uuid"
+)
+
+// Maximum length of a RTCP SDES item such as CNAME.
+const maxCnameLength = 255
+
+// GenerateCname generates a random RTCP CNAME.
+func GenerateCname() string {
+	return uuid.NewV4().String()[:8]
+}
+
+// Validate checks that the CNAME, if given, fits into a RTCP SDES item and
+// just contains printable ASCII characters.
+func (rtcp RtcpParameters) Validate() error {
+	if len(rtcp.Cname) > maxCnameLength {
+		return NewTypeError("rtcp.cname too long [length:%d]", len(rtcp.Cname))
+	}
+
+	for i := 0; i < len(rtcp.Cname); i++ {
+		if c := rtcp.Cname[i]; c < 0x20 || c > 0x7e {
+			return NewTypeError("invalid character in rtcp.cname [index:%d]", i)
+		}
+	}
+
+	return nil
+}
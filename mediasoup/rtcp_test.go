@@ -0,0 +1,27 @@
+package mediasoup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCname(t *testing.T) {
+	cname := GenerateCname()
+
+	assert.Len(t, cname, 8)
+	assert.NotEqual(t, cname, GenerateCname())
+	assert.NoError(t, RtcpParameters{Cname: cname}.Validate())
+}
+
+func TestRtcpParametersValidate(t *testing.T) {
+	assert.NoError(t, RtcpParameters{}.Validate())
+	assert.NoError(t, RtcpParameters{Cname: "user@host"}.Validate())
+
+	err := RtcpParameters{Cname: strings.Repeat("a", 256)}.Validate()
+	assert.IsType(t, NewTypeError(""), err)
+
+	err = RtcpParameters{Cname: "foo\nbar"}.Validate()
+	assert.IsType(t, NewTypeError(""), err)
+}
@@ -0,0 +1,191 @@
+package mediasoup
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// RTCP XR packet type and report block types (RFC 3611).
+const (
+	rtcpXrPacketType   = 207
+	rtcpXrBlockTypeRrt = 4
+	rtcpXrBlockTypeDlr = 5
+)
+
+// Seconds between the NTP epoch (1900) and the Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+// RtcpXrReceiverReferenceTime is the Receiver Reference Time report block,
+// sent by a receiver so the sender can compute the RTT with DLRR.
+type RtcpXrReceiverReferenceTime struct {
+	// 64 bits NTP timestamp.
+	NtpTimestamp uint64 `json:"ntpTimestamp"`
+}
+
+// RtcpXrDlrrItem is a sub-block of the DLRR report block, answering the
+// Receiver Reference Time of Ssrc.
+type RtcpXrDlrrItem struct {
+	Ssrc uint32 `json:"ssrc"`
+	// LastRr is the middle 32 bits of the NTP timestamp of the last Receiver
+	// Reference Time received from Ssrc.
+	LastRr uint32 `json:"lastRr"`
+	// DelaySinceLastRr in units of 1/65536 seconds.
+	DelaySinceLastRr uint32 `json:"delaySinceLastRr"`
+}
+
+// RoundTripTime returns the RTT computed by the receiver of the DLRR at
+// arrival time (RFC 3611 section 4.5), false if the item is empty.
+func (item RtcpXrDlrrItem) RoundTripTime(arrival time.Time) (time.Duration, bool) {
+	if item.LastRr == 0 {
+		return 0, false
+	}
+
+	now := compactNtp(arrival)
+	rtt := now - item.LastRr - item.DelaySinceLastRr
+
+	// Clock skew or a stale item.
+	if int32(rtt) < 0 {
+		return 0, true
+	}
+
+	return time.Duration(uint64(rtt) * uint64(time.Second) >> 16), true
+}
+
+// RtcpXr is a RTCP XR packet.
+type RtcpXr struct {
+	Ssrc           uint32                        `json:"ssrc"`
+	ReferenceTimes []RtcpXrReceiverReferenceTime `json:"referenceTimes,omitempty"`
+	DlrrItems      []RtcpXrDlrrItem              `json:"dlrrItems,omitempty"`
+}
+
+/**
+ * Parse the RTCP XR packets of a (compound) RTCP packet, e.g. as mirrored by
+ * a PlainRtpTransport. Other packets and report blocks are skipped.
+ *
+ * @returns {[]RtcpXr}
+ * @throws {TypeError} if the packet is malformed.
+ */
+func ParseRtcpXr(packet []byte) (xrs []RtcpXr, err error) {
+	for len(packet) > 0 {
+		if len(packet) < 4 || packet[0]>>6 != 2 {
+			return nil, NewTypeError("invalid RTCP packet")
+		}
+
+		length := 4 * (int(binary.BigEndian.Uint16(packet[2:4])) + 1)
+
+		if len(packet) < length {
+			return nil, NewTypeError("invalid RTCP packet length")
+		}
+
+		if packet[1] == rtcpXrPacketType {
+			xr, err := parseRtcpXrPacket(packet[:length])
+			if err != nil {
+				return nil, err
+			}
+			xrs = append(xrs, xr)
+		}
+
+		packet = packet[length:]
+	}
+
+	return
+}
+
+func parseRtcpXrPacket(packet []byte) (xr RtcpXr, err error) {
+	if len(packet) < 8 {
+		err = NewTypeError("invalid RTCP XR packet")
+		return
+	}
+
+	xr.Ssrc = binary.BigEndian.Uint32(packet[4:8])
+
+	for blocks := packet[8:]; len(blocks) > 0; {
+		if len(blocks) < 4 {
+			err = NewTypeError("invalid RTCP XR report block")
+			return
+		}
+
+		blockType := blocks[0]
+		length := 4 * (int(binary.BigEndian.Uint16(blocks[2:4])) + 1)
+
+		if len(blocks) < length {
+			err = NewTypeError("invalid RTCP XR report block length")
+			return
+		}
+
+		body := blocks[4:length]
+
+		switch blockType {
+		case rtcpXrBlockTypeRrt:
+			if len(body) != 8 {
+				err = NewTypeError("invalid RTCP XR receiver reference time block")
+				return
+			}
+			xr.ReferenceTimes = append(xr.ReferenceTimes, RtcpXrReceiverReferenceTime{
+				NtpTimestamp: binary.BigEndian.Uint64(body),
+			})
+
+		case rtcpXrBlockTypeDlr:
+			if len(body)%12 != 0 {
+				err = NewTypeError("invalid RTCP XR DLRR block")
+				return
+			}
+			for i := 0; i < len(body); i += 12 {
+				xr.DlrrItems = append(xr.DlrrItems, RtcpXrDlrrItem{
+					Ssrc:             binary.BigEndian.Uint32(body[i:]),
+					LastRr:           binary.BigEndian.Uint32(body[i+4:]),
+					DelaySinceLastRr: binary.BigEndian.Uint32(body[i+8:]),
+				})
+			}
+		}
+
+		blocks = blocks[length:]
+	}
+
+	return
+}
+
+// compactNtp returns the middle 32 bits of the NTP timestamp of t.
+func compactNtp(t time.Time) uint32 {
+	seconds := uint64(t.Unix()) + ntpEpochOffset
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+
+	return uint32((seconds<<32 | fraction) >> 16)
+}
+
+// StreamRoundTripTimes returns the RTT in milliseconds of every RTP stream of
+// the Producer, by SSRC. It is derived from RTCP XR DLRR reports, so only
+// endpoints sending Receiver Reference Time blocks have one.
+func (producer *Producer) StreamRoundTripTimes() (rtts map[uint32]float64, err error) {
+	var streamStats []RtpStreamStat
+
+	if err = producer.GetStats().Unmarshal(&streamStats); err != nil {
+		return
+	}
+
+	return streamRoundTripTimes(streamStats, "inbound-rtp"), nil
+}
+
+// StreamRoundTripTimes returns the RTT in milliseconds of every RTP stream
+// sent by the Consumer, by SSRC, derived from the RTCP receiver reports.
+func (consumer *Consumer) StreamRoundTripTimes() (rtts map[uint32]float64, err error) {
+	var streamStats []RtpStreamStat
+
+	if err = consumer.GetStats().Unmarshal(&streamStats); err != nil {
+		return
+	}
+
+	return streamRoundTripTimes(streamStats, "outbound-rtp"), nil
+}
+
+func streamRoundTripTimes(streamStats []RtpStreamStat, typ string) map[uint32]float64 {
+	rtts := make(map[uint32]float64)
+
+	for _, streamStat := range streamStats {
+		if streamStat.Type == typ && streamStat.RoundTripTime > 0 {
+			rtts[streamStat.Ssrc] = streamStat.RoundTripTime
+		}
+	}
+
+	return rtts
+}
@@ -0,0 +1,76 @@
+package mediasoup
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRtcpXr(t *testing.T) {
+	// Receiver report without report blocks, followed by a XR packet with a
+	// RRTR block, an unknown block and a DLRR block with two items.
+	packet := []byte{
+		0x80, 201, 0x00, 0x01, 0x00, 0x00, 0x04, 0x57,
+		0x80, 207, 0x00, 0x0c, 0x00, 0x00, 0x04, 0x57,
+		rtcpXrBlockTypeRrt, 0x00, 0x00, 0x02, 0xe8, 0x9b, 0x21, 0x4f, 0x12, 0x34, 0x56, 0x78,
+		0x06, 0x00, 0x00, 0x00,
+		rtcpXrBlockTypeDlr, 0x00, 0x00, 0x06,
+		0x00, 0x00, 0x08, 0xae, 0x21, 0x4f, 0x12, 0x34, 0x00, 0x00, 0x80, 0x00,
+		0x00, 0x00, 0x0d, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	xrs, err := ParseRtcpXr(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtcpXr{
+		{
+			Ssrc:           1111,
+			ReferenceTimes: []RtcpXrReceiverReferenceTime{{NtpTimestamp: 0xe89b214f12345678}},
+			DlrrItems: []RtcpXrDlrrItem{
+				{Ssrc: 2222, LastRr: 0x214f1234, DelaySinceLastRr: 0x8000},
+				{Ssrc: 3333},
+			},
+		},
+	}, xrs)
+
+	_, err = ParseRtcpXr(packet[:len(packet)-4])
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = ParseRtcpXr([]byte{0x00, 207, 0x00, 0x00})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestRtcpXrDlrrItemRoundTripTime(t *testing.T) {
+	sent := time.Unix(1700000000, 0)
+	arrival := sent.Add(1500 * time.Millisecond)
+
+	item := RtcpXrDlrrItem{
+		Ssrc:   2222,
+		LastRr: compactNtp(sent),
+		// The receiver held the RRTR for one second.
+		DelaySinceLastRr: 1 << 16,
+	}
+
+	rtt, ok := item.RoundTripTime(arrival)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, rtt)
+
+	_, ok = RtcpXrDlrrItem{Ssrc: 2222}.RoundTripTime(arrival)
+	assert.False(t, ok)
+
+	var ntp [8]byte
+	binary.BigEndian.PutUint32(ntp[:], uint32(sent.Unix()+ntpEpochOffset))
+	assert.Equal(t, binary.BigEndian.Uint32(ntp[2:6]), compactNtp(sent))
+}
+
+func TestStreamRoundTripTimes(t *testing.T) {
+	streamStats := []RtpStreamStat{
+		{Type: "outbound-rtp", Ssrc: 1111, RoundTripTime: 40},
+		{Type: "inbound-rtp", Ssrc: 2222, RoundTripTime: 25},
+		{Type: "inbound-rtp", Ssrc: 3333},
+	}
+
+	assert.Equal(t, map[uint32]float64{2222: 25}, streamRoundTripTimes(streamStats, "inbound-rtp"))
+	assert.Equal(t, map[uint32]float64{1111: 40}, streamRoundTripTimes(streamStats, "outbound-rtp"))
+}
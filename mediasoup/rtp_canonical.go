@@ -0,0 +1,90 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Canonicalize returns a copy of the RTP parameters with codecs sorted by
+// payload type, header extensions sorted by id, RTCP feedback sorted and
+// normalized mime types (see ParseMimeType). Encodings keep their order since
+// it is meaningful.
+func (params RtpParameters) Canonicalize() RtpParameters {
+	params.Codecs = canonicalCodecs(params.Codecs, func(codec RtpCodecCapability) int {
+		return codec.PayloadType
+	})
+
+	params.HeaderExtensions = append([]RtpHeaderExtension(nil), params.HeaderExtensions...)
+	sort.SliceStable(params.HeaderExtensions, func(i, j int) bool {
+		return params.HeaderExtensions[i].Id < params.HeaderExtensions[j].Id
+	})
+
+	return params
+}
+
+// CanonicalJSON returns the deterministic JSON encoding of the canonical form.
+func (params RtpParameters) CanonicalJSON() ([]byte, error) {
+	return json.Marshal(params.Canonicalize())
+}
+
+// Canonicalize returns a copy of the RTP capabilities with codecs sorted by
+// preferred payload type, header extensions sorted by preferred id and kind,
+// RTCP feedback and FEC mechanisms sorted and normalized mime
+// types.
+func (caps RtpCapabilities) Canonicalize() RtpCapabilities {
+	caps.Codecs = canonicalCodecs(caps.Codecs, func(codec RtpCodecCapability) int {
+		return codec.PreferredPayloadType
+	})
+
+	caps.HeaderExtensions = append([]RtpHeaderExtension(nil), caps.HeaderExtensions...)
+	sort.SliceStable(caps.HeaderExtensions, func(i, j int) bool {
+		a, b := caps.HeaderExtensions[i], caps.HeaderExtensions[j]
+
+		if a.PreferredId != b.PreferredId {
+			return a.PreferredId < b.PreferredId
+		}
+
+		return a.Kind < b.Kind
+	})
+
+	caps.FecMechanisms = append([]string(nil), caps.FecMechanisms...)
+	sort.Strings(caps.FecMechanisms)
+
+	return caps
+}
+
+// CanonicalJSON returns the deterministic JSON encoding of the canonical form.
+func (caps RtpCapabilities) CanonicalJSON() ([]byte, error) {
+	return json.Marshal(caps.Canonicalize())
+}
+
+func canonicalCodecs(codecs []RtpCodecCapability, payloadType func(RtpCodecCapability) int) []RtpCodecCapability {
+	if codecs == nil {
+		return nil
+	}
+
+	result := make([]RtpCodecCapability, 0, len(codecs))
+
+	for _, codec := range codecs {
+		codec.MimeType = ParseMimeType(codec.MimeType).String()
+		codec.RtcpFeedback = append([]RtcpFeedback(nil), codec.RtcpFeedback...)
+
+		sort.SliceStable(codec.RtcpFeedback, func(i, j int) bool {
+			a, b := codec.RtcpFeedback[i], codec.RtcpFeedback[j]
+
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+
+			return a.Parameter < b.Parameter
+		})
+
+		result = append(result, codec)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return payloadType(result[i]) < payloadType(result[j])
+	})
+
+	return result
+}
@@ -0,0 +1,100 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRtpParametersCanonicalize(t *testing.T) {
+	a := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+			{
+				MimeType:    "video/VP8",
+				PayloadType: 101,
+				ClockRate:   90000,
+				RtcpFeedback: []RtcpFeedback{
+					{Type: "nack", Parameter: "pli"},
+					{Type: "goog-remb"},
+					{Type: "nack"},
+				},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 3},
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 2}, {Ssrc: 1}},
+	}
+	b := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:    "video/vp8",
+				PayloadType: 101,
+				ClockRate:   90000,
+				RtcpFeedback: []RtcpFeedback{
+					{Type: "goog-remb"},
+					{Type: "nack"},
+					{Type: "nack", Parameter: "pli"},
+				},
+			},
+			{MimeType: " Video/RTX ", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+			{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 3},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 2}, {Ssrc: 1}},
+	}
+
+	canonical := a.Canonicalize()
+	assert.Equal(t, "video/vp8", canonical.Codecs[0].MimeType)
+	assert.Equal(t, 1, canonical.HeaderExtensions[0].Id)
+	assert.Equal(t, []RtpEncoding{{Ssrc: 2}, {Ssrc: 1}}, canonical.Encodings)
+	assert.Equal(t, "video/rtx", a.Codecs[0].MimeType)
+	assert.Equal(t, "pli", a.Codecs[1].RtcpFeedback[0].Parameter)
+
+	aJSON, err := a.CanonicalJSON()
+	assert.NoError(t, err)
+	bJSON, err := b.CanonicalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, string(aJSON), string(bJSON))
+}
+
+func TestRtpCapabilitiesCanonicalize(t *testing.T) {
+	a := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "video", MimeType: "video/VP8", PreferredPayloadType: 101, ClockRate: 90000},
+			{Kind: "audio", MimeType: "audio/opus", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+			{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+		},
+		FecMechanisms: []string{"b", "a"},
+	}
+	b := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "audio", MimeType: "AUDIO/OPUS", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+			{Kind: "video", MimeType: "video/vp8", PreferredPayloadType: 101, ClockRate: 90000},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+		},
+		FecMechanisms: []string{"a", "b"},
+	}
+
+	canonical := a.Canonicalize()
+	assert.Equal(t, "audio/opus", canonical.Codecs[0].MimeType)
+	assert.Equal(t, MediaKindAudio, canonical.HeaderExtensions[0].Kind)
+	assert.Equal(t, []string{"a", "b"}, canonical.FecMechanisms)
+	assert.Equal(t, []string{"b", "a"}, a.FecMechanisms)
+
+	aKey, err := rtpCapabilitiesKey(a)
+	assert.NoError(t, err)
+	bKey, err := rtpCapabilitiesKey(b)
+	assert.NoError(t, err)
+	assert.Equal(t, aKey, bKey)
+}
@@ -1,14 +1,17 @@
 package mediasoup
 
 import (
-	"github.com/jinzhu/copier"
 	h264 "github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	h265 "github.com/jiyeyuran/mediasoup-go/mediasoup/h265profile"
 )
 
 type RtpCapabilities struct {
 	Codecs           []RtpCodecCapability `json:"codecs,omitempty"`
 	HeaderExtensions []RtpHeaderExtension `json:"headerExtensions,omitempty"`
 	FecMechanisms    []string             `json:"fecMechanisms,omitempty"`
+	// ExtmapAllowMixed tells whether one-byte and two-byte header extensions
+	// (ids above 14) can be mixed, "a=extmap-allow-mixed" in SDP.
+	ExtmapAllowMixed bool `json:"extmapAllowMixed,omitempty"`
 }
 
 type RtpParameters struct {
@@ -16,7 +19,9 @@ type RtpParameters struct {
 	Codecs           []RtpCodecCapability `json:"codecs,omitempty"`
 	HeaderExtensions []RtpHeaderExtension `json:"headerExtensions,omitempty"`
 	Encodings        []RtpEncoding        `json:"encodings,omitempty"`
-	Rtcp             RtcpConfiguation     `json:"rtcp,omitempty"`
+	Rtcp             RtcpParameters       `json:"rtcp,omitempty"`
+	// ExtmapAllowMixed is set if some header extension id is above 14.
+	ExtmapAllowMixed bool `json:"extmapAllowMixed,omitempty"`
 }
 
 type RtpMappingParameters struct {
@@ -26,8 +31,8 @@ type RtpMappingParameters struct {
 }
 
 type RtpMappingCodec struct {
-	PayloadType       int `json:"payloadType,omitempty"`
-	MappedPayloadType int `json:"mappedPayloadType,omitempty"`
+	PayloadType       int `json:"payloadType"`
+	MappedPayloadType int `json:"mappedPayloadType"`
 }
 
 type RtpMappingHeaderExt struct {
@@ -36,13 +41,21 @@ type RtpMappingHeaderExt struct {
 }
 
 type RtpMappingEncoding struct {
-	Rid        string `json:"rid,omitempty"`
-	Ssrc       uint32 `json:"ssrc,omitempty"`
-	MappedSsrc uint32 `json:"mappedSsrc,omitempty"`
+	Rid             string `json:"rid,omitempty"`
+	Ssrc            uint32 `json:"ssrc,omitempty"`
+	MappedSsrc      uint32 `json:"mappedSsrc,omitempty"`
+	ScalabilityMode string `json:"scalabilityMode,omitempty"`
+	// RTX SSRC of the encoding and the one it is mapped to, if the encoding
+	// has RTX.
+	RtxSsrc       uint32 `json:"rtxSsrc,omitempty"`
+	MappedRtxSsrc uint32 `json:"mappedRtxSsrc,omitempty"`
+	// Layer counts parsed from ScalabilityMode.
+	SpatialLayers  int `json:"-"`
+	TemporalLayers int `json:"-"`
 }
 
 type RtpCodecCapability struct {
-	Kind                 string             `json:"kind,omitempty"`
+	Kind                 MediaKind          `json:"kind,omitempty"`
 	MimeType             string             `json:"mimeType,omitempty"`
 	ClockRate            int                `json:"clockRate,omitempty"`
 	Channels             int                `json:"channels,omitempty"`
@@ -61,35 +74,76 @@ type RtpCodecParameter struct {
 	h264.RtpH264Parameter     // used by h264 codec
 	Apt                   int `json:"apt,omitempty"` // used by rtx codec
 
-	SpropStereo         uint8  `json:"sprop-stereo,omitempty"` // used by audio, 1 or 0
+	// Used by vp9 (nil means 0) and h265 (nil means 1) codecs.
+	ProfileId *uint8 `json:"profile-id,omitempty"`
+
+	// Used by h265 codec, see RtpH265Parameter().
+	ProfileSpace int    `json:"profile-space,omitempty"`
+	TierFlag     int    `json:"tier-flag,omitempty"`
+	LevelId      int    `json:"level-id,omitempty"`
+	TxMode       string `json:"tx-mode,omitempty"`
+
+	// Used by av1 codec, nil means the default value (profile 0, level-idx 5,
+	// tier 0).
+	Profile  *uint8 `json:"profile,omitempty"`
+	LevelIdx *uint8 `json:"level-idx,omitempty"`
+	Tier     *uint8 `json:"tier,omitempty"`
+
+	SpropStereo uint8 `json:"sprop-stereo,omitempty"` // used by audio, 1 or 0
+	// Used by opus codec, whether the receiver accepts stereo, nil if not
+	// stated.
+	Stereo              *uint8 `json:"stereo,omitempty"`
 	Useinbandfec        uint8  `json:"useinbandfec,omitempty"` // used by audio, 1 or 0
 	Usedtx              uint8  `json:"usedtx,omitempty"`       // used by audio, 1 or 0
 	Maxplaybackrate     uint32 `json:"maxplaybackrate,omitempty"`
+	Maxaveragebitrate   uint32 `json:"maxaveragebitrate,omitempty"` // used by opus codec
 	XGoogleMinBitrate   uint32 `json:"x-google-min-bitrate,omitempty"`
 	XGoogleMaxBitrate   uint32 `json:"x-google-max-bitrate,omitempty"`
 	XGoogleStartBitrate uint32 `json:"x-google-start-bitrate,omitempty"`
 }
 
+// RtpH265Parameter returns the h265 codec parameters.
+func (params RtpCodecParameter) RtpH265Parameter() h265.RtpH265Parameter {
+	h265Params := h265.RtpH265Parameter{
+		ProfileSpace: params.ProfileSpace,
+		TierFlag:     params.TierFlag,
+		LevelId:      params.LevelId,
+		TxMode:       params.TxMode,
+	}
+
+	if params.ProfileId != nil {
+		h265Params.ProfileId = int(*params.ProfileId)
+	}
+
+	return h265Params
+}
+
 type RtpHeaderExtension struct {
-	Id               int    `json:"id,omitempty"`
-	Kind             string `json:"kind,omitempty"`
-	Uri              string `json:"uri,omitempty"`
-	Encrypt          *bool  `json:"encrypt,omitempty"`
-	Parameters       *H     `json:"parameters,omitempty"`
-	PreferredId      int    `json:"preferredId,omitempty"`
-	PreferredEncrypt bool   `json:"preferredEncrypt,omitempty"`
+	Id               int       `json:"id,omitempty"`
+	Kind             MediaKind `json:"kind,omitempty"`
+	Uri              string    `json:"uri,omitempty"`
+	Encrypt          *bool     `json:"encrypt,omitempty"`
+	Parameters       *H        `json:"parameters,omitempty"`
+	PreferredId      int       `json:"preferredId,omitempty"`
+	PreferredEncrypt bool      `json:"preferredEncrypt,omitempty"`
+	// Direction in capabilities, see HeaderExtensionDirection().
+	Direction string `json:"direction,omitempty"`
 }
 
 type RtpEncoding struct {
-	Rid              string       `json:"rid,omitempty"`
-	Ssrc             uint32       `json:"ssrc,omitempty"`
-	Rtx              *RtpEncoding `json:"rtx,omitempty"`
-	MaxBitrate       uint32       `json:"maxBitrate,omitempty"`
-	CodecPayloadType uint32       `json:"codecPayloadType,omitempty"`
-	Dtx              bool         `json:"dtx,omitempty"`
+	Rid                   string       `json:"rid,omitempty"`
+	Ssrc                  uint32       `json:"ssrc,omitempty"`
+	Rtx                   *RtpEncoding `json:"rtx,omitempty"`
+	MaxBitrate            uint32       `json:"maxBitrate,omitempty"`
+	CodecPayloadType      uint32       `json:"codecPayloadType,omitempty"`
+	Dtx                   bool         `json:"dtx,omitempty"`
+	ScaleResolutionDownBy float64      `json:"scaleResolutionDownBy,omitempty"`
+	MaxFramerate          float64      `json:"maxFramerate,omitempty"`
+	ScalabilityMode       string       `json:"scalabilityMode,omitempty"`
+	NetworkPriority       string       `json:"networkPriority,omitempty"`
 }
 
-type RtcpConfiguation struct {
+type RtcpParameters struct {
 	Cname       string `json:"cname,omitempty"`
 	ReducedSize bool   `json:"reducedSize,omitempty"`
 	Mux         *bool  `json:"mux,omitempty"`
@@ -285,6 +339,17 @@ var supportedRtpCapabilities = RtpCapabilities{
 				{Type: "goog-remb"},
 			},
 		},
+		{
+			Kind:      "video",
+			MimeType:  "video/AV1",
+			ClockRate: 90000,
+			RtcpFeedback: []RtcpFeedback{
+				{Type: "nack"},
+				{Type: "nack", Parameter: "pli"},
+				{Type: "ccm", Parameter: "fir"},
+				{Type: "goog-remb"},
+			},
+		},
 	},
 	HeaderExtensions: []RtpHeaderExtension{
 		{
@@ -292,60 +357,124 @@ var supportedRtpCapabilities = RtpCapabilities{
 			Uri:              "urn:ietf:params:rtp-hdrext:ssrc-audio-level",
 			PreferredId:      1,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 		{
 			Kind:             "video",
 			Uri:              "urn:ietf:params:rtp-hdrext:toffset",
 			PreferredId:      2,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 		{
 			Kind:             "audio",
 			Uri:              "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time",
 			PreferredId:      3,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 		{
 			Kind:             "video",
 			Uri:              "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time",
 			PreferredId:      3,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 		{
 			Kind:             "video",
 			Uri:              "urn:3gpp:video-orientation",
 			PreferredId:      4,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 		{
 			Kind:             "audio",
 			Uri:              "urn:ietf:params:rtp-hdrext:sdes:mid",
 			PreferredId:      5,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionRecvOnly,
 		},
 		{
 			Kind:             "video",
 			Uri:              "urn:ietf:params:rtp-hdrext:sdes:mid",
 			PreferredId:      5,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionRecvOnly,
 		},
 		{
 			Kind:             "video",
 			Uri:              "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id",
 			PreferredId:      6,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionRecvOnly,
 		},
 		{
 			Kind:             "video",
 			Uri:              "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id",
 			PreferredId:      7,
 			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionRecvOnly,
+		},
+		{
+			Kind:             "video",
+			Uri:              DependencyDescriptorUri,
+			PreferredId:      8,
+			PreferredEncrypt: false,
+			Direction:        HeaderExtensionDirectionSendRecv,
 		},
 	},
 }
 
-func GetSupportedRtpCapabilities() (rtpCapabilities RtpCapabilities) {
-	copier.Copy(&rtpCapabilities, &supportedRtpCapabilities)
+func GetSupportedRtpCapabilities() RtpCapabilities {
+	return supportedRtpCapabilities.Clone()
+}
+
+// routerSupportedRtpCapabilities returns the supported RTP capabilities with
+// the overrides of the Router options.
+func routerSupportedRtpCapabilities(opts *RouterOptions) (caps RtpCapabilities, err error) {
+	if opts.SupportedRtpCapabilities != nil {
+		caps = opts.SupportedRtpCapabilities.Clone()
+	} else {
+		caps = GetSupportedRtpCapabilities()
+	}
+
+	for _, codec := range opts.AdditionalCodecs {
+		if err = validateRtpCodecCapability(&codec); err != nil {
+			return
+		}
+		if _, matched := selectMatchedCodecs(&codec, caps.Codecs, codecMatchNormal); matched {
+			err = NewTypeError("additional codec already supported [mimeType:%s, clockRate:%d]",
+				codec.MimeType, codec.ClockRate)
+			return
+		}
+		caps.Codecs = append(caps.Codecs, codec)
+	}
+
+	for _, ext := range opts.CustomHeaderExtensions {
+		if IsTwoByteHeaderExtensionId(ext.PreferredId) && !opts.ExtmapAllowMixed {
+			err = NewTypeError(
+				"custom header extension needs two-byte header extensions [uri:%s, preferredId:%d]",
+				ext.Uri, ext.PreferredId)
+			return
+		}
+		if len(ext.Direction) == 0 {
+			ext.Direction = HeaderExtensionDirectionSendRecv
+		}
+		if err = validateRtpHeaderExtension(ext); err != nil {
+			return
+		}
+
+		for _, capExt := range caps.HeaderExtensions {
+			// Ids are shared by the audio and video extensions of a uri.
+			if capExt.PreferredId == ext.PreferredId && capExt.Uri != ext.Uri ||
+				capExt.Uri == ext.Uri && capExt.Kind == ext.Kind {
+				err = NewTypeError("custom header extension conflicts with %s [uri:%s, preferredId:%d]",
+					capExt.Uri, ext.Uri, ext.PreferredId)
+				return
+			}
+		}
+		caps.HeaderExtensions = append(caps.HeaderExtensions, ext)
+	}
 
 	return
 }
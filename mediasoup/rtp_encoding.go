@@ -0,0 +1,72 @@
+package mediasoup
+
+import (
+	"math"
+	"strings"
+)
+
+// Valid values of RtpEncoding.NetworkPriority.
+var networkPriorities = map[string]bool{
+	"very-low": true,
+	"low":      true,
+	"medium":   true,
+	"high":     true,
+}
+
+// Validate checks the fields of an encoding given by the client for a
+// Producer of the given kind. Zero values mean unset, so the worker and the
+// client defaults apply (e.g. scaleResolutionDownBy 1).
+func (encoding RtpEncoding) Validate(kind MediaKind) error {
+	if encoding.Dtx && kind != "audio" {
+		return NewTypeError("encoding.dtx is only valid for audio")
+	}
+
+	if encoding.ScaleResolutionDownBy != 0 {
+		if kind != "video" {
+			return NewTypeError("encoding.scaleResolutionDownBy is only valid for video")
+		}
+		if math.IsNaN(encoding.ScaleResolutionDownBy) || math.IsInf(encoding.ScaleResolutionDownBy, 0) ||
+			encoding.ScaleResolutionDownBy < 1 {
+			return NewTypeError("invalid encoding.scaleResolutionDownBy [value:%v]",
+				encoding.ScaleResolutionDownBy)
+		}
+	}
+
+	if encoding.MaxFramerate != 0 {
+		if kind != "video" {
+			return NewTypeError("encoding.maxFramerate is only valid for video")
+		}
+		if math.IsNaN(encoding.MaxFramerate) || math.IsInf(encoding.MaxFramerate, 0) ||
+			encoding.MaxFramerate < 0 {
+			return NewTypeError("invalid encoding.maxFramerate [value:%v]", encoding.MaxFramerate)
+		}
+	}
+
+	if len(encoding.NetworkPriority) > 0 && !networkPriorities[encoding.NetworkPriority] {
+		return NewTypeError("invalid encoding.networkPriority [value:%s]", encoding.NetworkPriority)
+	}
+
+	return nil
+}
+
+// normalizeRtpEncodings lower cases the networkPriority of the encodings and
+// validates them.
+func normalizeRtpEncodings(kind MediaKind, encodings []RtpEncoding) ([]RtpEncoding, error) {
+	if encodings == nil {
+		return nil, nil
+	}
+
+	normalized := make([]RtpEncoding, 0, len(encodings))
+
+	for i, encoding := range encodings {
+		encoding.NetworkPriority = strings.ToLower(strings.TrimSpace(encoding.NetworkPriority))
+
+		if err := encoding.Validate(kind); err != nil {
+			return nil, NewTypeError("encodings[%d]: %s", i, err)
+		}
+
+		normalized = append(normalized, encoding)
+	}
+
+	return normalized, nil
+}
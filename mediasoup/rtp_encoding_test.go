@@ -0,0 +1,61 @@
+package mediasoup
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRtpEncodingValidate(t *testing.T) {
+	valid := []struct {
+		kind     MediaKind
+		encoding RtpEncoding
+	}{
+		{"audio", RtpEncoding{}},
+		{"audio", RtpEncoding{Dtx: true, NetworkPriority: "high"}},
+		{"video", RtpEncoding{ScaleResolutionDownBy: 1, MaxFramerate: 30}},
+		{"video", RtpEncoding{ScaleResolutionDownBy: 2.5, NetworkPriority: "very-low"}},
+	}
+
+	for _, testCase := range valid {
+		assert.NoError(t, testCase.encoding.Validate(testCase.kind), "%+v", testCase.encoding)
+	}
+
+	invalid := []struct {
+		kind     MediaKind
+		encoding RtpEncoding
+	}{
+		{"video", RtpEncoding{Dtx: true}},
+		{"audio", RtpEncoding{ScaleResolutionDownBy: 2}},
+		{"audio", RtpEncoding{MaxFramerate: 30}},
+		{"video", RtpEncoding{ScaleResolutionDownBy: 0.5}},
+		{"video", RtpEncoding{ScaleResolutionDownBy: math.NaN()}},
+		{"video", RtpEncoding{MaxFramerate: -1}},
+		{"video", RtpEncoding{MaxFramerate: math.Inf(1)}},
+		{"video", RtpEncoding{NetworkPriority: "urgent"}},
+	}
+
+	for _, testCase := range invalid {
+		assert.IsType(t, NewTypeError(""), testCase.encoding.Validate(testCase.kind), "%+v", testCase.encoding)
+	}
+}
+
+func TestNormalizeRtpEncodings(t *testing.T) {
+	encodings, err := normalizeRtpEncodings("video", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, encodings)
+
+	input := []RtpEncoding{
+		{Ssrc: 1, NetworkPriority: " High "},
+		{Ssrc: 2, ScaleResolutionDownBy: 2},
+	}
+
+	encodings, err = normalizeRtpEncodings("video", input)
+	assert.NoError(t, err)
+	assert.Equal(t, "high", encodings[0].NetworkPriority)
+	assert.Equal(t, " High ", input[0].NetworkPriority)
+
+	_, err = normalizeRtpEncodings("video", []RtpEncoding{{Ssrc: 1}, {Ssrc: 2, Dtx: true}})
+	assert.EqualError(t, err, "encodings[1]: encoding.dtx is only valid for audio")
+}
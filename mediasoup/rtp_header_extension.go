@@ -0,0 +1,186 @@
+package mediasoup
+
+import "sync"
+
+// VideoOrientationUri is the 3GPP coordination of video orientation RTP
+// header extension, signaled by the Producer "videoorientationchange" event.
+const VideoOrientationUri = "urn:3gpp:video-orientation"
+
+// DependencyDescriptorUri is the AV1 Dependency Descriptor RTP header
+// extension, which describes the SVC layers of each frame so the SFU can
+// filter them.
+const DependencyDescriptorUri = "https://aomediacodec.github.io/av1-rtp-spec/#dependency-descriptor-rtp-header-extension"
+
+// PlayoutDelayUri is the playout delay RTP header extension, by which the
+// sender asks the receiver to bound its jitter buffer delay.
+const PlayoutDelayUri = "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay"
+
+// Directions of a header extension in RTP capabilities, from the point of
+// view of the Router: "recvonly" extensions are accepted from Producers but
+// not sent to Consumers, "sendonly" ones the other way around.
+const (
+	HeaderExtensionDirectionSendRecv = "sendrecv"
+	HeaderExtensionDirectionSendOnly = "sendonly"
+	HeaderExtensionDirectionRecvOnly = "recvonly"
+	HeaderExtensionDirectionInactive = "inactive"
+)
+
+// Directions of the extensions which are not "sendrecv" when capabilities
+// don't give it: the SFU rewrites the stream identification ones, and the
+// playout delay is a request of the sending endpoint.
+var defaultHeaderExtensionDirections = map[string]string{
+	"urn:ietf:params:rtp-hdrext:sdes:mid":                    HeaderExtensionDirectionRecvOnly,
+	"urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id":          HeaderExtensionDirectionRecvOnly,
+	"urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id": HeaderExtensionDirectionRecvOnly,
+	PlayoutDelayUri: HeaderExtensionDirectionRecvOnly,
+}
+
+var knownHeaderExtensions = []struct {
+	name    string
+	uri     string
+	aliases []string
+}{
+	{
+		name: "audio-level",
+		uri:  "urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+	},
+	{
+		name: "toffset",
+		uri:  "urn:ietf:params:rtp-hdrext:toffset",
+	},
+	{
+		name: "abs-send-time",
+		uri:  "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time",
+	},
+	{
+		name: "video-orientation",
+		uri:  VideoOrientationUri,
+	},
+	{
+		name: "mid",
+		uri:  "urn:ietf:params:rtp-hdrext:sdes:mid",
+	},
+	{
+		name: "rid",
+		uri:  "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id",
+	},
+	{
+		name: "rrid",
+		uri:  "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id",
+	},
+	{
+		name: "transport-wide-cc",
+		uri:  TransportWideCcUri,
+		aliases: []string{
+			"http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions",
+		},
+	},
+	{
+		name: "dependency-descriptor",
+		uri:  DependencyDescriptorUri,
+	},
+	{
+		name: "playout-delay",
+		uri:  PlayoutDelayUri,
+	},
+	{
+		name: "framemarking",
+		uri:  "urn:ietf:params:rtp-hdrext:framemarking",
+		aliases: []string{
+			"http://tools.ietf.org/html/draft-ietf-avtext-framemarking-07",
+		},
+	},
+}
+
+var (
+	headerExtensionLocker sync.RWMutex
+	// canonical uri -> friendly name
+	headerExtensionNames = map[string]string{}
+	// canonical uri or alias -> canonical uri
+	headerExtensionUris = map[string]string{}
+)
+
+func init() {
+	for _, ext := range knownHeaderExtensions {
+		RegisterHeaderExtensionUri(ext.name, ext.uri, ext.aliases...)
+	}
+}
+
+// RegisterHeaderExtensionUri registers a RTP header extension uri with a
+// friendly name. Aliases (e.g. draft uris) are treated as the same extension
+// when matching header extensions.
+func RegisterHeaderExtensionUri(name, uri string, aliases ...string) {
+	headerExtensionLocker.Lock()
+	defer headerExtensionLocker.Unlock()
+
+	headerExtensionNames[uri] = name
+	headerExtensionUris[uri] = uri
+
+	for _, alias := range aliases {
+		headerExtensionUris[alias] = uri
+	}
+}
+
+// HeaderExtensionName returns the friendly name of the given uri or alias, or
+// an empty string if it is unknown.
+func HeaderExtensionName(uri string) string {
+	headerExtensionLocker.RLock()
+	defer headerExtensionLocker.RUnlock()
+
+	return headerExtensionNames[headerExtensionUris[uri]]
+}
+
+// CanonicalHeaderExtensionUri resolves an alias to its registered uri. Unknown
+// uris are returned as is.
+func CanonicalHeaderExtensionUri(uri string) string {
+	headerExtensionLocker.RLock()
+	defer headerExtensionLocker.RUnlock()
+
+	if canonicalUri, ok := headerExtensionUris[uri]; ok {
+		return canonicalUri
+	}
+
+	return uri
+}
+
+// HeaderExtensionDirection returns the direction of a header extension of RTP
+// capabilities, the default one of its uri if not given.
+func HeaderExtensionDirection(ext RtpHeaderExtension) string {
+	if len(ext.Direction) > 0 {
+		return ext.Direction
+	}
+	if direction, ok := defaultHeaderExtensionDirections[CanonicalHeaderExtensionUri(ext.Uri)]; ok {
+		return direction
+	}
+
+	return HeaderExtensionDirectionSendRecv
+}
+
+// canSendHeaderExtension returns whether the Router may send the extension
+// to Consumers.
+func canSendHeaderExtension(ext RtpHeaderExtension) bool {
+	direction := HeaderExtensionDirection(ext)
+
+	return direction == HeaderExtensionDirectionSendRecv || direction == HeaderExtensionDirectionSendOnly
+}
+
+// canRecvHeaderExtension returns whether the Router may receive the extension
+// from Producers.
+func canRecvHeaderExtension(ext RtpHeaderExtension) bool {
+	direction := HeaderExtensionDirection(ext)
+
+	return direction == HeaderExtensionDirectionSendRecv || direction == HeaderExtensionDirectionRecvOnly
+}
+
+// reverseHeaderExtensionDirection returns the direction seen by the other
+// endpoint.
+func reverseHeaderExtensionDirection(direction string) string {
+	switch direction {
+	case HeaderExtensionDirectionSendOnly:
+		return HeaderExtensionDirectionRecvOnly
+	case HeaderExtensionDirectionRecvOnly:
+		return HeaderExtensionDirectionSendOnly
+	default:
+		return direction
+	}
+}
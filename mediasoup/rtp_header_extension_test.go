@@ -0,0 +1,115 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderExtensionName(t *testing.T) {
+	assert.Equal(t, "mid", HeaderExtensionName("urn:ietf:params:rtp-hdrext:sdes:mid"))
+	assert.Equal(t, "framemarking",
+		HeaderExtensionName("http://tools.ietf.org/html/draft-ietf-avtext-framemarking-07"))
+	assert.Empty(t, HeaderExtensionName("urn:foo"))
+}
+
+func TestMatchHeaderExtensions_Aliases(t *testing.T) {
+	assert.True(t, matchHeaderExtensions(
+		RtpHeaderExtension{Uri: "http://tools.ietf.org/html/draft-ietf-avtext-framemarking-07"},
+		RtpHeaderExtension{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:framemarking"},
+	))
+
+	assert.True(t, matchHeaderExtensions(
+		RtpHeaderExtension{Uri: "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions"},
+		RtpHeaderExtension{Kind: "video", Uri: TransportWideCcUri},
+	))
+	assert.Equal(t, "transport-wide-cc",
+		HeaderExtensionName("http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions"))
+
+	RegisterHeaderExtensionUri("foo", "urn:foo", "urn:foo-draft")
+
+	assert.Equal(t, "urn:foo", CanonicalHeaderExtensionUri("urn:foo-draft"))
+	assert.True(t, matchHeaderExtensions(
+		RtpHeaderExtension{Uri: "urn:foo-draft"},
+		RtpHeaderExtension{Uri: "urn:foo"},
+	))
+	assert.False(t, matchHeaderExtensions(
+		RtpHeaderExtension{Uri: "urn:foo"},
+		RtpHeaderExtension{Uri: "urn:bar"},
+	))
+}
+
+func TestStripHeaderExtension(t *testing.T) {
+	params := RtpParameters{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 2},
+			{Uri: VideoOrientationUri, Id: 4},
+		},
+	}
+
+	stripped := stripHeaderExtension(params, VideoOrientationUri)
+
+	assert.Equal(t, []RtpHeaderExtension{
+		{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 2},
+	}, stripped.HeaderExtensions)
+	assert.Len(t, params.HeaderExtensions, 2)
+}
+
+func TestVideoOrientationRotation(t *testing.T) {
+	var orientation VideoOrientation
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"camera":true,"rotation":270}`), &orientation))
+	assert.Equal(t, VideoOrientation{Camera: true, Rotation: 270}, orientation)
+}
+
+func TestHeaderExtensionDirection(t *testing.T) {
+	assert.Equal(t, HeaderExtensionDirectionRecvOnly,
+		HeaderExtensionDirection(RtpHeaderExtension{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid"}))
+	assert.Equal(t, HeaderExtensionDirectionRecvOnly,
+		HeaderExtensionDirection(RtpHeaderExtension{Uri: PlayoutDelayUri}))
+	assert.Equal(t, HeaderExtensionDirectionSendRecv,
+		HeaderExtensionDirection(RtpHeaderExtension{Uri: VideoOrientationUri}))
+	assert.Equal(t, HeaderExtensionDirectionInactive,
+		HeaderExtensionDirection(RtpHeaderExtension{Uri: VideoOrientationUri, Direction: HeaderExtensionDirectionInactive}))
+}
+
+func TestHeaderExtensionDirection_Mapping(t *testing.T) {
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "video", MimeType: "video/VP8", ClockRate: 90000, PreferredPayloadType: 100},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "video", Uri: PlayoutDelayUri, PreferredId: 9},
+			{Kind: "video", Uri: VideoOrientationUri, PreferredId: 4, Direction: HeaderExtensionDirectionSendOnly},
+			{Kind: "video", Uri: "urn:ietf:params:rtp-hdrext:toffset", PreferredId: 2},
+		},
+	}
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: PlayoutDelayUri, Id: 1},
+			{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 2},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1111}},
+	}
+
+	rtpMapping, err := GetProducerRtpParametersMapping(params, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtpMappingHeaderExt{{Id: 1, MappedId: 9}, {Id: 2, MappedId: 2}}, rtpMapping.HeaderExtensions)
+
+	consumableParams, err := GetConsumableRtpParameters("video", params, caps, rtpMapping)
+	assert.NoError(t, err)
+	assert.Equal(t, []RtpHeaderExtension{
+		{Uri: VideoOrientationUri, Id: 4},
+		{Uri: "urn:ietf:params:rtp-hdrext:toffset", Id: 2},
+	}, consumableParams.HeaderExtensions)
+
+	// The Router doesn't receive sendonly extensions.
+	params.HeaderExtensions = append(params.HeaderExtensions, RtpHeaderExtension{Uri: VideoOrientationUri, Id: 3})
+
+	_, err = GetProducerRtpParametersMapping(params, caps)
+	assert.IsType(t, NewUnsupportedError(""), err)
+}
@@ -25,6 +25,8 @@ type baseRtpObserver struct {
 	channel  *Channel
 	closed   bool
 	paused   bool
+	// Producers added, to add them again when recreated.
+	producerIds []string
 }
 
 func newRtpObserver(internal internalData, channel *Channel) *baseRtpObserver {
@@ -119,6 +121,13 @@ func (rtpObserver *baseRtpObserver) AddProducer(producerId string) {
 	internal.ProducerId = producerId
 
 	rtpObserver.channel.Request("rtpObserver.addProducer", internal, nil)
+
+	for _, id := range rtpObserver.producerIds {
+		if id == producerId {
+			return
+		}
+	}
+	rtpObserver.producerIds = append(rtpObserver.producerIds, producerId)
 }
 
 // Remove a Producer from the RtpObserver.
@@ -129,4 +138,11 @@ func (rtpObserver *baseRtpObserver) RemoveProducer(producerId string) {
 	internal.ProducerId = producerId
 
 	rtpObserver.channel.Request("rtpObserver.removeProducer", internal, nil)
+
+	for i, id := range rtpObserver.producerIds {
+		if id == producerId {
+			rtpObserver.producerIds = append(rtpObserver.producerIds[:i], rtpObserver.producerIds[i+1:]...)
+			break
+		}
+	}
 }
@@ -0,0 +1,47 @@
+package mediasoup
+
+// Ranges of the RtpObserver settings, as enforced by the worker.
+const (
+	AudioLevelObserverMinInterval    = 250
+	AudioLevelObserverMaxInterval    = 5000
+	AudioLevelObserverMinThreshold   = -127
+	AudioLevelObserverMaxThreshold   = 0
+	ActiveSpeakerObserverMinInterval = 100
+)
+
+// Validate checks the ranges of the AudioLevelObserver settings.
+func (params CreateAudioLevelObserverParams) Validate() error {
+	if params.MaxEntries < 1 {
+		return NewTypeError("invalid maxEntries [maxEntries:%d]", params.MaxEntries)
+	}
+	if params.Threshold < AudioLevelObserverMinThreshold || params.Threshold > AudioLevelObserverMaxThreshold {
+		return NewTypeError("threshold out of range [threshold:%d, min:%d, max:%d]",
+			params.Threshold, AudioLevelObserverMinThreshold, AudioLevelObserverMaxThreshold)
+	}
+	if params.Interval < AudioLevelObserverMinInterval || params.Interval > AudioLevelObserverMaxInterval {
+		return NewTypeError("interval out of range [interval:%d, min:%d, max:%d]",
+			params.Interval, AudioLevelObserverMinInterval, AudioLevelObserverMaxInterval)
+	}
+
+	return nil
+}
+
+// Validate checks the ranges of the ActiveSpeakerObserver settings.
+func (params CreateActiveSpeakerObserverParams) Validate() error {
+	if params.Interval < ActiveSpeakerObserverMinInterval {
+		return NewTypeError("interval out of range [interval:%d, min:%d]",
+			params.Interval, ActiveSpeakerObserverMinInterval)
+	}
+
+	return nil
+}
+
+// Settings returns the settings the AudioLevelObserver was created with.
+func (o *AudioLevelObserver) Settings() CreateAudioLevelObserverParams {
+	return o.settings
+}
+
+// Settings returns the settings the ActiveSpeakerObserver was created with.
+func (o *ActiveSpeakerObserver) Settings() CreateActiveSpeakerObserverParams {
+	return o.settings
+}
@@ -0,0 +1,47 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAudioLevelObserver_ValidatesSettings(t *testing.T) {
+	worker := newTestWorker()
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
+
+	settings := CreateAudioLevelObserverParams{MaxEntries: 1, Threshold: -60, Interval: 500}
+	observer, err := router.CreateAudioLevelObserver(&settings)
+	assert.NoError(t, err)
+	assert.Equal(t, settings, observer.(*AudioLevelObserver).Settings())
+
+	// A request to the closed channel would fail with an InvalidStateError.
+	worker.channel.Close()
+
+	for _, params := range []CreateAudioLevelObserverParams{
+		{Threshold: -60, Interval: 500},
+		{MaxEntries: 1, Threshold: 10, Interval: 500},
+		{MaxEntries: 1, Threshold: -128, Interval: 500},
+		{MaxEntries: 1, Threshold: -60, Interval: 100},
+		{MaxEntries: 1, Threshold: -60, Interval: 6000},
+	} {
+		_, err = router.CreateAudioLevelObserver(&params)
+		assert.IsType(t, NewTypeError(""), err)
+	}
+}
+
+func TestCreateActiveSpeakerObserver_ValidatesSettings(t *testing.T) {
+	worker := newTestWorker()
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
+
+	observer, err := router.CreateActiveSpeakerObserver(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 300, observer.(*ActiveSpeakerObserver).Settings().Interval)
+
+	worker.channel.Close()
+
+	_, err = router.CreateActiveSpeakerObserver(&CreateActiveSpeakerObserverParams{Interval: 50})
+	assert.IsType(t, NewTypeError(""), err)
+}
@@ -0,0 +1,138 @@
+package mediasoup
+
+/**
+ * The ORTC functions (GenerateRouterRtpCapabilities,
+ * GetProducerRtpParametersMapping, GetConsumableRtpParameters,
+ * GetConsumerRtpParameters, GetPipeConsumerRtpParameters...) never return data
+ * aliased to their inputs: they work on deep copies made by the Clone methods
+ * below, so a Producer, its Consumers and the Router never share codec
+ * parameters, RTCP feedback, header extension parameters or encodings, and
+ * mutating one of them cannot corrupt the others.
+ */
+
+// Clone returns a deep copy of the capabilities.
+func (caps RtpCapabilities) Clone() RtpCapabilities {
+	caps.Codecs = cloneRtpCodecCapabilities(caps.Codecs)
+	caps.HeaderExtensions = cloneRtpHeaderExtensions(caps.HeaderExtensions)
+
+	if caps.FecMechanisms != nil {
+		caps.FecMechanisms = append([]string{}, caps.FecMechanisms...)
+	}
+
+	return caps
+}
+
+// Clone returns a deep copy of the parameters.
+func (params RtpParameters) Clone() RtpParameters {
+	params.Codecs = cloneRtpCodecCapabilities(params.Codecs)
+	params.HeaderExtensions = cloneRtpHeaderExtensions(params.HeaderExtensions)
+	params.Rtcp = params.Rtcp.Clone()
+
+	if params.Encodings != nil {
+		encodings := make([]RtpEncoding, len(params.Encodings))
+
+		for i, encoding := range params.Encodings {
+			encodings[i] = encoding.Clone()
+		}
+		params.Encodings = encodings
+	}
+
+	return params
+}
+
+// Clone returns a deep copy of the codec.
+func (codec RtpCodecCapability) Clone() RtpCodecCapability {
+	if codec.Parameters != nil {
+		parameters := codec.Parameters.Clone()
+		codec.Parameters = &parameters
+	}
+	if codec.RtcpFeedback != nil {
+		codec.RtcpFeedback = append([]RtcpFeedback{}, codec.RtcpFeedback...)
+	}
+
+	return codec
+}
+
+// Clone returns a deep copy of the codec parameters.
+func (params RtpCodecParameter) Clone() RtpCodecParameter {
+	params.ProfileId = cloneUint8(params.ProfileId)
+	params.Profile = cloneUint8(params.Profile)
+	params.LevelIdx = cloneUint8(params.LevelIdx)
+	params.Tier = cloneUint8(params.Tier)
+
+	return params
+}
+
+// Clone returns a copy of the header extension, its parameters map is copied
+// but not the values in it.
+func (ext RtpHeaderExtension) Clone() RtpHeaderExtension {
+	if ext.Encrypt != nil {
+		encrypt := *ext.Encrypt
+		ext.Encrypt = &encrypt
+	}
+	if ext.Parameters != nil {
+		parameters := make(H, len(*ext.Parameters))
+
+		for key, value := range *ext.Parameters {
+			parameters[key] = value
+		}
+		ext.Parameters = &parameters
+	}
+
+	return ext
+}
+
+// Clone returns a deep copy of the encoding.
+func (encoding RtpEncoding) Clone() RtpEncoding {
+	if encoding.Rtx != nil {
+		rtx := encoding.Rtx.Clone()
+		encoding.Rtx = &rtx
+	}
+
+	return encoding
+}
+
+// Clone returns a deep copy of the RTCP parameters.
+func (rtcp RtcpParameters) Clone() RtcpParameters {
+	if rtcp.Mux != nil {
+		mux := *rtcp.Mux
+		rtcp.Mux = &mux
+	}
+
+	return rtcp
+}
+
+func cloneRtpCodecCapabilities(codecs []RtpCodecCapability) []RtpCodecCapability {
+	if codecs == nil {
+		return nil
+	}
+	clones := make([]RtpCodecCapability, len(codecs))
+
+	for i, codec := range codecs {
+		clones[i] = codec.Clone()
+	}
+
+	return clones
+}
+
+func cloneRtpHeaderExtensions(exts []RtpHeaderExtension) []RtpHeaderExtension {
+	if exts == nil {
+		return nil
+	}
+	clones := make([]RtpHeaderExtension, len(exts))
+
+	for i, ext := range exts {
+		clones[i] = ext.Clone()
+	}
+
+	return clones
+}
+
+func cloneUint8(value *uint8) *uint8 {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+
+	return &clone
+}
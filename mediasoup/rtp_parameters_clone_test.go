@@ -0,0 +1,189 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRtpParametersClone(t *testing.T) {
+	uint8Ptr := func(v uint8) *uint8 { return &v }
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:     "video/VP9",
+				Parameters:   &RtpCodecParameter{ProfileId: uint8Ptr(2)},
+				RtcpFeedback: []RtcpFeedback{{Type: "nack"}},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1, Parameters: &H{"foo": "bar"}},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 1, Rtx: &RtpEncoding{Ssrc: 2}}},
+		Rtcp:      RtcpParameters{Mux: newBool(true)},
+	}
+	clone := params.Clone()
+	assert.Equal(t, params, clone)
+
+	*clone.Codecs[0].Parameters.ProfileId = 0
+	clone.Codecs[0].RtcpFeedback[0].Type = "ccm"
+	(*clone.HeaderExtensions[0].Parameters)["foo"] = "baz"
+	clone.Encodings[0].Rtx.Ssrc = 3
+	*clone.Rtcp.Mux = false
+
+	assert.EqualValues(t, 2, *params.Codecs[0].Parameters.ProfileId)
+	assert.Equal(t, "nack", params.Codecs[0].RtcpFeedback[0].Type)
+	assert.Equal(t, "bar", (*params.HeaderExtensions[0].Parameters)["foo"])
+	assert.EqualValues(t, 2, params.Encodings[0].Rtx.Ssrc)
+	assert.True(t, *params.Rtcp.Mux)
+}
+
+func TestOrtcResultsAreNotAliased(t *testing.T) {
+	mediaCodecs := []RtpCodecCapability{
+		{
+			Kind:      "audio",
+			MimeType:  "audio/opus",
+			ClockRate: 48000,
+			Channels:  2,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/H264",
+			ClockRate: 90000,
+			Parameters: &RtpCodecParameter{
+				RtpH264Parameter: h264profile.RtpH264Parameter{
+					LevelAsymmetryAllowed: 1,
+					PacketizationMode:     1,
+					ProfileLevelId:        "42e01f",
+				},
+			},
+		},
+	}
+	mediaCodecsSnapshot := cloneRtpCodecCapabilities(mediaCodecs)
+
+	routerRtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	assert.NoError(t, err)
+	assert.Equal(t, mediaCodecsSnapshot, mediaCodecs)
+
+	// A higher level than the router one, which the mapping lowers to the
+	// answer level.
+	rtpParameters := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{
+				MimeType:     "video/H264",
+				ClockRate:    90000,
+				PayloadType:  111,
+				RtcpFeedback: []RtcpFeedback{{Type: "nack"}},
+				Parameters: &RtpCodecParameter{
+					RtpH264Parameter: h264profile.RtpH264Parameter{
+						LevelAsymmetryAllowed: 1,
+						PacketizationMode:     1,
+						ProfileLevelId:        "42e034",
+					},
+				},
+			},
+		},
+		HeaderExtensions: []RtpHeaderExtension{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncoding{{Ssrc: 11111111}},
+		Rtcp:      RtcpParameters{Cname: "qwerty1234"},
+	}
+	rtpParametersSnapshot := rtpParameters.Clone()
+	routerRtpCapabilitiesSnapshot := routerRtpCapabilities.Clone()
+
+	rtpMapping, err := GetProducerRtpParametersMapping(rtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+	assert.Equal(t, rtpParametersSnapshot, rtpParameters)
+
+	consumableRtpParameters, err := GetConsumableRtpParameters("video",
+		rtpParameters, routerRtpCapabilities, rtpMapping)
+	assert.NoError(t, err)
+	consumableRtpParametersSnapshot := consumableRtpParameters.Clone()
+
+	consumerRtpParameters, err := GetConsumerRtpParameters(
+		consumableRtpParameters, routerRtpCapabilities)
+	assert.NoError(t, err)
+
+	pipeConsumerRtpParameters := GetPipeConsumerRtpParameters(consumableRtpParameters)
+
+	assert.Equal(t, rtpParametersSnapshot, rtpParameters)
+	assert.Equal(t, routerRtpCapabilitiesSnapshot, routerRtpCapabilities)
+	assert.Equal(t, consumableRtpParametersSnapshot, consumableRtpParameters)
+
+	// Corrupting any result must not leak into another entity.
+	all := []*RtpParameters{
+		&rtpParameters, &consumableRtpParameters, &consumerRtpParameters, &pipeConsumerRtpParameters,
+	}
+	snapshots := make([]RtpParameters, len(all))
+
+	for i, params := range all {
+		snapshots[i] = params.Clone()
+	}
+
+	for i, params := range all {
+		corruptRtpParameters(*params)
+
+		for j, other := range all {
+			if j != i {
+				assert.Equal(t, snapshots[j], *other)
+			}
+		}
+		assert.Equal(t, routerRtpCapabilitiesSnapshot, routerRtpCapabilities)
+
+		*params = snapshots[i].Clone()
+	}
+
+	for _, codec := range routerRtpCapabilities.Codecs {
+		if codec.Parameters != nil {
+			codec.Parameters.ProfileLevelId = "corrupted"
+		}
+	}
+	for i, params := range all {
+		assert.Equal(t, snapshots[i], *params)
+	}
+	assert.Equal(t, mediaCodecsSnapshot, mediaCodecs)
+}
+
+func corruptRtpParameters(params RtpParameters) {
+	for _, codec := range params.Codecs {
+		codec.Parameters.ProfileLevelId = "corrupted"
+		codec.Parameters.Apt = 1
+
+		for i := range codec.RtcpFeedback {
+			codec.RtcpFeedback[i].Type = "corrupted"
+		}
+	}
+	for _, encoding := range params.Encodings {
+		if encoding.Rtx != nil {
+			encoding.Rtx.Ssrc = 1
+		}
+	}
+	if params.Rtcp.Mux != nil {
+		*params.Rtcp.Mux = false
+	}
+}
+
+func TestSupportedRtpCapabilitiesAreNotAliased(t *testing.T) {
+	snapshot := GetSupportedRtpCapabilities()
+
+	caps := GetSupportedRtpCapabilities()
+	caps.Codecs[0].MimeType = "audio/corrupted"
+	caps.HeaderExtensions[0].Direction = HeaderExtensionDirectionInactive
+
+	assert.Equal(t, snapshot, GetSupportedRtpCapabilities())
+
+	// The overrides of a Router do not change the given capabilities.
+	override := GetSupportedRtpCapabilities()
+	overrideSnapshot := override.Clone()
+
+	routerCaps, err := routerSupportedRtpCapabilities(&RouterOptions{
+		SupportedRtpCapabilities: &override,
+		AdditionalCodecs:         []RtpCodecCapability{{Kind: "video", MimeType: "video/AV2", ClockRate: 90000}},
+	})
+	assert.NoError(t, err)
+	routerCaps.HeaderExtensions[0].Direction = HeaderExtensionDirectionInactive
+
+	assert.Equal(t, overrideSnapshot, override)
+}
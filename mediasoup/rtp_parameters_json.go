@@ -0,0 +1,100 @@
+package mediasoup
+
+import "encoding/json"
+
+// The structs in rtp_capabilities.go use omitempty everywhere, which loses
+// values the worker treats as meaningful: payload type 0 (PCMU) and an
+// explicit `reducedSize: false`. The marshalers below restore those fields
+// while keeping the rest of the camelCase encoding untouched.
+
+// rtpCodecCapabilityJSON has the fields of RtpCodecCapability but none of its
+// methods, so it can be embedded without recursing into MarshalJSON.
+type rtpCodecCapabilityJSON RtpCodecCapability
+
+// rtpParametersCodecJSON always encodes payloadType, which is mandatory in
+// RtpParameters codecs.
+type rtpParametersCodecJSON struct {
+	rtpCodecCapabilityJSON
+	PayloadType int `json:"payloadType"`
+}
+
+// rtpCapabilitiesCodecJSON always encodes preferredPayloadType, which is
+// mandatory in RtpCapabilities codecs.
+type rtpCapabilitiesCodecJSON struct {
+	rtpCodecCapabilityJSON
+	PreferredPayloadType int `json:"preferredPayloadType"`
+}
+
+// MarshalJSON encodes the capabilities keeping preferredPayloadType 0.
+func (caps RtpCapabilities) MarshalJSON() ([]byte, error) {
+	type rtpCapabilitiesJSON RtpCapabilities
+
+	var codecs []rtpCapabilitiesCodecJSON
+
+	for _, codec := range caps.Codecs {
+		codecs = append(codecs, rtpCapabilitiesCodecJSON{
+			rtpCodecCapabilityJSON: rtpCodecCapabilityJSON(codec),
+			PreferredPayloadType:   codec.PreferredPayloadType,
+		})
+	}
+
+	return json.Marshal(struct {
+		rtpCapabilitiesJSON
+		Codecs []rtpCapabilitiesCodecJSON `json:"codecs,omitempty"`
+	}{
+		rtpCapabilitiesJSON: rtpCapabilitiesJSON(caps),
+		Codecs:              codecs,
+	})
+}
+
+// MarshalJSON encodes the parameters keeping payloadType 0.
+func (params RtpParameters) MarshalJSON() ([]byte, error) {
+	type rtpParametersJSON RtpParameters
+
+	var codecs []rtpParametersCodecJSON
+
+	for _, codec := range params.Codecs {
+		codecs = append(codecs, rtpParametersCodecJSON{
+			rtpCodecCapabilityJSON: rtpCodecCapabilityJSON(codec),
+			PayloadType:            codec.PayloadType,
+		})
+	}
+
+	return json.Marshal(struct {
+		rtpParametersJSON
+		Codecs []rtpParametersCodecJSON `json:"codecs,omitempty"`
+	}{
+		rtpParametersJSON: rtpParametersJSON(params),
+		Codecs:            codecs,
+	})
+}
+
+// MarshalJSON always encodes reducedSize, since the worker reads a missing
+// value as true.
+func (rtcp RtcpParameters) MarshalJSON() ([]byte, error) {
+	type rtcpParametersJSON RtcpParameters
+
+	return json.Marshal(struct {
+		rtcpParametersJSON
+		ReducedSize bool `json:"reducedSize"`
+	}{
+		rtcpParametersJSON: rtcpParametersJSON(rtcp),
+		ReducedSize:        rtcp.ReducedSize,
+	})
+}
+
+// UnmarshalJSON decodes the parameters defaulting reducedSize to true, as
+// the worker and mediasoup-client do.
+func (rtcp *RtcpParameters) UnmarshalJSON(data []byte) error {
+	type rtcpParametersJSON RtcpParameters
+
+	v := rtcpParametersJSON{ReducedSize: true}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*rtcp = RtcpParameters(v)
+
+	return nil
+}
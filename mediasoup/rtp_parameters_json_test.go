@@ -0,0 +1,82 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRtpParametersJSON_RoundTrip(t *testing.T) {
+	const parametersJSON = `{
+		"mid": "AUDIO",
+		"codecs": [
+			{
+				"mimeType": "audio/PCMU",
+				"payloadType": 0,
+				"clockRate": 8000
+			},
+			{
+				"mimeType": "audio/opus",
+				"payloadType": 111,
+				"clockRate": 48000,
+				"channels": 2,
+				"parameters": { "useinbandfec": 1, "usedtx": 1 },
+				"rtcpFeedback": [ { "type": "transport-cc" } ]
+			}
+		],
+		"headerExtensions": [
+			{ "uri": "urn:ietf:params:rtp-hdrext:sdes:mid", "id": 1, "encrypt": false }
+		],
+		"encodings": [ { "ssrc": 1111, "dtx": true, "maxBitrate": 64000 } ],
+		"rtcp": { "cname": "FOOBAR", "reducedSize": false }
+	}`
+
+	var params RtpParameters
+
+	assert.NoError(t, json.Unmarshal([]byte(parametersJSON), &params))
+	assert.Equal(t, 0, params.Codecs[0].PayloadType)
+	assert.False(t, params.Rtcp.ReducedSize)
+
+	data, err := json.Marshal(params)
+	assert.NoError(t, err)
+	assert.JSONEq(t, parametersJSON, string(data))
+
+	var again RtpParameters
+
+	assert.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, params, again)
+}
+
+func TestRtpCapabilitiesJSON_PreferredPayloadTypeZero(t *testing.T) {
+	caps := RtpCapabilities{
+		Codecs: []RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/PCMU", ClockRate: 8000, PreferredPayloadType: 0},
+		},
+	}
+
+	data, err := json.Marshal(caps)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"codecs":[{"kind":"audio","mimeType":"audio/PCMU","clockRate":8000,"preferredPayloadType":0}]}`, string(data))
+
+	data, err = json.Marshal(RtpCapabilities{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
+
+func TestRtcpParametersJSON_ReducedSizeDefault(t *testing.T) {
+	var rtcp RtcpParameters
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"cname":"FOOBAR"}`), &rtcp))
+	assert.Equal(t, RtcpParameters{Cname: "FOOBAR", ReducedSize: true}, rtcp)
+
+	data, err := json.Marshal(RtcpParameters{Mux: newBool(true)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"reducedSize":false,"mux":true}`, string(data))
+}
+
+func TestRtpMappingParametersJSON_PayloadTypeZero(t *testing.T) {
+	data, err := json.Marshal(RtpMappingCodec{PayloadType: 0, MappedPayloadType: 100})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"payloadType":0,"mappedPayloadType":100}`, string(data))
+}
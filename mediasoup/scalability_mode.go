@@ -0,0 +1,90 @@
+package mediasoup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var scalabilityModeRegex = regexp.MustCompile(`^[LS]([1-9][0-9]?)T([1-9][0-9]?)(_KEY)?`)
+
+// ScalabilityMode is a parsed scalabilityMode of an encoding, e.g. "L3T3" or
+// "L3T3_KEY" for K-SVC.
+type ScalabilityMode struct {
+	SpatialLayers  int
+	TemporalLayers int
+	Ksvc           bool
+}
+
+/**
+ * ParseScalabilityMode parses a scalabilityMode as defined in the WebRTC SVC
+ * specification (LxTy, SxTy and their _KEY K-SVC variants). Like mediasoup,
+ * an empty or unknown mode means a single spatial and temporal layer.
+ */
+func ParseScalabilityMode(mode string) ScalabilityMode {
+	match := scalabilityModeRegex.FindStringSubmatch(mode)
+
+	if match == nil {
+		return ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1}
+	}
+
+	spatialLayers, _ := strconv.Atoi(match[1])
+	temporalLayers, _ := strconv.Atoi(match[2])
+
+	return ScalabilityMode{
+		SpatialLayers:  spatialLayers,
+		TemporalLayers: temporalLayers,
+		Ksvc:           len(match[3]) > 0,
+	}
+}
+
+func (mode ScalabilityMode) String() string {
+	s := fmt.Sprintf("L%dT%d", mode.SpatialLayers, mode.TemporalLayers)
+
+	if mode.Ksvc {
+		s += "_KEY"
+	}
+
+	return s
+}
+
+/**
+ * consumerScalabilityMode returns the scalabilityMode of the single encoding
+ * of a Consumer of the given consumable encodings: simulcast streams become
+ * spatial layers, with the temporal layers of the first stream.
+ */
+func consumerScalabilityMode(encodings []RtpEncoding) string {
+	if len(encodings) == 0 {
+		return ""
+	}
+
+	mode := ParseScalabilityMode(encodings[0].ScalabilityMode)
+
+	if len(encodings) > 1 {
+		mode.SpatialLayers = len(encodings)
+		mode.Ksvc = false
+	} else if len(encodings[0].ScalabilityMode) == 0 {
+		return ""
+	}
+
+	return mode.String()
+}
+
+/**
+ * MaxPreferredLayers returns the highest spatial and temporal layers a
+ * Consumer with the given RTP parameters can be set to, e.g. with
+ * Consumer.SetPreferredLayers().
+ */
+func MaxPreferredLayers(params RtpParameters) (spatialLayer, temporalLayer uint8) {
+	if len(params.Encodings) == 0 {
+		return
+	}
+
+	mode := ParseScalabilityMode(params.Encodings[0].ScalabilityMode)
+
+	if len(params.Encodings) > 1 {
+		mode.SpatialLayers = len(params.Encodings)
+	}
+
+	return uint8(mode.SpatialLayers - 1), uint8(mode.TemporalLayers - 1)
+}
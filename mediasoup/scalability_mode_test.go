@@ -0,0 +1,76 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScalabilityMode(t *testing.T) {
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1}, ParseScalabilityMode(""))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1}, ParseScalabilityMode("foo"))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1}, ParseScalabilityMode("L0T3"))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 1, TemporalLayers: 3}, ParseScalabilityMode("L1T3"))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 3, TemporalLayers: 2}, ParseScalabilityMode("S3T2"))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 3, TemporalLayers: 3, Ksvc: true}, ParseScalabilityMode("L3T3_KEY"))
+	assert.Equal(t, ScalabilityMode{SpatialLayers: 10, TemporalLayers: 12}, ParseScalabilityMode("L10T12"))
+
+	assert.Equal(t, "L3T3_KEY", ParseScalabilityMode("L3T3_KEY").String())
+}
+
+func TestConsumerScalabilityMode(t *testing.T) {
+	assert.Equal(t, "", consumerScalabilityMode(nil))
+	assert.Equal(t, "", consumerScalabilityMode([]RtpEncoding{{}}))
+	assert.Equal(t, "L3T3_KEY", consumerScalabilityMode([]RtpEncoding{{ScalabilityMode: "L3T3_KEY"}}))
+	assert.Equal(t, "L3T1", consumerScalabilityMode([]RtpEncoding{{}, {}, {}}))
+	assert.Equal(t, "L2T3", consumerScalabilityMode([]RtpEncoding{{ScalabilityMode: "L1T3"}, {ScalabilityMode: "L1T3"}}))
+}
+
+func TestMaxPreferredLayers(t *testing.T) {
+	spatial, temporal := MaxPreferredLayers(RtpParameters{})
+	assert.Zero(t, spatial)
+	assert.Zero(t, temporal)
+
+	spatial, temporal = MaxPreferredLayers(RtpParameters{Encodings: []RtpEncoding{{ScalabilityMode: "L3T3"}}})
+	assert.EqualValues(t, 2, spatial)
+	assert.EqualValues(t, 2, temporal)
+
+	spatial, temporal = MaxPreferredLayers(RtpParameters{Encodings: []RtpEncoding{{ScalabilityMode: "L1T2"}, {}}})
+	assert.EqualValues(t, 1, spatial)
+	assert.EqualValues(t, 1, temporal)
+}
+
+func TestGetProducerRtpParametersMapping_ScalabilityMode(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+		},
+		Encodings: []RtpEncoding{
+			{Ssrc: 1, ScalabilityMode: "L1T3"},
+			{Ssrc: 2, ScalabilityMode: "L1T3"},
+			{Ssrc: 3, ScalabilityMode: "L1T3"},
+		},
+	}
+
+	mapping, err := GetProducerRtpParametersMapping(params, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, "L1T3", mapping.Encodings[0].ScalabilityMode)
+	assert.Equal(t, 1, mapping.Encodings[0].SpatialLayers)
+	assert.Equal(t, 3, mapping.Encodings[0].TemporalLayers)
+
+	consumableParams, err := GetConsumableRtpParameters(MediaKindVideo, params, caps, mapping)
+	assert.NoError(t, err)
+
+	consumerParams, err := GetConsumerRtpParameters(consumableParams, caps)
+	assert.NoError(t, err)
+	assert.Equal(t, "L3T3", consumerParams.Encodings[0].ScalabilityMode)
+
+	spatial, temporal := MaxPreferredLayers(consumerParams)
+	assert.EqualValues(t, 2, spatial)
+	assert.EqualValues(t, 2, temporal)
+}
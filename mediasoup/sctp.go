@@ -0,0 +1,156 @@
+package mediasoup
+
+import "sync"
+
+// NumSctpStreams of a SCTP association.
+type NumSctpStreams struct {
+	// OS is the initially requested number of outgoing SCTP streams.
+	OS uint16 `json:"OS"`
+	// MIS is the maximum number of incoming SCTP streams.
+	MIS uint16 `json:"MIS"`
+}
+
+// SctpCapabilities of an endpoint.
+type SctpCapabilities struct {
+	NumStreams NumSctpStreams `json:"numStreams"`
+}
+
+// SctpStreamParameters of a data channel.
+type SctpStreamParameters struct {
+	StreamId uint16 `json:"streamId"`
+	// Ordered delivery, default true.
+	Ordered           *bool  `json:"ordered,omitempty"`
+	MaxPacketLifeTime uint16 `json:"maxPacketLifeTime,omitempty"`
+	MaxRetransmits    uint16 `json:"maxRetransmits,omitempty"`
+}
+
+// Stream id conventions of a SCTP endpoint (RFC 8832 section 6): the DTLS
+// client uses even stream ids and the DTLS server odd ones.
+const (
+	SctpStreamIdsAny  = "any"
+	SctpStreamIdsEven = "even"
+	SctpStreamIdsOdd  = "odd"
+)
+
+/**
+ * SctpStreamIdAllocator hands out the SCTP stream ids of a transport, within
+ * the negotiated number of streams and following the even/odd convention of
+ * the endpoint. Ids are allocated round-robin, so a released id (e.g. when its
+ * data consumer is closed) is reused as late as possible.
+ */
+type SctpStreamIdAllocator struct {
+	locker sync.Mutex
+	used   []bool
+	first  int
+	step   int
+	next   int
+	count  int
+}
+
+// NewSctpStreamIdAllocator creates an allocator of the stream ids lower than
+// min(OS, MIS) with the given convention, SctpStreamIdsAny if empty.
+func NewSctpStreamIdAllocator(numStreams NumSctpStreams, convention string) (*SctpStreamIdAllocator, error) {
+	if numStreams.OS == 0 || numStreams.MIS == 0 {
+		return nil, NewTypeError("invalid numSctpStreams [OS:%d, MIS:%d]", numStreams.OS, numStreams.MIS)
+	}
+
+	allocator := &SctpStreamIdAllocator{step: 1}
+
+	switch convention {
+	case "", SctpStreamIdsAny:
+	case SctpStreamIdsEven:
+		allocator.step = 2
+	case SctpStreamIdsOdd:
+		allocator.first, allocator.step = 1, 2
+	default:
+		return nil, NewTypeError("invalid SCTP stream id convention [%s]", convention)
+	}
+
+	max := numStreams.OS
+	if numStreams.MIS < max {
+		max = numStreams.MIS
+	}
+
+	allocator.used = make([]bool, max)
+	allocator.next = allocator.first
+
+	return allocator, nil
+}
+
+// Allocate returns the next free stream id.
+func (allocator *SctpStreamIdAllocator) Allocate() (streamId uint16, err error) {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	size := len(allocator.used)
+
+	for i := allocator.first; i < size; i += allocator.step {
+		id := allocator.next
+
+		allocator.next += allocator.step
+		if allocator.next >= size {
+			allocator.next = allocator.first
+		}
+
+		if !allocator.used[id] {
+			allocator.used[id] = true
+			allocator.count++
+
+			return uint16(id), nil
+		}
+	}
+
+	err = NewInvalidStateError("no SCTP stream id available")
+	return
+}
+
+// Reserve marks a stream id chosen by the application as used.
+func (allocator *SctpStreamIdAllocator) Reserve(streamId uint16) error {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	if err := allocator.check(streamId); err != nil {
+		return err
+	}
+	if allocator.used[streamId] {
+		return NewTypeError("SCTP stream id already in use [streamId:%d]", streamId)
+	}
+
+	allocator.used[streamId] = true
+	allocator.count++
+
+	return nil
+}
+
+// Release frees a stream id, e.g. once its data consumer is closed.
+func (allocator *SctpStreamIdAllocator) Release(streamId uint16) {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	if allocator.check(streamId) == nil && allocator.used[streamId] {
+		allocator.used[streamId] = false
+		allocator.count--
+	}
+}
+
+// Available returns the number of free stream ids.
+func (allocator *SctpStreamIdAllocator) Available() int {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	total := (len(allocator.used) - allocator.first + allocator.step - 1) / allocator.step
+
+	return total - allocator.count
+}
+
+func (allocator *SctpStreamIdAllocator) check(streamId uint16) error {
+	if int(streamId) >= len(allocator.used) {
+		return NewTypeError("SCTP stream id out of range [streamId:%d, max:%d]",
+			streamId, len(allocator.used)-1)
+	}
+	if (int(streamId)-allocator.first)%allocator.step != 0 {
+		return NewTypeError("SCTP stream id does not follow the convention [streamId:%d]", streamId)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+type SctpSendFlowControlOptions struct {
+	// AutoPause pauses the senders once the send buffer is full.
+	AutoPause bool
+	// Clock of the WaitDrained() timeouts, default clock.System.
+	Clock clock.Clock
+}
+
+/**
+ * SctpSendFlowControl tracks the SCTP send buffer of a data consumer from the
+ * "sctpsendbufferfull" and "bufferedamountlow" worker notifications, given to
+ * HandleNotification(). With options.AutoPause, senders calling WaitDrained() block
+ * from the moment the buffer is full until its buffered amount goes below the
+ * threshold, instead of having their messages dropped by the worker.
+ *
+ * @emits sctpsendbufferfull
+ * @emits {bufferedAmount uint32} bufferedamountlow
+ */
+type SctpSendFlowControl struct {
+	EventEmitter
+	locker         sync.Mutex
+	options        SctpSendFlowControlOptions
+	bufferedAmount uint32
+	threshold      uint32
+	// Closed once drained, nil if not paused.
+	drainedCh chan struct{}
+}
+
+func NewSctpSendFlowControl(options SctpSendFlowControlOptions) *SctpSendFlowControl {
+	options.Clock = clock.OrSystem(options.Clock)
+
+	return &SctpSendFlowControl{
+		EventEmitter: NewEventEmitter(TypeLogger("SctpSendFlowControl")),
+		options:      options,
+	}
+}
+
+// BufferedAmount is the last known number of bytes in the send buffer.
+func (f *SctpSendFlowControl) BufferedAmount() uint32 {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	return f.bufferedAmount
+}
+
+// SetBufferedAmount updates the buffered amount, e.g. with the response of
+// "dataConsumer.getBufferedAmount", resuming the senders if it's below the
+// threshold.
+func (f *SctpSendFlowControl) SetBufferedAmount(bufferedAmount uint32) {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	f.bufferedAmount = bufferedAmount
+
+	if bufferedAmount <= f.threshold {
+		f.resume()
+	}
+}
+
+func (f *SctpSendFlowControl) BufferedAmountLowThreshold() uint32 {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	return f.threshold
+}
+
+/**
+ * Set the buffered amount below which the worker emits "bufferedamountlow".
+ * The same value must be given to the worker with the
+ * "dataConsumer.setBufferedAmountLowThreshold" request.
+ */
+func (f *SctpSendFlowControl) SetBufferedAmountLowThreshold(threshold uint32) {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	f.threshold = threshold
+}
+
+// Whether senders are paused until the buffer is drained.
+func (f *SctpSendFlowControl) Paused() bool {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	return f.drainedCh != nil
+}
+
+/**
+ * Wait until the send buffer is drained, returning right away if not paused.
+ *
+ * @throws {TimeoutError} if still paused after timeout, 0 waits forever.
+ */
+func (f *SctpSendFlowControl) WaitDrained(timeout time.Duration) error {
+	f.locker.Lock()
+	drainedCh := f.drainedCh
+	f.locker.Unlock()
+
+	if drainedCh == nil {
+		return nil
+	}
+	if timeout == 0 {
+		<-drainedCh
+		return nil
+	}
+
+	timer := f.options.Clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drainedCh:
+		return nil
+	case <-timer.C():
+		return NewTimeoutError("SCTP send buffer not drained after %s", timeout)
+	}
+}
+
+/**
+ * Handle a notification of the data consumer.
+ *
+ * @throws {TypeError} if the event is unknown or its payload invalid.
+ */
+func (f *SctpSendFlowControl) HandleNotification(event string, data json.RawMessage) error {
+	payload, err := DecodeNotification(IdEntityDataConsumer, event, data)
+	if err != nil {
+		return err
+	}
+
+	switch event {
+	case DataConsumerNotificationSctpSendBufferFull:
+		f.locker.Lock()
+		if f.options.AutoPause && f.drainedCh == nil {
+			f.drainedCh = make(chan struct{})
+		}
+		f.locker.Unlock()
+
+		f.SafeEmit("sctpsendbufferfull")
+
+	case DataConsumerNotificationBufferedAmountLow:
+		bufferedAmount := payload.(BufferedAmountLowNotification).BufferedAmount
+
+		f.locker.Lock()
+		f.bufferedAmount = bufferedAmount
+		f.resume()
+		f.locker.Unlock()
+
+		f.SafeEmit("bufferedamountlow", bufferedAmount)
+	}
+
+	return nil
+}
+
+// resume must be called with the lock held.
+func (f *SctpSendFlowControl) resume() {
+	if f.drainedCh != nil {
+		close(f.drainedCh)
+		f.drainedCh = nil
+	}
+}
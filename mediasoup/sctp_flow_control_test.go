@@ -0,0 +1,64 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSctpSendFlowControl(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+	f := NewSctpSendFlowControl(SctpSendFlowControlOptions{AutoPause: true, Clock: fakeClock})
+	f.SetBufferedAmountLowThreshold(1000)
+
+	full := 0
+	lows := make(chan uint32, 1)
+
+	f.On("sctpsendbufferfull", func() { full++ })
+	f.On("bufferedamountlow", func(bufferedAmount uint32) { lows <- bufferedAmount })
+
+	assert.NoError(t, f.WaitDrained(time.Second))
+
+	assert.NoError(t, f.HandleNotification(DataConsumerNotificationSctpSendBufferFull, nil))
+	assert.Equal(t, 1, full)
+	assert.True(t, f.Paused())
+
+	go func() {
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(time.Second)
+	}()
+	assert.IsType(t, NewTimeoutError(""), f.WaitDrained(time.Second))
+
+	doneCh := make(chan error)
+
+	go func() {
+		doneCh <- f.WaitDrained(0)
+	}()
+
+	err := f.HandleNotification(DataConsumerNotificationBufferedAmountLow,
+		json.RawMessage(`{"bufferedAmount":512}`))
+	assert.NoError(t, err)
+	assert.NoError(t, <-doneCh)
+	assert.EqualValues(t, 512, <-lows)
+	assert.EqualValues(t, 512, f.BufferedAmount())
+	assert.False(t, f.Paused())
+
+	// Polled buffered amount.
+	f.HandleNotification(DataConsumerNotificationSctpSendBufferFull, nil)
+	f.SetBufferedAmount(2000)
+	assert.True(t, f.Paused())
+	f.SetBufferedAmount(800)
+	assert.False(t, f.Paused())
+
+	assert.IsType(t, NewTypeError(""), f.HandleNotification("foo", nil))
+}
+
+func TestSctpSendFlowControl_NoAutoPause(t *testing.T) {
+	f := NewSctpSendFlowControl(SctpSendFlowControlOptions{})
+
+	assert.NoError(t, f.HandleNotification(DataConsumerNotificationSctpSendBufferFull, nil))
+	assert.False(t, f.Paused())
+}
@@ -0,0 +1,69 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSctpStreamIdAllocator(t *testing.T) {
+	allocator, err := NewSctpStreamIdAllocator(NumSctpStreams{OS: 1024, MIS: 5}, SctpStreamIdsOdd)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, allocator.Available())
+
+	id, err := allocator.Allocate()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, id)
+
+	id, err = allocator.Allocate()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, id)
+
+	_, err = allocator.Allocate()
+	assert.Error(t, err)
+	assert.Equal(t, 0, allocator.Available())
+
+	allocator.Release(1)
+	assert.Equal(t, 1, allocator.Available())
+
+	id, err = allocator.Allocate()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, id)
+
+	assert.IsType(t, NewTypeError(""), allocator.Reserve(2))
+	assert.IsType(t, NewTypeError(""), allocator.Reserve(5))
+	assert.IsType(t, NewTypeError(""), allocator.Reserve(3))
+}
+
+func TestSctpStreamIdAllocator_RoundRobin(t *testing.T) {
+	allocator, err := NewSctpStreamIdAllocator(NumSctpStreams{OS: 4, MIS: 4}, "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, allocator.Reserve(1))
+
+	var ids []uint16
+
+	for i := 0; i < 3; i++ {
+		id, err := allocator.Allocate()
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []uint16{0, 2, 3}, ids)
+
+	// Released ids are reused as late as possible.
+	allocator.Release(0)
+	allocator.Release(2)
+
+	id, _ := allocator.Allocate()
+	assert.EqualValues(t, 0, id)
+	id, _ = allocator.Allocate()
+	assert.EqualValues(t, 2, id)
+}
+
+func TestNewSctpStreamIdAllocator_TypeError(t *testing.T) {
+	_, err := NewSctpStreamIdAllocator(NumSctpStreams{OS: 0, MIS: 10}, "")
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = NewSctpStreamIdAllocator(NumSctpStreams{OS: 10, MIS: 10}, "foo")
+	assert.IsType(t, NewTypeError(""), err)
+}
@@ -0,0 +1,563 @@
+package sdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	h264 "github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+)
+
+// staticPayloads are the static RTP/AVP payload types commonly used without
+// "a=rtpmap" lines, e.g. by FFmpeg and SIP endpoints.
+var staticPayloads = map[int]RtpMap{
+	0: {Payload: 0, Codec: "PCMU", Rate: 8000},
+	8: {Payload: 8, Codec: "PCMA", Rate: 8000},
+	9: {Payload: 9, Codec: "G722", Rate: 8000},
+}
+
+// RtpCapabilities returns the codecs and header extensions of the audio and
+// video media of the session, e.g. to create a Router matching a SIP
+// endpoint. Codecs are not deduplicated across media.
+func (session *SessionDescription) RtpCapabilities() (caps mediasoup.RtpCapabilities, err error) {
+	for _, media := range session.Media {
+		if media.Type != string(mediasoup.MediaKindAudio) && media.Type != string(mediasoup.MediaKindVideo) {
+			continue
+		}
+
+		mediaCaps, err := media.RtpCapabilities()
+		if err != nil {
+			return caps, err
+		}
+
+		caps.Codecs = append(caps.Codecs, mediaCaps.Codecs...)
+		caps.HeaderExtensions = append(caps.HeaderExtensions, mediaCaps.HeaderExtensions...)
+		caps.ExtmapAllowMixed = caps.ExtmapAllowMixed || mediaCaps.ExtmapAllowMixed
+	}
+
+	caps.ExtmapAllowMixed = caps.ExtmapAllowMixed || session.ExtmapAllowMixed
+
+	return
+}
+
+// RtpCapabilities returns the codecs and header extensions of the media.
+func (media *MediaDescription) RtpCapabilities() (caps mediasoup.RtpCapabilities, err error) {
+	kind := mediasoup.MediaKind(media.Type)
+
+	caps.ExtmapAllowMixed = media.ExtmapAllowMixed
+
+	codecs, err := media.codecs()
+	if err != nil {
+		return
+	}
+
+	for _, codec := range codecs {
+		codec.Kind = kind
+		codec.PreferredPayloadType = codec.PayloadType
+		codec.PayloadType = 0
+
+		caps.Codecs = append(caps.Codecs, codec)
+	}
+
+	for _, ext := range media.Ext {
+		caps.HeaderExtensions = append(caps.HeaderExtensions, mediasoup.RtpHeaderExtension{
+			Kind:        kind,
+			Uri:         ext.Uri,
+			PreferredId: ext.Value,
+			Direction:   ext.Direction,
+		})
+	}
+
+	return
+}
+
+/**
+ * RtpParameters returns the RTP parameters sent by the author of the media,
+ * e.g. to Produce() the stream of an offer. Encodings come from the rids of
+ * the "a=simulcast" line if any, otherwise from the SSRCs and their SIM and
+ * FID groups. There is no encoding if the media has neither (e.g. FFmpeg
+ * SDPs), it must then be set by the caller.
+ */
+func (media *MediaDescription) RtpParameters() (params mediasoup.RtpParameters, err error) {
+	params.Mid = media.Mid
+
+	if params.Codecs, err = media.codecs(); err != nil {
+		return
+	}
+
+	for _, ext := range media.Ext {
+		params.HeaderExtensions = append(params.HeaderExtensions, mediasoup.RtpHeaderExtension{
+			Id:  ext.Value,
+			Uri: ext.Uri,
+		})
+	}
+
+	params.Encodings = media.encodings()
+	params.Rtcp.ReducedSize = media.RtcpRsize
+	params.ExtmapAllowMixed = media.ExtmapAllowMixed
+
+	for _, ssrc := range media.Ssrcs {
+		if ssrc.Attribute == "cname" {
+			params.Rtcp.Cname = ssrc.Value
+			break
+		}
+	}
+
+	return
+}
+
+func (media *MediaDescription) codecs() (codecs []mediasoup.RtpCodecCapability, err error) {
+	for _, payload := range media.Payloads() {
+		rtp, ok := media.rtpMap(payload)
+		if !ok {
+			// Skip unknown dynamic payload types.
+			continue
+		}
+
+		codec := mediasoup.RtpCodecCapability{
+			MimeType:    media.Type + "/" + rtp.Codec,
+			ClockRate:   rtp.Rate,
+			Channels:    rtp.Encoding,
+			PayloadType: payload,
+		}
+
+		if codec.Channels == 0 && media.Type == string(mediasoup.MediaKindAudio) {
+			codec.Channels = 1
+		}
+
+		for _, fmtp := range media.Fmtp {
+			if fmtp.Payload == payload {
+				if codec.Parameters, err = ParseFmtp(fmtp.Config); err != nil {
+					return nil, fmt.Errorf("invalid fmtp [payload:%d]: %s", payload, err)
+				}
+			}
+		}
+
+		for _, fb := range media.RtcpFb {
+			if fb.Payload == "*" || fb.Payload == strconv.Itoa(payload) {
+				codec.RtcpFeedback = append(codec.RtcpFeedback, mediasoup.RtcpFeedback{
+					Type:      fb.Type,
+					Parameter: fb.Subtype,
+				})
+			}
+		}
+
+		codecs = append(codecs, codec)
+	}
+
+	return
+}
+
+func (media *MediaDescription) rtpMap(payload int) (RtpMap, bool) {
+	for _, rtp := range media.Rtp {
+		if rtp.Payload == payload {
+			return rtp, true
+		}
+	}
+
+	rtp, ok := staticPayloads[payload]
+
+	return rtp, ok
+}
+
+func (media *MediaDescription) encodings() (encodings []mediasoup.RtpEncoding) {
+	if simulcast := media.Simulcast; simulcast != nil {
+		streams := simulcast.Send
+		if len(streams) == 0 {
+			streams = simulcast.Recv
+		}
+
+		for _, rid := range SimulcastStreams(streams) {
+			encodings = append(encodings, mediasoup.RtpEncoding{Rid: rid})
+		}
+
+		if len(encodings) > 0 {
+			return
+		}
+	}
+
+	rtxSsrcs := map[uint32]uint32{}
+	var ssrcs []uint32
+
+	for _, group := range media.SsrcGroups {
+		switch group.Semantics {
+		case "FID":
+			if len(group.Ssrcs) == 2 {
+				rtxSsrcs[group.Ssrcs[0]] = group.Ssrcs[1]
+			}
+		case "SIM":
+			ssrcs = group.Ssrcs
+		}
+	}
+
+	if len(ssrcs) == 0 {
+		isRtx := map[uint32]bool{}
+		for _, rtxSsrc := range rtxSsrcs {
+			isRtx[rtxSsrc] = true
+		}
+
+		for _, ssrc := range media.Ssrcs {
+			if !isRtx[ssrc.Id] {
+				ssrcs = append(ssrcs, ssrc.Id)
+				break
+			}
+		}
+	}
+
+	for _, ssrc := range ssrcs {
+		encoding := mediasoup.RtpEncoding{Ssrc: ssrc}
+
+		if rtxSsrc, ok := rtxSsrcs[ssrc]; ok {
+			encoding.Rtx = &mediasoup.RtpEncoding{Ssrc: rtxSsrc}
+		}
+
+		encodings = append(encodings, encoding)
+	}
+
+	return
+}
+
+/**
+ * IceParameters returns the ICE credentials of the media, falling back to
+ * the session level ones.
+ */
+func (session *SessionDescription) IceParameters(media *MediaDescription) mediasoup.IceParameters {
+	return mediasoup.IceParameters{
+		UsernameFragment: orDefault(media.IceUfrag, session.IceUfrag),
+		Password:         orDefault(media.IcePwd, session.IcePwd),
+		IceLite:          session.IceLite,
+	}
+}
+
+/**
+ * DtlsParameters returns the DTLS parameters of the media, falling back to
+ * the session level fingerprint and setup, e.g. to Connect() a
+ * WebRtcTransport. The role is the one of the author of the SDP.
+ */
+func (session *SessionDescription) DtlsParameters(media *MediaDescription) (params mediasoup.DtlsParameters, err error) {
+	fingerprint := media.Fingerprint
+	if fingerprint == nil {
+		fingerprint = session.Fingerprint
+	}
+	if fingerprint == nil {
+		return params, errors.New("missing fingerprint")
+	}
+
+	params.Fingerprints = []mediasoup.DtlsFingerprint{
+		{Algorithm: fingerprint.Type, Value: fingerprint.Hash},
+	}
+
+	switch orDefault(media.Setup, session.Setup) {
+	case "active":
+		params.Role = "client"
+	case "passive":
+		params.Role = "server"
+	default:
+		params.Role = "auto"
+	}
+
+	return
+}
+
+// IceCandidates returns the ICE candidates of the media.
+func (media *MediaDescription) IceCandidates() (candidates []mediasoup.IceCandidate) {
+	for _, candidate := range media.Candidates {
+		candidates = append(candidates, mediasoup.IceCandidate{
+			Foundation: candidate.Foundation,
+			Priority:   candidate.Priority,
+			Ip:         candidate.Ip,
+			Port:       uint16(candidate.Port),
+			Type:       candidate.Type,
+			Protocol:   strings.ToLower(candidate.Transport),
+			TcpType:    candidate.TcpType,
+		})
+	}
+
+	return
+}
+
+// NewSessionDescription returns an empty session description with the usual
+// defaults.
+func NewSessionDescription() *SessionDescription {
+	return &SessionDescription{
+		Origin: Origin{
+			Username:       "mediasoup-go",
+			SessionId:      "0",
+			SessionVersion: 1,
+			NetType:        "IN",
+			IpVer:          4,
+			Address:        "0.0.0.0",
+		},
+		Name:   "-",
+		Timing: "0 0",
+	}
+}
+
+/**
+ * NewMediaDescription returns the media sending the given RTP parameters,
+ * e.g. the ones of a Consumer. The protocol is ProtocolWebRtc and the
+ * direction is left empty, to be set by the caller along the transport
+ * parameters.
+ */
+func NewMediaDescription(kind mediasoup.MediaKind, params mediasoup.RtpParameters) (media *MediaDescription, err error) {
+	media = &MediaDescription{
+		Type:       string(kind),
+		Port:       9,
+		Protocol:   ProtocolWebRtc,
+		Connection: &Connection{IpVer: 4, Address: "0.0.0.0"},
+		Mid:        params.Mid,
+		RtcpMux:    true,
+		RtcpRsize:  params.Rtcp.ReducedSize,
+
+		ExtmapAllowMixed: params.ExtmapAllowMixed,
+	}
+
+	for _, codec := range params.Codecs {
+		_, name, _ := strings.Cut(codec.MimeType, "/")
+		payload := strconv.Itoa(codec.PayloadType)
+
+		media.Formats = append(media.Formats, payload)
+
+		rtp := RtpMap{Payload: codec.PayloadType, Codec: name, Rate: codec.ClockRate}
+		if codec.Channels > 1 {
+			rtp.Encoding = codec.Channels
+		}
+		media.Rtp = append(media.Rtp, rtp)
+
+		if codec.Parameters != nil {
+			config, err := WriteFmtp(codec.Parameters)
+			if err != nil {
+				return nil, err
+			}
+			if len(config) > 0 {
+				media.Fmtp = append(media.Fmtp, Fmtp{Payload: codec.PayloadType, Config: config})
+			}
+		}
+
+		for _, fb := range codec.RtcpFeedback {
+			media.RtcpFb = append(media.RtcpFb, RtcpFb{Payload: payload, Type: fb.Type, Subtype: fb.Parameter})
+		}
+	}
+
+	for _, ext := range params.HeaderExtensions {
+		media.Ext = append(media.Ext, Ext{Value: ext.Id, Uri: ext.Uri})
+	}
+
+	var simSsrcs []uint32
+	var streams []string
+
+	for _, encoding := range params.Encodings {
+		if len(encoding.Rid) > 0 {
+			media.Rids = append(media.Rids, Rid{Id: encoding.Rid, Direction: "send"})
+			streams = append(streams, encoding.Rid)
+		}
+		if encoding.Ssrc == 0 {
+			continue
+		}
+
+		simSsrcs = append(simSsrcs, encoding.Ssrc)
+		media.addSsrc(encoding.Ssrc, params.Rtcp.Cname)
+
+		if encoding.Rtx != nil && encoding.Rtx.Ssrc != 0 {
+			media.addSsrc(encoding.Rtx.Ssrc, params.Rtcp.Cname)
+			media.SsrcGroups = append(media.SsrcGroups, SsrcGroup{
+				Semantics: "FID",
+				Ssrcs:     []uint32{encoding.Ssrc, encoding.Rtx.Ssrc},
+			})
+		}
+	}
+
+	if len(streams) > 0 {
+		media.Simulcast = &Simulcast{Send: strings.Join(streams, ";")}
+	}
+	if len(simSsrcs) > 1 {
+		media.SsrcGroups = append([]SsrcGroup{{Semantics: "SIM", Ssrcs: simSsrcs}}, media.SsrcGroups...)
+	}
+
+	return
+}
+
+func (media *MediaDescription) addSsrc(ssrc uint32, cname string) {
+	if len(cname) > 0 {
+		media.Ssrcs = append(media.Ssrcs, Ssrc{Id: ssrc, Attribute: "cname", Value: cname})
+	}
+}
+
+// SetIceParameters sets the ICE credentials of the media.
+func (media *MediaDescription) SetIceParameters(params mediasoup.IceParameters) {
+	media.IceUfrag = params.UsernameFragment
+	media.IcePwd = params.Password
+}
+
+// SetIceCandidates sets the ICE candidates of the media, as RTP components.
+func (media *MediaDescription) SetIceCandidates(candidates []mediasoup.IceCandidate) {
+	media.Candidates = media.Candidates[:0]
+
+	for _, candidate := range candidates {
+		media.Candidates = append(media.Candidates, Candidate{
+			Foundation: candidate.Foundation,
+			Component:  1,
+			Transport:  candidate.Protocol,
+			Priority:   candidate.Priority,
+			Ip:         candidate.Ip,
+			Port:       int(candidate.Port),
+			Type:       candidate.Type,
+			TcpType:    candidate.TcpType,
+		})
+	}
+
+	media.EndOfCandidates = len(candidates) > 0
+}
+
+/**
+ * SetDtlsParameters sets the fingerprint and setup of the media from the
+ * local DTLS parameters, e.g. the ones of a WebRtcTransport. The sha-256
+ * fingerprint is preferred, as browsers do.
+ */
+func (media *MediaDescription) SetDtlsParameters(params mediasoup.DtlsParameters) error {
+	if len(params.Fingerprints) == 0 {
+		return errors.New("missing fingerprint")
+	}
+
+	fingerprint := params.Fingerprints[len(params.Fingerprints)-1]
+
+	for _, f := range params.Fingerprints {
+		if f.Algorithm == "sha-256" {
+			fingerprint = f
+		}
+	}
+
+	media.Fingerprint = &Fingerprint{Type: fingerprint.Algorithm, Hash: fingerprint.Value}
+
+	switch params.Role {
+	case "client":
+		media.Setup = "active"
+	case "server":
+		media.Setup = "passive"
+	default:
+		media.Setup = "actpass"
+	}
+
+	return nil
+}
+
+/**
+ * SrtpParameters returns the remote SRTP parameters of the first "a=crypto"
+ * line with a crypto suite supported by mediasoup, e.g. to Connect() a
+ * PlainRtpTransport to a SIP gateway. Lifetime and MKI key parameters are not
+ * supported.
+ */
+func (media *MediaDescription) SrtpParameters() (params mediasoup.SrtpParameters, err error) {
+	for _, crypto := range media.Crypto {
+		if !strings.HasPrefix(crypto.KeyParams, "inline:") || strings.Contains(crypto.KeyParams, "|") {
+			continue
+		}
+		key := strings.TrimPrefix(crypto.KeyParams, "inline:")
+
+		params = mediasoup.SrtpParameters{CryptoSuite: crypto.Suite, KeyBase64: key}
+
+		if params.Validate() == nil {
+			return
+		}
+	}
+
+	return mediasoup.SrtpParameters{}, errors.New("no supported crypto")
+}
+
+/**
+ * SetSrtpParameters sets the "a=crypto" line of the media from the local
+ * SRTP parameters, e.g. the ones of a PlainRtpTransport with SRTP enabled,
+ * and switches a RTP/AVP(F) protocol to RTP/SAVP(F).
+ */
+func (media *MediaDescription) SetSrtpParameters(params mediasoup.SrtpParameters) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	media.Crypto = []Crypto{{Tag: 1, Suite: params.CryptoSuite, KeyParams: "inline:" + params.KeyBase64}}
+
+	switch media.Protocol {
+	case ProtocolRtpAvp:
+		media.Protocol = ProtocolRtpSavp
+	case ProtocolRtpAvpf:
+		media.Protocol = ProtocolRtpSavpf
+	}
+
+	return nil
+}
+
+// h264FmtpKeys are the fmtp parameters parsed by h264.ParseRtpH264Fmtp().
+var h264FmtpKeys = map[string]bool{
+	"packetization-mode":      true,
+	"profile-level-id":        true,
+	"level-asymmetry-allowed": true,
+}
+
+/**
+ * ParseFmtp parses a fmtp config such as "minptime=10;useinbandfec=1" into
+ * codec parameters. Parameters unknown to mediasoup are ignored. H264
+ * parameters are matched regardless of their casing, as written by some MP4
+ * muxers, and their profile-level-id is normalized.
+ */
+func ParseFmtp(config string) (*mediasoup.RtpCodecParameter, error) {
+	params := &mediasoup.RtpCodecParameter{
+		RtpH264Parameter: h264.ParseRtpH264Fmtp(config),
+	}
+
+	for _, pair := range strings.Split(config, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || h264FmtpKeys[strings.ToLower(strings.TrimSpace(key))] {
+			continue
+		}
+
+		// Numeric values are tried as numbers first, as strings otherwise
+		// (e.g. a profile-level-id made of digits).
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			if err := json.Unmarshal([]byte(fmt.Sprintf("{%q:%s}", key, value)), params); err == nil {
+				continue
+			}
+		}
+
+		data, _ := json.Marshal(map[string]string{key: value})
+
+		if err := json.Unmarshal(data, params); err != nil {
+			return nil, fmt.Errorf("invalid fmtp parameter %q: %s", key, err)
+		}
+	}
+
+	return params, nil
+}
+
+// WriteFmtp writes codec parameters as a fmtp config, sorted by name.
+func WriteFmtp(params *mediasoup.RtpCodecParameter) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	var values map[string]json.RawMessage
+
+	if err = json.Unmarshal(data, &values); err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		value := bytes.Trim(values[key], `"`)
+		pairs = append(pairs, key+"="+string(value))
+	}
+
+	return strings.Join(pairs, ";"), nil
+}
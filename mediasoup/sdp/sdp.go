@@ -0,0 +1,707 @@
+// Package sdp parses and writes SDP session descriptions and converts them
+// to and from the mediasoup RTP, ICE and DTLS parameters, so plain SIP/WebRTC
+// endpoints and tools like FFmpeg or GStreamer can be bridged without a JS
+// SDP library.
+//
+// The model follows the one of sdp-transform: well known attributes are
+// parsed into typed fields and the others are kept in Attributes, in order.
+package sdp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProtocolWebRtc      = "UDP/TLS/RTP/SAVPF"
+	ProtocolRtpAvp      = "RTP/AVP"
+	ProtocolRtpAvpf     = "RTP/AVPF"
+	ProtocolRtpSavp     = "RTP/SAVP"
+	ProtocolRtpSavpf    = "RTP/SAVPF"
+	ProtocolDataChannel = "UDP/DTLS/SCTP"
+
+	DirectionSendRecv = "sendrecv"
+	DirectionSendOnly = "sendonly"
+	DirectionRecvOnly = "recvonly"
+	DirectionInactive = "inactive"
+)
+
+type SessionDescription struct {
+	Version      int
+	Origin       Origin
+	Name         string
+	Timing       string
+	Connection   *Connection
+	Bandwidths   []Bandwidth
+	Groups       []Group
+	MsidSemantic string
+	IceUfrag     string
+	IcePwd       string
+	IceLite      bool
+	IceOptions   string
+	Fingerprint  *Fingerprint
+	Setup        string
+	Attributes   []Attribute
+	Media        []*MediaDescription
+
+	// ExtmapAllowMixed is "a=extmap-allow-mixed", for all the media.
+	ExtmapAllowMixed bool
+}
+
+type Origin struct {
+	Username       string
+	SessionId      string
+	SessionVersion uint64
+	NetType        string
+	IpVer          int
+	Address        string
+}
+
+type Connection struct {
+	IpVer   int
+	Address string
+}
+
+type Bandwidth struct {
+	Type  string
+	Limit int
+}
+
+type Group struct {
+	Type string
+	Mids []string
+}
+
+type Fingerprint struct {
+	Type string
+	Hash string
+}
+
+// Attribute is an "a=" line not parsed into a typed field.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+type MediaDescription struct {
+	Type     string
+	Port     int
+	NumPorts int
+	Protocol string
+	// Formats are the payload types, or e.g. "webrtc-datachannel".
+	Formats []string
+
+	Connection      *Connection
+	Bandwidths      []Bandwidth
+	Mid             string
+	Direction       string
+	Msid            string
+	IceUfrag        string
+	IcePwd          string
+	IceOptions      string
+	Fingerprint     *Fingerprint
+	Setup           string
+	Rtcp            *Rtcp
+	RtcpMux         bool
+	RtcpRsize       bool
+	Rtp             []RtpMap
+	Fmtp            []Fmtp
+	RtcpFb          []RtcpFb
+	Ext             []Ext
+	Ssrcs           []Ssrc
+	SsrcGroups      []SsrcGroup
+	Rids            []Rid
+	Simulcast       *Simulcast
+	Candidates      []Candidate
+	EndOfCandidates bool
+	SctpPort        int
+	MaxMessageSize  int
+	Attributes      []Attribute
+
+	// ExtmapAllowMixed is "a=extmap-allow-mixed", see
+	// mediasoup.RtpCapabilities.ExtmapAllowMixed.
+	ExtmapAllowMixed bool
+	// Crypto are the SDES "a=crypto" lines, see SrtpParameters().
+	Crypto []Crypto
+}
+
+type Rtcp struct {
+	Port    int
+	IpVer   int
+	Address string
+}
+
+// Crypto is "a=crypto:<tag> <suite> <key-params> [<session-params>]" (SDES,
+// RFC 4568), e.g. "a=crypto:1 AES_CM_128_HMAC_SHA1_80 inline:<key>".
+type Crypto struct {
+	Tag           int
+	Suite         string
+	KeyParams     string
+	SessionParams string
+}
+
+// RtpMap is "a=rtpmap:<payload> <codec>/<rate>[/<encoding>]".
+type RtpMap struct {
+	Payload  int
+	Codec    string
+	Rate     int
+	Encoding int
+}
+
+// Fmtp is "a=fmtp:<payload> <config>".
+type Fmtp struct {
+	Payload int
+	Config  string
+}
+
+// RtcpFb is "a=rtcp-fb:<payload> <type> [<subtype>]", payload being "*" for
+// all the payloads.
+type RtcpFb struct {
+	Payload string
+	Type    string
+	Subtype string
+}
+
+// Ext is "a=extmap:<value>[/<direction>] <uri> [<config>]".
+type Ext struct {
+	Value     int
+	Direction string
+	Uri       string
+	Config    string
+}
+
+// Ssrc is "a=ssrc:<id> <attribute>[:<value>]".
+type Ssrc struct {
+	Id        uint32
+	Attribute string
+	Value     string
+}
+
+// SsrcGroup is "a=ssrc-group:<semantics> <ssrcs>...".
+type SsrcGroup struct {
+	Semantics string
+	Ssrcs     []uint32
+}
+
+// Rid is "a=rid:<id> <direction> [<params>]".
+type Rid struct {
+	Id        string
+	Direction string
+	Params    string
+}
+
+// Simulcast is "a=simulcast:[send <streams>] [recv <streams>]", streams
+// being as in the SDP, e.g. "h;m;~l".
+type Simulcast struct {
+	Send string
+	Recv string
+}
+
+type Candidate struct {
+	Foundation string
+	Component  int
+	Transport  string
+	Priority   uint32
+	Ip         string
+	Port       int
+	Type       string
+	Raddr      string
+	Rport      int
+	TcpType    string
+}
+
+// Payloads returns the formats of the media as payload types, ignoring the
+// non numeric ones.
+func (m *MediaDescription) Payloads() (payloads []int) {
+	for _, format := range m.Formats {
+		if payload, err := strconv.Atoi(format); err == nil {
+			payloads = append(payloads, payload)
+		}
+	}
+
+	return
+}
+
+// Parse parses an SDP session description. Lines of unknown types (e.g.
+// "i=", "u=", "k=") are ignored.
+func Parse(sdp string) (session *SessionDescription, err error) {
+	session = &SessionDescription{}
+
+	var media *MediaDescription
+
+	for i, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) < 2 || line[1] != '=' {
+			return nil, fmt.Errorf("invalid SDP line %d: %q", i+1, line)
+		}
+
+		typ, value := line[0], line[2:]
+
+		switch {
+		case typ == 'm':
+			if media, err = parseMedia(value); err == nil {
+				session.Media = append(session.Media, media)
+			}
+		case typ == 'a' && media != nil:
+			err = media.parseAttribute(value)
+		case typ == 'a':
+			err = session.parseAttribute(value)
+		case typ == 'c':
+			var connection *Connection
+			if connection, err = parseConnection(value); err == nil {
+				if media != nil {
+					media.Connection = connection
+				} else {
+					session.Connection = connection
+				}
+			}
+		case typ == 'b':
+			var bandwidth Bandwidth
+			if bandwidth, err = parseBandwidth(value); err == nil {
+				if media != nil {
+					media.Bandwidths = append(media.Bandwidths, bandwidth)
+				} else {
+					session.Bandwidths = append(session.Bandwidths, bandwidth)
+				}
+			}
+		case typ == 'v':
+			session.Version, err = strconv.Atoi(value)
+		case typ == 'o':
+			session.Origin, err = parseOrigin(value)
+		case typ == 's':
+			session.Name = value
+		case typ == 't':
+			session.Timing = value
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid SDP line %d: %q: %s", i+1, line, err)
+		}
+	}
+
+	return
+}
+
+func parseOrigin(value string) (origin Origin, err error) {
+	fields := strings.Fields(value)
+	if len(fields) != 6 {
+		return origin, errors.New("invalid origin")
+	}
+
+	origin.Username = fields[0]
+	origin.SessionId = fields[1]
+	origin.NetType = fields[3]
+	origin.Address = fields[5]
+
+	if origin.SessionVersion, err = strconv.ParseUint(fields[2], 10, 64); err != nil {
+		return
+	}
+	origin.IpVer, err = parseIpVer(fields[4])
+
+	return
+}
+
+func parseConnection(value string) (connection *Connection, err error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return nil, errors.New("invalid connection")
+	}
+
+	connection = &Connection{Address: fields[2]}
+	connection.IpVer, err = parseIpVer(fields[1])
+
+	return
+}
+
+func parseIpVer(addrType string) (int, error) {
+	switch addrType {
+	case "IP4":
+		return 4, nil
+	case "IP6":
+		return 6, nil
+	default:
+		return 0, fmt.Errorf("invalid address type %q", addrType)
+	}
+}
+
+func parseBandwidth(value string) (bandwidth Bandwidth, err error) {
+	typ, limit, ok := strings.Cut(value, ":")
+	if !ok {
+		return bandwidth, errors.New("invalid bandwidth")
+	}
+
+	bandwidth.Type = typ
+	bandwidth.Limit, err = strconv.Atoi(limit)
+
+	return
+}
+
+func parseMedia(value string) (media *MediaDescription, err error) {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return nil, errors.New("invalid media")
+	}
+
+	media = &MediaDescription{
+		Type:     fields[0],
+		Protocol: fields[2],
+		Formats:  fields[3:],
+	}
+
+	port, numPorts, hasNumPorts := strings.Cut(fields[1], "/")
+
+	if media.Port, err = strconv.Atoi(port); err != nil {
+		return
+	}
+	if hasNumPorts {
+		media.NumPorts, err = strconv.Atoi(numPorts)
+	}
+
+	return
+}
+
+func (session *SessionDescription) parseAttribute(attribute string) (err error) {
+	key, value, _ := strings.Cut(attribute, ":")
+
+	switch key {
+	case "group":
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return errors.New("invalid group")
+		}
+		session.Groups = append(session.Groups, Group{Type: fields[0], Mids: fields[1:]})
+	case "msid-semantic":
+		session.MsidSemantic = strings.TrimSpace(value)
+	case "ice-ufrag":
+		session.IceUfrag = value
+	case "ice-pwd":
+		session.IcePwd = value
+	case "ice-lite":
+		session.IceLite = true
+	case "extmap-allow-mixed":
+		session.ExtmapAllowMixed = true
+	case "ice-options":
+		session.IceOptions = value
+	case "fingerprint":
+		session.Fingerprint, err = parseFingerprint(value)
+	case "setup":
+		session.Setup = value
+	default:
+		session.Attributes = append(session.Attributes, Attribute{Key: key, Value: value})
+	}
+
+	return
+}
+
+func (media *MediaDescription) parseAttribute(attribute string) (err error) {
+	key, value, _ := strings.Cut(attribute, ":")
+
+	switch key {
+	case "mid":
+		media.Mid = value
+	case DirectionSendRecv, DirectionSendOnly, DirectionRecvOnly, DirectionInactive:
+		media.Direction = key
+	case "msid":
+		media.Msid = value
+	case "ice-ufrag":
+		media.IceUfrag = value
+	case "ice-pwd":
+		media.IcePwd = value
+	case "ice-options":
+		media.IceOptions = value
+	case "fingerprint":
+		media.Fingerprint, err = parseFingerprint(value)
+	case "setup":
+		media.Setup = value
+	case "rtcp":
+		media.Rtcp, err = parseRtcp(value)
+	case "rtcp-mux":
+		media.RtcpMux = true
+	case "rtcp-rsize":
+		media.RtcpRsize = true
+	case "rtpmap":
+		var rtp RtpMap
+		if rtp, err = parseRtpMap(value); err == nil {
+			media.Rtp = append(media.Rtp, rtp)
+		}
+	case "fmtp":
+		payload, config, _ := strings.Cut(value, " ")
+		fmtp := Fmtp{Config: strings.TrimSpace(config)}
+		if fmtp.Payload, err = strconv.Atoi(payload); err == nil {
+			media.Fmtp = append(media.Fmtp, fmtp)
+		}
+	case "rtcp-fb":
+		fields := strings.Fields(value)
+		if len(fields) < 2 {
+			return errors.New("invalid rtcp-fb")
+		}
+		fb := RtcpFb{Payload: fields[0], Type: fields[1]}
+		if len(fields) > 2 {
+			fb.Subtype = strings.Join(fields[2:], " ")
+		}
+		media.RtcpFb = append(media.RtcpFb, fb)
+	case "extmap-allow-mixed":
+		media.ExtmapAllowMixed = true
+	case "crypto":
+		var crypto Crypto
+		if crypto, err = parseCrypto(value); err == nil {
+			media.Crypto = append(media.Crypto, crypto)
+		}
+	case "extmap":
+		var ext Ext
+		if ext, err = parseExt(value); err == nil {
+			media.Ext = append(media.Ext, ext)
+		}
+	case "ssrc":
+		var ssrc Ssrc
+		if ssrc, err = parseSsrc(value); err == nil {
+			media.Ssrcs = append(media.Ssrcs, ssrc)
+		}
+	case "ssrc-group":
+		var group SsrcGroup
+		if group, err = parseSsrcGroup(value); err == nil {
+			media.SsrcGroups = append(media.SsrcGroups, group)
+		}
+	case "rid":
+		fields := strings.SplitN(value, " ", 3)
+		if len(fields) < 2 {
+			return errors.New("invalid rid")
+		}
+		rid := Rid{Id: fields[0], Direction: fields[1]}
+		if len(fields) > 2 {
+			rid.Params = fields[2]
+		}
+		media.Rids = append(media.Rids, rid)
+	case "simulcast":
+		media.Simulcast, err = parseSimulcast(value)
+	case "candidate":
+		var candidate Candidate
+		if candidate, err = parseCandidate(value); err == nil {
+			media.Candidates = append(media.Candidates, candidate)
+		}
+	case "end-of-candidates":
+		media.EndOfCandidates = true
+	case "sctp-port":
+		media.SctpPort, err = strconv.Atoi(value)
+	case "max-message-size":
+		media.MaxMessageSize, err = strconv.Atoi(value)
+	default:
+		media.Attributes = append(media.Attributes, Attribute{Key: key, Value: value})
+	}
+
+	return
+}
+
+func parseCrypto(value string) (crypto Crypto, err error) {
+	fields := strings.SplitN(value, " ", 4)
+	if len(fields) < 3 {
+		return crypto, errors.New("invalid crypto")
+	}
+	if crypto.Tag, err = strconv.Atoi(fields[0]); err != nil {
+		return
+	}
+	crypto.Suite = fields[1]
+	crypto.KeyParams = fields[2]
+	if len(fields) > 3 {
+		crypto.SessionParams = fields[3]
+	}
+
+	return
+}
+
+func parseFingerprint(value string) (*Fingerprint, error) {
+	typ, hash, ok := strings.Cut(value, " ")
+	if !ok {
+		return nil, errors.New("invalid fingerprint")
+	}
+
+	return &Fingerprint{Type: typ, Hash: hash}, nil
+}
+
+func parseRtcp(value string) (rtcp *Rtcp, err error) {
+	fields := strings.Fields(value)
+	if len(fields) != 1 && len(fields) != 4 {
+		return nil, errors.New("invalid rtcp")
+	}
+
+	rtcp = &Rtcp{}
+
+	if rtcp.Port, err = strconv.Atoi(fields[0]); err != nil || len(fields) == 1 {
+		return
+	}
+
+	rtcp.Address = fields[3]
+	rtcp.IpVer, err = parseIpVer(fields[2])
+
+	return
+}
+
+func parseRtpMap(value string) (rtp RtpMap, err error) {
+	payload, encoding, ok := strings.Cut(value, " ")
+	if !ok {
+		return rtp, errors.New("invalid rtpmap")
+	}
+	if rtp.Payload, err = strconv.Atoi(payload); err != nil {
+		return
+	}
+
+	fields := strings.Split(encoding, "/")
+	if len(fields) < 2 {
+		return rtp, errors.New("invalid rtpmap")
+	}
+
+	rtp.Codec = fields[0]
+
+	if rtp.Rate, err = strconv.Atoi(fields[1]); err != nil {
+		return
+	}
+	if len(fields) > 2 {
+		rtp.Encoding, err = strconv.Atoi(fields[2])
+	}
+
+	return
+}
+
+func parseExt(value string) (ext Ext, err error) {
+	fields := strings.SplitN(value, " ", 3)
+	if len(fields) < 2 {
+		return ext, errors.New("invalid extmap")
+	}
+
+	id, direction, _ := strings.Cut(fields[0], "/")
+
+	if ext.Value, err = strconv.Atoi(id); err != nil {
+		return
+	}
+
+	ext.Direction = direction
+	ext.Uri = fields[1]
+
+	if len(fields) > 2 {
+		ext.Config = fields[2]
+	}
+
+	return
+}
+
+func parseSsrc(value string) (ssrc Ssrc, err error) {
+	id, attribute, ok := strings.Cut(value, " ")
+	if !ok {
+		return ssrc, errors.New("invalid ssrc")
+	}
+
+	ssrcId, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return
+	}
+
+	ssrc.Id = uint32(ssrcId)
+	ssrc.Attribute, ssrc.Value, _ = strings.Cut(attribute, ":")
+
+	return
+}
+
+func parseSsrcGroup(value string) (group SsrcGroup, err error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return group, errors.New("invalid ssrc-group")
+	}
+
+	group.Semantics = fields[0]
+
+	for _, field := range fields[1:] {
+		ssrc, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return group, err
+		}
+		group.Ssrcs = append(group.Ssrcs, uint32(ssrc))
+	}
+
+	return
+}
+
+func parseSimulcast(value string) (*Simulcast, error) {
+	simulcast := &Simulcast{}
+	fields := strings.Fields(value)
+
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return nil, errors.New("invalid simulcast")
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		switch fields[i] {
+		case "send":
+			simulcast.Send = fields[i+1]
+		case "recv":
+			simulcast.Recv = fields[i+1]
+		default:
+			return nil, fmt.Errorf("invalid simulcast direction %q", fields[i])
+		}
+	}
+
+	return simulcast, nil
+}
+
+func parseCandidate(value string) (candidate Candidate, err error) {
+	fields := strings.Fields(value)
+	if len(fields) < 8 || fields[6] != "typ" {
+		return candidate, errors.New("invalid candidate")
+	}
+
+	candidate.Foundation = fields[0]
+	candidate.Transport = fields[2]
+	candidate.Ip = fields[4]
+	candidate.Type = fields[7]
+
+	if candidate.Component, err = strconv.Atoi(fields[1]); err != nil {
+		return
+	}
+
+	priority, err := strconv.ParseUint(fields[3], 10, 32)
+	if err != nil {
+		return
+	}
+
+	candidate.Priority = uint32(priority)
+
+	if candidate.Port, err = strconv.Atoi(fields[5]); err != nil {
+		return
+	}
+
+	// Extensions are key/value pairs.
+	for i := 8; i+1 < len(fields); i += 2 {
+		switch fields[i] {
+		case "raddr":
+			candidate.Raddr = fields[i+1]
+		case "rport":
+			if candidate.Rport, err = strconv.Atoi(fields[i+1]); err != nil {
+				return
+			}
+		case "tcptype":
+			candidate.TcpType = fields[i+1]
+		}
+	}
+
+	return
+}
+
+// SimulcastStreams returns the first alternative of every stream of a
+// simulcast "send" or "recv" value, without the paused "~" prefix.
+func SimulcastStreams(streams string) (rids []string) {
+	if len(streams) == 0 {
+		return
+	}
+
+	for _, stream := range strings.Split(streams, ";") {
+		alternative, _, _ := strings.Cut(stream, ",")
+		rids = append(rids, strings.TrimPrefix(alternative, "~"))
+	}
+
+	return
+}
@@ -0,0 +1,387 @@
+package sdp
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup"
+	h264 "github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+const chromeOffer = "v=0\r\n" +
+	"o=- 4611731400430051336 2 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"t=0 0\r\n" +
+	"a=group:BUNDLE 0 1\r\n" +
+	"a=msid-semantic: WMS stream\r\n" +
+	"m=audio 9 UDP/TLS/RTP/SAVPF 111 0\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=rtcp:9 IN IP4 0.0.0.0\r\n" +
+	"a=candidate:1 1 udp 2113937151 192.168.1.2 54400 typ host\r\n" +
+	"a=candidate:2 1 tcp 1518280447 192.168.1.2 9 typ host tcptype active\r\n" +
+	"a=ice-ufrag:F7gI\r\n" +
+	"a=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n" +
+	"a=ice-options:trickle\r\n" +
+	"a=fingerprint:sha-256 D1:2C:BE:AD:C4:F6:64:5C:25:16:11:9C:AF:E7:0F:73:79:36:4E:9C:1E:15:54:39:0C:06:8B:ED:96:86:00:39\r\n" +
+	"a=setup:actpass\r\n" +
+	"a=mid:0\r\n" +
+	"a=extmap:1 urn:ietf:params:rtp-hdrext:ssrc-audio-level\r\n" +
+	"a=sendonly\r\n" +
+	"a=msid:stream audio\r\n" +
+	"a=rtcp-mux\r\n" +
+	"a=rtpmap:111 opus/48000/2\r\n" +
+	"a=rtcp-fb:111 transport-cc\r\n" +
+	"a=fmtp:111 minptime=10;useinbandfec=1\r\n" +
+	"a=ssrc:1001 cname:abc\r\n" +
+	"a=x-custom:1\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96 97 102\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=ice-ufrag:F7gI\r\n" +
+	"a=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n" +
+	"a=fingerprint:sha-256 D1:2C:BE:AD:C4:F6:64:5C:25:16:11:9C:AF:E7:0F:73:79:36:4E:9C:1E:15:54:39:0C:06:8B:ED:96:86:00:39\r\n" +
+	"a=setup:actpass\r\n" +
+	"a=mid:1\r\n" +
+	"a=extmap:3 http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time\r\n" +
+	"a=extmap:4/sendonly urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id\r\n" +
+	"a=sendonly\r\n" +
+	"a=rtcp-mux\r\n" +
+	"a=rtcp-rsize\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"a=rtcp-fb:96 nack\r\n" +
+	"a=rtcp-fb:96 nack pli\r\n" +
+	"a=rtpmap:97 rtx/90000\r\n" +
+	"a=fmtp:97 apt=96\r\n" +
+	"a=rtpmap:102 H264/90000\r\n" +
+	"a=fmtp:102 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f\r\n" +
+	"a=rtcp-fb:* ccm fir\r\n" +
+	"a=rid:h send\r\n" +
+	"a=rid:m send\r\n" +
+	"a=rid:l send\r\n" +
+	"a=simulcast:send h;m;~l\r\n" +
+	"a=ssrc-group:FID 2001 2002\r\n" +
+	"a=ssrc:2001 cname:abc\r\n" +
+	"a=ssrc:2002 cname:abc\r\n"
+
+func TestParse(t *testing.T) {
+	session, err := Parse(chromeOffer)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "4611731400430051336", session.Origin.SessionId)
+	assert.Equal(t, []Group{{Type: "BUNDLE", Mids: []string{"0", "1"}}}, session.Groups)
+	assert.Len(t, session.Media, 2)
+
+	audio, video := session.Media[0], session.Media[1]
+
+	assert.Equal(t, []int{111, 0}, audio.Payloads())
+	assert.Equal(t, &Rtcp{Port: 9, IpVer: 4, Address: "0.0.0.0"}, audio.Rtcp)
+	assert.Equal(t, DirectionSendOnly, audio.Direction)
+	assert.Equal(t, []Attribute{{Key: "x-custom", Value: "1"}}, audio.Attributes)
+	assert.Equal(t, Candidate{
+		Foundation: "2", Component: 1, Transport: "tcp", Priority: 1518280447,
+		Ip: "192.168.1.2", Port: 9, Type: "host", TcpType: "active",
+	}, audio.Candidates[1])
+
+	assert.Equal(t, Ext{Value: 4, Direction: "sendonly", Uri: "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id"}, video.Ext[1])
+	assert.Equal(t, &Simulcast{Send: "h;m;~l"}, video.Simulcast)
+	assert.Equal(t, []string{"h", "m", "l"}, SimulcastStreams(video.Simulcast.Send))
+	assert.Equal(t, []SsrcGroup{{Semantics: "FID", Ssrcs: []uint32{2001, 2002}}}, video.SsrcGroups)
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, sdp := range []string{
+		"v=0\r\nfoo\r\n",
+		"v=0\r\nm=video x RTP/AVP 96\r\n",
+		"v=0\r\nm=video 9 RTP/AVP 96\r\na=rtpmap:96 VP8\r\n",
+		"v=0\r\nm=video 9 RTP/AVP 96\r\na=ssrc-group:FID a b\r\n",
+		"v=0\r\nc=IN IPX 0.0.0.0\r\n",
+	} {
+		_, err := Parse(sdp)
+		assert.Error(t, err, sdp)
+	}
+}
+
+func TestSessionDescriptionString(t *testing.T) {
+	session, err := Parse(chromeOffer)
+	assert.NoError(t, err)
+
+	written := session.String()
+
+	reparsed, err := Parse(written)
+	assert.NoError(t, err)
+	assert.Equal(t, session, reparsed)
+	assert.Equal(t, written, reparsed.String())
+}
+
+func TestMediaDescriptionRtpParameters(t *testing.T) {
+	session, err := Parse(chromeOffer)
+	assert.NoError(t, err)
+
+	audioParams, err := session.Media[0].RtpParameters()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0", audioParams.Mid)
+	assert.Equal(t, []mediasoup.RtpCodecCapability{
+		{
+			MimeType:     "audio/opus",
+			ClockRate:    48000,
+			Channels:     2,
+			PayloadType:  111,
+			Parameters:   &mediasoup.RtpCodecParameter{Useinbandfec: 1},
+			RtcpFeedback: []mediasoup.RtcpFeedback{{Type: "transport-cc"}},
+		},
+		{
+			MimeType:    "audio/PCMU",
+			ClockRate:   8000,
+			Channels:    1,
+			PayloadType: 0,
+		},
+	}, audioParams.Codecs)
+	assert.Equal(t, []mediasoup.RtpEncoding{{Ssrc: 1001}}, audioParams.Encodings)
+	assert.Equal(t, mediasoup.RtcpParameters{Cname: "abc"}, audioParams.Rtcp)
+
+	videoParams, err := session.Media[1].RtpParameters()
+	assert.NoError(t, err)
+
+	assert.Len(t, videoParams.Codecs, 3)
+	assert.Equal(t, 96, videoParams.Codecs[1].Parameters.Apt)
+	assert.Equal(t, "42e01f", videoParams.Codecs[2].Parameters.ProfileLevelId)
+	assert.Equal(t, []mediasoup.RtcpFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}, {Type: "ccm", Parameter: "fir"}},
+		videoParams.Codecs[0].RtcpFeedback)
+	assert.Equal(t, []mediasoup.RtpEncoding{{Rid: "h"}, {Rid: "m"}, {Rid: "l"}}, videoParams.Encodings)
+	assert.True(t, videoParams.Rtcp.ReducedSize)
+
+	// Without simulcast, the encoding comes from the SSRCs.
+	session.Media[1].Simulcast = nil
+
+	videoParams, err = session.Media[1].RtpParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, []mediasoup.RtpEncoding{{Ssrc: 2001, Rtx: &mediasoup.RtpEncoding{Ssrc: 2002}}}, videoParams.Encodings)
+}
+
+func TestSessionDescriptionRtpCapabilities(t *testing.T) {
+	// FFmpeg like SDP.
+	session, err := Parse("v=0\no=- 0 0 IN IP4 127.0.0.1\ns=No Name\nc=IN IP4 127.0.0.1\nt=0 0\n" +
+		"m=audio 5004 RTP/AVP 8\nb=AS:64\n" +
+		"m=video 5006 RTP/AVP 96\na=rtpmap:96 H264/90000\na=fmtp:96 packetization-mode=1\n")
+	assert.NoError(t, err)
+
+	caps, err := session.RtpCapabilities()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Bandwidth{{Type: "AS", Limit: 64}}, session.Media[0].Bandwidths)
+	assert.Equal(t, []mediasoup.RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/PCMA", ClockRate: 8000, Channels: 1, PreferredPayloadType: 8},
+		{
+			Kind: "video", MimeType: "video/H264", ClockRate: 90000, PreferredPayloadType: 96,
+			Parameters: &mediasoup.RtpCodecParameter{RtpH264Parameter: h264.RtpH264Parameter{PacketizationMode: 1}},
+		},
+	}, caps.Codecs)
+
+	params, err := session.Media[0].RtpParameters()
+	assert.NoError(t, err)
+	assert.Empty(t, params.Encodings)
+}
+
+func TestSessionDescriptionTransportParameters(t *testing.T) {
+	session, err := Parse(chromeOffer)
+	assert.NoError(t, err)
+
+	media := session.Media[0]
+
+	assert.Equal(t, mediasoup.IceParameters{UsernameFragment: "F7gI", Password: "x9cml/YzichV2+XlhiMu8g"},
+		session.IceParameters(media))
+
+	dtls, err := session.DtlsParameters(media)
+	assert.NoError(t, err)
+	assert.Equal(t, "auto", dtls.Role)
+	assert.Equal(t, "sha-256", dtls.Fingerprints[0].Algorithm)
+
+	assert.Equal(t, mediasoup.IceCandidate{
+		Foundation: "1", Priority: 2113937151, Ip: "192.168.1.2", Port: 54400, Type: "host", Protocol: "udp",
+	}, media.IceCandidates()[0])
+
+	media.Fingerprint = nil
+
+	_, err = session.DtlsParameters(media)
+	assert.Error(t, err)
+}
+
+func TestNewMediaDescription(t *testing.T) {
+	params := mediasoup.RtpParameters{
+		Mid: "1",
+		Codecs: []mediasoup.RtpCodecCapability{
+			{
+				MimeType: "video/VP8", ClockRate: 90000, PayloadType: 101,
+				RtcpFeedback: []mediasoup.RtcpFeedback{{Type: "nack", Parameter: "pli"}},
+			},
+			{MimeType: "video/rtx", ClockRate: 90000, PayloadType: 102, Parameters: &mediasoup.RtpCodecParameter{Apt: 101}},
+		},
+		HeaderExtensions: []mediasoup.RtpHeaderExtension{{Id: 4, Uri: "urn:3gpp:video-orientation"}},
+		Encodings: []mediasoup.RtpEncoding{
+			{Ssrc: 1, Rtx: &mediasoup.RtpEncoding{Ssrc: 2}},
+			{Ssrc: 3, Rtx: &mediasoup.RtpEncoding{Ssrc: 4}},
+		},
+		Rtcp: mediasoup.RtcpParameters{Cname: "cname", ReducedSize: true},
+	}
+
+	media, err := NewMediaDescription(mediasoup.MediaKindVideo, params)
+	assert.NoError(t, err)
+
+	media.Direction = DirectionSendOnly
+	media.SetIceParameters(mediasoup.IceParameters{UsernameFragment: "u", Password: "p"})
+	media.SetIceCandidates([]mediasoup.IceCandidate{{Foundation: "f", Priority: 1, Ip: "1.2.3.4", Port: 40000, Type: "host", Protocol: "udp"}})
+	assert.NoError(t, media.SetDtlsParameters(mediasoup.DtlsParameters{
+		Role: "server",
+		Fingerprints: []mediasoup.DtlsFingerprint{
+			{Algorithm: "sha-256", Value: "AA"},
+			{Algorithm: "sha-512", Value: "BB"},
+		},
+	}))
+
+	assert.Equal(t, []string{"101", "102"}, media.Formats)
+	assert.Equal(t, []Fmtp{{Payload: 102, Config: "apt=101"}}, media.Fmtp)
+	assert.Equal(t, []SsrcGroup{
+		{Semantics: "SIM", Ssrcs: []uint32{1, 3}},
+		{Semantics: "FID", Ssrcs: []uint32{1, 2}},
+		{Semantics: "FID", Ssrcs: []uint32{3, 4}},
+	}, media.SsrcGroups)
+	assert.Equal(t, &Fingerprint{Type: "sha-256", Hash: "AA"}, media.Fingerprint)
+	assert.Equal(t, "passive", media.Setup)
+
+	session := NewSessionDescription()
+	session.Media = append(session.Media, media)
+
+	reparsed, err := Parse(session.String())
+	assert.NoError(t, err)
+
+	roundTrip, err := reparsed.Media[0].RtpParameters()
+	assert.NoError(t, err)
+
+	// SSRC based encodings are restored, and the parsed fmtp/feedback match.
+	assert.Equal(t, params, roundTrip)
+
+	dtls, err := reparsed.DtlsParameters(reparsed.Media[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "server", dtls.Role)
+	assert.Equal(t, "1.2.3.4", reparsed.Media[0].IceCandidates()[0].Ip)
+}
+
+func TestFmtp(t *testing.T) {
+	params, err := ParseFmtp("profile-level-id=640032; packetization-mode=1;unknown=x;x-google-start-bitrate=1000")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "640032", params.ProfileLevelId)
+	assert.Equal(t, 1, params.PacketizationMode)
+	assert.EqualValues(t, 1000, params.XGoogleStartBitrate)
+
+	config, err := WriteFmtp(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "packetization-mode=1;profile-level-id=640032;x-google-start-bitrate=1000", config)
+
+	_, err = ParseFmtp("apt=x")
+	assert.Error(t, err)
+}
+
+func TestFmtp_H264KeyCasing(t *testing.T) {
+	offer := "v=0\r\n" +
+		"o=- 1 2 IN IP4 127.0.0.1\r\n" +
+		"s=-\r\n" +
+		"t=0 0\r\n" +
+		"m=video 9 RTP/AVP 102\r\n" +
+		"a=mid:0\r\n" +
+		"a=rtpmap:102 H264/90000\r\n" +
+		"a=fmtp:102 Profile-Level-Id=42E01F;PACKETIZATION-MODE=1\r\n"
+
+	session, err := Parse(offer)
+	assert.NoError(t, err)
+
+	caps, err := session.RtpCapabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, h264.RtpH264Parameter{PacketizationMode: 1, ProfileLevelId: "42e01f"},
+		caps.Codecs[0].Parameters.RtpH264Parameter)
+
+	routerCaps, err := mediasoup.GenerateRouterRtpCapabilities([]mediasoup.RtpCodecCapability{
+		{
+			Kind: "video", MimeType: "video/H264", ClockRate: 90000,
+			Parameters: &mediasoup.RtpCodecParameter{
+				RtpH264Parameter: h264.RtpH264Parameter{PacketizationMode: 1, ProfileLevelId: "42e01f"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	extendedCaps := mediasoup.GetExtendedRtpCapabilities(caps, routerCaps)
+
+	assert.Len(t, extendedCaps.Codecs, 1)
+}
+
+func TestExtmapAllowMixed(t *testing.T) {
+	offer := "v=0\r\n" +
+		"o=- 1 2 IN IP4 127.0.0.1\r\n" +
+		"s=-\r\n" +
+		"t=0 0\r\n" +
+		"a=extmap-allow-mixed\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:0\r\n" +
+		"a=extmap-allow-mixed\r\n" +
+		"a=extmap:16 urn:example:two-byte\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n"
+
+	session, err := Parse(offer)
+	assert.NoError(t, err)
+	assert.True(t, session.ExtmapAllowMixed)
+	assert.True(t, session.Media[0].ExtmapAllowMixed)
+	assert.Empty(t, session.Media[0].Attributes)
+	assert.Equal(t, offer, session.String())
+
+	caps, err := session.RtpCapabilities()
+	assert.NoError(t, err)
+	assert.True(t, caps.ExtmapAllowMixed)
+
+	params, err := session.Media[0].RtpParameters()
+	assert.NoError(t, err)
+	assert.True(t, params.ExtmapAllowMixed)
+	assert.Equal(t, 16, params.HeaderExtensions[0].Id)
+
+	media, err := NewMediaDescription(mediasoup.MediaKindVideo, params)
+	assert.NoError(t, err)
+	assert.True(t, media.ExtmapAllowMixed)
+}
+
+func TestCrypto(t *testing.T) {
+	key := "WVNfX19zZW1jdGwgKCkgewkyMjA7fQp9CnVubGVz"
+	offer := "v=0\r\n" +
+		"o=- 1 2 IN IP4 127.0.0.1\r\n" +
+		"s=-\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 5004 RTP/SAVP 0\r\n" +
+		"a=crypto:1 AES_CM_128_HMAC_SHA1_80 inline:" + key + "|2^20|1:4\r\n" +
+		"a=crypto:2 AES_CM_128_HMAC_SHA1_32 inline:" + key + " KDR=1\r\n"
+
+	session, err := Parse(offer)
+	assert.NoError(t, err)
+	assert.Equal(t, []Crypto{
+		{Tag: 1, Suite: "AES_CM_128_HMAC_SHA1_80", KeyParams: "inline:" + key + "|2^20|1:4"},
+		{Tag: 2, Suite: "AES_CM_128_HMAC_SHA1_32", KeyParams: "inline:" + key, SessionParams: "KDR=1"},
+	}, session.Media[0].Crypto)
+	assert.Empty(t, session.Media[0].Attributes)
+	assert.Equal(t, offer, session.String())
+
+	// The first line has a MKI, which is not supported.
+	params, err := session.Media[0].SrtpParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, mediasoup.SrtpParameters{
+		CryptoSuite: mediasoup.SrtpCryptoSuiteAesCm128HmacSha1_32,
+		KeyBase64:   key,
+	}, params)
+
+	media := &MediaDescription{Type: "audio", Protocol: ProtocolRtpAvp}
+	assert.NoError(t, media.SetSrtpParameters(params))
+	assert.Equal(t, ProtocolRtpSavp, media.Protocol)
+	assert.Equal(t, []Crypto{{Tag: 1, Suite: params.CryptoSuite, KeyParams: "inline:" + key}}, media.Crypto)
+
+	assert.Error(t, media.SetSrtpParameters(mediasoup.SrtpParameters{CryptoSuite: "foo"}))
+
+	_, err = (&MediaDescription{}).SrtpParameters()
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// String writes the session description, with CRLF line endings.
+func (session *SessionDescription) String() string {
+	w := &writer{}
+
+	w.line("v=%d", session.Version)
+	w.line("o=%s %s %d IN %s %s", orDash(session.Origin.Username), orDefault(session.Origin.SessionId, "0"),
+		session.Origin.SessionVersion, ipVer(session.Origin.IpVer), orDefault(session.Origin.Address, "0.0.0.0"))
+	w.line("s=%s", orDash(session.Name))
+	w.connection(session.Connection)
+	w.bandwidths(session.Bandwidths)
+	w.line("t=%s", orDefault(session.Timing, "0 0"))
+
+	if session.IceLite {
+		w.line("a=ice-lite")
+	}
+	if session.ExtmapAllowMixed {
+		w.line("a=extmap-allow-mixed")
+	}
+	w.attribute("ice-ufrag", session.IceUfrag)
+	w.attribute("ice-pwd", session.IcePwd)
+	w.attribute("ice-options", session.IceOptions)
+	w.fingerprint(session.Fingerprint)
+	w.attribute("setup", session.Setup)
+
+	for _, group := range session.Groups {
+		w.line("a=group:%s", strings.Join(append([]string{group.Type}, group.Mids...), " "))
+	}
+
+	w.attribute("msid-semantic", session.MsidSemantic)
+	w.attributes(session.Attributes)
+
+	for _, media := range session.Media {
+		media.write(w)
+	}
+
+	return w.String()
+}
+
+func (media *MediaDescription) write(w *writer) {
+	port := strconv.Itoa(media.Port)
+	if media.NumPorts > 0 {
+		port += "/" + strconv.Itoa(media.NumPorts)
+	}
+
+	w.line("m=%s", strings.Join(append([]string{media.Type, port, media.Protocol}, media.Formats...), " "))
+	w.connection(media.Connection)
+	w.bandwidths(media.Bandwidths)
+
+	if rtcp := media.Rtcp; rtcp != nil {
+		if len(rtcp.Address) > 0 {
+			w.line("a=rtcp:%d IN %s %s", rtcp.Port, ipVer(rtcp.IpVer), rtcp.Address)
+		} else {
+			w.line("a=rtcp:%d", rtcp.Port)
+		}
+	}
+
+	w.attribute("ice-ufrag", media.IceUfrag)
+	w.attribute("ice-pwd", media.IcePwd)
+	w.attribute("ice-options", media.IceOptions)
+	w.fingerprint(media.Fingerprint)
+	w.attribute("setup", media.Setup)
+	w.attribute("mid", media.Mid)
+
+	for _, crypto := range media.Crypto {
+		w.line("a=crypto:%s", joinNonEmpty(
+			strconv.Itoa(crypto.Tag), crypto.Suite, crypto.KeyParams, crypto.SessionParams))
+	}
+
+	if media.ExtmapAllowMixed {
+		w.line("a=extmap-allow-mixed")
+	}
+
+	for _, ext := range media.Ext {
+		value := strconv.Itoa(ext.Value)
+		if len(ext.Direction) > 0 {
+			value += "/" + ext.Direction
+		}
+		w.line("a=extmap:%s", joinNonEmpty(value, ext.Uri, ext.Config))
+	}
+
+	if len(media.Direction) > 0 {
+		w.line("a=%s", media.Direction)
+	}
+
+	w.attribute("msid", media.Msid)
+
+	if media.RtcpMux {
+		w.line("a=rtcp-mux")
+	}
+	if media.RtcpRsize {
+		w.line("a=rtcp-rsize")
+	}
+
+	for _, rtp := range media.Rtp {
+		if rtp.Encoding > 0 {
+			w.line("a=rtpmap:%d %s/%d/%d", rtp.Payload, rtp.Codec, rtp.Rate, rtp.Encoding)
+		} else {
+			w.line("a=rtpmap:%d %s/%d", rtp.Payload, rtp.Codec, rtp.Rate)
+		}
+
+		payload := strconv.Itoa(rtp.Payload)
+
+		for _, fb := range media.RtcpFb {
+			if fb.Payload == payload {
+				w.line("a=rtcp-fb:%s", joinNonEmpty(fb.Payload, fb.Type, fb.Subtype))
+			}
+		}
+		for _, fmtp := range media.Fmtp {
+			if fmtp.Payload == rtp.Payload {
+				w.line("a=fmtp:%d %s", fmtp.Payload, fmtp.Config)
+			}
+		}
+	}
+
+	// Wildcard feedback and the one of payloads without rtpmap (e.g. static
+	// payload types).
+	for _, fb := range media.RtcpFb {
+		if payload, err := strconv.Atoi(fb.Payload); err != nil || !media.hasRtpMap(payload) {
+			w.line("a=rtcp-fb:%s", joinNonEmpty(fb.Payload, fb.Type, fb.Subtype))
+		}
+	}
+	for _, fmtp := range media.Fmtp {
+		if !media.hasRtpMap(fmtp.Payload) {
+			w.line("a=fmtp:%d %s", fmtp.Payload, fmtp.Config)
+		}
+	}
+
+	for _, rid := range media.Rids {
+		w.line("a=rid:%s", joinNonEmpty(rid.Id, rid.Direction, rid.Params))
+	}
+
+	if simulcast := media.Simulcast; simulcast != nil {
+		var value []string
+		if len(simulcast.Send) > 0 {
+			value = append(value, "send", simulcast.Send)
+		}
+		if len(simulcast.Recv) > 0 {
+			value = append(value, "recv", simulcast.Recv)
+		}
+		w.line("a=simulcast:%s", strings.Join(value, " "))
+	}
+
+	for _, group := range media.SsrcGroups {
+		ssrcs := make([]string, 0, len(group.Ssrcs))
+		for _, ssrc := range group.Ssrcs {
+			ssrcs = append(ssrcs, strconv.FormatUint(uint64(ssrc), 10))
+		}
+		w.line("a=ssrc-group:%s %s", group.Semantics, strings.Join(ssrcs, " "))
+	}
+
+	for _, ssrc := range media.Ssrcs {
+		if len(ssrc.Value) > 0 {
+			w.line("a=ssrc:%d %s:%s", ssrc.Id, ssrc.Attribute, ssrc.Value)
+		} else {
+			w.line("a=ssrc:%d %s", ssrc.Id, ssrc.Attribute)
+		}
+	}
+
+	for _, candidate := range media.Candidates {
+		w.line("a=candidate:%s", candidate.String())
+	}
+
+	if media.EndOfCandidates {
+		w.line("a=end-of-candidates")
+	}
+	if media.SctpPort > 0 {
+		w.line("a=sctp-port:%d", media.SctpPort)
+	}
+	if media.MaxMessageSize > 0 {
+		w.line("a=max-message-size:%d", media.MaxMessageSize)
+	}
+
+	w.attributes(media.Attributes)
+}
+
+func (media *MediaDescription) hasRtpMap(payload int) bool {
+	for _, rtp := range media.Rtp {
+		if rtp.Payload == payload {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the value of the candidate attribute, without "candidate:".
+func (candidate Candidate) String() string {
+	s := fmt.Sprintf("%s %d %s %d %s %d typ %s", candidate.Foundation, candidate.Component,
+		candidate.Transport, candidate.Priority, candidate.Ip, candidate.Port, candidate.Type)
+
+	if len(candidate.Raddr) > 0 {
+		s += fmt.Sprintf(" raddr %s rport %d", candidate.Raddr, candidate.Rport)
+	}
+	if len(candidate.TcpType) > 0 {
+		s += " tcptype " + candidate.TcpType
+	}
+
+	return s
+}
+
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) line(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+	w.WriteString("\r\n")
+}
+
+func (w *writer) attribute(key, value string) {
+	if len(value) > 0 {
+		w.line("a=%s:%s", key, value)
+	}
+}
+
+func (w *writer) attributes(attributes []Attribute) {
+	for _, attribute := range attributes {
+		if len(attribute.Value) > 0 {
+			w.line("a=%s:%s", attribute.Key, attribute.Value)
+		} else {
+			w.line("a=%s", attribute.Key)
+		}
+	}
+}
+
+func (w *writer) connection(connection *Connection) {
+	if connection != nil {
+		w.line("c=IN %s %s", ipVer(connection.IpVer), connection.Address)
+	}
+}
+
+func (w *writer) bandwidths(bandwidths []Bandwidth) {
+	for _, bandwidth := range bandwidths {
+		w.line("b=%s:%d", bandwidth.Type, bandwidth.Limit)
+	}
+}
+
+func (w *writer) fingerprint(fingerprint *Fingerprint) {
+	if fingerprint != nil {
+		w.line("a=fingerprint:%s %s", fingerprint.Type, fingerprint.Hash)
+	}
+}
+
+func ipVer(version int) string {
+	if version == 6 {
+		return "IP6"
+	}
+
+	return "IP4"
+}
+
+func orDash(s string) string {
+	return orDefault(s, "-")
+}
+
+func orDefault(s, def string) string {
+	if len(s) == 0 {
+		return def
+	}
+
+	return s
+}
+
+func joinNonEmpty(values ...string) string {
+	nonEmpty := values[:0:0]
+
+	for _, value := range values {
+		if len(value) > 0 {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+
+	return strings.Join(nonEmpty, " ")
+}
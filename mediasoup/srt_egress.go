@@ -0,0 +1,370 @@
+package mediasoup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default ffmpeg output arguments of SrtEgress. Browsers usually produce
+// VP8/Opus which cannot be carried in MPEG-TS, so media is transcoded.
+var DefaultSrtEgressOutputArgs = []string{
+	"-c:v", "libx264", "-preset", "veryfast", "-tune", "zerolatency",
+	"-c:a", "aac",
+}
+
+type SrtEgressParams struct {
+	// Url of the SRT destination, e.g. "srt://1.2.3.4:9000?mode=caller".
+	Url string
+	// ProducerIds to forward, at most one audio and one video Producer.
+	ProducerIds []string
+	// ListenIp of the PlainRtpTransports, default "127.0.0.1". ffmpeg must
+	// be reachable at this address.
+	ListenIp ListenIp
+	// FfmpegBin is the ffmpeg executable, default "ffmpeg".
+	FfmpegBin string
+	// OutputArgs are the ffmpeg arguments placed before the output, default
+	// DefaultSrtEgressOutputArgs.
+	OutputArgs []string
+}
+
+/**
+ * SrtEgress consumes Producers through PlainRtpTransports and runs an ffmpeg
+ * process which wraps their RTP into MPEG-TS over SRT.
+ *
+ * EXPERIMENTAL: the API may change.
+ *
+ * @emits {err error} processexit
+ * @emits close
+ */
+type SrtEgress struct {
+	EventEmitter
+	logger     logrus.FieldLogger
+	params     SrtEgressParams
+	transports []*PlainRtpTransport
+	consumers  []*Consumer
+	// Guards child and closed, Close() being called by users and on ffmpeg
+	// exit.
+	locker sync.Mutex
+	child  *exec.Cmd
+	closed bool
+}
+
+// CreateSrtEgress starts forwarding the given Producers to a SRT destination.
+func (router *Router) CreateSrtEgress(params SrtEgressParams) (egress *SrtEgress, err error) {
+	router.logger.Debug("createSrtEgress()")
+
+	if len(params.Url) == 0 {
+		err = NewTypeError("missing url")
+		return
+	}
+	if len(params.ProducerIds) == 0 || len(params.ProducerIds) > 2 {
+		err = NewTypeError("invalid number of producers [count:%d]", len(params.ProducerIds))
+		return
+	}
+	if len(params.ListenIp.Ip) == 0 {
+		params.ListenIp.Ip = "127.0.0.1"
+	}
+	if len(params.FfmpegBin) == 0 {
+		params.FfmpegBin = "ffmpeg"
+	}
+	if params.OutputArgs == nil {
+		params.OutputArgs = DefaultSrtEgressOutputArgs
+	}
+
+	logger := TypeLogger("SrtEgress")
+
+	egress = &SrtEgress{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		params:       params,
+	}
+
+	// Stop ffmpeg, if spawned, and close the transports created so far.
+	defer func() {
+		if err != nil {
+			egress.Close()
+			egress = nil
+		}
+	}()
+
+	kinds := map[MediaKind]bool{}
+	var rtpPorts []uint16
+
+	for _, producerId := range params.ProducerIds {
+		producer := router.GetProducerById(producerId)
+
+		if producer == nil {
+			err = NewTypeError(`Producer with id "%s" not found`, producerId)
+			return
+		}
+		if kinds[producer.Kind()] {
+			err = NewTypeError("just one %s Producer is allowed", producer.Kind())
+			return
+		}
+		kinds[producer.Kind()] = true
+
+		var transport *PlainRtpTransport
+
+		transport, err = router.CreatePlainTransport(CreatePlainTransportParams{
+			ListenIp: params.ListenIp,
+			RtcpMux:  false,
+		})
+		if err != nil {
+			return
+		}
+		egress.transports = append(egress.transports, transport)
+
+		// ffmpeg receives RTP in an even port and RTCP in the next one.
+		var rtpPort uint16
+
+		if rtpPort, err = getFreeUdpPortPair(params.ListenIp.Ip); err != nil {
+			return
+		}
+		rtpPorts = append(rtpPorts, rtpPort)
+
+		err = transport.Connect(transportConnectParams{
+			Ip:       params.ListenIp.Ip,
+			Port:     rtpPort,
+			RtcpPort: rtpPort + 1,
+		})
+		if err != nil {
+			return
+		}
+
+		var consumer *Consumer
+
+		// The SDP given to ffmpeg has no RTX payload type.
+		consumer, err = transport.Consume(transportConsumeParams{
+			ProducerId:      producerId,
+			RtpCapabilities: router.RtpCapabilities(),
+			Paused:          true,
+			DisableRtx:      true,
+		})
+		if err != nil {
+			return
+		}
+		egress.consumers = append(egress.consumers, consumer)
+	}
+
+	var streams []srtEgressStream
+
+	for i, consumer := range egress.consumers {
+		streams = append(streams, srtEgressStream{
+			Kind:          consumer.Kind(),
+			RtpParameters: consumer.RtpParameters(),
+			Port:          rtpPorts[i],
+		})
+	}
+
+	sdp := generateSrtEgressSdp(params.ListenIp.Ip, streams)
+
+	if err = egress.spawn(sdp); err != nil {
+		return
+	}
+
+	// Media may flow now that ffmpeg is listening.
+	for _, consumer := range egress.consumers {
+		if err = consumer.Resume(); err != nil {
+			return
+		}
+		if consumer.Kind() == MediaKindVideo {
+			if err := consumer.RequestKeyFrame(); err != nil {
+				egress.logger.Warnf("key frame request failed: %s", err)
+			}
+		}
+	}
+
+	return
+}
+
+// Consumers used to forward media.
+func (egress *SrtEgress) Consumers() []*Consumer {
+	return egress.consumers
+}
+
+// Whether the SrtEgress is closed.
+func (egress *SrtEgress) Closed() bool {
+	egress.locker.Lock()
+	defer egress.locker.Unlock()
+
+	return egress.closed
+}
+
+// Close stops ffmpeg and closes the PlainRtpTransports.
+func (egress *SrtEgress) Close() {
+	egress.locker.Lock()
+	if egress.closed {
+		egress.locker.Unlock()
+		return
+	}
+	egress.closed = true
+	child := egress.child
+	egress.locker.Unlock()
+
+	egress.logger.Debug("close()")
+
+	if child != nil && child.Process != nil {
+		terminateProcess(child.Process)
+	}
+
+	for _, transport := range egress.transports {
+		transport.Close()
+	}
+
+	egress.SafeEmit("close")
+}
+
+func (egress *SrtEgress) spawn(sdp string) (err error) {
+	args := []string{
+		"-loglevel", "warning",
+		"-protocol_whitelist", "pipe,udp,rtp",
+		"-f", "sdp",
+		"-i", "pipe:0",
+		"-map", "0",
+	}
+	args = append(args, egress.params.OutputArgs...)
+	args = append(args, "-f", "mpegts", egress.params.Url)
+
+	egress.logger.Debugf("spawning ffmpeg: %s %s",
+		egress.params.FfmpegBin, strings.Join(args, " "))
+
+	child := exec.Command(egress.params.FfmpegBin, args...)
+	child.Stdin = strings.NewReader(sdp)
+
+	stderr, err := child.StderrPipe()
+	if err != nil {
+		return
+	}
+
+	if err = child.Start(); err != nil {
+		return
+	}
+
+	egress.locker.Lock()
+	egress.child = child
+	egress.locker.Unlock()
+
+	go func() {
+		r := bufio.NewReader(stderr)
+		for {
+			line, _, err := r.ReadLine()
+			if err != nil {
+				break
+			}
+			egress.logger.Warnf("(ffmpeg) %s", line)
+		}
+	}()
+
+	go func() {
+		err := child.Wait()
+
+		if err == nil && !egress.Closed() {
+			err = errors.New("ffmpeg exited")
+		}
+
+		// A nil error can't be emitted, the listeners get it as zero value.
+		if err != nil {
+			egress.SafeEmit("processexit", err)
+		} else {
+			egress.SafeEmit("processexit")
+		}
+		egress.Close()
+	}()
+
+	return
+}
+
+type srtEgressStream struct {
+	Kind          MediaKind
+	RtpParameters RtpParameters
+	Port          uint16
+}
+
+// generateSrtEgressSdp describes the RTP streams sent to ffmpeg.
+func generateSrtEgressSdp(ip string, streams []srtEgressStream) string {
+	var b bytes.Buffer
+
+	ipVersion := "IP4"
+	if strings.Contains(ip, ":") {
+		ipVersion = "IP6"
+	}
+
+	fmt.Fprintf(&b, "v=0\r\n")
+	fmt.Fprintf(&b, "o=- 0 0 IN %s %s\r\n", ipVersion, ip)
+	fmt.Fprintf(&b, "s=mediasoup\r\n")
+	fmt.Fprintf(&b, "c=IN %s %s\r\n", ipVersion, ip)
+	fmt.Fprintf(&b, "t=0 0\r\n")
+
+	for _, stream := range streams {
+		codec := stream.RtpParameters.Codecs[0]
+		mimeType := ParseMimeType(codec.MimeType)
+
+		fmt.Fprintf(&b, "m=%s %d RTP/AVP %d\r\n", stream.Kind, stream.Port, codec.PayloadType)
+
+		rtpmap := fmt.Sprintf("%d %s/%d", codec.PayloadType, mimeType.Subtype(), codec.ClockRate)
+		if codec.Channels > 1 {
+			rtpmap = fmt.Sprintf("%s/%d", rtpmap, codec.Channels)
+		}
+		fmt.Fprintf(&b, "a=rtpmap:%s\r\n", rtpmap)
+
+		if mimeType.String() == "video/h264" && codec.Parameters != nil {
+			fmt.Fprintf(&b, "a=fmtp:%d packetization-mode=%d", codec.PayloadType,
+				codec.Parameters.PacketizationMode)
+			if len(codec.Parameters.ProfileLevelId) > 0 {
+				fmt.Fprintf(&b, ";profile-level-id=%s", codec.Parameters.ProfileLevelId)
+			}
+			fmt.Fprintf(&b, "\r\n")
+		}
+
+		fmt.Fprintf(&b, "a=recvonly\r\n")
+	}
+
+	return b.String()
+}
+
+// getFreeUdpPortPair returns an even UDP port which, as the following one, is
+// currently free.
+func getFreeUdpPortPair(ip string) (port uint16, err error) {
+	for i := 0; i < 20; i++ {
+		var conn *net.UDPConn
+
+		conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(ip)})
+		if err != nil {
+			return
+		}
+
+		candidate := conn.LocalAddr().(*net.UDPAddr).Port &^ 1
+
+		conn.Close()
+
+		if candidate == 0 || candidate >= 65535 {
+			continue
+		}
+
+		conns := make([]*net.UDPConn, 0, 2)
+
+		for _, p := range []int{candidate, candidate + 1} {
+			if c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(ip), Port: p}); err == nil {
+				conns = append(conns, c)
+			}
+		}
+
+		for _, c := range conns {
+			c.Close()
+		}
+
+		if len(conns) == 2 {
+			return uint16(candidate), nil
+		}
+	}
+
+	return 0, errors.New("no free UDP port pair")
+}
@@ -0,0 +1,198 @@
+package mediasoup
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/h264profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSrtEgressSdp(t *testing.T) {
+	sdp := generateSrtEgressSdp("127.0.0.1", []srtEgressStream{
+		{
+			Kind: "audio",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+				},
+			},
+			Port: 20000,
+		},
+		{
+			Kind: "video",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{
+						MimeType:    "video/H264",
+						PayloadType: 101,
+						ClockRate:   90000,
+						Parameters: &RtpCodecParameter{
+							RtpH264Parameter: h264profile.RtpH264Parameter{
+								PacketizationMode: 1,
+								ProfileLevelId:    "42e01f",
+							},
+						},
+					},
+				},
+			},
+			Port: 20002,
+		},
+	})
+
+	assert.Equal(t, "v=0\r\n"+
+		"o=- 0 0 IN IP4 127.0.0.1\r\n"+
+		"s=mediasoup\r\n"+
+		"c=IN IP4 127.0.0.1\r\n"+
+		"t=0 0\r\n"+
+		"m=audio 20000 RTP/AVP 100\r\n"+
+		"a=rtpmap:100 opus/48000/2\r\n"+
+		"a=recvonly\r\n"+
+		"m=video 20002 RTP/AVP 101\r\n"+
+		"a=rtpmap:101 h264/90000\r\n"+
+		"a=fmtp:101 packetization-mode=1;profile-level-id=42e01f\r\n"+
+		"a=recvonly\r\n", sdp)
+}
+
+func TestSrtEgressClose_ProcessExit(t *testing.T) {
+	bin, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("no true binary")
+	}
+
+	logger := TypeLogger("SrtEgress")
+	egress := &SrtEgress{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		params:       SrtEgressParams{FfmpegBin: bin, Url: "srt://127.0.0.1:9000"},
+	}
+
+	var locker sync.Mutex
+	closes := 0
+	egress.On("close", func() {
+		locker.Lock()
+		closes++
+		locker.Unlock()
+	})
+	exited := make(chan struct{})
+	egress.On("processexit", func(err error) { close(exited) })
+
+	assert.NoError(t, egress.spawn(""))
+
+	// Closed by the user while the process exits.
+	egress.Close()
+	<-exited
+
+	assert.True(t, egress.Closed())
+	locker.Lock()
+	assert.Equal(t, 1, closes)
+	locker.Unlock()
+}
+
+func TestCreateSrtEgress_ResumeFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh binary")
+	}
+
+	// A fake ffmpeg recording its pid and running until killed.
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	ffmpegBin := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho $$ > " + pidFile + "\nexec sleep 60\n"
+	assert.NoError(t, os.WriteFile(ffmpegBin, []byte(script), 0755))
+
+	readPid := func() int {
+		data, _ := os.ReadFile(pidFile)
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		return pid
+	}
+
+	worker := newTestWorker()
+	worker.channel = newFailingTestChannel(func(method string) bool {
+		if method != "consumer.resume" {
+			return false
+		}
+		// Fail once the fake ffmpeg runs.
+		waitCondition(func() bool { return readPid() > 0 })
+		return true
+	})
+
+	router, err := worker.CreateRouter([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	var transports []Transport
+	var consumers []*Consumer
+	router.Observer().On("newtransport", func(transport Transport) {
+		transports = append(transports, transport)
+		transport.Observer().On("newconsumer", func(consumer *Consumer) {
+			consumers = append(consumers, consumer)
+		})
+	})
+
+	transport, err := router.CreatePlainTransport(CreatePlainTransportParams{
+		ListenIp: ListenIp{Ip: "127.0.0.1"},
+	})
+	assert.NoError(t, err)
+
+	producer, err := transport.Produce(transportProduceParams{
+		Kind: "video",
+		RtpParameters: RtpParameters{
+			Codecs: []RtpCodecCapability{
+				{MimeType: "video/VP8", ClockRate: 90000, PayloadType: 96},
+			},
+			Encodings: []RtpEncoding{{Ssrc: 1111}},
+		},
+	})
+	assert.NoError(t, err)
+
+	egress, err := router.CreateSrtEgress(SrtEgressParams{
+		Url:         "srt://127.0.0.1:9000",
+		ProducerIds: []string{producer.Id()},
+		FfmpegBin:   ffmpegBin,
+	})
+	assert.Error(t, err)
+	assert.Nil(t, egress)
+
+	// The SDP given to ffmpeg has no RTX payload type.
+	if assert.Len(t, consumers, 1) {
+		codecs := consumers[0].RtpParameters().Codecs
+		assert.Len(t, codecs, 1)
+		assert.Equal(t, "video/VP8", codecs[0].MimeType)
+		assert.Nil(t, consumers[0].RtpParameters().Encodings[0].Rtx)
+	}
+
+	// The transport of the egress is closed.
+	if assert.Len(t, transports, 2) {
+		assert.False(t, transports[0].Closed())
+		assert.True(t, transports[1].Closed())
+	}
+
+	// The fake ffmpeg is stopped.
+	pid := readPid()
+	assert.NotZero(t, pid)
+	assert.True(t, waitCondition(func() bool {
+		process, err := os.FindProcess(pid)
+		return err != nil || process.Signal(syscall.Signal(0)) != nil
+	}))
+}
+
+// waitCondition polls condition for up to a second.
+func waitCondition(condition func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return condition()
+}
@@ -0,0 +1,78 @@
+package mediasoup
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// SRTP crypto suites supported by PlainRtpTransport and PipeTransport, named
+// as in SDES "a=crypto" lines (RFC 4568), see sdp.MediaDescription.Crypto.
+const (
+	SrtpCryptoSuiteAeadAes256Gcm       = "AEAD_AES_256_GCM"
+	SrtpCryptoSuiteAeadAes128Gcm       = "AEAD_AES_128_GCM"
+	SrtpCryptoSuiteAesCm128HmacSha1_80 = "AES_CM_128_HMAC_SHA1_80"
+	SrtpCryptoSuiteAesCm128HmacSha1_32 = "AES_CM_128_HMAC_SHA1_32"
+	defaultSrtpCryptoSuite             = SrtpCryptoSuiteAesCm128HmacSha1_80
+)
+
+// Master key plus master salt lengths in bytes of the SRTP crypto suites.
+var srtpKeyLengths = map[string]int{
+	SrtpCryptoSuiteAeadAes256Gcm:       44,
+	SrtpCryptoSuiteAeadAes128Gcm:       28,
+	SrtpCryptoSuiteAesCm128HmacSha1_80: 30,
+	SrtpCryptoSuiteAesCm128HmacSha1_32: 30,
+}
+
+/**
+ * SrtpParameters of a PlainRtpTransport or PipeTransport with SRTP enabled.
+ *
+ * The keys of a transport cannot be rotated: the worker generates the local
+ * key when the transport is created and accepts the remote one in a single
+ * connect() request. To change keys, create another transport (which also
+ * gets another local port) and move the Producers and Consumers to it.
+ */
+type SrtpParameters struct {
+	CryptoSuite string `json:"cryptoSuite"`
+	// KeyBase64 is the base64 encoded master key and salt.
+	KeyBase64 string `json:"keyBase64"`
+}
+
+// Validate checks the crypto suite and the key length.
+func (params SrtpParameters) Validate() error {
+	keyLength, ok := srtpKeyLengths[params.CryptoSuite]
+	if !ok {
+		return NewTypeError("invalid SRTP crypto suite [cryptoSuite:%s]", params.CryptoSuite)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(params.KeyBase64)
+	if err != nil {
+		return NewTypeError("invalid SRTP key: %s", err)
+	}
+	if len(key) != keyLength {
+		return NewTypeError("invalid SRTP key length [cryptoSuite:%s, length:%d]",
+			params.CryptoSuite, len(key))
+	}
+
+	return nil
+}
+
+// NewSrtpParameters returns SRTP parameters with a random key, e.g. for the
+// remote endpoint of a PlainRtpTransport (FFmpeg -srtp_out_params).
+func NewSrtpParameters(cryptoSuite string) (params SrtpParameters, err error) {
+	keyLength, ok := srtpKeyLengths[cryptoSuite]
+	if !ok {
+		err = NewTypeError("invalid SRTP crypto suite [cryptoSuite:%s]", cryptoSuite)
+		return
+	}
+
+	key := make([]byte, keyLength)
+
+	if _, err = rand.Read(key); err != nil {
+		return
+	}
+
+	return SrtpParameters{
+		CryptoSuite: cryptoSuite,
+		KeyBase64:   base64.StdEncoding.EncodeToString(key),
+	}, nil
+}
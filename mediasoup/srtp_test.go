@@ -0,0 +1,40 @@
+package mediasoup
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSrtpParameters(t *testing.T) {
+	for suite, length := range srtpKeyLengths {
+		params, err := NewSrtpParameters(suite)
+		assert.NoError(t, err)
+		assert.Equal(t, suite, params.CryptoSuite)
+		assert.NoError(t, params.Validate())
+
+		key, _ := base64.StdEncoding.DecodeString(params.KeyBase64)
+		assert.Len(t, key, length)
+	}
+
+	_, err := NewSrtpParameters("foo")
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestSrtpParametersValidate(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 30))
+
+	assert.NoError(t, SrtpParameters{
+		CryptoSuite: SrtpCryptoSuiteAesCm128HmacSha1_80,
+		KeyBase64:   key,
+	}.Validate())
+
+	for _, params := range []SrtpParameters{
+		{CryptoSuite: "foo", KeyBase64: key},
+		{CryptoSuite: SrtpCryptoSuiteAeadAes256Gcm, KeyBase64: key},
+		{CryptoSuite: SrtpCryptoSuiteAesCm128HmacSha1_32, KeyBase64: "!"},
+	} {
+		assert.IsType(t, NewTypeError(""), params.Validate())
+	}
+}
@@ -0,0 +1,133 @@
+package mediasoup
+
+import "sync"
+
+/**
+ * SsrcAllocator returns the SSRC of a new consumable stream of a Router, e.g.
+ * because an application shards routers with disjoint ranges or wants
+ * reproducible SSRCs in tests. The Router skips the SSRCs still in use, and
+ * fails to create the Producer if the allocator returns 0 or only SSRCs in
+ * use.
+ */
+type SsrcAllocator func() uint32
+
+/**
+ * NewSequentialSsrcAllocator returns an SsrcAllocator handing out SSRCs
+ * sequentially from first, as mediasoup does from a random base. SSRC 0 is
+ * skipped when wrapping around, so the SSRCs are unique until 2^32 - 1 of
+ * them have been allocated.
+ */
+func NewSequentialSsrcAllocator(first uint32) SsrcAllocator {
+	var (
+		locker sync.Mutex
+		next   = first
+	)
+
+	return func() uint32 {
+		locker.Lock()
+		defer locker.Unlock()
+
+		if next == 0 {
+			next = 1
+		}
+
+		ssrc := next
+		next++
+
+		return ssrc
+	}
+}
+
+/**
+ * NewRangeSsrcAllocator returns an SsrcAllocator handing out the SSRCs of the
+ * given range sequentially, wrapping around once its end is reached, as
+ * used for Routers created with a MappedSsrcRange.
+ */
+func NewRangeSsrcAllocator(ssrcRange MappedSsrcRange) SsrcAllocator {
+	var (
+		locker sync.Mutex
+		next   = ssrcRange.Min
+	)
+
+	return func() uint32 {
+		locker.Lock()
+		defer locker.Unlock()
+
+		ssrc := next
+
+		if next >= ssrcRange.Max {
+			next = ssrcRange.Min
+		} else {
+			next++
+		}
+
+		return ssrc
+	}
+}
+
+// Draws from the SSRC source of a Router, in addition to one per SSRC in use,
+// before giving up on finding an SSRC not in use. A sequential source finds
+// one within the SSRCs in use plus one draws if there is any left.
+const maxSsrcAllocationAttempts = 1000
+
+/**
+ * routerSsrcAllocator hands out the mapped SSRCs of a Router from its
+ * SsrcAllocator, checking that none is in use by another Producer of the
+ * Router, e.g. after the allocator wrapped around. Generate returns 0 when no
+ * unused SSRC can be found.
+ */
+type routerSsrcAllocator struct {
+	locker sync.Mutex
+	source generateSsrcFunc
+	inUse  map[uint32]bool
+}
+
+func (allocator *routerSsrcAllocator) Generate() uint32 {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	attempts := maxSsrcAllocationAttempts + len(allocator.inUse)
+
+	for i := 0; i < attempts; i++ {
+		ssrc := allocator.source()
+
+		// Exhausted source.
+		if ssrc == 0 {
+			return 0
+		}
+		if !allocator.inUse[ssrc] {
+			allocator.inUse[ssrc] = true
+			return ssrc
+		}
+	}
+
+	return 0
+}
+
+// Release makes the SSRC of a closed Producer available again.
+func (allocator *routerSsrcAllocator) Release(ssrc uint32) {
+	allocator.locker.Lock()
+	defer allocator.locker.Unlock()
+
+	delete(allocator.inUse, ssrc)
+}
+
+// newRouterSsrcAllocator returns the generator of the mapped SSRCs of a
+// Router, and the release of the SSRCs of closed Producers: the given
+// allocator, the given range or a random base followed by sequential SSRCs.
+func newRouterSsrcAllocator(data routerData) (generate generateSsrcFunc, release func(ssrc uint32)) {
+	allocator := &routerSsrcAllocator{
+		inUse: make(map[uint32]bool),
+	}
+
+	switch {
+	case data.SsrcAllocator != nil:
+		allocator.source = generateSsrcFunc(data.SsrcAllocator)
+	case data.MappedSsrcRange != nil:
+		allocator.source = generateSsrcFunc(NewRangeSsrcAllocator(*data.MappedSsrcRange))
+	default:
+		allocator.source = generateSsrcFunc(NewSequentialSsrcAllocator(generateRandomNumber()))
+	}
+
+	return allocator.Generate, allocator.Release
+}
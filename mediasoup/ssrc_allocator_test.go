@@ -0,0 +1,189 @@
+package mediasoup
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequentialSsrcAllocator(t *testing.T) {
+	allocate := NewSequentialSsrcAllocator(1000)
+
+	assert.EqualValues(t, 1000, allocate())
+	assert.EqualValues(t, 1001, allocate())
+
+	allocate = NewSequentialSsrcAllocator(math.MaxUint32)
+
+	assert.EqualValues(t, math.MaxUint32, allocate())
+	assert.EqualValues(t, 1, allocate())
+}
+
+func TestRouterSsrcAllocator(t *testing.T) {
+	generate, _ := newRouterSsrcAllocator(routerData{SsrcAllocator: NewSequentialSsrcAllocator(5)})
+
+	assert.EqualValues(t, 5, generate())
+
+	generate, release := newRouterSsrcAllocator(routerData{MappedSsrcRange: &MappedSsrcRange{Min: 10, Max: 11}})
+
+	assert.EqualValues(t, 10, generate())
+	assert.EqualValues(t, 11, generate())
+	assert.EqualValues(t, 0, generate())
+
+	release(10)
+	assert.EqualValues(t, 10, generate())
+
+	generate, _ = newRouterSsrcAllocator(routerData{})
+	first := generate()
+
+	assert.Equal(t, first+1, generate())
+}
+
+func TestRouterSsrcAllocator_WrapAround(t *testing.T) {
+	// An allocator cycling over a few SSRCs.
+	cycle := []uint32{7, 8, 9}
+	next := 0
+	generate, release := newRouterSsrcAllocator(routerData{SsrcAllocator: func() uint32 {
+		ssrc := cycle[next%len(cycle)]
+		next++
+		return ssrc
+	}})
+
+	assert.EqualValues(t, 7, generate())
+	assert.EqualValues(t, 8, generate())
+
+	release(7)
+
+	// 9, then 7 again after wrapping around, skipping 8 in use.
+	assert.EqualValues(t, 9, generate())
+	assert.EqualValues(t, 7, generate())
+	assert.EqualValues(t, 0, generate())
+
+	// Sequential SSRCs wrapping around from the largest one.
+	generate, _ = newRouterSsrcAllocator(routerData{SsrcAllocator: NewSequentialSsrcAllocator(math.MaxUint32)})
+
+	assert.EqualValues(t, uint32(math.MaxUint32), generate())
+	assert.EqualValues(t, 1, generate())
+
+	// Range wrapping around.
+	generate, release = newRouterSsrcAllocator(routerData{MappedSsrcRange: &MappedSsrcRange{Min: 10, Max: 12}})
+
+	assert.EqualValues(t, 10, generate())
+	assert.EqualValues(t, 11, generate())
+	assert.EqualValues(t, 12, generate())
+
+	release(11)
+	assert.EqualValues(t, 11, generate())
+	assert.EqualValues(t, 0, generate())
+}
+
+func TestRangeSsrcAllocator(t *testing.T) {
+	allocate := NewRangeSsrcAllocator(MappedSsrcRange{Min: 1000000, Max: 1000002})
+
+	assert.EqualValues(t, 1000000, allocate())
+	assert.EqualValues(t, 1000001, allocate())
+	assert.EqualValues(t, 1000002, allocate())
+	assert.EqualValues(t, 1000000, allocate())
+
+	// Single SSRC range.
+	allocate = NewRangeSsrcAllocator(MappedSsrcRange{Min: math.MaxUint32, Max: math.MaxUint32})
+	assert.EqualValues(t, uint32(math.MaxUint32), allocate())
+	assert.EqualValues(t, uint32(math.MaxUint32), allocate())
+}
+
+func TestRouterSsrcAllocator_LargeRange(t *testing.T) {
+	generate, release := newRouterSsrcAllocator(routerData{
+		MappedSsrcRange: &MappedSsrcRange{Min: 1, Max: 2 * maxSsrcAllocationAttempts},
+	})
+
+	for i := 0; i < 2*maxSsrcAllocationAttempts; i++ {
+		assert.NotZero(t, generate())
+	}
+	assert.EqualValues(t, 0, generate())
+
+	// Found after skipping every other SSRC in use.
+	release(2 * maxSsrcAllocationAttempts)
+	assert.EqualValues(t, 2*maxSsrcAllocationAttempts, generate())
+}
+
+func TestTransportProduce_ReleasesMappedSsrcs(t *testing.T) {
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	assert.NoError(t, err)
+
+	generate, release := newRouterSsrcAllocator(routerData{
+		MappedSsrcRange: &MappedSsrcRange{Min: 1000000, Max: 1000001},
+	})
+	transport := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  newTestChannel(),
+		GetRouterRtpCapabilities: func() RtpCapabilities {
+			return caps
+		},
+		GenerateMappedSsrc: generate,
+		ReleaseMappedSsrc:  release,
+	})
+
+	produce := func(ssrc uint32) (*Producer, error) {
+		return transport.Produce(transportProduceParams{
+			Kind: "audio",
+			RtpParameters: RtpParameters{
+				Codecs: []RtpCodecCapability{
+					{MimeType: "audio/opus", PayloadType: 111, ClockRate: 48000, Channels: 2},
+				},
+				Encodings: []RtpEncoding{{Ssrc: ssrc}},
+			},
+		})
+	}
+
+	producer1, err := produce(11111111)
+	assert.NoError(t, err)
+	producer2, err := produce(11111112)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t,
+		producer1.ConsumableRtpParameters().Encodings[0].Ssrc,
+		producer2.ConsumableRtpParameters().Encodings[0].Ssrc)
+
+	_, err = produce(11111113)
+	assert.IsType(t, NewInvalidStateError(""), err)
+
+	assert.NoError(t, producer1.Close())
+
+	producer3, err := produce(11111113)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		producer1.ConsumableRtpParameters().Encodings[0].Ssrc,
+		producer3.ConsumableRtpParameters().Encodings[0].Ssrc)
+}
+
+func TestGetProducerRtpParametersMapping_UniqueMappedSsrcs(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: &RtpCodecParameter{Apt: 101}},
+		},
+		Encodings: []RtpEncoding{
+			{Ssrc: 1111, Rtx: &RtpEncoding{Ssrc: 1112}},
+			{Ssrc: 2222, Rtx: &RtpEncoding{Ssrc: 2223}},
+			{Ssrc: 3333, Rtx: &RtpEncoding{Ssrc: 3334}},
+		},
+	}
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	assert.NoError(t, err)
+
+	rtpMapping, err := getProducerRtpParametersMapping(params, caps,
+		generateSsrcFunc(NewSequentialSsrcAllocator(100)), HeaderExtensionStrict, nil)
+	assert.NoError(t, err)
+
+	var mappedSsrcs []uint32
+
+	for _, encoding := range rtpMapping.Encodings {
+		mappedSsrcs = append(mappedSsrcs, encoding.MappedSsrc, encoding.MappedRtxSsrc)
+	}
+
+	assert.Equal(t, []uint32{100, 101, 102, 103, 104, 105}, mappedSsrcs)
+}
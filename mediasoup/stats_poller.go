@@ -0,0 +1,282 @@
+package mediasoup
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// GetStreamStats returns the typed stats of the Producer streams.
+func (producer *Producer) GetStreamStats() (stats []RtpStreamStat, err error) {
+	err = producer.GetStats().Unmarshal(&stats)
+	return
+}
+
+// GetStreamStats returns the typed stats of the Consumer stream, and of the
+// consumed Producer stream.
+func (consumer *Consumer) GetStreamStats() (stats []RtpStreamStat, err error) {
+	err = consumer.GetStats().Unmarshal(&stats)
+	return
+}
+
+type StatsPollerOptions struct {
+	// Interval between polls, default 10s.
+	Interval time.Duration
+	// Clock used for the interval and the snapshot timestamps, default
+	// clock.System.
+	Clock clock.Clock
+	// Bulk emits all the snapshots of a poll as one "bulkstats" event
+	// instead of a "stats" event per entity.
+	Bulk bool
+}
+
+// StatsSnapshot is the stats of an entity at a point in time, Transport is
+// set for transports and Streams for producers and consumers.
+type StatsSnapshot struct {
+	Id string `json:"id"`
+	// Entity is IdEntityTransport, IdEntityProducer or IdEntityConsumer.
+	Entity string `json:"entity"`
+	// Timestamp in milliseconds.
+	Timestamp int64           `json:"timestamp"`
+	Transport []TransportStat `json:"transport,omitempty"`
+	Streams   []RtpStreamStat `json:"streams,omitempty"`
+	// Labels from the app data of the entity, see SetAppDataLabeler().
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// BulkStats is the consolidated result of a poll, see StatsPollerOptions.Bulk.
+type BulkStats struct {
+	// Timestamp in milliseconds.
+	Timestamp int64 `json:"timestamp"`
+	// Snapshots by entity id.
+	Snapshots map[string]StatsSnapshot `json:"snapshots"`
+	// Errors by entity id, also emitted as "statserror".
+	Errors map[string]error `json:"-"`
+}
+
+// Entities returns the snapshots of the given entity type (e.g.
+// IdEntityConsumer), sorted by id.
+func (stats BulkStats) Entities(entity string) (snapshots []StatsSnapshot) {
+	for _, snapshot := range stats.Snapshots {
+		if snapshot.Entity == entity {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Id < snapshots[j].Id
+	})
+
+	return
+}
+
+/**
+ * StatsPoller polls the typed stats of the added transports, producers and
+ * consumers periodically and emits them, until they are closed.
+ *
+ * @emits {snapshot StatsSnapshot} stats
+ * @emits {stats BulkStats} bulkstats
+ * @emits {id string, err error} statserror
+ */
+type StatsPoller struct {
+	EventEmitter
+	logger   logrus.FieldLogger
+	options  StatsPollerOptions
+	locker   sync.Mutex
+	entities map[string]func() (StatsSnapshot, error)
+	stopCh   chan struct{}
+	closed   bool
+}
+
+func NewStatsPoller(options StatsPollerOptions) *StatsPoller {
+	logger := TypeLogger("StatsPoller")
+
+	logger.Debug("constructor()")
+
+	if options.Interval == 0 {
+		options.Interval = 10 * time.Second
+	}
+	options.Clock = clock.OrSystem(options.Clock)
+
+	poller := &StatsPoller{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		options:      options,
+		entities:     make(map[string]func() (StatsSnapshot, error)),
+		stopCh:       make(chan struct{}),
+	}
+
+	go poller.run()
+
+	return poller
+}
+
+// AddTransport polls the stats of the Transport until it is closed.
+func (poller *StatsPoller) AddTransport(transport Transport) {
+	poller.add(transport.Id(), transport.Observer(), transport.AppData(), func() (snapshot StatsSnapshot, err error) {
+		snapshot.Entity = IdEntityTransport
+		snapshot.Transport, err = transport.GetStats()
+		return
+	})
+}
+
+// AddProducer polls the stats of the Producer until it is closed.
+func (poller *StatsPoller) AddProducer(producer *Producer) {
+	poller.add(producer.Id(), producer.Observer(), producer.AppData(), func() (snapshot StatsSnapshot, err error) {
+		snapshot.Entity = IdEntityProducer
+		snapshot.Streams, err = producer.GetStreamStats()
+		return
+	})
+}
+
+// AddConsumer polls the stats of the Consumer until it is closed.
+func (poller *StatsPoller) AddConsumer(consumer *Consumer) {
+	poller.add(consumer.Id(), consumer.Observer(), consumer.AppData(), func() (snapshot StatsSnapshot, err error) {
+		snapshot.Entity = IdEntityConsumer
+		snapshot.Streams, err = consumer.GetStreamStats()
+		return
+	})
+}
+
+// Remove stops polling the stats of the entity.
+func (poller *StatsPoller) Remove(id string) {
+	poller.locker.Lock()
+	defer poller.locker.Unlock()
+
+	delete(poller.entities, id)
+}
+
+func (poller *StatsPoller) Closed() bool {
+	poller.locker.Lock()
+	defer poller.locker.Unlock()
+
+	return poller.closed
+}
+
+// Close stops polling.
+func (poller *StatsPoller) Close() {
+	poller.locker.Lock()
+	defer poller.locker.Unlock()
+
+	if poller.closed {
+		return
+	}
+
+	poller.logger.Debug("close()")
+
+	poller.closed = true
+	close(poller.stopCh)
+}
+
+func (poller *StatsPoller) add(
+	id string, observer EventEmitter, appData interface{}, getStats func() (StatsSnapshot, error),
+) {
+	labels := appDataLabels(appData)
+
+	poller.locker.Lock()
+
+	if poller.closed || poller.entities[id] != nil {
+		poller.locker.Unlock()
+		return
+	}
+
+	poller.entities[id] = func() (snapshot StatsSnapshot, err error) {
+		snapshot, err = getStats()
+		snapshot.Labels = labels
+		return
+	}
+	poller.locker.Unlock()
+
+	observer.On("close", func() {
+		poller.Remove(id)
+	})
+}
+
+func (poller *StatsPoller) run() {
+	ticker := poller.options.Clock.NewTicker(poller.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-poller.stopCh:
+			return
+		case <-ticker.C():
+		}
+
+		poller.locker.Lock()
+		entities := make(map[string]func() (StatsSnapshot, error), len(poller.entities))
+		for id, getStats := range poller.entities {
+			entities[id] = getStats
+		}
+		poller.locker.Unlock()
+
+		bulk := BulkStats{
+			Timestamp: clock.NowMs(poller.options.Clock),
+			Snapshots: make(map[string]StatsSnapshot, len(entities)),
+			Errors:    make(map[string]error),
+		}
+
+		for id, getStats := range entities {
+			snapshot, err := getStats()
+			if err != nil {
+				poller.logger.Warnf("polling stats failed [id:%s]: %s", id, err)
+
+				bulk.Errors[id] = err
+				poller.SafeEmit("statserror", id, err)
+				continue
+			}
+
+			snapshot.Id = id
+			snapshot.Timestamp = clock.NowMs(poller.options.Clock)
+
+			if poller.options.Bulk {
+				bulk.Snapshots[id] = snapshot
+			} else {
+				poller.SafeEmit("stats", snapshot)
+			}
+		}
+
+		if poller.options.Bulk {
+			poller.SafeEmit("bulkstats", bulk)
+		}
+	}
+}
+
+/**
+ * StreamStats polls the stats of all the transports, producers and consumers
+ * of the Router, including the ones created later, and emits them as one
+ * "bulkstats" event per interval. The poller is closed with the Router.
+ *
+ * The worker has no bulk stats request, so each entity is still requested on
+ * its own, but the application handles a single message per interval.
+ */
+func (router *Router) StreamStats(options StatsPollerOptions) *StatsPoller {
+	options.Bulk = true
+	poller := NewStatsPoller(options)
+
+	addTransport := func(transport Transport) {
+		poller.AddTransport(transport)
+
+		transport.Observer().On("newproducer", poller.AddProducer)
+		transport.Observer().On("newconsumer", poller.AddConsumer)
+
+		for _, consumer := range transport.consumerList() {
+			poller.AddConsumer(consumer)
+		}
+	}
+
+	for _, transport := range router.Transports() {
+		addTransport(transport)
+	}
+	for _, producer := range router.Producers() {
+		poller.AddProducer(producer)
+	}
+
+	router.observer.On("newtransport", addTransport)
+	router.observer.On("close", poller.Close)
+
+	return poller
+}
@@ -0,0 +1,118 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsPoller(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fakeClock := testutil.NewFakeClock(start)
+
+	poller := NewStatsPoller(StatsPollerOptions{Interval: time.Second, Clock: fakeClock})
+	defer poller.Close()
+
+	snapshots := make(chan StatsSnapshot, 1)
+	errors := make(chan string, 1)
+
+	poller.On("stats", func(snapshot StatsSnapshot) { snapshots <- snapshot })
+	poller.On("statserror", func(id string, err error) { errors <- id })
+
+	observer := NewEventEmitter(TypeLogger("test"))
+
+	poller.add("t1", observer, nil, func() (StatsSnapshot, error) {
+		return StatsSnapshot{
+			Entity:    IdEntityTransport,
+			Transport: []TransportStat{{Type: "webrtc-transport", BytesReceived: 1 << 33}},
+		}, nil
+	})
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	snapshot := <-snapshots
+	assert.Equal(t, "t1", snapshot.Id)
+	assert.Equal(t, start.Add(time.Second).UnixNano()/int64(time.Millisecond), snapshot.Timestamp)
+	assert.EqualValues(t, 1<<33, snapshot.Transport[0].BytesReceived)
+
+	// The stats of the test channel are not an array.
+	observer.Emit("close")
+
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{Kind: MediaKindAudio},
+		newTestChannel(), nil, false)
+	poller.AddProducer(producer)
+
+	fakeClock.Advance(time.Second)
+
+	assert.Equal(t, "p1", <-errors)
+}
+
+func TestRtpStreamStatPacketLoss(t *testing.T) {
+	assert.Equal(t, 0.25, RtpStreamStat{FractionLost: 64}.PacketLoss())
+}
+
+func TestStatsPollerBulk(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(1000, 0))
+
+	poller := NewStatsPoller(StatsPollerOptions{Interval: time.Second, Clock: fakeClock, Bulk: true})
+	defer poller.Close()
+
+	bulks := make(chan BulkStats, 1)
+
+	poller.On("stats", func(snapshot StatsSnapshot) { t.Error("unexpected stats event") })
+	poller.On("bulkstats", func(stats BulkStats) { bulks <- stats })
+
+	observer := NewEventEmitter(TypeLogger("test"))
+
+	for _, id := range []string{"c2", "c1"} {
+		poller.add(id, observer, nil, func() (StatsSnapshot, error) {
+			return StatsSnapshot{Entity: IdEntityConsumer}, nil
+		})
+	}
+	poller.add("t1", observer, nil, func() (StatsSnapshot, error) {
+		return StatsSnapshot{Entity: IdEntityTransport}, nil
+	})
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	stats := <-bulks
+	assert.Len(t, stats.Snapshots, 3)
+	assert.Empty(t, stats.Errors)
+	assert.Equal(t, time.Unix(1001, 0).UnixNano()/int64(time.Millisecond), stats.Timestamp)
+
+	consumers := stats.Entities(IdEntityConsumer)
+	assert.Len(t, consumers, 2)
+	assert.Equal(t, "c1", consumers[0].Id)
+	assert.Equal(t, "c2", consumers[1].Id)
+}
+
+func TestRouterStreamStats(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(1000, 0))
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{}, newTestChannel())
+
+	poller := router.StreamStats(StatsPollerOptions{Interval: time.Second, Clock: fakeClock})
+
+	bulks := make(chan BulkStats, 1)
+	poller.On("bulkstats", func(stats BulkStats) { bulks <- stats })
+
+	transport := newTransport(createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  router.channel,
+	})
+	router.observer.SafeEmit("newtransport", transport)
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	// The stats of the test channel are not an array.
+	stats := <-bulks
+	assert.Empty(t, stats.Snapshots)
+	assert.Contains(t, stats.Errors, "t1")
+
+	router.Close()
+	assert.True(t, poller.Closed())
+}
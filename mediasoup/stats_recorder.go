@@ -0,0 +1,347 @@
+package mediasoup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of the header of a sample record: timestamp (int64) and data length
+// (uint32), both big endian.
+const statsSampleHeaderSize = 12
+
+var statsEntityIdRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type StatsRecorderOptions struct {
+	// Dir the sample files are written to. It is created if missing.
+	Dir string
+	// Interval between samples, default 10s.
+	Interval time.Duration
+	// MaxFileSize of a sample file before it is rotated, default 1MB.
+	MaxFileSize int64
+	// MaxFiles kept per entity, the oldest one is removed on rotation,
+	// default 5.
+	MaxFiles int
+	// Clock used for the interval and the sample timestamps, default
+	// clock.System.
+	Clock clock.Clock
+}
+
+// StatsSample is a stats snapshot of an entity.
+type StatsSample struct {
+	// Timestamp in milliseconds.
+	Timestamp int64           `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+/**
+ * StatsRecorder periodically samples the stats of Transports, Producers and
+ * Consumers and writes them to rotating local files, one ring of files per
+ * entity named "<id>.<n>.stats", being 0 the most recent one. Every sample is
+ * stored as a binary header (timestamp and data length) followed by the JSON
+ * stats returned by the worker.
+ *
+ * @emits {id string, err error} recorderror
+ * @emits close
+ */
+type StatsRecorder struct {
+	EventEmitter
+	logger   logrus.FieldLogger
+	options  StatsRecorderOptions
+	locker   sync.Mutex
+	entities map[string]func() (json.RawMessage, error)
+	stopCh   chan struct{}
+	closed   bool
+}
+
+func NewStatsRecorder(options StatsRecorderOptions) (recorder *StatsRecorder, err error) {
+	logger := TypeLogger("StatsRecorder")
+
+	logger.Debug("constructor()")
+
+	if len(options.Dir) == 0 {
+		err = NewTypeError("missing dir")
+		return
+	}
+	if options.Interval == 0 {
+		options.Interval = 10 * time.Second
+	}
+	if options.MaxFileSize == 0 {
+		options.MaxFileSize = 1024 * 1024
+	}
+	if options.MaxFiles == 0 {
+		options.MaxFiles = 5
+	}
+	options.Clock = clock.OrSystem(options.Clock)
+
+	if err = os.MkdirAll(options.Dir, 0755); err != nil {
+		return
+	}
+
+	recorder = &StatsRecorder{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		options:      options,
+		entities:     make(map[string]func() (json.RawMessage, error)),
+		stopCh:       make(chan struct{}),
+	}
+
+	go recorder.run()
+
+	return
+}
+
+// AddTransport records the stats of the Transport until it is closed.
+func (recorder *StatsRecorder) AddTransport(transport Transport) {
+	recorder.add(transport.Id(), transport.Observer(), func() (json.RawMessage, error) {
+		stats, err := transport.GetStats()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(stats)
+	})
+}
+
+// AddProducer records the stats of the Producer until it is closed.
+func (recorder *StatsRecorder) AddProducer(producer *Producer) {
+	recorder.add(producer.Id(), producer.Observer(), func() (json.RawMessage, error) {
+		rsp := producer.GetStats()
+		return rsp.Data(), rsp.Err()
+	})
+}
+
+// AddConsumer records the stats of the Consumer until it is closed.
+func (recorder *StatsRecorder) AddConsumer(consumer *Consumer) {
+	recorder.add(consumer.Id(), consumer.Observer(), func() (json.RawMessage, error) {
+		rsp := consumer.GetStats()
+		return rsp.Data(), rsp.Err()
+	})
+}
+
+// Remove stops recording the entity with the given id. Its files are kept.
+func (recorder *StatsRecorder) Remove(id string) {
+	recorder.locker.Lock()
+	defer recorder.locker.Unlock()
+
+	delete(recorder.entities, id)
+}
+
+// Read returns the samples of the entity with the given id taken since the
+// given time, oldest first.
+func (recorder *StatsRecorder) Read(id string, since time.Time) ([]StatsSample, error) {
+	return ReadStatsSamples(recorder.options.Dir, id, since)
+}
+
+// Whether the StatsRecorder is closed.
+func (recorder *StatsRecorder) Closed() bool {
+	recorder.locker.Lock()
+	defer recorder.locker.Unlock()
+
+	return recorder.closed
+}
+
+// Close stops recording. Files are kept.
+func (recorder *StatsRecorder) Close() {
+	recorder.locker.Lock()
+
+	if recorder.closed {
+		recorder.locker.Unlock()
+		return
+	}
+
+	recorder.logger.Debug("close()")
+
+	recorder.closed = true
+	recorder.entities = make(map[string]func() (json.RawMessage, error))
+	close(recorder.stopCh)
+	recorder.locker.Unlock()
+
+	recorder.SafeEmit("close")
+}
+
+func (recorder *StatsRecorder) add(id string, observer EventEmitter, getStats func() (json.RawMessage, error)) {
+	recorder.locker.Lock()
+
+	if recorder.closed || recorder.entities[id] != nil {
+		recorder.locker.Unlock()
+		return
+	}
+
+	recorder.entities[id] = getStats
+	recorder.locker.Unlock()
+
+	observer.On("close", func() {
+		recorder.Remove(id)
+	})
+}
+
+func (recorder *StatsRecorder) run() {
+	ticker := recorder.options.Clock.NewTicker(recorder.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-recorder.stopCh:
+			return
+		case <-ticker.C():
+		}
+
+		recorder.locker.Lock()
+		entities := make(map[string]func() (json.RawMessage, error), len(recorder.entities))
+		for id, getStats := range recorder.entities {
+			entities[id] = getStats
+		}
+		recorder.locker.Unlock()
+
+		for id, getStats := range entities {
+			if err := recorder.record(id, getStats); err != nil {
+				recorder.logger.Warnf("recording stats failed [id:%s]: %s", id, err)
+
+				recorder.SafeEmit("recorderror", id, err)
+			}
+		}
+	}
+}
+
+func (recorder *StatsRecorder) record(id string, getStats func() (json.RawMessage, error)) error {
+	data, err := getStats()
+	if err != nil {
+		return err
+	}
+
+	sample := StatsSample{
+		Timestamp: clock.NowMs(recorder.options.Clock),
+		Data:      data,
+	}
+
+	return writeStatsSample(recorder.options, id, sample)
+}
+
+// writeStatsSample appends the sample to the most recent file of the entity,
+// rotating the files first if it would exceed MaxFileSize.
+func writeStatsSample(options StatsRecorderOptions, id string, sample StatsSample) (err error) {
+	if !statsEntityIdRegexp.MatchString(id) {
+		return NewTypeError("invalid entity id [id:%s]", id)
+	}
+
+	filename := statsSampleFilename(options.Dir, id, 0)
+	size := int64(statsSampleHeaderSize + len(sample.Data))
+
+	if info, err := os.Stat(filename); err == nil && info.Size() > 0 &&
+		info.Size()+size > options.MaxFileSize {
+		if err = rotateStatsSampleFiles(options, id); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	buf := make([]byte, size)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(sample.Timestamp))
+	binary.BigEndian.PutUint32(buf[8:12], uint32(len(sample.Data)))
+	copy(buf[statsSampleHeaderSize:], sample.Data)
+
+	_, err = file.Write(buf)
+
+	return
+}
+
+func rotateStatsSampleFiles(options StatsRecorderOptions, id string) error {
+	last := statsSampleFilename(options.Dir, id, options.MaxFiles-1)
+
+	if err := os.Remove(last); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for n := options.MaxFiles - 2; n >= 0; n-- {
+		err := os.Rename(statsSampleFilename(options.Dir, id, n), statsSampleFilename(options.Dir, id, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadStatsSamples reads the samples of the entity with the given id written
+// to dir by a StatsRecorder, oldest first. Samples taken before since are
+// skipped. A truncated last sample, e.g. after a crash, is ignored.
+func ReadStatsSamples(dir, id string, since time.Time) (samples []StatsSample, err error) {
+	if !statsEntityIdRegexp.MatchString(id) {
+		return nil, NewTypeError("invalid entity id [id:%s]", id)
+	}
+
+	filenames, err := filepath.Glob(filepath.Join(dir, id+".*.stats"))
+	if err != nil {
+		return
+	}
+
+	sinceMs := since.UnixNano() / int64(time.Millisecond)
+
+	// The file with the highest number is the oldest one.
+	for n := len(filenames) - 1; n >= 0; n-- {
+		if samples, err = readStatsSampleFile(statsSampleFilename(dir, id, n), sinceMs, samples); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func readStatsSampleFile(filename string, sinceMs int64, samples []StatsSample) ([]StatsSample, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return samples, nil
+		}
+		return samples, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, statsSampleHeaderSize)
+
+	for {
+		if _, err = io.ReadFull(reader, header); err != nil {
+			break
+		}
+
+		sample := StatsSample{
+			Timestamp: int64(binary.BigEndian.Uint64(header[0:8])),
+			Data:      make(json.RawMessage, binary.BigEndian.Uint32(header[8:12])),
+		}
+
+		if _, err = io.ReadFull(reader, sample.Data); err != nil {
+			break
+		}
+
+		if sample.Timestamp >= sinceMs {
+			samples = append(samples, sample)
+		}
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	}
+
+	return samples, err
+}
+
+func statsSampleFilename(dir, id string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.%d.stats", id, n))
+}
@@ -0,0 +1,129 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsSamples_WriteAndRead(t *testing.T) {
+	options := StatsRecorderOptions{
+		Dir:         t.TempDir(),
+		MaxFileSize: 64,
+		MaxFiles:    3,
+	}
+	data := json.RawMessage(`[{"type":"inbound-rtp","score":10}]`)
+
+	for i := 1; i <= 6; i++ {
+		err := writeStatsSample(options, "producer-1", StatsSample{Timestamp: int64(i), Data: data})
+		assert.NoError(t, err)
+	}
+
+	// One sample per file, only the last 3 are kept.
+	for n := 0; n < 3; n++ {
+		_, err := os.Stat(statsSampleFilename(options.Dir, "producer-1", n))
+		assert.NoError(t, err)
+	}
+	_, err := os.Stat(statsSampleFilename(options.Dir, "producer-1", 3))
+	assert.True(t, os.IsNotExist(err))
+
+	samples, err := ReadStatsSamples(options.Dir, "producer-1", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, samples, 3)
+
+	for i, sample := range samples {
+		assert.EqualValues(t, i+4, sample.Timestamp)
+		assert.JSONEq(t, string(data), string(sample.Data))
+	}
+
+	samples, err = ReadStatsSamples(options.Dir, "producer-1", time.Unix(0, int64(6*time.Millisecond)))
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+
+	samples, err = ReadStatsSamples(options.Dir, "unknown", time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, samples)
+
+	_, err = ReadStatsSamples(options.Dir, "../producer-1", time.Time{})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestStatsSamples_IgnoreTruncatedSample(t *testing.T) {
+	options := StatsRecorderOptions{
+		Dir:         t.TempDir(),
+		MaxFileSize: 1024,
+		MaxFiles:    2,
+	}
+
+	err := writeStatsSample(options, "consumer-1", StatsSample{Timestamp: 1, Data: json.RawMessage(`{}`)})
+	assert.NoError(t, err)
+
+	file, err := os.OpenFile(statsSampleFilename(options.Dir, "consumer-1", 0), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	file.Write([]byte{0, 0, 0})
+	file.Close()
+
+	samples, err := ReadStatsSamples(options.Dir, "consumer-1", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+}
+
+func TestNewStatsRecorder(t *testing.T) {
+	_, err := NewStatsRecorder(StatsRecorderOptions{})
+	assert.IsType(t, NewTypeError(""), err)
+
+	recorder, err := NewStatsRecorder(StatsRecorderOptions{Dir: t.TempDir()})
+	assert.NoError(t, err)
+	assert.False(t, recorder.Closed())
+
+	recorder.Close()
+	assert.True(t, recorder.Closed())
+}
+
+func TestStatsRecorder_FakeClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fakeClock := testutil.NewFakeClock(start)
+
+	recorder, err := NewStatsRecorder(StatsRecorderOptions{
+		Dir:      t.TempDir(),
+		Interval: time.Second,
+		Clock:    fakeClock,
+	})
+	assert.NoError(t, err)
+	defer recorder.Close()
+
+	recordedCh := make(chan struct{}, 2)
+
+	recorder.add("producer-1", NewEventEmitter(TypeLogger("test")), func() (json.RawMessage, error) {
+		recordedCh <- struct{}{}
+		return json.RawMessage(`[]`), nil
+	})
+
+	fakeClock.BlockUntil(1)
+
+	// Samples are written right after getStats() returns.
+	waitSamples := func(n int) (samples []StatsSample) {
+		for i := 0; i < 1000 && len(samples) < n; i++ {
+			time.Sleep(time.Millisecond)
+			samples, _ = recorder.Read("producer-1", start)
+		}
+		return
+	}
+
+	fakeClock.Advance(time.Second)
+	<-recordedCh
+	waitSamples(1)
+
+	fakeClock.Advance(time.Second)
+	<-recordedCh
+	samples := waitSamples(2)
+
+	if assert.Len(t, samples, 2) {
+		assert.Equal(t, start.Add(time.Second).UnixNano()/int64(time.Millisecond), samples[0].Timestamp)
+		assert.Equal(t, start.Add(2*time.Second).UnixNano()/int64(time.Millisecond), samples[1].Timestamp)
+	}
+}
@@ -0,0 +1,221 @@
+package mediasoup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+/**
+ * EventRing keeps the last events published to it. It implements the
+ * Publisher interface of the eventbridge package, so the events of a Bridge can
+ * be attached to support bundles:
+ *
+ *	events := mediasoup.NewEventRing(1000)
+ *	eventbridge.New(events).WatchRouter(router)
+ */
+type EventRing struct {
+	locker sync.Mutex
+	events []json.RawMessage
+	next   int
+	full   bool
+}
+
+// NewEventRing keeps the last size events, default 1000.
+func NewEventRing(size int) *EventRing {
+	if size <= 0 {
+		size = 1000
+	}
+
+	return &EventRing{events: make([]json.RawMessage, size)}
+}
+
+// Publish stores a JSON serialized event, the topic is ignored.
+func (ring *EventRing) Publish(topic string, data []byte) error {
+	ring.locker.Lock()
+	defer ring.locker.Unlock()
+
+	ring.events[ring.next] = append(json.RawMessage(nil), data...)
+	ring.next = (ring.next + 1) % len(ring.events)
+
+	if ring.next == 0 {
+		ring.full = true
+	}
+
+	return nil
+}
+
+// Events returns the stored events, oldest first.
+func (ring *EventRing) Events() []json.RawMessage {
+	ring.locker.Lock()
+	defer ring.locker.Unlock()
+
+	if !ring.full {
+		return append([]json.RawMessage(nil), ring.events[:ring.next]...)
+	}
+
+	events := append([]json.RawMessage(nil), ring.events[ring.next:]...)
+
+	return append(events, ring.events[:ring.next]...)
+}
+
+type SupportBundleOptions struct {
+	// StatsRecorder to take the recent stats of the entities from, if any.
+	StatsRecorder *StatsRecorder
+	// StatsSince is how far back stats are taken, default 5 minutes.
+	StatsSince time.Duration
+	// Events to attach, e.g. fed by an eventbridge.Bridge.
+	Events *EventRing
+}
+
+// SupportBundle is the content of the archive of CollectSupportBundle().
+type SupportBundle struct {
+	// CreatedAt in milliseconds.
+	CreatedAt     int64  `json:"createdAt"`
+	WorkerVersion string `json:"workerVersion,omitempty"`
+	RouterId      string `json:"routerId"`
+	// Dumps of the Router and its Transports, Producers and Consumers by id.
+	Dumps map[string]json.RawMessage `json:"dumps"`
+	// Stats recorded by the StatsRecorder by entity id.
+	Stats map[string][]StatsSample `json:"stats,omitempty"`
+	// Events of the EventRing, oldest first.
+	Events []json.RawMessage `json:"events,omitempty"`
+	// CapabilityHashes are the SHA-256 of the canonical Router RTP
+	// capabilities ("router") and Producer RTP parameters (by id), to compare
+	// them between bundles without reading them.
+	CapabilityHashes map[string]string `json:"capabilityHashes"`
+	// Errors met while collecting, the bundle holds whatever could be
+	// collected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+/**
+ * Collect the dumps, recent stats and events of the Router and its entities,
+ * the worker version and the capability hashes into a gzipped JSON archive to
+ * attach to bug reports. See ReadSupportBundle().
+ *
+ * @throws {InvalidStateError} if the Router is closed.
+ */
+func CollectSupportBundle(router *Router, options ...SupportBundleOptions) (archive []byte, err error) {
+	router.logger.Debug("collectSupportBundle()")
+
+	if router.Closed() {
+		err = NewInvalidStateError("Router closed")
+		return
+	}
+
+	var opts SupportBundleOptions
+
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.StatsSince == 0 {
+		opts.StatsSince = 5 * time.Minute
+	}
+
+	bundle := SupportBundle{
+		CreatedAt:        time.Now().UnixNano() / int64(time.Millisecond),
+		WorkerVersion:    router.data.WorkerVersion,
+		RouterId:         router.Id(),
+		Dumps:            make(map[string]json.RawMessage),
+		Stats:            make(map[string][]StatsSample),
+		CapabilityHashes: make(map[string]string),
+	}
+
+	collect := func(id string, getDump func() Response) {
+		rsp := getDump()
+
+		if err := rsp.Err(); err != nil {
+			bundle.Errors = append(bundle.Errors, fmt.Sprintf("dump of %s failed: %s", id, err))
+		} else {
+			bundle.Dumps[id] = rsp.Data()
+		}
+
+		if opts.StatsRecorder == nil {
+			return
+		}
+
+		samples, err := opts.StatsRecorder.Read(id, time.Now().Add(-opts.StatsSince))
+		if err != nil {
+			bundle.Errors = append(bundle.Errors, fmt.Sprintf("stats of %s failed: %s", id, err))
+		} else if len(samples) > 0 {
+			bundle.Stats[id] = samples
+		}
+	}
+
+	collect(router.Id(), router.Dump)
+
+	for _, transport := range router.Transports() {
+		collect(transport.Id(), transport.Dump)
+
+		for _, consumer := range transport.consumerList() {
+			collect(consumer.Id(), consumer.Dump)
+		}
+	}
+
+	for _, producer := range router.Producers() {
+		collect(producer.Id(), producer.Dump)
+
+		if data, err := producer.RtpParameters().CanonicalJSON(); err == nil {
+			bundle.CapabilityHashes[producer.Id()] = sha256Hex(data)
+		}
+	}
+
+	data, err := router.RtpCapabilities().CanonicalJSON()
+	if err != nil {
+		return
+	}
+	bundle.CapabilityHashes["router"] = sha256Hex(data)
+
+	if opts.Events != nil {
+		bundle.Events = opts.Events.Events()
+	}
+
+	data, err = json.Marshal(bundle)
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err = w.Write(data); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ReadSupportBundle decodes an archive of CollectSupportBundle().
+func ReadSupportBundle(archive []byte) (bundle SupportBundle, err error) {
+	r, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &bundle)
+
+	return
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
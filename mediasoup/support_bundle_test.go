@@ -0,0 +1,63 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRing(t *testing.T) {
+	ring := NewEventRing(2)
+	assert.Empty(t, ring.Events())
+
+	for i := 1; i <= 3; i++ {
+		ring.Publish("topic", []byte(fmt.Sprintf(`{"n":%d}`, i)))
+	}
+
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"n":2}`), json.RawMessage(`{"n":3}`)}, ring.Events())
+}
+
+func TestCollectSupportBundle(t *testing.T) {
+	channel := newTestChannel()
+	caps, err := GenerateRouterRtpCapabilities([]RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	assert.NoError(t, err)
+
+	router := NewRouter(internalData{RouterId: "r1"}, routerData{
+		RtpCapabilities: caps,
+		WorkerVersion:   "3.9.0",
+	}, channel)
+
+	transport := NewPipeTransport(PipeTransportData{}, createTransportParams{
+		Internal: internalData{RouterId: "r1", TransportId: "t1"},
+		Channel:  channel,
+	})
+	router.transports["t1"] = transport
+	router.producers["p1"] = NewProducer(internalData{ProducerId: "p1"},
+		producerData{Kind: MediaKindAudio}, channel, nil, false)
+
+	events := NewEventRing(10)
+	events.Publish("mediasoup.router.created", []byte(`{"entity":"router"}`))
+
+	archive, err := CollectSupportBundle(router, SupportBundleOptions{Events: events})
+	assert.NoError(t, err)
+
+	bundle, err := ReadSupportBundle(archive)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.9.0", bundle.WorkerVersion)
+	assert.Equal(t, "r1", bundle.RouterId)
+	assert.Len(t, bundle.Dumps, 3)
+	assert.JSONEq(t, `{"method":"transport.dump"}`, string(bundle.Dumps["t1"]))
+	assert.Len(t, bundle.CapabilityHashes["router"], 64)
+	assert.Len(t, bundle.CapabilityHashes["p1"], 64)
+	assert.Len(t, bundle.Events, 1)
+	assert.Empty(t, bundle.Errors)
+
+	router.closed = true
+
+	_, err = CollectSupportBundle(router)
+	assert.IsType(t, NewInvalidStateError(""), err)
+}
@@ -0,0 +1,323 @@
+package mediasoup
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+	"github.com/sirupsen/logrus"
+)
+
+type TalkTimeTrackerOptions struct {
+	// PeerId returns the peer of a Producer, default the "peerId" string of
+	// its appData, or its id.
+	PeerId func(producer *Producer) string
+	// MaxHistory is the number of speaker turns kept, default 1000.
+	MaxHistory int
+	// MaxGap is the longest time accounted between two "volumes" events, so
+	// a paused observer does not count as speaking, default 2s.
+	MaxGap time.Duration
+	// Clock of the turns, default clock.System.
+	Clock clock.Clock
+}
+
+// TalkStats are the speaking statistics of a peer.
+type TalkStats struct {
+	PeerId       string        `json:"peerId"`
+	SpeakingTime time.Duration `json:"speakingTime"`
+	// Turns is the number of times the peer started to speak.
+	Turns int `json:"turns"`
+	// Interruptions is the number of times the peer started to speak while
+	// someone else was speaking.
+	Interruptions int `json:"interruptions"`
+	// Interrupted is the number of times someone started to speak while the
+	// peer was speaking.
+	Interrupted int       `json:"interrupted"`
+	LastSpokeAt time.Time `json:"lastSpokeAt"`
+}
+
+// SpeakerTurn is an uninterrupted period of speech of a peer.
+type SpeakerTurn struct {
+	PeerId  string    `json:"peerId"`
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+	// Peak volume of the turn, in dBvo.
+	PeakVolume int8 `json:"peakVolume"`
+	// Interrupting is set if the turn started while other peers were
+	// speaking.
+	Interrupting []string `json:"interrupting,omitempty"`
+}
+
+func (turn SpeakerTurn) Duration() time.Duration {
+	return turn.EndAt.Sub(turn.StartAt)
+}
+
+// TalkInterruption is the parameter of the "interruption" event.
+type TalkInterruption struct {
+	PeerId string `json:"peerId"`
+	// InterruptedPeerIds are the peers speaking when PeerId started to.
+	InterruptedPeerIds []string  `json:"interruptedPeerIds"`
+	At                 time.Time `json:"at"`
+}
+
+/**
+ * TalkTimeTracker accumulates, from the "volumes" and "silence" events of one
+ * or more AudioLevelObservers, the speaking time of every peer of a room, who
+ * interrupted whom, and a bounded history of the speaker turns. A peer is
+ * speaking while it is listed in the "volumes" events, so MaxEntries of the
+ * AudioLevelObserver should be above the number of concurrent speakers to
+ * detect.
+ *
+ * @emits {SpeakerTurn} speakerturn - a turn ended
+ * @emits {TalkInterruption} interruption
+ */
+type TalkTimeTracker struct {
+	EventEmitter
+	locker     sync.Mutex
+	logger     logrus.FieldLogger
+	options    TalkTimeTrackerOptions
+	stats      map[string]*TalkStats
+	speaking   map[string]*SpeakerTurn
+	lastUpdate time.Time
+	history    []SpeakerTurn
+}
+
+func NewTalkTimeTracker(options TalkTimeTrackerOptions) *TalkTimeTracker {
+	logger := TypeLogger("TalkTimeTracker")
+
+	if options.PeerId == nil {
+		options.PeerId = defaultTalkPeerId
+	}
+	if options.MaxHistory <= 0 {
+		options.MaxHistory = 1000
+	}
+	if options.MaxGap <= 0 {
+		options.MaxGap = 2 * time.Second
+	}
+	options.Clock = clock.OrSystem(options.Clock)
+
+	return &TalkTimeTracker{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		options:      options,
+		stats:        make(map[string]*TalkStats),
+		speaking:     make(map[string]*SpeakerTurn),
+	}
+}
+
+func defaultTalkPeerId(producer *Producer) string {
+	var peerId interface{}
+
+	switch appData := producer.AppData().(type) {
+	case H:
+		peerId = appData["peerId"]
+	case map[string]interface{}:
+		peerId = appData["peerId"]
+	}
+
+	if id, ok := peerId.(string); ok && len(id) > 0 {
+		return id
+	}
+
+	return producer.Id()
+}
+
+// Attach feeds the tracker with the events of an AudioLevelObserver. The
+// ongoing turns end when it closes.
+func (tracker *TalkTimeTracker) Attach(observer RtpObserver) {
+	observer.On("volumes", tracker.HandleVolumes)
+	observer.On("silence", tracker.HandleSilence)
+	observer.On("@close", tracker.HandleSilence)
+	observer.On("routerclose", tracker.HandleSilence)
+}
+
+// HandleVolumes accounts a "volumes" event of an AudioLevelObserver.
+func (tracker *TalkTimeTracker) HandleVolumes(volumes []VolumeInfo) {
+	peakVolumes := make(map[string]int8, len(volumes))
+
+	for _, volume := range volumes {
+		peerId := tracker.options.PeerId(volume.Producer)
+
+		if peak, ok := peakVolumes[peerId]; !ok || volume.Volume > peak {
+			peakVolumes[peerId] = volume.Volume
+		}
+	}
+
+	tracker.update(peakVolumes)
+}
+
+// HandleSilence accounts a "silence" event of an AudioLevelObserver, ending
+// the ongoing turns.
+func (tracker *TalkTimeTracker) HandleSilence() {
+	tracker.update(nil)
+}
+
+func (tracker *TalkTimeTracker) update(peakVolumes map[string]int8) {
+	var endedTurns []SpeakerTurn
+	var interruptions []TalkInterruption
+
+	tracker.locker.Lock()
+
+	now := tracker.options.Clock.Now()
+	elapsed := now.Sub(tracker.lastUpdate)
+	if elapsed > tracker.options.MaxGap {
+		elapsed = tracker.options.MaxGap
+	}
+	tracker.lastUpdate = now
+
+	// Account the time since the last event to the peers speaking then.
+	for peerId, turn := range tracker.speaking {
+		stats := tracker.stats[peerId]
+		stats.SpeakingTime += elapsed
+		stats.LastSpokeAt = now
+		turn.EndAt = now
+
+		if _, ok := peakVolumes[peerId]; !ok {
+			delete(tracker.speaking, peerId)
+			endedTurns = append(endedTurns, *turn)
+		}
+	}
+
+	// Peers still speaking, interrupted by the ones starting.
+	speakingPeerIds := make([]string, 0, len(tracker.speaking))
+	for peerId := range tracker.speaking {
+		speakingPeerIds = append(speakingPeerIds, peerId)
+	}
+	sort.Strings(speakingPeerIds)
+
+	startingPeerIds := []string{}
+	for peerId, volume := range peakVolumes {
+		if turn, ok := tracker.speaking[peerId]; ok {
+			if volume > turn.PeakVolume {
+				turn.PeakVolume = volume
+			}
+			continue
+		}
+		startingPeerIds = append(startingPeerIds, peerId)
+	}
+	sort.Strings(startingPeerIds)
+
+	for _, peerId := range startingPeerIds {
+		stats, ok := tracker.stats[peerId]
+		if !ok {
+			stats = &TalkStats{PeerId: peerId}
+			tracker.stats[peerId] = stats
+		}
+		stats.Turns++
+		stats.LastSpokeAt = now
+
+		turn := &SpeakerTurn{
+			PeerId:     peerId,
+			StartAt:    now,
+			EndAt:      now,
+			PeakVolume: peakVolumes[peerId],
+		}
+
+		if len(speakingPeerIds) > 0 {
+			turn.Interrupting = speakingPeerIds
+			stats.Interruptions++
+
+			for _, interruptedPeerId := range speakingPeerIds {
+				tracker.stats[interruptedPeerId].Interrupted++
+			}
+
+			interruptions = append(interruptions, TalkInterruption{
+				PeerId:             peerId,
+				InterruptedPeerIds: speakingPeerIds,
+				At:                 now,
+			})
+		}
+
+		tracker.speaking[peerId] = turn
+	}
+
+	sort.Slice(endedTurns, func(i, j int) bool {
+		return endedTurns[i].PeerId < endedTurns[j].PeerId
+	})
+
+	tracker.history = append(tracker.history, endedTurns...)
+	if overflow := len(tracker.history) - tracker.options.MaxHistory; overflow > 0 {
+		tracker.history = append([]SpeakerTurn(nil), tracker.history[overflow:]...)
+	}
+
+	tracker.locker.Unlock()
+
+	for _, turn := range endedTurns {
+		tracker.SafeEmit("speakerturn", turn)
+	}
+	for _, interruption := range interruptions {
+		tracker.SafeEmit("interruption", interruption)
+	}
+}
+
+// Stats of a peer, its ongoing turn being accounted up to the last event.
+func (tracker *TalkTimeTracker) Stats(peerId string) (stats TalkStats, ok bool) {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	if s, ok := tracker.stats[peerId]; ok {
+		return *s, true
+	}
+
+	return
+}
+
+// AllStats returns the stats of all the peers, by descending speaking time.
+func (tracker *TalkTimeTracker) AllStats() []TalkStats {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	allStats := make([]TalkStats, 0, len(tracker.stats))
+
+	for _, stats := range tracker.stats {
+		allStats = append(allStats, *stats)
+	}
+
+	sort.Slice(allStats, func(i, j int) bool {
+		if allStats[i].SpeakingTime != allStats[j].SpeakingTime {
+			return allStats[i].SpeakingTime > allStats[j].SpeakingTime
+		}
+		return allStats[i].PeerId < allStats[j].PeerId
+	})
+
+	return allStats
+}
+
+// ActiveSpeakers returns the peers currently speaking, sorted.
+func (tracker *TalkTimeTracker) ActiveSpeakers() []string {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	peerIds := make([]string, 0, len(tracker.speaking))
+
+	for peerId := range tracker.speaking {
+		peerIds = append(peerIds, peerId)
+	}
+	sort.Strings(peerIds)
+
+	return peerIds
+}
+
+// History returns the ended turns which ended after since, oldest first.
+// Ongoing turns are not part of it.
+func (tracker *TalkTimeTracker) History(since time.Time) []SpeakerTurn {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	i := sort.Search(len(tracker.history), func(i int) bool {
+		return tracker.history[i].EndAt.After(since)
+	})
+
+	return append([]SpeakerTurn{}, tracker.history[i:]...)
+}
+
+// Reset forgets the stats and the history, e.g. when a meeting restarts.
+func (tracker *TalkTimeTracker) Reset() {
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+
+	tracker.stats = make(map[string]*TalkStats)
+	tracker.speaking = make(map[string]*SpeakerTurn)
+	tracker.history = nil
+}
@@ -0,0 +1,93 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTalkTimeTracker(t *testing.T) {
+	fakeClock := testutil.NewFakeClock(time.Unix(1000, 0))
+	start := fakeClock.Now()
+
+	alice := &Producer{internal: internalData{ProducerId: "p1"}, appData: H{"peerId": "alice"}}
+	bob := &Producer{internal: internalData{ProducerId: "p2"}, appData: H{"peerId": "bob"}}
+	carol := &Producer{internal: internalData{ProducerId: "p3"}, appData: H{}}
+
+	observer := NewAudioLevelObserver(internalData{RtpObserverId: "o1"}, newTestChannel(),
+		func(producerId string) *Producer { return nil })
+
+	tracker := NewTalkTimeTracker(TalkTimeTrackerOptions{Clock: fakeClock, MaxHistory: 2})
+	tracker.Attach(observer)
+
+	var turns []SpeakerTurn
+	var interruptions []TalkInterruption
+	tracker.On("speakerturn", func(turn SpeakerTurn) { turns = append(turns, turn) })
+	tracker.On("interruption", func(interruption TalkInterruption) {
+		interruptions = append(interruptions, interruption)
+	})
+
+	volumes := func(infos ...VolumeInfo) {
+		observer.SafeEmit("volumes", infos)
+		fakeClock.Advance(time.Second)
+	}
+
+	volumes(VolumeInfo{Producer: alice, Volume: -40})
+	volumes(VolumeInfo{Producer: alice, Volume: -30})
+	// Bob interrupts alice.
+	volumes(VolumeInfo{Producer: alice, Volume: -35}, VolumeInfo{Producer: bob, Volume: -50})
+	volumes(VolumeInfo{Producer: bob, Volume: -50})
+
+	assert.Equal(t, []string{"bob"}, tracker.ActiveSpeakers())
+	assert.Equal(t, []TalkInterruption{
+		{PeerId: "bob", InterruptedPeerIds: []string{"alice"}, At: start.Add(2 * time.Second)},
+	}, interruptions)
+	assert.Equal(t, []SpeakerTurn{
+		{PeerId: "alice", StartAt: start, EndAt: start.Add(3 * time.Second), PeakVolume: -30},
+	}, turns)
+
+	observer.SafeEmit("silence")
+	fakeClock.Advance(time.Second)
+	volumes(VolumeInfo{Producer: carol, Volume: -20})
+	// Paused observer, only MaxGap is accounted.
+	fakeClock.Advance(time.Minute)
+	observer.SafeEmit("silence")
+
+	assert.Empty(t, tracker.ActiveSpeakers())
+	assert.Len(t, turns, 3)
+	assert.Equal(t, []string{"alice"}, turns[1].Interrupting)
+	assert.Equal(t, 2*time.Second, turns[1].Duration())
+
+	aliceStats, ok := tracker.Stats("alice")
+	assert.True(t, ok)
+	assert.Equal(t, TalkStats{
+		PeerId:       "alice",
+		SpeakingTime: 3 * time.Second,
+		Turns:        1,
+		Interrupted:  1,
+		LastSpokeAt:  start.Add(3 * time.Second),
+	}, aliceStats)
+
+	_, ok = tracker.Stats("dave")
+	assert.False(t, ok)
+
+	allStats := tracker.AllStats()
+	assert.Len(t, allStats, 3)
+	assert.Equal(t, "alice", allStats[0].PeerId)
+	assert.Equal(t, "bob", allStats[1].PeerId)
+	assert.Equal(t, 1, allStats[1].Interruptions)
+	// Producer id without a peerId in the appData.
+	assert.Equal(t, "p3", allStats[2].PeerId)
+	assert.Equal(t, 2*time.Second, allStats[2].SpeakingTime)
+
+	// Bounded history.
+	history := tracker.History(time.Time{})
+	assert.Equal(t, turns[1:], history)
+	assert.Equal(t, turns[2:], tracker.History(start.Add(4*time.Second)))
+
+	tracker.Reset()
+	assert.Empty(t, tracker.AllStats())
+	assert.Empty(t, tracker.History(time.Time{}))
+}
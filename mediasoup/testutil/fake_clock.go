@@ -0,0 +1,171 @@
+// Package testutil contains helpers for deterministic tests of time based
+// subsystems.
+package testutil
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+)
+
+/**
+ * FakeClock is a clock.Clock whose time only moves when Advance() is called.
+ * Tickers, timers and sleepers due within the advanced duration fire in order,
+ * each at its own deadline. As with the time package, a ticker whose channel
+ * is not drained drops ticks.
+ */
+type FakeClock struct {
+	locker sync.Mutex
+	// Signalled when a waiter is added.
+	added   *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration // 0 for timers and sleepers.
+	ch       chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.added = sync.NewCond(&c.locker)
+
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.addWaiter(d, d)}
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	return c.addWaiter(d, 0)
+}
+
+// Sleep blocks until the clock is advanced by d.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.addWaiter(d, 0).ch
+}
+
+// Waiters returns the number of pending tickers, timers and sleepers, useful
+// to wait for a goroutine to reach a Sleep() or to create its ticker before
+// advancing the clock.
+func (c *FakeClock) Waiters() int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return len(c.waiters)
+}
+
+// BlockUntil waits for the number of pending waiters to reach n.
+func (c *FakeClock) BlockUntil(n int) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	for len(c.waiters) < n {
+		c.added.Wait()
+	}
+}
+
+// Advance moves the clock forward, firing every waiter due meanwhile.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	end := c.now.Add(d)
+
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(end) {
+			break
+		}
+
+		w := c.waiters[0]
+		c.now = w.deadline
+
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+
+	c.now = end
+}
+
+func (c *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	w := &fakeWaiter{
+		clock:    c,
+		deadline: c.now.Add(d),
+		period:   period,
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		w.ch <- c.now
+		if period == 0 {
+			return w
+		}
+	}
+
+	c.waiters = append(c.waiters, w)
+	c.added.Broadcast()
+
+	return w
+}
+
+func (c *FakeClock) removeWaiter(w *fakeWaiter) bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() bool {
+	return w.clock.removeWaiter(w)
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
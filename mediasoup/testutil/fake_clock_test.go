@@ -0,0 +1,65 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock_Ticker(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewFakeClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(999 * time.Millisecond)
+
+	select {
+	case <-ticker.C():
+		t.Fatal("ticked too early")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+
+	// Undrained ticks are dropped.
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+
+	ticker.Stop()
+	clock.Advance(time.Second)
+
+	select {
+	case <-ticker.C():
+		t.Fatal("ticked after Stop()")
+	default:
+	}
+	assert.Equal(t, 0, clock.Waiters())
+}
+
+func TestFakeClock_TimerAndSleep(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	timer := clock.NewTimer(time.Second)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+
+	timer = clock.NewTimer(time.Second)
+	clock.Advance(time.Second)
+	<-timer.C()
+	assert.False(t, timer.Stop())
+
+	doneCh := make(chan struct{})
+
+	go func() {
+		clock.Sleep(time.Minute)
+		close(doneCh)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-doneCh
+
+	assert.Equal(t, time.Unix(61, 0), clock.Now())
+}
@@ -0,0 +1,349 @@
+package mediasoup
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// TimedMetadataUri is the custom RTP header extension carrying application
+// timed metadata (e.g. SCTE-like cues or subtitle timing).
+const TimedMetadataUri = "urn:mediasoup:params:rtp-hdrext:timed-metadata"
+
+func init() {
+	RegisterHeaderExtensionUri("timed-metadata", TimedMetadataUri)
+}
+
+// Kinds of TimedMetadata, applications may define their own from 128 on.
+const (
+	TimedMetadataCue      uint8 = 1
+	TimedMetadataSubtitle uint8 = 2
+)
+
+// Maximum length of a RTP header extension element (two-byte header).
+const maxRtpHeaderExtensionLength = 255
+
+// TimedMetadata is carried as one kind byte followed by the payload.
+type TimedMetadata struct {
+	Kind    uint8
+	Payload []byte
+}
+
+func (metadata TimedMetadata) Marshal() ([]byte, error) {
+	if len(metadata.Payload)+1 > maxRtpHeaderExtensionLength {
+		return nil, NewTypeError("timed metadata too long [length:%d]", len(metadata.Payload))
+	}
+
+	return append([]byte{metadata.Kind}, metadata.Payload...), nil
+}
+
+func ParseTimedMetadata(data []byte) (metadata TimedMetadata, err error) {
+	if len(data) == 0 {
+		err = NewTypeError("empty timed metadata")
+		return
+	}
+
+	metadata.Kind = data[0]
+	metadata.Payload = append([]byte{}, data[1:]...)
+
+	return
+}
+
+/**
+ * Add the timed metadata header extension to the RTP parameters of a Consumer
+ * if the consuming endpoint announces it in its RTP capabilities, so it can
+ * be signaled to the endpoint.
+ *
+ * @returns {RTCRtpParameters, bool} whether it was negotiated.
+ */
+func AddTimedMetadataHeaderExtension(params RtpParameters, caps RtpCapabilities) (RtpParameters, bool) {
+	var kind MediaKind
+
+	if len(params.Codecs) > 0 {
+		kind = MediaKind(ParseMimeType(params.Codecs[0].MimeType).Kind())
+	}
+
+	for _, ext := range params.HeaderExtensions {
+		if CanonicalHeaderExtensionUri(ext.Uri) == TimedMetadataUri {
+			return params, true
+		}
+	}
+
+	for _, capExt := range caps.HeaderExtensions {
+		if CanonicalHeaderExtensionUri(capExt.Uri) != TimedMetadataUri ||
+			(len(capExt.Kind) > 0 && capExt.Kind != kind) {
+			continue
+		}
+
+		headerExtensions := make([]RtpHeaderExtension, 0, len(params.HeaderExtensions)+1)
+		headerExtensions = append(headerExtensions, params.HeaderExtensions...)
+		headerExtensions = append(headerExtensions, RtpHeaderExtension{
+			Uri: TimedMetadataUri,
+			Id:  capExt.PreferredId,
+		})
+
+		params.HeaderExtensions = headerExtensions
+
+		return params, true
+	}
+
+	return params, false
+}
+
+/**
+ * TimedMetadataInjector attaches queued timed metadata to outgoing RTP
+ * packets, one metadata per packet, as the header extension with the
+ * negotiated id. It is meant to be called from the code relaying the RTP of a
+ * Consumer to the endpoint, since this version has no DirectTransport and the
+ * worker does not write unknown header extensions itself.
+ */
+type TimedMetadataInjector struct {
+	locker  sync.Mutex
+	id      uint8
+	pending [][]byte
+}
+
+// NewTimedMetadataInjector creates an injector writing the extension with the
+// given id, see AddTimedMetadataHeaderExtension().
+func NewTimedMetadataInjector(id int) (*TimedMetadataInjector, error) {
+	if id < 1 || id > 255 {
+		return nil, NewTypeError("invalid header extension id [id:%d]", id)
+	}
+
+	return &TimedMetadataInjector{id: uint8(id)}, nil
+}
+
+// Push queues the metadata for the next packet.
+func (injector *TimedMetadataInjector) Push(metadata TimedMetadata) error {
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+
+	injector.locker.Lock()
+	defer injector.locker.Unlock()
+
+	injector.pending = append(injector.pending, data)
+
+	return nil
+}
+
+// Pending returns the number of queued metadata.
+func (injector *TimedMetadataInjector) Pending() int {
+	injector.locker.Lock()
+	defer injector.locker.Unlock()
+
+	return len(injector.pending)
+}
+
+// Process returns the packet with the next queued metadata, or the packet
+// itself if none is queued. The metadata is kept queued if the packet is not
+// valid RTP.
+func (injector *TimedMetadataInjector) Process(packet []byte) ([]byte, error) {
+	injector.locker.Lock()
+	defer injector.locker.Unlock()
+
+	if len(injector.pending) == 0 {
+		return packet, nil
+	}
+
+	result, err := SetRtpHeaderExtension(packet, injector.id, injector.pending[0])
+	if err != nil {
+		return packet, err
+	}
+
+	injector.pending = injector.pending[1:]
+
+	return result, nil
+}
+
+type rtpHeaderExtensionElement struct {
+	id   uint8
+	data []byte
+}
+
+type rtpPacketLayout struct {
+	// Length of the fixed header and CSRCs.
+	headerLength int
+	// End of the header extension, equal to headerLength if none.
+	extensionEnd int
+	elements     []rtpHeaderExtensionElement
+}
+
+// GetRtpHeaderExtension returns the value of the header extension with the
+// given id of a RTP packet.
+func GetRtpHeaderExtension(packet []byte, id uint8) ([]byte, bool) {
+	layout, err := parseRtpPacketLayout(packet)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, element := range layout.elements {
+		if element.id == id {
+			return element.data, true
+		}
+	}
+
+	return nil, false
+}
+
+// SetRtpHeaderExtension returns a copy of the RTP packet with the header
+// extension with the given id set to value (RFC 8285). The one-byte header
+// form is kept when possible, the two-byte form is used otherwise.
+func SetRtpHeaderExtension(packet []byte, id uint8, value []byte) ([]byte, error) {
+	if id == 0 {
+		return nil, NewTypeError("invalid header extension id [id:%d]", id)
+	}
+	if len(value) > maxRtpHeaderExtensionLength {
+		return nil, NewTypeError("header extension too long [length:%d]", len(value))
+	}
+
+	layout, err := parseRtpPacketLayout(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		elements = make([]rtpHeaderExtensionElement, 0, len(layout.elements)+1)
+		replaced bool
+	)
+
+	for _, element := range layout.elements {
+		if element.id == id {
+			element.data, replaced = value, true
+		}
+		elements = append(elements, element)
+	}
+
+	if !replaced {
+		elements = append(elements, rtpHeaderExtensionElement{id: id, data: value})
+	}
+
+	oneByte := true
+
+	for _, element := range elements {
+		if element.id > 14 || len(element.data) == 0 || len(element.data) > 16 {
+			oneByte = false
+		}
+	}
+
+	var extension []byte
+
+	for _, element := range elements {
+		if oneByte {
+			extension = append(extension, element.id<<4|uint8(len(element.data)-1))
+		} else {
+			extension = append(extension, element.id, uint8(len(element.data)))
+		}
+		extension = append(extension, element.data...)
+	}
+
+	for len(extension)%4 != 0 {
+		extension = append(extension, 0)
+	}
+
+	result := make([]byte, 0, layout.headerLength+4+len(extension)+len(packet)-layout.extensionEnd)
+	result = append(result, packet[:layout.headerLength]...)
+	result[0] |= 0x10
+
+	profile := uint16(0xbede)
+	if !oneByte {
+		profile = 0x1000
+	}
+
+	extensionHeader := make([]byte, 4)
+	binary.BigEndian.PutUint16(extensionHeader[0:2], profile)
+	binary.BigEndian.PutUint16(extensionHeader[2:4], uint16(len(extension)/4))
+
+	result = append(result, extensionHeader...)
+	result = append(result, extension...)
+	result = append(result, packet[layout.extensionEnd:]...)
+
+	return result, nil
+}
+
+func parseRtpPacketLayout(packet []byte) (layout rtpPacketLayout, err error) {
+	if len(packet) < 12 || packet[0]>>6 != 2 {
+		err = NewTypeError("invalid RTP packet")
+		return
+	}
+
+	layout.headerLength = 12 + 4*int(packet[0]&0x0f)
+	layout.extensionEnd = layout.headerLength
+
+	if len(packet) < layout.headerLength {
+		err = NewTypeError("invalid RTP packet")
+		return
+	}
+
+	if packet[0]&0x10 == 0 {
+		return
+	}
+
+	if len(packet) < layout.headerLength+4 {
+		err = NewTypeError("invalid RTP header extension")
+		return
+	}
+
+	profile := binary.BigEndian.Uint16(packet[layout.headerLength:])
+	start := layout.headerLength + 4
+	layout.extensionEnd = start + 4*int(binary.BigEndian.Uint16(packet[layout.headerLength+2:]))
+
+	if len(packet) < layout.extensionEnd {
+		err = NewTypeError("invalid RTP header extension")
+		return
+	}
+
+	data := packet[start:layout.extensionEnd]
+
+	switch {
+	case profile == 0xbede:
+		for i := 0; i < len(data); {
+			id, length := data[i]>>4, int(data[i]&0x0f)+1
+
+			if id == 0 {
+				// Padding.
+				i++
+				continue
+			}
+			if id == 15 {
+				break
+			}
+			if i+1+length > len(data) {
+				err = NewTypeError("invalid RTP header extension")
+				return
+			}
+
+			layout.elements = append(layout.elements,
+				rtpHeaderExtensionElement{id: id, data: data[i+1 : i+1+length]})
+
+			i += 1 + length
+		}
+
+	case profile&0xfff0 == 0x1000:
+		for i := 0; i < len(data); {
+			id := data[i]
+
+			if id == 0 {
+				// Padding.
+				i++
+				continue
+			}
+			if i+2 > len(data) || i+2+int(data[i+1]) > len(data) {
+				err = NewTypeError("invalid RTP header extension")
+				return
+			}
+
+			length := int(data[i+1])
+
+			layout.elements = append(layout.elements,
+				rtpHeaderExtensionElement{id: id, data: data[i+2 : i+2+length]})
+
+			i += 2 + length
+		}
+
+	default:
+		// Not a RFC 8285 extension, it is replaced.
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package mediasoup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRtpPacket() []byte {
+	return []byte{
+		0x80, 0x60, 0x00, 0x01, // V=2, PT=96, seq=1
+		0x00, 0x00, 0x00, 0x10, // timestamp
+		0x11, 0x22, 0x33, 0x44, // ssrc
+		0xaa, 0xbb, 0xcc, // payload
+	}
+}
+
+func TestSetRtpHeaderExtension(t *testing.T) {
+	packet := newTestRtpPacket()
+
+	result, err := SetRtpHeaderExtension(packet, 3, []byte{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x90, 0x60}, result[:2])
+	assert.Equal(t, []byte{0xbe, 0xde, 0x00, 0x01, 0x31, 1, 2, 0}, result[12:20])
+	assert.Equal(t, []byte{0xaa, 0xbb, 0xcc}, result[20:])
+	assert.Equal(t, newTestRtpPacket(), packet)
+
+	// Replace and add.
+	result, err = SetRtpHeaderExtension(result, 3, []byte{9})
+	assert.NoError(t, err)
+	result, err = SetRtpHeaderExtension(result, 5, []byte{7})
+	assert.NoError(t, err)
+
+	value, ok := GetRtpHeaderExtension(result, 3)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{9}, value)
+	value, ok = GetRtpHeaderExtension(result, 5)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{7}, value)
+	assert.True(t, bytes.HasSuffix(result, []byte{0xaa, 0xbb, 0xcc}))
+
+	// Id above 14 switches to the two-byte form.
+	result, err = SetRtpHeaderExtension(result, 20, bytes.Repeat([]byte{1}, 20))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x10, 0x00}, result[12:14])
+
+	for id, length := range map[uint8]int{3: 1, 5: 1, 20: 20} {
+		value, ok = GetRtpHeaderExtension(result, id)
+		assert.True(t, ok)
+		assert.Len(t, value, length)
+	}
+	assert.True(t, bytes.HasSuffix(result, []byte{0xaa, 0xbb, 0xcc}))
+
+	_, ok = GetRtpHeaderExtension(result, 4)
+	assert.False(t, ok)
+
+	_, err = SetRtpHeaderExtension([]byte{0x80}, 3, []byte{1})
+	assert.IsType(t, NewTypeError(""), err)
+	_, err = SetRtpHeaderExtension(packet, 0, []byte{1})
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestTimedMetadataInjector(t *testing.T) {
+	injector, err := NewTimedMetadataInjector(7)
+	assert.NoError(t, err)
+
+	packet := newTestRtpPacket()
+
+	result, err := injector.Process(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, packet, result)
+
+	assert.NoError(t, injector.Push(TimedMetadata{Kind: TimedMetadataCue, Payload: []byte("splice")}))
+	assert.Equal(t, 1, injector.Pending())
+
+	result, err = injector.Process(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, injector.Pending())
+
+	value, ok := GetRtpHeaderExtension(result, 7)
+	assert.True(t, ok)
+
+	metadata, err := ParseTimedMetadata(value)
+	assert.NoError(t, err)
+	assert.Equal(t, TimedMetadata{Kind: TimedMetadataCue, Payload: []byte("splice")}, metadata)
+
+	assert.Error(t, injector.Push(TimedMetadata{Payload: make([]byte, 255)}))
+
+	_, err = NewTimedMetadataInjector(0)
+	assert.Error(t, err)
+}
+
+func TestAddTimedMetadataHeaderExtension(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []RtpCodecCapability{{MimeType: "video/VP8", ClockRate: 90000}},
+	}
+
+	_, ok := AddTimedMetadataHeaderExtension(params, RtpCapabilities{})
+	assert.False(t, ok)
+
+	result, ok := AddTimedMetadataHeaderExtension(params, RtpCapabilities{
+		HeaderExtensions: []RtpHeaderExtension{
+			{Kind: "audio", Uri: TimedMetadataUri, PreferredId: 14},
+			{Kind: "video", Uri: TimedMetadataUri, PreferredId: 15},
+		},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []RtpHeaderExtension{{Uri: TimedMetadataUri, Id: 15}}, result.HeaderExtensions)
+	assert.Empty(t, params.HeaderExtensions)
+	assert.Equal(t, "timed-metadata", HeaderExtensionName(TimedMetadataUri))
+}
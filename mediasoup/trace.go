@@ -0,0 +1,70 @@
+package mediasoup
+
+import (
+	"sort"
+	"strings"
+)
+
+// TraceIds are correlation ids of the application session, such as
+// TraceIds{"room": roomId, "peer": peerId}. They are included in the logs
+// and errors of the worker requests of the Router or Transport they are
+// given to, and of everything created from it.
+type TraceIds map[string]string
+
+// String returns the ids as "key:value" pairs sorted by key, as in the logs.
+func (ids TraceIds) String() string {
+	keys := make([]string, 0, len(ids))
+
+	for key := range ids {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		pairs = append(pairs, key+":"+ids[key])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// merge returns a copy of ids overridden by other, nil if both are empty.
+func (ids TraceIds) merge(other TraceIds) TraceIds {
+	if len(ids) == 0 && len(other) == 0 {
+		return nil
+	}
+
+	merged := make(TraceIds, len(ids)+len(other))
+
+	for key, value := range ids {
+		merged[key] = value
+	}
+	for key, value := range other {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// traceSuffix returns ", key:value..." to append to a log or error
+// "[...]" block of a request on behalf of internal, empty without trace ids.
+func traceSuffix(internal interface{}) string {
+	var ids TraceIds
+
+	switch internal := internal.(type) {
+	case internalData:
+		ids = internal.Trace
+	case *internalData:
+		if internal != nil {
+			ids = internal.Trace
+		}
+	}
+
+	if len(ids) == 0 {
+		return ""
+	}
+
+	return ", " + ids.String()
+}
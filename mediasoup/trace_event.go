@@ -0,0 +1,154 @@
+package mediasoup
+
+import "sync"
+
+// Trace event types of Producer.EnableTraceEvent() and
+// Consumer.EnableTraceEvent().
+const (
+	TraceEventTypeRtp      = "rtp"
+	TraceEventTypeKeyFrame = "keyframe"
+	TraceEventTypeNack     = "nack"
+	TraceEventTypePli      = "pli"
+	TraceEventTypeFir      = "fir"
+)
+
+func checkTraceEventTypes(types []string) error {
+	for _, typ := range types {
+		switch typ {
+		case TraceEventTypeRtp, TraceEventTypeKeyFrame, TraceEventTypeNack,
+			TraceEventTypePli, TraceEventTypeFir:
+		default:
+			return NewTypeError(`invalid trace event type "%s"`, typ)
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Enable "trace" events of the given types (TraceEventTypeRtp,
+ * TraceEventTypeKeyFrame...), replacing the previously enabled ones. No type
+ * disables them.
+ *
+ * @throws {TypeError} if a type is invalid.
+ */
+func (producer *Producer) EnableTraceEvent(types ...string) error {
+	producer.logger.Debug("enableTraceEvent()")
+
+	if err := checkTraceEventTypes(types); err != nil {
+		return err
+	}
+	if types == nil {
+		types = []string{}
+	}
+
+	response := producer.channel.Request("producer.enableTraceEvent", producer.internal, H{"types": types})
+
+	return response.Err()
+}
+
+/**
+ * Enable "trace" events of the given types (TraceEventTypeRtp,
+ * TraceEventTypeKeyFrame...), replacing the previously enabled ones. No type
+ * disables them.
+ *
+ * @throws {TypeError} if a type is invalid.
+ */
+func (consumer *Consumer) EnableTraceEvent(types ...string) error {
+	consumer.logger.Debug("enableTraceEvent()")
+
+	consumer.switchLocker.RLock()
+	defer consumer.switchLocker.RUnlock()
+
+	return consumer.enableTraceEvent(types...)
+}
+
+func (consumer *Consumer) enableTraceEvent(types ...string) error {
+	if err := checkTraceEventTypes(types); err != nil {
+		return err
+	}
+	if types == nil {
+		types = []string{}
+	}
+
+	response := consumer.channel.Request("consumer.enableTraceEvent", consumer.internal, H{"types": types})
+
+	if err := response.Err(); err != nil {
+		return err
+	}
+
+	// Enabled again by SwitchProducer().
+	consumer.traceEventTypes = append([]string{}, types...)
+
+	return nil
+}
+
+// OnTrace adds a listener of "trace".
+func (producer *Producer) OnTrace(listener func(trace TraceNotification)) (off func()) {
+	return OnEvent(producer.EventEmitter, "trace", listener)
+}
+
+// OnTrace adds a listener of "trace".
+func (consumer *Consumer) OnTrace(listener func(trace TraceNotification)) (off func()) {
+	return OnEvent(consumer.EventEmitter, "trace", listener)
+}
+
+// TraceStream returns the "trace" events as a channel, see traceStream().
+func (producer *Producer) TraceStream(size int) (events <-chan TraceNotification, stop func()) {
+	return traceStream(producer.EventEmitter, producer.observer, size)
+}
+
+// TraceStream returns the "trace" events as a channel, see traceStream().
+func (consumer *Consumer) TraceStream(size int) (events <-chan TraceNotification, stop func()) {
+	return traceStream(consumer.EventEmitter, consumer.observer, size)
+}
+
+// traceStream returns the "trace" events of the emitter as a channel with the
+// given buffer size, e.g. to log them from a goroutine. Events are dropped
+// while the buffer is full so notifications are never blocked. The channel is
+// closed by stop() or once the observer emits "close".
+func traceStream(emitter, observer EventEmitter, size int) (events <-chan TraceNotification, stop func()) {
+	ch := make(chan TraceNotification, size)
+
+	var (
+		locker             sync.Mutex
+		closed             bool
+		offTrace, offClose func()
+	)
+
+	stop = func() {
+		locker.Lock()
+		defer locker.Unlock()
+
+		if closed {
+			return
+		}
+		closed = true
+
+		offTrace()
+		offClose()
+		close(ch)
+	}
+
+	locker.Lock()
+	defer locker.Unlock()
+
+	offTrace = OnEvent(emitter, "trace", func(trace TraceNotification) {
+		locker.Lock()
+		defer locker.Unlock()
+
+		if closed {
+			return
+		}
+
+		select {
+		case ch <- trace:
+		default:
+		}
+	})
+	offClose = OnceSignal(observer, "close", func() {
+		go stop()
+	})
+
+	return ch, stop
+}
@@ -0,0 +1,67 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableTraceEvent(t *testing.T) {
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false)
+
+	assert.NoError(t, producer.EnableTraceEvent(TraceEventTypeKeyFrame, TraceEventTypePli))
+	assert.NoError(t, producer.EnableTraceEvent())
+	assert.IsType(t, NewTypeError(""), producer.EnableTraceEvent("foo"))
+
+	consumer := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false, false, nil)
+
+	assert.NoError(t, consumer.EnableTraceEvent(TraceEventTypeRtp))
+	assert.IsType(t, NewTypeError(""), consumer.EnableTraceEvent("foo"))
+}
+
+func TestTraceStream(t *testing.T) {
+	consumer := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false, false, nil)
+
+	var observed []TraceNotification
+	consumer.Observer().On("trace", func(trace TraceNotification) {
+		observed = append(observed, trace)
+	})
+
+	events, stop := consumer.TraceStream(1)
+	defer stop()
+
+	data := json.RawMessage(`{"type":"keyframe","timestamp":1234,"direction":"out","info":{"ssrc":1111}}`)
+
+	// The second one is dropped since the buffer is full.
+	consumer.channel.SafeEmit("c1", ConsumerNotificationTrace, data)
+	consumer.channel.SafeEmit("c1", ConsumerNotificationTrace, data)
+
+	trace := <-events
+	assert.Equal(t, TraceEventTypeKeyFrame, trace.Type)
+	assert.EqualValues(t, 1234, trace.Timestamp)
+	assert.Equal(t, "out", trace.Direction)
+	assert.Len(t, observed, 2)
+
+	select {
+	case <-events:
+		t.Fatal("unexpected trace event")
+	default:
+	}
+
+	stop()
+	_, ok := <-events
+	assert.False(t, ok)
+
+	producer := NewProducer(internalData{ProducerId: "p1"}, producerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false)
+
+	events, _ = producer.TraceStream(1)
+	producer.Close()
+
+	_, ok = <-events
+	assert.False(t, ok)
+}
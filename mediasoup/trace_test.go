@@ -0,0 +1,23 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceIds(t *testing.T) {
+	ids := TraceIds{"room": "r1"}
+
+	assert.Equal(t, "room:r1", ids.String())
+	assert.Equal(t, "", TraceIds(nil).String())
+
+	merged := ids.merge(TraceIds{"peer": "p1"})
+	assert.Equal(t, "peer:p1, room:r1", merged.String())
+	assert.Equal(t, TraceIds{"room": "r1"}, ids)
+	assert.Nil(t, TraceIds(nil).merge(nil))
+
+	assert.Equal(t, ", peer:p1, room:r1", traceSuffix(internalData{Trace: merged}))
+	assert.Equal(t, "", traceSuffix(internalData{RouterId: "router-1"}))
+	assert.Equal(t, "", traceSuffix(nil))
+}
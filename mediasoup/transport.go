@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
 
-	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,6 +24,13 @@ type Transport interface {
 	Connect(transportConnectParams) error
 	Produce(transportProduceParams) (*Producer, error)
 	Consume(transportConsumeParams) (*Consumer, error)
+	RevalidateConsumers(RevalidateConsumersParams) ([]ConsumerRevalidation, error)
+	SetRtxEnabled(enabled bool)
+	RtxEnabled() bool
+	CloseAllConsumers() BulkCloseResult
+	consumerList() []*Consumer
+	prepareClose() *channelRequest
+	finishClose()
 }
 
 type baseTransport struct {
@@ -35,16 +42,29 @@ type baseTransport struct {
 	closed                   bool
 	getRouterRtpCapabilities fetchRouterRtpCapabilitiesFunc
 	getProducerById          fetchProducerFunc
-	producers                map[string]*Producer
-	consumers                map[string]*Consumer
-	cnameForProducers        string
-	observer                 EventEmitter
+	generateMappedSsrc       generateSsrcFunc
+	releaseMappedSsrc        func(ssrc uint32)
+	isRouterClosing          func() bool
+	featureFlags             FeatureFlags
+	headerExtensionMode      HeaderExtensionMode
+	consumeTokenValidator    ConsumeTokenValidator
+	protectedConsumePolicy   ProtectedConsumePolicy
+	idGenerator              IdGenerator
+	// Guards producers and consumers, also read by other goroutines (e.g.
+	// WatchLeaks()).
+	entitiesLocker    sync.Mutex
+	producers         map[string]*Producer
+	consumers         map[string]*Consumer
+	cnameForProducers string
+	rtxDisabled       bool
+	observer          EventEmitter
 }
 
 /**
  * new transport
  *
  * @emits routerclose
+ * @emits {ProtectedConsumeAudit} protectedconsume
  * @emits @close
  * @emits @newproducer
  * @emits @producerclose
@@ -54,6 +74,13 @@ func newTransport(params createTransportParams) *baseTransport {
 
 	logger.Debug("constructor()")
 
+	if params.GenerateMappedSsrc == nil {
+		params.GenerateMappedSsrc = generateSsrcFunc(NewSequentialSsrcAllocator(generateRandomNumber()))
+	}
+	if params.ReleaseMappedSsrc == nil {
+		params.ReleaseMappedSsrc = func(ssrc uint32) {}
+	}
+
 	transport := &baseTransport{
 		EventEmitter: NewEventEmitter(logger),
 		logger:       logger,
@@ -64,6 +91,14 @@ func newTransport(params createTransportParams) *baseTransport {
 		appData:                  params.AppData,
 		getRouterRtpCapabilities: params.GetRouterRtpCapabilities,
 		getProducerById:          params.GetProducerById,
+		generateMappedSsrc:       params.GenerateMappedSsrc,
+		releaseMappedSsrc:        params.ReleaseMappedSsrc,
+		isRouterClosing:          params.IsRouterClosing,
+		featureFlags:             params.FeatureFlags,
+		headerExtensionMode:      params.HeaderExtensionMode,
+		consumeTokenValidator:    params.ConsumeTokenValidator,
+		protectedConsumePolicy:   params.ProtectedConsumePolicy,
+		idGenerator:              params.IdGenerator,
 		producers:                make(map[string]*Producer),
 		consumers:                make(map[string]*Consumer),
 		observer:                 NewEventEmitter(AppLogger()),
@@ -82,7 +117,7 @@ func (transport *baseTransport) Closed() bool {
 	return transport.closed
 }
 
-//App custom data.
+// App custom data.
 func (transport *baseTransport) AppData() interface{} {
 	return transport.appData
 }
@@ -93,47 +128,82 @@ func (transport *baseTransport) AppData() interface{} {
  * @emits close
  * @emits {producer: Producer} newproducer
  * @emits {consumer: Consumer} newconsumer
+ * @emits {ProtectedConsumeAudit} protectedconsume
  */
 func (transport *baseTransport) Observer() EventEmitter {
 	return transport.observer
 }
 
+// RtxEnabled tells whether the Consumers created from now on may use RTX.
+func (transport *baseTransport) RtxEnabled() bool {
+	return !transport.rtxDisabled
+}
+
+// SetRtxEnabled enables or disables RTX for the Consumers created from now
+// on, as transportConsumeParams.DisableRtx does for a single Consumer.
+// Existing Consumers are not changed.
+func (transport *baseTransport) SetRtxEnabled(enabled bool) {
+	transport.rtxDisabled = !enabled
+}
+
 // Close the Transport.
 func (transport *baseTransport) Close() (err error) {
-	if transport.closed {
+	request := transport.prepareClose()
+
+	if request == nil {
 		return
 	}
 
+	response := transport.channel.Request(request.Method, request.Internal, request.Data)
+
+	if err = response.Err(); err != nil {
+		return
+	}
+
+	transport.finishClose()
+
+	return
+}
+
+/**
+ * Mark the Transport closed and return the worker request closing it, nil if
+ * it is already closed.
+ *
+ * @virtual
+ */
+func (transport *baseTransport) prepareClose() *channelRequest {
+	if transport.closed {
+		return nil
+	}
+
 	transport.logger.Debug("close()")
 
 	transport.closed = true
 
 	transport.RemoveAllListeners(transport.internal.TransportId)
 
-	response := transport.channel.Request("transport.close", transport.internal, nil)
+	return &channelRequest{Method: "transport.close", Internal: transport.internal}
+}
 
-	if err = response.Err(); err != nil {
-		return
-	}
+// finishClose closes the Producers and Consumers and emits the close events
+// once the worker closed the Transport.
+func (transport *baseTransport) finishClose() {
+	producers, consumers := transport.takeEntities()
 
-	for _, producer := range transport.producers {
+	for _, producer := range producers {
 		producer.TransportClosed()
 
 		transport.Emit("@producerclose", producer)
 	}
-	transport.producers = make(map[string]*Producer)
 
-	for _, consumer := range transport.consumers {
+	for _, consumer := range consumers {
 		consumer.TransportClosed()
 	}
-	transport.consumers = make(map[string]*Consumer)
 
 	transport.Emit("@close")
 
 	// Emit observer event.
 	transport.observer.SafeEmit("close")
-
-	return
 }
 
 /**
@@ -153,17 +223,17 @@ func (transport *baseTransport) routerClosed() {
 	// Remove notification subscriptions.
 	transport.channel.RemoveAllListeners(transport.internal.TransportId)
 
-	for _, producer := range transport.producers {
+	producers, consumers := transport.takeEntities()
+
+	for _, producer := range producers {
 		producer.TransportClosed()
 
 		transport.Emit("@producerclose", producer)
 	}
-	transport.producers = make(map[string]*Producer)
 
-	for _, consumer := range transport.consumers {
+	for _, consumer := range consumers {
 		consumer.TransportClosed()
 	}
-	transport.consumers = make(map[string]*Consumer)
 
 	transport.SafeEmit("routerclose")
 
@@ -178,17 +248,77 @@ func (transport *baseTransport) Dump() Response {
 	return transport.channel.Request("transport.dump", transport.internal, nil)
 }
 
-// Get Transport stats.
-func (transport *baseTransport) GetStats() (stat []TransportStat, err error) {
-	transport.logger.Debug("getStats()")
+func (transport *baseTransport) consumerList() (consumers []*Consumer) {
+	transport.entitiesLocker.Lock()
+	defer transport.entitiesLocker.Unlock()
+
+	for _, consumer := range transport.consumers {
+		consumers = append(consumers, consumer)
+	}
+
+	return
+}
+
+// producerMap returns a copy of the Producers of the Transport.
+func (transport *baseTransport) producerMap() map[string]*Producer {
+	transport.entitiesLocker.Lock()
+	defer transport.entitiesLocker.Unlock()
+
+	producers := make(map[string]*Producer, len(transport.producers))
+
+	for id, producer := range transport.producers {
+		producers[id] = producer
+	}
+
+	return producers
+}
 
-	resp := transport.channel.Request("transport.getStats", transport.internal)
+// takeEntities empties the Producers and Consumers of the Transport,
+// returning them.
+func (transport *baseTransport) takeEntities() (producers []*Producer, consumers []*Consumer) {
+	transport.entitiesLocker.Lock()
+	defer transport.entitiesLocker.Unlock()
 
-	err = resp.Unmarshal(&stat)
+	for _, producer := range transport.producers {
+		producers = append(producers, producer)
+	}
+	for _, consumer := range transport.consumers {
+		consumers = append(consumers, consumer)
+	}
+
+	transport.producers = make(map[string]*Producer)
+	transport.consumers = make(map[string]*Consumer)
 
 	return
 }
 
+func (transport *baseTransport) addConsumer(consumer *Consumer) {
+	transport.entitiesLocker.Lock()
+	transport.consumers[consumer.Id()] = consumer
+	transport.entitiesLocker.Unlock()
+
+	consumer.On("@close", func() {
+		transport.removeConsumer(consumer)
+	})
+	consumer.On("@producerclose", func() {
+		transport.removeConsumer(consumer)
+	})
+}
+
+func (transport *baseTransport) removeConsumer(consumer *Consumer) {
+	transport.entitiesLocker.Lock()
+	defer transport.entitiesLocker.Unlock()
+
+	delete(transport.consumers, consumer.Id())
+}
+
+// Get Transport stats.
+func (transport *baseTransport) GetStats() (stat []TransportStat, err error) {
+	transport.logger.Debug("getStats()")
+
+	return request[[]TransportStat](transport.channel, "transport.getStats", transport.internal)
+}
+
 func (transport *baseTransport) Connect(transportConnectParams) error {
 	return errors.New("method not implemented in the subclass")
 }
@@ -205,6 +335,10 @@ func (transport *baseTransport) Connect(transportConnectParams) error {
 func (transport *baseTransport) Produce(params transportProduceParams) (producer *Producer, err error) {
 	transport.logger.Debug("produce()")
 
+	if err = transport.checkRouterClosing(); err != nil {
+		return
+	}
+
 	id := params.Id
 	kind := params.Kind
 	rtpParameters := params.RtpParameters
@@ -219,16 +353,36 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 		return
 	}
 
-	if len(id) > 0 && transport.producers[id] != nil {
+	producers := transport.producerMap()
+
+	if len(id) > 0 && producers[id] != nil {
 		err = NewTypeError(`a Producer with same id "%s" already exists`, id)
 		return
 	}
 
-	if kind != "audio" && kind != "video" {
+	if !kind.IsRtp() {
 		err = NewTypeError(`invalid kind "%s"`, kind)
 		return
 	}
 
+	if err = ValidateRtpParameters(rtpParameters); err != nil {
+		return
+	}
+
+	if rtpParameters.Encodings, err = normalizeRtpEncodings(kind, rtpParameters.Encodings); err != nil {
+		return
+	}
+
+	if err = checkDuplicateSsrcs(rtpParameters, producers); err != nil {
+		return
+	}
+
+	if transport.featureFlags.StrictValidation {
+		if err = validateRtpParametersStrict(rtpParameters); err != nil {
+			return
+		}
+	}
+
 	pc, _, _, ok := runtime.Caller(1)
 	// Don"t do this in PipeTransports since there we must keep CNAME value in
 	// each Producer.
@@ -241,7 +395,7 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 		} else if len(transport.cnameForProducers) == 0 {
 			// Otherwise if we don"t have yet a CNAME for Producers and the RTP parameters
 			// do not include CNAME, create a random one.
-			transport.cnameForProducers = uuid.NewV4().String()[:8]
+			transport.cnameForProducers = GenerateCname()
 		}
 
 		// Override Producer"s CNAME.
@@ -250,12 +404,42 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 
 	routerRtpCapabilities := transport.getRouterRtpCapabilities()
 
-	rtpMapping, err := GetProducerRtpParametersMapping(
-		rtpParameters, routerRtpCapabilities)
+	rtpMapping, err := getProducerRtpParametersMapping(rtpParameters,
+		routerRtpCapabilities, transport.generateMappedSsrc, transport.headerExtensionMode, nil)
+
+	// The mapped SSRCs are in use until the Producer is closed.
+	defer func() {
+		if err != nil {
+			transport.releaseMappedSsrcs(rtpMapping)
+		}
+	}()
+
 	if err != nil {
 		return
 	}
 
+	// Drop the header extensions left out of the mapping in lenient mode.
+	if len(rtpMapping.HeaderExtensions) < len(rtpParameters.HeaderExtensions) {
+		mappedIds := map[int]bool{}
+
+		for _, ext := range rtpMapping.HeaderExtensions {
+			mappedIds[ext.Id] = true
+		}
+
+		headerExtensions := []RtpHeaderExtension{}
+
+		for _, ext := range rtpParameters.HeaderExtensions {
+			if mappedIds[ext.Id] {
+				headerExtensions = append(headerExtensions, ext)
+			} else {
+				transport.logger.Debugf("dropping unsupported header extension [uri:%s, id:%d]",
+					ext.Uri, ext.Id)
+			}
+		}
+
+		rtpParameters.HeaderExtensions = headerExtensions
+	}
+
 	consumableRtpParameters, err := GetConsumableRtpParameters(
 		kind, rtpParameters, routerRtpCapabilities, rtpMapping)
 	if err != nil {
@@ -263,10 +447,11 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 	}
 
 	internal := transport.internal
+	internal.Trace = internal.Trace.merge(appDataLabels(appData))
 	if len(id) > 0 {
 		internal.ProducerId = id
 	} else {
-		internal.ProducerId = uuid.NewV4().String()
+		internal.ProducerId = transport.newId(IdEntityProducer)
 	}
 
 	reqData := H{
@@ -290,13 +475,23 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 		RtpParameters:           rtpParameters,
 		Type:                    status.Type,
 		ConsumableRtpParameters: consumableRtpParameters,
+		Protected:               params.Protected,
 	}
 
 	producer = NewProducer(internal, producerData, transport.channel, appData, paused)
 
+	transport.entitiesLocker.Lock()
 	transport.producers[producer.Id()] = producer
+	transport.entitiesLocker.Unlock()
+
+	producer.Observer().On("close", func() {
+		transport.releaseMappedSsrcs(rtpMapping)
+	})
 	producer.On("@close", func() {
+		transport.entitiesLocker.Lock()
 		delete(transport.producers, producer.Id())
+		transport.entitiesLocker.Unlock()
+
 		transport.Emit("@producerclose", producer)
 	})
 
@@ -308,6 +503,16 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
 	return
 }
 
+func (transport *baseTransport) releaseMappedSsrcs(rtpMapping RtpMappingParameters) {
+	for _, encoding := range rtpMapping.Encodings {
+		for _, ssrc := range []uint32{encoding.MappedSsrc, encoding.MappedRtxSsrc} {
+			if ssrc != 0 {
+				transport.releaseMappedSsrc(ssrc)
+			}
+		}
+	}
+}
+
 /**
  * Create a Consumer.
  *
@@ -315,10 +520,16 @@ func (transport *baseTransport) Produce(params transportProduceParams) (producer
  * @param rtpCapabilities - Remote RTP capabilities.
  * @param [paused=false] - Whether the Consumer must start paused.
  * @param [appData={}] - Custom app data.
+ * @param [stripVideoOrientation=false] - Remove the video orientation header
+ *   extension.
  */
 func (transport *baseTransport) Consume(params transportConsumeParams) (consumer *Consumer, err error) {
 	transport.logger.Debug("consume()")
 
+	if err = transport.checkRouterClosing(); err != nil {
+		return
+	}
+
 	producerId := params.ProducerId
 	rtpCapabilities := params.RtpCapabilities
 	paused := params.Paused
@@ -332,6 +543,16 @@ func (transport *baseTransport) Consume(params transportConsumeParams) (consumer
 		return
 	}
 
+	if transport.consumeTokenValidator != nil && !params.authorized {
+		if err = transport.consumeTokenValidator(producerId, params.Token); err != nil {
+			return
+		}
+	}
+
+	if err = ValidateRtpCapabilities(&rtpCapabilities); err != nil {
+		return
+	}
+
 	producer := transport.getProducerById(producerId)
 
 	if producer == nil {
@@ -339,15 +560,40 @@ func (transport *baseTransport) Consume(params transportConsumeParams) (consumer
 		return
 	}
 
-	rtpParameters, err := GetConsumerRtpParameters(
-		producer.ConsumableRtpParameters(), rtpCapabilities)
+	protectedAudit, err := transport.authorizeProtectedConsume(producer, false, params)
 	if err != nil {
 		return
 	}
 
+	var rtpParameters RtpParameters
+
+	if params.rtpParameters != nil {
+		rtpParameters = *params.rtpParameters
+	} else if rtpParameters, err = GetConsumerRtpParameters(
+		producer.ConsumableRtpParameters(), rtpCapabilities); err != nil {
+		return
+	}
+
+	if params.StripVideoOrientation {
+		rtpParameters = stripHeaderExtension(rtpParameters, VideoOrientationUri)
+	}
+
+	rtxDisabled := params.DisableRtx || transport.rtxDisabled
+
+	if rtxDisabled {
+		rtpParameters = removeRtx(rtpParameters)
+	}
+
+	if quirks, ok := GetClientQuirks(params.Device); ok {
+		if rtpParameters, err = applyClientQuirks(rtpParameters, quirks); err != nil {
+			return
+		}
+	}
+
 	internal := transport.internal
-	internal.ConsumerId = uuid.NewV4().String()
+	internal.ConsumerId = transport.newId(IdEntityConsumer)
 	internal.ProducerId = producerId
+	internal.Trace = internal.Trace.merge(appDataLabels(appData))
 
 	reqData := H{
 		"kind":                   producer.Kind(),
@@ -384,16 +630,22 @@ func (transport *baseTransport) Consume(params transportConsumeParams) (consumer
 		status.Score,
 	)
 
-	transport.consumers[consumer.Id()] = consumer
-	consumer.On("@close", func() {
-		delete(transport.consumers, consumer.Id())
-	})
-	consumer.On("@producerclose", func() {
-		delete(transport.consumers, consumer.Id())
-	})
+	consumer.getProducerById = transport.getProducerById
+	consumer.authorizeSwitch = func(producer *Producer, token string) (func(), error) {
+		return transport.authorizeSwitchProducer(consumer, producer, token, params)
+	}
+	consumer.autoKeyFrame = !params.DisableAutoKeyFrame
+	consumer.rtxDisabled = rtxDisabled
+
+	transport.addConsumer(consumer)
 
 	// Emit observer event.
 	transport.observer.SafeEmit("newconsumer", consumer)
 
+	if protectedAudit != nil {
+		protectedAudit.ConsumerId = consumer.Id()
+		transport.emitProtectedConsume(producer, *protectedAudit)
+	}
+
 	return
 }
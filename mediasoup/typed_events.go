@@ -0,0 +1,134 @@
+package mediasoup
+
+import "sync"
+
+/**
+ * OnEvent adds a listener of an event with a single argument of type T,
+ * checked at compile time instead of when the event is emitted. A missing
+ * argument is given as the zero value of T.
+ *
+ * @returns A function removing the listener.
+ */
+func OnEvent[T any](emitter EventEmitter, evt string, listener func(T)) (off func()) {
+	return addTypedListener(emitter, evt, func(v T) { listener(v) }, false)
+}
+
+// OnceEvent is OnEvent for a listener called at most once.
+func OnceEvent[T any](emitter EventEmitter, evt string, listener func(T)) (off func()) {
+	return addTypedListener(emitter, evt, func(v T) { listener(v) }, true)
+}
+
+// OnSignal adds a listener of an event without arguments, such as "close".
+func OnSignal(emitter EventEmitter, evt string, listener func()) (off func()) {
+	return addTypedListener(emitter, evt, func() { listener() }, false)
+}
+
+// OnceSignal is OnSignal for a listener called at most once.
+func OnceSignal(emitter EventEmitter, evt string, listener func()) (off func()) {
+	return addTypedListener(emitter, evt, func() { listener() }, true)
+}
+
+// addTypedListener adds the wrapper of a typed listener. Wrappers share the
+// same code pointer, so they are removed by entry instead of RemoveListener().
+func addTypedListener(emitter EventEmitter, evt string, wrapper interface{}, once bool) (off func()) {
+	e, ok := emitter.(*eventEmitter)
+	if !ok {
+		if once {
+			emitter.Once(evt, wrapper)
+		} else {
+			emitter.On(evt, wrapper)
+		}
+		return func() { emitter.Off(evt, wrapper) }
+	}
+
+	item := e.addListener(evt, wrapper, once)
+
+	var offOnce sync.Once
+
+	return func() {
+		offOnce.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			e.removeListenerLocked(evt, item)
+		})
+	}
+}
+
+// OnDied adds a listener of "died", emitted if the worker process dies
+// unexpectedly.
+func (w *Worker) OnDied(listener func(err error)) (off func()) {
+	return OnEvent(w.EventEmitter, "died", listener)
+}
+
+// OnWorkerClose adds a listener of "workerclose".
+func (router *Router) OnWorkerClose(listener func()) (off func()) {
+	return OnSignal(router.EventEmitter, "workerclose", listener)
+}
+
+// OnNewTransport adds an observer listener of "newtransport".
+func (router *Router) OnNewTransport(listener func(transport Transport)) (off func()) {
+	return OnEvent(router.observer, "newtransport", listener)
+}
+
+// OnRouterClose adds a listener of "routerclose".
+func (transport *baseTransport) OnRouterClose(listener func()) (off func()) {
+	return OnSignal(transport.EventEmitter, "routerclose", listener)
+}
+
+// OnNewProducer adds an observer listener of "newproducer".
+func (transport *baseTransport) OnNewProducer(listener func(producer *Producer)) (off func()) {
+	return OnEvent(transport.observer, "newproducer", listener)
+}
+
+// OnNewConsumer adds an observer listener of "newconsumer".
+func (transport *baseTransport) OnNewConsumer(listener func(consumer *Consumer)) (off func()) {
+	return OnEvent(transport.observer, "newconsumer", listener)
+}
+
+// OnScore adds a listener of "score".
+func (producer *Producer) OnScore(listener func(score []ProducerScore)) (off func()) {
+	return OnEvent(producer.EventEmitter, "score", listener)
+}
+
+// OnVideoOrientationChange adds a listener of "videoorientationchange".
+func (producer *Producer) OnVideoOrientationChange(listener func(orientation VideoOrientation)) (off func()) {
+	return OnEvent(producer.EventEmitter, "videoorientationchange", listener)
+}
+
+// OnTransportClose adds a listener of "transportclose".
+func (producer *Producer) OnTransportClose(listener func()) (off func()) {
+	return OnSignal(producer.EventEmitter, "transportclose", listener)
+}
+
+// OnScore adds a listener of "score".
+func (consumer *Consumer) OnScore(listener func(score ConsumerScore)) (off func()) {
+	return OnEvent(consumer.EventEmitter, "score", listener)
+}
+
+// OnLayersChange adds a listener of "layerschange", layer is nil when the
+// Consumer has no current layers.
+func (consumer *Consumer) OnLayersChange(listener func(layer *VideoLayer)) (off func()) {
+	return addTypedListener(consumer.EventEmitter, "layerschange", func(layers ...VideoLayer) {
+		if len(layers) == 0 {
+			listener(nil)
+			return
+		}
+		listener(&layers[0])
+	}, false)
+}
+
+// OnProducerClose adds a listener of "producerclose".
+func (consumer *Consumer) OnProducerClose(listener func()) (off func()) {
+	return OnSignal(consumer.EventEmitter, "producerclose", listener)
+}
+
+// OnTransportClose adds a listener of "transportclose".
+func (consumer *Consumer) OnTransportClose(listener func()) (off func()) {
+	return OnSignal(consumer.EventEmitter, "transportclose", listener)
+}
+
+// OnRtp adds a listener of "rtp", emitted by Consumers of a DirectTransport.
+func (consumer *Consumer) OnRtp(listener func(rtpPacket []byte)) (off func()) {
+	return OnEvent(consumer.EventEmitter, "rtp", listener)
+}
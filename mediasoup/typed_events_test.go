@@ -0,0 +1,76 @@
+package mediasoup
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnEvent(t *testing.T) {
+	emitter := NewEventEmitter(TypeLogger("eventEmitter"))
+
+	var scores []ConsumerScore
+
+	off := OnEvent(emitter, "score", func(score ConsumerScore) {
+		scores = append(scores, score)
+	})
+	other := OnEvent(emitter, "score", func(score ConsumerScore) {})
+
+	emitter.Emit("score", ConsumerScore{Consumer: 10})
+	emitter.Emit("score")
+
+	// Only the given listener is removed, although the wrappers share the
+	// same code.
+	off()
+	off()
+	emitter.Emit("score", ConsumerScore{Consumer: 5})
+
+	assert.Equal(t, []ConsumerScore{{Consumer: 10}, {}}, scores)
+	assert.Equal(t, 1, emitter.ListenerCount("score"))
+
+	other()
+	assert.Equal(t, 0, emitter.ListenerCount("score"))
+}
+
+func TestOnceEvent_ConcurrentEmit(t *testing.T) {
+	emitter := NewEventEmitter(TypeLogger("eventEmitter"))
+
+	var locker sync.Mutex
+	called := 0
+
+	OnceSignal(emitter, "close", func() {
+		locker.Lock()
+		called++
+		locker.Unlock()
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			emitter.Emit("close")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, called)
+}
+
+func TestConsumerOnLayersChange(t *testing.T) {
+	consumer := NewConsumer(internalData{ConsumerId: "c1"}, consumerData{Kind: MediaKindVideo},
+		newTestChannel(), nil, false, false, nil)
+
+	var layers []*VideoLayer
+
+	consumer.OnLayersChange(func(layer *VideoLayer) {
+		layers = append(layers, layer)
+	})
+
+	consumer.Emit("layerschange", VideoLayer{SpatialLayer: 1})
+	consumer.Emit("layerschange")
+
+	assert.Equal(t, []*VideoLayer{{SpatialLayer: 1}, nil}, layers)
+}
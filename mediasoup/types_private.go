@@ -1,36 +1,54 @@
 package mediasoup
 
+import "github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+
 type internalData struct {
 	RouterId      string `json:"routerId,omitempty"`
 	TransportId   string `json:"transportId,omitempty"`
 	ProducerId    string `json:"producerId,omitempty"`
 	ConsumerId    string `json:"consumerId,omitempty"`
 	RtpObserverId string `json:"rtpObserverId,omitempty"`
+	// Trace ids of the requests, not sent to the worker.
+	Trace TraceIds `json:"-"`
 }
 
 type routerData struct {
-	RtpCapabilities RtpCapabilities
+	RtpCapabilities        RtpCapabilities
+	MappedSsrcRange        *MappedSsrcRange
+	SsrcAllocator          SsrcAllocator
+	AppData                interface{}
+	FeatureFlags           FeatureFlags
+	HeaderExtensionMode    HeaderExtensionMode
+	ConsumeTokenValidator  ConsumeTokenValidator
+	ProtectedConsumePolicy ProtectedConsumePolicy
+	IdGenerator            IdGenerator
+	WorkerVersion          string
+	Clock                  clock.Clock
 }
 
 type producerData struct {
-	Kind                    string
+	Kind                    MediaKind
 	Type                    string
 	RtpParameters           RtpParameters
 	ConsumableRtpParameters RtpParameters
+	Protected               bool
 }
 
 type consumerData struct {
-	Kind          string
+	Kind          MediaKind
 	Type          string
 	RtpParameters RtpParameters
 }
 
 type transportProduceParams struct {
-	Id            string                `json:"id,omitempty"`
-	Kind          string                `json:"kind,omitempty"`
+	Id            string        `json:"id,omitempty"`
+	Kind          MediaKind     `json:"kind,omitempty"`
 	RtpParameters RtpParameters `json:"rtpParameters,omitempty"`
-	Paused        bool                  `json:"paused,omitempty"`
-	AppData       interface{}           `json:"appData,omitempty"`
+	Paused        bool          `json:"paused,omitempty"`
+	AppData       interface{}   `json:"appData,omitempty"`
+	// Protected Producers can only be consumed if allowed by the
+	// ProtectedConsumePolicy of the Router.
+	Protected bool `json:"protected,omitempty"`
 }
 
 type transportConsumeParams struct {
@@ -38,14 +56,46 @@ type transportConsumeParams struct {
 	RtpCapabilities RtpCapabilities `json:"rtpCapabilities,omitempty"`
 	Paused          bool            `json:"paused,omitempty"`
 	AppData         interface{}     `json:"appData,omitempty"`
+	// StripVideoOrientation removes urn:3gpp:video-orientation for endpoints
+	// which announce it but can't rotate, the application can then handle
+	// the Producer "videoorientationchange" event itself.
+	StripVideoOrientation bool `json:"stripVideoOrientation,omitempty"`
+	// Device hint of the consuming client (e.g. "safari", "firefox/115") to
+	// apply its known quirks, see RegisterClientQuirks().
+	Device string `json:"device,omitempty"`
+	// DisableAutoKeyFrame stops the video Consumer from requesting a key
+	// frame when it's resumed or switches spatial layer, see
+	// Consumer.SetAutoKeyFrame().
+	DisableAutoKeyFrame bool `json:"disableAutoKeyFrame,omitempty"`
+	// DisableRtx omits the RTX codecs and streams even if the endpoint
+	// supports them, e.g. for bandwidth constrained relays. See also
+	// Transport.SetRtxEnabled().
+	DisableRtx bool `json:"disableRtx,omitempty"`
+	// Token authorizing to consume the Producer, required if the Router has
+	// a ConsumeTokenValidator.
+	Token string `json:"token,omitempty"`
+	// rtpParameters computed by a BroadcastSession, RtpCapabilities are
+	// ignored if set.
+	rtpParameters *RtpParameters
+	// authorized skips the token validation when re-creating a Consumer.
+	authorized bool
 }
 
 type createTransportParams struct {
 	Internal                 internalData
 	Channel                  *Channel
+	PayloadChannel           *PayloadChannel
 	AppData                  interface{}
 	GetRouterRtpCapabilities fetchRouterRtpCapabilitiesFunc
 	GetProducerById          fetchProducerFunc
+	GenerateMappedSsrc       generateSsrcFunc
+	ReleaseMappedSsrc        func(ssrc uint32)
+	IsRouterClosing          func() bool
+	FeatureFlags             FeatureFlags
+	HeaderExtensionMode      HeaderExtensionMode
+	ConsumeTokenValidator    ConsumeTokenValidator
+	ProtectedConsumePolicy   ProtectedConsumePolicy
+	IdGenerator              IdGenerator
 }
 
 type transportConnectParams struct {
@@ -53,7 +103,8 @@ type transportConnectParams struct {
 	Ip   string `json:"ip,omitempty"`
 	Port uint16 `json:"port,omitempty"`
 	// plain transport
-	RtcpPort uint16 `json:"rtcpPort,omitempty"`
+	RtcpPort       uint16          `json:"rtcpPort,omitempty"`
+	SrtpParameters *SrtpParameters `json:"srtpParameters,omitempty"`
 	// webrtc transport
 	DtlsParameters *DtlsParameters `json:"dtlsParameters,omitempty"`
 }
@@ -61,3 +112,5 @@ type transportConnectParams struct {
 type fetchProducerFunc func(producerId string) *Producer
 
 type fetchRouterRtpCapabilitiesFunc func() RtpCapabilities
+
+type generateSsrcFunc func() uint32
@@ -28,19 +28,22 @@ func (r Response) Err() error {
 // []VolumeInfo is the parameter of event "volumes" emitted by AudioLevelObserver
 type VolumeInfo struct {
 	Producer *Producer
-	Volume   uint8
+	// Volume in dBvo, from -127 to 0.
+	Volume int8
 }
 
 // VideoLayer is the parameter of event "layerschange" emitted by Consumer
 type VideoLayer struct {
-	SpatialLayer uint8 `json:"spatialLayer"`
+	SpatialLayer  uint8 `json:"spatialLayer"`
+	TemporalLayer uint8 `json:"temporalLayer"`
 }
 
 // VideoOrientation is the parameter of event "videoorientationchange" emitted by Producer
 type VideoOrientation struct {
-	Camera   bool  `json:"camera,omitempty"`
-	Flip     bool  `json:"flip,omitempty"`
-	Rotation uint8 `json:"rotation,omitempty"`
+	Camera bool `json:"camera,omitempty"`
+	Flip   bool `json:"flip,omitempty"`
+	// Rotation in degrees: 0, 90, 180 or 270.
+	Rotation uint16 `json:"rotation,omitempty"`
 }
 
 type ProducerScore struct {
@@ -56,6 +59,12 @@ type ConsumerScore struct {
 
 type PipeTransportData struct {
 	Tuple TransportTuple `json:"tuple,omitempty"`
+	// SrtpParameters of the local endpoint, if SRTP is enabled.
+	SrtpParameters *SrtpParameters `json:"srtpParameters,omitempty"`
+}
+
+type DirectTransportData struct {
+	MaxMessageSize uint32 `json:"maxMessageSize,omitempty"`
 }
 
 type PlainTransportData struct {
@@ -64,6 +73,8 @@ type PlainTransportData struct {
 	MultiSource bool            `json:"multiSource,omitempty"`
 	Tuple       TransportTuple  `json:"tuple,omitempty"`
 	RtcpTuple   *TransportTuple `json:"rtcpTuple,omitempty"`
+	// SrtpParameters of the local endpoint, if SRTP is enabled.
+	SrtpParameters *SrtpParameters `json:"srtpParameters,omitempty"`
 }
 
 type WebRtcTransportData struct {
@@ -112,12 +123,22 @@ type DtlsFingerprint struct {
 }
 
 type CreateWebRtcTransportParams struct {
-	ListenIps []ListenIp  `json:"listenIps,omitempty"`
-	EnableUdp bool        `json:"enableUdp,omitempty"`
-	EnableTcp bool        `json:"enableTcp,omitempty"`
-	PreferUdp bool        `json:"preferUdp,omitempty"`
-	PreferTcp bool        `json:"preferTcp,omitempty"`
-	AppData   interface{} `json:"appData,omitempty"`
+	// ListenIps to open the ports of the WebRtcTransport on. Every transport
+	// has ports of its own: sharing them through a WebRtcServer (mediasoup
+	// 3.10+) needs the handlerId channel format of those workers, which
+	// Channel does not speak.
+	ListenIps []ListenIp `json:"listenIps,omitempty"`
+	EnableUdp bool       `json:"enableUdp,omitempty"`
+	EnableTcp bool       `json:"enableTcp,omitempty"`
+	PreferUdp bool       `json:"preferUdp,omitempty"`
+	PreferTcp bool       `json:"preferTcp,omitempty"`
+	// InitialAvailableOutgoingBitrate in bps used by the bandwidth estimator
+	// until it gets feedback, the worker default if 0.
+	InitialAvailableOutgoingBitrate uint32      `json:"initialAvailableOutgoingBitrate,omitempty"`
+	AppData                         interface{} `json:"appData,omitempty"`
+	// TraceIds of the Transport (e.g. the peer id), added to the ones of the
+	// Router.
+	TraceIds TraceIds `json:"-"`
 }
 
 type CreatePlainRtpTransportParams struct {
@@ -126,22 +147,71 @@ type CreatePlainRtpTransportParams struct {
 	Comedia     bool        `json:"comedia,omitempty"`
 	MultiSource bool        `json:"multiSource,omitempty"`
 	AppData     interface{} `json:"appData,omitempty"`
+	// EnableSrtp to encrypt the RTP and RTCP packets.
+	EnableSrtp bool `json:"enableSrtp,omitempty"`
+	// SrtpCryptoSuite if SRTP is enabled, default AES_CM_128_HMAC_SHA1_80.
+	SrtpCryptoSuite string `json:"srtpCryptoSuite,omitempty"`
+	// TraceIds of the Transport, added to the ones of the Router.
+	TraceIds TraceIds `json:"-"`
+	// PortPool to take the port from, or to reserve ListenIp.Port in.
+	PortPool *PortPool `json:"-"`
 }
 
 type CreatePipeTransportParams struct {
 	ListenIp ListenIp    `json:"listenIp,omitempty"`
 	AppData  interface{} `json:"appData,omitempty"`
+	// EnableSrtp to encrypt the RTP and RTCP packets, e.g. when piping with a
+	// remote which is not a mediasoup Router.
+	EnableSrtp bool `json:"enableSrtp,omitempty"`
+	// SrtpCryptoSuite if SRTP is enabled, default AES_CM_128_HMAC_SHA1_80.
+	SrtpCryptoSuite string `json:"srtpCryptoSuite,omitempty"`
+	// TraceIds of the Transport, added to the ones of the Router.
+	TraceIds TraceIds `json:"-"`
+	// PortPool to take the port from, or to reserve ListenIp.Port in.
+	PortPool *PortPool `json:"-"`
+}
+
+type CreateDirectTransportParams struct {
+	// MaxMessageSize of the messages sent through the DirectTransport,
+	// default 262144.
+	MaxMessageSize uint32      `json:"maxMessageSize,omitempty"`
+	AppData        interface{} `json:"appData,omitempty"`
+	// TraceIds of the Transport, added to the ones of the Router.
+	TraceIds TraceIds `json:"-"`
+}
+
+// ImportPipeProducerParams describes a stream sent to a PipeTransport by a
+// remote which is not a mediasoup Router.
+type ImportPipeProducerParams struct {
+	Id            string        `json:"id,omitempty"`
+	Kind          MediaKind     `json:"kind,omitempty"`
+	RtpParameters RtpParameters `json:"rtpParameters,omitempty"`
+	Paused        bool          `json:"paused,omitempty"`
+	AppData       interface{}   `json:"appData,omitempty"`
 }
 
 type PipeToRouterParams struct {
 	ProducerId string   `json:"producerId,omitempty"`
 	Router     *Router  `json:"router,omitempty"`
 	ListenIp   ListenIp `json:"listenIp,omitempty"`
+	// PortPool of the two PipeTransports, if any.
+	PortPool *PortPool `json:"-"`
 }
 
 type ListenIp struct {
 	Ip          string `json:"ip,omitempty"`
 	AnnouncedIp string `json:"announcedIp,omitempty"`
+	// Port to listen on, a random one of the worker range if 0.
+	Port uint16 `json:"port,omitempty"`
+	// Flags of the listening socket.
+	Flags *SocketFlags `json:"flags,omitempty"`
+}
+
+// MappedSsrcRange is the inclusive range of SSRCs a Router uses when mapping
+// Producer encodings.
+type MappedSsrcRange struct {
+	Min uint32 `json:"min"`
+	Max uint32 `json:"max"`
 }
 
 type CreateAudioLevelObserverParams struct {
@@ -150,15 +220,22 @@ type CreateAudioLevelObserverParams struct {
 	Interval   uint32 `json:"interval,omitempty"`
 }
 
+type CreateActiveSpeakerObserverParams struct {
+	// Interval in ms for checking the dominant speaker, default 300.
+	Interval uint32 `json:"interval,omitempty"`
+}
+
 type TransportStat struct {
 	Type                     string `json:"type,omitempty"`
 	TransportId              string `json:"transportId,omitempty"`
-	Timestamp                uint32 `json:"timestamp,omitempty"`
-	BytesReceived            uint32 `json:"bytesReceived,omitempty"`
-	BytesSent                uint32 `json:"bytesSent,omitempty"`
+	Timestamp                uint64 `json:"timestamp,omitempty"`
+	BytesReceived            uint64 `json:"bytesReceived,omitempty"`
+	BytesSent                uint64 `json:"bytesSent,omitempty"`
 	AvailableIncomingBitrate uint32 `json:"availableIncomingBitrate,omitempty"`
 	AvailableOutgoingBitrate uint32 `json:"availableOutgoingBitrate,omitempty"`
 	MaxIncomingBitrate       uint32 `json:"maxIncomingBitrate,omitempty"`
+	RecvBitrate              uint32 `json:"recvBitrate,omitempty"`
+	SendBitrate              uint32 `json:"sendBitrate,omitempty"`
 
 	// webrtc transport
 	IceRole          string          `json:"iceRole,omitempty"`
@@ -170,3 +247,35 @@ type TransportStat struct {
 	Tuple     *TransportTuple `json:"tuple,omitempty"`
 	RtcpTuple *TransportTuple `json:"rtcpTuple,omitempty"`
 }
+
+// RtpStreamStat is an entry of Producer.GetStats() and Consumer.GetStats().
+type RtpStreamStat struct {
+	Type                 string  `json:"type,omitempty"`
+	Timestamp            uint64  `json:"timestamp,omitempty"`
+	Ssrc                 uint32  `json:"ssrc,omitempty"`
+	RtxSsrc              uint32  `json:"rtxSsrc,omitempty"`
+	Rid                  string  `json:"rid,omitempty"`
+	Kind                 string  `json:"kind,omitempty"`
+	MimeType             string  `json:"mimeType,omitempty"`
+	PacketsLost          uint32  `json:"packetsLost,omitempty"`
+	FractionLost         uint8   `json:"fractionLost,omitempty"`
+	PacketsDiscarded     uint32  `json:"packetsDiscarded,omitempty"`
+	PacketsRetransmitted uint32  `json:"packetsRetransmitted,omitempty"`
+	PacketsRepaired      uint32  `json:"packetsRepaired,omitempty"`
+	NackCount            uint32  `json:"nackCount,omitempty"`
+	NackPacketCount      uint32  `json:"nackPacketCount,omitempty"`
+	PliCount             uint32  `json:"pliCount,omitempty"`
+	FirCount             uint32  `json:"firCount,omitempty"`
+	Score                uint8   `json:"score,omitempty"`
+	PacketCount          uint32  `json:"packetCount,omitempty"`
+	ByteCount            uint64  `json:"byteCount,omitempty"`
+	Bitrate              uint32  `json:"bitrate,omitempty"`
+	RoundTripTime        float64 `json:"roundTripTime,omitempty"`
+	Jitter               uint32  `json:"jitter,omitempty"`
+}
+
+// PacketLoss is the fraction of packets lost, between 0 and 1, in the last
+// RTCP report.
+func (stat RtpStreamStat) PacketLoss() float64 {
+	return float64(stat.FractionLost) / 256
+}
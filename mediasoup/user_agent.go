@@ -0,0 +1,117 @@
+package mediasoup
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ClientDevice describes the client parsed from its User-Agent.
+type ClientDevice struct {
+	// Browser name in lower case: "chrome", "firefox", "safari", "edge",
+	// "opera" or empty if unknown.
+	Browser string `json:"browser,omitempty"`
+	// Browser version, e.g. "115.0".
+	Version string `json:"version,omitempty"`
+	// OS name in lower case: "windows", "macos", "ios", "android", "linux",
+	// "chromeos" or empty if unknown.
+	Os string `json:"os,omitempty"`
+	// WebRTC engine in lower case: "libwebrtc" (Chromium based browsers and
+	// native apps), "gecko" (Firefox), "webkit" (Safari and every iOS browser)
+	// or empty if unknown.
+	Engine string `json:"engine,omitempty"`
+}
+
+var userAgentBrowsers = []struct {
+	name   string
+	regexp *regexp.Regexp
+}{
+	// Order matters, Chromium based browsers also announce Chrome and Safari.
+	{"edge", regexp.MustCompile(`\bEdg(?:e|A|iOS)?/([\d.]+)`)},
+	{"opera", regexp.MustCompile(`\b(?:OPR|Opera)/([\d.]+)`)},
+	{"firefox", regexp.MustCompile(`\b(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"chrome", regexp.MustCompile(`\b(?:Chrome|CriOS|Chromium)/([\d.]+)`)},
+	{"safari", regexp.MustCompile(`\bVersion/([\d.]+).*\bSafari/`)},
+}
+
+var userAgentOses = []struct {
+	name   string
+	regexp *regexp.Regexp
+}{
+	{"ios", regexp.MustCompile(`\b(?:iPhone|iPad|iPod)\b`)},
+	{"android", regexp.MustCompile(`\bAndroid\b`)},
+	{"chromeos", regexp.MustCompile(`\bCrOS\b`)},
+	{"windows", regexp.MustCompile(`\bWindows\b`)},
+	{"macos", regexp.MustCompile(`\bMac OS X\b|\bMacintosh\b`)},
+	{"linux", regexp.MustCompile(`\bLinux\b`)},
+}
+
+var libwebrtcUserAgentRegexp = regexp.MustCompile(`\blibwebrtc\b`)
+
+// ParseUserAgent parses the User-Agent of a WebRTC client. Unknown fields are
+// left empty.
+func ParseUserAgent(ua string) (device ClientDevice) {
+	for _, browser := range userAgentBrowsers {
+		if match := browser.regexp.FindStringSubmatch(ua); match != nil {
+			device.Browser = browser.name
+			device.Version = match[1]
+			break
+		}
+	}
+
+	for _, os := range userAgentOses {
+		if os.regexp.MatchString(ua) {
+			device.Os = os.name
+			break
+		}
+	}
+
+	switch {
+	case device.Os == "ios":
+		// Every iOS browser must use WebKit.
+		device.Engine = "webkit"
+	case device.Browser == "firefox":
+		device.Engine = "gecko"
+	case device.Browser == "safari":
+		device.Engine = "webkit"
+	case len(device.Browser) > 0, libwebrtcUserAgentRegexp.MatchString(ua):
+		device.Engine = "libwebrtc"
+	}
+
+	return
+}
+
+// Name returns the device hint to give to Consume() to apply the client
+// quirks. Browsers on iOS are reported as "safari" since they all use WebKit.
+func (device ClientDevice) Name() string {
+	if device.Engine == "webkit" {
+		return "safari"
+	}
+
+	return device.Browser
+}
+
+// String returns e.g. "chrome/115.0 (windows; libwebrtc)".
+func (device ClientDevice) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(device.Browser)
+
+	if len(device.Version) > 0 {
+		sb.WriteString("/" + device.Version)
+	}
+
+	sb.WriteString(" (" + device.Os + "; " + device.Engine + ")")
+
+	return sb.String()
+}
+
+// AppData returns the device as appData entries, to be merged into the
+// appData of a Transport or Consumer so it shows up in dumps and metrics.
+func (device ClientDevice) AppData() H {
+	return H{
+		"browser": device.Browser,
+		"version": device.Version,
+		"os":      device.Os,
+		"engine":  device.Engine,
+	}
+}
@@ -0,0 +1,61 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	testCases := []struct {
+		ua     string
+		device ClientDevice
+	}{
+		{
+			ua:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36",
+			device: ClientDevice{Browser: "chrome", Version: "115.0.0.0", Os: "windows", Engine: "libwebrtc"},
+		},
+		{
+			ua:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36 Edg/115.0.1901.188",
+			device: ClientDevice{Browser: "edge", Version: "115.0.1901.188", Os: "windows", Engine: "libwebrtc"},
+		},
+		{
+			ua:     "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0",
+			device: ClientDevice{Browser: "firefox", Version: "115.0", Os: "linux", Engine: "gecko"},
+		},
+		{
+			ua:     "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Safari/605.1.15",
+			device: ClientDevice{Browser: "safari", Version: "16.5", Os: "macos", Engine: "webkit"},
+		},
+		{
+			ua:     "Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/115.0.5790.130 Mobile/15E148 Safari/604.1",
+			device: ClientDevice{Browser: "chrome", Version: "115.0.5790.130", Os: "ios", Engine: "webkit"},
+		},
+		{
+			ua:     "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Mobile Safari/537.36",
+			device: ClientDevice{Browser: "chrome", Version: "115.0.0.0", Os: "android", Engine: "libwebrtc"},
+		},
+		{
+			ua:     "myapp/1.0 libwebrtc",
+			device: ClientDevice{Engine: "libwebrtc"},
+		},
+		{
+			ua:     "",
+			device: ClientDevice{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.device, ParseUserAgent(testCase.ua), testCase.ua)
+	}
+}
+
+func TestClientDevice_Name(t *testing.T) {
+	device := ParseUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/115.0.5790.130 Mobile/15E148 Safari/604.1")
+	assert.Equal(t, "safari", device.Name())
+	assert.Equal(t, "chrome/115.0.5790.130 (ios; webkit)", device.String())
+
+	quirks, ok := GetClientQuirks("Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0")
+	assert.True(t, ok)
+	assert.True(t, quirks.NoTransportCc)
+}
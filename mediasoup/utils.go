@@ -1,6 +1,8 @@
 package mediasoup
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"reflect"
 	"time"
@@ -23,3 +25,65 @@ func isObject(appData interface{}) bool {
 
 	return appDataKind == reflect.Struct || appDataKind == reflect.Map
 }
+
+// dumpWithAppData adds the given app data to a dump response as "appData",
+// unless it is empty.
+func dumpWithAppData(rsp Response, appData interface{}) Response {
+	if rsp.err != nil || isEmptyAppData(appData) {
+		return rsp
+	}
+
+	dump := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(rsp.data, &dump); err != nil {
+		return rsp
+	}
+
+	data, err := json.Marshal(appData)
+	if err != nil {
+		return rsp
+	}
+	dump["appData"] = data
+
+	if data, err = json.Marshal(dump); err != nil {
+		return rsp
+	}
+
+	return Response{data: data}
+}
+
+func isEmptyAppData(appData interface{}) bool {
+	if appData == nil {
+		return true
+	}
+
+	data, err := json.Marshal(appData)
+
+	return err != nil || string(data) == "{}" || string(data) == "null"
+}
+
+// AppDataLabels flattens the top level scalar values of the given app data to
+// strings, e.g. to be used as metrics labels. Nested values are ignored.
+func AppDataLabels(appData interface{}) map[string]string {
+	labels := map[string]string{}
+
+	data, err := json.Marshal(appData)
+	if err != nil {
+		return labels
+	}
+
+	values := map[string]interface{}{}
+
+	if err := unmarshalUseNumber(data, &values); err != nil {
+		return labels
+	}
+
+	for key, value := range values {
+		switch value.(type) {
+		case string, bool, json.Number:
+			labels[key] = fmt.Sprint(value)
+		}
+	}
+
+	return labels
+}
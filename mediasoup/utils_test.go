@@ -0,0 +1,43 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpWithAppData(t *testing.T) {
+	rsp := Response{data: []byte(`{"id":"router1","transportIds":[]}`)}
+
+	assert.JSONEq(t, `{"id":"router1","transportIds":[]}`,
+		string(dumpWithAppData(rsp, H{}).Data()))
+	assert.JSONEq(t, `{"id":"router1","transportIds":[],"appData":{"roomId":"room1"}}`,
+		string(dumpWithAppData(rsp, H{"roomId": "room1"}).Data()))
+
+	rsp = Response{err: NewInvalidStateError("closed")}
+
+	assert.Error(t, dumpWithAppData(rsp, H{"roomId": "room1"}).Err())
+}
+
+func TestAppDataLabels(t *testing.T) {
+	type appData struct {
+		RoomId   string `json:"roomId"`
+		TenantId int    `json:"tenantId"`
+		Private  bool   `json:"private"`
+		Extra    H      `json:"extra"`
+	}
+
+	labels := AppDataLabels(appData{
+		RoomId:   "room1",
+		TenantId: 42,
+		Private:  true,
+		Extra:    H{"foo": "bar"},
+	})
+
+	assert.Equal(t, map[string]string{
+		"roomId":   "room1",
+		"tenantId": "42",
+		"private":  "true",
+	}, labels)
+	assert.Empty(t, AppDataLabels(nil))
+}
@@ -0,0 +1,530 @@
+package mediasoup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// Header carrying the hex encoded HMAC-SHA256 of the request body, prefixed
+// with "sha256=".
+const WebhookSignatureHeader = "X-Mediasoup-Signature"
+
+const (
+	WebhookEventTransportCreated = "transport.created"
+	WebhookEventTransportClosed  = "transport.closed"
+	WebhookEventProducerCreated  = "producer.created"
+	WebhookEventProducerClosed   = "producer.closed"
+	WebhookEventConsumerCreated  = "consumer.created"
+	WebhookEventConsumerClosed   = "consumer.closed"
+	WebhookEventConsumerStats    = "consumer.stats"
+)
+
+type WebhookOptions struct {
+	// Url the events are POSTed to.
+	Url string
+	// Secret used to sign the request body. If empty requests are not signed.
+	Secret string
+	// StatsInterval between quality snapshots of every Consumer, 0 disables
+	// them.
+	StatsInterval time.Duration
+	// MaxRetries of a failed delivery, default 3.
+	MaxRetries int
+	// RetryDelay before the first retry, doubled on every retry, default 1s.
+	RetryDelay time.Duration
+	// QueueSize is the number of pending events, further events are dropped,
+	// default 1024.
+	QueueSize int
+	// FlushTimeout is the time Close() gives the queued events to be
+	// delivered before cancelling the ongoing and remaining deliveries,
+	// default 5s.
+	FlushTimeout time.Duration
+	// Summarize builds the JSON body of an event. If not set the event itself
+	// is sent.
+	Summarize func(event WebhookEvent) interface{}
+	// Client to send requests, default a client with a 10s timeout.
+	Client *http.Client
+	// Clock used for the stats interval, the retry delays, the flush timeout
+	// and the event timestamps, default clock.System.
+	Clock clock.Clock
+}
+
+// WebhookEvent is the default JSON body of a webhook request. Ids are set
+// according to the entity of the event: transport events only have a
+// TransportId, producer events a ProducerId and consumer events a ConsumerId
+// and a ProducerId.
+type WebhookEvent struct {
+	Event       string          `json:"event"`
+	Timestamp   int64           `json:"timestamp"`
+	TransportId string          `json:"transportId,omitempty"`
+	ConsumerId  string          `json:"consumerId,omitempty"`
+	ProducerId  string          `json:"producerId,omitempty"`
+	Kind        MediaKind       `json:"kind,omitempty"`
+	Type        string          `json:"type,omitempty"`
+	Paused      bool            `json:"paused"`
+	Score       *ConsumerScore  `json:"score,omitempty"`
+	Stats       json.RawMessage `json:"stats,omitempty"`
+	AppData     interface{}     `json:"appData,omitempty"`
+}
+
+/**
+ * Webhook POSTs Transport, Producer and Consumer lifecycle events and periodic
+ * Consumer quality snapshots to an HTTP endpoint, retrying failed deliveries
+ * with exponential backoff.
+ *
+ * @emits {err error, event WebhookEvent} deliveryerror
+ * @emits close
+ */
+type Webhook struct {
+	EventEmitter
+	logger    logrus.FieldLogger
+	options   WebhookOptions
+	locker    sync.Mutex
+	consumers map[string]*Consumer
+	// Ids of the Transports, Producers and Consumers reported.
+	reported map[string]bool
+	// Removal of the listeners added to the watched entities, by entity id.
+	offs   map[string][]func()
+	queue  chan WebhookEvent
+	stopCh chan struct{}
+	doneCh chan struct{}
+	// Cancelled once the queued events are flushed or FlushTimeout elapsed.
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+}
+
+func NewWebhook(options WebhookOptions) (webhook *Webhook, err error) {
+	logger := TypeLogger("Webhook")
+
+	logger.Debug("constructor()")
+
+	if len(options.Url) == 0 {
+		err = NewTypeError("missing url")
+		return
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = 3
+	}
+	if options.RetryDelay == 0 {
+		options.RetryDelay = time.Second
+	}
+	if options.QueueSize == 0 {
+		options.QueueSize = 1024
+	}
+	if options.FlushTimeout == 0 {
+		options.FlushTimeout = 5 * time.Second
+	}
+	options.Clock = clock.OrSystem(options.Clock)
+
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	webhook = &Webhook{
+		EventEmitter: NewEventEmitter(logger),
+		logger:       logger,
+		options:      options,
+		consumers:    make(map[string]*Consumer),
+		reported:     make(map[string]bool),
+		offs:         make(map[string][]func()),
+		queue:        make(chan WebhookEvent, options.QueueSize),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	go webhook.run()
+
+	if options.StatsInterval > 0 {
+		go webhook.runStats()
+	}
+
+	return
+}
+
+// WatchRouter reports every current and future Transport of the Router, and
+// the Producers and Consumers created in them from now on.
+func (webhook *Webhook) WatchRouter(router *Router) {
+	routerId := router.Id()
+
+	webhook.addOffs(routerId,
+		OnEvent(router.Observer(), "newtransport", func(transport Transport) {
+			webhook.AddTransport(transport)
+		}),
+		OnSignal(router.Observer(), "close", func() {
+			webhook.unreport(routerId)
+		}),
+	)
+
+	for _, transport := range router.Transports() {
+		webhook.AddTransport(transport)
+	}
+}
+
+// WatchTransport reports every Producer and Consumer created in the Transport
+// from now on.
+func (webhook *Webhook) WatchTransport(transport Transport) {
+	webhook.addOffs(transport.Id(),
+		OnEvent(transport.Observer(), "newproducer", func(producer *Producer) {
+			webhook.AddProducer(producer)
+		}),
+		OnEvent(transport.Observer(), "newconsumer", func(consumer *Consumer) {
+			webhook.AddConsumer(consumer)
+		}),
+	)
+}
+
+// AddTransport reports the creation of the Transport and its closure, and
+// watches it as WatchTransport() does.
+func (webhook *Webhook) AddTransport(transport Transport) {
+	transportId := transport.Id()
+
+	if !webhook.report(transportId) {
+		return
+	}
+
+	webhook.addOffs(transportId, OnSignal(transport.Observer(), "close", func() {
+		webhook.unreport(transportId)
+		webhook.enqueue(webhook.newTransportEvent(WebhookEventTransportClosed, transport))
+	}))
+
+	webhook.enqueue(webhook.newTransportEvent(WebhookEventTransportCreated, transport))
+	webhook.WatchTransport(transport)
+}
+
+// AddProducer reports the creation of the Producer and its closure.
+func (webhook *Webhook) AddProducer(producer *Producer) {
+	producerId := producer.Id()
+
+	if !webhook.report(producerId) {
+		return
+	}
+
+	webhook.addOffs(producerId, OnSignal(producer.Observer(), "close", func() {
+		webhook.unreport(producerId)
+		webhook.enqueue(webhook.newProducerEvent(WebhookEventProducerClosed, producer))
+	}))
+
+	webhook.enqueue(webhook.newProducerEvent(WebhookEventProducerCreated, producer))
+}
+
+// AddConsumer reports the creation of the Consumer, its quality snapshots and
+// its closure.
+func (webhook *Webhook) AddConsumer(consumer *Consumer) {
+	consumerId := consumer.Id()
+
+	if !webhook.report(consumerId) {
+		return
+	}
+
+	webhook.locker.Lock()
+	webhook.consumers[consumerId] = consumer
+	webhook.locker.Unlock()
+
+	webhook.addOffs(consumerId, OnSignal(consumer.Observer(), "close", func() {
+		webhook.locker.Lock()
+		delete(webhook.consumers, consumerId)
+		webhook.locker.Unlock()
+
+		webhook.unreport(consumerId)
+		webhook.enqueue(webhook.newConsumerEvent(WebhookEventConsumerClosed, consumer))
+	}))
+
+	webhook.enqueue(webhook.newConsumerEvent(WebhookEventConsumerCreated, consumer))
+}
+
+// report returns whether the entity has to be reported, i.e. the Webhook is
+// not closed and doesn't report it yet.
+func (webhook *Webhook) report(entityId string) bool {
+	webhook.locker.Lock()
+	defer webhook.locker.Unlock()
+
+	if webhook.closed || webhook.reported[entityId] {
+		return false
+	}
+
+	webhook.reported[entityId] = true
+
+	return true
+}
+
+// unreport forgets the closed entity and removes the listeners added to it.
+func (webhook *Webhook) unreport(entityId string) {
+	webhook.locker.Lock()
+	offs := webhook.offs[entityId]
+	delete(webhook.reported, entityId)
+	delete(webhook.offs, entityId)
+	webhook.locker.Unlock()
+
+	for _, off := range offs {
+		off()
+	}
+}
+
+// addOffs keeps the removals of the listeners added to the entity, removing
+// them at once if the Webhook is closed.
+func (webhook *Webhook) addOffs(entityId string, offs ...func()) {
+	webhook.locker.Lock()
+
+	if webhook.closed {
+		webhook.locker.Unlock()
+
+		for _, off := range offs {
+			off()
+		}
+		return
+	}
+
+	webhook.offs[entityId] = append(webhook.offs[entityId], offs...)
+	webhook.locker.Unlock()
+}
+
+// Whether the Webhook is closed.
+func (webhook *Webhook) Closed() bool {
+	webhook.locker.Lock()
+	defer webhook.locker.Unlock()
+
+	return webhook.closed
+}
+
+// Close removes the listeners of the watched entities, stops the snapshots
+// and the retries, and waits for the queued events to be flushed: each of
+// them gets a single delivery attempt, a failed one being reported by
+// "deliveryerror" without being retried. The deliveries still ongoing after
+// FlushTimeout are cancelled and reported the same way.
+func (webhook *Webhook) Close() {
+	webhook.locker.Lock()
+
+	if webhook.closed {
+		webhook.locker.Unlock()
+		return
+	}
+
+	webhook.logger.Debug("close()")
+
+	webhook.closed = true
+	webhook.consumers = make(map[string]*Consumer)
+	webhook.reported = make(map[string]bool)
+	offs := webhook.offs
+	webhook.offs = make(map[string][]func())
+	close(webhook.stopCh)
+	close(webhook.queue)
+	webhook.locker.Unlock()
+
+	for _, entityOffs := range offs {
+		for _, off := range entityOffs {
+			off()
+		}
+	}
+
+	timer := webhook.options.Clock.NewTimer(webhook.options.FlushTimeout)
+
+	select {
+	case <-webhook.doneCh:
+		timer.Stop()
+	case <-timer.C():
+		webhook.logger.Warnf("queued events not flushed after %s, cancelling them",
+			webhook.options.FlushTimeout)
+	}
+
+	webhook.cancel()
+	<-webhook.doneCh
+
+	webhook.SafeEmit("close")
+}
+
+func (webhook *Webhook) enqueue(event WebhookEvent) {
+	webhook.locker.Lock()
+	defer webhook.locker.Unlock()
+
+	if webhook.closed {
+		return
+	}
+
+	select {
+	case webhook.queue <- event:
+	default:
+		webhook.logger.Warnf("queue full, dropping event [event:%s]", event.Event)
+	}
+}
+
+func (webhook *Webhook) run() {
+	defer close(webhook.doneCh)
+
+	for event := range webhook.queue {
+		// Cancelled deliveries are not attempted.
+		err := webhook.ctx.Err()
+
+		if err == nil {
+			err = webhook.deliver(event)
+		}
+
+		if err != nil {
+			webhook.logger.Errorf("event delivery failed [event:%s]: %s", event.Event, err)
+
+			webhook.SafeEmit("deliveryerror", err, event)
+		}
+	}
+}
+
+func (webhook *Webhook) runStats() {
+	ticker := webhook.options.Clock.NewTicker(webhook.options.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-webhook.stopCh:
+			return
+		case <-ticker.C():
+		}
+
+		webhook.locker.Lock()
+		consumers := make([]*Consumer, 0, len(webhook.consumers))
+		for _, consumer := range webhook.consumers {
+			consumers = append(consumers, consumer)
+		}
+		webhook.locker.Unlock()
+
+		for _, consumer := range consumers {
+			rsp := consumer.GetStats()
+
+			if err := rsp.Err(); err != nil {
+				webhook.logger.Warnf("consumer getStats() failed [consumerId:%s]: %s",
+					consumer.Id(), err)
+				continue
+			}
+
+			event := webhook.newConsumerEvent(WebhookEventConsumerStats, consumer)
+			event.Stats = rsp.Data()
+
+			webhook.enqueue(event)
+		}
+	}
+}
+
+// deliver POSTs the event, retrying on network errors and 5xx/429 responses
+// up to MaxRetries times with a doubling delay. Closing the Webhook stops
+// the retries.
+func (webhook *Webhook) deliver(event WebhookEvent) (err error) {
+	var payload interface{} = event
+
+	if webhook.options.Summarize != nil {
+		payload = webhook.options.Summarize(event)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	delay := webhook.options.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		var retry bool
+
+		if retry, err = webhook.post(body); err == nil || !retry ||
+			attempt >= webhook.options.MaxRetries {
+			return
+		}
+
+		webhook.logger.Warnf("event delivery failed, retrying in %s [event:%s]: %s",
+			delay, event.Event, err)
+
+		timer := webhook.options.Clock.NewTimer(delay)
+
+		select {
+		case <-timer.C():
+		case <-webhook.stopCh:
+			timer.Stop()
+			return
+		}
+
+		delay *= 2
+	}
+}
+
+func (webhook *Webhook) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(webhook.ctx, "POST", webhook.options.Url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(webhook.options.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader,
+			"sha256="+webhookSignature(webhook.options.Secret, body))
+	}
+
+	resp, err := webhook.options.Client.Do(req)
+	if err != nil {
+		return webhook.ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+
+	return
+}
+
+func (webhook *Webhook) newTransportEvent(name string, transport Transport) WebhookEvent {
+	return WebhookEvent{
+		Event:       name,
+		Timestamp:   clock.NowMs(webhook.options.Clock),
+		TransportId: transport.Id(),
+		AppData:     transport.AppData(),
+	}
+}
+
+func (webhook *Webhook) newProducerEvent(name string, producer *Producer) WebhookEvent {
+	return WebhookEvent{
+		Event:      name,
+		Timestamp:  clock.NowMs(webhook.options.Clock),
+		ProducerId: producer.Id(),
+		Kind:       producer.Kind(),
+		Type:       producer.Type(),
+		Paused:     producer.Paused(),
+		AppData:    producer.AppData(),
+	}
+}
+
+func (webhook *Webhook) newConsumerEvent(name string, consumer *Consumer) WebhookEvent {
+	return WebhookEvent{
+		Event:      name,
+		Timestamp:  clock.NowMs(webhook.options.Clock),
+		ConsumerId: consumer.Id(),
+		ProducerId: consumer.ProducerId(),
+		Kind:       consumer.Kind(),
+		Type:       consumer.Type(),
+		Paused:     consumer.Paused(),
+		Score:      consumer.Score(),
+		AppData:    consumer.AppData(),
+	}
+}
+
+// webhookSignature returns the hex encoded HMAC-SHA256 of body.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
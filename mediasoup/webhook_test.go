@@ -0,0 +1,245 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/mediasoup/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeliver(t *testing.T) {
+	requests := 0
+	delivered := make(chan struct{})
+	var body []byte
+	var signature string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		close(delivered)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		Url:        server.URL,
+		Secret:     "secret",
+		RetryDelay: time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerCreated, ConsumerId: "c1"})
+	<-delivered
+	webhook.Close()
+
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "sha256="+webhookSignature("secret", body), signature)
+
+	var event WebhookEvent
+	assert.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, WebhookEventConsumerCreated, event.Event)
+	assert.Equal(t, "c1", event.ConsumerId)
+}
+
+func TestWebhookDeliver_Summarize(t *testing.T) {
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		Url: server.URL,
+		Summarize: func(event WebhookEvent) interface{} {
+			return H{"id": event.ConsumerId}
+		},
+	})
+	assert.NoError(t, err)
+
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerClosed, ConsumerId: "c1"})
+	webhook.Close()
+
+	assert.JSONEq(t, `{"id":"c1"}`, string(body))
+}
+
+func TestWebhookDeliver_NoRetryOnClientError(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{Url: server.URL, RetryDelay: time.Millisecond})
+	assert.NoError(t, err)
+
+	var deliveryErr error
+	webhook.On("deliveryerror", func(err error, event WebhookEvent) {
+		deliveryErr = err
+	})
+
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerStats})
+	webhook.Close()
+
+	assert.Equal(t, 1, requests)
+	assert.Error(t, deliveryErr)
+}
+
+func TestWebhookClose_InterruptsRetries(t *testing.T) {
+	requests := make(chan struct{}, 4)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		requests <- struct{}{}
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{Url: server.URL, RetryDelay: time.Hour})
+	assert.NoError(t, err)
+
+	var failedEvents []string
+	webhook.On("deliveryerror", func(err error, event WebhookEvent) {
+		failedEvents = append(failedEvents, event.ConsumerId)
+	})
+
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerCreated, ConsumerId: "c1"})
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerCreated, ConsumerId: "c2"})
+	<-requests
+
+	closed := make(chan struct{})
+	go func() {
+		webhook.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() waited for the retry delay")
+	}
+
+	// The queued event is flushed with a single attempt.
+	assert.Len(t, requests, 1)
+	assert.Equal(t, []string{"c1", "c2"}, failedEvents)
+}
+
+func TestWebhookClose_CancelsDeliveries(t *testing.T) {
+	requests := make(chan struct{}, 4)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	fakeClock := testutil.NewFakeClock(time.Unix(0, 0))
+
+	webhook, err := NewWebhook(WebhookOptions{
+		Url:          server.URL,
+		FlushTimeout: time.Second,
+		Clock:        fakeClock,
+	})
+	assert.NoError(t, err)
+
+	var failedEvents []string
+	webhook.On("deliveryerror", func(err error, event WebhookEvent) {
+		assert.Error(t, err)
+		failedEvents = append(failedEvents, event.ConsumerId)
+	})
+
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerCreated, ConsumerId: "c1"})
+	webhook.enqueue(WebhookEvent{Event: WebhookEventConsumerCreated, ConsumerId: "c2"})
+	<-requests
+
+	closed := make(chan struct{})
+	go func() {
+		webhook.Close()
+		close(closed)
+	}()
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() waited for the ongoing delivery")
+	}
+
+	// The queued event is cancelled without being attempted.
+	assert.Len(t, requests, 0)
+	assert.Equal(t, []string{"c1", "c2"}, failedEvents)
+}
+
+func TestWebhookAddTransport(t *testing.T) {
+	var locker sync.Mutex
+	var events []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		json.NewDecoder(r.Body).Decode(&event)
+
+		locker.Lock()
+		events = append(events, event.Event+":"+event.TransportId+event.ProducerId)
+		locker.Unlock()
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{Url: server.URL})
+	assert.NoError(t, err)
+
+	channel := newTestChannel()
+	defer channel.Close()
+
+	newTestTransport := func(transportId string) *baseTransport {
+		return newTransport(createTransportParams{
+			Internal: internalData{RouterId: "r1", TransportId: transportId},
+			Channel:  channel,
+		})
+	}
+	transport1, transport2 := newTestTransport("t1"), newTestTransport("t2")
+	producer := NewProducer(internalData{RouterId: "r1", TransportId: "t1", ProducerId: "p1"},
+		producerData{Kind: MediaKindAudio, Type: "simple"}, channel, nil, false)
+
+	webhook.AddTransport(transport1)
+	webhook.AddTransport(transport1)
+	transport1.Observer().SafeEmit("newproducer", producer)
+	assert.NoError(t, producer.Close())
+	assert.NoError(t, transport1.Close())
+	assert.Zero(t, transport1.Observer().ListenerCount("newproducer"))
+
+	webhook.AddTransport(transport2)
+	webhook.Close()
+
+	// Listeners are removed once the Webhook is closed.
+	assert.Zero(t, transport2.Observer().ListenerCount("close"))
+	assert.Zero(t, transport2.Observer().ListenerCount("newproducer"))
+	assert.Zero(t, transport2.Observer().ListenerCount("newconsumer"))
+
+	assert.Equal(t, []string{
+		"transport.created:t1",
+		"producer.created:p1",
+		"producer.closed:p1",
+		"transport.closed:t1",
+		"transport.created:t2",
+	}, events)
+}
+
+func TestNewWebhook_TypeError(t *testing.T) {
+	_, err := NewWebhook(WebhookOptions{})
+	assert.IsType(t, NewTypeError(""), err)
+}
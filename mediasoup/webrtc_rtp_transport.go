@@ -96,6 +96,23 @@ func (t *WebRtcTransport) Close() (err error) {
 	return t.baseTransport.Close()
 }
 
+/**
+ * Mark the WebRtcTransport closed before a bulk close.
+ *
+ * @override
+ */
+func (t *WebRtcTransport) prepareClose() *channelRequest {
+	if t.closed {
+		return nil
+	}
+
+	t.data.IceState = "closed"
+	t.data.IceSelectedTuple = nil
+	t.data.DtlsState = "closed"
+
+	return t.baseTransport.prepareClose()
+}
+
 /**
  * Router was closed.
  *
@@ -189,7 +206,7 @@ func (t *WebRtcTransport) handleWorkerNotifications() {
 		json.Unmarshal([]byte(rawData), &data)
 
 		switch event {
-		case "icestatechange":
+		case TransportNotificationIceStateChange:
 			iceState := data.IceState
 
 			t.data.IceState = iceState
@@ -199,7 +216,7 @@ func (t *WebRtcTransport) handleWorkerNotifications() {
 			// Emit observer event.
 			t.observer.SafeEmit("icestatechange", iceState)
 
-		case "iceselectedtuplechange":
+		case TransportNotificationIceSelectedTupleChange:
 			iceSelectedTuple := *data.IceSelectedTuple
 
 			t.data.IceSelectedTuple = &iceSelectedTuple
@@ -209,7 +226,7 @@ func (t *WebRtcTransport) handleWorkerNotifications() {
 			// Emit observer event.
 			t.observer.SafeEmit("iceselectedtuplechange", iceSelectedTuple)
 
-		case "dtlsstatechange":
+		case TransportNotificationDtlsStateChange:
 			dtlsState, dtlsRemoteCert := data.DtlsState, data.DtlsRemoteCert
 
 			t.data.DtlsState = dtlsState
@@ -455,3 +455,24 @@ func TestWebRtcTransport_EmitsIfWorkerIsClosed(t *testing.T) {
 	assert.Empty(t, transport.IceSelectedTuple())
 	assert.Equal(t, transport.DtlsState(), "closed")
 }
+
+func TestRouterCreateWebRtcTransport_InitialAvailableOutgoingBitrate(t *testing.T) {
+	router, err := worker.CreateRouter(testWebRtcMediaCodecs)
+	assert.NoError(t, err)
+	defer router.Close()
+
+	params := CreateWebRtcTransportParams{
+		ListenIps:                       []ListenIp{{Ip: "127.0.0.1"}},
+		InitialAvailableOutgoingBitrate: 1000000,
+	}
+
+	data, err := json.Marshal(params)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"initialAvailableOutgoingBitrate":1000000`)
+	assert.NotContains(t, string(data), "TraceIds")
+
+	transport, err := router.CreateWebRtcTransport(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", transport.IceState())
+	assert.Equal(t, "new", transport.DtlsState())
+}
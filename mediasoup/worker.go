@@ -3,28 +3,50 @@ package mediasoup
 import (
 	"bufio"
 	"fmt"
-	"net"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
-	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type Worker struct {
 	EventEmitter
-	pid          int
-	closed       bool
-	channel      *Channel
-	observer     EventEmitter
-	logger       logrus.FieldLogger
-	workerLogger logrus.FieldLogger
-	child        *exec.Cmd
-	spawnDone    bool
-	routers      map[string]*Router
+	pid            int
+	closed         bool
+	channel        *Channel
+	payloadChannel *PayloadChannel
+	observer       EventEmitter
+	logger         logrus.FieldLogger
+	workerLogger   logrus.FieldLogger
+	child          *exec.Cmd
+	spawnDone      bool
+	// Guards routers, also read by other goroutines (e.g. WatchLeaks()).
+	routersLocker sync.Mutex
+	routers       map[string]*Router
+	appData       interface{}
+	featureFlags  FeatureFlags
+	idGenerator   IdGenerator
+	version       string
+}
+
+// channelFiles are the ends of a Channel or PayloadChannel given to the
+// worker: the one it reads from and the one it writes to, which are the same
+// socket except on Windows.
+type channelFiles struct {
+	read  *os.File
+	write *os.File
+}
+
+func (f channelFiles) Close() {
+	f.read.Close()
+
+	if f.write != f.read {
+		f.write.Close()
+	}
 }
 
 func newWorker(workerBin string, options ...Option) (worker *Worker, err error) {
@@ -42,22 +64,68 @@ func newWorker(workerBin string, options ...Option) (worker *Worker, err error)
 
 	logger.Debug("constructor()")
 
-	fds, err := syscall.Socketpair(syscall.AF_LOCAL, syscall.SOCK_STREAM, 0)
+	if opts.AppData == nil {
+		opts.AppData = H{}
+	}
+	if !isObject(opts.AppData) {
+		err = NewTypeError("if given, appData must be an object")
+		return
+	}
+
+	requestTimeouts := DefaultRequestTimeouts()
+
+	if opts.RequestTimeouts != nil {
+		if err = opts.RequestTimeouts.Validate(); err != nil {
+			return
+		}
+		requestTimeouts = *opts.RequestTimeouts
+	}
+
+	featureFlags := DefaultFeatureFlags()
+
+	if opts.FeatureFlags != nil {
+		featureFlags = *opts.FeatureFlags
+	}
+	if featureFlags.FlatBuffersChannel || featureFlags.SvcShaping {
+		logger.Warn("flatBuffersChannel and svcShaping feature flags are reserved and have no effect")
+	}
+
+	if len(workerBin) == 0 && len(opts.WorkerBinCandidates) > 0 {
+		if workerBin, err = SelectWorkerBinary(opts.WorkerBinCandidates...); err != nil {
+			return
+		}
+	}
+	if len(workerBin) > 0 {
+		if err = CheckWorkerBinary(workerBin); err != nil {
+			return
+		}
+	}
+
+	socket, channelFiles, err := newChannelSocket()
 	if err != nil {
 		return
 	}
-	fd1, fd2 := fds[0], fds[1]
+	// The worker has its own copies once spawned.
+	defer channelFiles.Close()
 
-	socket, err := fdToFileConn(fd1)
+	payloadSocket, payloadChannelFiles, err := newChannelSocket()
 	if err != nil {
+		socket.Close()
 		return
 	}
+	defer payloadChannelFiles.Close()
+
+	defer func() {
+		if err != nil {
+			socket.Close()
+			payloadSocket.Close()
+		}
+	}()
 
 	logger.Debugf(
 		"spawning worker process: %s %s", workerBin, strings.Join(opts.WorkerArgs(), " "))
 
 	child := exec.Command(workerBin, opts.WorkerArgs()...)
-	child.ExtraFiles = []*os.File{os.NewFile(uintptr(fd2), "")}
 	child.Env = []string{"MEDIASOUP_VERSION=" + opts.Version}
 
 	stderr, err := child.StderrPipe()
@@ -70,13 +138,17 @@ func newWorker(workerBin string, options ...Option) (worker *Worker, err error)
 		return
 	}
 
-	if err = child.Start(); err != nil {
+	if err = startWorkerProcess(child, channelFiles, payloadChannelFiles); err != nil {
 		return
 	}
 
 	pid := child.Process.Pid
 
 	channel := NewChannel(socket, pid)
+	channel.timeouts = requestTimeouts
+
+	payloadChannel := NewPayloadChannel(payloadSocket, pid)
+	payloadChannel.timeouts = requestTimeouts
 
 	workerLogger := TypeLogger(fmt.Sprintf(`worker[pid:%d]`, pid))
 
@@ -103,18 +175,23 @@ func newWorker(workerBin string, options ...Option) (worker *Worker, err error)
 	}()
 
 	worker = &Worker{
-		EventEmitter: NewEventEmitter(logger),
-		pid:          pid,
-		channel:      channel,
-		observer:     NewEventEmitter(AppLogger()),
-		logger:       logger,
-		workerLogger: workerLogger,
-		child:        child,
-		routers:      make(map[string]*Router),
+		EventEmitter:   NewEventEmitter(logger),
+		pid:            pid,
+		channel:        channel,
+		payloadChannel: payloadChannel,
+		observer:       NewEventEmitter(AppLogger()),
+		logger:         logger,
+		workerLogger:   workerLogger,
+		child:          child,
+		routers:        make(map[string]*Router),
+		appData:        opts.AppData,
+		featureFlags:   featureFlags,
+		idGenerator:    opts.IdGenerator,
+		version:        opts.Version,
 	}
 
 	channel.Once(strconv.Itoa(pid), func(event string) {
-		if !worker.spawnDone && event == "running" {
+		if !worker.spawnDone && event == WorkerNotificationRunning {
 			worker.spawnDone = true
 
 			logger.Debugf("worker process running [pid:%d]", pid)
@@ -136,7 +213,22 @@ func (w *Worker) Closed() bool {
 	return w.closed
 }
 
-func (w Worker) Observer() EventEmitter {
+// Custom app data.
+func (w *Worker) AppData() interface{} {
+	return w.appData
+}
+
+// Version of the worker process given in MEDIASOUP_VERSION.
+func (w *Worker) Version() string {
+	return w.version
+}
+
+// Feature flags of the Worker.
+func (w *Worker) FeatureFlags() FeatureFlags {
+	return w.featureFlags
+}
+
+func (w *Worker) Observer() EventEmitter {
 	return w.observer
 }
 
@@ -151,18 +243,24 @@ func (w *Worker) Close() {
 
 	// Kill the worker process.
 	if w.child != nil {
-		w.child.Process.Signal(syscall.SIGTERM)
+		terminateProcess(w.child.Process)
 		w.child = nil
 	}
 
-	// Close the Channel instance.
+	// Close the Channel instances.
 	w.channel.Close()
+	w.payloadChannel.Close()
 
 	// Close every Router.
-	for _, router := range w.routers {
+	routers := w.routerList()
+
+	w.routersLocker.Lock()
+	w.routers = make(map[string]*Router)
+	w.routersLocker.Unlock()
+
+	for _, router := range routers {
 		router.workerClosed()
 	}
-	w.routers = make(map[string]*Router)
 
 	// Emit observer event.
 	w.observer.SafeEmit("close")
@@ -172,7 +270,7 @@ func (w *Worker) Close() {
 func (w *Worker) Dump() Response {
 	w.logger.Debugln("dump()")
 
-	return w.channel.Request("worker.dump", nil, nil)
+	return dumpWithAppData(w.channel.Request("worker.dump", nil, nil), w.appData)
 }
 
 // UpdateSettings Update settings.
@@ -183,26 +281,78 @@ func (w *Worker) UpdateSettings(options Options) Response {
 }
 
 // CreateRouter creates a router.
-func (w *Worker) CreateRouter(mediaCodecs []RtpCodecCapability) (router *Router, err error) {
+func (w *Worker) CreateRouter(
+	mediaCodecs []RtpCodecCapability,
+	options ...RouterOption,
+) (router *Router, err error) {
 	w.logger.Debug("createRouter()")
 
-	internal := internalData{RouterId: uuid.NewV4().String()}
+	opts := &RouterOptions{}
 
-	rsp := w.channel.Request("worker.createRouter", internal, nil)
-	if err = rsp.Err(); err != nil {
+	for _, option := range options {
+		option(opts)
+	}
+
+	if opts.AppData == nil {
+		opts.AppData = H{}
+	}
+	if !isObject(opts.AppData) {
+		err = NewTypeError("if given, appData must be an object")
 		return
 	}
 
-	rtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs)
+	if ssrcRange := opts.MappedSsrcRange; ssrcRange != nil &&
+		(ssrcRange.Min == 0 || ssrcRange.Min > ssrcRange.Max) {
+		err = NewTypeError("invalid mapped SSRC range [min:%d, max:%d]",
+			ssrcRange.Min, ssrcRange.Max)
+		return
+	}
+	if opts.MappedSsrcRange != nil && opts.SsrcAllocator != nil {
+		err = NewTypeError("cannot set both mapped SSRC range and SSRC allocator")
+		return
+	}
+
+	// Invalid capabilities must not leave a Router in the worker.
+	rtpCapabilities, err := GenerateRouterRtpCapabilities(mediaCodecs, options...)
 	if err != nil {
 		return
 	}
-	data := routerData{RtpCapabilities: rtpCapabilities}
+
+	internal := internalData{
+		RouterId: w.newId(IdEntityRouter),
+		Trace:    appDataLabels(opts.AppData).merge(opts.TraceIds),
+	}
+
+	rsp := w.channel.Request("worker.createRouter", internal, nil)
+	if err = rsp.Err(); err != nil {
+		return
+	}
+
+	data := routerData{
+		RtpCapabilities:        rtpCapabilities,
+		MappedSsrcRange:        opts.MappedSsrcRange,
+		SsrcAllocator:          opts.SsrcAllocator,
+		AppData:                opts.AppData,
+		FeatureFlags:           w.featureFlags,
+		HeaderExtensionMode:    opts.HeaderExtensionMode,
+		ConsumeTokenValidator:  opts.ConsumeTokenValidator,
+		ProtectedConsumePolicy: opts.ProtectedConsumePolicy,
+		IdGenerator:            w.idGenerator,
+		WorkerVersion:          w.version,
+		Clock:                  opts.Clock,
+	}
 
 	router = NewRouter(internal, data, w.channel)
+	router.payloadChannel = w.payloadChannel
 
+	w.routersLocker.Lock()
 	w.routers[internal.RouterId] = router
+	w.routersLocker.Unlock()
+
 	router.On("@close", func() {
+		w.routersLocker.Lock()
+		defer w.routersLocker.Unlock()
+
 		delete(w.routers, internal.RouterId)
 	})
 
@@ -252,9 +402,3 @@ func (w *Worker) wait(child *exec.Cmd) {
 		w.SafeEmit("died", fmt.Errorf("[pid:%d, code:%d, signal:%s]", w.pid, code, signal))
 	}
 }
-
-func fdToFileConn(fd int) (net.Conn, error) {
-	f := os.NewFile(uintptr(fd), "")
-	defer f.Close()
-	return net.FileConn(f)
-}
@@ -0,0 +1,203 @@
+package mediasoup
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Libc of a dynamically linked Linux worker binary.
+const (
+	WorkerLibcGlibc  = "glibc"
+	WorkerLibcMusl   = "musl"
+	WorkerLibcStatic = "static"
+)
+
+// WorkerBinaryInfo describes a mediasoup-worker binary, see
+// InspectWorkerBinary().
+type WorkerBinaryInfo struct {
+	Path string
+	// OS and Arch use the GOOS/GOARCH names, empty if unknown.
+	OS   string
+	Arch string
+	// Libc is only set on Linux, Interpreter (the dynamic loader) on every
+	// ELF system.
+	Libc        string
+	Interpreter string
+}
+
+var elfMachineArchs = map[elf.Machine]string{
+	elf.EM_X86_64:  "amd64",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_ARM:     "arm",
+	elf.EM_386:     "386",
+	elf.EM_S390:    "s390x",
+	elf.EM_RISCV:   "riscv64",
+}
+
+// elfOSABIs maps the ELF OS ABIs to GOOS, Linux binaries usually have
+// ELFOSABI_NONE (System V).
+var elfOSABIs = map[elf.OSABI]string{
+	elf.ELFOSABI_NONE:    "linux",
+	elf.ELFOSABI_LINUX:   "linux",
+	elf.ELFOSABI_FREEBSD: "freebsd",
+	elf.ELFOSABI_NETBSD:  "netbsd",
+	elf.ELFOSABI_OPENBSD: "openbsd",
+	elf.ELFOSABI_SOLARIS: "solaris",
+}
+
+func elfArch(f *elf.File) string {
+	if f.Machine == elf.EM_PPC64 {
+		if f.ByteOrder == binary.LittleEndian {
+			return "ppc64le"
+		}
+		return "ppc64"
+	}
+
+	return elfMachineArchs[f.Machine]
+}
+
+var machoCpuArchs = map[macho.Cpu]string{
+	macho.CpuAmd64: "amd64",
+	macho.CpuArm64: "arm64",
+}
+
+var peMachineArchs = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+}
+
+/**
+ * Inspect the format, architecture and libc of a worker binary without
+ * running it. A path without separators is looked up in PATH, as when the
+ * worker is spawned, and info.Path is the resolved one. ok is false if the
+ * file is not an ELF, Mach-O or PE binary (e.g. a wrapper script), in which
+ * case nothing can be told about it.
+ */
+func InspectWorkerBinary(path string) (info WorkerBinaryInfo, ok bool, err error) {
+	info.Path = path
+
+	if path, err = exec.LookPath(path); err != nil {
+		return
+	}
+	info.Path = path
+
+	if _, err = os.Stat(path); err != nil {
+		return
+	}
+
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+
+		info.OS = elfOSABIs[f.OSABI]
+		info.Arch = elfArch(f)
+		if info.OS == "linux" {
+			info.Libc = WorkerLibcStatic
+		}
+
+		for _, prog := range f.Progs {
+			if prog.Type != elf.PT_INTERP {
+				continue
+			}
+			data := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(data, 0); err != nil {
+				break
+			}
+			info.Interpreter = strings.TrimRight(string(data), "\x00")
+
+			if info.OS != "linux" {
+				break
+			}
+			if strings.Contains(info.Interpreter, "musl") {
+				info.Libc = WorkerLibcMusl
+			} else {
+				info.Libc = WorkerLibcGlibc
+			}
+		}
+
+		return info, true, nil
+	}
+
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+
+		info.OS = "darwin"
+		info.Arch = machoCpuArchs[f.Cpu]
+
+		return info, true, nil
+	}
+
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+
+		info.OS = "windows"
+		info.Arch = peMachineArchs[f.Machine]
+
+		return info, true, nil
+	}
+
+	return info, false, nil
+}
+
+/**
+ * Check that the worker binary can run on this host, so an incompatible
+ * binary (e.g. an amd64 or glibc build in an arm64 Alpine container) gives a
+ * descriptive error instead of a confusing "no such file or directory" exec
+ * failure. Binaries that cannot be inspected are accepted.
+ *
+ * @throws {TypeError} if the binary is missing or not compatible.
+ */
+func CheckWorkerBinary(path string) error {
+	info, ok, err := InspectWorkerBinary(path)
+	if err != nil {
+		return NewTypeError("worker binary %q not found: %s", path, err)
+	}
+	if !ok {
+		return nil
+	}
+
+	if len(info.OS) > 0 && info.OS != runtime.GOOS {
+		return NewTypeError("worker binary %q is built for %s, host is %s", path, info.OS, runtime.GOOS)
+	}
+	if len(info.Arch) > 0 && info.Arch != runtime.GOARCH {
+		return NewTypeError("worker binary %q is built for %s, host is %s", path, info.Arch, runtime.GOARCH)
+	}
+	if len(info.Interpreter) > 0 {
+		if _, err := os.Stat(info.Interpreter); err != nil {
+			return NewTypeError("worker binary %q needs the %s loader %s, missing on this host",
+				path, info.Libc, info.Interpreter)
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Select the first worker binary compatible with this host among the given
+ * ones, e.g. bundled glibc and musl builds for amd64 and arm64.
+ *
+ * @throws {TypeError} with the reason of every rejected binary.
+ */
+func SelectWorkerBinary(paths ...string) (string, error) {
+	if len(paths) == 0 {
+		return "", NewTypeError("no worker binary given")
+	}
+
+	reasons := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		err := CheckWorkerBinary(path)
+		if err == nil {
+			return path, nil
+		}
+		reasons = append(reasons, err.Error())
+	}
+
+	return "", NewTypeError("no compatible worker binary: %s", strings.Join(reasons, "; "))
+}
@@ -0,0 +1,153 @@
+package mediasoup
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestElf writes a minimal little endian Linux ELF64 executable header
+// for the given machine, with a PT_INTERP program header if interp is given.
+func writeTestElf(t *testing.T, name string, machine elf.Machine, interp string) string {
+	return writeTestElfWith(t, name, elf.ELFOSABI_NONE, binary.LittleEndian, machine, interp)
+}
+
+// writeTestElfWith is writeTestElf with the given OS ABI and byte order.
+func writeTestElfWith(
+	t *testing.T, name string, osabi elf.OSABI, order binary.ByteOrder, machine elf.Machine, interp string,
+) string {
+	buf := &bytes.Buffer{}
+
+	data := byte(elf.ELFDATA2LSB)
+	if order == binary.BigEndian {
+		data = byte(elf.ELFDATA2MSB)
+	}
+
+	var phnum uint16
+	if len(interp) > 0 {
+		phnum = 1
+	}
+
+	buf.Write([]byte{0x7f, 'E', 'L', 'F', 2, data, 1, byte(osabi), 0, 0, 0, 0, 0, 0, 0, 0})
+	binary.Write(buf, order, uint16(elf.ET_EXEC))
+	binary.Write(buf, order, uint16(machine))
+	binary.Write(buf, order, uint32(1))
+	binary.Write(buf, order, uint64(0))  // entry
+	binary.Write(buf, order, uint64(64)) // phoff
+	binary.Write(buf, order, uint64(0))  // shoff
+	binary.Write(buf, order, uint32(0))  // flags
+	binary.Write(buf, order, uint16(64)) // ehsize
+	binary.Write(buf, order, uint16(56)) // phentsize
+	binary.Write(buf, order, phnum)
+	binary.Write(buf, order, uint16(64)) // shentsize
+	binary.Write(buf, order, uint16(0))  // shnum
+	binary.Write(buf, order, uint16(0))  // shstrndx
+
+	if phnum > 0 {
+		data := append([]byte(interp), 0)
+
+		binary.Write(buf, order, uint32(elf.PT_INTERP))
+		binary.Write(buf, order, uint32(elf.PF_R))
+		binary.Write(buf, order, uint64(64+56)) // offset
+		binary.Write(buf, order, uint64(0))     // vaddr
+		binary.Write(buf, order, uint64(0))     // paddr
+		binary.Write(buf, order, uint64(len(data)))
+		binary.Write(buf, order, uint64(len(data)))
+		binary.Write(buf, order, uint64(1))
+		buf.Write(data)
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0755))
+
+	return path
+}
+
+func TestInspectWorkerBinary(t *testing.T) {
+	path := writeTestElf(t, "worker-musl", elf.EM_AARCH64, "/lib/ld-musl-aarch64.so.1")
+
+	info, ok, err := InspectWorkerBinary(path)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, WorkerBinaryInfo{
+		Path:        path,
+		OS:          "linux",
+		Arch:        "arm64",
+		Libc:        WorkerLibcMusl,
+		Interpreter: "/lib/ld-musl-aarch64.so.1",
+	}, info)
+
+	info, ok, err = InspectWorkerBinary(writeTestElf(t, "worker-static", elf.EM_X86_64, ""))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "amd64", info.Arch)
+	assert.Equal(t, WorkerLibcStatic, info.Libc)
+
+	script := filepath.Join(t.TempDir(), "worker.sh")
+	assert.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0755))
+
+	_, ok, err = InspectWorkerBinary(script)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = InspectWorkerBinary(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	info, ok, err = InspectWorkerBinary(writeTestElfWith(
+		t, "worker-freebsd", elf.ELFOSABI_FREEBSD, binary.LittleEndian, elf.EM_X86_64, "/libexec/ld-elf.so.1"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "freebsd", info.OS)
+	assert.Equal(t, "/libexec/ld-elf.so.1", info.Interpreter)
+	assert.Empty(t, info.Libc)
+
+	info, _, _ = InspectWorkerBinary(writeTestElfWith(
+		t, "worker-ppc64", elf.ELFOSABI_NONE, binary.BigEndian, elf.EM_PPC64, ""))
+	assert.Equal(t, "ppc64", info.Arch)
+
+	info, _, _ = InspectWorkerBinary(writeTestElf(t, "worker-ppc64le", elf.EM_PPC64, ""))
+	assert.Equal(t, "ppc64le", info.Arch)
+}
+
+func TestInspectWorkerBinary_LookPath(t *testing.T) {
+	path := writeTestElf(t, "mediasoup-worker-test", elf.EM_X86_64, "")
+
+	t.Setenv("PATH", filepath.Dir(path))
+
+	info, ok, err := InspectWorkerBinary("mediasoup-worker-test")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, path, info.Path)
+}
+
+func TestCheckWorkerBinary(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("linux/amd64 only")
+	}
+
+	compatible := writeTestElf(t, "worker-static", elf.EM_X86_64, "")
+	otherArch := writeTestElf(t, "worker-arm64", elf.EM_AARCH64, "")
+	noLoader := writeTestElf(t, "worker-musl", elf.EM_X86_64, "/lib/ld-musl-nonexistent.so.1")
+
+	assert.NoError(t, CheckWorkerBinary(compatible))
+	assert.IsType(t, NewTypeError(""), CheckWorkerBinary(otherArch))
+	assert.Contains(t, CheckWorkerBinary(otherArch).Error(), "built for arm64")
+	assert.Contains(t, CheckWorkerBinary(noLoader).Error(), "musl loader /lib/ld-musl-nonexistent.so.1")
+
+	path, err := SelectWorkerBinary(otherArch, noLoader, compatible)
+	assert.NoError(t, err)
+	assert.Equal(t, compatible, path)
+
+	_, err = SelectWorkerBinary(otherArch, noLoader)
+	assert.Contains(t, err.Error(), "built for arm64")
+	assert.Contains(t, err.Error(), "missing on this host")
+
+	_, err = CreateWorker("", WithWorkerBinCandidates(otherArch))
+	assert.IsType(t, NewTypeError(""), err)
+}
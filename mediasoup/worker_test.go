@@ -3,6 +3,7 @@ package mediasoup
 import (
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -39,6 +40,17 @@ func CreateTestWorker(options ...Option) *Worker {
 	return worker
 }
 
+// newTestWorker returns a Worker talking to a fake worker, see
+// newTestChannel().
+func newTestWorker() *Worker {
+	return &Worker{
+		channel:  newTestChannel(),
+		observer: NewEventEmitter(AppLogger()),
+		logger:   TypeLogger("Worker"),
+		routers:  make(map[string]*Router),
+	}
+}
+
 func TestCreateWorker_Succeeds(t *testing.T) {
 	worker := CreateTestWorker()
 	assert.Greater(t, worker.Pid(), 0)
@@ -73,6 +85,9 @@ func TestCreateWorker_TypeError(t *testing.T) {
 
 	_, err = CreateWorker("", WithDTLSCert("notfuond/dtls-cert.pem", "notfuond/dtls-key.pem"))
 	assert.IsType(t, err, NewTypeError(""))
+
+	_, err = CreateWorker("", WithRequestTimeouts(RequestTimeouts{Expensive: -time.Second}))
+	assert.IsType(t, err, NewTypeError(""))
 }
 
 func TestWorkerUpdateSettings_Succeeds(t *testing.T) {
@@ -168,28 +183,41 @@ func TestWorkerEmitsDied(t *testing.T) {
 	}
 }
 
-func TestWorkerProcessIgnoreSignals(t *testing.T) {
-	worker := CreateTestWorker(WithLogLevel("warn"))
+func TestWorkerRequest(t *testing.T) {
+	worker := CreateTestWorker()
+	defer worker.Close()
 
-	nextCh := make(chan struct{})
+	dump, err := WorkerRequest[struct {
+		Pid       int
+		RouterIds []string
+	}](worker, "worker.dump", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, worker.Pid(), dump.Pid)
 
-	worker.On("died", func() { close(nextCh) })
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
 
-	process, err := os.FindProcess(worker.Pid())
+	routerDump, err := RouterRequest[struct{ Id string }](router, "router.dump", nil)
 	assert.NoError(t, err)
+	assert.Equal(t, router.Id(), routerDump.Id)
 
-	process.Signal(syscall.SIGPIPE)
-	process.Signal(syscall.SIGHUP)
-	process.Signal(syscall.SIGALRM)
-	process.Signal(syscall.SIGUSR1)
-	process.Signal(syscall.SIGUSR2)
+	_, err = WorkerRequest[H](worker, "worker.unknownMethod", nil)
+	assert.Error(t, err)
+}
 
-	timer := time.NewTimer(time.Second)
-	select {
-	case <-nextCh:
-	case <-timer.C:
-	}
+func TestWorkerWithIdGenerator(t *testing.T) {
+	worker := CreateTestWorker(WithIdGenerator(func(entity string) string {
+		return entity + "-" + UlidIdGenerator(entity)
+	}))
+	defer worker.Close()
 
-	assert.False(t, worker.Closed())
-	worker.Close()
+	router, err := worker.CreateRouter(testRouterMediaCodecs)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(router.Id(), "router-"))
+
+	transport, err := router.CreateWebRtcTransport(CreateWebRtcTransportParams{
+		ListenIps: []ListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(transport.Id(), "transport-"))
 }
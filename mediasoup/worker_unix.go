@@ -0,0 +1,48 @@
+//go:build !windows
+
+package mediasoup
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// newChannelSocket creates a socketpair for a Channel or PayloadChannel,
+// returning the end used by this process and the one given to the worker.
+func newChannelSocket() (conn net.Conn, workerFiles channelFiles, err error) {
+	fds, err := syscall.Socketpair(syscall.AF_LOCAL, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		return
+	}
+
+	if conn, err = fdToFileConn(fds[0]); err != nil {
+		syscall.Close(fds[1])
+		return
+	}
+
+	workerFile := os.NewFile(uintptr(fds[1]), "")
+
+	return conn, channelFiles{read: workerFile, write: workerFile}, nil
+}
+
+// startWorkerProcess passes the worker end of each socket twice, since
+// workers using pipes read from the first fd and write to the second one:
+// 3/4 for the Channel and 5/6 for the PayloadChannel.
+func startWorkerProcess(child *exec.Cmd, channel, payloadChannel channelFiles) error {
+	child.ExtraFiles = []*os.File{channel.read, channel.write, payloadChannel.read, payloadChannel.write}
+
+	return child.Start()
+}
+
+// terminateProcess asks the process to exit.
+func terminateProcess(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
+
+func fdToFileConn(fd int) (net.Conn, error) {
+	f := os.NewFile(uintptr(fd), "")
+	defer f.Close()
+	return net.FileConn(f)
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package mediasoup
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChannelSocket(t *testing.T) {
+	conn, workerFiles, err := newChannelSocket()
+	assert.NoError(t, err)
+	defer conn.Close()
+	defer workerFiles.Close()
+
+	assert.Equal(t, workerFiles.read, workerFiles.write)
+
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = workerFiles.read.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	_, err = workerFiles.write.Write([]byte("pong"))
+	assert.NoError(t, err)
+
+	_, err = conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestWorkerProcessIgnoreSignals(t *testing.T) {
+	worker := CreateTestWorker(WithLogLevel("warn"))
+
+	nextCh := make(chan struct{})
+
+	worker.On("died", func() { close(nextCh) })
+
+	process, err := os.FindProcess(worker.Pid())
+	assert.NoError(t, err)
+
+	process.Signal(syscall.SIGPIPE)
+	process.Signal(syscall.SIGHUP)
+	process.Signal(syscall.SIGALRM)
+	process.Signal(syscall.SIGUSR1)
+	process.Signal(syscall.SIGUSR2)
+
+	timer := time.NewTimer(time.Second)
+	select {
+	case <-nextCh:
+	case <-timer.C:
+	}
+
+	assert.False(t, worker.Closed())
+	worker.Close()
+}
@@ -0,0 +1,297 @@
+//go:build windows
+
+package mediasoup
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var procCreateProcessW = syscall.NewLazyDLL("kernel32.dll").NewProc("CreateProcessW")
+
+// Flags of the C runtime file descriptors given to a child process.
+const (
+	crtFlagOpen = 0x01
+	crtFlagPipe = 0x08
+)
+
+// newChannelSocket creates the pipes of a Channel or PayloadChannel, one per
+// direction since Windows has no socketpair, returning a net.Conn over the
+// ends used by this process and the ends given to the worker.
+func newChannelSocket() (conn net.Conn, workerFiles channelFiles, err error) {
+	workerRead, writer, err := os.Pipe()
+	if err != nil {
+		return
+	}
+
+	reader, workerWrite, err := os.Pipe()
+	if err != nil {
+		workerRead.Close()
+		writer.Close()
+		return
+	}
+
+	return &pipeConn{reader: reader, writer: writer}, channelFiles{read: workerRead, write: workerWrite}, nil
+}
+
+/**
+ * Start the worker with the ends of the Channel as its fds 3/4 and the ones of
+ * the PayloadChannel as its fds 5/6. os/exec cannot pass them on Windows (no
+ * ExtraFiles), so the process is created with the C runtime fd table in the
+ * lpReserved2 field of its STARTUPINFO, as libuv does, which the worker (a
+ * libuv program) opens its fds from.
+ *
+ * Only the Path, Args, Env, Stdout and Stderr of child are used, Stdout and
+ * Stderr being the pipes of StdoutPipe() and StderrPipe(). child.Process is
+ * set, so child.Wait() can be called.
+ */
+func startWorkerProcess(child *exec.Cmd, channel, payloadChannel channelFiles) (err error) {
+	stdout, stdoutOk := child.Stdout.(*os.File)
+	stderr, stderrOk := child.Stderr.(*os.File)
+
+	if !stdoutOk || !stderrOk {
+		return errors.New("worker stdout and stderr must be pipes")
+	}
+
+	// The worker has its own copies once spawned.
+	defer stdout.Close()
+	defer stderr.Close()
+
+	// fd 0 (stdin) is not given.
+	files := []*os.File{nil, stdout, stderr, channel.read, channel.write, payloadChannel.read, payloadChannel.write}
+	handles := make([]syscall.Handle, len(files))
+	flags := make([]byte, len(files))
+
+	for fd, file := range files {
+		handles[fd] = syscall.InvalidHandle
+
+		if file != nil {
+			handles[fd], flags[fd] = syscall.Handle(file.Fd()), crtFlagOpen|crtFlagPipe
+		}
+	}
+
+	crtFds := crtFileDescriptors(handles, flags)
+
+	startupInfo := windowsStartupInfo{
+		Flags:       syscall.STARTF_USESTDHANDLES,
+		CbReserved2: uint16(len(crtFds)),
+		LpReserved2: &crtFds[0],
+		StdInput:    syscall.InvalidHandle,
+		StdOutput:   handles[1],
+		StdErr:      handles[2],
+	}
+	startupInfo.Cb = uint32(unsafe.Sizeof(startupInfo))
+
+	appName, err := syscall.UTF16PtrFromString(child.Path)
+	if err != nil {
+		return
+	}
+	cmdLine, err := syscall.UTF16PtrFromString(makeCmdLine(child.Args))
+	if err != nil {
+		return
+	}
+	envBlock := createEnvBlock(child.Env)
+
+	var processInfo syscall.ProcessInformation
+
+	// Every inheritable handle is inherited, so no other process is started
+	// while the ones of the worker are inheritable.
+	syscall.ForkLock.Lock()
+
+	for _, handle := range handles {
+		if handle != syscall.InvalidHandle {
+			if err = syscall.SetHandleInformation(handle, syscall.HANDLE_FLAG_INHERIT, syscall.HANDLE_FLAG_INHERIT); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		ok, _, e := procCreateProcessW.Call(
+			uintptr(unsafe.Pointer(appName)),
+			uintptr(unsafe.Pointer(cmdLine)),
+			0,
+			0,
+			1, // bInheritHandles
+			syscall.CREATE_UNICODE_ENVIRONMENT,
+			uintptr(unsafe.Pointer(envBlock)),
+			0,
+			uintptr(unsafe.Pointer(&startupInfo)),
+			uintptr(unsafe.Pointer(&processInfo)),
+		)
+		if ok == 0 {
+			err = os.NewSyscallError("CreateProcess", e)
+		}
+	}
+
+	for _, handle := range handles {
+		if handle != syscall.InvalidHandle {
+			syscall.SetHandleInformation(handle, syscall.HANDLE_FLAG_INHERIT, 0)
+		}
+	}
+
+	syscall.ForkLock.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	defer syscall.CloseHandle(processInfo.Thread)
+	// Keeps the pid from being reused until the process is found.
+	defer syscall.CloseHandle(processInfo.Process)
+
+	child.Process, err = os.FindProcess(int(processInfo.ProcessId))
+
+	return
+}
+
+// windowsStartupInfo is syscall.StartupInfo with the lpReserved2 field.
+type windowsStartupInfo struct {
+	Cb            uint32
+	_             *uint16
+	Desktop       *uint16
+	Title         *uint16
+	X             uint32
+	Y             uint32
+	XSize         uint32
+	YSize         uint32
+	XCountChars   uint32
+	YCountChars   uint32
+	FillAttribute uint32
+	Flags         uint32
+	ShowWindow    uint16
+	CbReserved2   uint16
+	LpReserved2   *byte
+	StdInput      syscall.Handle
+	StdOutput     syscall.Handle
+	StdErr        syscall.Handle
+}
+
+// crtFileDescriptors returns the C runtime fd table of a child process: the
+// number of fds (int32), then the flags of every fd (byte), then the handle of
+// every fd (pointer sized), unaligned.
+func crtFileDescriptors(handles []syscall.Handle, flags []byte) []byte {
+	count := len(handles)
+	handleSize := int(unsafe.Sizeof(syscall.Handle(0)))
+	buf := make([]byte, 4+count+count*handleSize)
+
+	binary.LittleEndian.PutUint32(buf, uint32(count))
+	copy(buf[4:], flags)
+
+	for fd, handle := range handles {
+		offset := 4 + count + fd*handleSize
+
+		if handleSize == 8 {
+			binary.LittleEndian.PutUint64(buf[offset:], uint64(handle))
+		} else {
+			binary.LittleEndian.PutUint32(buf[offset:], uint32(handle))
+		}
+	}
+
+	return buf
+}
+
+func makeCmdLine(args []string) string {
+	escapedArgs := make([]string, len(args))
+
+	for i, arg := range args {
+		escapedArgs[i] = syscall.EscapeArg(arg)
+	}
+
+	return strings.Join(escapedArgs, " ")
+}
+
+// createEnvBlock returns the environment block of a child process, nil to
+// inherit the one of this process.
+func createEnvBlock(env []string) *uint16 {
+	if env == nil {
+		return nil
+	}
+
+	block := []uint16{}
+
+	for _, keyValue := range env {
+		block = append(block, utf16.Encode([]rune(keyValue))...)
+		block = append(block, 0)
+	}
+	if len(env) == 0 {
+		block = append(block, 0)
+	}
+	block = append(block, 0)
+
+	return &block[0]
+}
+
+/**
+ * pipeConn is a net.Conn over the pipes of a Channel or PayloadChannel. The
+ * pipes are synchronous: a pending Read() returns once the worker closes its
+ * end, e.g. when the Worker is closed and its process killed, not on Close().
+ */
+type pipeConn struct {
+	reader *os.File
+	writer *os.File
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	return c.writer.Write(b)
+}
+
+func (c *pipeConn) Close() error {
+	werr := c.writer.Close()
+
+	if err := c.reader.Close(); err != nil {
+		return err
+	}
+
+	return werr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	if err := c.reader.SetReadDeadline(t); err != nil {
+		return err
+	}
+
+	return c.writer.SetWriteDeadline(t)
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return c.reader.SetReadDeadline(t)
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return c.writer.SetWriteDeadline(t)
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (pipeAddr) String() string {
+	return "pipe"
+}
+
+// terminateProcess kills the process, Windows has no SIGTERM.
+func terminateProcess(process *os.Process) error {
+	return process.Kill()
+}
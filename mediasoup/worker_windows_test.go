@@ -0,0 +1,56 @@
+//go:build windows
+
+package mediasoup
+
+import (
+	"encoding/binary"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChannelSocket(t *testing.T) {
+	conn, workerFiles, err := newChannelSocket()
+	assert.NoError(t, err)
+	defer conn.Close()
+	defer workerFiles.Close()
+
+	assert.NotEqual(t, workerFiles.read, workerFiles.write)
+
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = workerFiles.read.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	_, err = workerFiles.write.Write([]byte("pong"))
+	assert.NoError(t, err)
+
+	_, err = conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestCrtFileDescriptors(t *testing.T) {
+	handles := []syscall.Handle{syscall.InvalidHandle, 0x10, 0x20}
+	flags := []byte{0, crtFlagOpen | crtFlagPipe, crtFlagOpen | crtFlagPipe}
+
+	buf := crtFileDescriptors(handles, flags)
+	handleSize := int(unsafe.Sizeof(syscall.Handle(0)))
+
+	assert.Len(t, buf, 4+3+3*handleSize)
+	assert.EqualValues(t, 3, binary.LittleEndian.Uint32(buf))
+	assert.Equal(t, flags, buf[4:7])
+	assert.EqualValues(t, 0x20, buf[7+2*handleSize])
+}
+
+func TestCreateEnvBlock(t *testing.T) {
+	assert.Nil(t, createEnvBlock(nil))
+
+	block := createEnvBlock([]string{"A=1"})
+	assert.Equal(t, []uint16{'A', '=', '1', 0, 0}, unsafe.Slice(block, 5))
+}